@@ -3,16 +3,22 @@ package main
 import (
 	"context"
 	"log"
+	"time"
 
 	"github.com/Tomelin/financial-management-backend/configs"
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
 	"github.com/Tomelin/financial-management-backend/internal/core/repository"
 	"github.com/Tomelin/financial-management-backend/internal/core/service"
+	middleware "github.com/Tomelin/financial-management-backend/internal/infra/handler/middleware/authorization"
 	"github.com/Tomelin/financial-management-backend/internal/infra/handler/web"
 	"github.com/Tomelin/financial-management-backend/pkg/authProvider"
 	"github.com/Tomelin/financial-management-backend/pkg/db"
 	http_server "github.com/Tomelin/financial-management-backend/pkg/http_server/server"
+	"github.com/Tomelin/financial-management-backend/pkg/jwtkeys"
 	"github.com/Tomelin/financial-management-backend/pkg/logger"
+	"github.com/Tomelin/financial-management-backend/pkg/mail"
 	"github.com/Tomelin/financial-management-backend/pkg/observability"
+	"github.com/Tomelin/financial-management-backend/pkg/payment"
 )
 
 func main() {
@@ -34,7 +40,21 @@ func main() {
 	ctx, span := tracer.Trace.Start(context.Background(), "main")
 	defer span.End()
 
-	fbDB, err := db.NewFirebaseDatabaseConnection(ctx, cfg.Fields["firebase"], "firebase")
+	// the database section picks the backend: firebase (default), memory, sqlite or postgres
+	dbKind, dbFields := db.KindFirebase, cfg.Fields["firebase"]
+	if database, ok := cfg.Fields["database"].(map[string]any); ok {
+		if kind, ok := database["kind"].(string); ok && kind != "" && kind != db.KindFirebase {
+			dbKind, dbFields = kind, database
+		}
+	}
+
+	fbDB, err := db.NewFirebaseDatabaseConnection(ctx, dbFields, dbKind)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	defer fbDB.Close()
+
+	authConfig, err := authProvider.ParseConfig(cfg.Fields["auth"])
 	if err != nil {
 		log.Fatalln(err)
 	}
@@ -93,8 +113,72 @@ func main() {
 		log.Fatalln(err)
 	}
 
-	svcWallet, mErr := service.NewWalletSvc(repoWallet, svcTenant, userSvc)
+	svcEntitlement, mErr := service.NewEntitlementSvc(svcTenant)
+	if mErr != nil {
+		log.Fatalln(mErr)
+	}
+
+	svcWallet, mErr := service.NewWalletSvc(repoWallet, svcTenant, userSvc, svcEntitlement)
+	if mErr != nil {
+		log.Fatalln(mErr)
+	}
+
+	repoWalletInvitation, err := repository.NewWalletInvitationRepo(fbDB)
 	if err != nil {
+		log.Fatalln(err)
+	}
+
+	svcWalletShare, mErr := service.NewWalletShareSvc(repoWallet, repoWalletInvitation, svcEntitlement, userSvc)
+	if mErr != nil {
+		log.Fatalln(mErr)
+	}
+
+	// SUBSCRIPTION
+	repoSubscription, err := repository.NewSubscriptionRepo(fbDB)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	svcSubscription, mErr := service.NewSubscriptionSvc(repoSubscription, svcTenant, svcPlan, repoWallet, userSvc)
+	if mErr != nil {
+		log.Fatalln(mErr)
+	}
+	go renewSubscriptions(svcSubscription, customLogger)
+
+	// ADMIN
+	repoAudit, err := repository.NewAuditRepo(fbDB)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	svcAudit, mErr := service.NewAuditSvc(repoAudit)
+	if mErr != nil {
+		log.Fatalln(mErr)
+	}
+
+	svcAdmin, mErr := service.NewAdminSvc(svcTenant, userSvc, repoWallet)
+	if mErr != nil {
+		log.Fatalln(mErr)
+	}
+
+	// BILLING
+	paymentConfig, err := payment.LoadConfig(cfg.Fields["payment"])
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	paymentProvider, err := payment.NewProvider(paymentConfig)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	repoBilling, err := repository.NewBillingRepo(fbDB)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	svcBilling, mErr := service.NewBillingSvc(repoBilling, paymentProvider, svcSubscription, svcTenant, svcPlan, userSvc, paymentConfig)
+	if mErr != nil {
 		log.Fatalln(mErr)
 	}
 
@@ -104,33 +188,122 @@ func main() {
 		log.Fatalln(err)
 	}
 
-	svcCategory, mErr := service.NewTransactionCategorySvc(tracer, &repoCategory, &svcTenant, &svcWallet, &userSvc)
+	svcCategory, mErr := service.NewTransactionCategorySvc(tracer, &repoCategory, &svcTenant, &svcWallet, &userSvc, &svcEntitlement)
 	if mErr != nil {
 		log.Fatalln(err)
 	}
 
+	// TRANSACTION
+	repoTransaction, err := repository.NewTransactionRepo(fbDB)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	svcTransaction, mErr := service.NewTransactionSvc(repoTransaction, svcWallet, svcCategory, userSvc)
+	if mErr != nil {
+		log.Fatalln(mErr)
+	}
+
 	// Authorization
 	repoAuth, err := repository.NewAuthorizationRepo(fbDB, customLogger)
 	if err != nil {
 		log.Fatalln(err)
 	}
 
-	svcAuth, err := service.NewAuthorizationSvc(repoAuth, customLogger)
+	jwtKeys, err := jwtkeys.NewKeySet(cfg.Fields["jwt"])
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	svcAuth, err := service.NewAuthorizationSvc(repoAuth, userSvc, jwtKeys, customLogger)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	// LOCAL AUTHENTICATION
+	mailSender, err := mail.NewSender(cfg.Fields["mail"], customLogger)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	repoLocalAuth, err := repository.NewLocalAuthRepo(fbDB, customLogger)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	// TWO-FACTOR
+	repoTwoFactor, err := repository.NewTwoFactorRepo(fbDB, customLogger)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	svcTwoFactor, err := service.NewTwoFactorSvc(repoTwoFactor, repoLocalAuth, userSvc, svcTenant, svcAuth, customLogger)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	svcLocalAuth, err := service.NewLocalAuthSvc(repoLocalAuth, userSvc, svcAuth, svcTwoFactor, mailSender, service.LocalAuthConfig{
+		VerifyEmailURL:   authConfig.Local.VerifyEmailURL,
+		ResetPasswordURL: authConfig.Local.ResetPasswordURL,
+	}, customLogger)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	// IDENTITIES
+	repoIdentity, err := repository.NewIdentityRepo(fbDB, customLogger)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	svcIdentity, err := service.NewIdentitySvc(repoIdentity, repoLocalAuth, userSvc, customLogger)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	// PERSONAL ACCESS TOKENS
+	repoAccessToken, err := repository.NewPersonalAccessTokenRepo(fbDB, customLogger)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	svcAccessToken, err := service.NewPersonalAccessTokenSvc(repoAccessToken, userSvc, customLogger)
 	if err != nil {
 		log.Fatalln(err)
 	}
 
 	// WEbServer
-	web.NewAuthenticationHandlerHttp(authProvider, customLogger, svcAuth, userSvc, rest.RouterGroup)
-	web.NewUserHandlerHttp(&userSvc, tracer, rest.RouterGroup)
+	rest.SetAuthenticator(middleware.Authenticate(svcAuth, svcAccessToken, svcTenant))
+
+	web.NewAuthenticationHandlerHttp(authProvider, customLogger, svcAuth, userSvc, svcIdentity, svcTwoFactor, rest.RouterGroup, rest.ValidateToken)
+	web.NewLocalAuthHandlerHttp(svcLocalAuth, authProvider.Cookies(), rest.RouterGroup)
+	web.NewTwoFactorHandlerHttp(svcTwoFactor, authProvider.Cookies(), rest.RouterGroup, rest.ValidateToken)
+	web.NewSessionHandlerHttp(svcAuth, rest.RouterGroup, rest.ValidateToken)
+	web.NewPersonalAccessTokenHandlerHttp(svcAccessToken, rest.RouterGroup, rest.ValidateToken)
+	web.NewJWKSHandlerHttp(jwtKeys, &rest.Route.RouterGroup)
+	web.NewUserHandlerHttp(&userSvc, tracer, rest.RouterGroup, rest.ValidateToken)
 	// web.NewCategoryHandlerHttp(&svcCategory, rest.RouterGroup)
-	web.NewTenantHandlerHttp(&svcTenant, rest.RouterGroup)
-	web.NewPlanHandlerHttp(&svcPlan, rest.RouterGroup)
-	web.NewWalletHandlerHttp(&svcWallet, &userSvc, rest.RouterGroup)
-	web.NewTransactionCategoryHandlerHttp(tracer, &svcCategory, &svcWallet, rest.RouterGroup, rest.MiddlewareHeader)
+	web.NewTenantHandlerHttp(svcTenant, rest.RouterGroup, rest.ValidateToken)
+	web.NewAdminHandlerHttp(svcAdmin, svcAudit, svcPlan, svcTenant, userSvc, rest.RouterGroup, rest.ValidateToken)
+	web.NewPlanHandlerHttp(&svcPlan, rest.RouterGroup, rest.ValidateToken)
+	web.NewWalletHandlerHttp(&svcWallet, &userSvc, rest.RouterGroup, rest.ValidateToken)
+	web.NewWalletShareHandlerHttp(svcWalletShare, rest.RouterGroup, rest.ValidateToken)
+	web.NewSubscriptionHandlerHttp(svcSubscription, rest.RouterGroup, rest.ValidateToken)
+	web.NewBillingHandlerHttp(svcBilling, paymentProvider, rest.DevMode(), rest.RouterGroup, rest.ValidateToken)
+	web.NewTransactionCategoryHandlerHttp(tracer, &svcCategory, &svcWallet, rest.RouterGroup, rest.ValidateToken)
+	web.NewTransactionHandlerHttp(&svcTransaction, &userSvc, rest.RouterGroup, rest.ValidateToken)
 	rest.Run(rest.Route.Handler())
 }
 
+// renewSubscriptions applies the renewals, downgrades and cancels of the subscriptions every hour
+func renewSubscriptions(svc entity.ISubscription, l logger.Logger) {
+	for now := range time.Tick(time.Hour) {
+		if mErr := svc.Renew(context.Background(), now.UTC()); mErr != nil {
+			l.Error(&logger.Message{Body: mErr.Err, Code: logger.ResponseCodeInternalServer})
+		}
+	}
+}
+
 func second(l logger.Logger) {
 
 	l.Error(&logger.Message{Body: "second", Code: logger.ResponseCodeAccepted})
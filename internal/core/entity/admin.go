@@ -0,0 +1,26 @@
+package entity
+
+import "context"
+
+// UsageFeatures are the features of the plan shown with the usage of a tenant
+var UsageFeatures = []string{FeatureWalletsMax, FeatureWalletSharesMax, FeatureCategoriesCustomMax}
+
+type IAdmin interface {
+	// GetTenants pages the tenants with their usage
+	GetTenants(ctx context.Context, page *PageRequest) (*Page[TenantUsage], *ModuleError)
+	// SuspendTenant blocks the users of the tenant, a suspended tenant is suspended again with the new reason
+	SuspendTenant(ctx context.Context, tenantId *string, data *SuspendRequest) (*TenantResponse, *ModuleError)
+	UnsuspendTenant(ctx context.Context, tenantId *string) (*TenantResponse, *ModuleError)
+}
+
+// TenantUsage is a tenant with its users, its wallets and the limits of its plan
+type TenantUsage struct {
+	Tenant       TenantResponse `json:"tenant"`
+	Users        int            `json:"users"`
+	Wallets      int            `json:"wallets"`
+	Entitlements []Entitlement  `json:"entitlements"`
+}
+
+type SuspendRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
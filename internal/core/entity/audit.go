@@ -0,0 +1,54 @@
+package entity
+
+import (
+	"context"
+	"time"
+)
+
+// The actions of the system admins written to the audit log
+const (
+	AuditPlanCreate      = "plan.create"
+	AuditPlanUpdate      = "plan.update"
+	AuditPlanDelete      = "plan.delete"
+	AuditTenantCreate    = "tenant.create"
+	AuditTenantUpdate    = "tenant.update"
+	AuditTenantDelete    = "tenant.delete"
+	AuditTenantList      = "tenant.list"
+	AuditTenantSearch    = "tenant.search"
+	AuditTenantView      = "tenant.view"
+	AuditTenantSuspend   = "tenant.suspend"
+	AuditTenantUnsuspend = "tenant.unsuspend"
+	AuditUserList        = "user.list"
+	AuditUserSearch      = "user.search"
+	AuditUserView        = "user.view"
+	AuditAuditList       = "audit.list"
+)
+
+// AuditFields are the fields of the audit log that can be filtered and sorted
+var AuditFields = Fields{
+	"id":          {Type: FieldString},
+	"actor_id":    {Type: FieldString},
+	"action":      {Type: FieldString},
+	"resource_id": {Type: FieldString},
+	"create_at":   {Type: FieldTime, Sortable: true},
+}
+
+type IAudit interface {
+	// Record writes the action to the audit log
+	Record(ctx context.Context, data *AuditLog) *ModuleError
+	Get(ctx context.Context, page *PageRequest) (*Page[AuditLog], *ModuleError)
+}
+
+// AuditLog is an action of a system admin, the failed and the denied ones are written as well
+type AuditLog struct {
+	ID         string    `json:"id" firestore:"id"`
+	ActorID    string    `json:"actor_id" firestore:"actor_id"`
+	ActorEmail string    `json:"actor_email" firestore:"actor_email"`
+	Action     string    `json:"action" firestore:"action"`
+	ResourceID string    `json:"resource_id,omitempty" firestore:"resource_id"`
+	Method     string    `json:"method" firestore:"method"`
+	Path       string    `json:"path" firestore:"path"`
+	Status     int       `json:"status" firestore:"status"`
+	IP         string    `json:"ip" firestore:"ip"`
+	CreatedAt  time.Time `json:"created_at" firestore:"create_at"`
+}
@@ -2,17 +2,129 @@ package entity
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
 
 	"github.com/dgrijalva/jwt-go"
+	"github.com/google/uuid"
 )
 
+const (
+	// AccessTokenTTL is the lifetime of the access tokens, the JWT of the Authorization header
+	AccessTokenTTL = 15 * time.Minute
+	// RefreshTokenTTL is the lifetime of a refresh token, each rotation starts it again
+	RefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+// opaqueTokenSize is the number of random bytes of the refresh tokens and of the action tokens
+const opaqueTokenSize = 32
+
 type IAuthorization interface {
-	GenerateTokenJWT(ctx context.Context, token *AuthorizationClaims, user *AccountUser) (*string, error)
+	// GenerateTokenJWT issues the access token of the user and starts a new refresh token family
+	GenerateTokenJWT(ctx context.Context, token *AuthorizationClaims, user *AccountUser) (*TokenPair, error)
+	// ValidateTokenJWT verifies the token and that it was not revoked, by its id or by the token generation of the user
 	ValidateTokenJWT(ctx context.Context, token string) (*AccountUser, error)
+	// RevokeTokenJWT revokes the token of the id (jti)
 	RevokeTokenJWT(ctx context.Context, token *string) error
-	RefreshTokenJWT(ctx context.Context, token *string) (*string, error)
-	ParseTokenJWT(ctx context.Context, token string) error
-	StoreTokenJWT(ctx context.Context, token []byte, userId *string) error
+	// RevokeAllTokenJWT revokes every token of the user, the access and the refresh tokens, by bumping its token generation
+	RevokeAllTokenJWT(ctx context.Context, userID string) error
+	// RefreshTokenJWT rotates the refresh token and issues a new access token
+	// A refresh token that was already used revokes its whole family
+	RefreshTokenJWT(ctx context.Context, refreshToken string) (*TokenPair, error)
+	ParseTokenJWT(ctx context.Context, token string) (*AuthorizationClaims, error)
+	// ListSessions returns the active sessions of the user, the last seen first
+	ListSessions(ctx context.Context, userID string) ([]Session, error)
+	// RevokeSession ends the session of the user, its refresh tokens and its last access token are revoked
+	RevokeSession(ctx context.Context, userID, id string) error
+}
+
+// TokenPair is the response of the login and of the refresh
+type TokenPair struct {
+	AccessToken           string    `json:"access_token"`
+	TokenType             string    `json:"token_type"`
+	ExpiresAt             time.Time `json:"expires_at"`
+	RefreshToken          string    `json:"refresh_token"`
+	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"`
+}
+
+// RefreshToken is the stored record of an opaque refresh token
+// The ID is the SHA-256 of the token, the token itself is never stored
+// The tokens rotated from the same login share the family
+type RefreshToken struct {
+	ID        string    `json:"id" firestore:"id"`
+	UserID    string    `json:"user_id" firestore:"user_id"`
+	Family    string    `json:"family" firestore:"family"`
+	ExpiresAt time.Time `json:"expires_at" firestore:"expires_at"`
+	Revoked   bool      `json:"revoked" firestore:"revoked"`
+	Used      bool      `json:"used" firestore:"used"`
+	// Generation is the token generation of the user when the family started
+	Generation int64     `json:"generation" firestore:"generation"`
+	CreatedAt  time.Time `json:"created_at" firestore:"create_at"`
+}
+
+// TokenGeneration is the counter of the tokens of a user
+// The tokens issued with a lower generation are revoked, "log out of all devices" bumps it
+type TokenGeneration struct {
+	UserID     string    `json:"user_id" firestore:"user_id"`
+	Generation int64     `json:"generation" firestore:"generation"`
+	UpdatedAt  time.Time `json:"updated_at" firestore:"update_at"`
+}
+
+// NewRefreshToken creates a refresh token of the user, an empty family starts a new one
+// It returns the opaque token, that is sent to the client, and its record
+func NewRefreshToken(userID, family string, generation int64) (string, *RefreshToken, error) {
+	if userID == "" {
+		return "", nil, errors.New("user id is required")
+	}
+
+	token, err := newOpaqueToken()
+	if err != nil {
+		return "", nil, err
+	}
+
+	if family == "" {
+		family = uuid.NewString()
+	}
+
+	now := time.Now().UTC()
+	return token, &RefreshToken{
+		ID:         RefreshTokenID(token),
+		UserID:     userID,
+		Family:     family,
+		ExpiresAt:  now.Add(RefreshTokenTTL),
+		Generation: generation,
+		CreatedAt:  now,
+	}, nil
+}
+
+// RefreshTokenID returns the ID of the record of the opaque token
+func RefreshTokenID(token string) string {
+	return opaqueTokenID(token)
+}
+
+// newOpaqueToken returns a random token that is safe in URLs
+func newOpaqueToken() (string, error) {
+	b := make([]byte, opaqueTokenSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// opaqueTokenID is the SHA-256 of the token, the stored records are found by it
+func opaqueTokenID(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsExpired reports if the refresh token expired at the time
+func (r *RefreshToken) IsExpired(now time.Time) bool {
+	return !now.Before(r.ExpiresAt)
 }
 
 type AuthorizationClaims struct {
@@ -21,7 +133,21 @@ type AuthorizationClaims struct {
 	Email     string         `json:"email" firestore:"email"`
 	Roles     []AccountRoles `json:"roles" firestore:"roles"`
 	IsRevoked bool           `json:"is_revoked" firestore:"is_revoked"`
+	// Generation is the token generation of the user when the token was issued
+	Generation int64 `json:"gen,omitempty" firestore:"generation"`
+	// SessionID is the session of the token, the family of its refresh token
+	SessionID string `json:"sid,omitempty" firestore:"session_id"`
 	jwt.StandardClaims
 }
 
-const SecretTokenJWT = "TW9uIERlYyAxNiAyMjoxNDowNyAtMDMgMjAyNAo="
+// BearerToken returns the token of an Authorization header, without the Bearer scheme
+func BearerToken(header string) string {
+	header = strings.TrimSpace(header)
+	if scheme, token, ok := strings.Cut(header, " "); ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+	if strings.EqualFold(header, "Bearer") {
+		return ""
+	}
+	return header
+}
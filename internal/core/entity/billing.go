@@ -0,0 +1,50 @@
+package entity
+
+import (
+	"context"
+	"time"
+)
+
+type IBilling interface {
+	// Checkout creates the page the owner of the tenant pays a paid plan on
+	Checkout(ctx context.Context, userId *string, data *SubscriptionRequest) (*CheckoutResponse, *ModuleError)
+	// Cancel stops the charges of the provider and cancels the subscription at the end of the period
+	Cancel(ctx context.Context, userId *string) (*Subscription, *ModuleError)
+	// Resume undoes a cancel, the charges of the provider must not be stopped
+	Resume(ctx context.Context, userId *string) (*Subscription, *ModuleError)
+	// HandleWebhook verifies the signature of an event of the provider and applies it to the subscription once
+	HandleWebhook(ctx context.Context, payload []byte, signature string) *ModuleError
+}
+
+// BillingAccount links a tenant to its customer and its subscription in the payment provider
+type BillingAccount struct {
+	TenantID       string    `json:"tenant_id" firestore:"tenant_id"`
+	CustomerID     string    `json:"customer_id" firestore:"customer_id"`
+	SubscriptionID string    `json:"subscription_id" firestore:"subscription_id"`
+	CreatedAt      time.Time `json:"created_at" firestore:"create_at"`
+	UpdatedAt      time.Time `json:"updated_at" firestore:"update_at"`
+}
+
+// PaymentEventClaimTTL is how long a webhook is claimed by the request that applies it, a claim that is
+// neither processed nor released by then is of a request that stopped and the event is applied again
+const PaymentEventClaimTTL = 5 * time.Minute
+
+// PaymentEvent is a webhook claimed by the request that applies it, a webhook sent again is skipped
+// ProcessedAt is zero until the event is applied
+type PaymentEvent struct {
+	ID          string    `json:"id" firestore:"id"`
+	Type        string    `json:"type" firestore:"type"`
+	TenantID    string    `json:"tenant_id" firestore:"tenant_id"`
+	ClaimedAt   time.Time `json:"claimed_at" firestore:"claimed_at"`
+	ProcessedAt time.Time `json:"processed_at" firestore:"processed_at"`
+}
+
+// Claimed reports if the event is applied, or claimed by a request that is applying it
+func (e *PaymentEvent) Claimed(now time.Time) bool {
+	return !e.ProcessedAt.IsZero() || now.Sub(e.ClaimedAt) < PaymentEventClaimTTL
+}
+
+type CheckoutResponse struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+}
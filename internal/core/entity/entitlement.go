@@ -0,0 +1,61 @@
+package entity
+
+import (
+	"context"
+	"fmt"
+)
+
+// The keys of the features of a plan the services check on create
+const (
+	FeatureWalletsMax          = "wallets.max"
+	FeatureWalletSharesMax     = "wallet.shares.max"
+	FeatureCategoriesCustomMax = "categories.custom.max"
+)
+
+// DefaultEntitlements are the limits of a plan without the feature of the key
+var DefaultEntitlements = map[string]PlanFeatures{
+	FeatureWalletsMax:          {Name: FeatureWalletsMax, Count: 1},
+	FeatureWalletSharesMax:     {Name: FeatureWalletSharesMax, Count: 1},
+	FeatureCategoriesCustomMax: {Name: FeatureCategoriesCustomMax, Unlimited: true},
+}
+
+type IEntitlement interface {
+	// Get resolves the feature of the key on the plan of the tenant
+	Get(ctx context.Context, tenantId *string, key string) (*Entitlement, *ModuleError)
+	// Check returns the limit reached error when the usage of the tenant leaves no room for one more
+	Check(ctx context.Context, tenantId *string, key string, usage int) *ModuleError
+}
+
+// Entitlement is the limit of a feature of the plan of a tenant
+type Entitlement struct {
+	Key       string `json:"key"`
+	Limit     int    `json:"limit"`
+	Unlimited bool   `json:"unlimited"`
+}
+
+// Allows reports if one more is allowed with the current usage
+func (e *Entitlement) Allows(usage int) bool {
+	return e.Unlimited || usage < e.Limit
+}
+
+// Check returns the limit reached error, with the usage and the limit, when the usage is at the limit
+func (e *Entitlement) Check(usage int) *ModuleError {
+	if e.Allows(usage) {
+		return nil
+	}
+
+	return Error(fmt.Sprintf("limit reached: %s usage %d of %d", e.Key, usage, e.Limit), "entitlement", "Check", ApplicationLayerService, ResponseCodeBadRequest)
+}
+
+// Entitlement resolves the feature of the key, a feature the plan does not have falls back to the default
+// An unknown key allows nothing
+func (p *PlanResponse) Entitlement(key string) Entitlement {
+	for _, feature := range p.Features {
+		if feature.Name == key {
+			return Entitlement{Key: key, Limit: feature.Count, Unlimited: feature.Unlimited}
+		}
+	}
+
+	feature := DefaultEntitlements[key]
+	return Entitlement{Key: key, Limit: feature.Count, Unlimited: feature.Unlimited}
+}
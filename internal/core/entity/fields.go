@@ -0,0 +1,162 @@
+package entity
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FieldType is the type of a field of the filters
+type FieldType string
+
+const (
+	FieldString FieldType = "string"
+	FieldNumber FieldType = "number"
+	FieldBool   FieldType = "bool"
+	FieldTime   FieldType = "time"
+	// FieldArray is a list of strings, it is filtered by array-contains and array-contains-any
+	FieldArray FieldType = "array"
+)
+
+// Field describes a field that the clients can filter
+type Field struct {
+	Type     FieldType
+	Sortable bool
+}
+
+// Fields are the filterable fields of an entity by their document name
+// The fields that are not listed cannot be filtered or sorted
+type Fields map[string]Field
+
+// Sortable reports if the field can be used by order_by
+func (f Fields) Sortable(name string) bool {
+	return f[name].Sortable
+}
+
+// Coerce checks the fields and conditions of the clauses and converts the values to the types of the fields
+// The converted value is kept in QueryDB.Typed, it returns ErrInvalidQueryFilter when a query is invalid
+func (f Fields) Coerce(clauses []QueryDBClause) error {
+	for i := range clauses {
+		if err := f.CoerceQueries(clauses[i].Queries); err != nil {
+			return err
+		}
+		if err := f.Coerce(clauses[i].Clauses); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CoerceQueries is the Coerce of a list of queries
+func (f Fields) CoerceQueries(queries []QueryDB) error {
+	for i := range queries {
+		typed, err := f.coerce(queries[i])
+		if err != nil {
+			return err
+		}
+		queries[i].Typed = typed
+	}
+	return nil
+}
+
+func (f Fields) coerce(query QueryDB) (any, error) {
+	field, ok := f[query.Key]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown field %s", ErrInvalidQueryFilter, query.Key)
+	}
+
+	condition := QueryFirebase(query.Condition)
+	if condition == "" {
+		condition = QueryFirebaseEqual
+	}
+
+	switch condition {
+	case QueryFirebaseArrayContains, QueryFirebaseArrayContainsAny:
+		if field.Type != FieldArray {
+			return nil, fmt.Errorf("%w: %s is not supported on %s", ErrInvalidQueryFilter, condition, query.Key)
+		}
+		if condition == QueryFirebaseArrayContains {
+			return query.Value, nil
+		}
+		return field.coerceList(query)
+	case QueryFirebaseIn, QueryFirebaseNotIn:
+		if field.Type == FieldArray {
+			return nil, fmt.Errorf("%w: %s is not supported on %s", ErrInvalidQueryFilter, condition, query.Key)
+		}
+		return field.coerceList(query)
+	case QueryFirebaseEqual, QueryFirebaseNotEqual, QueryFirebaseLessThan, QueryFirebaseLessThanOrEqual,
+		QueryFirebaseGreaterThan, QueryFirebaseGreaterThanOrEqual:
+		if field.Type == FieldArray {
+			return nil, fmt.Errorf("%w: %s is not supported on %s", ErrInvalidQueryFilter, condition, query.Key)
+		}
+		return field.coerceValue(query.Key, query.Value)
+	}
+
+	return nil, fmt.Errorf("%w: unknown condition %s", ErrInvalidQueryFilter, query.Condition)
+}
+
+// coerceList converts the items of in, not-in and array-contains-any, they are separated by commas
+func (field Field) coerceList(query QueryDB) (any, error) {
+	var values []any
+	for _, item := range strings.Split(query.Value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		value, err := field.coerceValue(query.Key, item)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+
+	if len(values) == 0 {
+		return nil, fmt.Errorf("%w: value of %s is required", ErrInvalidQueryFilter, query.Key)
+	}
+	return values, nil
+}
+
+func (field Field) coerceValue(key, value string) (any, error) {
+	var (
+		typed any
+		err   error
+	)
+
+	switch field.Type {
+	case FieldNumber:
+		typed, err = strconv.ParseFloat(value, 64)
+	case FieldBool:
+		typed, err = strconv.ParseBool(value)
+	case FieldTime:
+		typed, err = ParseQueryTime(value)
+	default:
+		typed = value
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s must be a %s", ErrInvalidQueryFilter, key, field.Type)
+	}
+	return typed, nil
+}
+
+// ParseQueryTime parses the time of a filter, a RFC 3339 time or a date in UTC
+func ParseQueryTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, value)
+}
+
+// sortableFields returns the names of the sortable fields, it is used by the error messages
+func (f Fields) sortableFields() []string {
+	var names []string
+	for name, field := range f {
+		if field.Sortable {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
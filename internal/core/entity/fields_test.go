@@ -0,0 +1,48 @@
+package entity_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/stretchr/testify/suite"
+)
+
+type FieldsTestSuite struct {
+	suite.Suite
+}
+
+func (s *FieldsTestSuite) TestCoerce() {
+	clauses, err := entity.ParseQueryFilters([]string{"or(balance:gte:10.5,and(created_at:lt:2024-05-01,name:in:a|b))", "shared_with_tenants:contains-any:x|y"})
+	s.Require().Nil(err)
+	s.Nil(entity.WalletFields.Coerce(clauses))
+
+	s.Equal(10.5, clauses[0].Queries[0].Typed)
+	s.Equal(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), clauses[0].Clauses[0].Queries[0].Typed)
+	s.Equal([]any{"a", "b"}, clauses[0].Clauses[0].Queries[1].Typed)
+	s.Equal([]any{"x", "y"}, clauses[1].Queries[0].Typed)
+
+	queries := []entity.QueryDB{{Key: "created_at", Value: "2024-05-01T10:00:00-03:00", Condition: "=="}}
+	s.Nil(entity.WalletFields.CoerceQueries(queries))
+	s.True(time.Date(2024, 5, 1, 13, 0, 0, 0, time.UTC).Equal(queries[0].Typed.(time.Time)))
+}
+
+func (s *FieldsTestSuite) TestCoerce_Invalid() {
+	queries := []entity.QueryDB{
+		{Key: "mu", Value: "x", Condition: "=="},
+		{Key: "balance", Value: "ten", Condition: ">"},
+		{Key: "created_at", Value: "yesterday", Condition: ">"},
+		{Key: "name", Value: "x", Condition: "array-contains"},
+		{Key: "shared_with_tenants", Value: "x", Condition: "=="},
+		{Key: "name", Value: "x", Condition: "like"},
+		{Key: "name", Value: " , ", Condition: "in"},
+	}
+	for _, query := range queries {
+		err := entity.WalletFields.CoerceQueries([]entity.QueryDB{query})
+		s.ErrorIs(err, entity.ErrInvalidQueryFilter, query.Key)
+	}
+}
+
+func TestRunFieldsTestSuite(t *testing.T) {
+	suite.Run(t, new(FieldsTestSuite))
+}
@@ -0,0 +1,71 @@
+package entity
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrIdentityLinked is returned when the provider account is linked to another user
+	ErrIdentityLinked = errors.New("provider account is linked to another user")
+	// ErrProviderLinked is returned when the user has linked another account of the same provider
+	ErrProviderLinked = errors.New("provider is already linked")
+	// ErrIdentityConflict is returned by the login of a new provider account whose email is of an existing user but is not verified by the provider
+	ErrIdentityConflict = errors.New("an account of this email exists, sign in and link the provider")
+	// ErrIdentityNotFound is returned when the provider is not linked to the user
+	ErrIdentityNotFound = errors.New("provider is not linked")
+	// ErrLastIdentity is returned when unlinking the provider would leave the user without a way to sign in
+	ErrLastIdentity = errors.New("the last sign-in method cannot be unlinked")
+)
+
+// IIdentity links the accounts of the OAuth providers to the users
+// Resolve, Link, Unlink, List
+type IIdentity interface {
+	// Resolve returns the user of the provider account at the login
+	// It looks the linked identity up first, then an existing user of the verified email, and creates the user otherwise
+	// created reports if the user was created by the login
+	Resolve(ctx context.Context, identity *ExternalIdentity) (user *AccountUser, created bool, err error)
+	// Link links the provider account to the user, a user has one account of each provider
+	Link(ctx context.Context, userID string, identity *ExternalIdentity) (*Identity, error)
+	Unlink(ctx context.Context, userID, provider string) error
+	List(ctx context.Context, userID string) ([]Identity, error)
+}
+
+// Identity is an account of an OAuth provider linked to a user
+// It is stored in the identities sub-collection of the user
+type Identity struct {
+	UserID         string    `json:"user_id" firestore:"user_id"`
+	Provider       string    `json:"provider" firestore:"provider"`
+	ProviderUserID string    `json:"provider_user_id" firestore:"provider_user_id"`
+	Email          string    `json:"email" firestore:"email"`
+	LinkedAt       time.Time `json:"linked_at" firestore:"linked_at"`
+}
+
+// ExternalIdentity is the account returned by an OAuth provider at the callback
+// EmailVerified is true only when the provider verified the email of the account
+type ExternalIdentity struct {
+	Provider       string
+	ProviderUserID string
+	EmailVerified  bool
+	User           User
+}
+
+// Validate checks the provider account has what links it to a user
+func (e *ExternalIdentity) Validate() error {
+	if e == nil {
+		return errors.New("identity cannot be empty")
+	}
+	if e.Provider == "" || e.ProviderUserID == "" {
+		return errors.New("provider and provider user ID are required")
+	}
+	return nil
+}
+
+// IdentityID is the ID of the provider account, it is the same for every user
+func IdentityID(provider, providerUserID string) string {
+	sum := sha256.Sum256([]byte(provider + "\x00" + providerUserID))
+	return hex.EncodeToString(sum[:])
+}
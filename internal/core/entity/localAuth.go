@@ -0,0 +1,181 @@
+package entity
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Tomelin/financial-management-backend/pkg/utils"
+)
+
+const (
+	// ProviderLocal is the provider of the users that sign in with email and password
+	ProviderLocal = "local"
+
+	// PasswordMinLength and PasswordMaxLength bound the length of a password, in characters
+	PasswordMinLength = 8
+	PasswordMaxLength = 128
+
+	// EmailVerificationTTL is the lifetime of the token of the email verification link
+	EmailVerificationTTL = 48 * time.Hour
+	// PasswordResetTTL is the lifetime of the token of the password reset link
+	PasswordResetTTL = time.Hour
+)
+
+var (
+	// ErrInvalidCredentials is returned by the login when the email or the password is wrong
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	// ErrEmailNotVerified is returned by the login while the email of the user is not verified
+	ErrEmailNotVerified = errors.New("email not verified")
+	// ErrInvalidActionToken is returned when the token of a link is unknown, used or expired
+	ErrInvalidActionToken = errors.New("invalid or expired token")
+	// ErrEmailInUse is returned by the registration when a user of the email exists
+	ErrEmailInUse = errors.New("email already registered")
+	// ErrInvalidRequest wraps the validation errors of the requests of the local authentication
+	ErrInvalidRequest = errors.New("invalid request")
+)
+
+// ActionTokenPurpose is what an action token is for
+type ActionTokenPurpose string
+
+const (
+	ActionTokenVerifyEmail   ActionTokenPurpose = "verify_email"
+	ActionTokenResetPassword ActionTokenPurpose = "reset_password"
+)
+
+// ILocalAuth is the email and password authentication
+// Register, Login, VerifyEmail, ResendVerification, ForgotPassword, ResetPassword
+type ILocalAuth interface {
+	// Register creates the user and its credential and sends the email verification link
+	Register(ctx context.Context, request *RegisterRequest) (*AccountUser, error)
+	// Login returns the same token pair as the OAuth callback, or the challenge of the second factor
+	Login(ctx context.Context, request *LoginRequest) (*LoginResult, error)
+	VerifyEmail(ctx context.Context, token string) error
+	// ResendVerification sends a new verification link, it does not tell if the email exists
+	ResendVerification(ctx context.Context, email string) error
+	// ForgotPassword sends the password reset link, it does not tell if the email exists
+	ForgotPassword(ctx context.Context, email string) error
+	// ResetPassword sets the password and revokes every token of the user
+	ResetPassword(ctx context.Context, request *ResetPasswordRequest) error
+}
+
+// Credential is the password of a local user, it is stored apart from the user
+type Credential struct {
+	UserID        string    `json:"user_id" firestore:"user_id"`
+	PasswordHash  string    `json:"-" firestore:"password_hash"`
+	EmailVerified bool      `json:"email_verified" firestore:"email_verified"`
+	CreatedAt     time.Time `json:"created_at" firestore:"create_at"`
+	UpdatedAt     time.Time `json:"updated_at" firestore:"update_at"`
+}
+
+// ActionToken is the stored record of the opaque token of an email link
+// The ID is the SHA-256 of the token, the token itself is never stored
+type ActionToken struct {
+	ID        string             `json:"id" firestore:"id"`
+	UserID    string             `json:"user_id" firestore:"user_id"`
+	Purpose   ActionTokenPurpose `json:"purpose" firestore:"purpose"`
+	ExpiresAt time.Time          `json:"expires_at" firestore:"expires_at"`
+	Used      bool               `json:"used" firestore:"used"`
+	CreatedAt time.Time          `json:"created_at" firestore:"create_at"`
+}
+
+// NewActionToken creates an action token of the user
+// It returns the opaque token, that is sent by email, and its record
+func NewActionToken(userID string, purpose ActionTokenPurpose, ttl time.Duration) (string, *ActionToken, error) {
+	if userID == "" {
+		return "", nil, errors.New("user id is required")
+	}
+
+	token, err := newOpaqueToken()
+	if err != nil {
+		return "", nil, err
+	}
+
+	now := time.Now().UTC()
+	return token, &ActionToken{
+		ID:        ActionTokenID(token),
+		UserID:    userID,
+		Purpose:   purpose,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}, nil
+}
+
+// ActionTokenID returns the ID of the record of the opaque token
+func ActionTokenID(token string) string {
+	return opaqueTokenID(token)
+}
+
+// IsValid reports if the action token can be used for the purpose at the time
+func (t *ActionToken) IsValid(purpose ActionTokenPurpose, now time.Time) bool {
+	return t != nil && !t.Used && t.Purpose == purpose && now.Before(t.ExpiresAt)
+}
+
+type RegisterRequest struct {
+	Name     string `json:"name" binding:"required"`
+	Email    string `json:"email" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+type LoginRequest struct {
+	Email    string `json:"email" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+type EmailRequest struct {
+	Email string `json:"email" binding:"required"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+func (r *RegisterRequest) Validate() error {
+	if r == nil {
+		return errors.New("request is required")
+	}
+
+	r.Name = strings.TrimSpace(r.Name)
+	r.Email = NormalizeEmail(r.Email)
+	if r.Name == "" {
+		return errors.New("name is required")
+	}
+	if !utils.IsValidEmail(r.Email) {
+		return errors.New("invalid email")
+	}
+
+	return ValidatePassword(r.Password)
+}
+
+func (r *ResetPasswordRequest) Validate() error {
+	if r == nil || r.Token == "" {
+		return errors.New("token is required")
+	}
+
+	return ValidatePassword(r.Password)
+}
+
+// ValidatePassword checks the length of the password
+// The maximum bounds the cost of the hash, it is not a complexity rule
+func ValidatePassword(password string) error {
+	n := utf8.RuneCountInString(password)
+	if n < PasswordMinLength {
+		return errors.New("password must have at least 8 characters")
+	}
+	if n > PasswordMaxLength {
+		return errors.New("password must have at most 128 characters")
+	}
+	return nil
+}
+
+// NormalizeEmail trims and lowers the email, the emails of the local users are compared normalized
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,380 @@
+package entity
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency is used when a wallet or plan is created without currency
+const DefaultCurrency = "BRL"
+
+var (
+	ErrMoneyCurrencyMismatch = errors.New("currency mismatch")
+	ErrMoneyOverflow         = errors.New("amount overflow")
+	ErrMoneyInvalidCurrency  = errors.New("currency must be an ISO-4217 code")
+	ErrMoneyInvalidAmount    = errors.New("invalid amount")
+)
+
+// currencyExponent holds the ISO-4217 currencies that do not use two decimal places
+var currencyExponent = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"CLF": 4, "UYW": 4,
+}
+
+// CurrencyExponent returns the number of decimal places of the currency
+func CurrencyExponent(currency string) int {
+	if exp, ok := currencyExponent[strings.ToUpper(currency)]; ok {
+		return exp
+	}
+	return 2
+}
+
+// ValidateCurrency checks if the currency looks like an ISO-4217 code
+func ValidateCurrency(currency string) error {
+	if len(currency) != 3 {
+		return ErrMoneyInvalidCurrency
+	}
+	for _, r := range currency {
+		if r < 'A' || r > 'Z' {
+			return ErrMoneyInvalidCurrency
+		}
+	}
+	return nil
+}
+
+// Money is an exact amount in the minor unit of an ISO-4217 currency
+// 12.34 BRL is Money{Amount: 1234, Currency: "BRL"}
+//
+// In JSON and in the database a Money is a decimal number, like the float fields it replaces.
+// A decimal read without currency stays pending until the container binds its currency with In.
+type Money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+
+	// pending keeps a decimal that was read before its currency was known
+	pending *big.Rat
+	// approx is true when pending came from a float and may be rounded to the currency
+	approx bool
+}
+
+// NewMoney creates a Money from an amount in minor units
+func NewMoney(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: strings.ToUpper(currency)}
+}
+
+// ParseMoney creates a Money from a decimal string, e.g. "12.34"
+// It returns an error when the value has more decimal places than the currency allows
+func ParseMoney(value string, currency string) (Money, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(value))
+	if !ok {
+		return Money{}, ErrMoneyInvalidAmount
+	}
+	return fromRat(r, currency, false)
+}
+
+// MoneyFromFloat creates a Money from a float, rounding half away from zero to the currency minor unit
+// It exists to read the legacy float values, new code should use NewMoney or ParseMoney
+func MoneyFromFloat(value float64, currency string) (Money, error) {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return Money{}, ErrMoneyInvalidAmount
+	}
+	r, _ := new(big.Rat).SetString(strconv.FormatFloat(value, 'f', -1, 64))
+	return fromRat(r, currency, true)
+}
+
+func fromRat(r *big.Rat, currency string, round bool) (Money, error) {
+	currency = strings.ToUpper(currency)
+	if err := ValidateCurrency(currency); err != nil {
+		return Money{}, err
+	}
+
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(CurrencyExponent(currency))), nil)
+	minor := new(big.Rat).Mul(r, new(big.Rat).SetInt(scale))
+
+	if !minor.IsInt() {
+		if !round {
+			return Money{}, fmt.Errorf("%w: %s allows %d decimal places", ErrMoneyInvalidAmount, currency, CurrencyExponent(currency))
+		}
+		// round half away from zero
+		num := new(big.Int).Abs(minor.Num())
+		q, m := new(big.Int).QuoRem(num, minor.Denom(), new(big.Int))
+		if new(big.Int).Mul(m, big.NewInt(2)).Cmp(minor.Denom()) >= 0 {
+			q.Add(q, big.NewInt(1))
+		}
+		if minor.Sign() < 0 {
+			q.Neg(q)
+		}
+		minor.SetInt(q)
+	}
+
+	if !minor.Num().IsInt64() {
+		return Money{}, ErrMoneyOverflow
+	}
+
+	return Money{Amount: minor.Num().Int64(), Currency: currency}, nil
+}
+
+// In binds the money to the currency of its container
+// A pending decimal is converted to the currency minor unit, a bound money must have the same currency
+func (m Money) In(currency string) (Money, error) {
+	currency = strings.ToUpper(currency)
+
+	if m.pending != nil {
+		return fromRat(m.pending, currency, m.approx)
+	}
+
+	if m.Currency == "" {
+		if err := ValidateCurrency(currency); err != nil {
+			return Money{}, err
+		}
+		return Money{Amount: m.Amount, Currency: currency}, nil
+	}
+
+	if m.Currency != currency {
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrMoneyCurrencyMismatch, m.Currency, currency)
+	}
+
+	return m, nil
+}
+
+// Add returns the sum of both amounts, they must have the same currency
+func (m Money) Add(o Money) (Money, error) {
+	if err := m.sameCurrency(o); err != nil {
+		return Money{}, err
+	}
+
+	sum := m.Amount + o.Amount
+	if (o.Amount > 0 && sum < m.Amount) || (o.Amount < 0 && sum > m.Amount) {
+		return Money{}, ErrMoneyOverflow
+	}
+
+	return Money{Amount: sum, Currency: m.currency(o)}, nil
+}
+
+// Sub returns the difference of both amounts, they must have the same currency
+func (m Money) Sub(o Money) (Money, error) {
+	if o.Amount == math.MinInt64 {
+		return Money{}, ErrMoneyOverflow
+	}
+	return m.Add(o.Neg())
+}
+
+// Neg returns the money with the opposite sign
+func (m Money) Neg() Money {
+	if m.pending != nil {
+		return Money{pending: new(big.Rat).Neg(m.pending), approx: m.approx}
+	}
+	return Money{Amount: -m.Amount, Currency: m.Currency}
+}
+
+// Allocate splits the money by the ratios without losing cents
+// The remainder is spread one minor unit at a time from the first share on
+func (m Money) Allocate(ratios ...int) ([]Money, error) {
+	if len(ratios) == 0 {
+		return nil, errors.New("at least one ratio is required")
+	}
+
+	total := big.NewInt(0)
+	for _, r := range ratios {
+		if r < 0 {
+			return nil, errors.New("ratios cannot be negative")
+		}
+		total.Add(total, big.NewInt(int64(r)))
+	}
+	if total.Sign() == 0 {
+		return nil, errors.New("the sum of ratios must be greater than zero")
+	}
+
+	amount := big.NewInt(m.Amount)
+	abs := new(big.Int).Abs(amount)
+
+	shares := make([]Money, len(ratios))
+	allocated := big.NewInt(0)
+	for i, r := range ratios {
+		share := new(big.Int).Mul(abs, big.NewInt(int64(r)))
+		share.Quo(share, total)
+		allocated.Add(allocated, share)
+		shares[i] = Money{Amount: share.Int64(), Currency: m.Currency}
+	}
+
+	remainder := new(big.Int).Sub(abs, allocated).Int64()
+	for i := 0; remainder > 0; i = (i + 1) % len(shares) {
+		if ratios[i] == 0 {
+			continue
+		}
+		shares[i].Amount++
+		remainder--
+	}
+
+	if amount.Sign() < 0 {
+		for i := range shares {
+			shares[i].Amount = -shares[i].Amount
+		}
+	}
+
+	return shares, nil
+}
+
+func (m Money) IsZero() bool {
+	if m.pending != nil {
+		return m.pending.Sign() == 0
+	}
+	return m.Amount == 0
+}
+
+func (m Money) IsNegative() bool {
+	if m.pending != nil {
+		return m.pending.Sign() < 0
+	}
+	return m.Amount < 0
+}
+
+func (m Money) IsPositive() bool {
+	if m.pending != nil {
+		return m.pending.Sign() > 0
+	}
+	return m.Amount > 0
+}
+
+// Decimal returns the amount as a decimal string in the currency unit, e.g. "12.34"
+func (m Money) Decimal() string {
+	if m.pending != nil {
+		return strings.TrimRight(strings.TrimRight(m.pending.FloatString(20), "0"), ".")
+	}
+
+	exp := CurrencyExponent(m.Currency)
+	sign := ""
+	amount := new(big.Int).SetInt64(m.Amount)
+	if amount.Sign() < 0 {
+		sign = "-"
+		amount.Neg(amount)
+	}
+
+	digits := amount.String()
+	if exp == 0 {
+		return sign + digits
+	}
+	if len(digits) <= exp {
+		digits = strings.Repeat("0", exp-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-exp] + "." + digits[len(digits)-exp:]
+}
+
+// Float64 returns the amount in the currency unit, it is only exact for display and storage
+func (m Money) Float64() float64 {
+	f, _ := strconv.ParseFloat(m.Decimal(), 64)
+	return f
+}
+
+func (m Money) String() string {
+	if m.Currency == "" {
+		return m.Decimal()
+	}
+	return m.Decimal() + " " + m.Currency
+}
+
+// MarshalJSON writes the money as a decimal number, the same format of the old float fields
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.Decimal()), nil
+}
+
+// UnmarshalJSON reads a decimal number, a decimal string or an object with amount in minor units and currency
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		*m = Money{}
+		return nil
+	}
+
+	if len(data) > 0 && data[0] == '{' {
+		var obj struct {
+			Amount   int64  `json:"amount"`
+			Currency string `json:"currency"`
+		}
+		if err := json.Unmarshal(data, &obj); err != nil {
+			return err
+		}
+		if obj.Currency != "" {
+			if err := ValidateCurrency(strings.ToUpper(obj.Currency)); err != nil {
+				return err
+			}
+		}
+		*m = NewMoney(obj.Amount, obj.Currency)
+		return nil
+	}
+
+	value := string(data)
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &value); err != nil {
+			return err
+		}
+	}
+
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(value))
+	if !ok {
+		return ErrMoneyInvalidAmount
+	}
+
+	*m = Money{pending: r}
+	return nil
+}
+
+// DocumentValue stores the money as a decimal number, the same format of the old float fields
+func (m Money) DocumentValue() (any, error) {
+	return m.Float64(), nil
+}
+
+// ScanDocumentValue reads the decimal number stored in the document
+// The currency is bound by the container after the document is loaded
+func (m *Money) ScanDocumentValue(value any) error {
+	switch v := value.(type) {
+	case nil:
+		*m = Money{}
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return ErrMoneyInvalidAmount
+		}
+		r, _ := new(big.Rat).SetString(strconv.FormatFloat(v, 'f', -1, 64))
+		*m = Money{pending: r, approx: true}
+	case int64:
+		*m = Money{pending: new(big.Rat).SetInt64(v)}
+	case string:
+		r, ok := new(big.Rat).SetString(v)
+		if !ok {
+			return ErrMoneyInvalidAmount
+		}
+		*m = Money{pending: r}
+	case map[string]any:
+		amount, _ := v["amount"].(int64)
+		currency, _ := v["currency"].(string)
+		*m = NewMoney(amount, currency)
+	default:
+		return fmt.Errorf("%w: cannot read %T", ErrMoneyInvalidAmount, value)
+	}
+	return nil
+}
+
+func (m Money) sameCurrency(o Money) error {
+	if m.pending != nil || o.pending != nil {
+		return errors.New("money currency is not bound")
+	}
+	if m.Currency != "" && o.Currency != "" && m.Currency != o.Currency {
+		return fmt.Errorf("%w: %s and %s", ErrMoneyCurrencyMismatch, m.Currency, o.Currency)
+	}
+	return nil
+}
+
+func (m Money) currency(o Money) string {
+	if m.Currency != "" {
+		return m.Currency
+	}
+	return o.Currency
+}
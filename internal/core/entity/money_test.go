@@ -0,0 +1,134 @@
+package entity_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/stretchr/testify/suite"
+)
+
+type MoneyTestSuite struct {
+	suite.Suite
+}
+
+func (s *MoneyTestSuite) TestParseMoney() {
+	m, err := entity.ParseMoney("12.34", "brl")
+	s.Nil(err)
+	s.Equal(entity.NewMoney(1234, "BRL"), m)
+
+	m, err = entity.ParseMoney("1.234", "BHD")
+	s.Nil(err)
+	s.Equal(int64(1234), m.Amount)
+
+	m, err = entity.ParseMoney("100", "JPY")
+	s.Nil(err)
+	s.Equal(int64(100), m.Amount)
+}
+
+func (s *MoneyTestSuite) TestParseMoney_Error() {
+	_, err := entity.ParseMoney("12.345", "BRL")
+	s.ErrorIs(err, entity.ErrMoneyInvalidAmount)
+
+	_, err = entity.ParseMoney("12.3", "JPY")
+	s.ErrorIs(err, entity.ErrMoneyInvalidAmount)
+
+	_, err = entity.ParseMoney("abc", "BRL")
+	s.ErrorIs(err, entity.ErrMoneyInvalidAmount)
+
+	_, err = entity.ParseMoney("1", "real")
+	s.ErrorIs(err, entity.ErrMoneyInvalidCurrency)
+}
+
+func (s *MoneyTestSuite) TestMoneyFromFloat_Rounding() {
+	m, err := entity.MoneyFromFloat(0.1+0.2, "BRL")
+	s.Nil(err)
+	s.Equal(int64(30), m.Amount)
+
+	m, err = entity.MoneyFromFloat(-2.675, "BRL")
+	s.Nil(err)
+	s.Equal(int64(-268), m.Amount)
+}
+
+func (s *MoneyTestSuite) TestAddSub() {
+	a := entity.NewMoney(1010, "BRL")
+	b := entity.NewMoney(2020, "BRL")
+
+	sum, err := a.Add(b)
+	s.Nil(err)
+	s.Equal(entity.NewMoney(3030, "BRL"), sum)
+
+	diff, err := a.Sub(b)
+	s.Nil(err)
+	s.Equal(entity.NewMoney(-1010, "BRL"), diff)
+	s.True(diff.IsNegative())
+
+	_, err = a.Add(entity.NewMoney(1, "USD"))
+	s.ErrorIs(err, entity.ErrMoneyCurrencyMismatch)
+}
+
+func (s *MoneyTestSuite) TestAllocate() {
+	shares, err := entity.NewMoney(100, "BRL").Allocate(1, 1, 1)
+	s.Nil(err)
+	s.Equal([]entity.Money{entity.NewMoney(34, "BRL"), entity.NewMoney(33, "BRL"), entity.NewMoney(33, "BRL")}, shares)
+
+	shares, err = entity.NewMoney(-5, "BRL").Allocate(3, 7)
+	s.Nil(err)
+	s.Equal([]entity.Money{entity.NewMoney(-2, "BRL"), entity.NewMoney(-3, "BRL")}, shares)
+
+	_, err = entity.NewMoney(5, "BRL").Allocate()
+	s.NotNil(err)
+}
+
+func (s *MoneyTestSuite) TestDecimal() {
+	s.Equal("12.34", entity.NewMoney(1234, "BRL").Decimal())
+	s.Equal("-0.05", entity.NewMoney(-5, "BRL").Decimal())
+	s.Equal("1.005", entity.NewMoney(1005, "KWD").Decimal())
+	s.Equal("500", entity.NewMoney(500, "JPY").Decimal())
+	s.Equal("12.34 BRL", entity.NewMoney(1234, "BRL").String())
+}
+
+func (s *MoneyTestSuite) TestJSON() {
+	b, err := json.Marshal(entity.NewMoney(1234, "BRL"))
+	s.Nil(err)
+	s.Equal("12.34", string(b))
+
+	var m entity.Money
+	s.Nil(json.Unmarshal([]byte("12.34"), &m))
+	m, err = m.In("BRL")
+	s.Nil(err)
+	s.Equal(entity.NewMoney(1234, "BRL"), m)
+
+	s.Nil(json.Unmarshal([]byte(`"1.5"`), &m))
+	m, err = m.In("BRL")
+	s.Nil(err)
+	s.Equal(int64(150), m.Amount)
+
+	s.Nil(json.Unmarshal([]byte(`{"amount": 150, "currency": "usd"}`), &m))
+	s.Equal(entity.NewMoney(150, "USD"), m)
+
+	s.Nil(json.Unmarshal([]byte("12.345"), &m))
+	_, err = m.In("BRL")
+	s.ErrorIs(err, entity.ErrMoneyInvalidAmount)
+}
+
+func (s *MoneyTestSuite) TestDocumentValue() {
+	v, err := entity.NewMoney(1234, "BRL").DocumentValue()
+	s.Nil(err)
+	s.Equal(12.34, v)
+
+	var m entity.Money
+	s.Nil(m.ScanDocumentValue(12.34))
+	m, err = m.In("BRL")
+	s.Nil(err)
+	s.Equal(entity.NewMoney(1234, "BRL"), m)
+
+	s.Nil(m.ScanDocumentValue(int64(7)))
+	m, err = m.In("BRL")
+	s.Nil(err)
+	s.Equal(entity.NewMoney(700, "BRL"), m)
+}
+
+func TestRunMoneyTestSuite(t *testing.T) {
+	suite.Run(t, new(MoneyTestSuite))
+}
@@ -0,0 +1,78 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Limits of the page size of the list endpoints
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 200
+)
+
+// Directions of the order of a page
+const (
+	PageAsc  = "asc"
+	PageDesc = "desc"
+)
+
+// ErrInvalidPage is returned when the limit, order or cursor of a page is invalid
+var ErrInvalidPage = errors.New("invalid page")
+
+// PageRequest is the page of a list
+// Cursor is the next_cursor of the previous page, it is only valid with the same order
+type PageRequest struct {
+	Limit     int
+	OrderBy   string
+	Direction string
+	Cursor    string
+}
+
+// Page is the envelope of the list endpoints
+// Total is the number of items of the whole list, it is empty when the backend cannot count it cheaply
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	Total      *int64 `json:"total,omitempty"`
+}
+
+// NewPageRequest validates the parameters of a list, orderBy must be a sortable field
+// An empty limit is DefaultPageLimit and an empty orderBy orders by ID
+func NewPageRequest(limit, orderBy, direction, cursor string, fields Fields) (*PageRequest, error) {
+	page := &PageRequest{
+		Limit:     DefaultPageLimit,
+		OrderBy:   orderBy,
+		Direction: direction,
+		Cursor:    cursor,
+	}
+
+	if limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 || n > MaxPageLimit {
+			return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidPage, MaxPageLimit)
+		}
+		page.Limit = n
+	}
+
+	if orderBy != "" && !fields.Sortable(orderBy) {
+		return nil, fmt.Errorf("%w: cannot order by %s, the sortable fields are %s", ErrInvalidPage, orderBy, strings.Join(fields.sortableFields(), ", "))
+	}
+
+	switch direction {
+	case "":
+		page.Direction = PageAsc
+	case PageAsc, PageDesc:
+	default:
+		return nil, fmt.Errorf("%w: direction must be %s or %s", ErrInvalidPage, PageAsc, PageDesc)
+	}
+
+	return page, nil
+}
+
+// DefaultPage is the first page of a list ordered by ID
+func DefaultPage() *PageRequest {
+	return &PageRequest{Limit: DefaultPageLimit, Direction: PageAsc}
+}
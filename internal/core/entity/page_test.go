@@ -0,0 +1,41 @@
+package entity_test
+
+import (
+	"testing"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/stretchr/testify/suite"
+)
+
+type PageRequestTestSuite struct {
+	suite.Suite
+}
+
+func (s *PageRequestTestSuite) TestNewPageRequest() {
+	page, err := entity.NewPageRequest("", "", "", "", entity.WalletFields)
+	s.Nil(err)
+	s.Equal(&entity.PageRequest{Limit: entity.DefaultPageLimit, Direction: entity.PageAsc}, page)
+
+	page, err = entity.NewPageRequest("10", "name", "desc", "abc", entity.WalletFields)
+	s.Nil(err)
+	s.Equal(&entity.PageRequest{Limit: 10, OrderBy: "name", Direction: entity.PageDesc, Cursor: "abc"}, page)
+}
+
+func (s *PageRequestTestSuite) TestNewPageRequest_Invalid() {
+	params := [][]string{
+		{"0", "", ""},
+		{"201", "", ""},
+		{"ten", "", ""},
+		{"", "balance", ""},
+		{"", "unknown", ""},
+		{"", "name", "up"},
+	}
+	for _, p := range params {
+		_, err := entity.NewPageRequest(p[0], p[1], p[2], "", entity.WalletFields)
+		s.ErrorIs(err, entity.ErrInvalidPage, p)
+	}
+}
+
+func TestRunPageRequestTestSuite(t *testing.T) {
+	suite.Run(t, new(PageRequestTestSuite))
+}
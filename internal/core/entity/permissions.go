@@ -27,16 +27,22 @@ func (p *PermissionLevel) Validate() error {
 type Module string
 
 const (
-	ModuleUser    Module = "user"
-	ModuleTenant  Module = "tenant"
-	ModuleProduct Module = "product"
-	ModuleOrder   Module = "order"
-	ModuleInvoice Module = "invoice"
+	ModuleUser        Module = "user"
+	ModuleTenant      Module = "tenant"
+	ModuleProduct     Module = "product"
+	ModuleOrder       Module = "order"
+	ModuleInvoice     Module = "invoice"
+	ModulePlan        Module = "plan"
+	ModuleWallet      Module = "wallet"
+	ModuleTransaction Module = "transaction"
+	ModuleCategory    Module = "category"
+	// ModuleSystem is the key of the roles of the system levels, they apply to every module
+	ModuleSystem Module = "system"
 )
 
 func (m *Module) Validate() error {
 	switch *m {
-	case ModuleUser, ModuleTenant, ModuleProduct, ModuleOrder, ModuleInvoice:
+	case ModuleUser, ModuleTenant, ModuleProduct, ModuleOrder, ModuleInvoice, ModulePlan, ModuleWallet, ModuleTransaction, ModuleCategory, ModuleSystem:
 		return nil
 	default:
 		return errors.New("invalid module")
@@ -0,0 +1,169 @@
+package entity
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// PersonalAccessTokenPrefix starts every personal access token, the middleware tells them from the JWTs by it
+	PersonalAccessTokenPrefix = "pat_"
+	// PersonalAccessTokenNameMaxLength bounds the name of a personal access token, in characters
+	PersonalAccessTokenNameMaxLength = 100
+	// PersonalAccessTokenLastUsedInterval is how often the last use of a token is written, not on every request
+	PersonalAccessTokenLastUsedInterval = time.Minute
+)
+
+// ErrPersonalAccessTokenNotFound is returned when the personal access token is not of the user
+var ErrPersonalAccessTokenNotFound = errors.New("personal access token not found")
+
+// IPersonalAccessToken is the personal access tokens of the users, for scripts and automation
+// Create, List, Revoke, Authenticate
+type IPersonalAccessToken interface {
+	// Create returns the token once, only its hash is stored
+	Create(ctx context.Context, userID string, request *PersonalAccessTokenRequest) (*PersonalAccessTokenCreated, error)
+	List(ctx context.Context, userID string) ([]PersonalAccessToken, error)
+	Revoke(ctx context.Context, userID, id string) error
+	// Authenticate returns the principal of the token, limited to the scopes of the token
+	// The error wraps ErrUnauthorized when the token is unknown or expired
+	Authenticate(ctx context.Context, token string) (*Principal, error)
+}
+
+// TokenScope is a level of a module granted to a personal access token
+// The token never has more than its user, a scope only narrows the roles of the user
+type TokenScope struct {
+	Module Module          `json:"module" firestore:"module"`
+	Level  PermissionLevel `json:"level" firestore:"level"`
+}
+
+// PersonalAccessToken is the stored record of a personal access token
+// TokenHash is the SHA-256 of the token, the token itself is never stored
+// A zero ExpiresAt is a token that does not expire
+type PersonalAccessToken struct {
+	ID         string       `json:"id" firestore:"id"`
+	TokenHash  string       `json:"-" firestore:"token_hash"`
+	UserID     string       `json:"user_id" firestore:"user_id"`
+	Name       string       `json:"name" firestore:"name"`
+	Scopes     []TokenScope `json:"scopes" firestore:"scopes"`
+	ExpiresAt  time.Time    `json:"expires_at" firestore:"expires_at"`
+	LastUsedAt time.Time    `json:"last_used_at" firestore:"last_used_at"`
+	CreatedAt  time.Time    `json:"created_at" firestore:"create_at"`
+}
+
+type PersonalAccessTokenRequest struct {
+	Name      string       `json:"name" binding:"required"`
+	Scopes    []TokenScope `json:"scopes" binding:"required"`
+	ExpiresAt *time.Time   `json:"expires_at"`
+}
+
+// PersonalAccessTokenCreated is the response of the creation, the only time the token is shown
+type PersonalAccessTokenCreated struct {
+	Token string `json:"token"`
+	PersonalAccessToken
+}
+
+func (s *TokenScope) Validate() error {
+	if err := s.Module.Validate(); err != nil {
+		return err
+	}
+	if s.Module == ModuleSystem {
+		if _, ok := systemRank[s.Level]; !ok {
+			return errors.New("invalid permission")
+		}
+		return nil
+	}
+	return s.Level.Validate()
+}
+
+func (r *PersonalAccessTokenRequest) Validate(now time.Time) error {
+	if r == nil {
+		return errors.New("request is required")
+	}
+
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" {
+		return errors.New("name is required")
+	}
+	if utf8.RuneCountInString(r.Name) > PersonalAccessTokenNameMaxLength {
+		return errors.New("name must have at most 100 characters")
+	}
+
+	if len(r.Scopes) == 0 {
+		return errors.New("at least one scope is required")
+	}
+	for i := range r.Scopes {
+		if err := r.Scopes[i].Validate(); err != nil {
+			return err
+		}
+	}
+
+	if r.ExpiresAt != nil && !r.ExpiresAt.After(now) {
+		return errors.New("expires_at must be in the future")
+	}
+	return nil
+}
+
+// NewPersonalAccessToken creates the personal access token of the user
+// It returns the token, shown once to the user, and its record
+func NewPersonalAccessToken(userID string, request *PersonalAccessTokenRequest) (string, *PersonalAccessToken, error) {
+	if userID == "" {
+		return "", nil, errors.New("user id is required")
+	}
+
+	now := time.Now().UTC()
+	if err := request.Validate(now); err != nil {
+		return "", nil, err
+	}
+
+	opaque, err := newOpaqueToken()
+	if err != nil {
+		return "", nil, err
+	}
+	token := PersonalAccessTokenPrefix + opaque
+
+	id, _ := uuid.NewV7()
+	record := &PersonalAccessToken{
+		ID:        id.String(),
+		TokenHash: PersonalAccessTokenHash(token),
+		UserID:    userID,
+		Name:      request.Name,
+		Scopes:    request.Scopes,
+		CreatedAt: now,
+	}
+	if request.ExpiresAt != nil {
+		record.ExpiresAt = request.ExpiresAt.UTC()
+	}
+	return token, record, nil
+}
+
+// IsPersonalAccessToken reports if the bearer token is a personal access token
+func IsPersonalAccessToken(token string) bool {
+	return strings.HasPrefix(token, PersonalAccessTokenPrefix)
+}
+
+// PersonalAccessTokenHash returns the hash of the personal access token, the stored records are found by it
+func PersonalAccessTokenHash(token string) string {
+	return opaqueTokenID(token)
+}
+
+// IsExpired reports if the token expired at the time, a token without expiry does not
+func (t *PersonalAccessToken) IsExpired(now time.Time) bool {
+	return !t.ExpiresAt.IsZero() && !now.Before(t.ExpiresAt)
+}
+
+// scopePolicy checks the scopes of a token, they grant nothing by default
+var scopePolicy = &Policy{}
+
+// scopesAllow reports if the scopes grant the level on the module, the same way the roles do
+func scopesAllow(scopes []TokenScope, module Module, level PermissionLevel) bool {
+	roles := make([]AccountRoles, len(scopes))
+	for i, scope := range scopes {
+		roles[i] = AccountRoles{Key: string(scope.Module), Value: string(scope.Level)}
+	}
+	return scopePolicy.Allowed(roles, module, level)
+}
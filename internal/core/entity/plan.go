@@ -3,19 +3,31 @@ package entity
 import (
 	"errors"
 	"reflect"
+	"strings"
 	"time"
 
 	"github.com/Tomelin/financial-management-backend/pkg/utils"
 	"github.com/google/uuid"
 )
 
+// PlanFields are the fields of the plan that can be filtered and sorted
+var PlanFields = Fields{
+	"id":          {Type: FieldString},
+	"name":        {Type: FieldString, Sortable: true},
+	"description": {Type: FieldString},
+	"price":       {Type: FieldNumber},
+	"currency":    {Type: FieldString},
+	"create_at":   {Type: FieldTime, Sortable: true},
+	"update_at":   {Type: FieldTime, Sortable: true},
+}
+
 type IPlan interface {
 	Create(*PlanResponse) (*PlanResponse, error)
-	Get() ([]PlanResponse, error)
+	Get(page *PageRequest) (*Page[PlanResponse], error)
 	GetById(id *string) (*PlanResponse, error)
 	Update(data *PlanResponse) (*PlanResponse, error)
 	Delete(id *string) error
-	GetByFilterMany(key string, value *string) ([]PlanResponse, error)
+	GetByFilterMany(filter []QueryDBClause) ([]PlanResponse, error)
 	GetByFilterOne(key string, value *string) (*PlanResponse, error)
 }
 
@@ -33,7 +45,8 @@ type PlanResponse struct {
 	Name        string         `json:"name" binding:"required" firestore:"name"`
 	Description string         `json:"description" firestore:"description"`
 	Features    []PlanFeatures `json:"features"  firestore:"features"`
-	Price       float64        `json:"price" firestore:"price"`
+	Price       Money          `json:"price" firestore:"price"`
+	Currency    string         `json:"currency" firestore:"currency"`
 }
 
 func NewPlan(plan *PlanResponse) (*PlanResponse, error) {
@@ -60,16 +73,60 @@ func (p *PlanResponse) Validate() error {
 		return errors.New("plan name is required")
 	}
 
-	if p.Name != "bronze" {
-		if len(p.Features) == 0 {
-			return errors.New("features are required")
+	if len(p.Features) == 0 {
+		return errors.New("features are required")
+	}
+
+	names := make(map[string]bool, len(p.Features))
+	for i := range p.Features {
+		if err := p.Features[i].Validate(); err != nil {
+			return err
+		}
+		if names[p.Features[i].Name] {
+			return errors.New("feature name is duplicated")
 		}
+		names[p.Features[i].Name] = true
 	}
 
 	if err := utils.ValidateUUID(&p.ID); err != nil {
 		return errors.New("invalid ID")
 	}
 
+	if p.Currency == "" {
+		p.Currency = DefaultCurrency
+	}
+
+	p.Currency = strings.ToUpper(p.Currency)
+	if err := ValidateCurrency(p.Currency); err != nil {
+		return err
+	}
+
+	price, err := p.Price.In(p.Currency)
+	if err != nil {
+		return err
+	}
+
+	if price.IsNegative() {
+		return errors.New("price cannot be negative")
+	}
+	p.Price = price
+
+	return nil
+}
+
+// AfterDocumentLoad binds the price read from the database to the plan currency
+// Plans saved before the currency field existed are in the default currency
+func (p *PlanResponse) AfterDocumentLoad() error {
+	if p.Currency == "" {
+		p.Currency = DefaultCurrency
+	}
+
+	price, err := p.Price.In(p.Currency)
+	if err != nil {
+		return err
+	}
+	p.Price = price
+
 	return nil
 }
 
@@ -87,6 +144,10 @@ func (p *PlanFeatures) Validate() error {
 		return errors.New("plan name is required")
 	}
 
+	if p.Count < 0 {
+		return errors.New("feature count cannot be negative")
+	}
+
 	return nil
 }
 
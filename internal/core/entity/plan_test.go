@@ -27,7 +27,7 @@ func (s *PlanTestSuite) SetupTest() {
 		ID:          uuid.New().String(),
 		Name:        "Bronze",
 		Description: "Bronze plan",
-		Price:       0.0,
+		Price:       entity.NewMoney(0, "BRL"),
 		Features:    []entity.PlanFeatures{*s.features},
 		CreatedAt:   time.Now().UTC(),
 		UpdatedAt:   time.Now().UTC(),
@@ -129,6 +129,42 @@ func (s *PlanTestSuite) TestNewPlan_Success() {
 	s.Nil(err)
 }
 
+func (s *PlanTestSuite) TestNewPlan_Error_Features() {
+
+	s.planResponse.Features = append(s.planResponse.Features, *s.features)
+	_, err := entity.NewPlan(s.planResponse)
+	s.EqualError(err, "feature name is duplicated")
+
+	s.planResponse.Features = []entity.PlanFeatures{{Name: entity.FeatureWalletsMax, Count: -1}}
+	_, err = entity.NewPlan(s.planResponse)
+	s.EqualError(err, "feature count cannot be negative")
+}
+
+func (s *PlanTestSuite) TestEntitlement() {
+
+	s.planResponse.Features = []entity.PlanFeatures{
+		{Name: entity.FeatureWalletsMax, Count: 3},
+		{Name: entity.FeatureWalletSharesMax, Unlimited: true},
+	}
+
+	wallets := s.planResponse.Entitlement(entity.FeatureWalletsMax)
+	s.Equal(entity.Entitlement{Key: entity.FeatureWalletsMax, Limit: 3}, wallets)
+	s.Nil(wallets.Check(2))
+	mErr := wallets.Check(3)
+	s.Require().NotNil(mErr)
+	s.Equal(entity.ResponseCodeBadRequest, mErr.Code)
+	s.Equal("limit reached: wallets.max usage 3 of 3", mErr.Err)
+
+	shares := s.planResponse.Entitlement(entity.FeatureWalletSharesMax)
+	s.Nil(shares.Check(100))
+
+	categories := s.planResponse.Entitlement(entity.FeatureCategoriesCustomMax)
+	s.True(categories.Unlimited, "a feature the plan does not have falls back to the default")
+
+	unknown := s.planResponse.Entitlement("unknown")
+	s.NotNil(unknown.Check(0), "an unknown key allows nothing")
+}
+
 func TestRunPlanTestSuite(t *testing.T) {
 	suite.Run(t, new(PlanTestSuite))
 }
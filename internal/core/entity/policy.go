@@ -0,0 +1,137 @@
+package entity
+
+import "errors"
+
+// ErrForbidden is returned when the roles of the user do not grant the permission
+var ErrForbidden = errors.New("forbidden")
+
+// levelRank orders the levels of a module, a level grants the levels below it
+var levelRank = map[PermissionLevel]int{
+	PermissionView:  1,
+	PermissionEdit:  2,
+	PermissionAdmin: 3,
+	PermissionOwner: 4,
+}
+
+// systemRank orders the system levels
+var systemRank = map[PermissionLevel]int{
+	PermissionSystemView:  1,
+	PermissionSystemAdmin: 2,
+}
+
+// Policy decides which levels the roles of a user grant on each module
+//
+// A role is an AccountRoles with the module as Key and the level as Value, the system levels have the Key system.
+// The role of a module sets the level of the user on it, the modules without role fall back to the level of the membership:
+// Owner for the owner of the tenant and Defaults for the other members.
+// system-admin grants every level of every module, system-view grants view on every module.
+// On a wallet the level of the user on the wallet replaces the level of the membership, see CanOnWallet.
+type Policy struct {
+	Defaults map[Module]PermissionLevel
+	Owner    map[Module]PermissionLevel
+}
+
+// NewPolicy creates the policy of the application, the members view the resources of the tenant and its owner owns them
+func NewPolicy() *Policy {
+	return &Policy{
+		Defaults: map[Module]PermissionLevel{
+			ModuleUser:        PermissionView,
+			ModuleTenant:      PermissionView,
+			ModuleWallet:      PermissionView,
+			ModuleTransaction: PermissionView,
+			ModuleCategory:    PermissionView,
+			ModulePlan:        PermissionView,
+		},
+		Owner: map[Module]PermissionLevel{
+			ModuleUser:        PermissionOwner,
+			ModuleTenant:      PermissionOwner,
+			ModuleWallet:      PermissionOwner,
+			ModuleTransaction: PermissionOwner,
+			ModuleCategory:    PermissionOwner,
+			ModulePlan:        PermissionView,
+		},
+	}
+}
+
+// DefaultPolicy is the policy enforced by the Require middleware
+var DefaultPolicy = NewPolicy()
+
+// Allowed reports if the roles of a member of the tenant grant the level on the module
+func (p *Policy) Allowed(roles []AccountRoles, module Module, level PermissionLevel) bool {
+	return p.allowed(roles, p.Defaults, module, level)
+}
+
+// OwnerAllowed reports if the roles of the owner of the tenant grant the level on the module
+func (p *Policy) OwnerAllowed(roles []AccountRoles, module Module, level PermissionLevel) bool {
+	return p.allowed(roles, p.Owner, module, level)
+}
+
+func (p *Policy) allowed(roles []AccountRoles, defaults map[Module]PermissionLevel, module Module, level PermissionLevel) bool {
+	system := 0
+	for _, role := range roles {
+		if Module(role.Key) == ModuleSystem && systemRank[PermissionLevel(role.Value)] > system {
+			system = systemRank[PermissionLevel(role.Value)]
+		}
+	}
+
+	if want, ok := systemRank[level]; ok {
+		return system >= want
+	}
+
+	want, ok := levelRank[level]
+	if !ok {
+		return false
+	}
+	if system == systemRank[PermissionSystemAdmin] || (system == systemRank[PermissionSystemView] && level == PermissionView) {
+		return true
+	}
+
+	granted, assigned := assignedRank(roles, module)
+	if !assigned {
+		granted = levelRank[defaults[module]]
+	}
+	return granted >= want
+}
+
+// assignedRank returns the highest level of the roles assigned on the module, and if there is one
+func assignedRank(roles []AccountRoles, module Module) (int, bool) {
+	granted, assigned := 0, false
+	for _, role := range roles {
+		if Module(role.Key) == module {
+			assigned = true
+			if levelRank[PermissionLevel(role.Value)] > granted {
+				granted = levelRank[PermissionLevel(role.Value)]
+			}
+		}
+	}
+	return granted, assigned
+}
+
+// Can reports if the principal has the level on the module, by the DefaultPolicy
+// The principal of a personal access token needs a scope of the level as well
+func (p *Principal) Can(module Module, level PermissionLevel) bool {
+	allowed := DefaultPolicy.Allowed
+	if p.TenantOwner {
+		allowed = DefaultPolicy.OwnerAllowed
+	}
+	if !allowed(p.Roles, module, level) {
+		return false
+	}
+	return p.Scopes == nil || scopesAllow(p.Scopes, module, level)
+}
+
+// CanOnWallet reports if the principal has the level on the module of the wallet
+// The level of the user on the wallet grants it instead of the level of the membership,
+// a role assigned on the module and the scopes of a personal access token still limit it
+func (p *Principal) CanOnWallet(wallet *WalletResponse, module Module, level PermissionLevel) bool {
+	return wallet.Allows(p.User.ID, p.TenantID, level) && p.RoleAllows(module, level)
+}
+
+// RoleAllows reports if the role assigned on the module and the scopes of a personal access token allow the level
+// A module without role assigned does not limit the level
+func (p *Principal) RoleAllows(module Module, level PermissionLevel) bool {
+	if granted, assigned := assignedRank(p.Roles, module); assigned && granted < levelRank[level] {
+		return false
+	}
+	return p.Scopes == nil || scopesAllow(p.Scopes, module, level)
+}
@@ -0,0 +1,120 @@
+package entity_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+)
+
+type PolicyTestSuite struct {
+	suite.Suite
+	policy *entity.Policy
+}
+
+func (s *PolicyTestSuite) SetupTest() {
+	s.policy = entity.NewPolicy()
+}
+
+func role(module entity.Module, level entity.PermissionLevel) entity.AccountRoles {
+	return entity.AccountRoles{Key: string(module), Value: string(level)}
+}
+
+func (s *PolicyTestSuite) TestDefaults() {
+	s.True(s.policy.Allowed(nil, entity.ModuleWallet, entity.PermissionView), "a member views the resources of the tenant")
+	s.False(s.policy.Allowed(nil, entity.ModuleWallet, entity.PermissionEdit))
+	s.True(s.policy.OwnerAllowed(nil, entity.ModuleWallet, entity.PermissionOwner), "the owner owns the resources of the tenant")
+	s.False(s.policy.OwnerAllowed(nil, entity.ModulePlan, entity.PermissionEdit))
+	s.True(s.policy.Allowed(nil, entity.ModulePlan, entity.PermissionView))
+	s.False(s.policy.Allowed(nil, entity.ModulePlan, entity.PermissionEdit))
+	s.False(s.policy.Allowed(nil, entity.ModuleInvoice, entity.PermissionView), "a module without default needs a role")
+	s.False(s.policy.Allowed(nil, entity.ModuleUser, entity.PermissionSystemView))
+}
+
+func (s *PolicyTestSuite) TestModuleRoles() {
+	roles := []entity.AccountRoles{role(entity.ModuleInvoice, entity.PermissionEdit)}
+
+	s.True(s.policy.Allowed(roles, entity.ModuleInvoice, entity.PermissionView), "a level grants the levels below it")
+	s.True(s.policy.Allowed(roles, entity.ModuleInvoice, entity.PermissionEdit))
+	s.False(s.policy.Allowed(roles, entity.ModuleInvoice, entity.PermissionAdmin))
+	s.False(s.policy.Allowed(roles, entity.ModuleOrder, entity.PermissionView), "a role grants only its module")
+	s.False(s.policy.Allowed([]entity.AccountRoles{role(entity.ModuleInvoice, "root")}, entity.ModuleInvoice, entity.PermissionView))
+}
+
+func (s *PolicyTestSuite) TestRoleSetsTheLevel() {
+	view := []entity.AccountRoles{role(entity.ModuleWallet, entity.PermissionView)}
+	s.True(s.policy.OwnerAllowed(view, entity.ModuleWallet, entity.PermissionView))
+	s.False(s.policy.OwnerAllowed(view, entity.ModuleWallet, entity.PermissionEdit), "the role restricts the owner")
+	s.True(s.policy.OwnerAllowed(view, entity.ModuleTransaction, entity.PermissionEdit), "the other modules keep the owner level")
+
+	edit := []entity.AccountRoles{role(entity.ModuleWallet, entity.PermissionEdit)}
+	s.True(s.policy.Allowed(edit, entity.ModuleWallet, entity.PermissionEdit), "the role raises the member")
+	s.False(s.policy.Allowed(edit, entity.ModuleTransaction, entity.PermissionEdit))
+}
+
+func (s *PolicyTestSuite) TestSystemRoles() {
+	admin := []entity.AccountRoles{role(entity.ModuleSystem, entity.PermissionSystemAdmin)}
+	view := []entity.AccountRoles{role(entity.ModuleSystem, entity.PermissionSystemView)}
+
+	s.True(s.policy.Allowed(admin, entity.ModuleUser, entity.PermissionSystemAdmin))
+	s.True(s.policy.Allowed(admin, entity.ModuleUser, entity.PermissionSystemView))
+	s.True(s.policy.Allowed(admin, entity.ModuleInvoice, entity.PermissionOwner))
+
+	s.False(s.policy.Allowed(view, entity.ModuleUser, entity.PermissionSystemAdmin))
+	s.True(s.policy.Allowed(view, entity.ModuleUser, entity.PermissionSystemView))
+	s.True(s.policy.Allowed(view, entity.ModuleInvoice, entity.PermissionView))
+	s.False(s.policy.Allowed(view, entity.ModuleInvoice, entity.PermissionEdit))
+
+	// a system level is granted only by the system key
+	s.False(s.policy.Allowed([]entity.AccountRoles{role(entity.ModuleUser, entity.PermissionSystemAdmin)}, entity.ModuleUser, entity.PermissionSystemAdmin))
+}
+
+func (s *PolicyTestSuite) TestPrincipalCan() {
+	principal := &entity.Principal{Roles: []entity.AccountRoles{role(entity.ModuleSystem, entity.PermissionSystemAdmin)}}
+	s.True(principal.Can(entity.ModuleUser, entity.PermissionSystemAdmin))
+	s.False((&entity.Principal{}).Can(entity.ModuleUser, entity.PermissionSystemAdmin))
+
+	s.False((&entity.Principal{}).Can(entity.ModuleWallet, entity.PermissionEdit), "a member does not edit by default")
+	s.True((&entity.Principal{TenantOwner: true}).Can(entity.ModuleWallet, entity.PermissionEdit))
+}
+
+func (s *PolicyTestSuite) TestPrincipalScopes() {
+	principal := &entity.Principal{TenantOwner: true, Scopes: []entity.TokenScope{{Module: entity.ModuleTransaction, Level: entity.PermissionEdit}}}
+	s.True(principal.Can(entity.ModuleTransaction, entity.PermissionView))
+	s.True(principal.Can(entity.ModuleTransaction, entity.PermissionEdit))
+	s.False(principal.Can(entity.ModuleTransaction, entity.PermissionOwner), "the scope limits the roles of the user")
+	s.False(principal.Can(entity.ModuleWallet, entity.PermissionView), "a scope grants only its module")
+
+	// a scope does not grant what the user does not have
+	principal.Scopes = []entity.TokenScope{{Module: entity.ModuleSystem, Level: entity.PermissionSystemAdmin}}
+	s.False(principal.Can(entity.ModuleUser, entity.PermissionSystemAdmin))
+	s.True(principal.Can(entity.ModuleWallet, entity.PermissionOwner))
+
+	s.False((&entity.Principal{Scopes: []entity.TokenScope{}}).Can(entity.ModulePlan, entity.PermissionView), "no scope grants nothing")
+}
+
+func (s *PolicyTestSuite) TestPrincipalCanOnWallet() {
+	wallet := &entity.WalletResponse{OwnerID: "owner", TenantID: "tenant", SharedWithTenants: []string{"member-tenant"},
+		Permissions: []entity.WalletPermission{{TennantID: "member-tenant", Level: entity.PermissionEdit}}}
+	member := &entity.Principal{User: entity.AccountUser{ID: "member"}, TenantID: "member-tenant"}
+
+	s.False(member.Can(entity.ModuleTransaction, entity.PermissionEdit))
+	s.True(member.CanOnWallet(wallet, entity.ModuleTransaction, entity.PermissionEdit), "the share of the wallet grants edit")
+	s.False(member.CanOnWallet(wallet, entity.ModuleTransaction, entity.PermissionAdmin))
+
+	member.Roles = []entity.AccountRoles{role(entity.ModuleTransaction, entity.PermissionView)}
+	s.False(member.CanOnWallet(wallet, entity.ModuleTransaction, entity.PermissionEdit), "the assigned role limits the wallet")
+	s.True(member.CanOnWallet(wallet, entity.ModuleTransaction, entity.PermissionView))
+
+	member.Roles = nil
+	member.Scopes = []entity.TokenScope{{Module: entity.ModuleTransaction, Level: entity.PermissionView}}
+	s.False(member.CanOnWallet(wallet, entity.ModuleTransaction, entity.PermissionEdit), "the scope limits the wallet")
+
+	other := &entity.Principal{User: entity.AccountUser{ID: "other"}, TenantID: "other-tenant", TenantOwner: true}
+	s.False(other.CanOnWallet(wallet, entity.ModuleTransaction, entity.PermissionView), "the membership does not grant a wallet")
+}
+
+func TestPolicyTestSuite(t *testing.T) {
+	suite.Run(t, new(PolicyTestSuite))
+}
@@ -0,0 +1,73 @@
+package entity
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrUnauthorized is returned when the token of the request is missing, invalid, expired or revoked
+var ErrUnauthorized = errors.New("unauthorized")
+
+// PrincipalKey is the key of the principal in the Gin context
+const PrincipalKey = "principal"
+
+type principalContextKey struct{}
+
+// Principal is the authenticated user of a request
+// It is loaded once by the authentication middleware from the JWT and the user repository
+type Principal struct {
+	User      AccountUser    `json:"user"`
+	TenantID  string         `json:"tenant_id"`
+	Roles     []AccountRoles `json:"roles"`
+	TokenID   string         `json:"token_id"`
+	SessionID string         `json:"session_id,omitempty"`
+	ExpiresAt time.Time      `json:"expires_at"`
+	// Scopes limit the principal of a personal access token, they are nil for the JWTs
+	Scopes []TokenScope `json:"scopes,omitempty"`
+	// TenantOwner is set by the authentication middleware when the user owns the tenant
+	TenantOwner bool `json:"tenant_owner"`
+}
+
+// NewPrincipal creates the principal of the user authenticated by the claims
+func NewPrincipal(user *AccountUser, claims *AuthorizationClaims) (*Principal, error) {
+	if user == nil || user.ID == "" {
+		return nil, errors.New("user is required")
+	}
+
+	principal := &Principal{
+		User:     *user,
+		TenantID: user.TenantID,
+		Roles:    user.Roles,
+	}
+
+	if claims != nil {
+		principal.TokenID = claims.Id
+		principal.SessionID = claims.SessionID
+		if claims.ExpiresAt != 0 {
+			principal.ExpiresAt = time.Unix(claims.ExpiresAt, 0)
+		}
+	}
+
+	return principal, nil
+}
+
+// Email returns the email of the authenticated user
+func (p *Principal) Email() string {
+	return p.User.Email
+}
+
+// WithPrincipal returns a copy of ctx that carries the principal
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal stored by WithPrincipal
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+
+	principal, ok := ctx.Value(principalContextKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
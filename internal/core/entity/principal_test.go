@@ -0,0 +1,67 @@
+package entity_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+)
+
+type PrincipalTestSuite struct {
+	suite.Suite
+}
+
+func (s *PrincipalTestSuite) TestNewPrincipal() {
+	user := &entity.AccountUser{
+		ID:       "user-id",
+		TenantID: "tenant-id",
+		Roles:    []entity.AccountRoles{{Key: "wallet", Value: "admin"}},
+		User:     entity.User{Email: "user@domain.com"},
+	}
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	principal, err := entity.NewPrincipal(user, &entity.AuthorizationClaims{
+		StandardClaims: jwt.StandardClaims{Id: "token-id", ExpiresAt: expiresAt.Unix()},
+	})
+	s.Require().NoError(err)
+	s.Equal("tenant-id", principal.TenantID)
+	s.Equal(user.Roles, principal.Roles)
+	s.Equal("token-id", principal.TokenID)
+	s.True(expiresAt.Equal(principal.ExpiresAt))
+	s.Equal("user@domain.com", principal.Email())
+
+	_, err = entity.NewPrincipal(&entity.AccountUser{}, nil)
+	s.Error(err)
+}
+
+func (s *PrincipalTestSuite) TestContext() {
+	_, ok := entity.PrincipalFromContext(context.Background())
+	s.False(ok)
+
+	principal := &entity.Principal{User: entity.AccountUser{ID: "user-id"}}
+	got, ok := entity.PrincipalFromContext(entity.WithPrincipal(context.Background(), principal))
+	s.True(ok)
+	s.Same(principal, got)
+}
+
+func (s *PrincipalTestSuite) TestBearerToken() {
+	tests := map[string]string{
+		"Bearer abc":   "abc",
+		"bearer abc":   "abc",
+		" Bearer  abc": "abc",
+		"abc":          "abc",
+		"Bearer ":      "",
+		"":             "",
+	}
+	for header, want := range tests {
+		s.Equal(want, entity.BearerToken(header), header)
+	}
+}
+
+func TestPrincipalTestSuite(t *testing.T) {
+	suite.Run(t, new(PrincipalTestSuite))
+}
@@ -0,0 +1,219 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidQueryFilter is returned when the filter of the query string cannot be parsed
+var ErrInvalidQueryFilter = errors.New("invalid filter")
+
+// maxQueryFilterDepth limits the nested groups of a filter
+const maxQueryFilterDepth = 5
+
+// queryFilterOperators are the operators of the query string and their Firestore conditions
+var queryFilterOperators = map[string]QueryFirebase{
+	"eq":           QueryFirebaseEqual,
+	"ne":           QueryFirebaseNotEqual,
+	"lt":           QueryFirebaseLessThan,
+	"lte":          QueryFirebaseLessThanOrEqual,
+	"gt":           QueryFirebaseGreaterThan,
+	"gte":          QueryFirebaseGreaterThanOrEqual,
+	"in":           QueryFirebaseIn,
+	"nin":          QueryFirebaseNotIn,
+	"contains":     QueryFirebaseArrayContains,
+	"contains-any": QueryFirebaseArrayContainsAny,
+}
+
+// ParseQueryFilter parses the filter of a query string
+//
+//	filter = group | condition
+//	group  = ("and" | "or") "(" filter { "," filter } ")"
+//	condition = key ":" operator ":" value
+//
+// The operators are eq, ne, lt, lte, gt, gte, in, nin, contains and contains-any.
+// The items of in, nin and contains-any are separated by "|".
+// A value with "," "(" ")" or a double quote is written between double quotes, \" and \\ are escapes.
+//
+// Example: or(name:eq:Nubank,and(balance:gte:100,currency:in:BRL|USD))
+func ParseQueryFilter(filter string) (*QueryDBClause, error) {
+	p := &queryFilterParser{input: filter}
+
+	clause, query, err := p.parse(0)
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpaces()
+	if p.pos < len(p.input) {
+		return nil, p.errorf("unexpected %q", p.input[p.pos])
+	}
+
+	if query != nil {
+		return &QueryDBClause{Clause: QueryClauseAnd, Queries: []QueryDB{*query}}, nil
+	}
+	return clause, nil
+}
+
+// ParseQueryFilters parses the filters of a query string, the filters are joined by AND
+func ParseQueryFilters(filters []string) ([]QueryDBClause, error) {
+	clauses := make([]QueryDBClause, 0, len(filters))
+	for _, filter := range filters {
+		clause, err := ParseQueryFilter(filter)
+		if err != nil {
+			return nil, err
+		}
+		clauses = append(clauses, *clause)
+	}
+	return clauses, nil
+}
+
+type queryFilterParser struct {
+	input string
+	pos   int
+}
+
+func (p *queryFilterParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("%w at position %d: %s", ErrInvalidQueryFilter, p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *queryFilterParser) skipSpaces() {
+	for p.pos < len(p.input) && p.input[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+// parse reads a group or a condition, only one of them is returned
+func (p *queryFilterParser) parse(depth int) (*QueryDBClause, *QueryDB, error) {
+	p.skipSpaces()
+
+	for _, clause := range []QueryClause{QueryClauseAnd, QueryClauseOr} {
+		if strings.HasPrefix(p.input[p.pos:], string(clause)+"(") {
+			p.pos += len(clause) + 1
+			group, err := p.group(clause, depth+1)
+			return group, nil, err
+		}
+	}
+
+	query, err := p.condition()
+	return nil, query, err
+}
+
+func (p *queryFilterParser) group(clause QueryClause, depth int) (*QueryDBClause, error) {
+	if depth > maxQueryFilterDepth {
+		return nil, p.errorf("more than %d nested groups", maxQueryFilterDepth)
+	}
+
+	group := &QueryDBClause{Clause: clause}
+	for {
+		child, query, err := p.parse(depth)
+		if err != nil {
+			return nil, err
+		}
+
+		if query != nil {
+			group.Queries = append(group.Queries, *query)
+		} else {
+			group.Clauses = append(group.Clauses, *child)
+		}
+
+		p.skipSpaces()
+		if p.pos >= len(p.input) {
+			return nil, p.errorf("missing )")
+		}
+
+		switch p.input[p.pos] {
+		case ',':
+			p.pos++
+		case ')':
+			p.pos++
+			return group, nil
+		default:
+			return nil, p.errorf("unexpected %q", p.input[p.pos])
+		}
+	}
+}
+
+func (p *queryFilterParser) condition() (*QueryDB, error) {
+	key, err := p.token(':')
+	if err != nil {
+		return nil, err
+	}
+	if key == "" {
+		return nil, p.errorf("key is required")
+	}
+	if p.pos >= len(p.input) || p.input[p.pos] != ':' {
+		return nil, p.errorf("missing operator of %s", key)
+	}
+	p.pos++
+
+	name, err := p.token(':')
+	if err != nil {
+		return nil, err
+	}
+	operator, ok := queryFilterOperators[name]
+	if !ok {
+		return nil, p.errorf("unknown operator %q", name)
+	}
+	if p.pos >= len(p.input) || p.input[p.pos] != ':' {
+		return nil, p.errorf("missing value of %s", key)
+	}
+	p.pos++
+
+	value, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	if value == "" {
+		return nil, p.errorf("value of %s is required", key)
+	}
+
+	switch operator {
+	case QueryFirebaseIn, QueryFirebaseNotIn, QueryFirebaseArrayContainsAny:
+		value = strings.ReplaceAll(value, "|", ",")
+	}
+
+	return &QueryDB{Key: key, Value: value, Condition: string(operator)}, nil
+}
+
+// token reads until the separator or the end of a group
+func (p *queryFilterParser) token(separator byte) (string, error) {
+	start := p.pos
+	for p.pos < len(p.input) {
+		switch c := p.input[p.pos]; c {
+		case separator, ',', ')':
+			return strings.TrimSpace(p.input[start:p.pos]), nil
+		case '(', '"':
+			return "", p.errorf("unexpected %q", c)
+		}
+		p.pos++
+	}
+	return strings.TrimSpace(p.input[start:p.pos]), nil
+}
+
+func (p *queryFilterParser) value() (string, error) {
+	if p.pos >= len(p.input) || p.input[p.pos] != '"' {
+		return p.token(0)
+	}
+
+	p.pos++
+	var b strings.Builder
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		p.pos++
+		switch c {
+		case '\\':
+			if p.pos >= len(p.input) {
+				return "", p.errorf("unterminated escape")
+			}
+			b.WriteByte(p.input[p.pos])
+			p.pos++
+		case '"':
+			return b.String(), nil
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", p.errorf("unterminated quote")
+}
@@ -0,0 +1,82 @@
+package entity_test
+
+import (
+	"testing"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/stretchr/testify/suite"
+)
+
+type QueryFilterTestSuite struct {
+	suite.Suite
+}
+
+func (s *QueryFilterTestSuite) TestParseQueryFilter_Condition() {
+	clause, err := entity.ParseQueryFilter("name:eq:Nubank")
+	s.Nil(err)
+	s.Equal(&entity.QueryDBClause{
+		Clause:  entity.QueryClauseAnd,
+		Queries: []entity.QueryDB{{Key: "name", Value: "Nubank", Condition: "=="}},
+	}, clause)
+
+	clause, err = entity.ParseQueryFilter("currency:in:BRL|USD")
+	s.Nil(err)
+	s.Equal(entity.QueryDB{Key: "currency", Value: "BRL,USD", Condition: "in"}, clause.Queries[0])
+}
+
+func (s *QueryFilterTestSuite) TestParseQueryFilter_Groups() {
+	clause, err := entity.ParseQueryFilter("or(name:eq:Nubank, and(balance:gte:100,currency:nin:BRL|USD))")
+	s.Nil(err)
+	s.Equal(&entity.QueryDBClause{
+		Clause:  entity.QueryClauseOr,
+		Queries: []entity.QueryDB{{Key: "name", Value: "Nubank", Condition: "=="}},
+		Clauses: []entity.QueryDBClause{{
+			Clause: entity.QueryClauseAnd,
+			Queries: []entity.QueryDB{
+				{Key: "balance", Value: "100", Condition: ">="},
+				{Key: "currency", Value: "BRL,USD", Condition: "not-in"},
+			},
+		}},
+	}, clause)
+}
+
+func (s *QueryFilterTestSuite) TestParseQueryFilter_Quoted() {
+	clause, err := entity.ParseQueryFilter(`or(name:eq:"a, (b)",name:eq:"say \"hi\"")`)
+	s.Nil(err)
+	s.Equal("a, (b)", clause.Queries[0].Value)
+	s.Equal(`say "hi"`, clause.Queries[1].Value)
+}
+
+func (s *QueryFilterTestSuite) TestParseQueryFilter_Invalid() {
+	filters := []string{
+		"",
+		"name",
+		"name:eq",
+		"name:eq:",
+		"name:like:x",
+		":eq:x",
+		"or(name:eq:a",
+		"or(name:eq:a))",
+		"xor(name:eq:a)",
+		`name:eq:"open`,
+		"and(and(and(and(and(and(name:eq:a))))))",
+	}
+	for _, filter := range filters {
+		_, err := entity.ParseQueryFilter(filter)
+		s.ErrorIs(err, entity.ErrInvalidQueryFilter, filter)
+	}
+}
+
+func (s *QueryFilterTestSuite) TestParseQueryFilters() {
+	clauses, err := entity.ParseQueryFilters([]string{"name:eq:a", "or(balance:lt:0,balance:gt:10)"})
+	s.Nil(err)
+	s.Len(clauses, 2)
+	s.Equal(entity.QueryClauseOr, clauses[1].Clause)
+
+	_, err = entity.ParseQueryFilters([]string{"name:eq:a", "or("})
+	s.ErrorIs(err, entity.ErrInvalidQueryFilter)
+}
+
+func TestRunQueryFilterTestSuite(t *testing.T) {
+	suite.Run(t, new(QueryFilterTestSuite))
+}
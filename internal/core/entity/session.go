@@ -0,0 +1,95 @@
+package entity
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// SessionUserAgentMaxLength bounds the user agent kept in a session, in characters
+const SessionUserAgentMaxLength = 512
+
+// ErrSessionNotFound is returned when the session is not of the user
+var ErrSessionNotFound = errors.New("session not found")
+
+type sessionClientContextKey struct{}
+
+// Session is a login of the user on a device
+// Its ID is the family of the refresh tokens of the login, a rotation keeps the session and updates LastSeenAt
+type Session struct {
+	ID        string `json:"id" firestore:"id"`
+	UserID    string `json:"user_id" firestore:"user_id"`
+	Provider  string `json:"provider" firestore:"provider"`
+	UserAgent string `json:"user_agent" firestore:"user_agent"`
+	IP        string `json:"ip" firestore:"ip"`
+	// AccessTokenID is the id (jti) of the last access token of the session, it is revoked with the session
+	AccessTokenID string `json:"-" firestore:"access_token_id"`
+	// Generation is the token generation of the user when the session started
+	Generation int64     `json:"-" firestore:"generation"`
+	CreatedAt  time.Time `json:"created_at" firestore:"create_at"`
+	LastSeenAt time.Time `json:"last_seen_at" firestore:"last_seen_at"`
+	// Current is the session of the token of the request, it is not stored
+	Current bool `json:"current" firestore:"-"`
+}
+
+// SessionClient is the device of a login or of a refresh, the handlers put it in the context
+type SessionClient struct {
+	UserAgent string
+	IP        string
+	Provider  string
+}
+
+// WithSessionClient returns a copy of ctx that carries the client of the session
+func WithSessionClient(ctx context.Context, client SessionClient) context.Context {
+	return context.WithValue(ctx, sessionClientContextKey{}, client)
+}
+
+// SessionClientFromContext returns the client stored by WithSessionClient, empty when there is none
+func SessionClientFromContext(ctx context.Context) SessionClient {
+	if ctx == nil {
+		return SessionClient{}
+	}
+
+	client, _ := ctx.Value(sessionClientContextKey{}).(SessionClient)
+	return client
+}
+
+// NewSession creates the session of a new refresh token family
+func NewSession(id, userID string, generation int64, client SessionClient) (*Session, error) {
+	if id == "" || userID == "" {
+		return nil, errors.New("session id and user id are required")
+	}
+
+	now := time.Now().UTC()
+	session := &Session{
+		ID:         id,
+		UserID:     userID,
+		Provider:   client.Provider,
+		Generation: generation,
+		CreatedAt:  now,
+	}
+	session.Seen(client, now)
+	return session, nil
+}
+
+// Seen records a use of the session, an empty user agent or IP keeps the previous one
+func (s *Session) Seen(client SessionClient, now time.Time) {
+	if ua := strings.TrimSpace(client.UserAgent); ua != "" {
+		if utf8.RuneCountInString(ua) > SessionUserAgentMaxLength {
+			ua = string([]rune(ua)[:SessionUserAgentMaxLength])
+		}
+		s.UserAgent = ua
+	}
+	if client.IP != "" {
+		s.IP = client.IP
+	}
+	s.LastSeenAt = now
+}
+
+// IsActive reports if the session can still be refreshed at the time
+// The session ends when its refresh token expires or when the token generation of the user was bumped
+func (s *Session) IsActive(generation int64, now time.Time) bool {
+	return s.Generation >= generation && now.Before(s.LastSeenAt.Add(RefreshTokenTTL))
+}
@@ -0,0 +1,245 @@
+package entity
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// SubscriptionTrial is how long the first period of a subscription to a paid plan is a trial
+const SubscriptionTrial = 14 * 24 * time.Hour
+
+// SubscriptionGracePeriod is how long a past due subscription keeps the plan before it is canceled
+const SubscriptionGracePeriod = 7 * 24 * time.Hour
+
+// ErrPlanCurrency is returned when the plans of a change have prices in different currencies
+var ErrPlanCurrency = errors.New("the plans have prices in different currencies")
+
+type SubscriptionStatus string
+
+const (
+	SubscriptionTrialing SubscriptionStatus = "trialing"
+	SubscriptionActive   SubscriptionStatus = "active"
+	SubscriptionPastDue  SubscriptionStatus = "past_due"
+	SubscriptionCanceled SubscriptionStatus = "canceled"
+)
+
+// SubscriptionOpen are the status a subscription still has a plan and changes at the due time
+var SubscriptionOpen = []SubscriptionStatus{SubscriptionTrialing, SubscriptionActive, SubscriptionPastDue}
+
+type ISubscription interface {
+	// Get returns the subscription of the tenant of the user, the changes due are applied first
+	Get(ctx context.Context, userId *string) (*Subscription, *ModuleError)
+	// ChangePlan applies an upgrade at once and schedules a downgrade to the end of the period
+	// Only the owner of the tenant changes the plan, an upgrade to a paid plan is made through the checkout
+	ChangePlan(ctx context.Context, userId *string, data *SubscriptionRequest) (*Subscription, *ModuleError)
+	// Cancel cancels the subscription at the end of the period
+	Cancel(ctx context.Context, userId *string) (*Subscription, *ModuleError)
+	// Resume undoes a cancel or a downgrade that is not applied yet
+	Resume(ctx context.Context, userId *string) (*Subscription, *ModuleError)
+	// ChangeTenantPlan changes the plan of the tenant like ChangePlan, it is called when a checkout is paid
+	ChangeTenantPlan(ctx context.Context, tenantId *string, data *SubscriptionRequest) (*Subscription, *ModuleError)
+	// CancelTenant cancels the subscription of the tenant at the end of the period
+	CancelTenant(ctx context.Context, tenantId *string) (*Subscription, *ModuleError)
+	// MarkPaid ends the grace period of the tenant, it is called when a renewal is paid
+	MarkPaid(ctx context.Context, tenantId *string) (*Subscription, *ModuleError)
+	// MarkPastDue starts the grace period of the tenant, it is called when a renewal is not paid
+	MarkPastDue(ctx context.Context, tenantId *string) (*Subscription, *ModuleError)
+	// Renew applies the changes of the subscriptions that are due at the time
+	Renew(ctx context.Context, now time.Time) *ModuleError
+}
+
+// Subscription is the plan of a tenant over time, one for each tenant
+// The plan of the tenant is the plan of the subscription, a canceled subscription has no plan
+type Subscription struct {
+	TenantID    string             `json:"tenant_id" firestore:"tenant_id"`
+	PlanID      string             `json:"plan_id" firestore:"plan_id"`
+	Status      SubscriptionStatus `json:"status" firestore:"status"`
+	TrialEnd    time.Time          `json:"trial_end,omitempty" firestore:"trial_end"`
+	PeriodStart time.Time          `json:"period_start" firestore:"period_start"`
+	PeriodEnd   time.Time          `json:"period_end" firestore:"period_end"`
+	// ScheduledPlanID is the plan of a downgrade, it replaces the plan at the end of the period
+	ScheduledPlanID   string    `json:"scheduled_plan_id,omitempty" firestore:"scheduled_plan_id"`
+	CancelAtPeriodEnd bool      `json:"cancel_at_period_end" firestore:"cancel_at_period_end"`
+	PastDueAt         time.Time `json:"past_due_at,omitempty" firestore:"past_due_at"`
+	// DueAt is when the subscription changes next, the end of the period or of the grace period
+	DueAt     time.Time `json:"due_at" firestore:"due_at"`
+	CreatedAt time.Time `json:"created_at" firestore:"create_at"`
+	UpdatedAt time.Time `json:"updated_at" firestore:"update_at"`
+}
+
+type SubscriptionRequest struct {
+	PlanID string `json:"plan_id" binding:"required"`
+}
+
+// NewSubscription subscribes the tenant to the plan, a paid plan starts with a trial
+// A tenant without a plan has an active subscription to the default entitlements
+func NewSubscription(tenantID string, plan *PlanResponse, now time.Time) *Subscription {
+	s := &Subscription{
+		TenantID:    tenantID,
+		PlanID:      plan.ID,
+		Status:      SubscriptionActive,
+		PeriodStart: now,
+		PeriodEnd:   now.AddDate(0, 1, 0),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if plan.ID != "" && plan.Price.IsPositive() {
+		s.Status = SubscriptionTrialing
+		s.TrialEnd = now.Add(SubscriptionTrial)
+		s.PeriodEnd = s.TrialEnd
+	}
+
+	s.schedule()
+	return s
+}
+
+// IsUpgrade reports if the plan next costs more than the plan current, a tenant without a plan always upgrades
+func IsUpgrade(current, next *PlanResponse) (bool, error) {
+	if current.ID == "" {
+		return true, nil
+	}
+
+	diff, err := next.Price.Sub(current.Price)
+	if err != nil {
+		return false, ErrPlanCurrency
+	}
+	return diff.IsPositive(), nil
+}
+
+// Upgrade changes the plan at once, a scheduled downgrade is dropped
+func (s *Subscription) Upgrade(planID string, now time.Time) {
+	s.PlanID = planID
+	s.ScheduledPlanID = ""
+	s.UpdatedAt = now
+}
+
+// Downgrade schedules the plan to the end of the period
+func (s *Subscription) Downgrade(planID string, now time.Time) {
+	s.ScheduledPlanID = planID
+	s.UpdatedAt = now
+}
+
+// Restart starts a new period of the plan, a canceled subscription subscribes again without a trial
+func (s *Subscription) Restart(planID string, now time.Time) {
+	s.PlanID = planID
+	s.Status = SubscriptionActive
+	s.PeriodStart = now
+	s.PeriodEnd = now.AddDate(0, 1, 0)
+	s.ScheduledPlanID = ""
+	s.CancelAtPeriodEnd = false
+	s.PastDueAt = time.Time{}
+	s.UpdatedAt = now
+	s.schedule()
+}
+
+func (s *Subscription) Cancel(now time.Time) error {
+	if s.Status == SubscriptionCanceled {
+		return errors.New("subscription is canceled")
+	}
+
+	s.CancelAtPeriodEnd = true
+	s.UpdatedAt = now
+	return nil
+}
+
+// Resume undoes the cancel and the scheduled downgrade
+func (s *Subscription) Resume(now time.Time) error {
+	if s.Status == SubscriptionCanceled {
+		return errors.New("subscription is canceled")
+	}
+
+	s.CancelAtPeriodEnd = false
+	s.ScheduledPlanID = ""
+	s.UpdatedAt = now
+	return nil
+}
+
+// MarkPastDue starts the grace period, the plan is kept until it ends
+func (s *Subscription) MarkPastDue(now time.Time) error {
+	if s.Status == SubscriptionCanceled {
+		return errors.New("subscription is canceled")
+	}
+
+	if s.Status != SubscriptionPastDue {
+		s.Status = SubscriptionPastDue
+		s.PastDueAt = now
+	}
+	s.UpdatedAt = now
+	s.schedule()
+	return nil
+}
+
+// Paid ends the grace period, the subscription is active again
+func (s *Subscription) Paid(now time.Time) error {
+	if s.Status == SubscriptionCanceled {
+		return errors.New("subscription is canceled")
+	}
+
+	if s.Status == SubscriptionPastDue {
+		s.Status = SubscriptionActive
+		s.PastDueAt = time.Time{}
+	}
+	s.UpdatedAt = now
+	s.schedule()
+	return nil
+}
+
+// Advance applies the changes due at now and reports if the subscription changed
+// At the end of the grace period or of a period to cancel the subscription is canceled and loses the plan.
+// At the end of a period the scheduled plan replaces the plan and a trial becomes active.
+func (s *Subscription) Advance(now time.Time) bool {
+	changed := false
+	for s.Status != SubscriptionCanceled {
+		if s.Status == SubscriptionPastDue && !now.Before(s.PastDueAt.Add(SubscriptionGracePeriod)) {
+			s.cancel()
+			changed = true
+			break
+		}
+
+		if now.Before(s.PeriodEnd) {
+			break
+		}
+
+		if s.CancelAtPeriodEnd {
+			s.cancel()
+			changed = true
+			break
+		}
+
+		if s.ScheduledPlanID != "" {
+			s.PlanID = s.ScheduledPlanID
+			s.ScheduledPlanID = ""
+		}
+		if s.Status == SubscriptionTrialing {
+			s.Status = SubscriptionActive
+		}
+		s.PeriodStart = s.PeriodEnd
+		s.PeriodEnd = s.PeriodStart.AddDate(0, 1, 0)
+		changed = true
+	}
+
+	if changed {
+		s.UpdatedAt = now
+		s.schedule()
+	}
+	return changed
+}
+
+func (s *Subscription) cancel() {
+	s.Status = SubscriptionCanceled
+	s.PlanID = ""
+	s.ScheduledPlanID = ""
+	s.CancelAtPeriodEnd = false
+}
+
+// schedule sets the time the subscription changes next
+func (s *Subscription) schedule() {
+	s.DueAt = s.PeriodEnd
+	if s.Status == SubscriptionPastDue {
+		if grace := s.PastDueAt.Add(SubscriptionGracePeriod); grace.Before(s.DueAt) {
+			s.DueAt = grace
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package entity_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+)
+
+type SubscriptionTestSuite struct {
+	suite.Suite
+	free   *entity.PlanResponse
+	silver *entity.PlanResponse
+	gold   *entity.PlanResponse
+	now    time.Time
+}
+
+func (s *SubscriptionTestSuite) SetupTest() {
+	s.now = time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC)
+	s.free = &entity.PlanResponse{ID: uuid.New().String(), Name: "bronze", Price: entity.NewMoney(0, "BRL")}
+	s.silver = &entity.PlanResponse{ID: uuid.New().String(), Name: "silver", Price: entity.NewMoney(1990, "BRL")}
+	s.gold = &entity.PlanResponse{ID: uuid.New().String(), Name: "gold", Price: entity.NewMoney(4990, "BRL")}
+}
+
+func (s *SubscriptionTestSuite) TestNewSubscription() {
+	subscription := entity.NewSubscription("tenant", s.free, s.now)
+	s.Equal(entity.SubscriptionActive, subscription.Status, "a free plan has no trial")
+	s.Equal(s.now.AddDate(0, 1, 0), subscription.PeriodEnd)
+
+	subscription = entity.NewSubscription("tenant", s.gold, s.now)
+	s.Equal(entity.SubscriptionTrialing, subscription.Status)
+	s.Equal(s.now.Add(entity.SubscriptionTrial), subscription.PeriodEnd)
+	s.Equal(subscription.PeriodEnd, subscription.DueAt)
+}
+
+func (s *SubscriptionTestSuite) TestIsUpgrade() {
+	upgrade, err := entity.IsUpgrade(s.silver, s.gold)
+	s.NoError(err)
+	s.True(upgrade)
+
+	upgrade, err = entity.IsUpgrade(s.gold, s.free)
+	s.NoError(err)
+	s.False(upgrade)
+
+	upgrade, err = entity.IsUpgrade(&entity.PlanResponse{}, s.free)
+	s.NoError(err)
+	s.True(upgrade, "a tenant without a plan upgrades")
+
+	_, err = entity.IsUpgrade(s.silver, &entity.PlanResponse{ID: uuid.New().String(), Price: entity.NewMoney(100, "USD")})
+	s.ErrorIs(err, entity.ErrPlanCurrency)
+}
+
+func (s *SubscriptionTestSuite) TestAdvance() {
+	subscription := entity.NewSubscription("tenant", s.gold, s.now)
+	s.False(subscription.Advance(s.now.Add(time.Hour)))
+
+	subscription.Downgrade(s.silver.ID, s.now)
+	s.Equal(s.gold.ID, subscription.PlanID, "a downgrade waits for the end of the period")
+
+	end := subscription.PeriodEnd
+	s.True(subscription.Advance(end))
+	s.Equal(entity.SubscriptionActive, subscription.Status, "the trial ends")
+	s.Equal(s.silver.ID, subscription.PlanID)
+	s.Empty(subscription.ScheduledPlanID)
+	s.Equal(end, subscription.PeriodStart)
+	s.Equal(end.AddDate(0, 1, 0), subscription.PeriodEnd)
+
+	later := end.AddDate(0, 3, 1)
+	s.True(subscription.Advance(later))
+	s.True(subscription.PeriodEnd.After(later), "the periods missed are renewed")
+
+	s.Require().NoError(subscription.Cancel(later))
+	s.True(subscription.CancelAtPeriodEnd)
+	s.Require().NoError(subscription.Resume(later))
+	s.False(subscription.CancelAtPeriodEnd)
+
+	s.Require().NoError(subscription.Cancel(later))
+	s.True(subscription.Advance(subscription.PeriodEnd))
+	s.Equal(entity.SubscriptionCanceled, subscription.Status)
+	s.Empty(subscription.PlanID, "a canceled subscription has no plan")
+	s.Error(subscription.Cancel(later))
+}
+
+func (s *SubscriptionTestSuite) TestGracePeriod() {
+	subscription := entity.NewSubscription("tenant", s.free, s.now)
+	s.Require().NoError(subscription.MarkPastDue(s.now))
+	s.Equal(entity.SubscriptionPastDue, subscription.Status)
+	s.Equal(s.now.Add(entity.SubscriptionGracePeriod), subscription.DueAt)
+
+	s.False(subscription.Advance(s.now.Add(entity.SubscriptionGracePeriod-time.Minute)), "the plan is kept in the grace period")
+	s.Equal(s.free.ID, subscription.PlanID)
+
+	s.True(subscription.Advance(s.now.Add(entity.SubscriptionGracePeriod)))
+	s.Equal(entity.SubscriptionCanceled, subscription.Status)
+	s.Empty(subscription.PlanID)
+
+	subscription.Restart(s.silver.ID, s.now)
+	s.Equal(entity.SubscriptionActive, subscription.Status, "a canceled subscription subscribes again without a trial")
+	s.Equal(s.silver.ID, subscription.PlanID)
+}
+
+func TestSubscriptionTestSuite(t *testing.T) {
+	suite.Run(t, new(SubscriptionTestSuite))
+}
@@ -11,13 +11,31 @@ import (
 	"github.com/google/uuid"
 )
 
+// TenantFields are the fields of the tenant that can be filtered and sorted
+var TenantFields = Fields{
+	"id":        {Type: FieldString},
+	"name":      {Type: FieldString, Sortable: true},
+	"alias":     {Type: FieldString},
+	"owner_id":  {Type: FieldString},
+	"users":     {Type: FieldArray},
+	"wallets":   {Type: FieldArray},
+	"create_at": {Type: FieldTime, Sortable: true},
+	"update_at": {Type: FieldTime, Sortable: true},
+
+	"require_two_factor": {Type: FieldBool},
+	"suspended":          {Type: FieldBool},
+}
+
+// ErrTenantSuspended is returned when the tenant of the user was suspended by a system admin
+var ErrTenantSuspended = errors.New("tenant is suspended")
+
 type ITenant interface {
 	Create(*TenantResponse) (*TenantResponse, error)
-	Get() ([]TenantResponse, error)
+	Get(page *PageRequest) (*Page[TenantResponse], error)
 	GetById(id *string) (*TenantResponse, error)
 	Update(data *TenantResponse) (*TenantResponse, error)
 	Delete(id *string) error
-	GetByFilterMany(ctx context.Context, filter []QueryDB) ([]TenantResponse, error)
+	GetByFilterMany(ctx context.Context, filter []QueryDBClause) ([]TenantResponse, error)
 	GetByFilterOne(ctx context.Context, filter []QueryDB) (*TenantResponse, error)
 }
 
@@ -31,6 +49,12 @@ type TenantResponse struct {
 	CreatedAt time.Time    `json:"created_at" firestore:"create_at"`
 	UpdatedAt time.Time    `json:"updated_at" firestore:"update_at"`
 	Wallets   []string     `json:"wallets,omitempty" firestore:"wallets"`
+	// RequireTwoFactor is set by the owner, everyone that accesses the wallets of the tenant must use the second factor
+	RequireTwoFactor bool `json:"require_two_factor" firestore:"require_two_factor"`
+	// Suspended is set by a system admin, the users of the tenant cannot use the API until it is unset
+	Suspended       bool      `json:"suspended" firestore:"suspended"`
+	SuspendedReason string    `json:"suspended_reason,omitempty" firestore:"suspended_reason"`
+	SuspendedAt     time.Time `json:"suspended_at,omitempty" firestore:"suspended_at"`
 }
 
 func NewTenant(tenant *TenantResponse) (*TenantResponse, error) {
@@ -54,6 +78,8 @@ func NewTenant(tenant *TenantResponse) (*TenantResponse, error) {
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 		Wallets:   tenant.Wallets,
+
+		RequireTwoFactor: tenant.RequireTwoFactor,
 	}
 
 	if err := t.Validate(); err != nil {
@@ -22,7 +22,7 @@ func (s *TenantTestSuite) SetupTest() {
 		ID:          uuid.New().String(),
 		Name:        "Bronze",
 		Description: "Bronze plan",
-		Price:       0.0,
+		Price:       entity.NewMoney(0, "BRL"),
 		CreatedAt:   time.Now().UTC(),
 		UpdatedAt:   time.Now().UTC(),
 	}
@@ -0,0 +1,195 @@
+package entity
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/Tomelin/financial-management-backend/pkg/utils"
+	"github.com/google/uuid"
+)
+
+// TransactionFields are the fields of the transaction that can be filtered and sorted
+var TransactionFields = Fields{
+	"id":          {Type: FieldString},
+	"wallet_id":   {Type: FieldString},
+	"tenant_id":   {Type: FieldString},
+	"category_id": {Type: FieldString},
+	"type":        {Type: FieldString, Sortable: true},
+	"amount":      {Type: FieldNumber},
+	"currency":    {Type: FieldString},
+	"description": {Type: FieldString},
+	"date":        {Type: FieldTime, Sortable: true},
+	"created_by":  {Type: FieldString},
+	"created_at":  {Type: FieldTime, Sortable: true},
+	"updated_at":  {Type: FieldTime, Sortable: true},
+}
+
+// ITransaction interface
+// Methods that must be implemented by the wallet transactions
+// The userId is the ID of the user that is calling, the walletId is the wallet that owns the transactions
+type ITransaction interface {
+	Create(ctx context.Context, userId *string, walletId *string, transaction *WalletTransaction) (*WalletTransaction, *ModuleError)
+	Get(ctx context.Context, userId *string, walletId *string, page *PageRequest) (*Page[WalletTransaction], *ModuleError)
+	GetByID(ctx context.Context, userId *string, walletId *string, id *string) (*WalletTransaction, *ModuleError)
+	Update(ctx context.Context, userId *string, walletId *string, transaction *WalletTransaction) (*WalletTransaction, *ModuleError)
+	Delete(ctx context.Context, userId *string, walletId *string, id *string) *ModuleError
+	GetByFilterMany(ctx context.Context, userId *string, walletId *string, filter []QueryDB) ([]WalletTransaction, *ModuleError)
+}
+
+type TransactionType string
+
+const (
+	TransactionTypeIncome  TransactionType = "income"
+	TransactionTypeExpense TransactionType = "expense"
+)
+
+func (t *TransactionType) Validate() *ModuleError {
+	switch TransactionType(strings.ToLower(string(*t))) {
+	case TransactionTypeIncome, TransactionTypeExpense:
+		*t = TransactionType(strings.ToLower(string(*t)))
+		return nil
+	default:
+		return Error("type must be income or expense", "transaction", "Validate", ApplicationLayerEntity, ResponseCodeBadRequest)
+	}
+}
+
+// WalletTransaction struct
+// A income or expense registered in a wallet, linked to a transaction category
+type WalletTransaction struct {
+	ID          string          `json:"id" firestore:"id"`
+	WalletID    string          `json:"wallet_id" firestore:"wallet_id"`
+	TenantID    string          `json:"tenant_id" firestore:"tenant_id"`
+	CategoryID  string          `json:"category_id" binding:"required" firestore:"category_id"`
+	Type        TransactionType `json:"type" binding:"required" firestore:"type"`
+	Amount      Money           `json:"amount" binding:"required" firestore:"amount"`
+	Currency    string          `json:"currency" firestore:"currency"`
+	Description string          `json:"description" firestore:"description"`
+	Date        time.Time       `json:"date" firestore:"date"`
+	CreatedBy   string          `json:"created_by" firestore:"created_by"`
+	CreatedAt   time.Time       `json:"created_at" firestore:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at" firestore:"updated_at"`
+}
+
+// NewWalletTransaction function
+// Create a new wallet transaction with a new id
+// Return a new WalletTransaction and error
+func NewWalletTransaction(t *WalletTransaction) (*WalletTransaction, *ModuleError) {
+
+	if t.IsEmpty(t) {
+		return nil, Error("transaction is required", "transaction", "NewWalletTransaction", ApplicationLayerEntity, ResponseCodeBadRequest)
+	}
+
+	// the id is always generated, the id of the request body is ignored
+	id, _ := uuid.NewV7()
+
+	transaction := &WalletTransaction{
+		ID:          id.String(),
+		WalletID:    t.WalletID,
+		TenantID:    t.TenantID,
+		CategoryID:  t.CategoryID,
+		Type:        t.Type,
+		Amount:      t.Amount,
+		Currency:    t.Currency,
+		Description: t.Description,
+		Date:        t.Date,
+		CreatedBy:   t.CreatedBy,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+
+	if transaction.Date.IsZero() {
+		transaction.Date = transaction.CreatedAt
+	}
+
+	if err := transaction.Validate(); err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+func (t *WalletTransaction) Validate() *ModuleError {
+
+	if t.IsEmpty(t) {
+		return Error("transaction cannot be empty", "transaction", "Validate", ApplicationLayerEntity, ResponseCodeBadRequest)
+	}
+
+	if t.ID == "" {
+		return Error("id is required", "transaction", "Validate", ApplicationLayerEntity, ResponseCodeBadRequest)
+	}
+
+	id := t.ID
+	if err := utils.ValidateUUID(&id); err != nil {
+		return Error(err.Error(), "transaction", "Validate", ApplicationLayerEntity, ResponseCodeBadRequest)
+	}
+
+	if t.WalletID != "" {
+		walletId := t.WalletID
+		if err := utils.ValidateUUID(&walletId); err != nil {
+			return Error(err.Error(), "transaction", "Validate", ApplicationLayerEntity, ResponseCodeBadRequest)
+		}
+	}
+
+	categoryId := t.CategoryID
+	if err := utils.ValidateUUID(&categoryId); err != nil {
+		return Error("invalid category id", "transaction", "Validate", ApplicationLayerEntity, ResponseCodeBadRequest)
+	}
+
+	if err := t.Type.Validate(); err != nil {
+		return err
+	}
+
+	if !t.Amount.IsPositive() {
+		return Error("amount must be greater than zero", "transaction", "Validate", ApplicationLayerEntity, ResponseCodeBadRequest)
+	}
+
+	// the currency is the wallet currency, it is set by the service before saving
+	if t.Currency != "" {
+		amount, err := t.Amount.In(t.Currency)
+		if err != nil {
+			return Error(err.Error(), "transaction", "Validate", ApplicationLayerEntity, ResponseCodeBadRequest)
+		}
+		t.Amount = amount
+		t.Currency = amount.Currency
+	}
+
+	if t.UpdatedAt == (time.Time{}) {
+		t.UpdatedAt = time.Now()
+	}
+
+	return nil
+}
+
+// SignedAmount returns the amount with the sign applied to the wallet balance
+// income is positive and expense is negative
+func (t *WalletTransaction) SignedAmount() Money {
+	if t.Type == TransactionTypeExpense {
+		return t.Amount.Neg()
+	}
+	return t.Amount
+}
+
+// AfterDocumentLoad binds the amount read from the database to the transaction currency
+func (t *WalletTransaction) AfterDocumentLoad() error {
+	if t.Currency == "" {
+		t.Currency = DefaultCurrency
+	}
+
+	amount, err := t.Amount.In(t.Currency)
+	if err != nil {
+		return err
+	}
+	t.Amount = amount
+
+	return nil
+}
+
+func (t *WalletTransaction) SetUpdate() {
+	t.UpdatedAt = time.Now()
+}
+
+func (t *WalletTransaction) IsEmpty(data *WalletTransaction) bool {
+	return data == nil || reflect.DeepEqual(*data, WalletTransaction{})
+}
@@ -9,9 +9,18 @@ import (
 	"github.com/google/uuid"
 )
 
+// TransactionCategoryFields are the fields of the category that can be filtered and sorted
+var TransactionCategoryFields = Fields{
+	"id":        {Type: FieldString},
+	"name":      {Type: FieldString, Sortable: true},
+	"default":   {Type: FieldString},
+	"tenant_id": {Type: FieldString},
+	"wallet_id": {Type: FieldString},
+}
+
 type ITransactionCategoryRepository interface {
 	Create(ctx context.Context, category *TransactionCategory) (*TransactionCategory, *ModuleError)
-	Get(ctx context.Context, walletID *string) ([]TransactionCategory, *ModuleError)
+	Get(ctx context.Context, filter []QueryDBClause, page *PageRequest) (*Page[TransactionCategory], *ModuleError)
 	GetById(ctx context.Context, id *string) (*TransactionCategory, *ModuleError)
 	Update(ctx context.Context, category *TransactionCategory) (*TransactionCategory, *ModuleError)
 	Delete(ctx context.Context, id *string) *ModuleError
@@ -21,11 +30,11 @@ type ITransactionCategoryRepository interface {
 
 type ITransactionCategory interface {
 	Create(ctx context.Context, email *string, category *TransactionCategory) (*TransactionCategory, *ModuleError)
-	Get(ctx context.Context, email *string, walletID *string) ([]TransactionCategory, *ModuleError)
+	Get(ctx context.Context, email *string, walletID *string, page *PageRequest) (*Page[TransactionCategory], *ModuleError)
 	GetById(ctx context.Context, email *string, id *string) (*TransactionCategory, *ModuleError)
 	Update(ctx context.Context, email *string, category *TransactionCategory) (*TransactionCategory, *ModuleError)
 	Delete(ctx context.Context, email *string, id *string) *ModuleError
-	GetByFilterMany(ctx context.Context, email *string, filter []QueryDB) ([]TransactionCategory, *ModuleError)
+	GetByFilterMany(ctx context.Context, email *string, filter []QueryDBClause) ([]TransactionCategory, *ModuleError)
 	GetByFilterOne(ctx context.Context, email *string, filter []QueryDB) (*TransactionCategory, *ModuleError)
 }
 
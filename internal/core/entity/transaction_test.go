@@ -0,0 +1,89 @@
+package entity_test
+
+import (
+	"testing"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+type WalletTransactionTestSuite struct {
+	suite.Suite
+	transaction *entity.WalletTransaction
+}
+
+func (s *WalletTransactionTestSuite) SetupTest() {
+	s.transaction = &entity.WalletTransaction{
+		WalletID:    uuid.New().String(),
+		CategoryID:  uuid.New().String(),
+		Type:        entity.TransactionTypeExpense,
+		Amount:      entity.NewMoney(1050, "BRL"),
+		Description: "market",
+	}
+}
+
+func (s *WalletTransactionTestSuite) TearDownTest() {
+	s.transaction = nil
+}
+
+func (s *WalletTransactionTestSuite) TestNewWalletTransaction_Error_Nil() {
+	transaction, err := entity.NewWalletTransaction(nil)
+	s.Nil(transaction)
+	s.Equal(err.Err, "transaction is required")
+}
+
+func (s *WalletTransactionTestSuite) TestNewWalletTransaction_Success() {
+	transaction, err := entity.NewWalletTransaction(s.transaction)
+	s.Nil(err)
+	s.NotNil(transaction)
+	s.NotEmpty(transaction.ID)
+	s.False(transaction.Date.IsZero())
+	s.Equal(transaction.CreatedAt, transaction.Date)
+}
+
+func (s *WalletTransactionTestSuite) TestNewWalletTransaction_GeneratesID() {
+	s.transaction.ID = uuid.New().String()
+	transaction, err := entity.NewWalletTransaction(s.transaction)
+	s.Nil(err)
+	s.NotEqual(s.transaction.ID, transaction.ID, "the id of the request body is ignored")
+}
+
+func (s *WalletTransactionTestSuite) TestNewWalletTransaction_Error_Type() {
+	s.transaction.Type = "transfer"
+	transaction, err := entity.NewWalletTransaction(s.transaction)
+	s.Nil(transaction)
+	s.Equal(err.Err, "type must be income or expense")
+}
+
+func (s *WalletTransactionTestSuite) TestNewWalletTransaction_TypeUpperCase() {
+	s.transaction.Type = "INCOME"
+	transaction, err := entity.NewWalletTransaction(s.transaction)
+	s.Nil(err)
+	s.Equal(entity.TransactionTypeIncome, transaction.Type)
+}
+
+func (s *WalletTransactionTestSuite) TestNewWalletTransaction_Error_Amount() {
+	s.transaction.Amount = entity.NewMoney(0, "BRL")
+	transaction, err := entity.NewWalletTransaction(s.transaction)
+	s.Nil(transaction)
+	s.Equal(err.Err, "amount must be greater than zero")
+}
+
+func (s *WalletTransactionTestSuite) TestNewWalletTransaction_Error_Category() {
+	s.transaction.CategoryID = "category"
+	transaction, err := entity.NewWalletTransaction(s.transaction)
+	s.Nil(transaction)
+	s.Equal(err.Err, "invalid category id")
+}
+
+func (s *WalletTransactionTestSuite) TestSignedAmount() {
+	s.Equal(entity.NewMoney(-1050, "BRL"), s.transaction.SignedAmount())
+
+	s.transaction.Type = entity.TransactionTypeIncome
+	s.Equal(entity.NewMoney(1050, "BRL"), s.transaction.SignedAmount())
+}
+
+func TestRunWalletTransactionTestSuite(t *testing.T) {
+	suite.Run(t, new(WalletTransactionTestSuite))
+}
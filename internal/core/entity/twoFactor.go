@@ -0,0 +1,156 @@
+package entity
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+)
+
+const (
+	// TwoFactorIssuer is the name of the account in the authenticator apps
+	TwoFactorIssuer = "Financial Management"
+	// TwoFactorChallengeTTL is how long the user has to send the code after the password or the provider login
+	TwoFactorChallengeTTL = 5 * time.Minute
+	// TwoFactorSkew is the number of time steps accepted before and after the current one
+	TwoFactorSkew = 1
+	// RecoveryCodeCount is the number of recovery codes of a user
+	RecoveryCodeCount = 10
+
+	// ActionTokenTwoFactor is the purpose of the challenge token of the login
+	ActionTokenTwoFactor ActionTokenPurpose = "two_factor"
+)
+
+var (
+	// ErrTwoFactorRequired is returned when the second factor cannot be disabled because a tenant requires it
+	ErrTwoFactorRequired = errors.New("two-factor authentication is required by a tenant")
+	// ErrTwoFactorEnabled is returned by the enrollment of a user that has the second factor
+	ErrTwoFactorEnabled = errors.New("two-factor authentication is already enabled")
+	// ErrTwoFactorNotEnabled is returned when the user has no second factor
+	ErrTwoFactorNotEnabled = errors.New("two-factor authentication is not enabled")
+	// ErrInvalidTwoFactorCode is returned when the code or the recovery code is wrong or was used
+	ErrInvalidTwoFactorCode = errors.New("invalid two-factor code")
+)
+
+// ITwoFactor is the second factor of the login, TOTP codes and single-use recovery codes
+// Login, Verify, ChallengeUser, Enroll, Confirm, Disable, RegenerateRecoveryCodes, Status, RequireForTenant
+type ITwoFactor interface {
+	// Login returns the token pair of the user, or a challenge when the user must send the second factor
+	// The user that is required to use the second factor and did not enroll yet gets a challenge to enroll
+	Login(ctx context.Context, user *AccountUser) (*LoginResult, error)
+	// Verify checks the code of the challenge and returns the token pair
+	// The code of a challenge to enroll confirms the enrollment, the result has the recovery codes
+	Verify(ctx context.Context, request *TwoFactorVerifyRequest) (*TwoFactorVerifyResult, error)
+	// ChallengeUser returns the user of a pending challenge
+	ChallengeUser(ctx context.Context, challengeToken string) (string, error)
+	// Enroll generates the secret of the user, it is enabled by Confirm
+	Enroll(ctx context.Context, userID string) (*TwoFactorEnrollment, error)
+	// Confirm enables the second factor with a code of the secret and returns the recovery codes
+	Confirm(ctx context.Context, userID, code string) ([]string, error)
+	// Disable removes the second factor, with a code or a recovery code
+	Disable(ctx context.Context, userID, code string) error
+	// RegenerateRecoveryCodes replaces the recovery codes, with a code
+	RegenerateRecoveryCodes(ctx context.Context, userID, code string) ([]string, error)
+	Status(ctx context.Context, user *AccountUser) (*TwoFactorStatus, error)
+	// RequireForTenant sets if everyone that accesses the wallets of the tenant must use the second factor
+	// Only the owner of the tenant sets it
+	RequireForTenant(ctx context.Context, userID, tenantID string, required bool) error
+}
+
+// TwoFactor is the TOTP secret of a user, it is enabled after the first code
+// RecoveryCodes are the SHA-256 of the unused recovery codes, the codes are shown once
+type TwoFactor struct {
+	UserID        string    `json:"user_id" firestore:"user_id"`
+	Secret        string    `json:"-" firestore:"secret"`
+	Enabled       bool      `json:"enabled" firestore:"enabled"`
+	LastStep      int64     `json:"-" firestore:"last_step"`
+	RecoveryCodes []string  `json:"-" firestore:"recovery_codes"`
+	CreatedAt     time.Time `json:"created_at" firestore:"create_at"`
+	UpdatedAt     time.Time `json:"updated_at" firestore:"update_at"`
+}
+
+// LoginResult is the token pair of the login, or the challenge of the second factor
+type LoginResult struct {
+	*TokenPair
+	TwoFactorRequired bool `json:"two_factor_required,omitempty"`
+	// TwoFactorEnrollment is true when the user must enroll before the challenge is verified
+	TwoFactorEnrollment bool   `json:"two_factor_enrollment,omitempty"`
+	ChallengeToken      string `json:"challenge_token,omitempty"`
+}
+
+type TwoFactorVerifyRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+	RecoveryCode   string `json:"recovery_code"`
+}
+
+type TwoFactorVerifyResult struct {
+	*TokenPair
+	RecoveryCodes []string `json:"recovery_codes,omitempty"`
+}
+
+type TwoFactorEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+type TwoFactorStatus struct {
+	Enabled           bool `json:"enabled"`
+	Required          bool `json:"required"`
+	RecoveryCodesLeft int  `json:"recovery_codes_left"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type TwoFactorTenantRequest struct {
+	Required bool `json:"required"`
+}
+
+func (r *TwoFactorVerifyRequest) Validate() error {
+	if r == nil || r.ChallengeToken == "" {
+		return errors.New("challenge token is required")
+	}
+	if (r.Code == "") == (r.RecoveryCode == "") {
+		return errors.New("either code or recovery code is required")
+	}
+	return nil
+}
+
+// NewRecoveryCodes returns the recovery codes, to show to the user, and their hashes, to store
+func NewRecoveryCodes(n int) ([]string, []string, error) {
+	codes := make([]string, n)
+	hashes := make([]string, n)
+	for i := range codes {
+		b := make([]byte, 10)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		code := strings.ToLower(base32.StdEncoding.EncodeToString(b))
+		codes[i] = code[:4] + "-" + code[4:8] + "-" + code[8:12] + "-" + code[12:]
+		hashes[i] = RecoveryCodeID(codes[i])
+	}
+	return codes, hashes, nil
+}
+
+// RecoveryCodeID returns the hash of the recovery code, without the case and the separators typed by the user
+func RecoveryCodeID(code string) string {
+	code = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	return opaqueTokenID(code)
+}
+
+// UseRecoveryCode removes the recovery code, it reports false when the code is not one of the user
+func (t *TwoFactor) UseRecoveryCode(code string) bool {
+	id := RecoveryCodeID(code)
+	for i, hash := range t.RecoveryCodes {
+		if subtle.ConstantTimeCompare([]byte(hash), []byte(id)) == 1 {
+			t.RecoveryCodes = append(t.RecoveryCodes[:i:i], t.RecoveryCodes[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
@@ -19,15 +19,41 @@ const (
 	QueryClauseOr  QueryClause = "or"
 )
 
+// QueryDBClause is a node of a boolean filter
+// The queries and the nested clauses are joined by the clause, "and" when it is empty
+// A list of clauses is joined by AND
 type QueryDBClause struct {
-	Clause  QueryClause `json:"clause"`
-	Queries []QueryDB   `json:"queries"`
+	Clause  QueryClause     `json:"clause"`
+	Queries []QueryDB       `json:"queries"`
+	Clauses []QueryDBClause `json:"clauses,omitempty"`
+}
+
+// IsEmpty reports if the clause has no query with key and value, the repositories ignore the empty queries
+func (c QueryDBClause) IsEmpty() bool {
+	for _, q := range c.Queries {
+		if q.Key != "" && q.Value != "" {
+			return false
+		}
+	}
+	for _, nested := range c.Clauses {
+		if !nested.IsEmpty() {
+			return false
+		}
+	}
+	return true
+}
+
+// AndClause returns the clauses that match the documents of all queries
+func AndClause(queries []QueryDB) []QueryDBClause {
+	return []QueryDBClause{{Clause: QueryClauseAnd, Queries: queries}}
 }
 
 type QueryDB struct {
 	Key       string `json:"key"`
 	Value     string `json:"value"`
 	Condition string `json:"condition"`
+	// Typed is the value converted to the type of the field by Fields.Coerce, the string value is used when it is nil
+	Typed any `json:"-"`
 }
 
 type QueryFirebase string
@@ -92,15 +118,17 @@ const (
 type ResponseCode int
 
 const (
-	ResponseCodeInternalServer ResponseCode = 500
-	ResponseCodeBadRequest     ResponseCode = 400
-	ResponseCodeUnauthorized   ResponseCode = 401
-	ResponseCodeForbidden      ResponseCode = 403
-	ResponseCodeNotFound       ResponseCode = 404
-	ResponseCodeNoContent      ResponseCode = 204
-	ResponseCodeOK             ResponseCode = 200
-	ResponseCodeCreated        ResponseCode = 201
-	ResponseCodeAccepted       ResponseCode = 202
+	ResponseCodeInternalServer     ResponseCode = 500
+	ResponseCodeBadRequest         ResponseCode = 400
+	ResponseCodeUnauthorized       ResponseCode = 401
+	ResponseCodeForbidden          ResponseCode = 403
+	ResponseCodeNotFound           ResponseCode = 404
+	ResponseCodeConflict           ResponseCode = 409
+	ResponseCodeServiceUnavailable ResponseCode = 503
+	ResponseCodeNoContent          ResponseCode = 204
+	ResponseCodeOK                 ResponseCode = 200
+	ResponseCodeCreated            ResponseCode = 201
+	ResponseCodeAccepted           ResponseCode = 202
 )
 
 type ResponseMessage string
@@ -111,6 +139,7 @@ const (
 	ResponseMessageUnauthorized   ResponseMessage = "unauthorized"
 	ResponseMessageForbidden      ResponseMessage = "forbidden"
 	ResponseMessageNotFound       ResponseMessage = "not found"
+	ResponseMessageConflict       ResponseMessage = "conflict"
 	ResponseMessageNoContent      ResponseMessage = "no content"
 	ResponseMessageOK             ResponseMessage = "ok"
 	ResponseMessageCreated        ResponseMessage = "item was created"
@@ -129,6 +158,8 @@ func ResponseCodeToMessage(code ResponseCode) ResponseMessage {
 		return ResponseMessageForbidden
 	case ResponseCodeNotFound:
 		return ResponseMessageNotFound
+	case ResponseCodeConflict:
+		return ResponseMessageConflict
 	case ResponseCodeNoContent:
 		return ResponseMessageNoContent
 	case ResponseCodeOK:
@@ -154,6 +185,8 @@ func ResponseMessageToCode(message ResponseMessage) ResponseCode {
 		return ResponseCodeForbidden
 	case ResponseMessageNotFound:
 		return ResponseCodeNotFound
+	case ResponseMessageConflict:
+		return ResponseCodeConflict
 	case ResponseMessageNoContent:
 		return ResponseCodeNoContent
 	case ResponseMessageOK:
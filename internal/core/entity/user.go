@@ -5,19 +5,38 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
-	"strings"
+	"regexp"
 	"time"
 
 	"github.com/Tomelin/financial-management-backend/pkg/utils"
 	"github.com/google/uuid"
 )
 
+// providerName is the name of the provider the user signed up with, local or the name of an OAuth provider
+var providerName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// UserFields are the fields of the user that can be filtered and sorted
+var UserFields = Fields{
+	"id":         {Type: FieldString},
+	"tenant_id":  {Type: FieldString},
+	"name":       {Type: FieldString, Sortable: true},
+	"email":      {Type: FieldString, Sortable: true},
+	"provider":   {Type: FieldString},
+	"first_name": {Type: FieldString},
+	"last_name":  {Type: FieldString},
+	"nick_name":  {Type: FieldString},
+	"user_id":    {Type: FieldString},
+	"location":   {Type: FieldString},
+	"create_at":  {Type: FieldTime, Sortable: true},
+	"update_at":  {Type: FieldTime, Sortable: true},
+}
+
 // IUser interface
 // Methods that must be implemented by the user
 // Create, Get, GetById, Update, Delete, GetByFilterMany, GetByFilterOne, GetByEmail
 type IUser interface {
 	Create(ctx context.Context, user *AccountUser) (*AccountUser, error)
-	Get(ctx context.Context) ([]AccountUser, error)
+	Get(ctx context.Context, page *PageRequest) (*Page[AccountUser], error)
 	GetById(ctx context.Context, id *string) (*AccountUser, error)
 	Update(ctx context.Context, data *AccountUser) (*AccountUser, error)
 	Delete(ctx context.Context, id *string) error
@@ -112,7 +131,7 @@ func (u *User) Validate() error {
 		return errors.New("invalid email")
 	}
 
-	if u.Provider == "" || (!strings.Contains(strings.ToLower(u.Provider), "local") && !strings.Contains(strings.ToLower(u.Provider), "google")) {
+	if !providerName.MatchString(u.Provider) {
 		return errors.New("provider is required")
 	}
 
@@ -96,7 +96,7 @@ func (s *UserTestSuite) TestNewUser_Error_InvalidProvider() {
 	s.Equal("provider is required", err.Error())
 
 	// Teste com User nulo
-	s.user.Provider = "teste"
+	s.user.Provider = "Google Inc"
 	accountUser, err = entity.NewUser(s.user)
 	s.Error(err)
 	s.Nil(accountUser)
@@ -164,11 +164,15 @@ func (s *UserTestSuite) TestNewUser_Error_UserValidate_Provider() {
 	s.Error(err)
 	s.Equal("provider is required", err.Error())
 
-	s.user.Provider = "googl"
+	s.user.Provider = "google/oauth"
 	err = s.user.Validate()
 	s.NotNil(err)
 	s.Error(err)
 	s.Equal("provider is required", err.Error())
+
+	// every configured OAuth provider signs users up
+	s.user.Provider = "github"
+	s.Nil(s.user.Validate())
 }
 
 func (s *UserTestSuite) TestNewUser_Error_IsEmpty() {
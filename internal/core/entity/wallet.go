@@ -2,41 +2,59 @@ package entity
 
 import (
 	"context"
-	"fmt"
 	"reflect"
-	"strconv"
-	"sync"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/Tomelin/financial-management-backend/pkg/utils"
 	"github.com/google/uuid"
 )
 
+// WalletFields are the fields of the wallet that can be filtered and sorted
+var WalletFields = Fields{
+	"id":                  {Type: FieldString},
+	"name":                {Type: FieldString, Sortable: true},
+	"description":         {Type: FieldString},
+	"owner_id":            {Type: FieldString},
+	"tenant_id":           {Type: FieldString},
+	"balance":             {Type: FieldNumber},
+	"currency":            {Type: FieldString, Sortable: true},
+	"shared_with_tenants": {Type: FieldArray},
+	"read_only":           {Type: FieldBool},
+	"created_at":          {Type: FieldTime, Sortable: true},
+	"updated_at":          {Type: FieldTime, Sortable: true},
+}
+
 type IWallet interface {
 	Create(ctx context.Context, userId *string, wallet *WalletResponse) (*WalletResponse, *ModuleError)
-	Get(ctx context.Context, userId *string) ([]WalletResponse, *ModuleError)
+	Get(ctx context.Context, userId *string, page *PageRequest) (*Page[WalletResponse], *ModuleError)
 	GetWalletByIdAndUserID(ctx context.Context, userId *string, walletId *string) (*WalletResponse, *ModuleError)
 	GetByID(ctx context.Context, walletId *string) (*WalletResponse, *ModuleError)
 	Update(ctx context.Context, userId *string, data *WalletResponse) (*WalletResponse, *ModuleError)
-	UpdateBalance(ctx context.Context, walletID *string, balance *float64) (*WalletResponse, *ModuleError)
+	UpdateBalance(ctx context.Context, walletID *string, delta *Money) (*WalletResponse, *ModuleError)
 	Delete(ctx context.Context, userId *string, walletId *string) *ModuleError
-	GetByFilterMany(ctx context.Context, userId *string, filter []QueryDB) ([]WalletResponse, *ModuleError)
+	GetByFilterMany(ctx context.Context, userId *string, filter []QueryDBClause) ([]WalletResponse, *ModuleError)
 	GetByFilterOne(ctx context.Context, userId *string, filter []QueryDB) (*WalletResponse, *ModuleError)
 }
 
 type WalletResponse struct {
-	mu sync.Mutex // Mutex para garantir segurança em acessos concorrentes
-
-	ID                string    `json:"id" firestore:"id"`
-	Name              string    `json:"name" firestore:"name"`
-	Description       string    `json:"description" firestore:"description"`
-	OwnerID           string    `json:"owner_id" binding:"required" firestore:"owner_id"`
-	TenantID          string    `json:"tenant_id" binding:"required" firestore:"tenant_id"`
-	Balance           float64   `json:"balance" firestore:"balance"`
-	Currency          string    `json:"currency" firestore:"currency"`
-	SharedWithTenants []string  `json:"shared_with_tenants" firestore:"shared_with_tenants"`
-	CreatedAt         time.Time `json:"createdAt" firestore:"created_at"`
-	UpdatedAt         time.Time `json:"updatedAt" firestore:"updated_at"`
+	ID                string   `json:"id" firestore:"id"`
+	Name              string   `json:"name" firestore:"name"`
+	Description       string   `json:"description" firestore:"description"`
+	OwnerID           string   `json:"owner_id" binding:"required" firestore:"owner_id"`
+	TenantID          string   `json:"tenant_id" binding:"required" firestore:"tenant_id"`
+	Balance           Money    `json:"balance" firestore:"balance"`
+	Currency          string   `json:"currency" firestore:"currency"`
+	SharedWithTenants []string `json:"shared_with_tenants" firestore:"shared_with_tenants"`
+	// Permissions are the levels of the tenants the wallet is shared with, one for each of SharedWithTenants
+	Permissions []WalletPermission `json:"permissions" firestore:"permissions"`
+	// ReadOnly is set when the plan of the tenant allows fewer wallets, the wallet is kept but not changed
+	ReadOnly  bool      `json:"read_only" firestore:"read_only"`
+	CreatedAt time.Time `json:"createdAt" firestore:"created_at"`
+	UpdatedAt time.Time `json:"updatedAt" firestore:"updated_at"`
+	// Permission is the effective level of the user of the request, it is not stored
+	Permission PermissionLevel `json:"permission,omitempty" firestore:"-"`
 }
 
 func NewWallet(w *WalletResponse) (*WalletResponse, *ModuleError) {
@@ -45,18 +63,16 @@ func NewWallet(w *WalletResponse) (*WalletResponse, *ModuleError) {
 		return nil, Error("wallet is required", "wallet", "NewWallet", ApplicationLayerEntity, ResponseCodeBadRequest)
 	}
 
-	if w.ID == "" {
-		id, _ := uuid.NewV7()
-		w.ID = id.String()
-	}
+	// the id is always generated, the id of the request body is ignored
+	id, _ := uuid.NewV7()
 
 	wallet := &WalletResponse{
-		ID:          w.ID,
+		ID:          id.String(),
 		Name:        w.Name,
 		Description: w.Description,
 		OwnerID:     w.OwnerID,
 		TenantID:    w.TenantID,
-		Balance:     0,
+		Balance:     NewMoney(0, w.Currency),
 		Currency:    w.Currency,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
@@ -80,9 +96,20 @@ func (w *WalletResponse) Validate() *ModuleError {
 	}
 
 	if w.Currency == "" {
-		w.Currency = "BRL"
+		w.Currency = DefaultCurrency
+	}
+
+	w.Currency = strings.ToUpper(w.Currency)
+	if err := ValidateCurrency(w.Currency); err != nil {
+		return Error(err.Error(), "wallet", "Validate", ApplicationLayerEntity, ResponseCodeBadRequest)
 	}
 
+	balance, err := w.Balance.In(w.Currency)
+	if err != nil {
+		return Error(err.Error(), "wallet", "Validate", ApplicationLayerEntity, ResponseCodeBadRequest)
+	}
+	w.Balance = balance
+
 	ownerId := w.OwnerID
 	if err := utils.ValidateUUID(&ownerId); err != nil {
 		return Error(err.Error(), "wallet", "Validate", ApplicationLayerEntity, ResponseCodeBadRequest)
@@ -109,9 +136,10 @@ func (w *WalletResponse) Validate() *ModuleError {
 	return nil
 }
 
-func (w *WalletResponse) SetBalance(data float64) error {
+// SetBalance sets the balance, it must be in the wallet currency
+func (w *WalletResponse) SetBalance(data Money) error {
 
-	balance, err := strconv.ParseFloat(fmt.Sprintf("%.2f", data), 64)
+	balance, err := data.In(w.currency())
 	if err != nil {
 		return err
 	}
@@ -120,32 +148,44 @@ func (w *WalletResponse) SetBalance(data float64) error {
 	return nil
 }
 
-func (w *WalletResponse) IsEmpty(data *WalletResponse) bool {
-	return data == nil || reflect.DeepEqual(*data, WalletResponse{})
-}
+// ApplyBalance adds a signed delta to the balance
+// income is a positive delta and expense is a negative delta
+func (w *WalletResponse) ApplyBalance(delta Money) error {
 
-func (w *WalletResponse) WalletByPlan(data *string) int {
+	current, err := w.Balance.In(w.currency())
+	if err != nil {
+		return err
+	}
+
+	delta, err = delta.In(w.currency())
+	if err != nil {
+		return err
+	}
 
-	switch *data {
-	case "silver":
-		return 3
-	case "gold":
-		return -1
-	default:
-		return 1
+	balance, err := current.Add(delta)
+	if err != nil {
+		return err
 	}
+
+	w.Balance = balance
+	return nil
 }
 
-func (w *WalletResponse) SharedByPlan(data *string) int {
+// AfterDocumentLoad binds the balance read from the database to the wallet currency
+func (w *WalletResponse) AfterDocumentLoad() error {
+	w.Currency = w.currency()
+	return w.SetBalance(w.Balance)
+}
 
-	switch *data {
-	case "silver":
-		return 2
-	case "gold":
-		return -1
-	default:
-		return 1
+func (w *WalletResponse) currency() string {
+	if w.Currency == "" {
+		return DefaultCurrency
 	}
+	return w.Currency
+}
+
+func (w *WalletResponse) IsEmpty(data *WalletResponse) bool {
+	return data == nil || reflect.DeepEqual(*data, WalletResponse{})
 }
 
 func (w *WalletResponse) SetUpdate() {
@@ -153,25 +193,26 @@ func (w *WalletResponse) SetUpdate() {
 }
 
 func (w *WalletResponse) Share(tenantID string) {
-	w.mu.Lock()
-	defer w.mu.Unlock()
 	w.SharedWithTenants = append(w.SharedWithTenants, tenantID)
 }
 
+// Unshare removes the tenant and its level
 func (w *WalletResponse) Unshare(tenantID string) {
-	w.mu.Lock()
-	defer w.mu.Unlock()
 	for i, id := range w.SharedWithTenants {
 		if id == tenantID {
 			w.SharedWithTenants = append(w.SharedWithTenants[:i], w.SharedWithTenants[i+1:]...)
+			break
+		}
+	}
+	for i, permission := range w.Permissions {
+		if permission.TennantID == tenantID {
+			w.Permissions = append(w.Permissions[:i], w.Permissions[i+1:]...)
 			return
 		}
 	}
 }
 
 func (w *WalletResponse) IsSharedWith(tenantID string) bool {
-	w.mu.Lock()
-	defer w.mu.Unlock()
 	for _, id := range w.SharedWithTenants {
 		if id == tenantID {
 			return true
@@ -179,3 +220,55 @@ func (w *WalletResponse) IsSharedWith(tenantID string) bool {
 	}
 	return false
 }
+
+// Grant shares the wallet with the tenant at the level, or changes the level of a tenant it is shared with
+func (w *WalletResponse) Grant(tenantID string, level PermissionLevel) error {
+	if !IsWalletShareLevel(level) {
+		return ErrInvalidWalletShareLevel
+	}
+
+	if !slices.Contains(w.SharedWithTenants, tenantID) {
+		w.SharedWithTenants = append(w.SharedWithTenants, tenantID)
+	}
+	for i := range w.Permissions {
+		if w.Permissions[i].TennantID == tenantID {
+			w.Permissions[i].Level = level
+			return nil
+		}
+	}
+	w.Permissions = append(w.Permissions, WalletPermission{Level: level, TennantID: tenantID, WalletID: w.ID})
+	return nil
+}
+
+// PermissionOf returns the level of the user on the wallet, empty when the wallet is not visible to the user
+// The owner has owner, the users of the tenant of the wallet have edit and the shared tenants have the granted level.
+// A tenant shared before the levels existed has view.
+func (w *WalletResponse) PermissionOf(userID, tenantID string) PermissionLevel {
+	switch {
+	case userID != "" && w.OwnerID == userID:
+		return PermissionOwner
+	case tenantID != "" && w.TenantID == tenantID:
+		return PermissionEdit
+	}
+
+	for _, permission := range w.Permissions {
+		if permission.TennantID == tenantID {
+			return permission.Level
+		}
+	}
+	if tenantID != "" && w.IsSharedWith(tenantID) {
+		return PermissionView
+	}
+	return ""
+}
+
+// Allows reports if the level of the user on the wallet grants the level
+// A read-only wallet grants no more than view
+func (w *WalletResponse) Allows(userID, tenantID string, level PermissionLevel) bool {
+	if w.ReadOnly && levelRank[level] > levelRank[PermissionView] {
+		return false
+	}
+
+	granted, ok := levelRank[w.PermissionOf(userID, tenantID)]
+	return ok && granted >= levelRank[level]
+}
@@ -0,0 +1,127 @@
+package entity
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Tomelin/financial-management-backend/pkg/utils"
+	"github.com/google/uuid"
+)
+
+// WalletInvitationTTL is how long an invitation to a wallet can be accepted
+const WalletInvitationTTL = 7 * 24 * time.Hour
+
+// ErrInvalidWalletShareLevel is returned when the level of a share is not view, edit or admin
+var ErrInvalidWalletShareLevel = errors.New("the level of a share is view, edit or admin")
+
+type InvitationStatus string
+
+const (
+	InvitationPending  InvitationStatus = "pending"
+	InvitationAccepted InvitationStatus = "accepted"
+	InvitationDeclined InvitationStatus = "declined"
+	InvitationExpired  InvitationStatus = "expired"
+	InvitationCanceled InvitationStatus = "canceled"
+)
+
+type IWalletShare interface {
+	// Invite invites the email to the wallet, the user needs admin on the wallet
+	Invite(ctx context.Context, userId *string, walletId *string, data *WalletInvitationRequest) (*WalletInvitation, *ModuleError)
+	// GetInvitations returns the invitations of the wallet, the newest first
+	GetInvitations(ctx context.Context, userId *string, walletId *string) ([]WalletInvitation, *ModuleError)
+	CancelInvitation(ctx context.Context, userId *string, walletId *string, invitationId *string) *ModuleError
+	// GetReceivedInvitations returns the pending invitations to the email of the user
+	GetReceivedInvitations(ctx context.Context, userId *string) ([]WalletInvitation, *ModuleError)
+	// Accept shares the wallet of the invitation with the tenant of the user
+	Accept(ctx context.Context, userId *string, invitationId *string) (*WalletResponse, *ModuleError)
+	Decline(ctx context.Context, userId *string, invitationId *string) *ModuleError
+	// SetPermission changes the level of a tenant the wallet is shared with
+	SetPermission(ctx context.Context, userId *string, walletId *string, tenantId *string, level PermissionLevel) (*WalletResponse, *ModuleError)
+	// Unshare removes a tenant of the wallet, an admin removes any tenant and a tenant can leave the wallet
+	Unshare(ctx context.Context, userId *string, walletId *string, tenantId *string) *ModuleError
+}
+
+// WalletInvitation invites the user of an email to a wallet
+// On accept, the wallet is shared with the tenant of the user at the level of the invitation
+type WalletInvitation struct {
+	ID          string           `json:"id" firestore:"id"`
+	WalletID    string           `json:"wallet_id" firestore:"wallet_id"`
+	WalletName  string           `json:"wallet_name" firestore:"wallet_name"`
+	InvitedBy   string           `json:"invited_by" firestore:"invited_by"`
+	Email       string           `json:"email" firestore:"email"`
+	Level       PermissionLevel  `json:"level" firestore:"level"`
+	Status      InvitationStatus `json:"status" firestore:"status"`
+	ExpiresAt   time.Time        `json:"expires_at" firestore:"expires_at"`
+	CreatedAt   time.Time        `json:"created_at" firestore:"create_at"`
+	RespondedAt time.Time        `json:"responded_at,omitempty" firestore:"responded_at"`
+}
+
+type WalletInvitationRequest struct {
+	Email string          `json:"email" binding:"required"`
+	Level PermissionLevel `json:"level" binding:"required"`
+}
+
+type WalletPermissionRequest struct {
+	Level PermissionLevel `json:"level" binding:"required"`
+}
+
+// IsWalletShareLevel reports if the level can be granted to a tenant, the owner is not shared
+func IsWalletShareLevel(level PermissionLevel) bool {
+	switch level {
+	case PermissionView, PermissionEdit, PermissionAdmin:
+		return true
+	}
+	return false
+}
+
+// NewWalletInvitation creates a pending invitation to the wallet
+func NewWalletInvitation(wallet *WalletResponse, invitedBy string, data *WalletInvitationRequest) (*WalletInvitation, *ModuleError) {
+
+	if wallet == nil || wallet.ID == "" || data == nil {
+		return nil, Error("wallet and invitation are required", "wallet", "NewWalletInvitation", ApplicationLayerEntity, ResponseCodeBadRequest)
+	}
+
+	email := NormalizeEmail(data.Email)
+	if !utils.IsValidEmail(email) {
+		return nil, Error("email is invalid", "wallet", "NewWalletInvitation", ApplicationLayerEntity, ResponseCodeBadRequest)
+	}
+
+	if !IsWalletShareLevel(data.Level) {
+		return nil, Error(ErrInvalidWalletShareLevel.Error(), "wallet", "NewWalletInvitation", ApplicationLayerEntity, ResponseCodeBadRequest)
+	}
+
+	id, _ := uuid.NewV7()
+	now := time.Now().UTC()
+	return &WalletInvitation{
+		ID:         id.String(),
+		WalletID:   wallet.ID,
+		WalletName: wallet.Name,
+		InvitedBy:  invitedBy,
+		Email:      email,
+		Level:      data.Level,
+		Status:     InvitationPending,
+		ExpiresAt:  now.Add(WalletInvitationTTL),
+		CreatedAt:  now,
+	}, nil
+}
+
+// Refresh marks a pending invitation past its expiration as expired, it reports if the status changed
+func (i *WalletInvitation) Refresh(now time.Time) bool {
+	if i.Status == InvitationPending && !now.Before(i.ExpiresAt) {
+		i.Status = InvitationExpired
+		return true
+	}
+	return false
+}
+
+// IsPending reports if the invitation can still be accepted or declined
+func (i *WalletInvitation) IsPending(now time.Time) bool {
+	return i.Status == InvitationPending && now.Before(i.ExpiresAt)
+}
+
+// Respond closes a pending invitation with the status
+func (i *WalletInvitation) Respond(status InvitationStatus, now time.Time) {
+	i.Status = status
+	i.RespondedAt = now
+}
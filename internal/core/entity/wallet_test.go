@@ -24,7 +24,7 @@ func (s *WalletTestSuite) SetupTest() {
 		Description: "Wallet description",
 		OwnerID:     ownerID,
 		TenantID:    tenantID,
-		Balance:     0,
+		Balance:     entity.NewMoney(0, "BRL"),
 		Currency:    "BRL",
 	}
 }
@@ -50,7 +50,15 @@ func (s *WalletTestSuite) TestNewWallet_EmptyID() {
 	walletResponse, err := entity.NewWallet(s.walletResponse)
 	s.NotNil(walletResponse)
 	s.Nil(err)
-	s.Equal(s.walletResponse.ID, walletResponse.ID)
+	s.NotEmpty(walletResponse.ID)
+}
+
+func (s *WalletTestSuite) TestNewWallet_GeneratesID() {
+
+	s.walletResponse.ID = uuid.New().String()
+	walletResponse, err := entity.NewWallet(s.walletResponse)
+	s.Nil(err)
+	s.NotEqual(s.walletResponse.ID, walletResponse.ID, "the id of the request body is ignored")
 }
 
 func (s *WalletTestSuite) TestNewWallet_EmptyCurrency() {
@@ -109,16 +117,16 @@ func (s *WalletTestSuite) TestNewWallet_Error_VaidateID() {
 
 func (s *WalletTestSuite) TestNewWallet_SetBalanceZero() {
 
-	err := s.walletResponse.SetBalance(0.0)
+	err := s.walletResponse.SetBalance(entity.NewMoney(0, "BRL"))
 	s.Nil(err)
-	s.Equal(0.0, s.walletResponse.Balance)
+	s.Equal(int64(0), s.walletResponse.Balance.Amount)
 }
 
 func (s *WalletTestSuite) TestNewWallet_SetBalanceFloat100() {
 
-	err := s.walletResponse.SetBalance(100)
+	err := s.walletResponse.SetBalance(entity.NewMoney(10000, "BRL"))
 	s.Nil(err)
-	s.Equal(float64(100), s.walletResponse.Balance)
+	s.Equal(float64(100), s.walletResponse.Balance.Float64())
 }
 
 func (s *WalletTestSuite) TestNewWallet_SetUpdate() {
@@ -130,43 +138,11 @@ func (s *WalletTestSuite) TestNewWallet_SetUpdate() {
 	s.NotEmpty(s.walletResponse.UpdatedAt)
 }
 
-func (s *WalletTestSuite) TestNewWallet_WalletByPlan() {
-	silverPlan := "silver"
-	silver := 3
-	goldPlan := "gold"
-	gold := -1
-	bronzelan := "bronze"
-	bronze := 1
-	emptyPLan := ""
-
-	s.Equal(silver, s.walletResponse.WalletByPlan(&silverPlan))
-	s.Equal(gold, s.walletResponse.WalletByPlan(&goldPlan))
-	s.Equal(bronze, s.walletResponse.WalletByPlan(&bronzelan))
-	s.NotEqual(silver, s.walletResponse.WalletByPlan(&goldPlan))
-	s.Equal(bronze, s.walletResponse.WalletByPlan(&emptyPLan))
-}
-
-func (s *WalletTestSuite) TestNewWallet_SharedByPlan() {
-	silverPlan := "silver"
-	silver := 2
-	goldPlan := "gold"
-	gold := -1
-	bronzelan := "bronze"
-	bronze := 1
-	emptyPLan := ""
-
-	s.Equal(silver, s.walletResponse.SharedByPlan(&silverPlan))
-	s.Equal(gold, s.walletResponse.SharedByPlan(&goldPlan))
-	s.Equal(bronze, s.walletResponse.SharedByPlan(&bronzelan))
-	s.NotEqual(silver, s.walletResponse.SharedByPlan(&goldPlan))
-	s.Equal(bronze, s.walletResponse.SharedByPlan(&emptyPLan))
-}
-
 func (s *WalletTestSuite) TestNewWallet_Error_SetBalance100() {
 
-	err := s.walletResponse.SetBalance(100)
+	err := s.walletResponse.SetBalance(entity.NewMoney(100, "BRL"))
 	s.Nil(err)
-	s.NotEqual(100, s.walletResponse.Balance)
+	s.NotEqual(100, s.walletResponse.Balance.Float64())
 }
 
 func (s *WalletTestSuite) TestNewWallet_Validate() {
@@ -189,3 +165,79 @@ func (s *WalletTestSuite) TestNewWallet_Success() {
 func TestRunWalletTestSuite(t *testing.T) {
 	suite.Run(t, new(WalletTestSuite))
 }
+
+func (s *WalletTestSuite) TestSetBalance_Error_Currency() {
+
+	err := s.walletResponse.SetBalance(entity.NewMoney(100, "USD"))
+	s.ErrorIs(err, entity.ErrMoneyCurrencyMismatch)
+}
+
+func (s *WalletTestSuite) TestApplyBalance() {
+
+	s.Nil(s.walletResponse.ApplyBalance(entity.NewMoney(10000, "BRL")))
+	s.Nil(s.walletResponse.ApplyBalance(entity.NewMoney(-2550, "BRL")))
+	s.Equal(entity.NewMoney(7450, "BRL"), s.walletResponse.Balance)
+}
+
+func (s *WalletTestSuite) TestAfterDocumentLoad_LegacyFloat() {
+
+	s.Nil(s.walletResponse.Balance.ScanDocumentValue(0.1 + 0.2))
+	s.Nil(s.walletResponse.AfterDocumentLoad())
+	s.Equal(entity.NewMoney(30, "BRL"), s.walletResponse.Balance)
+}
+
+func (s *WalletTestSuite) TestGrantAndPermissionOf() {
+	w := s.walletResponse
+	shared, other := uuid.New().String(), uuid.New().String()
+
+	s.Equal(entity.PermissionOwner, w.PermissionOf(w.OwnerID, w.TenantID))
+	s.Equal(entity.PermissionEdit, w.PermissionOf(uuid.New().String(), w.TenantID), "the users of the tenant edit the wallet")
+	s.Empty(w.PermissionOf(uuid.New().String(), shared))
+
+	s.ErrorIs(w.Grant(shared, entity.PermissionOwner), entity.ErrInvalidWalletShareLevel)
+	s.Require().NoError(w.Grant(shared, entity.PermissionView))
+	s.Equal(entity.PermissionView, w.PermissionOf(uuid.New().String(), shared))
+	s.True(w.Allows(uuid.New().String(), shared, entity.PermissionView))
+	s.False(w.Allows(uuid.New().String(), shared, entity.PermissionEdit))
+
+	s.Require().NoError(w.Grant(shared, entity.PermissionAdmin))
+	s.Equal([]string{shared}, w.SharedWithTenants, "a new level does not share the wallet again")
+	s.Len(w.Permissions, 1)
+	s.True(w.Allows(uuid.New().String(), shared, entity.PermissionEdit))
+	s.False(w.Allows(uuid.New().String(), shared, entity.PermissionOwner))
+
+	w.Share(other)
+	s.Equal(entity.PermissionView, w.PermissionOf(uuid.New().String(), other), "a tenant shared without a level views the wallet")
+
+	w.Unshare(shared)
+	s.Empty(w.PermissionOf(uuid.New().String(), shared))
+	s.Empty(w.Permissions)
+	s.Equal([]string{other}, w.SharedWithTenants)
+
+	w.ReadOnly = true
+	s.Equal(entity.PermissionOwner, w.PermissionOf(w.OwnerID, w.TenantID))
+	s.True(w.Allows(w.OwnerID, w.TenantID, entity.PermissionView))
+	s.False(w.Allows(w.OwnerID, w.TenantID, entity.PermissionEdit), "a read-only wallet is only viewed")
+}
+
+func (s *WalletTestSuite) TestWalletInvitation() {
+	_, mErr := entity.NewWalletInvitation(s.walletResponse, s.walletResponse.OwnerID, &entity.WalletInvitationRequest{Email: "invalid", Level: entity.PermissionView})
+	s.NotNil(mErr)
+	_, mErr = entity.NewWalletInvitation(s.walletResponse, s.walletResponse.OwnerID, &entity.WalletInvitationRequest{Email: "user@domain.com", Level: entity.PermissionOwner})
+	s.NotNil(mErr)
+
+	invitation, mErr := entity.NewWalletInvitation(s.walletResponse, s.walletResponse.OwnerID, &entity.WalletInvitationRequest{Email: " User@Domain.com ", Level: entity.PermissionEdit})
+	s.Require().Nil(mErr)
+	s.Equal("user@domain.com", invitation.Email)
+	s.Equal(entity.InvitationPending, invitation.Status)
+	s.Equal(s.walletResponse.Name, invitation.WalletName)
+
+	now := time.Now().UTC()
+	s.True(invitation.IsPending(now))
+	s.False(invitation.Refresh(now))
+
+	later := now.Add(entity.WalletInvitationTTL + time.Minute)
+	s.False(invitation.IsPending(later))
+	s.True(invitation.Refresh(later))
+	s.Equal(entity.InvitationExpired, invitation.Status)
+}
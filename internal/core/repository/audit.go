@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/pkg/db"
+)
+
+const auditLogsCollection = "audit_logs"
+
+type IAuditRepo interface {
+	CreateAuditLog(ctx context.Context, data *entity.AuditLog) *entity.ModuleError
+	GetAuditLogs(ctx context.Context, page *entity.PageRequest) (*entity.Page[entity.AuditLog], *entity.ModuleError)
+}
+
+type AuditRepo struct {
+	db db.DocumentStore
+}
+
+// NewAuditRepo creates the repository of the audit log of the database kind
+func NewAuditRepo(database db.Database) (IAuditRepo, error) {
+	switch conn := database.(type) {
+	case *db.SQLDatabase:
+		return NewAuditSQLRepo(conn)
+	case db.DocumentStore:
+		return &AuditRepo{db: conn}, nil
+	}
+
+	return nil, errors.New("database is required")
+}
+
+func (a *AuditRepo) CreateAuditLog(ctx context.Context, data *entity.AuditLog) *entity.ModuleError {
+	if err := a.db.Collection(auditLogsCollection).Doc(data.ID).Set(ctx, *data); err != nil {
+		return entity.Error(err.Error(), "audit", "CreateAuditLog", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+	return nil
+}
+
+func (a *AuditRepo) GetAuditLogs(ctx context.Context, page *entity.PageRequest) (*entity.Page[entity.AuditLog], *entity.ModuleError) {
+	logs, err := documentPage(ctx, a.db.Collection(auditLogsCollection), page, func(doc *db.Document) (entity.AuditLog, error) {
+		var log entity.AuditLog
+		err := doc.DataTo(&log)
+		return log, err
+	})
+	if errors.Is(err, entity.ErrInvalidPage) {
+		return nil, entity.Error(err.Error(), "audit", "GetAuditLogs", entity.ApplicationLayerRepository, entity.ResponseCodeBadRequest)
+	}
+	if err != nil {
+		return nil, entity.Error(err.Error(), "audit", "GetAuditLogs", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+	return logs, nil
+}
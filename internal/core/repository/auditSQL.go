@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/pkg/db"
+)
+
+var auditTable = sqlTable{
+	name: "audit_logs",
+	columns: map[string]sqlKind{
+		"id":          sqlText,
+		"actor_id":    sqlText,
+		"action":      sqlText,
+		"resource_id": sqlText,
+		"create_at":   sqlTime,
+	},
+}
+
+const auditColumns = `id, actor_id, actor_email, action, resource_id, method, path, status, ip, create_at`
+
+type AuditSQLRepo struct {
+	db *db.SQLDatabase
+}
+
+// NewAuditSQLRepo creates the repository of the audit log of a relational database
+func NewAuditSQLRepo(database *db.SQLDatabase) (IAuditRepo, error) {
+	if database == nil {
+		return nil, errors.New("database is required")
+	}
+
+	return &AuditSQLRepo{db: database}, nil
+}
+
+func (a *AuditSQLRepo) CreateAuditLog(ctx context.Context, data *entity.AuditLog) *entity.ModuleError {
+	_, err := a.db.DB.ExecContext(ctx, a.db.Rebind(`INSERT INTO audit_logs (`+auditColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`),
+		data.ID, data.ActorID, data.ActorEmail, data.Action, data.ResourceID, data.Method, data.Path, data.Status, data.IP, data.CreatedAt)
+	if err != nil {
+		return entity.Error(err.Error(), "audit", "CreateAuditLog", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+	return nil
+}
+
+func (a *AuditSQLRepo) GetAuditLogs(ctx context.Context, page *entity.PageRequest) (*entity.Page[entity.AuditLog], *entity.ModuleError) {
+	logs, err := sqlPage(ctx, a.db, auditTable, page, "", nil, a.query)
+	if errors.Is(err, entity.ErrInvalidPage) {
+		return nil, entity.Error(err.Error(), "audit", "GetAuditLogs", entity.ApplicationLayerRepository, entity.ResponseCodeBadRequest)
+	}
+	if err != nil {
+		return nil, entity.Error(err.Error(), "audit", "GetAuditLogs", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+	return logs, nil
+}
+
+func (a *AuditSQLRepo) query(ctx context.Context, where, order string, limit int, args ...any) ([]entity.AuditLog, error) {
+	rows, err := a.db.DB.QueryContext(ctx, a.db.Rebind(auditTable.selectQuery(auditColumns, where, order, limit)), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	logs := []entity.AuditLog{}
+	for rows.Next() {
+		var i entity.AuditLog
+		if err := rows.Scan(&i.ID, &i.ActorID, &i.ActorEmail, &i.Action, &i.ResourceID, &i.Method, &i.Path, &i.Status, &i.IP, &i.CreatedAt); err != nil {
+			return nil, err
+		}
+		logs = append(logs, i)
+	}
+	return logs, rows.Err()
+}
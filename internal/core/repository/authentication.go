@@ -4,153 +4,320 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
 	"time"
 
-	"cloud.google.com/go/firestore"
 	"github.com/Tomelin/financial-management-backend/internal/core/entity"
 	"github.com/Tomelin/financial-management-backend/pkg/db"
 	"github.com/Tomelin/financial-management-backend/pkg/logger"
 )
 
+const (
+	accessTokensCollection     = "access_tokens"
+	refreshTokensCollection    = "refresh_tokens"
+	tokenGenerationsCollection = "token_generations"
+	sessionsCollection         = "sessions"
+)
+
 type IAuthorizationRepo interface {
-	entity.IAuthorization
+	// GenerateTokenJWT stores the record of the access token of the claims
+	GenerateTokenJWT(ctx context.Context, token *entity.AuthorizationClaims, user *entity.AccountUser) (*string, error)
+	RevokeTokenJWT(ctx context.Context, tokenId *string) error
+	// IsRevokedTokenJWT reports if the token of the id was revoked, an unknown token is not revoked
+	IsRevokedTokenJWT(ctx context.Context, tokenId *string) (bool, error)
+
+	CreateRefreshToken(ctx context.Context, token *entity.RefreshToken) error
+	// GetRefreshToken returns nil when the refresh token does not exist
+	GetRefreshToken(ctx context.Context, id string) (*entity.RefreshToken, error)
+	// UseRefreshToken marks the refresh token as used, it reports false when it was already used or revoked
+	UseRefreshToken(ctx context.Context, id string) (bool, error)
+	RevokeRefreshTokenFamily(ctx context.Context, family string) error
+
+	// GetTokenGeneration returns the token generation of the user, 0 when it was never bumped
+	GetTokenGeneration(ctx context.Context, userID string) (int64, error)
+	// IncrementTokenGeneration bumps the token generation of the user and returns the new one
+	IncrementTokenGeneration(ctx context.Context, userID string) (int64, error)
+
+	// SaveSession creates or replaces the session
+	SaveSession(ctx context.Context, session *entity.Session) error
+	// GetSession returns nil when the session does not exist
+	GetSession(ctx context.Context, id string) (*entity.Session, error)
+	// ListSessions returns the sessions of the user, the last seen first
+	ListSessions(ctx context.Context, userID string) ([]entity.Session, error)
+	DeleteSession(ctx context.Context, id string) error
 }
+
 type AuthorizationRepo struct {
-	db  db.FirebaseDatabaseInterface
+	db  db.DocumentStore
 	log logger.Logger
 }
 
-func NewAuthorizationRepo(db db.FirebaseDatabaseInterface, l logger.Logger) (IAuthorizationRepo, error) {
+// NewAuthorizationRepo creates the authorization repository of the database kind
+func NewAuthorizationRepo(database db.Database, l logger.Logger) (IAuthorizationRepo, error) {
 
-	if db == nil {
-		return nil, errors.New("db é obrigatório")
+	switch conn := database.(type) {
+	case *db.SQLDatabase:
+		return NewAuthorizationSQLRepo(conn, l)
+	case db.DocumentStore:
+		return &AuthorizationRepo{
+			db:  conn,
+			log: l,
+		}, nil
 	}
 
-	return &AuthorizationRepo{
-		db:  db,
-		log: l,
-	}, nil
+	return nil, errors.New("db é obrigatório")
 }
 
 func (a *AuthorizationRepo) GenerateTokenJWT(ctx context.Context, token *entity.AuthorizationClaims, user *entity.AccountUser) (*string, error) {
 
-	docRef := a.db.Collection("refresh_tokens").Doc(user.ID)
-	_, err := docRef.Set(ctx, *user)
+	docRef := a.db.Collection(accessTokensCollection).Doc(token.StandardClaims.Id)
+	err := docRef.Set(ctx, *token)
 	if err != nil {
 		return nil, a.log.Error(&logger.Message{
-			Body: fmt.Sprintf("erro ao salvar o refresh token no Firestore GenerateTokenJWT: %s", err.Error()),
+			Body: fmt.Sprintf("erro ao salvar o token no Firestore GenerateTokenJWT: %s", err.Error()),
 			Code: logger.ResponseCodeInternalServer})
 
 	}
 	return &token.StandardClaims.Id, nil
 }
 
-func (a *AuthorizationRepo) ValidateTokenJWT(ctx context.Context, token string) (*entity.AccountUser, error) {
-	return nil, nil
-}
-
-func (a *AuthorizationRepo) RevokeTokenJWT(ctx context.Context, tokenId *string) error {
-	docRef := a.getToken(ctx, tokenId)
-	if docRef == nil {
-		log.Println("docRef is nil", *tokenId)
-	} else {
-		log.Println("docRef is not nil", *docRef)
+func (a *AuthorizationRepo) IsRevokedTokenJWT(ctx context.Context, tokenId *string) (bool, error) {
+	doc, err := a.db.Collection(accessTokensCollection).Doc(*tokenId).Get(ctx)
+	if errors.Is(err, db.ErrNotFound) {
+		return false, nil
 	}
-	var refreshToken entity.AuthorizationClaims
-
-	err := docRef.DataTo(&refreshToken)
 	if err != nil {
-		return a.log.Error(&logger.Message{
-			Body: fmt.Sprintf("erro ao buscar refresh token: %s", err.Error()),
+		return false, a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao buscar o token IsRevokedTokenJWT: %s", err.Error()),
 			Code: logger.ResponseCodeInternalServer})
 	}
 
-	if docRef == nil {
-		return a.log.Error(&logger.Message{
-			Body: fmt.Sprintf("erro ao buscar refresh token: %s", err.Error()),
+	var token entity.AuthorizationClaims
+	if err := doc.DataTo(&token); err != nil {
+		return false, a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao buscar o token IsRevokedTokenJWT: %s", err.Error()),
 			Code: logger.ResponseCodeInternalServer})
 	}
 
-	if refreshToken.Email == "" {
-		return a.log.Error(&logger.Message{
-			Body: "refresh token não encontrado",
-			Code: logger.ResponseCodeInternalServer})
-	}
+	return token.IsRevoked, nil
+}
 
-	refreshToken.ExpiresAt = time.Now().Unix() - 10
-	refreshToken.IsRevoked = true
-	_, err = docRef.Ref.Update(ctx, []firestore.Update{
-		{
+func (a *AuthorizationRepo) RevokeTokenJWT(ctx context.Context, tokenId *string) error {
+
+	err := a.db.Collection(accessTokensCollection).Doc(*tokenId).Update(ctx,
+		db.Update{
 			Path:  "is_revoked",
-			Value: refreshToken.IsRevoked,
+			Value: true,
 		},
-		{
-			Path:  "expiresAt",
-			Value: refreshToken.ExpiresAt,
+		db.Update{
+			Path:  "ExpiresAt",
+			Value: time.Now().Unix() - 10,
 		},
-	})
+	)
+	if errors.Is(err, db.ErrNotFound) {
+		return a.log.Error(&logger.Message{
+			Body: "token não encontrado",
+			Code: logger.ResponseCodeInternalServer})
+	}
 	if err != nil {
 		return a.log.Error(&logger.Message{
-			Body: fmt.Sprintf("erro ao buscar refresh token: %w", err.Error()),
+			Body: fmt.Sprintf("erro ao revogar o token: %s", err.Error()),
 			Code: logger.ResponseCodeInternalServer})
 	}
 
 	return nil
 }
 
-func (a *AuthorizationRepo) RefreshTokenJWT(ctx context.Context, tokenId *string) (*string, error) {
+func (a *AuthorizationRepo) CreateRefreshToken(ctx context.Context, token *entity.RefreshToken) error {
+	err := a.db.Collection(refreshTokensCollection).Doc(token.ID).Set(ctx, *token)
+	if err != nil {
+		return a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao salvar o refresh token CreateRefreshToken: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+	return nil
+}
 
-	docRef := a.db.Collection("refresh_tokens").Where("token", "==", &tokenId).Documents(ctx)
-	docsnap, err := docRef.GetAll()
+func (a *AuthorizationRepo) GetRefreshToken(ctx context.Context, id string) (*entity.RefreshToken, error) {
+	doc, err := a.db.Collection(refreshTokensCollection).Doc(id).Get(ctx)
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, nil
+	}
 	if err != nil {
 		return nil, a.log.Error(&logger.Message{
-			Body: fmt.Sprintf("erro ao buscar refresh token: %s", err.Error()),
+			Body: fmt.Sprintf("erro ao buscar o refresh token GetRefreshToken: %s", err.Error()),
 			Code: logger.ResponseCodeInternalServer})
 	}
 
-	if len(docsnap) == 0 {
+	var token entity.RefreshToken
+	if err := doc.DataTo(&token); err != nil {
 		return nil, a.log.Error(&logger.Message{
-			Body: "refresh token não encontrado",
+			Body: fmt.Sprintf("erro ao buscar o refresh token GetRefreshToken: %s", err.Error()),
 			Code: logger.ResponseCodeInternalServer})
 	}
+	return &token, nil
+}
+
+func (a *AuthorizationRepo) UseRefreshToken(ctx context.Context, id string) (bool, error) {
+	ref := a.db.Collection(refreshTokensCollection).Doc(id)
+
+	var used bool
+	err := a.db.RunTransaction(ctx, func(ctx context.Context, tx db.Transaction) error {
+		used = false
 
-	var refreshToken entity.AuthorizationClaims
-	err = docsnap[0].DataTo(&refreshToken)
+		doc, err := tx.Get(ref)
+		if err != nil {
+			return err
+		}
+
+		var token entity.RefreshToken
+		if err := doc.DataTo(&token); err != nil {
+			return err
+		}
+		if token.Used || token.Revoked {
+			return nil
+		}
+
+		used = true
+		return tx.Update(ref, db.Update{Path: "used", Value: true})
+	})
+	if errors.Is(err, db.ErrNotFound) {
+		return false, nil
+	}
 	if err != nil {
-		return nil, a.log.Error(&logger.Message{
-			Body: fmt.Sprintf("erro ao buscar refresh token: %s", err.Error()),
+		return false, a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao usar o refresh token UseRefreshToken: %s", err.Error()),
 			Code: logger.ResponseCodeInternalServer})
 	}
-	if refreshToken.IsRevoked {
-		return nil, a.log.Error(&logger.Message{
-			Body: "refresh token revogado",
+
+	return used, nil
+}
+
+func (a *AuthorizationRepo) RevokeRefreshTokenFamily(ctx context.Context, family string) error {
+	docs, err := a.db.Collection(refreshTokensCollection).Where("family", "==", family).Documents(ctx)
+	if err != nil {
+		return a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao revogar os refresh tokens RevokeRefreshTokenFamily: %s", err.Error()),
 			Code: logger.ResponseCodeInternalServer})
 	}
-	if refreshToken.StandardClaims.VerifyNotBefore(time.Now().Unix(), true) {
-		return nil, a.log.Error(&logger.Message{
-			Body: "refresh token expirado",
+
+	for _, doc := range docs {
+		err := a.db.Collection(refreshTokensCollection).Doc(doc.ID).Update(ctx, db.Update{Path: "revoked", Value: true})
+		if err != nil && !errors.Is(err, db.ErrNotFound) {
+			return a.log.Error(&logger.Message{
+				Body: fmt.Sprintf("erro ao revogar os refresh tokens RevokeRefreshTokenFamily: %s", err.Error()),
+				Code: logger.ResponseCodeInternalServer})
+		}
+	}
+
+	return nil
+}
+
+func (a *AuthorizationRepo) GetTokenGeneration(ctx context.Context, userID string) (int64, error) {
+	doc, err := a.db.Collection(tokenGenerationsCollection).Doc(userID).Get(ctx)
+	if errors.Is(err, db.ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao buscar a geração dos tokens GetTokenGeneration: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+
+	var generation entity.TokenGeneration
+	if err := doc.DataTo(&generation); err != nil {
+		return 0, a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao buscar a geração dos tokens GetTokenGeneration: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+	return generation.Generation, nil
+}
+
+func (a *AuthorizationRepo) IncrementTokenGeneration(ctx context.Context, userID string) (int64, error) {
+	ref := a.db.Collection(tokenGenerationsCollection).Doc(userID)
+
+	var generation entity.TokenGeneration
+	err := a.db.RunTransaction(ctx, func(ctx context.Context, tx db.Transaction) error {
+		generation = entity.TokenGeneration{UserID: userID}
+
+		doc, err := tx.Get(ref)
+		if err != nil && !errors.Is(err, db.ErrNotFound) {
+			return err
+		}
+		if err == nil {
+			if err := doc.DataTo(&generation); err != nil {
+				return err
+			}
+		}
+
+		generation.Generation++
+		generation.UpdatedAt = time.Now().UTC()
+		return tx.Set(ref, generation)
+	})
+	if err != nil {
+		return 0, a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao incrementar a geração dos tokens IncrementTokenGeneration: %s", err.Error()),
 			Code: logger.ResponseCodeInternalServer})
 	}
-	return nil, nil
+
+	return generation.Generation, nil
 }
 
-func (a *AuthorizationRepo) ParseTokenJWT(ctx context.Context, token string) error {
+func (a *AuthorizationRepo) SaveSession(ctx context.Context, session *entity.Session) error {
+	err := a.db.Collection(sessionsCollection).Doc(session.ID).Set(ctx, *session)
+	if err != nil {
+		return a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao salvar a sessão SaveSession: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
 	return nil
 }
 
-func (a *AuthorizationRepo) getToken(ctx context.Context, tokenId *string) *firestore.DocumentSnapshot {
-	docRef, _ := a.db.Collection("refresh_tokens").Where("id", "==", *tokenId).Documents(ctx).Next()
+func (a *AuthorizationRepo) GetSession(ctx context.Context, id string) (*entity.Session, error) {
+	doc, err := a.db.Collection(sessionsCollection).Doc(id).Get(ctx)
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao buscar a sessão GetSession: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
 
-	return docRef
+	var session entity.Session
+	if err := doc.DataTo(&session); err != nil {
+		return nil, a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao buscar a sessão GetSession: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+	return &session, nil
 }
 
-func (a *AuthorizationRepo) StoreTokenJWT(ctx context.Context, token []byte, userId *string) error {
-	docRef := a.db.Collection("refresh_tokens").Doc(*userId)
-	_, err := docRef.Set(ctx, string(token))
+func (a *AuthorizationRepo) ListSessions(ctx context.Context, userID string) ([]entity.Session, error) {
+	docs, err := a.db.Collection(sessionsCollection).Where("user_id", db.OpEqual, userID).OrderBy("last_seen_at", db.Desc).Documents(ctx)
 	if err != nil {
+		return nil, a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao buscar as sessões ListSessions: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+
+	sessions := make([]entity.Session, 0, len(docs))
+	for _, doc := range docs {
+		var session entity.Session
+		if err := doc.DataTo(&session); err != nil {
+			return nil, a.log.Error(&logger.Message{
+				Body: fmt.Sprintf("erro ao buscar as sessões ListSessions: %s", err.Error()),
+				Code: logger.ResponseCodeInternalServer})
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, nil
+}
+
+func (a *AuthorizationRepo) DeleteSession(ctx context.Context, id string) error {
+	err := a.db.Collection(sessionsCollection).Doc(id).Delete(ctx)
+	if err != nil && !errors.Is(err, db.ErrNotFound) {
 		return a.log.Error(&logger.Message{
-			Body: fmt.Sprintf("erro ao salvar o refresh token no Firestore StoreTokenJWT: %s", err.Error()),
+			Body: fmt.Sprintf("erro ao remover a sessão DeleteSession: %s", err.Error()),
 			Code: logger.ResponseCodeInternalServer})
 	}
 	return nil
@@ -0,0 +1,250 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/pkg/db"
+	"github.com/Tomelin/financial-management-backend/pkg/logger"
+)
+
+const refreshTokenColumns = `id, user_id, family, expires_at, revoked, used, generation, create_at`
+
+const sessionColumns = `id, user_id, provider, user_agent, ip, access_token_id, generation, create_at, last_seen_at`
+
+type AuthorizationSQLRepo struct {
+	db  *db.SQLDatabase
+	log logger.Logger
+}
+
+// NewAuthorizationSQLRepo creates the authorization repository of a relational database
+func NewAuthorizationSQLRepo(database *db.SQLDatabase, l logger.Logger) (IAuthorizationRepo, error) {
+
+	if database == nil {
+		return nil, errors.New("db é obrigatório")
+	}
+
+	return &AuthorizationSQLRepo{
+		db:  database,
+		log: l,
+	}, nil
+}
+
+func (a *AuthorizationSQLRepo) GenerateTokenJWT(ctx context.Context, token *entity.AuthorizationClaims, user *entity.AccountUser) (*string, error) {
+
+	claims, err := jsonValue(token)
+	if err != nil {
+		return nil, a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao salvar o token GenerateTokenJWT: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+
+	_, err = a.db.DB.ExecContext(ctx, a.db.Rebind(`INSERT INTO access_tokens (id, user_id, email, claims, is_revoked, expires_at)
+VALUES (?, ?, ?, ?, ?, ?)
+ON CONFLICT (id) DO UPDATE SET
+    user_id = excluded.user_id,
+    email = excluded.email,
+    claims = excluded.claims,
+    is_revoked = excluded.is_revoked,
+    expires_at = excluded.expires_at`),
+		token.StandardClaims.Id, user.ID, user.Email, claims, token.IsRevoked, token.ExpiresAt)
+	if err != nil {
+		return nil, a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao salvar o token GenerateTokenJWT: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+
+	return &token.StandardClaims.Id, nil
+}
+
+func (a *AuthorizationSQLRepo) IsRevokedTokenJWT(ctx context.Context, tokenId *string) (bool, error) {
+
+	var isRevoked bool
+	err := a.db.DB.QueryRowContext(ctx, a.db.Rebind(`SELECT is_revoked FROM access_tokens WHERE id = ?`), *tokenId).Scan(&isRevoked)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao buscar o token IsRevokedTokenJWT: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+
+	return isRevoked, nil
+}
+
+func (a *AuthorizationSQLRepo) RevokeTokenJWT(ctx context.Context, tokenId *string) error {
+
+	result, err := a.db.DB.ExecContext(ctx, a.db.Rebind(`UPDATE access_tokens SET is_revoked = ?, expires_at = ? WHERE id = ?`),
+		true, time.Now().Unix()-10, *tokenId)
+	if err != nil {
+		return a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao revogar o token: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return a.log.Error(&logger.Message{
+			Body: "token não encontrado",
+			Code: logger.ResponseCodeInternalServer})
+	}
+
+	return nil
+}
+
+func (a *AuthorizationSQLRepo) CreateRefreshToken(ctx context.Context, token *entity.RefreshToken) error {
+	_, err := a.db.DB.ExecContext(ctx, a.db.Rebind(`INSERT INTO refresh_tokens (`+refreshTokenColumns+`)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)`),
+		token.ID, token.UserID, token.Family, token.ExpiresAt, token.Revoked, token.Used, token.Generation, token.CreatedAt)
+	if err != nil {
+		return a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao salvar o refresh token CreateRefreshToken: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+	return nil
+}
+
+func (a *AuthorizationSQLRepo) GetRefreshToken(ctx context.Context, id string) (*entity.RefreshToken, error) {
+	var token entity.RefreshToken
+	err := a.db.DB.QueryRowContext(ctx, a.db.Rebind(`SELECT `+refreshTokenColumns+` FROM refresh_tokens WHERE id = ?`), id).
+		Scan(&token.ID, &token.UserID, &token.Family, &token.ExpiresAt, &token.Revoked, &token.Used, &token.Generation, &token.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao buscar o refresh token GetRefreshToken: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+	return &token, nil
+}
+
+func (a *AuthorizationSQLRepo) UseRefreshToken(ctx context.Context, id string) (bool, error) {
+	result, err := a.db.DB.ExecContext(ctx, a.db.Rebind(`UPDATE refresh_tokens SET used = ? WHERE id = ? AND used = ? AND revoked = ?`),
+		true, id, false, false)
+	if err != nil {
+		return false, a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao usar o refresh token UseRefreshToken: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+func (a *AuthorizationSQLRepo) RevokeRefreshTokenFamily(ctx context.Context, family string) error {
+	_, err := a.db.DB.ExecContext(ctx, a.db.Rebind(`UPDATE refresh_tokens SET revoked = ? WHERE family = ?`), true, family)
+	if err != nil {
+		return a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao revogar os refresh tokens RevokeRefreshTokenFamily: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+	return nil
+}
+
+func (a *AuthorizationSQLRepo) GetTokenGeneration(ctx context.Context, userID string) (int64, error) {
+	var generation int64
+	err := a.db.DB.QueryRowContext(ctx, a.db.Rebind(`SELECT generation FROM token_generations WHERE user_id = ?`), userID).Scan(&generation)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao buscar a geração dos tokens GetTokenGeneration: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+	return generation, nil
+}
+
+func (a *AuthorizationSQLRepo) IncrementTokenGeneration(ctx context.Context, userID string) (int64, error) {
+	var generation int64
+	err := a.db.DB.QueryRowContext(ctx, a.db.Rebind(`INSERT INTO token_generations (user_id, generation, update_at)
+VALUES (?, 1, ?)
+ON CONFLICT (user_id) DO UPDATE SET
+    generation = token_generations.generation + 1,
+    update_at = excluded.update_at
+RETURNING generation`), userID, time.Now().UTC()).Scan(&generation)
+	if err != nil {
+		return 0, a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao incrementar a geração dos tokens IncrementTokenGeneration: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+	return generation, nil
+}
+
+func (a *AuthorizationSQLRepo) SaveSession(ctx context.Context, session *entity.Session) error {
+	_, err := a.db.DB.ExecContext(ctx, a.db.Rebind(`INSERT INTO sessions (`+sessionColumns+`)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (id) DO UPDATE SET
+    user_agent = excluded.user_agent,
+    ip = excluded.ip,
+    access_token_id = excluded.access_token_id,
+    last_seen_at = excluded.last_seen_at`),
+		session.ID, session.UserID, session.Provider, session.UserAgent, session.IP, session.AccessTokenID, session.Generation, session.CreatedAt, session.LastSeenAt)
+	if err != nil {
+		return a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao salvar a sessão SaveSession: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+	return nil
+}
+
+func (a *AuthorizationSQLRepo) GetSession(ctx context.Context, id string) (*entity.Session, error) {
+	sessions, err := a.sessions(ctx, `WHERE id = ?`, id)
+	if err != nil {
+		return nil, a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao buscar a sessão GetSession: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+	if len(sessions) == 0 {
+		return nil, nil
+	}
+	return &sessions[0], nil
+}
+
+func (a *AuthorizationSQLRepo) ListSessions(ctx context.Context, userID string) ([]entity.Session, error) {
+	sessions, err := a.sessions(ctx, `WHERE user_id = ? ORDER BY last_seen_at DESC`, userID)
+	if err != nil {
+		return nil, a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao buscar as sessões ListSessions: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+	return sessions, nil
+}
+
+func (a *AuthorizationSQLRepo) DeleteSession(ctx context.Context, id string) error {
+	_, err := a.db.DB.ExecContext(ctx, a.db.Rebind(`DELETE FROM sessions WHERE id = ?`), id)
+	if err != nil {
+		return a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao remover a sessão DeleteSession: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+	return nil
+}
+
+func (a *AuthorizationSQLRepo) sessions(ctx context.Context, where string, args ...any) ([]entity.Session, error) {
+	rows, err := a.db.DB.QueryContext(ctx, a.db.Rebind(`SELECT `+sessionColumns+` FROM sessions `+where), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []entity.Session{}
+	for rows.Next() {
+		var session entity.Session
+		err := rows.Scan(&session.ID, &session.UserID, &session.Provider, &session.UserAgent, &session.IP,
+			&session.AccessTokenID, &session.Generation, &session.CreatedAt, &session.LastSeenAt)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
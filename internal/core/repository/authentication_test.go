@@ -0,0 +1,178 @@
+package repository_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/internal/core/repository"
+	"github.com/Tomelin/financial-management-backend/pkg/db"
+	"github.com/Tomelin/financial-management-backend/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+type AuthorizationRepoTestSuite struct {
+	suite.Suite
+	database func() db.Database
+	repo     repository.IAuthorizationRepo
+	userID   string
+	ctx      context.Context
+}
+
+func (s *AuthorizationRepoTestSuite) SetupTest() {
+	repo, err := repository.NewAuthorizationRepo(s.database(), logger.NewLoggerConfig(map[string]any{"level": "error"}))
+	s.Require().Nil(err)
+
+	s.repo = repo
+	s.userID = uuid.New().String()
+	s.ctx = context.Background()
+}
+
+func (s *AuthorizationRepoTestSuite) newRefreshToken(family string) *entity.RefreshToken {
+	_, token, err := entity.NewRefreshToken(s.userID, family, 2)
+	s.Require().Nil(err)
+	s.Require().Nil(s.repo.CreateRefreshToken(s.ctx, token))
+	return token
+}
+
+func (s *AuthorizationRepoTestSuite) TestRefreshToken_CreateAndGet() {
+	token := s.newRefreshToken("")
+
+	found, err := s.repo.GetRefreshToken(s.ctx, token.ID)
+	s.Require().Nil(err)
+	s.Require().NotNil(found)
+	s.Equal(token.UserID, found.UserID)
+	s.Equal(token.Family, found.Family)
+	s.True(token.ExpiresAt.Equal(found.ExpiresAt))
+	s.Equal(token.Generation, found.Generation)
+	s.False(found.Used)
+	s.False(found.Revoked)
+
+	found, err = s.repo.GetRefreshToken(s.ctx, uuid.New().String())
+	s.Nil(err)
+	s.Nil(found)
+}
+
+func (s *AuthorizationRepoTestSuite) TestUseRefreshToken_Once() {
+	token := s.newRefreshToken("")
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	used := 0
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ok, err := s.repo.UseRefreshToken(s.ctx, token.ID)
+			s.Nil(err)
+			if ok {
+				mu.Lock()
+				used++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	s.Equal(1, used, "a refresh token is used once")
+
+	found, err := s.repo.GetRefreshToken(s.ctx, token.ID)
+	s.Require().Nil(err)
+	s.True(found.Used)
+
+	ok, err := s.repo.UseRefreshToken(s.ctx, uuid.New().String())
+	s.Nil(err)
+	s.False(ok)
+}
+
+func (s *AuthorizationRepoTestSuite) TestRevokeRefreshTokenFamily() {
+	first := s.newRefreshToken("")
+	second := s.newRefreshToken(first.Family)
+	other := s.newRefreshToken("")
+
+	s.Nil(s.repo.RevokeRefreshTokenFamily(s.ctx, first.Family))
+
+	for _, token := range []*entity.RefreshToken{first, second} {
+		found, err := s.repo.GetRefreshToken(s.ctx, token.ID)
+		s.Require().Nil(err)
+		s.True(found.Revoked)
+
+		ok, err := s.repo.UseRefreshToken(s.ctx, token.ID)
+		s.Nil(err)
+		s.False(ok, "a revoked refresh token cannot be used")
+	}
+
+	found, err := s.repo.GetRefreshToken(s.ctx, other.ID)
+	s.Require().Nil(err)
+	s.False(found.Revoked)
+}
+
+func (s *AuthorizationRepoTestSuite) TestTokenGeneration() {
+	generation, err := s.repo.GetTokenGeneration(s.ctx, s.userID)
+	s.Nil(err)
+	s.Equal(int64(0), generation)
+
+	for want := int64(1); want <= 3; want++ {
+		generation, err = s.repo.IncrementTokenGeneration(s.ctx, s.userID)
+		s.Require().Nil(err)
+		s.Equal(want, generation)
+	}
+
+	generation, err = s.repo.GetTokenGeneration(s.ctx, s.userID)
+	s.Nil(err)
+	s.Equal(int64(3), generation)
+
+	generation, err = s.repo.GetTokenGeneration(s.ctx, uuid.New().String())
+	s.Nil(err)
+	s.Equal(int64(0), generation)
+}
+
+func (s *AuthorizationRepoTestSuite) TestSessions() {
+	found, err := s.repo.GetSession(s.ctx, uuid.New().String())
+	s.Nil(err)
+	s.Nil(found)
+
+	older, err := entity.NewSession(uuid.New().String(), s.userID, 1, entity.SessionClient{UserAgent: "curl/8.0", IP: "10.0.0.1", Provider: "local"})
+	s.Require().Nil(err)
+	older.LastSeenAt = time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	s.Require().Nil(s.repo.SaveSession(s.ctx, older))
+	newer, err := entity.NewSession(uuid.New().String(), s.userID, 1, entity.SessionClient{UserAgent: "Firefox", IP: "10.0.0.2", Provider: "google"})
+	s.Require().Nil(err)
+	s.Require().Nil(s.repo.SaveSession(s.ctx, newer))
+
+	// a refresh replaces the device of the session
+	older.Seen(entity.SessionClient{IP: "10.0.0.3"}, older.LastSeenAt)
+	older.AccessTokenID = "jti"
+	s.Require().Nil(s.repo.SaveSession(s.ctx, older))
+
+	found, err = s.repo.GetSession(s.ctx, older.ID)
+	s.Require().Nil(err)
+	s.Require().NotNil(found)
+	s.Equal("curl/8.0", found.UserAgent)
+	s.Equal("10.0.0.3", found.IP)
+	s.Equal("jti", found.AccessTokenID)
+	s.Equal("local", found.Provider)
+	s.Equal(int64(1), found.Generation)
+
+	sessions, err := s.repo.ListSessions(s.ctx, s.userID)
+	s.Require().Nil(err)
+	s.Require().Len(sessions, 2)
+	s.Equal(newer.ID, sessions[0].ID, "the last seen first")
+	s.Equal(older.ID, sessions[1].ID)
+
+	s.Require().Nil(s.repo.DeleteSession(s.ctx, older.ID))
+	s.Nil(s.repo.DeleteSession(s.ctx, older.ID))
+	sessions, err = s.repo.ListSessions(s.ctx, s.userID)
+	s.Require().Nil(err)
+	s.Len(sessions, 1)
+}
+
+func TestRunAuthorizationRepoTestSuite(t *testing.T) {
+	suite.Run(t, &AuthorizationRepoTestSuite{database: func() db.Database { return db.NewMemoryStore() }})
+}
+
+func TestRunAuthorizationSQLRepoTestSuite(t *testing.T) {
+	suite.Run(t, &AuthorizationRepoTestSuite{database: func() db.Database { return newSQLiteDatabase(t) }})
+}
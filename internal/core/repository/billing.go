@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/pkg/db"
+)
+
+const (
+	billingAccountsCollection = "billing_accounts"
+	paymentEventsCollection   = "payment_events"
+)
+
+type IBillingRepo interface {
+	// SaveBillingAccount creates or replaces the billing account of the tenant
+	SaveBillingAccount(ctx context.Context, data *entity.BillingAccount) *entity.ModuleError
+	// GetBillingAccount returns nil when the tenant has no billing account
+	GetBillingAccount(ctx context.Context, tenantId string) (*entity.BillingAccount, *entity.ModuleError)
+	// ClaimPaymentEvent records the event before it is applied, in one write that only one request wins
+	// It returns false when the event is applied, or claimed by another request, see entity.PaymentEvent.Claimed
+	ClaimPaymentEvent(ctx context.Context, data *entity.PaymentEvent) (bool, *entity.ModuleError)
+	// SavePaymentEvent records the claimed event as applied
+	SavePaymentEvent(ctx context.Context, data *entity.PaymentEvent) *entity.ModuleError
+	// ReleasePaymentEvent removes the claim of an event that was not applied, it is applied again when it is sent again
+	ReleasePaymentEvent(ctx context.Context, id string) *entity.ModuleError
+	// GetPaymentEvent returns nil when the event was not claimed
+	GetPaymentEvent(ctx context.Context, id string) (*entity.PaymentEvent, *entity.ModuleError)
+}
+
+type BillingRepo struct {
+	db db.DocumentStore
+}
+
+// NewBillingRepo creates the repository of the billing accounts of the database kind
+func NewBillingRepo(database db.Database) (IBillingRepo, error) {
+	switch conn := database.(type) {
+	case *db.SQLDatabase:
+		return NewBillingSQLRepo(conn)
+	case db.DocumentStore:
+		return &BillingRepo{db: conn}, nil
+	}
+
+	return nil, errors.New("database is required")
+}
+
+func (b *BillingRepo) SaveBillingAccount(ctx context.Context, data *entity.BillingAccount) *entity.ModuleError {
+	if err := b.db.Collection(billingAccountsCollection).Doc(data.TenantID).Set(ctx, *data); err != nil {
+		return entity.Error(err.Error(), "billing", "SaveBillingAccount", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+	return nil
+}
+
+func (b *BillingRepo) GetBillingAccount(ctx context.Context, tenantId string) (*entity.BillingAccount, *entity.ModuleError) {
+	doc, err := b.db.Collection(billingAccountsCollection).Doc(tenantId).Get(ctx)
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, entity.Error(err.Error(), "billing", "GetBillingAccount", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+
+	var account entity.BillingAccount
+	if err := doc.DataTo(&account); err != nil {
+		return nil, entity.Error(err.Error(), "billing", "GetBillingAccount", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+	return &account, nil
+}
+
+func (b *BillingRepo) ClaimPaymentEvent(ctx context.Context, data *entity.PaymentEvent) (bool, *entity.ModuleError) {
+	ref := b.db.Collection(paymentEventsCollection).Doc(data.ID)
+
+	claimed := false
+	err := b.db.RunTransaction(ctx, func(ctx context.Context, tx db.Transaction) error {
+		claimed = false
+		doc, err := tx.Get(ref)
+		if err == nil {
+			var current entity.PaymentEvent
+			if err := doc.DataTo(&current); err != nil {
+				return err
+			}
+			if current.Claimed(data.ClaimedAt) {
+				return nil
+			}
+		} else if !errors.Is(err, db.ErrNotFound) {
+			return err
+		}
+
+		claimed = true
+		return tx.Set(ref, *data)
+	})
+	if err != nil {
+		return false, entity.Error(err.Error(), "billing", "ClaimPaymentEvent", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+	return claimed, nil
+}
+
+func (b *BillingRepo) SavePaymentEvent(ctx context.Context, data *entity.PaymentEvent) *entity.ModuleError {
+	if err := b.db.Collection(paymentEventsCollection).Doc(data.ID).Set(ctx, *data); err != nil {
+		return entity.Error(err.Error(), "billing", "SavePaymentEvent", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+	return nil
+}
+
+func (b *BillingRepo) ReleasePaymentEvent(ctx context.Context, id string) *entity.ModuleError {
+	ref := b.db.Collection(paymentEventsCollection).Doc(id)
+
+	err := b.db.RunTransaction(ctx, func(ctx context.Context, tx db.Transaction) error {
+		doc, err := tx.Get(ref)
+		if errors.Is(err, db.ErrNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var current entity.PaymentEvent
+		if err := doc.DataTo(&current); err != nil {
+			return err
+		}
+		if !current.ProcessedAt.IsZero() {
+			return nil
+		}
+		return tx.Delete(ref)
+	})
+	if err != nil {
+		return entity.Error(err.Error(), "billing", "ReleasePaymentEvent", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+	return nil
+}
+
+func (b *BillingRepo) GetPaymentEvent(ctx context.Context, id string) (*entity.PaymentEvent, *entity.ModuleError) {
+	doc, err := b.db.Collection(paymentEventsCollection).Doc(id).Get(ctx)
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, entity.Error(err.Error(), "billing", "GetPaymentEvent", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+
+	var event entity.PaymentEvent
+	if err := doc.DataTo(&event); err != nil {
+		return nil, entity.Error(err.Error(), "billing", "GetPaymentEvent", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+	return &event, nil
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/pkg/db"
+)
+
+type BillingSQLRepo struct {
+	db *db.SQLDatabase
+}
+
+// NewBillingSQLRepo creates the repository of the billing accounts of a relational database
+func NewBillingSQLRepo(database *db.SQLDatabase) (IBillingRepo, error) {
+	if database == nil {
+		return nil, errors.New("database is required")
+	}
+
+	return &BillingSQLRepo{db: database}, nil
+}
+
+func (b *BillingSQLRepo) SaveBillingAccount(ctx context.Context, data *entity.BillingAccount) *entity.ModuleError {
+	_, err := b.db.DB.ExecContext(ctx, b.db.Rebind(`INSERT INTO billing_accounts (tenant_id, customer_id, subscription_id, create_at, update_at)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT (tenant_id) DO UPDATE SET
+    customer_id = excluded.customer_id,
+    subscription_id = excluded.subscription_id,
+    update_at = excluded.update_at`),
+		data.TenantID, data.CustomerID, data.SubscriptionID, data.CreatedAt, data.UpdatedAt)
+	if err != nil {
+		return entity.Error(err.Error(), "billing", "SaveBillingAccount", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+	return nil
+}
+
+func (b *BillingSQLRepo) GetBillingAccount(ctx context.Context, tenantId string) (*entity.BillingAccount, *entity.ModuleError) {
+	rows, err := b.db.DB.QueryContext(ctx, b.db.Rebind(`SELECT tenant_id, customer_id, subscription_id, create_at, update_at
+FROM billing_accounts WHERE tenant_id = ?`), tenantId)
+	if err != nil {
+		return nil, entity.Error(err.Error(), "billing", "GetBillingAccount", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, entity.Error(err.Error(), "billing", "GetBillingAccount", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+		}
+		return nil, nil
+	}
+
+	var i entity.BillingAccount
+	if err := rows.Scan(&i.TenantID, &i.CustomerID, &i.SubscriptionID, &i.CreatedAt, &i.UpdatedAt); err != nil {
+		return nil, entity.Error(err.Error(), "billing", "GetBillingAccount", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+	return &i, nil
+}
+
+// ClaimPaymentEvent inserts the event, the unique id lets only one request insert it
+// A claim that expired before the event was applied is taken by the update of the conflict
+func (b *BillingSQLRepo) ClaimPaymentEvent(ctx context.Context, data *entity.PaymentEvent) (bool, *entity.ModuleError) {
+	result, err := b.db.DB.ExecContext(ctx, b.db.Rebind(`INSERT INTO payment_events (id, type, tenant_id, claimed_at, processed_at)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT (id) DO UPDATE SET
+    claimed_at = excluded.claimed_at
+WHERE payment_events.processed_at = ? AND payment_events.claimed_at <= ?`),
+		data.ID, data.Type, data.TenantID, data.ClaimedAt, time.Time{},
+		time.Time{}, data.ClaimedAt.Add(-entity.PaymentEventClaimTTL))
+	if err != nil {
+		return false, entity.Error(err.Error(), "billing", "ClaimPaymentEvent", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, entity.Error(err.Error(), "billing", "ClaimPaymentEvent", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+	return rows > 0, nil
+}
+
+func (b *BillingSQLRepo) SavePaymentEvent(ctx context.Context, data *entity.PaymentEvent) *entity.ModuleError {
+	_, err := b.db.DB.ExecContext(ctx, b.db.Rebind(`UPDATE payment_events SET processed_at = ? WHERE id = ?`), data.ProcessedAt, data.ID)
+	if err != nil {
+		return entity.Error(err.Error(), "billing", "SavePaymentEvent", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+	return nil
+}
+
+func (b *BillingSQLRepo) ReleasePaymentEvent(ctx context.Context, id string) *entity.ModuleError {
+	_, err := b.db.DB.ExecContext(ctx, b.db.Rebind(`DELETE FROM payment_events WHERE id = ? AND processed_at = ?`), id, time.Time{})
+	if err != nil {
+		return entity.Error(err.Error(), "billing", "ReleasePaymentEvent", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+	return nil
+}
+
+func (b *BillingSQLRepo) GetPaymentEvent(ctx context.Context, id string) (*entity.PaymentEvent, *entity.ModuleError) {
+	rows, err := b.db.DB.QueryContext(ctx, b.db.Rebind(`SELECT id, type, tenant_id, claimed_at, processed_at FROM payment_events WHERE id = ?`), id)
+	if err != nil {
+		return nil, entity.Error(err.Error(), "billing", "GetPaymentEvent", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, entity.Error(err.Error(), "billing", "GetPaymentEvent", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+		}
+		return nil, nil
+	}
+
+	var i entity.PaymentEvent
+	if err := rows.Scan(&i.ID, &i.Type, &i.TenantID, &i.ClaimedAt, &i.ProcessedAt); err != nil {
+		return nil, entity.Error(err.Error(), "billing", "GetPaymentEvent", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+	return &i, nil
+}
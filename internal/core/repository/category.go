@@ -6,14 +6,13 @@ import (
 
 	"github.com/Tomelin/financial-management-backend/internal/core/entity"
 	"github.com/Tomelin/financial-management-backend/pkg/db"
-	"google.golang.org/api/iterator"
 )
 
 type CategoryRepo struct {
-	db db.FirebaseDatabaseInterface
+	db db.DocumentStore
 }
 
-func NewCategoryRepository(db db.FirebaseDatabaseInterface) (*CategoryRepo, error) {
+func NewCategoryRepository(db db.DocumentStore) (*CategoryRepo, error) {
 	if db == nil {
 		return nil, errors.New("db is required")
 	}
@@ -24,8 +23,8 @@ func (c *CategoryRepo) Create(cat *entity.CategoryResponse) (*entity.CategoryRes
 
 	ctx := context.Background()
 
-	_, err := c.db.Collection("categories").Doc(cat.ID).Set(ctx, cat)
-	// ref, _, err := c.db.Collection("categories").Add(ctx, cat)
+	err := c.db.Collection("categories").Doc(cat.ID).Set(ctx, cat)
+	// ref, err := c.db.Collection("categories").Add(ctx, cat)
 	if err != nil {
 		return nil, err
 	}
@@ -46,26 +45,19 @@ func (c *CategoryRepo) Create(cat *entity.CategoryResponse) (*entity.CategoryRes
 
 func (c *CategoryRepo) Get() ([]entity.CategoryResponse, error) {
 
-	iter := c.db.Documents(context.Background(), "categories")
-
-	defer iter.Stop()
+	docs, err := c.db.Collection("categories").Documents(context.Background())
+	if err != nil {
+		return nil, err
+	}
 
 	var documents []entity.CategoryResponse
-	for {
+	for _, doc := range docs {
 		var user entity.CategoryResponse
-		doc, err := iter.Next()
-		if err == iterator.Done {
-			break
-		}
-		if err != nil {
-			return nil, err
-		}
-
 		err = doc.DataTo(&user)
 		if err != nil {
 			return nil, err
 		}
-		user.ID = doc.Ref.ID
+		user.ID = doc.ID
 
 		documents = append(documents, user)
 	}
@@ -91,26 +83,19 @@ func (c *CategoryRepo) GetById(id *string) (*entity.CategoryResponse, error) {
 }
 
 func (c *CategoryRepo) GetByFilterMany(key string, value *string) ([]entity.CategoryResponse, error) {
-	iter := c.db.Documents(context.Background(), "categories")
-
-	defer iter.Stop()
+	docs, err := c.db.Collection("categories").Documents(context.Background())
+	if err != nil {
+		return nil, err
+	}
 
 	var documents []entity.CategoryResponse
-	for {
+	for _, doc := range docs {
 		var user entity.CategoryResponse
-		doc, err := iter.Next()
-		if err == iterator.Done {
-			break
-		}
-		if err != nil {
-			return nil, err
-		}
-
 		err = doc.DataTo(&user)
 		if err != nil {
 			return nil, err
 		}
-		user.ID = doc.Ref.ID
+		user.ID = doc.ID
 
 		documents = append(documents, user)
 	}
@@ -119,27 +104,20 @@ func (c *CategoryRepo) GetByFilterMany(key string, value *string) ([]entity.Cate
 }
 
 func (c *CategoryRepo) GetByFilterOne(key string, value *string) (*entity.CategoryResponse, error) {
-	iter := c.db.Documents(context.Background(), "categories")
-
-	defer iter.Stop()
+	docs, err := c.db.Collection("categories").Documents(context.Background())
+	if err != nil {
+		return nil, err
+	}
 
 	var categories entity.CategoryResponse
-	for {
-		doc, err := iter.Next()
-		if err == iterator.Done {
-			break
-		}
-		if err != nil {
-			return nil, err
-		}
-
+	for _, doc := range docs {
 		err = doc.DataTo(&categories)
 		if err != nil {
 			return nil, err
 		}
 
 		if categories.Name == *value {
-			categories.ID = doc.Ref.ID
+			categories.ID = doc.ID
 			return &categories, nil
 		}
 	}
@@ -148,7 +126,7 @@ func (c *CategoryRepo) GetByFilterOne(key string, value *string) (*entity.Catego
 }
 
 func (c *CategoryRepo) Update(data *entity.CategoryResponse) (*entity.CategoryResponse, error) {
-	_, err := c.db.Collection("categories").Doc(data.ID).Set(context.Background(), data)
+	err := c.db.Collection("categories").Doc(data.ID).Set(context.Background(), data)
 	if err != nil {
 		return nil, err
 	}
@@ -169,7 +147,7 @@ func (c *CategoryRepo) Update(data *entity.CategoryResponse) (*entity.CategoryRe
 }
 
 func (c *CategoryRepo) Delete(id *string) error {
-	_, err := c.db.Collection("categories").Doc(*id).Delete(context.Background())
+	err := c.db.Collection("categories").Doc(*id).Delete(context.Background())
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/pkg/db"
+	"github.com/Tomelin/financial-management-backend/pkg/logger"
+)
+
+// identitiesCollection is the sub-collection of the identities of a user, and the collection
+// of the identities of all the users keyed by entity.IdentityID, used to find the user of the login
+const identitiesCollection = "identities"
+
+type IIdentityRepo interface {
+	// CreateIdentity links the identity to its user, linking it again to the same user does nothing
+	// It returns entity.ErrIdentityLinked when the provider account is linked to another user
+	// and entity.ErrProviderLinked when the user has linked another account of the provider
+	CreateIdentity(ctx context.Context, identity *entity.Identity) error
+	// GetIdentity returns nil when the provider account is not linked
+	GetIdentity(ctx context.Context, provider, providerUserID string) (*entity.Identity, error)
+	ListIdentities(ctx context.Context, userID string) ([]entity.Identity, error)
+	// DeleteIdentity returns entity.ErrIdentityNotFound when the provider is not linked to the user
+	DeleteIdentity(ctx context.Context, userID, provider string) error
+}
+
+type IdentityRepo struct {
+	db  db.DocumentStore
+	log logger.Logger
+}
+
+// NewIdentityRepo creates the repository of the identities of the database kind
+func NewIdentityRepo(database db.Database, l logger.Logger) (IIdentityRepo, error) {
+
+	switch conn := database.(type) {
+	case *db.SQLDatabase:
+		return NewIdentitySQLRepo(conn, l)
+	case db.DocumentStore:
+		return &IdentityRepo{
+			db:  conn,
+			log: l,
+		}, nil
+	}
+
+	return nil, errors.New("db é obrigatório")
+}
+
+func (a *IdentityRepo) userIdentities(userID string) db.CollectionRef {
+	return a.db.Collection("users").Doc(userID).Collection(identitiesCollection)
+}
+
+func (a *IdentityRepo) CreateIdentity(ctx context.Context, identity *entity.Identity) error {
+	index := a.db.Collection(identitiesCollection).Doc(entity.IdentityID(identity.Provider, identity.ProviderUserID))
+	ref := a.userIdentities(identity.UserID).Doc(identity.Provider)
+
+	err := a.db.RunTransaction(ctx, func(ctx context.Context, tx db.Transaction) error {
+		doc, err := tx.Get(index)
+		if err != nil && !errors.Is(err, db.ErrNotFound) {
+			return err
+		}
+		if err == nil {
+			var linked entity.Identity
+			if err := doc.DataTo(&linked); err != nil {
+				return err
+			}
+			if linked.UserID != identity.UserID {
+				return entity.ErrIdentityLinked
+			}
+			return nil
+		}
+
+		_, err = tx.Get(ref)
+		if err == nil {
+			return entity.ErrProviderLinked
+		}
+		if !errors.Is(err, db.ErrNotFound) {
+			return err
+		}
+
+		if err := tx.Set(index, *identity); err != nil {
+			return err
+		}
+		return tx.Set(ref, *identity)
+	})
+	if errors.Is(err, entity.ErrIdentityLinked) || errors.Is(err, entity.ErrProviderLinked) {
+		return err
+	}
+	if err != nil {
+		return a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao salvar a identidade CreateIdentity: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+	return nil
+}
+
+func (a *IdentityRepo) GetIdentity(ctx context.Context, provider, providerUserID string) (*entity.Identity, error) {
+	doc, err := a.db.Collection(identitiesCollection).Doc(entity.IdentityID(provider, providerUserID)).Get(ctx)
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao buscar a identidade GetIdentity: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+
+	var identity entity.Identity
+	if err := doc.DataTo(&identity); err != nil {
+		return nil, a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao buscar a identidade GetIdentity: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+	return &identity, nil
+}
+
+func (a *IdentityRepo) ListIdentities(ctx context.Context, userID string) ([]entity.Identity, error) {
+	docs, err := a.userIdentities(userID).OrderBy("linked_at", db.Asc).Documents(ctx)
+	if err != nil {
+		return nil, a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao buscar as identidades ListIdentities: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+
+	identities := make([]entity.Identity, 0, len(docs))
+	for _, doc := range docs {
+		var identity entity.Identity
+		if err := doc.DataTo(&identity); err != nil {
+			return nil, a.log.Error(&logger.Message{
+				Body: fmt.Sprintf("erro ao buscar as identidades ListIdentities: %s", err.Error()),
+				Code: logger.ResponseCodeInternalServer})
+		}
+		identities = append(identities, identity)
+	}
+	return identities, nil
+}
+
+func (a *IdentityRepo) DeleteIdentity(ctx context.Context, userID, provider string) error {
+	ref := a.userIdentities(userID).Doc(provider)
+
+	err := a.db.RunTransaction(ctx, func(ctx context.Context, tx db.Transaction) error {
+		doc, err := tx.Get(ref)
+		if errors.Is(err, db.ErrNotFound) {
+			return entity.ErrIdentityNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		var identity entity.Identity
+		if err := doc.DataTo(&identity); err != nil {
+			return err
+		}
+
+		if err := tx.Delete(a.db.Collection(identitiesCollection).Doc(entity.IdentityID(identity.Provider, identity.ProviderUserID))); err != nil {
+			return err
+		}
+		return tx.Delete(ref)
+	})
+	if errors.Is(err, entity.ErrIdentityNotFound) {
+		return err
+	}
+	if err != nil {
+		return a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao remover a identidade DeleteIdentity: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+	return nil
+}
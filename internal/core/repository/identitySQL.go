@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/pkg/db"
+	"github.com/Tomelin/financial-management-backend/pkg/logger"
+)
+
+const identityColumns = `user_id, provider, provider_user_id, email, linked_at`
+
+type IdentitySQLRepo struct {
+	db  *db.SQLDatabase
+	log logger.Logger
+}
+
+// NewIdentitySQLRepo creates the repository of the identities of a relational database
+func NewIdentitySQLRepo(database *db.SQLDatabase, l logger.Logger) (IIdentityRepo, error) {
+
+	if database == nil {
+		return nil, errors.New("db é obrigatório")
+	}
+
+	return &IdentitySQLRepo{
+		db:  database,
+		log: l,
+	}, nil
+}
+
+func (a *IdentitySQLRepo) CreateIdentity(ctx context.Context, identity *entity.Identity) error {
+	id := entity.IdentityID(identity.Provider, identity.ProviderUserID)
+
+	result, err := a.db.DB.ExecContext(ctx, a.db.Rebind(`INSERT INTO identities (id, `+identityColumns+`)
+VALUES (?, ?, ?, ?, ?, ?)
+ON CONFLICT DO NOTHING`),
+		id, identity.UserID, identity.Provider, identity.ProviderUserID, identity.Email, identity.LinkedAt)
+	if err != nil {
+		return a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao salvar a identidade CreateIdentity: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+	if n, _ := result.RowsAffected(); n == 1 {
+		return nil
+	}
+
+	// one of the unique keys exists, the provider account or the provider of the user
+	var userID string
+	err = a.db.DB.QueryRowContext(ctx, a.db.Rebind(`SELECT user_id FROM identities WHERE id = ?`), id).Scan(&userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.ErrProviderLinked
+	}
+	if err != nil {
+		return a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao salvar a identidade CreateIdentity: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+	if userID != identity.UserID {
+		return entity.ErrIdentityLinked
+	}
+	return nil
+}
+
+func (a *IdentitySQLRepo) GetIdentity(ctx context.Context, provider, providerUserID string) (*entity.Identity, error) {
+	var identity entity.Identity
+	err := a.db.DB.QueryRowContext(ctx, a.db.Rebind(`SELECT `+identityColumns+` FROM identities WHERE id = ?`), entity.IdentityID(provider, providerUserID)).
+		Scan(&identity.UserID, &identity.Provider, &identity.ProviderUserID, &identity.Email, &identity.LinkedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao buscar a identidade GetIdentity: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+	return &identity, nil
+}
+
+func (a *IdentitySQLRepo) ListIdentities(ctx context.Context, userID string) ([]entity.Identity, error) {
+	rows, err := a.db.DB.QueryContext(ctx, a.db.Rebind(`SELECT `+identityColumns+` FROM identities WHERE user_id = ? ORDER BY linked_at`), userID)
+	if err != nil {
+		return nil, a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao buscar as identidades ListIdentities: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+	defer rows.Close()
+
+	identities := []entity.Identity{}
+	for rows.Next() {
+		var identity entity.Identity
+		if err := rows.Scan(&identity.UserID, &identity.Provider, &identity.ProviderUserID, &identity.Email, &identity.LinkedAt); err != nil {
+			return nil, a.log.Error(&logger.Message{
+				Body: fmt.Sprintf("erro ao buscar as identidades ListIdentities: %s", err.Error()),
+				Code: logger.ResponseCodeInternalServer})
+		}
+		identities = append(identities, identity)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao buscar as identidades ListIdentities: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+	return identities, nil
+}
+
+func (a *IdentitySQLRepo) DeleteIdentity(ctx context.Context, userID, provider string) error {
+	result, err := a.db.DB.ExecContext(ctx, a.db.Rebind(`DELETE FROM identities WHERE user_id = ? AND provider = ?`), userID, provider)
+	if err != nil {
+		return a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao remover a identidade DeleteIdentity: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return entity.ErrIdentityNotFound
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/internal/core/repository"
+	"github.com/Tomelin/financial-management-backend/pkg/db"
+	"github.com/Tomelin/financial-management-backend/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+type IdentityRepoTestSuite struct {
+	suite.Suite
+	database func() db.Database
+	repo     repository.IIdentityRepo
+	userID   string
+	ctx      context.Context
+}
+
+func (s *IdentityRepoTestSuite) SetupTest() {
+	repo, err := repository.NewIdentityRepo(s.database(), logger.NewLoggerConfig(map[string]any{"level": "error"}))
+	s.Require().Nil(err)
+
+	s.repo = repo
+	s.userID = uuid.New().String()
+	s.ctx = context.Background()
+}
+
+func (s *IdentityRepoTestSuite) identity(userID, provider, providerUserID string) *entity.Identity {
+	return &entity.Identity{
+		UserID:         userID,
+		Provider:       provider,
+		ProviderUserID: providerUserID,
+		Email:          "user@domain.com",
+		LinkedAt:       time.Now().UTC().Truncate(time.Second),
+	}
+}
+
+func (s *IdentityRepoTestSuite) TestCreateAndGet() {
+	found, err := s.repo.GetIdentity(s.ctx, "google", "1")
+	s.Nil(err)
+	s.Nil(found)
+
+	s.Require().Nil(s.repo.CreateIdentity(s.ctx, s.identity(s.userID, "google", "1")))
+	s.Nil(s.repo.CreateIdentity(s.ctx, s.identity(s.userID, "google", "1")), "linking again to the same user does nothing")
+
+	found, err = s.repo.GetIdentity(s.ctx, "google", "1")
+	s.Require().Nil(err)
+	s.Require().NotNil(found)
+	s.Equal(s.userID, found.UserID)
+	s.Equal("user@domain.com", found.Email)
+
+	// the same subject of another provider is another account
+	found, err = s.repo.GetIdentity(s.ctx, "github", "1")
+	s.Nil(err)
+	s.Nil(found)
+}
+
+func (s *IdentityRepoTestSuite) TestCreate_Conflicts() {
+	s.Require().Nil(s.repo.CreateIdentity(s.ctx, s.identity(s.userID, "google", "1")))
+
+	err := s.repo.CreateIdentity(s.ctx, s.identity(uuid.New().String(), "google", "1"))
+	s.True(errors.Is(err, entity.ErrIdentityLinked))
+
+	err = s.repo.CreateIdentity(s.ctx, s.identity(s.userID, "google", "2"))
+	s.True(errors.Is(err, entity.ErrProviderLinked))
+
+	found, err := s.repo.GetIdentity(s.ctx, "google", "2")
+	s.Nil(err)
+	s.Nil(found)
+}
+
+func (s *IdentityRepoTestSuite) TestListAndDelete() {
+	identities, err := s.repo.ListIdentities(s.ctx, s.userID)
+	s.Require().Nil(err)
+	s.Empty(identities)
+
+	google := s.identity(s.userID, "google", "1")
+	github := s.identity(s.userID, "github", "1")
+	github.LinkedAt = google.LinkedAt.Add(time.Minute)
+	s.Require().Nil(s.repo.CreateIdentity(s.ctx, github))
+	s.Require().Nil(s.repo.CreateIdentity(s.ctx, google))
+	s.Require().Nil(s.repo.CreateIdentity(s.ctx, s.identity(uuid.New().String(), "google", "2")))
+
+	identities, err = s.repo.ListIdentities(s.ctx, s.userID)
+	s.Require().Nil(err)
+	s.Require().Len(identities, 2)
+	s.Equal("google", identities[0].Provider)
+	s.Equal("github", identities[1].Provider)
+
+	s.Require().Nil(s.repo.DeleteIdentity(s.ctx, s.userID, "google"))
+	s.True(errors.Is(s.repo.DeleteIdentity(s.ctx, s.userID, "google"), entity.ErrIdentityNotFound))
+
+	found, err := s.repo.GetIdentity(s.ctx, "google", "1")
+	s.Nil(err)
+	s.Nil(found, "the provider account can be linked again")
+	s.Nil(s.repo.CreateIdentity(s.ctx, s.identity(uuid.New().String(), "google", "1")))
+
+	identities, err = s.repo.ListIdentities(s.ctx, s.userID)
+	s.Require().Nil(err)
+	s.Len(identities, 1)
+}
+
+func TestRunIdentityRepoTestSuite(t *testing.T) {
+	suite.Run(t, &IdentityRepoTestSuite{database: func() db.Database { return db.NewMemoryStore() }})
+}
+
+func TestRunIdentitySQLRepoTestSuite(t *testing.T) {
+	suite.Run(t, &IdentityRepoTestSuite{database: func() db.Database { return newSQLiteDatabase(t) }})
+}
@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/pkg/db"
+	"github.com/Tomelin/financial-management-backend/pkg/logger"
+)
+
+const (
+	credentialsCollection  = "credentials"
+	actionTokensCollection = "action_tokens"
+)
+
+type ILocalAuthRepo interface {
+	CreateCredential(ctx context.Context, credential *entity.Credential) error
+	// GetCredential returns nil when the user has no credential
+	GetCredential(ctx context.Context, userID string) (*entity.Credential, error)
+	UpdateCredential(ctx context.Context, credential *entity.Credential) error
+
+	CreateActionToken(ctx context.Context, token *entity.ActionToken) error
+	// GetActionToken returns nil when the action token does not exist
+	GetActionToken(ctx context.Context, id string) (*entity.ActionToken, error)
+	// UseActionToken marks the action token as used, it reports false when it was already used
+	UseActionToken(ctx context.Context, id string) (bool, error)
+}
+
+type LocalAuthRepo struct {
+	db  db.DocumentStore
+	log logger.Logger
+}
+
+// NewLocalAuthRepo creates the repository of the credentials of the database kind
+func NewLocalAuthRepo(database db.Database, l logger.Logger) (ILocalAuthRepo, error) {
+
+	switch conn := database.(type) {
+	case *db.SQLDatabase:
+		return NewLocalAuthSQLRepo(conn, l)
+	case db.DocumentStore:
+		return &LocalAuthRepo{
+			db:  conn,
+			log: l,
+		}, nil
+	}
+
+	return nil, errors.New("db é obrigatório")
+}
+
+func (a *LocalAuthRepo) CreateCredential(ctx context.Context, credential *entity.Credential) error {
+	err := a.db.Collection(credentialsCollection).Doc(credential.UserID).Set(ctx, *credential)
+	if err != nil {
+		return a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao salvar a credencial CreateCredential: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+	return nil
+}
+
+func (a *LocalAuthRepo) GetCredential(ctx context.Context, userID string) (*entity.Credential, error) {
+	doc, err := a.db.Collection(credentialsCollection).Doc(userID).Get(ctx)
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao buscar a credencial GetCredential: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+
+	var credential entity.Credential
+	if err := doc.DataTo(&credential); err != nil {
+		return nil, a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao buscar a credencial GetCredential: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+	return &credential, nil
+}
+
+func (a *LocalAuthRepo) UpdateCredential(ctx context.Context, credential *entity.Credential) error {
+	err := a.db.Collection(credentialsCollection).Doc(credential.UserID).Update(ctx,
+		db.Update{Path: "password_hash", Value: credential.PasswordHash},
+		db.Update{Path: "email_verified", Value: credential.EmailVerified},
+		db.Update{Path: "update_at", Value: credential.UpdatedAt},
+	)
+	if errors.Is(err, db.ErrNotFound) {
+		return a.log.Error(&logger.Message{
+			Body: "credencial não encontrada",
+			Code: logger.ResponseCodeNotFound})
+	}
+	if err != nil {
+		return a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao atualizar a credencial UpdateCredential: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+	return nil
+}
+
+func (a *LocalAuthRepo) CreateActionToken(ctx context.Context, token *entity.ActionToken) error {
+	err := a.db.Collection(actionTokensCollection).Doc(token.ID).Set(ctx, *token)
+	if err != nil {
+		return a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao salvar o token CreateActionToken: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+	return nil
+}
+
+func (a *LocalAuthRepo) GetActionToken(ctx context.Context, id string) (*entity.ActionToken, error) {
+	doc, err := a.db.Collection(actionTokensCollection).Doc(id).Get(ctx)
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao buscar o token GetActionToken: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+
+	var token entity.ActionToken
+	if err := doc.DataTo(&token); err != nil {
+		return nil, a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao buscar o token GetActionToken: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+	return &token, nil
+}
+
+func (a *LocalAuthRepo) UseActionToken(ctx context.Context, id string) (bool, error) {
+	ref := a.db.Collection(actionTokensCollection).Doc(id)
+
+	var used bool
+	err := a.db.RunTransaction(ctx, func(ctx context.Context, tx db.Transaction) error {
+		used = false
+
+		doc, err := tx.Get(ref)
+		if err != nil {
+			return err
+		}
+
+		var token entity.ActionToken
+		if err := doc.DataTo(&token); err != nil {
+			return err
+		}
+		if token.Used {
+			return nil
+		}
+
+		used = true
+		return tx.Update(ref, db.Update{Path: "used", Value: true})
+	})
+	if errors.Is(err, db.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao usar o token UseActionToken: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+
+	return used, nil
+}
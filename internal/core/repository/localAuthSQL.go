@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/pkg/db"
+	"github.com/Tomelin/financial-management-backend/pkg/logger"
+)
+
+const actionTokenColumns = `id, user_id, purpose, expires_at, used, create_at`
+
+type LocalAuthSQLRepo struct {
+	db  *db.SQLDatabase
+	log logger.Logger
+}
+
+// NewLocalAuthSQLRepo creates the repository of the credentials of a relational database
+func NewLocalAuthSQLRepo(database *db.SQLDatabase, l logger.Logger) (ILocalAuthRepo, error) {
+
+	if database == nil {
+		return nil, errors.New("db é obrigatório")
+	}
+
+	return &LocalAuthSQLRepo{
+		db:  database,
+		log: l,
+	}, nil
+}
+
+func (a *LocalAuthSQLRepo) CreateCredential(ctx context.Context, credential *entity.Credential) error {
+	_, err := a.db.DB.ExecContext(ctx, a.db.Rebind(`INSERT INTO credentials (user_id, password_hash, email_verified, create_at, update_at)
+VALUES (?, ?, ?, ?, ?)`),
+		credential.UserID, credential.PasswordHash, credential.EmailVerified, credential.CreatedAt, credential.UpdatedAt)
+	if err != nil {
+		return a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao salvar a credencial CreateCredential: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+	return nil
+}
+
+func (a *LocalAuthSQLRepo) GetCredential(ctx context.Context, userID string) (*entity.Credential, error) {
+	var credential entity.Credential
+	err := a.db.DB.QueryRowContext(ctx, a.db.Rebind(`SELECT user_id, password_hash, email_verified, create_at, update_at FROM credentials WHERE user_id = ?`), userID).
+		Scan(&credential.UserID, &credential.PasswordHash, &credential.EmailVerified, &credential.CreatedAt, &credential.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao buscar a credencial GetCredential: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+	return &credential, nil
+}
+
+func (a *LocalAuthSQLRepo) UpdateCredential(ctx context.Context, credential *entity.Credential) error {
+	result, err := a.db.DB.ExecContext(ctx, a.db.Rebind(`UPDATE credentials SET password_hash = ?, email_verified = ?, update_at = ? WHERE user_id = ?`),
+		credential.PasswordHash, credential.EmailVerified, credential.UpdatedAt, credential.UserID)
+	if err != nil {
+		return a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao atualizar a credencial UpdateCredential: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return a.log.Error(&logger.Message{
+			Body: "credencial não encontrada",
+			Code: logger.ResponseCodeNotFound})
+	}
+	return nil
+}
+
+func (a *LocalAuthSQLRepo) CreateActionToken(ctx context.Context, token *entity.ActionToken) error {
+	_, err := a.db.DB.ExecContext(ctx, a.db.Rebind(`INSERT INTO action_tokens (`+actionTokenColumns+`)
+VALUES (?, ?, ?, ?, ?, ?)`),
+		token.ID, token.UserID, string(token.Purpose), token.ExpiresAt, token.Used, token.CreatedAt)
+	if err != nil {
+		return a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao salvar o token CreateActionToken: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+	return nil
+}
+
+func (a *LocalAuthSQLRepo) GetActionToken(ctx context.Context, id string) (*entity.ActionToken, error) {
+	var token entity.ActionToken
+	var purpose string
+	err := a.db.DB.QueryRowContext(ctx, a.db.Rebind(`SELECT `+actionTokenColumns+` FROM action_tokens WHERE id = ?`), id).
+		Scan(&token.ID, &token.UserID, &purpose, &token.ExpiresAt, &token.Used, &token.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao buscar o token GetActionToken: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+
+	token.Purpose = entity.ActionTokenPurpose(purpose)
+	return &token, nil
+}
+
+func (a *LocalAuthSQLRepo) UseActionToken(ctx context.Context, id string) (bool, error) {
+	result, err := a.db.DB.ExecContext(ctx, a.db.Rebind(`UPDATE action_tokens SET used = ? WHERE id = ? AND used = ?`), true, id, false)
+	if err != nil {
+		return false, a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao usar o token UseActionToken: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
@@ -0,0 +1,96 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/internal/core/repository"
+	"github.com/Tomelin/financial-management-backend/pkg/db"
+	"github.com/Tomelin/financial-management-backend/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+type LocalAuthRepoTestSuite struct {
+	suite.Suite
+	database func() db.Database
+	repo     repository.ILocalAuthRepo
+	userID   string
+	ctx      context.Context
+}
+
+func (s *LocalAuthRepoTestSuite) SetupTest() {
+	repo, err := repository.NewLocalAuthRepo(s.database(), logger.NewLoggerConfig(map[string]any{"level": "error"}))
+	s.Require().Nil(err)
+
+	s.repo = repo
+	s.userID = uuid.New().String()
+	s.ctx = context.Background()
+}
+
+func (s *LocalAuthRepoTestSuite) TestCredential() {
+	found, err := s.repo.GetCredential(s.ctx, s.userID)
+	s.Nil(err)
+	s.Nil(found)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	s.Require().Nil(s.repo.CreateCredential(s.ctx, &entity.Credential{UserID: s.userID, PasswordHash: "hash", CreatedAt: now, UpdatedAt: now}))
+
+	found, err = s.repo.GetCredential(s.ctx, s.userID)
+	s.Require().Nil(err)
+	s.Require().NotNil(found)
+	s.Equal("hash", found.PasswordHash)
+	s.False(found.EmailVerified)
+
+	found.PasswordHash = "other"
+	found.EmailVerified = true
+	found.UpdatedAt = now.Add(time.Minute)
+	s.Require().Nil(s.repo.UpdateCredential(s.ctx, found))
+
+	found, err = s.repo.GetCredential(s.ctx, s.userID)
+	s.Require().Nil(err)
+	s.Equal("other", found.PasswordHash)
+	s.True(found.EmailVerified)
+	s.True(now.Add(time.Minute).Equal(found.UpdatedAt))
+
+	s.NotNil(s.repo.UpdateCredential(s.ctx, &entity.Credential{UserID: uuid.New().String()}))
+}
+
+func (s *LocalAuthRepoTestSuite) TestActionToken() {
+	_, token, err := entity.NewActionToken(s.userID, entity.ActionTokenResetPassword, time.Hour)
+	s.Require().Nil(err)
+	s.Require().Nil(s.repo.CreateActionToken(s.ctx, token))
+
+	found, err := s.repo.GetActionToken(s.ctx, token.ID)
+	s.Require().Nil(err)
+	s.Require().NotNil(found)
+	s.Equal(entity.ActionTokenResetPassword, found.Purpose)
+	s.Equal(s.userID, found.UserID)
+	s.True(found.IsValid(entity.ActionTokenResetPassword, time.Now()))
+
+	used, err := s.repo.UseActionToken(s.ctx, token.ID)
+	s.Nil(err)
+	s.True(used)
+
+	used, err = s.repo.UseActionToken(s.ctx, token.ID)
+	s.Nil(err)
+	s.False(used, "an action token is used once")
+
+	found, err = s.repo.GetActionToken(s.ctx, token.ID)
+	s.Require().Nil(err)
+	s.True(found.Used)
+
+	found, err = s.repo.GetActionToken(s.ctx, uuid.New().String())
+	s.Nil(err)
+	s.Nil(found)
+}
+
+func TestRunLocalAuthRepoTestSuite(t *testing.T) {
+	suite.Run(t, &LocalAuthRepoTestSuite{database: func() db.Database { return db.NewMemoryStore() }})
+}
+
+func TestRunLocalAuthSQLRepoTestSuite(t *testing.T) {
+	suite.Run(t, &LocalAuthRepoTestSuite{database: func() db.Database { return newSQLiteDatabase(t) }})
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/pkg/db"
+	"github.com/Tomelin/financial-management-backend/pkg/logger"
+)
+
+// personalAccessTokensCollection keys the personal access tokens by their hash, the middleware finds them by it
+const personalAccessTokensCollection = "personal_access_tokens"
+
+type IPersonalAccessTokenRepo interface {
+	CreateAccessToken(ctx context.Context, token *entity.PersonalAccessToken) error
+	// GetAccessTokenByHash returns nil when the token does not exist
+	GetAccessTokenByHash(ctx context.Context, tokenHash string) (*entity.PersonalAccessToken, error)
+	// ListAccessTokens returns the tokens of the user, the newest first
+	ListAccessTokens(ctx context.Context, userID string) ([]entity.PersonalAccessToken, error)
+	// DeleteAccessToken returns entity.ErrPersonalAccessTokenNotFound when the token is not of the user
+	DeleteAccessToken(ctx context.Context, userID, id string) error
+	// TouchAccessToken records the last use of the token
+	TouchAccessToken(ctx context.Context, tokenHash string, usedAt time.Time) error
+}
+
+type PersonalAccessTokenRepo struct {
+	db  db.DocumentStore
+	log logger.Logger
+}
+
+// NewPersonalAccessTokenRepo creates the repository of the personal access tokens of the database kind
+func NewPersonalAccessTokenRepo(database db.Database, l logger.Logger) (IPersonalAccessTokenRepo, error) {
+
+	switch conn := database.(type) {
+	case *db.SQLDatabase:
+		return NewPersonalAccessTokenSQLRepo(conn, l)
+	case db.DocumentStore:
+		return &PersonalAccessTokenRepo{
+			db:  conn,
+			log: l,
+		}, nil
+	}
+
+	return nil, errors.New("db é obrigatório")
+}
+
+func (a *PersonalAccessTokenRepo) CreateAccessToken(ctx context.Context, token *entity.PersonalAccessToken) error {
+	err := a.db.Collection(personalAccessTokensCollection).Doc(token.TokenHash).Set(ctx, *token)
+	if err != nil {
+		return a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao salvar o token CreateAccessToken: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+	return nil
+}
+
+func (a *PersonalAccessTokenRepo) GetAccessTokenByHash(ctx context.Context, tokenHash string) (*entity.PersonalAccessToken, error) {
+	doc, err := a.db.Collection(personalAccessTokensCollection).Doc(tokenHash).Get(ctx)
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao buscar o token GetAccessTokenByHash: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+
+	var token entity.PersonalAccessToken
+	if err := doc.DataTo(&token); err != nil {
+		return nil, a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao buscar o token GetAccessTokenByHash: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+	return &token, nil
+}
+
+func (a *PersonalAccessTokenRepo) ListAccessTokens(ctx context.Context, userID string) ([]entity.PersonalAccessToken, error) {
+	docs, err := a.db.Collection(personalAccessTokensCollection).Where("user_id", db.OpEqual, userID).OrderBy("create_at", db.Desc).Documents(ctx)
+	if err != nil {
+		return nil, a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao buscar os tokens ListAccessTokens: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+
+	tokens := make([]entity.PersonalAccessToken, 0, len(docs))
+	for _, doc := range docs {
+		var token entity.PersonalAccessToken
+		if err := doc.DataTo(&token); err != nil {
+			return nil, a.log.Error(&logger.Message{
+				Body: fmt.Sprintf("erro ao buscar os tokens ListAccessTokens: %s", err.Error()),
+				Code: logger.ResponseCodeInternalServer})
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, nil
+}
+
+func (a *PersonalAccessTokenRepo) DeleteAccessToken(ctx context.Context, userID, id string) error {
+	docs, err := a.db.Collection(personalAccessTokensCollection).Where("id", db.OpEqual, id).Where("user_id", db.OpEqual, userID).Limit(1).Documents(ctx)
+	if err != nil {
+		return a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao remover o token DeleteAccessToken: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+	if len(docs) == 0 {
+		return entity.ErrPersonalAccessTokenNotFound
+	}
+
+	if err := a.db.Collection(personalAccessTokensCollection).Doc(docs[0].ID).Delete(ctx); err != nil {
+		return a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao remover o token DeleteAccessToken: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+	return nil
+}
+
+func (a *PersonalAccessTokenRepo) TouchAccessToken(ctx context.Context, tokenHash string, usedAt time.Time) error {
+	err := a.db.Collection(personalAccessTokensCollection).Doc(tokenHash).Update(ctx, db.Update{Path: "last_used_at", Value: usedAt})
+	if err != nil && !errors.Is(err, db.ErrNotFound) {
+		return a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao atualizar o token TouchAccessToken: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/pkg/db"
+	"github.com/Tomelin/financial-management-backend/pkg/logger"
+)
+
+const personalAccessTokenColumns = `id, token_hash, user_id, name, scopes, expires_at, last_used_at, create_at`
+
+type PersonalAccessTokenSQLRepo struct {
+	db  *db.SQLDatabase
+	log logger.Logger
+}
+
+// NewPersonalAccessTokenSQLRepo creates the repository of the personal access tokens of a relational database
+func NewPersonalAccessTokenSQLRepo(database *db.SQLDatabase, l logger.Logger) (IPersonalAccessTokenRepo, error) {
+
+	if database == nil {
+		return nil, errors.New("db é obrigatório")
+	}
+
+	return &PersonalAccessTokenSQLRepo{
+		db:  database,
+		log: l,
+	}, nil
+}
+
+func (a *PersonalAccessTokenSQLRepo) CreateAccessToken(ctx context.Context, token *entity.PersonalAccessToken) error {
+	scopes, err := jsonValue(token.Scopes)
+	if err != nil {
+		return err
+	}
+
+	_, err = a.db.DB.ExecContext(ctx, a.db.Rebind(`INSERT INTO personal_access_tokens (`+personalAccessTokenColumns+`)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)`),
+		token.ID, token.TokenHash, token.UserID, token.Name, scopes, token.ExpiresAt, token.LastUsedAt, token.CreatedAt)
+	if err != nil {
+		return a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao salvar o token CreateAccessToken: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+	return nil
+}
+
+func (a *PersonalAccessTokenSQLRepo) GetAccessTokenByHash(ctx context.Context, tokenHash string) (*entity.PersonalAccessToken, error) {
+	tokens, err := a.query(ctx, `WHERE token_hash = ?`, tokenHash)
+	if err != nil {
+		return nil, a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao buscar o token GetAccessTokenByHash: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+	return &tokens[0], nil
+}
+
+func (a *PersonalAccessTokenSQLRepo) ListAccessTokens(ctx context.Context, userID string) ([]entity.PersonalAccessToken, error) {
+	tokens, err := a.query(ctx, `WHERE user_id = ? ORDER BY create_at DESC`, userID)
+	if err != nil {
+		return nil, a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao buscar os tokens ListAccessTokens: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+	return tokens, nil
+}
+
+func (a *PersonalAccessTokenSQLRepo) DeleteAccessToken(ctx context.Context, userID, id string) error {
+	result, err := a.db.DB.ExecContext(ctx, a.db.Rebind(`DELETE FROM personal_access_tokens WHERE id = ? AND user_id = ?`), id, userID)
+	if err != nil {
+		return a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao remover o token DeleteAccessToken: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return entity.ErrPersonalAccessTokenNotFound
+	}
+	return nil
+}
+
+func (a *PersonalAccessTokenSQLRepo) TouchAccessToken(ctx context.Context, tokenHash string, usedAt time.Time) error {
+	_, err := a.db.DB.ExecContext(ctx, a.db.Rebind(`UPDATE personal_access_tokens SET last_used_at = ? WHERE token_hash = ?`), usedAt, tokenHash)
+	if err != nil {
+		return a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao atualizar o token TouchAccessToken: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+	return nil
+}
+
+func (a *PersonalAccessTokenSQLRepo) query(ctx context.Context, where string, args ...any) ([]entity.PersonalAccessToken, error) {
+	rows, err := a.db.DB.QueryContext(ctx, a.db.Rebind(`SELECT `+personalAccessTokenColumns+` FROM personal_access_tokens `+where), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := []entity.PersonalAccessToken{}
+	for rows.Next() {
+		var token entity.PersonalAccessToken
+		err := rows.Scan(&token.ID, &token.TokenHash, &token.UserID, &token.Name, sqlJSON{&token.Scopes},
+			&token.ExpiresAt, &token.LastUsedAt, &token.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, rows.Err()
+}
@@ -0,0 +1,96 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/internal/core/repository"
+	"github.com/Tomelin/financial-management-backend/pkg/db"
+	"github.com/Tomelin/financial-management-backend/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+type PersonalAccessTokenRepoTestSuite struct {
+	suite.Suite
+	database func() db.Database
+	repo     repository.IPersonalAccessTokenRepo
+	userID   string
+	ctx      context.Context
+}
+
+func (s *PersonalAccessTokenRepoTestSuite) SetupTest() {
+	repo, err := repository.NewPersonalAccessTokenRepo(s.database(), logger.NewLoggerConfig(map[string]any{"level": "error"}))
+	s.Require().Nil(err)
+
+	s.repo = repo
+	s.userID = uuid.New().String()
+	s.ctx = context.Background()
+}
+
+func (s *PersonalAccessTokenRepoTestSuite) create(name string, createdAt time.Time) (string, *entity.PersonalAccessToken) {
+	token, record, err := entity.NewPersonalAccessToken(s.userID, &entity.PersonalAccessTokenRequest{
+		Name:   name,
+		Scopes: []entity.TokenScope{{Module: entity.ModuleTransaction, Level: entity.PermissionView}},
+	})
+	s.Require().Nil(err)
+	record.CreatedAt = createdAt.UTC().Truncate(time.Second)
+	s.Require().Nil(s.repo.CreateAccessToken(s.ctx, record))
+	return token, record
+}
+
+func (s *PersonalAccessTokenRepoTestSuite) TestCreateAndGetByHash() {
+	token, record := s.create("ci", time.Now())
+
+	found, err := s.repo.GetAccessTokenByHash(s.ctx, entity.PersonalAccessTokenHash(token))
+	s.Require().Nil(err)
+	s.Require().NotNil(found)
+	s.Equal(record.ID, found.ID)
+	s.Equal(s.userID, found.UserID)
+	s.Equal(record.Scopes, found.Scopes)
+	s.True(found.ExpiresAt.IsZero())
+
+	found, err = s.repo.GetAccessTokenByHash(s.ctx, entity.PersonalAccessTokenHash("pat_unknown"))
+	s.Nil(err)
+	s.Nil(found)
+}
+
+func (s *PersonalAccessTokenRepoTestSuite) TestListTouchAndDelete() {
+	_, older := s.create("older", time.Now().Add(-time.Hour))
+	token, newer := s.create("newer", time.Now())
+
+	tokens, err := s.repo.ListAccessTokens(s.ctx, s.userID)
+	s.Require().Nil(err)
+	s.Require().Len(tokens, 2)
+	s.Equal(newer.ID, tokens[0].ID)
+	s.Equal(older.ID, tokens[1].ID)
+
+	usedAt := time.Now().UTC().Truncate(time.Second)
+	s.Require().Nil(s.repo.TouchAccessToken(s.ctx, newer.TokenHash, usedAt))
+	found, err := s.repo.GetAccessTokenByHash(s.ctx, entity.PersonalAccessTokenHash(token))
+	s.Require().Nil(err)
+	s.True(usedAt.Equal(found.LastUsedAt))
+
+	err = s.repo.DeleteAccessToken(s.ctx, uuid.New().String(), newer.ID)
+	s.True(errors.Is(err, entity.ErrPersonalAccessTokenNotFound), "a token is deleted only by its user")
+	s.Require().Nil(s.repo.DeleteAccessToken(s.ctx, s.userID, newer.ID))
+	s.True(errors.Is(s.repo.DeleteAccessToken(s.ctx, s.userID, newer.ID), entity.ErrPersonalAccessTokenNotFound))
+
+	found, err = s.repo.GetAccessTokenByHash(s.ctx, entity.PersonalAccessTokenHash(token))
+	s.Nil(err)
+	s.Nil(found)
+	tokens, err = s.repo.ListAccessTokens(s.ctx, s.userID)
+	s.Require().Nil(err)
+	s.Len(tokens, 1)
+}
+
+func TestRunPersonalAccessTokenRepoTestSuite(t *testing.T) {
+	suite.Run(t, &PersonalAccessTokenRepoTestSuite{database: func() db.Database { return db.NewMemoryStore() }})
+}
+
+func TestRunPersonalAccessTokenSQLRepoTestSuite(t *testing.T) {
+	suite.Run(t, &PersonalAccessTokenRepoTestSuite{database: func() db.Database { return newSQLiteDatabase(t) }})
+}
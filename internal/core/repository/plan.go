@@ -3,11 +3,9 @@ package repository
 import (
 	"context"
 	"fmt"
-	"strconv"
 
 	"github.com/Tomelin/financial-management-backend/internal/core/entity"
 	"github.com/Tomelin/financial-management-backend/pkg/db"
-	"google.golang.org/api/iterator"
 )
 
 type IPlanRepo interface {
@@ -15,20 +13,24 @@ type IPlanRepo interface {
 }
 
 type PlanRepo struct {
-	db db.FirebaseDatabaseInterface
+	db db.DocumentStore
 }
 
-func NewPlanRepository(db db.FirebaseDatabaseInterface) (entity.IPlan, error) {
-	if db == nil {
-		return nil, fmt.Errorf("db %s", entity.ErrRequired)
+// NewPlanRepository creates the plan repository of the database kind
+func NewPlanRepository(database db.Database) (entity.IPlan, error) {
+	switch conn := database.(type) {
+	case *db.SQLDatabase:
+		return NewPlanSQLRepository(conn)
+	case db.DocumentStore:
+		return &PlanRepo{db: conn}, nil
 	}
-	return &PlanRepo{db: db}, nil
+	return nil, fmt.Errorf("db %s", entity.ErrRequired)
 }
 
 func (u *PlanRepo) Create(plan *entity.PlanResponse) (*entity.PlanResponse, error) {
 	ctx := context.Background()
 
-	_, err := u.db.Collection("plans").Doc(plan.ID).Set(ctx, plan)
+	err := u.db.Collection("plans").Doc(plan.ID).Set(ctx, plan)
 	if err != nil {
 		return nil, err
 	}
@@ -47,33 +49,13 @@ func (u *PlanRepo) Create(plan *entity.PlanResponse) (*entity.PlanResponse, erro
 	return &PlanResponse, nil
 }
 
-func (u *PlanRepo) Get() ([]entity.PlanResponse, error) {
-
-	iter := u.db.Documents(context.Background(), "plans")
-
-	defer iter.Stop()
-
-	var documents []entity.PlanResponse
-	for {
-		var tenant entity.PlanResponse
-		doc, err := iter.Next()
-		if err == iterator.Done {
-			break
-		}
-		if err != nil {
-			return nil, err
-		}
-
-		err = doc.DataTo(&tenant)
-		if err != nil {
-			return nil, err
-		}
-		tenant.ID = doc.Ref.ID
-
-		documents = append(documents, tenant)
-	}
-
-	return documents, nil
+func (u *PlanRepo) Get(page *entity.PageRequest) (*entity.Page[entity.PlanResponse], error) {
+	return documentPage(context.Background(), u.db.Collection("plans"), page, func(doc *db.Document) (entity.PlanResponse, error) {
+		var plan entity.PlanResponse
+		err := doc.DataTo(&plan)
+		plan.ID = doc.ID
+		return plan, err
+	})
 }
 
 func (u *PlanRepo) GetById(id *string) (*entity.PlanResponse, error) {
@@ -93,7 +75,7 @@ func (u *PlanRepo) GetById(id *string) (*entity.PlanResponse, error) {
 
 func (u *PlanRepo) Update(data *entity.PlanResponse) (*entity.PlanResponse, error) {
 
-	_, err := u.db.Collection("plans").Doc(data.ID).Set(context.Background(), data)
+	err := u.db.Collection("plans").Doc(data.ID).Set(context.Background(), data)
 	if err != nil {
 		return nil, err
 	}
@@ -113,44 +95,23 @@ func (u *PlanRepo) Update(data *entity.PlanResponse) (*entity.PlanResponse, erro
 }
 
 func (u *PlanRepo) Delete(id *string) error {
-	_, err := u.db.Collection("plans").Doc(*id).Delete(context.Background())
-
-	return err
+	return u.db.Collection("plans").Doc(*id).Delete(context.Background())
 }
 
-func (u *PlanRepo) GetByFilterMany(key string, value *string) ([]entity.PlanResponse, error) {
-
-	var data interface{} = *value
-	resultInt, err := strconv.Atoi(*value)
-	if err == nil {
-		data = resultInt
-	}
-
-	resultBool, err := strconv.ParseBool(*value)
-	if err == nil {
-		data = resultBool
+func (u *PlanRepo) GetByFilterMany(filter []entity.QueryDBClause) ([]entity.PlanResponse, error) {
+	docs, err := applyClauses(u.db.Collection("plans"), filter).Documents(context.Background())
+	if err != nil {
+		return nil, err
 	}
 
-	iter := u.db.Collection("plans").Where(key, "==", data).Documents(context.Background())
-
-	defer iter.Stop()
-
 	var documents []entity.PlanResponse
-	for {
+	for _, doc := range docs {
 		var plan entity.PlanResponse
-		doc, err := iter.Next()
-		if err == iterator.Done {
-			break
-		}
-		if err != nil {
-			return nil, err
-		}
-
 		err = doc.DataTo(&plan)
 		if err != nil {
 			return nil, err
 		}
-		plan.ID = doc.Ref.ID
+		plan.ID = doc.ID
 
 		documents = append(documents, plan)
 	}
@@ -163,22 +124,17 @@ func (u *PlanRepo) GetByFilterMany(key string, value *string) ([]entity.PlanResp
 }
 
 func (u *PlanRepo) GetByFilterOne(key string, value *string) (*entity.PlanResponse, error) {
-	doc := u.db.Collection("plans").Where(key, "==", *value).Limit(1).Documents(context.Background())
-	defer doc.Stop()
-
-	result, err := doc.Next()
+	docs, err := u.db.Collection("plans").Where(key, "==", *value).Limit(1).Documents(context.Background())
 	if err != nil {
 		return nil, err
-
 	}
 
-	if result == nil {
+	if len(docs) == 0 {
 		return nil, nil
-
 	}
 
 	var plan entity.PlanResponse
-	err = result.DataTo(&plan)
+	err = docs[0].DataTo(&plan)
 	if err != nil {
 		return nil, err
 	}
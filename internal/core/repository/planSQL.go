@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/pkg/db"
+)
+
+var planTable = sqlTable{
+	name: "plans",
+	columns: map[string]sqlKind{
+		"id":          sqlText,
+		"name":        sqlText,
+		"description": sqlText,
+		"price":       sqlDecimal,
+		"currency":    sqlText,
+		"create_at":   sqlTime,
+		"update_at":   sqlTime,
+	},
+}
+
+const planColumns = `id, name, description, features, price, currency, create_at, update_at`
+
+type PlanSQLRepo struct {
+	db *db.SQLDatabase
+}
+
+// NewPlanSQLRepository creates the plan repository of a relational database
+func NewPlanSQLRepository(database *db.SQLDatabase) (entity.IPlan, error) {
+	if database == nil {
+		return nil, fmt.Errorf("db %s", entity.ErrRequired)
+	}
+	return &PlanSQLRepo{db: database}, nil
+}
+
+func (u *PlanSQLRepo) Create(plan *entity.PlanResponse) (*entity.PlanResponse, error) {
+	if err := u.save(context.Background(), plan); err != nil {
+		return nil, err
+	}
+	return u.GetById(&plan.ID)
+}
+
+func (u *PlanSQLRepo) Get(page *entity.PageRequest) (*entity.Page[entity.PlanResponse], error) {
+	return sqlPage(context.Background(), u.db, planTable, page, "", nil, u.query)
+}
+
+func (u *PlanSQLRepo) GetById(id *string) (*entity.PlanResponse, error) {
+	plans, err := u.query(context.Background(), `id = ?`, "", 0, *id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(plans) == 0 {
+		return nil, fmt.Errorf("%w: plans/%s", db.ErrNotFound, *id)
+	}
+
+	return &plans[0], nil
+}
+
+func (u *PlanSQLRepo) Update(data *entity.PlanResponse) (*entity.PlanResponse, error) {
+	if err := u.save(context.Background(), data); err != nil {
+		return nil, err
+	}
+	return u.GetById(&data.ID)
+}
+
+func (u *PlanSQLRepo) Delete(id *string) error {
+	_, err := u.db.DB.ExecContext(context.Background(), u.db.Rebind(`DELETE FROM plans WHERE id = ?`), *id)
+	return err
+}
+
+func (u *PlanSQLRepo) GetByFilterMany(filter []entity.QueryDBClause) ([]entity.PlanResponse, error) {
+	where, args, err := planTable.whereClauses(u.db, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	plans, err := u.query(context.Background(), where, "", 0, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(plans) == 0 {
+		return nil, nil
+	}
+
+	return plans, nil
+}
+
+func (u *PlanSQLRepo) GetByFilterOne(key string, value *string) (*entity.PlanResponse, error) {
+	where, args, err := planTable.where(u.db, []entity.QueryDB{{Key: key, Value: *value, Condition: "=="}})
+	if err != nil {
+		return nil, err
+	}
+
+	plans, err := u.query(context.Background(), where, "", 1, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(plans) == 0 {
+		return nil, nil
+	}
+
+	return &plans[0], nil
+}
+
+func (u *PlanSQLRepo) save(ctx context.Context, plan *entity.PlanResponse) error {
+	features, err := jsonValue(plan.Features)
+	if err != nil {
+		return err
+	}
+
+	_, err = u.db.DB.ExecContext(ctx, u.db.Rebind(`INSERT INTO plans (`+planColumns+`)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (id) DO UPDATE SET
+    name = excluded.name,
+    description = excluded.description,
+    features = excluded.features,
+    price = excluded.price,
+    currency = excluded.currency,
+    create_at = excluded.create_at,
+    update_at = excluded.update_at`),
+		plan.ID, plan.Name, plan.Description, features, plan.Price.Decimal(), plan.Currency, plan.CreatedAt, plan.UpdatedAt)
+	return err
+}
+
+func (u *PlanSQLRepo) query(ctx context.Context, where, order string, limit int, args ...any) ([]entity.PlanResponse, error) {
+	rows, err := u.db.DB.QueryContext(ctx, u.db.Rebind(planTable.selectQuery(planColumns, where, order, limit)), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var plans []entity.PlanResponse
+	for rows.Next() {
+		var plan entity.PlanResponse
+		err := rows.Scan(&plan.ID, &plan.Name, &plan.Description, sqlJSON{&plan.Features},
+			sqlMoney{&plan.Price}, &plan.Currency, &plan.CreatedAt, &plan.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := plan.AfterDocumentLoad(); err != nil {
+			return nil, err
+		}
+		plans = append(plans, plan)
+	}
+	return plans, rows.Err()
+}
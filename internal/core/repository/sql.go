@@ -0,0 +1,352 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/pkg/db"
+)
+
+// ErrInvalidFilter is returned when a filter cannot be translated to SQL
+var ErrInvalidFilter = errors.New("invalid filter")
+
+type sqlKind int
+
+const (
+	sqlText sqlKind = iota
+	sqlInteger
+	sqlBoolean
+	sqlDecimal
+	sqlTime
+	sqlArray
+)
+
+// sqlTable describes the columns of a table that can be filtered
+// The column names are the same field names of the documents, so the filters work with both backends
+type sqlTable struct {
+	name    string
+	columns map[string]sqlKind
+}
+
+// where translates the filter to a condition joined by AND, the empty clauses are ignored like in applyFilter
+func (t sqlTable) where(database *db.SQLDatabase, filter []entity.QueryDB) (string, []any, error) {
+	return t.join(database, filter, " AND ")
+}
+
+// whereClauses translates the clauses to a condition, the clauses are joined by AND
+// The queries and the nested clauses of an "or" clause are joined by OR, the others by AND
+func (t sqlTable) whereClauses(database *db.SQLDatabase, clauses []entity.QueryDBClause) (string, []any, error) {
+	var conditions []string
+	var args []any
+	for _, clause := range clauses {
+		condition, clauseArgs, err := t.clause(database, clause)
+		if err != nil {
+			return "", nil, err
+		}
+		if condition == "" {
+			continue
+		}
+		conditions = append(conditions, "("+condition+")")
+		args = append(args, clauseArgs...)
+	}
+
+	return strings.Join(conditions, " AND "), args, nil
+}
+
+func (t sqlTable) clause(database *db.SQLDatabase, clause entity.QueryDBClause) (string, []any, error) {
+	separator := " AND "
+	if clause.Clause == entity.QueryClauseOr {
+		separator = " OR "
+	}
+
+	condition, args, err := t.join(database, clause.Queries, separator)
+	if err != nil {
+		return "", nil, err
+	}
+
+	conditions := []string{}
+	if condition != "" {
+		conditions = append(conditions, condition)
+	}
+
+	for _, nested := range clause.Clauses {
+		nestedCondition, nestedArgs, err := t.clause(database, nested)
+		if err != nil {
+			return "", nil, err
+		}
+		if nestedCondition == "" {
+			continue
+		}
+		conditions = append(conditions, "("+nestedCondition+")")
+		args = append(args, nestedArgs...)
+	}
+
+	return strings.Join(conditions, separator), args, nil
+}
+
+func (t sqlTable) join(database *db.SQLDatabase, filter []entity.QueryDB, separator string) (string, []any, error) {
+	var conditions []string
+	var args []any
+	for _, f := range filter {
+		if f.Key == "" || f.Value == "" {
+			continue
+		}
+
+		condition, conditionArgs, err := t.condition(database, f)
+		if err != nil {
+			return "", nil, err
+		}
+		conditions = append(conditions, condition)
+		args = append(args, conditionArgs...)
+	}
+
+	return strings.Join(conditions, separator), args, nil
+}
+
+func (t sqlTable) condition(database *db.SQLDatabase, f entity.QueryDB) (string, []any, error) {
+	kind, ok := t.columns[f.Key]
+	if !ok {
+		return "", nil, fmt.Errorf("%w: unknown field %s", ErrInvalidFilter, f.Key)
+	}
+
+	column := quoteIdentifier(f.Key)
+	condition := checkFirebaseCondition(&f.Condition)
+
+	if kind == sqlArray {
+		switch condition {
+		case "array-contains":
+			return database.ArrayContains(column), []any{f.Value}, nil
+		case "array-contains-any":
+			values := splitValues(f.Value)
+			conditions := make([]string, len(values))
+			args := make([]any, len(values))
+			for i, value := range values {
+				conditions[i] = database.ArrayContains(column)
+				args[i] = value
+			}
+			return "(" + strings.Join(conditions, " OR ") + ")", args, nil
+		}
+		return "", nil, fmt.Errorf("%w: %s is not supported on %s", ErrInvalidFilter, condition, f.Key)
+	}
+
+	switch condition {
+	case "in", "not-in":
+		values := splitValues(f.Value)
+		placeholders := make([]string, len(values))
+		args := make([]any, len(values))
+		for i, value := range values {
+			arg, err := sqlValue(kind, value)
+			if err != nil {
+				return "", nil, fmt.Errorf("%w: %s: %s", ErrInvalidFilter, f.Key, err.Error())
+			}
+			placeholders[i] = "?"
+			args[i] = arg
+		}
+
+		operator := "IN"
+		if condition == "not-in" {
+			operator = "NOT IN"
+		}
+		return fmt.Sprintf("%s %s (%s)", column, operator, strings.Join(placeholders, ", ")), args, nil
+	case "array-contains", "array-contains-any":
+		return "", nil, fmt.Errorf("%w: %s is not supported on %s", ErrInvalidFilter, condition, f.Key)
+	}
+
+	arg, err := sqlValue(kind, f.Value)
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %s: %s", ErrInvalidFilter, f.Key, err.Error())
+	}
+
+	return fmt.Sprintf("%s %s ?", column, condition), []any{arg}, nil
+}
+
+// sqlValue converts the value of the filter to the type of the column
+func sqlValue(kind sqlKind, value string) (any, error) {
+	switch kind {
+	case sqlInteger:
+		return strconv.ParseInt(value, 10, 64)
+	case sqlBoolean:
+		return strconv.ParseBool(value)
+	case sqlDecimal:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return nil, err
+		}
+		return value, nil
+	case sqlTime:
+		return entity.ParseQueryTime(value)
+	}
+	return value, nil
+}
+
+func splitValues(value string) []string {
+	values := strings.Split(value, ",")
+	for i := range values {
+		values[i] = strings.TrimSpace(values[i])
+	}
+	return values
+}
+
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// selectQuery returns the select of the columns with the optional condition and limit
+func (t sqlTable) selectQuery(columns string, where, order string, limit int) string {
+	query := fmt.Sprintf("SELECT %s FROM %s", columns, t.name)
+	if where != "" {
+		query += " WHERE " + where
+	}
+	if order != "" {
+		query += " ORDER BY " + order
+	}
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit)
+	}
+	return query
+}
+
+// sqlPage reads a page of the table ordered by the column of the page and then by id
+// where and args filter the whole list, the cursor is a keyset condition on the ordered columns
+func sqlPage[T any](ctx context.Context, database *db.SQLDatabase, t sqlTable, page *entity.PageRequest, where string, args []any,
+	query func(ctx context.Context, where, order string, limit int, args ...any) ([]T, error)) (*entity.Page[T], error) {
+	if page == nil {
+		page = entity.DefaultPage()
+	}
+
+	column := page.OrderBy
+	if column == "id" {
+		column = ""
+	}
+	if _, ok := t.columns[column]; column != "" && !ok {
+		return nil, fmt.Errorf("%w: cannot order by %s", entity.ErrInvalidPage, column)
+	}
+
+	var total int64
+	count := "SELECT COUNT(*) FROM " + t.name
+	if where != "" {
+		count += " WHERE " + where
+	}
+	if err := database.DB.QueryRowContext(ctx, database.Rebind(count), args...).Scan(&total); err != nil {
+		return nil, err
+	}
+
+	dir, direction, operator := pageDirection(page), "ASC", ">"
+	if dir == db.Desc {
+		direction, operator = "DESC", "<"
+	}
+
+	order := "id " + direction
+	keyset := "id " + operator + " ?"
+	if column != "" {
+		order = quoteIdentifier(column) + " " + direction + ", " + order
+		keyset = fmt.Sprintf("(%s, id) %s (?, ?)", quoteIdentifier(column), operator)
+	}
+
+	if page.Cursor != "" {
+		values, err := db.DecodeCursor(page.Cursor, column, dir)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", entity.ErrInvalidPage, err)
+		}
+		if len(values) != strings.Count(keyset, "?") {
+			return nil, fmt.Errorf("%w: %s", entity.ErrInvalidPage, db.ErrInvalidCursor)
+		}
+		where = joinAnd(where, keyset)
+		args = append(append([]any{}, args...), values...)
+	}
+
+	// one more item tells if there is a next page
+	items, err := query(ctx, where, order, page.Limit+1, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &entity.Page[T]{Items: items, Total: &total}
+	if len(items) > page.Limit {
+		result.Items = items[:page.Limit]
+
+		data, err := db.Encode(&result.Items[page.Limit-1])
+		if err != nil {
+			return nil, err
+		}
+
+		values := []any{data["id"]}
+		if column != "" {
+			values = []any{data[column], data["id"]}
+		}
+
+		result.NextCursor, err = db.EncodeCursor(column, dir, values...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if result.Items == nil {
+		result.Items = []T{}
+	}
+	return result, nil
+}
+
+// joinAnd joins the conditions that are not empty
+func joinAnd(conditions ...string) string {
+	var result []string
+	for _, c := range conditions {
+		if c != "" {
+			result = append(result, "("+c+")")
+		}
+	}
+	return strings.Join(result, " AND ")
+}
+
+// sqlMoney reads a decimal column into a pending money, the currency is bound by AfterDocumentLoad
+type sqlMoney struct {
+	money *entity.Money
+}
+
+func (s sqlMoney) Scan(src any) error {
+	if b, ok := src.([]byte); ok {
+		src = string(b)
+	}
+	return s.money.ScanDocumentValue(src)
+}
+
+// sqlJSON reads and writes a JSON column
+type sqlJSON struct {
+	value any
+}
+
+func (s sqlJSON) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		return nil
+	case string:
+		return json.Unmarshal([]byte(v), s.value)
+	case []byte:
+		return json.Unmarshal(v, s.value)
+	}
+	return fmt.Errorf("cannot read %T as json", src)
+}
+
+// jsonValue encodes the value of a JSON column, nil slices are stored as empty arrays
+func jsonValue(value any) (string, error) {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	if string(b) == "null" {
+		return "[]", nil
+	}
+	return string(b), nil
+}
+
+// sqlErrorCode returns the response code of a SQL error, the invalid filters are a bad request
+func sqlErrorCode(err error) entity.ResponseCode {
+	if errors.Is(err, ErrInvalidFilter) {
+		return entity.ResponseCodeBadRequest
+	}
+	return entity.ResponseCodeInternalServer
+}
@@ -0,0 +1,394 @@
+package repository_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/internal/core/repository"
+	"github.com/Tomelin/financial-management-backend/pkg/db"
+	"github.com/Tomelin/financial-management-backend/pkg/logger"
+	"github.com/Tomelin/financial-management-backend/pkg/observability"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// newSQLiteDatabase opens a migrated sqlite database in a temporary file, so the writers use the real locks
+func newSQLiteDatabase(t *testing.T) *db.SQLDatabase {
+	dsn := "file:" + filepath.Join(t.TempDir(), "test.db") + "?_busy_timeout=5000&_txlock=immediate"
+	database, err := db.NewSQLDatabase(context.Background(), map[string]any{"dsn": dsn}, db.KindSQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.Close() })
+	return database
+}
+
+type SQLRepoTestSuite struct {
+	suite.Suite
+	database *db.SQLDatabase
+	userID   string
+	ctx      context.Context
+}
+
+func (s *SQLRepoTestSuite) SetupTest() {
+	s.database = newSQLiteDatabase(s.T())
+	s.userID = uuid.New().String()
+	s.ctx = context.Background()
+}
+
+func (s *SQLRepoTestSuite) TestWallet_Filters() {
+	repo, err := repository.NewWalletRepo(s.database)
+	s.Require().Nil(err)
+
+	for _, name := range []string{"a", "b", "c"} {
+		wallet, mErr := entity.NewWallet(&entity.WalletResponse{Name: name, OwnerID: s.userID, TenantID: uuid.New().String(), Currency: "BRL"})
+		s.Require().Nil(mErr)
+		if name != "c" {
+			wallet.SharedWithTenants = []string{"t-" + name, "t-all"}
+		}
+		_, mErr = repo.Create(s.ctx, &s.userID, wallet)
+		s.Require().Nil(mErr)
+	}
+
+	names := func(filter []entity.QueryDB) []string {
+		wallets, mErr := repo.GetByFilterMany(s.ctx, &s.userID, entity.AndClause(filter))
+		s.Require().Nil(mErr)
+		result := []string{}
+		for _, w := range wallets {
+			result = append(result, w.Name)
+		}
+		return result
+	}
+
+	s.ElementsMatch([]string{"a", "b"}, names([]entity.QueryDB{{Key: "shared_with_tenants", Value: "t-all", Condition: "array-contains"}}))
+	s.ElementsMatch([]string{"a"}, names([]entity.QueryDB{{Key: "shared_with_tenants", Value: "t-a, t-x", Condition: "array-contains-any"}}))
+	s.ElementsMatch([]string{"a", "c"}, names([]entity.QueryDB{{Key: "name", Value: "a,c", Condition: "in"}}))
+	s.ElementsMatch([]string{"b"}, names([]entity.QueryDB{{Key: "name", Value: "a,c", Condition: "not-in"}}))
+	s.ElementsMatch([]string{"b", "c"}, names([]entity.QueryDB{{Key: "name", Value: "a", Condition: ">"}}))
+	s.ElementsMatch([]string{"a", "b", "c"}, names([]entity.QueryDB{{Key: "balance", Value: "0", Condition: "=="}}))
+}
+
+func (s *SQLRepoTestSuite) TestWallet_InvalidFilter() {
+	repo, err := repository.NewWalletRepo(s.database)
+	s.Require().Nil(err)
+
+	filters := [][]entity.QueryDB{
+		{{Key: "name; DROP TABLE wallets", Value: "x", Condition: "=="}},
+		{{Key: "balance", Value: "abc", Condition: "=="}},
+		{{Key: "name", Value: "x", Condition: "array-contains"}},
+	}
+	for _, filter := range filters {
+		_, mErr := repo.GetByFilterMany(s.ctx, &s.userID, entity.AndClause(filter))
+		s.NotNil(mErr)
+		s.Equal(entity.ResponseCodeBadRequest, mErr.Code)
+	}
+}
+
+func (s *SQLRepoTestSuite) TestPlan_RoundTrip() {
+	repo, err := repository.NewPlanRepository(s.database)
+	s.Require().Nil(err)
+
+	plan := &entity.PlanResponse{
+		ID:        uuid.New().String(),
+		Name:      "gold",
+		Features:  []entity.PlanFeatures{{Name: "wallets", Count: 10}},
+		Price:     entity.NewMoney(1999, "BRL"),
+		Currency:  "BRL",
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
+	}
+	created, err := repo.Create(plan)
+	s.Require().Nil(err)
+	s.Equal(entity.NewMoney(1999, "BRL"), created.Price)
+	s.Equal(plan.Features, created.Features)
+
+	name := "gold"
+	found, err := repo.GetByFilterOne("name", &name)
+	s.Nil(err)
+	s.Equal(plan.ID, found.ID)
+
+	name = "none"
+	found, err = repo.GetByFilterOne("name", &name)
+	s.Nil(err)
+	s.Nil(found)
+
+	s.Nil(repo.Delete(&plan.ID))
+	_, err = repo.GetById(&plan.ID)
+	s.ErrorIs(err, db.ErrNotFound)
+}
+
+func (s *SQLRepoTestSuite) TestTenant_RoundTrip() {
+	repo, err := repository.NewTenantRepository(s.database)
+	s.Require().Nil(err)
+
+	tenant := &entity.TenantResponse{
+		ID:      uuid.New().String(),
+		Name:    "owner@example.com",
+		OwnerID: s.userID,
+		Users:   []string{s.userID},
+		Plan:    entity.PlanResponse{ID: uuid.New().String(), Name: "bronze", Price: entity.NewMoney(500, "BRL"), Currency: "BRL"},
+	}
+	created, err := repo.Create(tenant)
+	s.Require().Nil(err)
+	s.Equal(tenant.Users, created.Users)
+	s.Equal(entity.NewMoney(500, "BRL"), created.Plan.Price)
+
+	tenants, err := repo.GetByFilterMany(s.ctx, entity.AndClause([]entity.QueryDB{{Key: "users", Value: s.userID, Condition: "array-contains"}}))
+	s.Nil(err)
+	s.Len(tenants, 1)
+
+	found, err := repo.GetByFilterOne(s.ctx, []entity.QueryDB{{Key: "owner_id", Value: uuid.New().String(), Condition: "=="}})
+	s.Nil(err)
+	s.Nil(found)
+
+	gold := entity.PlanResponse{ID: uuid.New().String(), Name: "gold", Price: entity.NewMoney(4990, "BRL"), Currency: "BRL"}
+	s.Require().Nil(repo.SetPlan(&tenant.ID, &gold))
+	plan, err := repo.GetPlan(&tenant.ID)
+	s.Require().Nil(err)
+	s.Equal(gold.ID, plan.ID)
+
+	missing := uuid.New().String()
+	s.ErrorIs(repo.SetPlan(&missing, &gold), db.ErrNotFound)
+
+	created.Suspended = true
+	created.SuspendedReason = "chargeback"
+	created.SuspendedAt = time.Now().UTC().Truncate(time.Second)
+	_, err = repo.Update(created)
+	s.Require().Nil(err)
+	suspended, err := repo.GetByFilterMany(s.ctx, entity.AndClause([]entity.QueryDB{{Key: "suspended", Value: "true", Condition: "=="}}))
+	s.Require().Nil(err)
+	s.Require().Len(suspended, 1)
+	s.Equal("chargeback", suspended[0].SuspendedReason)
+	s.True(created.SuspendedAt.Equal(suspended[0].SuspendedAt))
+}
+
+func (s *SQLRepoTestSuite) TestAudit_RoundTrip() {
+	repo, err := repository.NewAuditRepo(s.database)
+	s.Require().Nil(err)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	for i, action := range []string{entity.AuditPlanCreate, entity.AuditTenantSuspend, entity.AuditUserSearch} {
+		s.Require().Nil(repo.CreateAuditLog(s.ctx, &entity.AuditLog{
+			ID:        uuid.New().String(),
+			ActorID:   s.userID,
+			Action:    action,
+			Method:    "POST",
+			Path:      "/admin",
+			Status:    200,
+			CreatedAt: now.Add(time.Duration(i) * time.Minute),
+		}))
+	}
+
+	page, mErr := repo.GetAuditLogs(s.ctx, &entity.PageRequest{Limit: 2, OrderBy: "create_at", Direction: entity.PageDesc})
+	s.Require().Nil(mErr)
+	s.Require().Len(page.Items, 2)
+	s.Equal(entity.AuditUserSearch, page.Items[0].Action, "the newest first")
+	s.Equal(int64(3), *page.Total)
+	s.NotEmpty(page.NextCursor)
+}
+
+func (s *SQLRepoTestSuite) TestSubscription_RoundTrip() {
+	repo, err := repository.NewSubscriptionRepo(s.database)
+	s.Require().Nil(err)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	plan := &entity.PlanResponse{ID: uuid.New().String(), Price: entity.NewMoney(1990, "BRL")}
+	trial := entity.NewSubscription(uuid.New().String(), plan, now)
+	s.Require().Nil(repo.SaveSubscription(s.ctx, trial))
+
+	canceled := entity.NewSubscription(uuid.New().String(), plan, now)
+	s.Require().Nil(canceled.MarkPastDue(now))
+	canceled.Advance(now.Add(entity.SubscriptionGracePeriod))
+	s.Require().Nil(repo.SaveSubscription(s.ctx, canceled))
+
+	found, mErr := repo.GetSubscription(s.ctx, trial.TenantID)
+	s.Require().Nil(mErr)
+	s.Equal(entity.SubscriptionTrialing, found.Status)
+	s.True(trial.PeriodEnd.Equal(found.PeriodEnd))
+
+	missing, mErr := repo.GetSubscription(s.ctx, uuid.New().String())
+	s.Nil(mErr)
+	s.Nil(missing)
+
+	due, mErr := repo.GetSubscriptionsDue(s.ctx, now)
+	s.Require().Nil(mErr)
+	s.Empty(due)
+
+	due, mErr = repo.GetSubscriptionsDue(s.ctx, trial.PeriodEnd)
+	s.Require().Nil(mErr)
+	s.Require().Len(due, 1, "a canceled subscription is not due")
+	s.Equal(trial.TenantID, due[0].TenantID)
+
+	wallets, err := repository.NewWalletRepo(s.database)
+	s.Require().Nil(err)
+	wallet, mErr := entity.NewWallet(&entity.WalletResponse{Name: "a", OwnerID: s.userID, TenantID: trial.TenantID, Currency: "BRL"})
+	s.Require().Nil(mErr)
+	wallet.ReadOnly = true
+	_, mErr = wallets.Create(s.ctx, &s.userID, wallet)
+	s.Require().Nil(mErr)
+	stored, mErr := wallets.GetByID(s.ctx, &wallet.ID)
+	s.Require().Nil(mErr)
+	s.True(stored.ReadOnly)
+}
+
+func (s *SQLRepoTestSuite) TestBilling_RoundTrip() {
+	repo, err := repository.NewBillingRepo(s.database)
+	s.Require().Nil(err)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	account := &entity.BillingAccount{TenantID: uuid.New().String(), CustomerID: "cus_1", CreatedAt: now, UpdatedAt: now}
+	s.Require().Nil(repo.SaveBillingAccount(s.ctx, account))
+
+	account.SubscriptionID = "sub_1"
+	s.Require().Nil(repo.SaveBillingAccount(s.ctx, account))
+
+	found, mErr := repo.GetBillingAccount(s.ctx, account.TenantID)
+	s.Require().Nil(mErr)
+	s.Equal("cus_1", found.CustomerID)
+	s.Equal("sub_1", found.SubscriptionID)
+
+	missing, mErr := repo.GetBillingAccount(s.ctx, uuid.New().String())
+	s.Nil(mErr)
+	s.Nil(missing)
+
+	event := &entity.PaymentEvent{ID: "evt_1", Type: "invoice.paid", TenantID: account.TenantID, ClaimedAt: now}
+	claimed, mErr := repo.ClaimPaymentEvent(s.ctx, event)
+	s.Require().Nil(mErr)
+	s.True(claimed)
+	claimed, mErr = repo.ClaimPaymentEvent(s.ctx, event)
+	s.Require().Nil(mErr)
+	s.False(claimed, "an event is claimed once")
+
+	s.Require().Nil(repo.ReleasePaymentEvent(s.ctx, event.ID))
+	claimed, mErr = repo.ClaimPaymentEvent(s.ctx, event)
+	s.Require().Nil(mErr)
+	s.True(claimed, "a released event is claimed again")
+
+	event.ProcessedAt = now
+	s.Require().Nil(repo.SavePaymentEvent(s.ctx, event))
+	s.Require().Nil(repo.ReleasePaymentEvent(s.ctx, event.ID), "an applied event is kept")
+	expired := *event
+	expired.ClaimedAt = now.Add(2 * entity.PaymentEventClaimTTL)
+	claimed, mErr = repo.ClaimPaymentEvent(s.ctx, &expired)
+	s.Require().Nil(mErr)
+	s.False(claimed, "an applied event is not claimed again")
+
+	processed, mErr := repo.GetPaymentEvent(s.ctx, event.ID)
+	s.Require().Nil(mErr)
+	s.Equal(account.TenantID, processed.TenantID)
+	s.True(now.Equal(processed.ProcessedAt))
+
+	stale := &entity.PaymentEvent{ID: "evt_3", ClaimedAt: now.Add(-2 * entity.PaymentEventClaimTTL)}
+	claimed, mErr = repo.ClaimPaymentEvent(s.ctx, stale)
+	s.Require().Nil(mErr)
+	s.Require().True(claimed)
+	stale.ClaimedAt = now
+	claimed, mErr = repo.ClaimPaymentEvent(s.ctx, stale)
+	s.Require().Nil(mErr)
+	s.True(claimed, "the expired claim of a request that stopped is taken")
+
+	processed, mErr = repo.GetPaymentEvent(s.ctx, "evt_2")
+	s.Nil(mErr)
+	s.Nil(processed)
+}
+
+func (s *SQLRepoTestSuite) TestUser_FilterClauses() {
+	repo, err := repository.NewUserRepository(s.database)
+	s.Require().Nil(err)
+
+	for _, email := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		_, err := repo.Create(s.ctx, &entity.AccountUser{
+			ID:    uuid.New().String(),
+			Roles: []entity.AccountRoles{{Key: "wallet", Name: "wallet", Value: "admin"}},
+			User:  entity.User{Name: email, Email: email, Provider: "google"},
+		})
+		s.Require().Nil(err)
+	}
+
+	users, err := repo.GetByFilterMany(s.ctx, []entity.QueryDBClause{{
+		Clause: entity.QueryClauseOr,
+		Queries: []entity.QueryDB{
+			{Key: "email", Value: "a@example.com", Condition: "=="},
+			{Key: "email", Value: "c@example.com", Condition: "=="},
+		},
+	}})
+	s.Nil(err)
+	s.Len(users, 2)
+
+	users, err = repo.GetByFilterMany(s.ctx, []entity.QueryDBClause{{
+		Clause: entity.QueryClauseAnd,
+		Queries: []entity.QueryDB{
+			{Key: "provider", Value: "google", Condition: "=="},
+			{Key: "email", Value: "b@example.com", Condition: "=="},
+		},
+	}})
+	s.Nil(err)
+	s.Len(users, 1)
+	s.Equal("admin", users[0].Roles[0].Value)
+
+	email := "none@example.com"
+	_, err = repo.GetByEmail(s.ctx, &email)
+	s.NotNil(err)
+}
+
+func (s *SQLRepoTestSuite) TestTransactionCategory_CRUD() {
+	tracer := &observability.Tracer{Trace: noop.NewTracerProvider().Tracer("test")}
+	repo, mErr := repository.NewTransactionCategoryRepo(tracer, s.database)
+	s.Require().Nil(mErr)
+
+	walletID := uuid.New().String()
+	category := &entity.TransactionCategory{ID: uuid.New().String(), Name: "market", Default: "false", WalletID: walletID}
+	created, mErr := repo.Create(s.ctx, category)
+	s.Nil(mErr)
+	s.Equal(*category, *created)
+
+	category.Name = "food"
+	updated, mErr := repo.Update(s.ctx, category)
+	s.Nil(mErr)
+	s.Equal("food", updated.Name)
+
+	categories, mErr := repo.Get(s.ctx, entity.AndClause([]entity.QueryDB{{Key: "wallet_id", Value: walletID, Condition: "=="}}), entity.DefaultPage())
+	s.Nil(mErr)
+	s.Len(categories.Items, 1)
+
+	found, mErr := repo.GetByFilterOne(s.ctx, []entity.QueryDB{{Key: "default", Value: "false", Condition: "=="}})
+	s.Nil(mErr)
+	s.Equal(category.ID, found.ID)
+
+	s.Nil(repo.Delete(s.ctx, &category.ID))
+	found, mErr = repo.GetById(s.ctx, &category.ID)
+	s.Nil(mErr)
+	s.Empty(found.ID)
+}
+
+func (s *SQLRepoTestSuite) TestAuthorization_Revoke() {
+	repo, err := repository.NewAuthorizationRepo(s.database, logger.NewLoggerConfig(nil))
+	s.Require().Nil(err)
+
+	claims := &entity.AuthorizationClaims{Email: "a@example.com"}
+	claims.Id = uuid.New().String()
+	claims.ExpiresAt = time.Now().Add(time.Hour).Unix()
+
+	id, err := repo.GenerateTokenJWT(s.ctx, claims, &entity.AccountUser{ID: s.userID, User: entity.User{Email: claims.Email}})
+	s.Require().Nil(err)
+
+	revoked, err := repo.IsRevokedTokenJWT(s.ctx, id)
+	s.Nil(err)
+	s.False(revoked)
+
+	s.Nil(repo.RevokeTokenJWT(s.ctx, id))
+	revoked, err = repo.IsRevokedTokenJWT(s.ctx, id)
+	s.Nil(err)
+	s.True(revoked)
+
+	missing := uuid.New().String()
+	s.NotNil(repo.RevokeTokenJWT(s.ctx, &missing))
+}
+
+func TestRunSQLRepoTestSuite(t *testing.T) {
+	suite.Run(t, new(SQLRepoTestSuite))
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/pkg/db"
+)
+
+const subscriptionsCollection = "subscriptions"
+
+type ISubscriptionRepo interface {
+	// SaveSubscription creates or replaces the subscription of the tenant
+	SaveSubscription(ctx context.Context, data *entity.Subscription) *entity.ModuleError
+	// GetSubscription returns nil when the tenant has no subscription
+	GetSubscription(ctx context.Context, tenantId string) (*entity.Subscription, *entity.ModuleError)
+	// GetSubscriptionsDue returns the subscriptions that are not canceled and change before the time
+	GetSubscriptionsDue(ctx context.Context, before time.Time) ([]entity.Subscription, *entity.ModuleError)
+}
+
+type SubscriptionRepo struct {
+	db db.DocumentStore
+}
+
+// NewSubscriptionRepo creates the repository of the subscriptions of the database kind
+func NewSubscriptionRepo(database db.Database) (ISubscriptionRepo, error) {
+	switch conn := database.(type) {
+	case *db.SQLDatabase:
+		return NewSubscriptionSQLRepo(conn)
+	case db.DocumentStore:
+		return &SubscriptionRepo{db: conn}, nil
+	}
+
+	return nil, errors.New("database is required")
+}
+
+func (s *SubscriptionRepo) SaveSubscription(ctx context.Context, data *entity.Subscription) *entity.ModuleError {
+	if err := s.db.Collection(subscriptionsCollection).Doc(data.TenantID).Set(ctx, *data); err != nil {
+		return entity.Error(err.Error(), "subscription", "SaveSubscription", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+	return nil
+}
+
+func (s *SubscriptionRepo) GetSubscription(ctx context.Context, tenantId string) (*entity.Subscription, *entity.ModuleError) {
+	doc, err := s.db.Collection(subscriptionsCollection).Doc(tenantId).Get(ctx)
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, entity.Error(err.Error(), "subscription", "GetSubscription", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+
+	var subscription entity.Subscription
+	if err := doc.DataTo(&subscription); err != nil {
+		return nil, entity.Error(err.Error(), "subscription", "GetSubscription", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+	return &subscription, nil
+}
+
+func (s *SubscriptionRepo) GetSubscriptionsDue(ctx context.Context, before time.Time) ([]entity.Subscription, *entity.ModuleError) {
+	docs, err := s.db.Collection(subscriptionsCollection).
+		Where("status", db.OpIn, entity.SubscriptionOpen).
+		Where("due_at", db.OpLessEqual, before).
+		Documents(ctx)
+	if err != nil {
+		return nil, entity.Error(err.Error(), "subscription", "GetSubscriptionsDue", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+
+	subscriptions := make([]entity.Subscription, 0, len(docs))
+	for _, doc := range docs {
+		var subscription entity.Subscription
+		if err := doc.DataTo(&subscription); err != nil {
+			return nil, entity.Error(err.Error(), "subscription", "GetSubscriptionsDue", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+	return subscriptions, nil
+}
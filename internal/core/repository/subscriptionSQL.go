@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/pkg/db"
+)
+
+const subscriptionColumns = `tenant_id, plan_id, status, trial_end, period_start, period_end, scheduled_plan_id, cancel_at_period_end, past_due_at, due_at, create_at, update_at`
+
+type SubscriptionSQLRepo struct {
+	db *db.SQLDatabase
+}
+
+// NewSubscriptionSQLRepo creates the repository of the subscriptions of a relational database
+func NewSubscriptionSQLRepo(database *db.SQLDatabase) (ISubscriptionRepo, error) {
+	if database == nil {
+		return nil, errors.New("database is required")
+	}
+
+	return &SubscriptionSQLRepo{db: database}, nil
+}
+
+func (s *SubscriptionSQLRepo) SaveSubscription(ctx context.Context, data *entity.Subscription) *entity.ModuleError {
+	_, err := s.db.DB.ExecContext(ctx, s.db.Rebind(`INSERT INTO subscriptions (`+subscriptionColumns+`)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (tenant_id) DO UPDATE SET
+    plan_id = excluded.plan_id,
+    status = excluded.status,
+    trial_end = excluded.trial_end,
+    period_start = excluded.period_start,
+    period_end = excluded.period_end,
+    scheduled_plan_id = excluded.scheduled_plan_id,
+    cancel_at_period_end = excluded.cancel_at_period_end,
+    past_due_at = excluded.past_due_at,
+    due_at = excluded.due_at,
+    update_at = excluded.update_at`),
+		data.TenantID, data.PlanID, data.Status, data.TrialEnd, data.PeriodStart, data.PeriodEnd, data.ScheduledPlanID,
+		data.CancelAtPeriodEnd, data.PastDueAt, data.DueAt, data.CreatedAt, data.UpdatedAt)
+	if err != nil {
+		return entity.Error(err.Error(), "subscription", "SaveSubscription", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+	return nil
+}
+
+func (s *SubscriptionSQLRepo) GetSubscription(ctx context.Context, tenantId string) (*entity.Subscription, *entity.ModuleError) {
+	subscriptions, err := s.query(ctx, `WHERE tenant_id = ?`, tenantId)
+	if err != nil {
+		return nil, entity.Error(err.Error(), "subscription", "GetSubscription", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+	if len(subscriptions) == 0 {
+		return nil, nil
+	}
+	return &subscriptions[0], nil
+}
+
+func (s *SubscriptionSQLRepo) GetSubscriptionsDue(ctx context.Context, before time.Time) ([]entity.Subscription, *entity.ModuleError) {
+	subscriptions, err := s.query(ctx, `WHERE status IN (?, ?, ?) AND due_at <= ? ORDER BY due_at`,
+		entity.SubscriptionTrialing, entity.SubscriptionActive, entity.SubscriptionPastDue, before)
+	if err != nil {
+		return nil, entity.Error(err.Error(), "subscription", "GetSubscriptionsDue", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+	return subscriptions, nil
+}
+
+func (s *SubscriptionSQLRepo) query(ctx context.Context, where string, args ...any) ([]entity.Subscription, error) {
+	rows, err := s.db.DB.QueryContext(ctx, s.db.Rebind(`SELECT `+subscriptionColumns+` FROM subscriptions `+where), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subscriptions := []entity.Subscription{}
+	for rows.Next() {
+		var i entity.Subscription
+		if err := rows.Scan(&i.TenantID, &i.PlanID, &i.Status, &i.TrialEnd, &i.PeriodStart, &i.PeriodEnd, &i.ScheduledPlanID,
+			&i.CancelAtPeriodEnd, &i.PastDueAt, &i.DueAt, &i.CreatedAt, &i.UpdatedAt); err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, i)
+	}
+	return subscriptions, rows.Err()
+}
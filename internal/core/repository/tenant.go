@@ -3,10 +3,10 @@ package repository
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/Tomelin/financial-management-backend/internal/core/entity"
 	"github.com/Tomelin/financial-management-backend/pkg/db"
-	"google.golang.org/api/iterator"
 )
 
 type ITenantRepo interface {
@@ -16,20 +16,24 @@ type ITenantRepo interface {
 }
 
 type TenantRepo struct {
-	db db.FirebaseDatabaseInterface
+	db db.DocumentStore
 }
 
-func NewTenantRepository(db db.FirebaseDatabaseInterface) (ITenantRepo, error) {
-	if db == nil {
-		return nil, errors.New("db is required")
+// NewTenantRepository creates the tenant repository of the database kind
+func NewTenantRepository(database db.Database) (ITenantRepo, error) {
+	switch conn := database.(type) {
+	case *db.SQLDatabase:
+		return NewTenantSQLRepository(conn)
+	case db.DocumentStore:
+		return &TenantRepo{db: conn}, nil
 	}
-	return &TenantRepo{db: db}, nil
+	return nil, errors.New("db is required")
 }
 
 func (u *TenantRepo) Create(tenant *entity.TenantResponse) (*entity.TenantResponse, error) {
 	ctx := context.Background()
 
-	_, err := u.db.Collection("tenants").Doc(tenant.ID).Set(ctx, tenant)
+	err := u.db.Collection("tenants").Doc(tenant.ID).Set(ctx, tenant)
 	if err != nil {
 		return nil, err
 	}
@@ -48,32 +52,13 @@ func (u *TenantRepo) Create(tenant *entity.TenantResponse) (*entity.TenantRespon
 	return &TenantResponse, nil
 }
 
-func (u *TenantRepo) Get() ([]entity.TenantResponse, error) {
-	iter := u.db.Documents(context.Background(), "tenants")
-
-	defer iter.Stop()
-
-	var documents []entity.TenantResponse
-	for {
+func (u *TenantRepo) Get(page *entity.PageRequest) (*entity.Page[entity.TenantResponse], error) {
+	return documentPage(context.Background(), u.db.Collection("tenants"), page, func(doc *db.Document) (entity.TenantResponse, error) {
 		var tenant entity.TenantResponse
-		doc, err := iter.Next()
-		if err == iterator.Done {
-			break
-		}
-		if err != nil {
-			return nil, err
-		}
-
-		err = doc.DataTo(&tenant)
-		if err != nil {
-			return nil, err
-		}
-		tenant.ID = doc.Ref.ID
-
-		documents = append(documents, tenant)
-	}
-
-	return documents, nil
+		err := doc.DataTo(&tenant)
+		tenant.ID = doc.ID
+		return tenant, err
+	})
 }
 
 func (u *TenantRepo) GetById(id *string) (*entity.TenantResponse, error) {
@@ -92,7 +77,7 @@ func (u *TenantRepo) GetById(id *string) (*entity.TenantResponse, error) {
 }
 
 func (u *TenantRepo) Update(data *entity.TenantResponse) (*entity.TenantResponse, error) {
-	_, err := u.db.Collection("tenants").Doc(data.ID).Set(context.Background(), data)
+	err := u.db.Collection("tenants").Doc(data.ID).Set(context.Background(), data)
 	if err != nil {
 		return nil, err
 	}
@@ -112,7 +97,7 @@ func (u *TenantRepo) Update(data *entity.TenantResponse) (*entity.TenantResponse
 }
 
 func (u *TenantRepo) Delete(id *string) error {
-	_, err := u.db.Collection("tenants").Doc(*id).Delete(context.Background())
+	err := u.db.Collection("tenants").Doc(*id).Delete(context.Background())
 	if err != nil {
 		return err
 	}
@@ -120,29 +105,18 @@ func (u *TenantRepo) Delete(id *string) error {
 	return nil
 }
 
-func (u *TenantRepo) GetByFilterMany(ctx context.Context, filter []entity.QueryDB) ([]entity.TenantResponse, error) {
+func (u *TenantRepo) GetByFilterMany(ctx context.Context, filter []entity.QueryDBClause) ([]entity.TenantResponse, error) {
 	// ctx, span := u.trace.Trace.Start(ctx, "TenantRepo.GetByFilterMany")
 	// defer span.End()
-	query := u.db.Collection("tenants").Query
-	for _, f := range filter {
-		condition := checkFirebaseCondition(&f.Condition)
-		if f.Key != "" && f.Value != "" && condition != "" {
-			query = query.Where(f.Key, condition, f.Value)
-		}
+	query := applyClauses(u.db.Collection("tenants"), filter)
+
+	docs, err := query.Documents(context.Background())
+	if err != nil {
+		return nil, errors.New(err.Error())
 	}
 
-	iter := query.Documents(context.Background())
-	defer iter.Stop()
 	var tenants []entity.TenantResponse
-	for {
-		doc, err := iter.Next()
-		if err == iterator.Done {
-			break
-		}
-		if err != nil {
-			return nil, errors.New(err.Error())
-		}
-
+	for _, doc := range docs {
 		var tenant entity.TenantResponse
 		err = doc.DataTo(&tenant)
 		if err != nil {
@@ -155,30 +129,19 @@ func (u *TenantRepo) GetByFilterMany(ctx context.Context, filter []entity.QueryD
 
 func (u *TenantRepo) GetByFilterOne(ctx context.Context, filter []entity.QueryDB) (*entity.TenantResponse, error) {
 
-	query := u.db.Collection("tenants").Query
-	for _, f := range filter {
-		condition := checkFirebaseCondition(&f.Condition)
-		if f.Key != "" && f.Value != "" && condition != "" {
-			query = query.Where(f.Key, condition, f.Value)
-		}
-	}
+	query := applyFilter(u.db.Collection("tenants"), filter)
 
-	doc := query.Limit(1).Documents(context.Background())
-
-	result, err := doc.Next()
+	docs, err := query.Limit(1).Documents(context.Background())
 	if err != nil {
-		if err == iterator.Done {
-			return nil, nil
-		}
 		return nil, errors.New(err.Error())
 	}
 
-	if result == nil {
+	if len(docs) == 0 {
 		return nil, nil
 	}
 
 	var tenant entity.TenantResponse
-	err = result.DataTo(&tenant)
+	err = docs[0].DataTo(&tenant)
 	if err != nil {
 		return nil, errors.New(err.Error())
 	}
@@ -187,7 +150,17 @@ func (u *TenantRepo) GetByFilterOne(ctx context.Context, filter []entity.QueryDB
 }
 
 func (u *TenantRepo) GetPlan(id *string) (*entity.PlanResponse, error) {
-	return nil, nil
+	tenant, err := u.GetById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tenant.Plan, nil
 }
 
-func (u *TenantRepo) SetPlan(id *string, plan *entity.PlanResponse) error { return nil }
+// SetPlan replaces the plan of the tenant, an empty plan falls back to the default entitlements
+func (u *TenantRepo) SetPlan(id *string, plan *entity.PlanResponse) error {
+	return u.db.Collection("tenants").Doc(*id).Update(context.Background(),
+		db.Update{Path: "plan", Value: *plan},
+		db.Update{Path: "update_at", Value: time.Now()})
+}
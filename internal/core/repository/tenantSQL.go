@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/pkg/db"
+)
+
+var tenantTable = sqlTable{
+	name: "tenants",
+	columns: map[string]sqlKind{
+		"id":        sqlText,
+		"name":      sqlText,
+		"alias":     sqlText,
+		"owner_id":  sqlText,
+		"users":     sqlArray,
+		"wallets":   sqlArray,
+		"create_at": sqlTime,
+		"update_at": sqlTime,
+
+		"require_two_factor": sqlBoolean,
+		"suspended":          sqlBoolean,
+	},
+}
+
+const tenantColumns = `id, name, alias, owner_id, users, plan, wallets, create_at, update_at, require_two_factor, suspended, suspended_reason, suspended_at`
+
+type TenantSQLRepo struct {
+	db *db.SQLDatabase
+}
+
+// NewTenantSQLRepository creates the tenant repository of a relational database
+func NewTenantSQLRepository(database *db.SQLDatabase) (ITenantRepo, error) {
+	if database == nil {
+		return nil, errors.New("db is required")
+	}
+	return &TenantSQLRepo{db: database}, nil
+}
+
+func (u *TenantSQLRepo) Create(tenant *entity.TenantResponse) (*entity.TenantResponse, error) {
+	if err := u.save(context.Background(), tenant); err != nil {
+		return nil, err
+	}
+	return u.GetById(&tenant.ID)
+}
+
+func (u *TenantSQLRepo) Get(page *entity.PageRequest) (*entity.Page[entity.TenantResponse], error) {
+	return sqlPage(context.Background(), u.db, tenantTable, page, "", nil, u.query)
+}
+
+func (u *TenantSQLRepo) GetById(id *string) (*entity.TenantResponse, error) {
+	tenants, err := u.query(context.Background(), `id = ?`, "", 0, *id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tenants) == 0 {
+		return nil, fmt.Errorf("%w: tenants/%s", db.ErrNotFound, *id)
+	}
+
+	return &tenants[0], nil
+}
+
+func (u *TenantSQLRepo) Update(data *entity.TenantResponse) (*entity.TenantResponse, error) {
+	if err := u.save(context.Background(), data); err != nil {
+		return nil, err
+	}
+	return u.GetById(&data.ID)
+}
+
+func (u *TenantSQLRepo) Delete(id *string) error {
+	_, err := u.db.DB.ExecContext(context.Background(), u.db.Rebind(`DELETE FROM tenants WHERE id = ?`), *id)
+	return err
+}
+
+func (u *TenantSQLRepo) GetByFilterMany(ctx context.Context, filter []entity.QueryDBClause) ([]entity.TenantResponse, error) {
+	where, args, err := tenantTable.whereClauses(u.db, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.query(ctx, where, "", 0, args...)
+}
+
+func (u *TenantSQLRepo) GetByFilterOne(ctx context.Context, filter []entity.QueryDB) (*entity.TenantResponse, error) {
+	where, args, err := tenantTable.where(u.db, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	tenants, err := u.query(ctx, where, "", 1, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tenants) == 0 {
+		return nil, nil
+	}
+
+	return &tenants[0], nil
+}
+
+func (u *TenantSQLRepo) GetPlan(id *string) (*entity.PlanResponse, error) {
+	tenant, err := u.GetById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tenant.Plan, nil
+}
+
+// SetPlan replaces the plan of the tenant, an empty plan falls back to the default entitlements
+func (u *TenantSQLRepo) SetPlan(id *string, plan *entity.PlanResponse) error {
+	value, err := jsonValue(*plan)
+	if err != nil {
+		return err
+	}
+
+	result, err := u.db.DB.ExecContext(context.Background(), u.db.Rebind(`UPDATE tenants SET plan = ?, update_at = ? WHERE id = ?`), value, time.Now(), *id)
+	if err != nil {
+		return err
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("%w: tenants/%s", db.ErrNotFound, *id)
+	}
+	return nil
+}
+
+func (u *TenantSQLRepo) save(ctx context.Context, tenant *entity.TenantResponse) error {
+	users, err := jsonValue(tenant.Users)
+	if err != nil {
+		return err
+	}
+
+	plan, err := jsonValue(tenant.Plan)
+	if err != nil {
+		return err
+	}
+
+	wallets, err := jsonValue(tenant.Wallets)
+	if err != nil {
+		return err
+	}
+
+	_, err = u.db.DB.ExecContext(ctx, u.db.Rebind(`INSERT INTO tenants (`+tenantColumns+`)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (id) DO UPDATE SET
+    name = excluded.name,
+    alias = excluded.alias,
+    owner_id = excluded.owner_id,
+    users = excluded.users,
+    plan = excluded.plan,
+    wallets = excluded.wallets,
+    create_at = excluded.create_at,
+    update_at = excluded.update_at,
+    require_two_factor = excluded.require_two_factor,
+    suspended = excluded.suspended,
+    suspended_reason = excluded.suspended_reason,
+    suspended_at = excluded.suspended_at`),
+		tenant.ID, tenant.Name, tenant.Alias, tenant.OwnerID, users, plan, wallets, tenant.CreatedAt, tenant.UpdatedAt, tenant.RequireTwoFactor,
+		tenant.Suspended, tenant.SuspendedReason, tenant.SuspendedAt)
+	return err
+}
+
+func (u *TenantSQLRepo) query(ctx context.Context, where, order string, limit int, args ...any) ([]entity.TenantResponse, error) {
+	rows, err := u.db.DB.QueryContext(ctx, u.db.Rebind(tenantTable.selectQuery(tenantColumns, where, order, limit)), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tenants []entity.TenantResponse
+	for rows.Next() {
+		var tenant entity.TenantResponse
+		err := rows.Scan(&tenant.ID, &tenant.Name, &tenant.Alias, &tenant.OwnerID, sqlJSON{&tenant.Users},
+			sqlJSON{&tenant.Plan}, sqlJSON{&tenant.Wallets}, &tenant.CreatedAt, &tenant.UpdatedAt, &tenant.RequireTwoFactor,
+			&tenant.Suspended, &tenant.SuspendedReason, &tenant.SuspendedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		if tenant.Plan.ID != "" {
+			if err := tenant.Plan.AfterDocumentLoad(); err != nil {
+				return nil, err
+			}
+		}
+		tenants = append(tenants, tenant)
+	}
+	return tenants, rows.Err()
+}
@@ -0,0 +1,218 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/pkg/db"
+)
+
+var (
+	// errTransactionNotFound is returned when the transaction of an update or a delete is not in the wallet
+	errTransactionNotFound = errors.New("transaction not found")
+	// errTransactionExists is returned when the id of a created transaction is already stored
+	errTransactionExists = errors.New("transaction already exists")
+)
+
+type TransactionRepo struct {
+	db db.DocumentStore
+}
+
+// NewTransactionRepo creates the wallet transaction repository of the database kind
+func NewTransactionRepo(database db.Database) (entity.ITransaction, error) {
+	switch conn := database.(type) {
+	case *db.SQLDatabase:
+		return NewTransactionSQLRepo(conn)
+	case db.DocumentStore:
+		return &TransactionRepo{db: conn}, nil
+	}
+
+	return nil, errors.New("database is required")
+}
+
+// Create writes the transaction and applies its signed amount to the balance of the wallet, in one database transaction
+// It only inserts, a stored transaction of the id is not replaced
+func (t *TransactionRepo) Create(ctx context.Context, userId *string, walletId *string, data *entity.WalletTransaction) (*entity.WalletTransaction, *entity.ModuleError) {
+
+	data.WalletID = *walletId
+	data.CreatedBy = *userId
+
+	if err := t.write(ctx, *walletId, data.ID, data, false); err != nil {
+		return nil, transactionWriteError(err, "Create")
+	}
+
+	return t.GetByID(ctx, userId, walletId, &data.ID)
+}
+
+func (t *TransactionRepo) Get(ctx context.Context, userId *string, walletId *string, page *entity.PageRequest) (*entity.Page[entity.WalletTransaction], *entity.ModuleError) {
+	query := t.db.Collection("wallet_transactions").Where("wallet_id", "==", *walletId)
+
+	transactions, err := documentPage(ctx, query, page, func(doc *db.Document) (entity.WalletTransaction, error) {
+		var transaction entity.WalletTransaction
+		err := doc.DataTo(&transaction)
+		return transaction, err
+	})
+	if err != nil {
+		return nil, entity.Error(err.Error(), "transaction", "Get", entity.ApplicationLayerRepository, pageErrorCode(err))
+	}
+	return transactions, nil
+}
+
+func (t *TransactionRepo) GetByID(ctx context.Context, userId *string, walletId *string, id *string) (*entity.WalletTransaction, *entity.ModuleError) {
+	docs, err := t.db.Collection("wallet_transactions").Where("id", "==", *id).Where("wallet_id", "==", *walletId).Limit(1).Documents(ctx)
+	if err != nil {
+		return nil, entity.Error(err.Error(), "transaction", "GetByID", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+
+	if len(docs) == 0 {
+		return nil, nil
+	}
+
+	var transaction entity.WalletTransaction
+	err = docs[0].DataTo(&transaction)
+	if err != nil {
+		return nil, entity.Error(err.Error(), "transaction", "GetByID", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+
+	return &transaction, nil
+}
+
+// Update writes the transaction and applies the difference of the signed amounts to the balance of the wallet
+// The stored transaction is read in the same database transaction, so concurrent updates do not apply a stale difference
+func (t *TransactionRepo) Update(ctx context.Context, userId *string, walletId *string, data *entity.WalletTransaction) (*entity.WalletTransaction, *entity.ModuleError) {
+
+	if err := t.write(ctx, *walletId, data.ID, data, true); err != nil {
+		return nil, transactionWriteError(err, "Update")
+	}
+
+	return t.GetByID(ctx, userId, walletId, &data.ID)
+}
+
+// Delete removes the transaction and reverts its signed amount from the balance of the wallet, in one database transaction
+func (t *TransactionRepo) Delete(ctx context.Context, userId *string, walletId *string, id *string) *entity.ModuleError {
+
+	if err := t.write(ctx, *walletId, *id, nil, true); err != nil {
+		return transactionWriteError(err, "Delete")
+	}
+
+	return nil
+}
+
+// write sets the transaction of the id, or deletes it when data is nil, and applies the change to the balance of the wallet
+// The stored transaction is read when stored is set, it must belong to the wallet, otherwise the id must not be stored
+func (t *TransactionRepo) write(ctx context.Context, walletId, id string, data *entity.WalletTransaction, stored bool) error {
+
+	walletRef := t.db.Collection("wallets").Doc(walletId)
+	transactionRef := t.db.Collection("wallet_transactions").Doc(id)
+
+	return t.db.RunTransaction(ctx, func(ctx context.Context, tx db.Transaction) error {
+		doc, err := tx.Get(walletRef)
+		if errors.Is(err, db.ErrNotFound) {
+			return errWalletNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		var wallet entity.WalletResponse
+		if err := doc.DataTo(&wallet); err != nil {
+			return err
+		}
+
+		// the transaction is read in a create too, so a concurrent create of the id conflicts
+		doc, err = tx.Get(transactionRef)
+		switch {
+		case err == nil && !stored:
+			return errTransactionExists
+		case errors.Is(err, db.ErrNotFound) && stored:
+			return errTransactionNotFound
+		case err != nil && !errors.Is(err, db.ErrNotFound):
+			return err
+		}
+
+		var current *entity.WalletTransaction
+		if stored {
+			current = &entity.WalletTransaction{}
+			if err := doc.DataTo(current); err != nil {
+				return err
+			}
+			if current.WalletID != walletId {
+				return errTransactionNotFound
+			}
+		}
+
+		if data != nil && current != nil {
+			data.WalletID = current.WalletID
+			data.CreatedBy = current.CreatedBy
+			data.CreatedAt = current.CreatedAt
+		}
+
+		delta, err := balanceDelta(current, data)
+		if err != nil {
+			return err
+		}
+
+		if data == nil {
+			err = tx.Delete(transactionRef)
+		} else {
+			err = tx.Set(transactionRef, data)
+		}
+		if err != nil || delta.IsZero() {
+			return err
+		}
+
+		if err := wallet.ApplyBalance(delta); err != nil {
+			return err
+		}
+		wallet.SetUpdate()
+
+		return tx.Update(walletRef,
+			db.Update{Path: "balance", Value: wallet.Balance},
+			db.Update{Path: "updated_at", Value: wallet.UpdatedAt},
+		)
+	})
+}
+
+func (t *TransactionRepo) GetByFilterMany(ctx context.Context, userId *string, walletId *string, filter []entity.QueryDB) ([]entity.WalletTransaction, *entity.ModuleError) {
+	query := applyFilter(t.db.Collection("wallet_transactions").Where("wallet_id", "==", *walletId), filter)
+
+	docs, err := query.Documents(ctx)
+	if err != nil {
+		return nil, entity.Error(err.Error(), "transaction", "GetByFilterMany", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+
+	var transactions []entity.WalletTransaction
+	for _, doc := range docs {
+		var transaction entity.WalletTransaction
+		err = doc.DataTo(&transaction)
+		if err != nil {
+			return nil, entity.Error(err.Error(), "transaction", "GetByFilterMany", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+		}
+		transactions = append(transactions, transaction)
+	}
+	return transactions, nil
+}
+
+// balanceDelta is the change of the wallet balance when the current transaction is replaced by data
+// current is nil for a created transaction and data is nil for a deleted one
+func balanceDelta(current, data *entity.WalletTransaction) (entity.Money, error) {
+	switch {
+	case current == nil:
+		return data.SignedAmount(), nil
+	case data == nil:
+		return current.SignedAmount().Neg(), nil
+	}
+
+	return data.SignedAmount().Sub(current.SignedAmount())
+}
+
+func transactionWriteError(err error, method string) *entity.ModuleError {
+	if errors.Is(err, errWalletNotFound) || errors.Is(err, errTransactionNotFound) {
+		return entity.Error(err.Error(), "transaction", method, entity.ApplicationLayerRepository, entity.ResponseCodeNotFound)
+	}
+	if errors.Is(err, errTransactionExists) {
+		return entity.Error(err.Error(), "transaction", method, entity.ApplicationLayerRepository, entity.ResponseCodeConflict)
+	}
+	return entity.Error(err.Error(), "transaction", method, entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+}
@@ -6,31 +6,34 @@ import (
 	"github.com/Tomelin/financial-management-backend/internal/core/entity"
 	"github.com/Tomelin/financial-management-backend/pkg/db"
 	"github.com/Tomelin/financial-management-backend/pkg/observability"
-	"google.golang.org/api/iterator"
 )
 
 type TransactionCategoryRepo struct {
-	db    db.FirebaseDatabaseInterface
+	db    db.DocumentStore
 	trace *observability.Tracer
 }
 
-func NewTransactionCategoryRepo(trace *observability.Tracer, db db.FirebaseDatabaseInterface) (entity.ITransactionCategoryRepository, *entity.ModuleError) {
-
-	if db == nil {
-		return nil, entity.Error("database is required", "transactionCategory", "inicialization", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+// NewTransactionCategoryRepo creates the transaction category repository of the database kind
+func NewTransactionCategoryRepo(trace *observability.Tracer, database db.Database) (entity.ITransactionCategoryRepository, *entity.ModuleError) {
+
+	switch conn := database.(type) {
+	case *db.SQLDatabase:
+		return NewTransactionCategorySQLRepo(trace, conn)
+	case db.DocumentStore:
+		return &TransactionCategoryRepo{
+			db:    conn,
+			trace: trace,
+		}, nil
 	}
 
-	return &TransactionCategoryRepo{
-		db:    db,
-		trace: trace,
-	}, nil
+	return nil, entity.Error("database is required", "transactionCategory", "inicialization", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
 }
 
 func (c *TransactionCategoryRepo) Create(ctx context.Context, category *entity.TransactionCategory) (*entity.TransactionCategory, *entity.ModuleError) {
 	ctx, span := c.trace.Trace.Start(ctx, "TransactionCategoryRepo.Create")
 	defer span.End()
 
-	_, err := c.db.Collection("transaction_categories").Doc(category.ID).Set(ctx, category)
+	err := c.db.Collection("transaction_categories").Doc(category.ID).Set(ctx, category)
 	if err != nil {
 		return nil, entity.Error(err.Error(), "transactionCategory", "Create", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
 	}
@@ -49,21 +52,31 @@ func (c *TransactionCategoryRepo) Create(ctx context.Context, category *entity.T
 	return &cat, nil
 }
 
-func (c *TransactionCategoryRepo) Get(ctx context.Context, walletID *string) ([]entity.TransactionCategory, *entity.ModuleError) {
-	return nil, nil
+func (c *TransactionCategoryRepo) Get(ctx context.Context, filter []entity.QueryDBClause, page *entity.PageRequest) (*entity.Page[entity.TransactionCategory], *entity.ModuleError) {
+	ctx, span := c.trace.Trace.Start(ctx, "TransactionCategoryRepo.Get")
+	defer span.End()
+
+	query := applyClauses(c.db.Collection("transaction_categories"), filter)
+
+	categories, err := documentPage(ctx, query, page, func(doc *db.Document) (entity.TransactionCategory, error) {
+		var category entity.TransactionCategory
+		err := doc.DataTo(&category)
+		return category, err
+	})
+	if err != nil {
+		return nil, entity.Error(err.Error(), "transactionCategory", "Get", entity.ApplicationLayerRepository, pageErrorCode(err))
+	}
+	return categories, nil
 }
 
 func (c *TransactionCategoryRepo) GetById(ctx context.Context, id *string) (*entity.TransactionCategory, *entity.ModuleError) {
-	doc := c.db.Collection("transaction_categories").Where("id", "==", *id).Documents(context.Background())
-	defer doc.Stop()
-
-	data, _ := doc.Next()
-	if data == nil {
+	docs, _ := c.db.Collection("transaction_categories").Where("id", "==", *id).Limit(1).Documents(context.Background())
+	if len(docs) == 0 {
 		return &entity.TransactionCategory{}, nil
 	}
 
 	var category entity.TransactionCategory
-	err := data.DataTo(&category)
+	err := docs[0].DataTo(&category)
 	if err != nil {
 		return nil, entity.Error(err.Error(), "category", "GetById", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
 	}
@@ -83,73 +96,19 @@ func (c *TransactionCategoryRepo) GetByFilterMany(ctx context.Context, filter []
 	ctx, span := c.trace.Trace.Start(ctx, "TransactionCategoryRepo.GetByFilterMany")
 	defer span.End()
 
-	hasQuery := false
-	hasQueryOr := false
-	query := c.db.Collection("transaction_categories").Query
-	queryOr := c.db.Collection("transaction_categories").Query
-	for _, f := range filter {
-		if f.Clause == "" || f.Clause == entity.QueryClauseAnd {
-			hasQuery = true
-			for _, q := range f.Queries {
-				condition := checkFirebaseCondition(&q.Condition)
-				if q.Key != "" && q.Value != "" && condition != "" {
-					query = query.Where(q.Key, condition, q.Value)
-				}
-			}
-		}
-		if f.Clause == entity.QueryClauseOr {
-			hasQueryOr = true
-			for _, q := range f.Queries {
-				condition := checkFirebaseCondition(&q.Condition)
-				if q.Key != "" && q.Value != "" && condition != "" {
-					queryOr = queryOr.Where(q.Key, condition, q.Value)
-				}
-			}
-		}
-
+	docs, err := applyClauses(c.db.Collection("transaction_categories"), filter).Documents(ctx)
+	if err != nil {
+		return nil, entity.Error(err.Error(), "transactionCategory", "GetByFilterMany", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
 	}
 
 	var categories []entity.TransactionCategory
-	if hasQuery {
-		iter := query.Documents(ctx)
-		defer iter.Stop()
-		for {
-			doc, err := iter.Next()
-			if err == iterator.Done {
-				break
-			}
-			if err != nil {
-				return nil, entity.Error(err.Error(), "transactionCategory", "GetByFilterMany", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
-			}
-
-			var category entity.TransactionCategory
-			err = doc.DataTo(&category)
-			if err != nil {
-				return nil, entity.Error(err.Error(), "transactionCategory", "GetByFilterMany", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
-			}
-			categories = append(categories, category)
-		}
-	}
-
-	if hasQueryOr {
-		iter := queryOr.Documents(ctx)
-		defer iter.Stop()
-		for {
-			doc, err := iter.Next()
-			if err == iterator.Done {
-				break
-			}
-			if err != nil {
-				return nil, entity.Error(err.Error(), "transactionCategory", "GetByFilterMany", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
-			}
-
-			var category entity.TransactionCategory
-			err = doc.DataTo(&category)
-			if err != nil {
-				return nil, entity.Error(err.Error(), "transactionCategory", "GetByFilterMany", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
-			}
-			categories = append(categories, category)
+	for _, doc := range docs {
+		var category entity.TransactionCategory
+		err = doc.DataTo(&category)
+		if err != nil {
+			return nil, entity.Error(err.Error(), "transactionCategory", "GetByFilterMany", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
 		}
+		categories = append(categories, category)
 	}
 
 	return categories, nil
@@ -159,30 +118,19 @@ func (c *TransactionCategoryRepo) GetByFilterOne(ctx context.Context, filter []e
 	ctx, span := c.trace.Trace.Start(ctx, "TransactionCategoryRepo.GetByFilterOne")
 	defer span.End()
 
-	query := c.db.Collection("transaction_categories").Query
+	query := applyFilter(c.db.Collection("transaction_categories"), filter)
 
-	for _, f := range filter {
-		condition := checkFirebaseCondition(&f.Condition)
-		if f.Key != "" && f.Value != "" && condition != "" {
-			query = query.Where(f.Key, condition, f.Value)
-		}
-	}
-
-	doc := query.Limit(1).Documents(ctx)
-	result, err := doc.Next()
+	docs, err := query.Limit(1).Documents(ctx)
 	if err != nil {
-		if err == iterator.Done {
-			return nil, nil
-		}
 		return nil, entity.Error(err.Error(), "transactionCategory", "GetByFilterOne", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
 	}
 
-	if result == nil {
+	if len(docs) == 0 {
 		return nil, nil
 	}
 
 	var tenant entity.TransactionCategory
-	err = result.DataTo(&tenant)
+	err = docs[0].DataTo(&tenant)
 	if err != nil {
 		return nil, entity.Error(err.Error(), "transactionCategory", "GetByFilterOne", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
 	}
@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/pkg/db"
+	"github.com/Tomelin/financial-management-backend/pkg/observability"
+)
+
+var transactionCategoryTable = sqlTable{
+	name: "transaction_categories",
+	columns: map[string]sqlKind{
+		"id":        sqlText,
+		"name":      sqlText,
+		"default":   sqlText,
+		"tenant_id": sqlText,
+		"wallet_id": sqlText,
+	},
+}
+
+const transactionCategoryColumns = `id, name, "default", tenant_id, wallet_id`
+
+type TransactionCategorySQLRepo struct {
+	db    *db.SQLDatabase
+	trace *observability.Tracer
+}
+
+// NewTransactionCategorySQLRepo creates the transaction category repository of a relational database
+func NewTransactionCategorySQLRepo(trace *observability.Tracer, database *db.SQLDatabase) (entity.ITransactionCategoryRepository, *entity.ModuleError) {
+
+	if database == nil {
+		return nil, entity.Error("database is required", "transactionCategory", "inicialization", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+
+	return &TransactionCategorySQLRepo{
+		db:    database,
+		trace: trace,
+	}, nil
+}
+
+func (c *TransactionCategorySQLRepo) Create(ctx context.Context, category *entity.TransactionCategory) (*entity.TransactionCategory, *entity.ModuleError) {
+	ctx, span := c.trace.Trace.Start(ctx, "TransactionCategorySQLRepo.Create")
+	defer span.End()
+
+	if err := c.save(ctx, category); err != nil {
+		return nil, entity.Error(err.Error(), "transactionCategory", "Create", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+
+	return c.GetById(ctx, &category.ID)
+}
+
+func (c *TransactionCategorySQLRepo) Get(ctx context.Context, filter []entity.QueryDBClause, page *entity.PageRequest) (*entity.Page[entity.TransactionCategory], *entity.ModuleError) {
+	where, args, err := transactionCategoryTable.whereClauses(c.db, filter)
+	if err != nil {
+		return nil, entity.Error(err.Error(), "transactionCategory", "Get", entity.ApplicationLayerRepository, sqlErrorCode(err))
+	}
+
+	categories, err := sqlPage(ctx, c.db, transactionCategoryTable, page, where, args, c.query)
+	if err != nil {
+		return nil, entity.Error(err.Error(), "transactionCategory", "Get", entity.ApplicationLayerRepository, pageErrorCode(err))
+	}
+	return categories, nil
+}
+
+func (c *TransactionCategorySQLRepo) GetById(ctx context.Context, id *string) (*entity.TransactionCategory, *entity.ModuleError) {
+	categories, err := c.query(ctx, `id = ?`, "", 1, *id)
+	if err != nil {
+		return nil, entity.Error(err.Error(), "category", "GetById", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+
+	if len(categories) == 0 {
+		return &entity.TransactionCategory{}, nil
+	}
+
+	return &categories[0], nil
+}
+
+func (c *TransactionCategorySQLRepo) Update(ctx context.Context, category *entity.TransactionCategory) (*entity.TransactionCategory, *entity.ModuleError) {
+	if err := c.save(ctx, category); err != nil {
+		return nil, entity.Error(err.Error(), "transactionCategory", "Update", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+
+	return c.GetById(ctx, &category.ID)
+}
+
+func (c *TransactionCategorySQLRepo) Delete(ctx context.Context, id *string) *entity.ModuleError {
+	_, err := c.db.DB.ExecContext(ctx, c.db.Rebind(`DELETE FROM transaction_categories WHERE id = ?`), *id)
+	if err != nil {
+		return entity.Error(err.Error(), "transactionCategory", "Delete", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+	return nil
+}
+
+func (c *TransactionCategorySQLRepo) GetByFilterMany(ctx context.Context, filter []entity.QueryDBClause) ([]entity.TransactionCategory, *entity.ModuleError) {
+	ctx, span := c.trace.Trace.Start(ctx, "TransactionCategorySQLRepo.GetByFilterMany")
+	defer span.End()
+
+	where, args, err := transactionCategoryTable.whereClauses(c.db, filter)
+	if err != nil {
+		return nil, entity.Error(err.Error(), "transactionCategory", "GetByFilterMany", entity.ApplicationLayerRepository, sqlErrorCode(err))
+	}
+
+	categories, err := c.query(ctx, where, "", 0, args...)
+	if err != nil {
+		return nil, entity.Error(err.Error(), "transactionCategory", "GetByFilterMany", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+
+	return categories, nil
+}
+
+func (c *TransactionCategorySQLRepo) GetByFilterOne(ctx context.Context, filter []entity.QueryDB) (*entity.TransactionCategory, *entity.ModuleError) {
+	ctx, span := c.trace.Trace.Start(ctx, "TransactionCategorySQLRepo.GetByFilterOne")
+	defer span.End()
+
+	where, args, err := transactionCategoryTable.where(c.db, filter)
+	if err != nil {
+		return nil, entity.Error(err.Error(), "transactionCategory", "GetByFilterOne", entity.ApplicationLayerRepository, sqlErrorCode(err))
+	}
+
+	categories, err := c.query(ctx, where, "", 1, args...)
+	if err != nil {
+		return nil, entity.Error(err.Error(), "transactionCategory", "GetByFilterOne", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+
+	if len(categories) == 0 {
+		return nil, nil
+	}
+
+	return &categories[0], nil
+}
+
+func (c *TransactionCategorySQLRepo) save(ctx context.Context, category *entity.TransactionCategory) error {
+	_, err := c.db.DB.ExecContext(ctx, c.db.Rebind(`INSERT INTO transaction_categories (`+transactionCategoryColumns+`)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT (id) DO UPDATE SET
+    name = excluded.name,
+    "default" = excluded."default",
+    tenant_id = excluded.tenant_id,
+    wallet_id = excluded.wallet_id`),
+		category.ID, category.Name, category.Default, category.TenantID, category.WalletID)
+	return err
+}
+
+func (c *TransactionCategorySQLRepo) query(ctx context.Context, where, order string, limit int, args ...any) ([]entity.TransactionCategory, error) {
+	rows, err := c.db.DB.QueryContext(ctx, c.db.Rebind(transactionCategoryTable.selectQuery(transactionCategoryColumns, where, order, limit)), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var categories []entity.TransactionCategory
+	for rows.Next() {
+		var category entity.TransactionCategory
+		err := rows.Scan(&category.ID, &category.Name, &category.Default, &category.TenantID, &category.WalletID)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, category)
+	}
+	return categories, rows.Err()
+}
@@ -0,0 +1,227 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/pkg/db"
+)
+
+var transactionTable = sqlTable{
+	name: "wallet_transactions",
+	columns: map[string]sqlKind{
+		"id":          sqlText,
+		"wallet_id":   sqlText,
+		"tenant_id":   sqlText,
+		"category_id": sqlText,
+		"type":        sqlText,
+		"amount":      sqlDecimal,
+		"currency":    sqlText,
+		"description": sqlText,
+		"date":        sqlTime,
+		"created_by":  sqlText,
+		"created_at":  sqlTime,
+		"updated_at":  sqlTime,
+	},
+}
+
+const transactionColumns = `id, wallet_id, tenant_id, category_id, type, amount, currency, description, date, created_by, created_at, updated_at`
+
+type TransactionSQLRepo struct {
+	db *db.SQLDatabase
+}
+
+// NewTransactionSQLRepo creates the wallet transaction repository of a relational database
+func NewTransactionSQLRepo(database *db.SQLDatabase) (entity.ITransaction, error) {
+	if database == nil {
+		return nil, errors.New("database is required")
+	}
+
+	return &TransactionSQLRepo{db: database}, nil
+}
+
+// Create writes the transaction and applies its signed amount to the balance of the wallet, in one database transaction
+// It only inserts, a stored transaction of the id is a conflict
+func (t *TransactionSQLRepo) Create(ctx context.Context, userId *string, walletId *string, data *entity.WalletTransaction) (*entity.WalletTransaction, *entity.ModuleError) {
+
+	data.WalletID = *walletId
+	data.CreatedBy = *userId
+
+	if err := t.write(ctx, *walletId, data.ID, data, false); err != nil {
+		return nil, transactionWriteError(err, "Create")
+	}
+
+	return t.GetByID(ctx, userId, walletId, &data.ID)
+}
+
+func (t *TransactionSQLRepo) Get(ctx context.Context, userId *string, walletId *string, page *entity.PageRequest) (*entity.Page[entity.WalletTransaction], *entity.ModuleError) {
+	transactions, err := sqlPage(ctx, t.db, transactionTable, page, `wallet_id = ?`, []any{*walletId}, t.query)
+	if err != nil {
+		return nil, entity.Error(err.Error(), "transaction", "Get", entity.ApplicationLayerRepository, pageErrorCode(err))
+	}
+	return transactions, nil
+}
+
+func (t *TransactionSQLRepo) GetByID(ctx context.Context, userId *string, walletId *string, id *string) (*entity.WalletTransaction, *entity.ModuleError) {
+	transactions, err := t.query(ctx, `id = ? AND wallet_id = ?`, "", 1, *id, *walletId)
+	if err != nil {
+		return nil, entity.Error(err.Error(), "transaction", "GetByID", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+
+	if len(transactions) == 0 {
+		return nil, nil
+	}
+
+	return &transactions[0], nil
+}
+
+// Update writes the transaction and applies the difference of the signed amounts to the balance of the wallet
+// The stored transaction is read in the same database transaction, so concurrent updates do not apply a stale difference
+func (t *TransactionSQLRepo) Update(ctx context.Context, userId *string, walletId *string, data *entity.WalletTransaction) (*entity.WalletTransaction, *entity.ModuleError) {
+
+	if err := t.write(ctx, *walletId, data.ID, data, true); err != nil {
+		return nil, transactionWriteError(err, "Update")
+	}
+
+	return t.GetByID(ctx, userId, walletId, &data.ID)
+}
+
+// Delete removes the transaction and reverts its signed amount from the balance of the wallet, in one database transaction
+func (t *TransactionSQLRepo) Delete(ctx context.Context, userId *string, walletId *string, id *string) *entity.ModuleError {
+
+	if err := t.write(ctx, *walletId, *id, nil, true); err != nil {
+		return transactionWriteError(err, "Delete")
+	}
+
+	return nil
+}
+
+// write inserts or updates the transaction of the id, or deletes it when data is nil, and applies the change to the balance of the wallet
+// The row of the wallet is locked first, the stored transaction is read after it when stored is set
+func (t *TransactionSQLRepo) write(ctx context.Context, walletId, id string, data *entity.WalletTransaction, stored bool) error {
+
+	tx, err := t.db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	wallet, err := lockWallet(ctx, t.db, tx, walletId)
+	if err != nil {
+		return err
+	}
+
+	var current *entity.WalletTransaction
+	if stored {
+		transactions, err := scanTransactions(tx.QueryContext(ctx, t.db.Rebind(transactionTable.selectQuery(transactionColumns, `id = ? AND wallet_id = ?`, "", 1)), id, walletId))
+		if err != nil {
+			return err
+		}
+		if len(transactions) == 0 {
+			return errTransactionNotFound
+		}
+		current = &transactions[0]
+	}
+
+	if data != nil && current != nil {
+		data.WalletID = current.WalletID
+		data.CreatedBy = current.CreatedBy
+		data.CreatedAt = current.CreatedAt
+	}
+
+	delta, err := balanceDelta(current, data)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case data == nil:
+		_, err = tx.ExecContext(ctx, t.db.Rebind(`DELETE FROM wallet_transactions WHERE id = ? AND wallet_id = ?`), id, walletId)
+	case current == nil:
+		err = t.insert(ctx, tx, data)
+	default:
+		err = t.update(ctx, tx, data)
+	}
+	if err != nil {
+		return err
+	}
+
+	if !delta.IsZero() {
+		if err := applyWalletBalance(ctx, t.db, tx, wallet, delta); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (t *TransactionSQLRepo) GetByFilterMany(ctx context.Context, userId *string, walletId *string, filter []entity.QueryDB) ([]entity.WalletTransaction, *entity.ModuleError) {
+	where, args, err := transactionTable.where(t.db, filter)
+	if err != nil {
+		return nil, entity.Error(err.Error(), "transaction", "GetByFilterMany", entity.ApplicationLayerRepository, sqlErrorCode(err))
+	}
+
+	transactions, err := t.query(ctx, joinAnd(`wallet_id = ?`, where), "", 0, append([]any{*walletId}, args...)...)
+	if err != nil {
+		return nil, entity.Error(err.Error(), "transaction", "GetByFilterMany", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+	return transactions, nil
+}
+
+// insert adds the transaction, an id that is already stored is a conflict
+func (t *TransactionSQLRepo) insert(ctx context.Context, tx *sql.Tx, data *entity.WalletTransaction) error {
+	_, err := tx.ExecContext(ctx, t.db.Rebind(`INSERT INTO wallet_transactions (`+transactionColumns+`)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`),
+		data.ID, data.WalletID, data.TenantID, data.CategoryID, string(data.Type), data.Amount.Decimal(), data.Currency,
+		data.Description, data.Date, data.CreatedBy, data.CreatedAt, data.UpdatedAt)
+	if db.IsUniqueViolation(err) {
+		return errTransactionExists
+	}
+	return err
+}
+
+// update writes the editable fields of the transaction of the wallet, the wallet, the author and the creation are kept
+func (t *TransactionSQLRepo) update(ctx context.Context, tx *sql.Tx, data *entity.WalletTransaction) error {
+	result, err := tx.ExecContext(ctx, t.db.Rebind(`UPDATE wallet_transactions SET tenant_id = ?, category_id = ?, type = ?, amount = ?, currency = ?, description = ?, date = ?, updated_at = ?
+WHERE id = ? AND wallet_id = ?`),
+		data.TenantID, data.CategoryID, string(data.Type), data.Amount.Decimal(), data.Currency, data.Description, data.Date, data.UpdatedAt,
+		data.ID, data.WalletID)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return errTransactionNotFound
+	}
+	return nil
+}
+
+func (t *TransactionSQLRepo) query(ctx context.Context, where, order string, limit int, args ...any) ([]entity.WalletTransaction, error) {
+	return scanTransactions(t.db.DB.QueryContext(ctx, t.db.Rebind(transactionTable.selectQuery(transactionColumns, where, order, limit)), args...))
+}
+
+// scanTransactions reads the rows of a query of the transactions, of the database or of a database transaction
+func scanTransactions(rows *sql.Rows, err error) ([]entity.WalletTransaction, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transactions []entity.WalletTransaction
+	for rows.Next() {
+		var transaction entity.WalletTransaction
+		err := rows.Scan(&transaction.ID, &transaction.WalletID, &transaction.TenantID, &transaction.CategoryID,
+			&transaction.Type, sqlMoney{&transaction.Amount}, &transaction.Currency, &transaction.Description,
+			&transaction.Date, &transaction.CreatedBy, &transaction.CreatedAt, &transaction.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := transaction.AfterDocumentLoad(); err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, transaction)
+	}
+	return transactions, rows.Err()
+}
@@ -0,0 +1,200 @@
+package repository_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/internal/core/repository"
+	"github.com/Tomelin/financial-management-backend/pkg/db"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+type TransactionRepoTestSuite struct {
+	suite.Suite
+	database func() db.Database
+	repo     entity.ITransaction
+	wallets  repository.IWalletRepo
+	userID   string
+	walletID string
+	ctx      context.Context
+}
+
+func (s *TransactionRepoTestSuite) SetupTest() {
+	database := s.database()
+	repo, err := repository.NewTransactionRepo(database)
+	s.Require().Nil(err)
+	s.wallets, err = repository.NewWalletRepo(database)
+	s.Require().Nil(err)
+
+	s.repo = repo
+	s.userID = uuid.New().String()
+	s.ctx = context.Background()
+
+	wallet, mErr := entity.NewWallet(&entity.WalletResponse{Name: "MyWallet", OwnerID: s.userID, TenantID: uuid.New().String(), Currency: "BRL"})
+	s.Require().Nil(mErr)
+	_, mErr = s.wallets.Create(s.ctx, &s.userID, wallet)
+	s.Require().Nil(mErr)
+	s.walletID = wallet.ID
+}
+
+func (s *TransactionRepoTestSuite) balance() entity.Money {
+	wallet, mErr := s.wallets.GetByID(s.ctx, &s.walletID)
+	s.Require().Nil(mErr)
+	return wallet.Balance
+}
+
+func (s *TransactionRepoTestSuite) newTransaction(kind entity.TransactionType, amount int64) *entity.WalletTransaction {
+	transaction := &entity.WalletTransaction{
+		ID:          uuid.New().String(),
+		CategoryID:  uuid.New().String(),
+		Type:        kind,
+		Amount:      entity.NewMoney(amount, "BRL"),
+		Currency:    "BRL",
+		Description: "market",
+		Date:        time.Now().UTC(),
+		CreatedAt:   time.Now().UTC(),
+		UpdatedAt:   time.Now().UTC(),
+	}
+
+	created, mErr := s.repo.Create(s.ctx, &s.userID, &s.walletID, transaction)
+	s.Require().Nil(mErr)
+	return created
+}
+
+func (s *TransactionRepoTestSuite) TestCreate() {
+	created := s.newTransaction(entity.TransactionTypeExpense, 1050)
+
+	s.Equal(s.walletID, created.WalletID)
+	s.Equal(s.userID, created.CreatedBy)
+	s.Equal(entity.NewMoney(1050, "BRL"), created.Amount)
+	s.Equal(entity.NewMoney(-1050, "BRL"), s.balance(), "the expense is applied to the wallet")
+
+	otherWallet := uuid.New().String()
+	transaction := *created
+	transaction.ID = uuid.New().String()
+	_, mErr := s.repo.Create(s.ctx, &s.userID, &otherWallet, &transaction)
+	s.Require().NotNil(mErr)
+	s.Equal(entity.ResponseCodeNotFound, mErr.Code)
+}
+
+func (s *TransactionRepoTestSuite) TestCreate_Conflict() {
+	created := s.newTransaction(entity.TransactionTypeExpense, 1050)
+
+	wallet, mErr := entity.NewWallet(&entity.WalletResponse{Name: "OtherWallet", OwnerID: s.userID, TenantID: uuid.New().String(), Currency: "BRL"})
+	s.Require().Nil(mErr)
+	_, mErr = s.wallets.Create(s.ctx, &s.userID, wallet)
+	s.Require().Nil(mErr)
+
+	for _, walletID := range []string{s.walletID, wallet.ID} {
+		transaction := *created
+		transaction.Amount = entity.NewMoney(9000, "BRL")
+		_, mErr := s.repo.Create(s.ctx, &s.userID, &walletID, &transaction)
+		s.Require().NotNil(mErr, "a create does not replace a stored transaction")
+		s.Equal(entity.ResponseCodeConflict, mErr.Code)
+	}
+
+	stored, mErr := s.repo.GetByID(s.ctx, &s.userID, &s.walletID, &created.ID)
+	s.Require().Nil(mErr)
+	s.Require().NotNil(stored)
+	s.Equal(entity.NewMoney(1050, "BRL"), stored.Amount)
+	s.Equal(entity.NewMoney(-1050, "BRL"), s.balance(), "the amount is applied once")
+}
+
+func (s *TransactionRepoTestSuite) TestGet() {
+	s.newTransaction(entity.TransactionTypeExpense, 1050)
+	s.newTransaction(entity.TransactionTypeIncome, 5000)
+
+	transactions, mErr := s.repo.Get(s.ctx, &s.userID, &s.walletID, entity.DefaultPage())
+	s.Nil(mErr)
+	s.Len(transactions.Items, 2)
+
+	otherWallet := uuid.New().String()
+	transactions, mErr = s.repo.Get(s.ctx, &s.userID, &otherWallet, entity.DefaultPage())
+	s.Nil(mErr)
+	s.Empty(transactions.Items)
+}
+
+func (s *TransactionRepoTestSuite) TestGetByID_NotFound() {
+	id := uuid.New().String()
+	transaction, mErr := s.repo.GetByID(s.ctx, &s.userID, &s.walletID, &id)
+	s.Nil(mErr)
+	s.Nil(transaction)
+}
+
+func (s *TransactionRepoTestSuite) TestUpdate() {
+	created := s.newTransaction(entity.TransactionTypeExpense, 1050)
+
+	created.Amount = entity.NewMoney(2000, "BRL")
+	created.WalletID = uuid.New().String()
+	updated, mErr := s.repo.Update(s.ctx, &s.userID, &s.walletID, created)
+	s.Nil(mErr)
+	s.Equal(entity.NewMoney(2000, "BRL"), updated.Amount)
+	s.Equal(s.walletID, updated.WalletID)
+	s.Equal(entity.NewMoney(-2000, "BRL"), s.balance(), "the difference is applied to the wallet")
+
+	missing := *created
+	missing.ID = uuid.New().String()
+	_, mErr = s.repo.Update(s.ctx, &s.userID, &s.walletID, &missing)
+	s.NotNil(mErr)
+	s.Equal(entity.ResponseCodeNotFound, mErr.Code)
+}
+
+func (s *TransactionRepoTestSuite) TestDelete() {
+	created := s.newTransaction(entity.TransactionTypeExpense, 1050)
+
+	s.Nil(s.repo.Delete(s.ctx, &s.userID, &s.walletID, &created.ID))
+	s.True(s.balance().IsZero(), "the amount is reverted from the wallet")
+
+	transaction, mErr := s.repo.GetByID(s.ctx, &s.userID, &s.walletID, &created.ID)
+	s.Nil(mErr)
+	s.Nil(transaction)
+
+	mErr = s.repo.Delete(s.ctx, &s.userID, &s.walletID, &created.ID)
+	s.Require().NotNil(mErr)
+	s.Equal(entity.ResponseCodeNotFound, mErr.Code)
+	s.True(s.balance().IsZero(), "a missing transaction is not reverted twice")
+}
+
+func (s *TransactionRepoTestSuite) TestUpdate_Concurrent() {
+	created := s.newTransaction(entity.TransactionTypeIncome, 1000)
+
+	const writers = 10
+	var wg sync.WaitGroup
+	for i := 1; i <= writers; i++ {
+		wg.Add(1)
+		go func(amount int64) {
+			defer wg.Done()
+			transaction := *created
+			transaction.Amount = entity.NewMoney(amount, "BRL")
+			_, mErr := s.repo.Update(s.ctx, &s.userID, &s.walletID, &transaction)
+			s.Nil(mErr)
+		}(int64(i * 100))
+	}
+	wg.Wait()
+
+	stored, mErr := s.repo.GetByID(s.ctx, &s.userID, &s.walletID, &created.ID)
+	s.Require().Nil(mErr)
+	s.Equal(stored.Amount, s.balance(), "the balance follows the stored transaction")
+}
+
+func (s *TransactionRepoTestSuite) TestGetByFilterMany() {
+	s.newTransaction(entity.TransactionTypeExpense, 1050)
+	s.newTransaction(entity.TransactionTypeIncome, 5000)
+
+	transactions, mErr := s.repo.GetByFilterMany(s.ctx, &s.userID, &s.walletID, []entity.QueryDB{{Key: "type", Value: string(entity.TransactionTypeIncome), Condition: "=="}})
+	s.Nil(mErr)
+	s.Len(transactions, 1)
+	s.Equal(entity.NewMoney(5000, "BRL"), transactions[0].Amount)
+}
+
+func TestRunTransactionRepoTestSuite(t *testing.T) {
+	suite.Run(t, &TransactionRepoTestSuite{database: func() db.Database { return db.NewMemoryStore() }})
+}
+
+func TestRunTransactionSQLRepoTestSuite(t *testing.T) {
+	suite.Run(t, &TransactionRepoTestSuite{database: func() db.Database { return newSQLiteDatabase(t) }})
+}
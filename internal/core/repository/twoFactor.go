@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/pkg/db"
+	"github.com/Tomelin/financial-management-backend/pkg/logger"
+)
+
+const twoFactorsCollection = "two_factors"
+
+type ITwoFactorRepo interface {
+	// GetTwoFactor returns nil when the user has no second factor
+	GetTwoFactor(ctx context.Context, userID string) (*entity.TwoFactor, error)
+	SaveTwoFactor(ctx context.Context, twoFactor *entity.TwoFactor) error
+	DeleteTwoFactor(ctx context.Context, userID string) error
+	// TwoFactorRequired reports if a tenant whose wallets the user accesses requires the second factor
+	// The user accesses the wallets of the own tenant, of the tenants it is a user of and the wallets shared with the own tenant
+	TwoFactorRequired(ctx context.Context, userID, tenantID string) (bool, error)
+}
+
+type TwoFactorRepo struct {
+	db  db.DocumentStore
+	log logger.Logger
+}
+
+// NewTwoFactorRepo creates the repository of the second factors of the database kind
+func NewTwoFactorRepo(database db.Database, l logger.Logger) (ITwoFactorRepo, error) {
+
+	switch conn := database.(type) {
+	case *db.SQLDatabase:
+		return NewTwoFactorSQLRepo(conn, l)
+	case db.DocumentStore:
+		return &TwoFactorRepo{
+			db:  conn,
+			log: l,
+		}, nil
+	}
+
+	return nil, errors.New("db é obrigatório")
+}
+
+func (a *TwoFactorRepo) GetTwoFactor(ctx context.Context, userID string) (*entity.TwoFactor, error) {
+	doc, err := a.db.Collection(twoFactorsCollection).Doc(userID).Get(ctx)
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao buscar o segundo fator GetTwoFactor: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+
+	var twoFactor entity.TwoFactor
+	if err := doc.DataTo(&twoFactor); err != nil {
+		return nil, a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao buscar o segundo fator GetTwoFactor: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+	return &twoFactor, nil
+}
+
+func (a *TwoFactorRepo) SaveTwoFactor(ctx context.Context, twoFactor *entity.TwoFactor) error {
+	err := a.db.Collection(twoFactorsCollection).Doc(twoFactor.UserID).Set(ctx, *twoFactor)
+	if err != nil {
+		return a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao salvar o segundo fator SaveTwoFactor: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+	return nil
+}
+
+func (a *TwoFactorRepo) DeleteTwoFactor(ctx context.Context, userID string) error {
+	err := a.db.Collection(twoFactorsCollection).Doc(userID).Delete(ctx)
+	if err != nil && !errors.Is(err, db.ErrNotFound) {
+		return a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao remover o segundo fator DeleteTwoFactor: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+	return nil
+}
+
+func (a *TwoFactorRepo) TwoFactorRequired(ctx context.Context, userID, tenantID string) (bool, error) {
+	tenants := a.db.Collection("tenants")
+
+	required, err := a.anyDocument(ctx, tenants.Where("require_two_factor", db.OpEqual, true).Where("users", db.OpArrayContains, userID))
+	if err != nil || required {
+		return required, err
+	}
+	required, err = a.anyDocument(ctx, tenants.Where("require_two_factor", db.OpEqual, true).Where("owner_id", db.OpEqual, userID))
+	if err != nil || required || tenantID == "" {
+		return required, err
+	}
+
+	tenantIDs := []string{tenantID}
+	docs, err := a.db.Collection("wallets").Where("shared_with_tenants", db.OpArrayContains, tenantID).Documents(ctx)
+	if err != nil {
+		return false, a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao buscar as carteiras TwoFactorRequired: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+	for _, doc := range docs {
+		var wallet entity.WalletResponse
+		if err := doc.DataTo(&wallet); err != nil {
+			return false, a.log.Error(&logger.Message{
+				Body: fmt.Sprintf("erro ao buscar as carteiras TwoFactorRequired: %s", err.Error()),
+				Code: logger.ResponseCodeInternalServer})
+		}
+		tenantIDs = append(tenantIDs, wallet.TenantID)
+	}
+
+	for _, id := range tenantIDs {
+		doc, err := tenants.Doc(id).Get(ctx)
+		if errors.Is(err, db.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return false, a.log.Error(&logger.Message{
+				Body: fmt.Sprintf("erro ao buscar o tenant TwoFactorRequired: %s", err.Error()),
+				Code: logger.ResponseCodeInternalServer})
+		}
+		var tenant entity.TenantResponse
+		if err := doc.DataTo(&tenant); err != nil {
+			return false, a.log.Error(&logger.Message{
+				Body: fmt.Sprintf("erro ao buscar o tenant TwoFactorRequired: %s", err.Error()),
+				Code: logger.ResponseCodeInternalServer})
+		}
+		if tenant.RequireTwoFactor {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (a *TwoFactorRepo) anyDocument(ctx context.Context, query db.Query) (bool, error) {
+	docs, err := query.Limit(1).Documents(ctx)
+	if err != nil {
+		return false, a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao buscar os tenants TwoFactorRequired: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+	return len(docs) > 0, nil
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/pkg/db"
+	"github.com/Tomelin/financial-management-backend/pkg/logger"
+)
+
+const twoFactorColumns = `user_id, secret, enabled, last_step, recovery_codes, create_at, update_at`
+
+type TwoFactorSQLRepo struct {
+	db  *db.SQLDatabase
+	log logger.Logger
+}
+
+// NewTwoFactorSQLRepo creates the repository of the second factors of a relational database
+func NewTwoFactorSQLRepo(database *db.SQLDatabase, l logger.Logger) (ITwoFactorRepo, error) {
+
+	if database == nil {
+		return nil, errors.New("db é obrigatório")
+	}
+
+	return &TwoFactorSQLRepo{
+		db:  database,
+		log: l,
+	}, nil
+}
+
+func (a *TwoFactorSQLRepo) GetTwoFactor(ctx context.Context, userID string) (*entity.TwoFactor, error) {
+	var twoFactor entity.TwoFactor
+	err := a.db.DB.QueryRowContext(ctx, a.db.Rebind(`SELECT `+twoFactorColumns+` FROM two_factors WHERE user_id = ?`), userID).
+		Scan(&twoFactor.UserID, &twoFactor.Secret, &twoFactor.Enabled, &twoFactor.LastStep, sqlJSON{&twoFactor.RecoveryCodes}, &twoFactor.CreatedAt, &twoFactor.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao buscar o segundo fator GetTwoFactor: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+	return &twoFactor, nil
+}
+
+func (a *TwoFactorSQLRepo) SaveTwoFactor(ctx context.Context, twoFactor *entity.TwoFactor) error {
+	recoveryCodes, err := jsonValue(twoFactor.RecoveryCodes)
+	if err != nil {
+		return err
+	}
+
+	_, err = a.db.DB.ExecContext(ctx, a.db.Rebind(`INSERT INTO two_factors (`+twoFactorColumns+`)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (user_id) DO UPDATE SET
+    secret = excluded.secret,
+    enabled = excluded.enabled,
+    last_step = excluded.last_step,
+    recovery_codes = excluded.recovery_codes,
+    update_at = excluded.update_at`),
+		twoFactor.UserID, twoFactor.Secret, twoFactor.Enabled, twoFactor.LastStep, recoveryCodes, twoFactor.CreatedAt, twoFactor.UpdatedAt)
+	if err != nil {
+		return a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao salvar o segundo fator SaveTwoFactor: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+	return nil
+}
+
+func (a *TwoFactorSQLRepo) DeleteTwoFactor(ctx context.Context, userID string) error {
+	_, err := a.db.DB.ExecContext(ctx, a.db.Rebind(`DELETE FROM two_factors WHERE user_id = ?`), userID)
+	if err != nil {
+		return a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao remover o segundo fator DeleteTwoFactor: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+	return nil
+}
+
+func (a *TwoFactorSQLRepo) TwoFactorRequired(ctx context.Context, userID, tenantID string) (bool, error) {
+	query := `SELECT 1 FROM tenants t WHERE t.require_two_factor = ? AND (
+    t.id = ? OR t.owner_id = ? OR ` + a.db.ArrayContains("t.users") + ` OR
+    EXISTS (SELECT 1 FROM wallets w WHERE w.tenant_id = t.id AND ` + a.db.ArrayContains("w.shared_with_tenants") + `)
+) LIMIT 1`
+
+	var found int
+	err := a.db.DB.QueryRowContext(ctx, a.db.Rebind(query), true, tenantID, userID, userID, tenantID).Scan(&found)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("erro ao buscar os tenants TwoFactorRequired: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer})
+	}
+	return true, nil
+}
@@ -0,0 +1,122 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/internal/core/repository"
+	"github.com/Tomelin/financial-management-backend/pkg/db"
+	"github.com/Tomelin/financial-management-backend/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+type TwoFactorRepoTestSuite struct {
+	suite.Suite
+	database func() db.Database
+	conn     db.Database
+	repo     repository.ITwoFactorRepo
+	userID   string
+	ctx      context.Context
+}
+
+func (s *TwoFactorRepoTestSuite) SetupTest() {
+	s.conn = s.database()
+	repo, err := repository.NewTwoFactorRepo(s.conn, logger.NewLoggerConfig(map[string]any{"level": "error"}))
+	s.Require().Nil(err)
+
+	s.repo = repo
+	s.userID = uuid.New().String()
+	s.ctx = context.Background()
+}
+
+func (s *TwoFactorRepoTestSuite) tenant(ownerID string, users []string, required bool) *entity.TenantResponse {
+	repo, err := repository.NewTenantRepository(s.conn)
+	s.Require().Nil(err)
+
+	tenant, err := repo.Create(&entity.TenantResponse{
+		ID:               uuid.New().String(),
+		Name:             "owner@example.com",
+		OwnerID:          ownerID,
+		Users:            users,
+		Plan:             entity.PlanResponse{ID: uuid.New().String(), Name: "bronze", Price: entity.NewMoney(500, "BRL"), Currency: "BRL"},
+		RequireTwoFactor: required,
+		CreatedAt:        time.Now().UTC(),
+		UpdatedAt:        time.Now().UTC(),
+	})
+	s.Require().Nil(err)
+	return tenant
+}
+
+func (s *TwoFactorRepoTestSuite) TestSaveGetAndDelete() {
+	found, err := s.repo.GetTwoFactor(s.ctx, s.userID)
+	s.Nil(err)
+	s.Nil(found)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	twoFactor := &entity.TwoFactor{UserID: s.userID, Secret: "SECRET", CreatedAt: now, UpdatedAt: now}
+	s.Require().Nil(s.repo.SaveTwoFactor(s.ctx, twoFactor))
+
+	twoFactor.Enabled = true
+	twoFactor.LastStep = 42
+	twoFactor.RecoveryCodes = []string{"a", "b"}
+	s.Require().Nil(s.repo.SaveTwoFactor(s.ctx, twoFactor))
+
+	found, err = s.repo.GetTwoFactor(s.ctx, s.userID)
+	s.Require().Nil(err)
+	s.Require().NotNil(found)
+	s.Equal("SECRET", found.Secret)
+	s.True(found.Enabled)
+	s.Equal(int64(42), found.LastStep)
+	s.Equal([]string{"a", "b"}, found.RecoveryCodes)
+
+	s.Require().Nil(s.repo.DeleteTwoFactor(s.ctx, s.userID))
+	s.Nil(s.repo.DeleteTwoFactor(s.ctx, s.userID))
+	found, err = s.repo.GetTwoFactor(s.ctx, s.userID)
+	s.Nil(err)
+	s.Nil(found)
+}
+
+func (s *TwoFactorRepoTestSuite) TestTwoFactorRequired() {
+	own := s.tenant(s.userID, nil, false)
+	required, err := s.repo.TwoFactorRequired(s.ctx, s.userID, own.ID)
+	s.Require().Nil(err)
+	s.False(required)
+
+	// a tenant the user is a user of
+	other := s.tenant(uuid.New().String(), []string{s.userID}, true)
+	required, err = s.repo.TwoFactorRequired(s.ctx, s.userID, own.ID)
+	s.Require().Nil(err)
+	s.True(required)
+	required, err = s.repo.TwoFactorRequired(s.ctx, uuid.New().String(), uuid.New().String())
+	s.Require().Nil(err)
+	s.False(required)
+
+	// a wallet of the tenant shared with the tenant of another user
+	wallets, err := repository.NewWalletRepo(s.conn)
+	s.Require().Nil(err)
+	wallet, mErr := entity.NewWallet(&entity.WalletResponse{Name: "shared", OwnerID: other.OwnerID, TenantID: other.ID, Currency: "BRL"})
+	s.Require().Nil(mErr)
+	wallet.SharedWithTenants = []string{"t-shared"}
+	_, mErr = wallets.Create(s.ctx, &other.OwnerID, wallet)
+	s.Require().Nil(mErr)
+
+	required, err = s.repo.TwoFactorRequired(s.ctx, uuid.New().String(), "t-shared")
+	s.Require().Nil(err)
+	s.True(required)
+
+	// the own tenant
+	required, err = s.repo.TwoFactorRequired(s.ctx, other.OwnerID, other.ID)
+	s.Require().Nil(err)
+	s.True(required)
+}
+
+func TestRunTwoFactorRepoTestSuite(t *testing.T) {
+	suite.Run(t, &TwoFactorRepoTestSuite{database: func() db.Database { return db.NewMemoryStore() }})
+}
+
+func TestRunTwoFactorSQLRepoTestSuite(t *testing.T) {
+	suite.Run(t, &TwoFactorRepoTestSuite{database: func() db.Database { return newSQLiteDatabase(t) }})
+}
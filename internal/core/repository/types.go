@@ -1,5 +1,14 @@
 package repository
 
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/pkg/db"
+)
+
 func checkFirebaseCondition(condition *string) string {
 	if condition == nil {
 		return "=="
@@ -14,3 +23,118 @@ func checkFirebaseCondition(condition *string) string {
 
 	return "=="
 }
+
+// applyFilter adds the where clauses of the filter to the query, the empty clauses are ignored
+func applyFilter(query db.Query, filter []entity.QueryDB) db.Query {
+	for _, f := range filter {
+		if p, ok := propertyFilter(f); ok {
+			query = query.WhereFilter(p)
+		}
+	}
+	return query
+}
+
+// applyClauses adds the clauses to the query, they are joined by AND
+func applyClauses(query db.Query, clauses []entity.QueryDBClause) db.Query {
+	for _, clause := range clauses {
+		if f := clauseFilter(clause); f != nil {
+			query = query.WhereFilter(f)
+		}
+	}
+	return query
+}
+
+// clauseFilter converts the clause and its nested clauses to a composite filter
+// The empty queries are ignored, it returns nil when nothing is left
+func clauseFilter(clause entity.QueryDBClause) db.Filter {
+	var filters []db.Filter
+	for _, q := range clause.Queries {
+		if p, ok := propertyFilter(q); ok {
+			filters = append(filters, p)
+		}
+	}
+	for _, nested := range clause.Clauses {
+		if f := clauseFilter(nested); f != nil {
+			filters = append(filters, f)
+		}
+	}
+
+	switch {
+	case len(filters) == 0:
+		return nil
+	case len(filters) == 1:
+		return filters[0]
+	case clause.Clause == entity.QueryClauseOr:
+		return db.OrFilter{Filters: filters}
+	}
+	return db.AndFilter{Filters: filters}
+}
+
+// propertyFilter converts the query, the items of in, not-in and array-contains-any are separated by commas
+func propertyFilter(f entity.QueryDB) (db.PropertyFilter, bool) {
+	if f.Key == "" || f.Value == "" {
+		return db.PropertyFilter{}, false
+	}
+
+	condition := checkFirebaseCondition(&f.Condition)
+
+	var value any = f.Value
+	switch condition {
+	case db.OpIn, db.OpNotIn, db.OpArrayContainsAny:
+		value = splitValues(f.Value)
+	}
+
+	// the value converted to the type of the field by entity.Fields
+	if f.Typed != nil {
+		value = f.Typed
+	}
+
+	return db.PropertyFilter{Path: f.Key, Operator: condition, Value: value}, true
+}
+
+// pageDirection returns the direction of the page in the database
+func pageDirection(page *entity.PageRequest) db.Direction {
+	if page.Direction == entity.PageDesc {
+		return db.Desc
+	}
+	return db.Asc
+}
+
+// pageErrorCode returns the response code of an error of a list, an invalid page is a bad request
+func pageErrorCode(err error) entity.ResponseCode {
+	if errors.Is(err, entity.ErrInvalidPage) {
+		return entity.ResponseCodeBadRequest
+	}
+	return sqlErrorCode(err)
+}
+
+// documentPage reads a page of the query, load converts each document into an item
+func documentPage[T any](ctx context.Context, query db.Query, page *entity.PageRequest, load func(doc *db.Document) (T, error)) (*entity.Page[T], error) {
+	if page == nil {
+		page = entity.DefaultPage()
+	}
+
+	total, err := query.Count(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	docs, next, err := db.Paginate(ctx, query, page.OrderBy, pageDirection(page), page.Limit, page.Cursor)
+	if errors.Is(err, db.ErrInvalidCursor) {
+		return nil, fmt.Errorf("%w: %s", entity.ErrInvalidPage, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]T, 0, len(docs))
+	for _, doc := range docs {
+		item, err := load(doc)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return &entity.Page[T]{Items: items, NextCursor: next, Total: &total}, nil
+}
@@ -7,24 +7,27 @@ import (
 	"github.com/Tomelin/financial-management-backend/internal/core/entity"
 	"github.com/Tomelin/financial-management-backend/pkg/db"
 	"github.com/Tomelin/financial-management-backend/pkg/observability"
-	"google.golang.org/api/iterator"
 )
 
 type UserRepo struct {
-	db    db.FirebaseDatabaseInterface
+	db    db.DocumentStore
 	trace *observability.Tracer
 }
 
-func NewUserRepository(db db.FirebaseDatabaseInterface) (entity.IUser, error) {
-	if db == nil {
-		return nil, errors.New("db is required")
+// NewUserRepository creates the user repository of the database kind
+func NewUserRepository(database db.Database) (entity.IUser, error) {
+	switch conn := database.(type) {
+	case *db.SQLDatabase:
+		return NewUserSQLRepository(conn)
+	case db.DocumentStore:
+		return &UserRepo{db: conn}, nil
 	}
-	return &UserRepo{db: db}, nil
+	return nil, errors.New("db is required")
 }
 
 func (u *UserRepo) Create(ctx context.Context, user *entity.AccountUser) (*entity.AccountUser, error) {
 
-	_, err := u.db.Collection("users").Doc(user.ID).Set(ctx, user)
+	err := u.db.Collection("users").Doc(user.ID).Set(ctx, user)
 	if err != nil {
 		return nil, err
 	}
@@ -43,32 +46,13 @@ func (u *UserRepo) Create(ctx context.Context, user *entity.AccountUser) (*entit
 	return &userResponse, nil
 }
 
-func (u *UserRepo) Get(ctx context.Context) ([]entity.AccountUser, error) {
-	iter := u.db.Documents(ctx, "users")
-
-	defer iter.Stop()
-
-	var documents []entity.AccountUser
-	for {
+func (u *UserRepo) Get(ctx context.Context, page *entity.PageRequest) (*entity.Page[entity.AccountUser], error) {
+	return documentPage(ctx, u.db.Collection("users"), page, func(doc *db.Document) (entity.AccountUser, error) {
 		var user entity.AccountUser
-		doc, err := iter.Next()
-		if err == iterator.Done {
-			break
-		}
-		if err != nil {
-			return nil, err
-		}
-
-		err = doc.DataTo(&user)
-		if err != nil {
-			return nil, err
-		}
-		user.ID = doc.Ref.ID
-
-		documents = append(documents, user)
-	}
-
-	return documents, nil
+		err := doc.DataTo(&user)
+		user.ID = doc.ID
+		return user, err
+	})
 }
 
 func (u *UserRepo) GetById(ctx context.Context, id *string) (*entity.AccountUser, error) {
@@ -88,17 +72,17 @@ func (u *UserRepo) GetById(ctx context.Context, id *string) (*entity.AccountUser
 
 func (u *UserRepo) GetByEmail(ctx context.Context, email *string) (*entity.AccountUser, error) {
 
-	doc, err := u.db.Collection("users").Where("email", "==", *email).Documents(ctx).Next()
+	docs, err := u.db.Collection("users").Where("email", "==", *email).Limit(1).Documents(ctx)
 	if err != nil {
-		if err == iterator.Done {
-			return nil, errors.New("not found")
-		}
-
 		return nil, err
 	}
 
+	if len(docs) == 0 {
+		return nil, errors.New("not found")
+	}
+
 	var user entity.AccountUser
-	err = doc.DataTo(&user)
+	err = docs[0].DataTo(&user)
 	if err != nil {
 		return nil, err
 	}
@@ -107,7 +91,7 @@ func (u *UserRepo) GetByEmail(ctx context.Context, email *string) (*entity.Accou
 }
 
 func (u *UserRepo) Update(ctx context.Context, data *entity.AccountUser) (*entity.AccountUser, error) {
-	_, err := u.db.Collection("users").Doc(data.ID).Set(ctx, data)
+	err := u.db.Collection("users").Doc(data.ID).Set(ctx, data)
 	if err != nil {
 		return nil, err
 	}
@@ -127,7 +111,7 @@ func (u *UserRepo) Update(ctx context.Context, data *entity.AccountUser) (*entit
 }
 
 func (u *UserRepo) Delete(ctx context.Context, id *string) error {
-	_, err := u.db.Collection("users").Doc(*id).Delete(ctx)
+	err := u.db.Collection("users").Doc(*id).Delete(ctx)
 	if err != nil {
 		return err
 	}
@@ -136,26 +120,19 @@ func (u *UserRepo) Delete(ctx context.Context, id *string) error {
 }
 
 func (u *UserRepo) GetByFilterMany(ctx context.Context, filter []entity.QueryDBClause) ([]entity.AccountUser, error) {
-	iter := u.db.Documents(ctx, "users")
-
-	defer iter.Stop()
+	docs, err := applyClauses(u.db.Collection("users"), filter).Documents(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	var documents []entity.AccountUser
-	for {
+	for _, doc := range docs {
 		var user entity.AccountUser
-		doc, err := iter.Next()
-		if err == iterator.Done {
-			break
-		}
-		if err != nil {
-			return nil, err
-		}
-
 		err = doc.DataTo(&user)
 		if err != nil {
 			return nil, err
 		}
-		user.ID = doc.Ref.ID
+		user.ID = doc.ID
 
 		documents = append(documents, user)
 	}
@@ -167,78 +144,20 @@ func (u *UserRepo) GetByFilterOne(ctx context.Context, filter []entity.QueryDBCl
 	// ctx, span := c.trace.Trace.Start(ctx, "TransactionCategoryRepo.GetByFilterOne")
 	// defer span.End()
 
-	hasQuery := false
-	hasQueryOr := false
-	query := u.db.Collection("users").Query
-	queryOr := u.db.Collection("users").Query
-	for _, f := range filter {
-		if f.Clause == "" || f.Clause == entity.QueryClauseAnd {
-			hasQuery = true
-			for _, q := range f.Queries {
-				condition := checkFirebaseCondition(&q.Condition)
-				if q.Key != "" && q.Value != "" && condition != "" {
-					query = query.Where(q.Key, condition, q.Value)
-				}
-			}
-		}
-		if f.Clause == entity.QueryClauseOr {
-			hasQueryOr = true
-			for _, q := range f.Queries {
-				condition := checkFirebaseCondition(&q.Condition)
-				if q.Key != "" && q.Value != "" && condition != "" {
-					queryOr = queryOr.Where(q.Key, condition, q.Value)
-				}
-			}
-		}
-
-	}
-
-	var users []entity.AccountUser
-	if hasQuery {
-		iter := query.Documents(ctx)
-		defer iter.Stop()
-		for {
-			doc, err := iter.Next()
-			if err == iterator.Done {
-				break
-			}
-			if err != nil {
-				return nil, err
-			}
-
-			var user entity.AccountUser
-			err = doc.DataTo(&user)
-			if err != nil {
-				return nil, err
-			}
-			users = append(users, user)
-		}
+	docs, err := applyClauses(u.db.Collection("users"), filter).Limit(1).Documents(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	if hasQueryOr {
-		iter := queryOr.Documents(ctx)
-		defer iter.Stop()
-		for {
-			doc, err := iter.Next()
-			if err == iterator.Done {
-				break
-			}
-			if err != nil {
-				return nil, err
-			}
-
-			var user entity.AccountUser
-			err = doc.DataTo(&user)
-			if err != nil {
-				return nil, err
-			}
-			users = append(users, user)
-		}
+	if len(docs) == 0 {
+		return nil, errors.New("not found")
 	}
 
-	if len(users) == 0 {
-		return nil, errors.New("not found")
+	var user entity.AccountUser
+	err = docs[0].DataTo(&user)
+	if err != nil {
+		return nil, err
 	}
 
-	return &users[0], nil
+	return &user, nil
 }
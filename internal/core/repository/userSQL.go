@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/pkg/db"
+)
+
+var userTable = sqlTable{
+	name: "users",
+	columns: map[string]sqlKind{
+		"id":          sqlText,
+		"tenant_id":   sqlText,
+		"name":        sqlText,
+		"email":       sqlText,
+		"avatar_url":  sqlText,
+		"provider":    sqlText,
+		"first_name":  sqlText,
+		"last_name":   sqlText,
+		"nick_name":   sqlText,
+		"description": sqlText,
+		"user_id":     sqlText,
+		"location":    sqlText,
+		"create_at":   sqlTime,
+		"update_at":   sqlTime,
+	},
+}
+
+const userColumns = `id, tenant_id, roles, name, email, avatar_url, provider, first_name, last_name, nick_name, description, user_id, location, create_at, update_at`
+
+type UserSQLRepo struct {
+	db *db.SQLDatabase
+}
+
+// NewUserSQLRepository creates the user repository of a relational database
+func NewUserSQLRepository(database *db.SQLDatabase) (entity.IUser, error) {
+	if database == nil {
+		return nil, errors.New("db is required")
+	}
+	return &UserSQLRepo{db: database}, nil
+}
+
+func (u *UserSQLRepo) Create(ctx context.Context, user *entity.AccountUser) (*entity.AccountUser, error) {
+	if err := u.save(ctx, user); err != nil {
+		return nil, err
+	}
+	return u.GetById(ctx, &user.ID)
+}
+
+func (u *UserSQLRepo) Get(ctx context.Context, page *entity.PageRequest) (*entity.Page[entity.AccountUser], error) {
+	return sqlPage(ctx, u.db, userTable, page, "", nil, u.query)
+}
+
+func (u *UserSQLRepo) GetById(ctx context.Context, id *string) (*entity.AccountUser, error) {
+	users, err := u.query(ctx, `id = ?`, "", 0, *id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(users) == 0 {
+		return nil, fmt.Errorf("%w: users/%s", db.ErrNotFound, *id)
+	}
+
+	return &users[0], nil
+}
+
+func (u *UserSQLRepo) GetByEmail(ctx context.Context, email *string) (*entity.AccountUser, error) {
+	users, err := u.query(ctx, `email = ?`, "", 1, *email)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(users) == 0 {
+		return nil, errors.New("not found")
+	}
+
+	return &users[0], nil
+}
+
+func (u *UserSQLRepo) Update(ctx context.Context, data *entity.AccountUser) (*entity.AccountUser, error) {
+	if err := u.save(ctx, data); err != nil {
+		return nil, err
+	}
+	return u.GetById(ctx, &data.ID)
+}
+
+func (u *UserSQLRepo) Delete(ctx context.Context, id *string) error {
+	_, err := u.db.DB.ExecContext(ctx, u.db.Rebind(`DELETE FROM users WHERE id = ?`), *id)
+	return err
+}
+
+func (u *UserSQLRepo) GetByFilterMany(ctx context.Context, filter []entity.QueryDBClause) ([]entity.AccountUser, error) {
+	where, args, err := userTable.whereClauses(u.db, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.query(ctx, where, "", 0, args...)
+}
+
+func (u *UserSQLRepo) GetByFilterOne(ctx context.Context, filter []entity.QueryDBClause) (*entity.AccountUser, error) {
+	where, args, err := userTable.whereClauses(u.db, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := u.query(ctx, where, "", 1, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(users) == 0 {
+		return nil, errors.New("not found")
+	}
+
+	return &users[0], nil
+}
+
+func (u *UserSQLRepo) save(ctx context.Context, user *entity.AccountUser) error {
+	roles, err := jsonValue(user.Roles)
+	if err != nil {
+		return err
+	}
+
+	_, err = u.db.DB.ExecContext(ctx, u.db.Rebind(`INSERT INTO users (`+userColumns+`)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (id) DO UPDATE SET
+    tenant_id = excluded.tenant_id,
+    roles = excluded.roles,
+    name = excluded.name,
+    email = excluded.email,
+    avatar_url = excluded.avatar_url,
+    provider = excluded.provider,
+    first_name = excluded.first_name,
+    last_name = excluded.last_name,
+    nick_name = excluded.nick_name,
+    description = excluded.description,
+    user_id = excluded.user_id,
+    location = excluded.location,
+    create_at = excluded.create_at,
+    update_at = excluded.update_at`),
+		user.ID, user.TenantID, roles, user.Name, user.Email, user.AvatarURL, user.Provider, user.FirstName, user.LastName,
+		user.NickName, user.Description, user.UserID, user.Location, user.CreatedAt, user.UpdatedAt)
+	return err
+}
+
+func (u *UserSQLRepo) query(ctx context.Context, where, order string, limit int, args ...any) ([]entity.AccountUser, error) {
+	rows, err := u.db.DB.QueryContext(ctx, u.db.Rebind(userTable.selectQuery(userColumns, where, order, limit)), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []entity.AccountUser
+	for rows.Next() {
+		var user entity.AccountUser
+		err := rows.Scan(&user.ID, &user.TenantID, sqlJSON{&user.Roles}, &user.Name, &user.Email, &user.AvatarURL,
+			&user.Provider, &user.FirstName, &user.LastName, &user.NickName, &user.Description, &user.UserID,
+			&user.Location, &user.CreatedAt, &user.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
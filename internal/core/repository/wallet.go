@@ -6,25 +6,60 @@ import (
 
 	"github.com/Tomelin/financial-management-backend/internal/core/entity"
 	"github.com/Tomelin/financial-management-backend/pkg/db"
-	"google.golang.org/api/iterator"
+)
+
+type IWalletRepo interface {
+	entity.IWallet
+	// GetAccessible pages the wallets the user owns, of the tenant of the user and shared with it
+	GetAccessible(ctx context.Context, userId *string, tenantId *string, page *entity.PageRequest) (*entity.Page[entity.WalletResponse], *entity.ModuleError)
+	// GetAccessibleByFilter returns the wallets of GetAccessible that match the filter
+	GetAccessibleByFilter(ctx context.Context, userId *string, tenantId *string, filter []entity.QueryDBClause) ([]entity.WalletResponse, *entity.ModuleError)
+	// GetByTenant returns the wallets of the tenant, of every owner, the limits of the plan count them
+	GetByTenant(ctx context.Context, tenantId *string) ([]entity.WalletResponse, *entity.ModuleError)
+}
+
+var (
+	// errWalletOwner is returned when the wallet of an update does not belong to the user
+	errWalletOwner = errors.New("unauthorized: wallet does not belong to the user")
+	// errWalletNotFound is returned when the wallet of a balance update does not exist
+	errWalletNotFound = errors.New("wallet not found")
+	// errWalletExists is returned when the id of a created wallet is already stored
+	errWalletExists = errors.New("wallet already exists")
 )
 
 type WalletRepo struct {
-	db db.FirebaseDatabaseInterface
+	db db.DocumentStore
 }
 
-// NewWalletRepo
-func NewWalletRepo(db db.FirebaseDatabaseInterface) (entity.IWallet, error) {
-	if db == nil {
-		return nil, errors.New("database is required")
+// NewWalletRepo creates the wallet repository of the database kind
+func NewWalletRepo(database db.Database) (IWalletRepo, error) {
+	switch conn := database.(type) {
+	case *db.SQLDatabase:
+		return NewWalletSQLRepo(conn)
+	case db.DocumentStore:
+		return &WalletRepo{db: conn}, nil
 	}
 
-	return &WalletRepo{db: db}, nil
+	return nil, errors.New("database is required")
 }
 
+// Create inserts the wallet, a stored wallet of the id is a conflict and is not replaced
 func (w *WalletRepo) Create(ctx context.Context, userId *string, data *entity.WalletResponse) (*entity.WalletResponse, *entity.ModuleError) {
 
-	_, err := w.db.Collection("wallets").Doc(data.ID).Set(ctx, data)
+	ref := w.db.Collection("wallets").Doc(data.ID)
+	err := w.db.RunTransaction(ctx, func(ctx context.Context, tx db.Transaction) error {
+		_, err := tx.Get(ref)
+		if err == nil {
+			return errWalletExists
+		}
+		if !errors.Is(err, db.ErrNotFound) {
+			return err
+		}
+		return tx.Set(ref, data)
+	})
+	if errors.Is(err, errWalletExists) {
+		return nil, entity.Error(err.Error(), "wallet", "Create", entity.ApplicationLayerRepository, entity.ResponseCodeConflict)
+	}
 	if err != nil {
 		return nil, entity.Error(err.Error(), "wallet", "Create", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
 	}
@@ -37,41 +72,91 @@ func (w *WalletRepo) Create(ctx context.Context, userId *string, data *entity.Wa
 	return WalletResponse, nil
 }
 
-func (w *WalletRepo) Get(ctx context.Context, userId *string) ([]entity.WalletResponse, *entity.ModuleError) {
-	iter := w.db.Collection("wallets").Where("owner_id", "==", *userId).Documents(context.Background())
+func (w *WalletRepo) Get(ctx context.Context, userId *string, page *entity.PageRequest) (*entity.Page[entity.WalletResponse], *entity.ModuleError) {
+	query := w.db.Collection("wallets").Where("owner_id", "==", *userId)
+
+	wallets, err := documentPage(ctx, query, page, func(doc *db.Document) (entity.WalletResponse, error) {
+		var wallet entity.WalletResponse
+		err := doc.DataTo(&wallet)
+		return wallet, err
+	})
+	if err != nil {
+		return nil, entity.Error(err.Error(), "wallet", "Get", entity.ApplicationLayerRepository, pageErrorCode(err))
+	}
+	return wallets, nil
+}
+
+func (w *WalletRepo) GetAccessible(ctx context.Context, userId *string, tenantId *string, page *entity.PageRequest) (*entity.Page[entity.WalletResponse], *entity.ModuleError) {
+	query := w.db.Collection("wallets").WhereFilter(accessibleWallets(*userId, *tenantId))
+
+	wallets, err := documentPage(ctx, query, page, func(doc *db.Document) (entity.WalletResponse, error) {
+		var wallet entity.WalletResponse
+		err := doc.DataTo(&wallet)
+		return wallet, err
+	})
+	if err != nil {
+		return nil, entity.Error(err.Error(), "wallet", "GetAccessible", entity.ApplicationLayerRepository, pageErrorCode(err))
+	}
+	return wallets, nil
+}
+
+func (w *WalletRepo) GetAccessibleByFilter(ctx context.Context, userId *string, tenantId *string, filter []entity.QueryDBClause) ([]entity.WalletResponse, *entity.ModuleError) {
+	query := applyClauses(w.db.Collection("wallets").WhereFilter(accessibleWallets(*userId, *tenantId)), filter)
+
+	docs, err := query.Documents(ctx)
+	if err != nil {
+		return nil, entity.Error(err.Error(), "wallet", "GetAccessibleByFilter", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
 
-	defer iter.Stop()
 	var wallets []entity.WalletResponse
-	for {
-		doc, err := iter.Next()
-		if err == iterator.Done {
-			break
-		}
-		if err != nil {
-			return nil, entity.Error(err.Error(), "wallet", "Get", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	for _, doc := range docs {
+		var wallet entity.WalletResponse
+		if err := doc.DataTo(&wallet); err != nil {
+			return nil, entity.Error(err.Error(), "wallet", "GetAccessibleByFilter", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
 		}
+		wallets = append(wallets, wallet)
+	}
+	return wallets, nil
+}
+
+func (w *WalletRepo) GetByTenant(ctx context.Context, tenantId *string) ([]entity.WalletResponse, *entity.ModuleError) {
+	docs, err := w.db.Collection("wallets").Where("tenant_id", "==", *tenantId).Documents(ctx)
+	if err != nil {
+		return nil, entity.Error(err.Error(), "wallet", "GetByTenant", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
 
+	var wallets []entity.WalletResponse
+	for _, doc := range docs {
 		var wallet entity.WalletResponse
-		err = doc.DataTo(&wallet)
-		if err != nil {
-			return nil, entity.Error(err.Error(), "wallet", "Get", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+		if err := doc.DataTo(&wallet); err != nil {
+			return nil, entity.Error(err.Error(), "wallet", "GetByTenant", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
 		}
 		wallets = append(wallets, wallet)
 	}
 	return wallets, nil
 }
 
+// accessibleWallets matches the wallets of the owner, of the tenant and shared with the tenant
+func accessibleWallets(userId, tenantId string) db.Filter {
+	return db.OrFilter{Filters: []db.Filter{
+		db.PropertyFilter{Path: "owner_id", Operator: db.OpEqual, Value: userId},
+		db.PropertyFilter{Path: "tenant_id", Operator: db.OpEqual, Value: tenantId},
+		db.PropertyFilter{Path: "shared_with_tenants", Operator: db.OpArrayContains, Value: tenantId},
+	}}
+}
+
 func (w *WalletRepo) GetWalletByIdAndUserID(ctx context.Context, userId *string, id *string) (*entity.WalletResponse, *entity.ModuleError) {
-	doc := w.db.Collection("wallets").Where("id", "==", *id).Where("owner_id", "==", *userId).Documents(context.Background())
-	defer doc.Stop()
+	docs, err := w.db.Collection("wallets").Where("id", "==", *id).Where("owner_id", "==", *userId).Limit(1).Documents(ctx)
+	if err != nil {
+		return nil, entity.Error(err.Error(), "wallet", "GetById", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
 
-	data, _ := doc.Next()
-	if data == nil {
+	if len(docs) == 0 {
 		return &entity.WalletResponse{}, nil
 	}
 
 	var wallet entity.WalletResponse
-	err := data.DataTo(&wallet)
+	err = docs[0].DataTo(&wallet)
 	if err != nil {
 		return nil, entity.Error(err.Error(), "wallet", "GetById", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
 	}
@@ -79,50 +164,62 @@ func (w *WalletRepo) GetWalletByIdAndUserID(ctx context.Context, userId *string,
 	return &wallet, nil
 }
 
+// Update writes the fields of the wallet that are changed by the users, the plans and the shares
+// The balance, the currency, the owner and the tenant are kept, the balance changes only through UpdateBalance.
+// The ownership is checked in the transaction of the write
 func (w *WalletRepo) Update(ctx context.Context, userId *string, data *entity.WalletResponse) (*entity.WalletResponse, *entity.ModuleError) {
-	// Primeiro, obtenha a referência ao documento
-	docRef := w.db.Collection("wallets").Doc(data.ID)
-
-	// Verifique se o documento pertence ao usuário
-	doc, err := docRef.Get(ctx)
-	if err != nil {
-		return nil, entity.Error(err.Error(), "wallet", "Update", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
-	}
 
-	var wallet entity.WalletResponse
-	err = doc.DataTo(&wallet)
-	if err != nil {
-		return nil, entity.Error(err.Error(), "wallet", "Update", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
-	}
+	docRef := w.db.Collection("wallets").Doc(data.ID)
 
-	if wallet.OwnerID != *userId {
-		return nil, entity.Error("unauthorized: wallet does not belong to the user", "wallet", "Update", entity.ApplicationLayerRepository, entity.ResponseCodeUnauthorized)
-	}
+	var updated *entity.WalletResponse
+	err := w.db.RunTransaction(ctx, func(ctx context.Context, tx db.Transaction) error {
+		doc, err := tx.Get(docRef)
+		if err != nil {
+			return err
+		}
 
-	// Atualize o documento
-	_, err = docRef.Set(ctx, data)
-	if err != nil {
-		return nil, entity.Error(err.Error(), "wallet", "Update", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
-	}
+		var wallet entity.WalletResponse
+		if err := doc.DataTo(&wallet); err != nil {
+			return err
+		}
 
-	// Obtenha o documento atualizado
-	doc, err = docRef.Get(ctx)
-	if err != nil {
-		return nil, entity.Error(err.Error(), "wallet", "Update", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
-	}
+		if wallet.OwnerID != *userId {
+			return errWalletOwner
+		}
 
-	var updatedWallet entity.WalletResponse
-	err = doc.DataTo(&updatedWallet)
+		wallet.Name = data.Name
+		wallet.Description = data.Description
+		wallet.SharedWithTenants = data.SharedWithTenants
+		wallet.Permissions = data.Permissions
+		wallet.ReadOnly = data.ReadOnly
+		wallet.UpdatedAt = data.UpdatedAt
+
+		updated = &wallet
+		return tx.Update(docRef,
+			db.Update{Path: "name", Value: wallet.Name},
+			db.Update{Path: "description", Value: wallet.Description},
+			db.Update{Path: "shared_with_tenants", Value: wallet.SharedWithTenants},
+			db.Update{Path: "permissions", Value: wallet.Permissions},
+			db.Update{Path: "read_only", Value: wallet.ReadOnly},
+			db.Update{Path: "updated_at", Value: wallet.UpdatedAt},
+		)
+	})
 	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return nil, entity.Error("wallet not found", "wallet", "Update", entity.ApplicationLayerRepository, entity.ResponseCodeNotFound)
+		}
+		if errors.Is(err, errWalletOwner) {
+			return nil, entity.Error(err.Error(), "wallet", "Update", entity.ApplicationLayerRepository, entity.ResponseCodeUnauthorized)
+		}
 		return nil, entity.Error(err.Error(), "wallet", "Update", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
 	}
 
-	return &updatedWallet, nil
+	return updated, nil
 }
 
 func (w *WalletRepo) Delete(ctx context.Context, userId *string, id *string) *entity.ModuleError {
 
-	_, err := w.db.Collection("wallets").Doc(*id).Delete(context.Background())
+	err := w.db.Collection("wallets").Doc(*id).Delete(ctx)
 	if err != nil {
 		return entity.Error(err.Error(), "wallet", "Delete", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
 	}
@@ -130,27 +227,16 @@ func (w *WalletRepo) Delete(ctx context.Context, userId *string, id *string) *en
 	return nil
 }
 
-func (w *WalletRepo) GetByFilterMany(ctx context.Context, userId *string, filter []entity.QueryDB) ([]entity.WalletResponse, *entity.ModuleError) {
-	query := w.db.Collection("wallets").Where("owner_id", "==", *userId)
-	for _, f := range filter {
-		condition := checkFirebaseCondition(&f.Condition)
-		if f.Key != "" && f.Value != "" && condition != "" {
-			query = query.Where(f.Key, condition, f.Value)
-		}
+func (w *WalletRepo) GetByFilterMany(ctx context.Context, userId *string, filter []entity.QueryDBClause) ([]entity.WalletResponse, *entity.ModuleError) {
+	query := applyClauses(w.db.Collection("wallets").Where("owner_id", "==", *userId), filter)
+
+	docs, err := query.Documents(ctx)
+	if err != nil {
+		return nil, entity.Error(err.Error(), "wallet", "GetByFilterMany", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
 	}
 
-	iter := query.Documents(context.Background())
-	defer iter.Stop()
 	var wallets []entity.WalletResponse
-	for {
-		doc, err := iter.Next()
-		if err == iterator.Done {
-			break
-		}
-		if err != nil {
-			return nil, entity.Error(err.Error(), "wallet", "GetByFilterMany", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
-		}
-
+	for _, doc := range docs {
 		var wallet entity.WalletResponse
 		err = doc.DataTo(&wallet)
 		if err != nil {
@@ -163,30 +249,19 @@ func (w *WalletRepo) GetByFilterMany(ctx context.Context, userId *string, filter
 
 func (w *WalletRepo) GetByFilterOne(ctx context.Context, userId *string, filter []entity.QueryDB) (*entity.WalletResponse, *entity.ModuleError) {
 
-	query := w.db.Collection("wallets").Where("owner_id", "==", *userId)
-	for _, f := range filter {
-		condition := checkFirebaseCondition(&f.Condition)
-		if f.Key != "" && f.Value != "" && condition != "" {
-			query = query.Where(f.Key, condition, f.Value)
-		}
-	}
-
-	doc := query.Limit(1).Documents(context.Background())
+	query := applyFilter(w.db.Collection("wallets").Where("owner_id", "==", *userId), filter)
 
-	result, err := doc.Next()
+	docs, err := query.Limit(1).Documents(ctx)
 	if err != nil {
-		if err == iterator.Done {
-			return nil, nil
-		}
 		return nil, entity.Error(err.Error(), "wallet", "GetByFilterOne", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
 	}
 
-	if result == nil {
+	if len(docs) == 0 {
 		return nil, nil
 	}
 
 	var wallet entity.WalletResponse
-	err = result.DataTo(&wallet)
+	err = docs[0].DataTo(&wallet)
 	if err != nil {
 		return nil, entity.Error(err.Error(), "wallet", "GetByFilterOne", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
 	}
@@ -195,16 +270,17 @@ func (w *WalletRepo) GetByFilterOne(ctx context.Context, userId *string, filter
 }
 
 func (w *WalletRepo) GetByID(ctx context.Context, walletId *string) (*entity.WalletResponse, *entity.ModuleError) {
-	doc := w.db.Collection("wallets").Where("id", "==", *walletId).Documents(context.Background())
-	defer doc.Stop()
+	docs, err := w.db.Collection("wallets").Where("id", "==", *walletId).Limit(1).Documents(ctx)
+	if err != nil {
+		return nil, entity.Error(err.Error(), "wallet", "GetById", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
 
-	data, _ := doc.Next()
-	if data == nil {
+	if len(docs) == 0 {
 		return &entity.WalletResponse{}, nil
 	}
 
 	var wallet entity.WalletResponse
-	err := data.DataTo(&wallet)
+	err = docs[0].DataTo(&wallet)
 	if err != nil {
 		return nil, entity.Error(err.Error(), "wallet", "GetById", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
 	}
@@ -212,6 +288,42 @@ func (w *WalletRepo) GetByID(ctx context.Context, walletId *string) (*entity.Wal
 	return &wallet, nil
 }
 
-func (w *WalletRepo) UpdateBalance(ctx context.Context, walletID *string, balance *float64) (*entity.WalletResponse, *entity.ModuleError) {
-	return nil, nil
+// UpdateBalance applies a signed delta to the wallet balance
+// The read-modify-write runs in a transaction, so concurrent writers are retried instead of losing updates
+// It returns the wallet after the update
+func (w *WalletRepo) UpdateBalance(ctx context.Context, walletID *string, delta *entity.Money) (*entity.WalletResponse, *entity.ModuleError) {
+
+	docRef := w.db.Collection("wallets").Doc(*walletID)
+
+	var updated *entity.WalletResponse
+	err := w.db.RunTransaction(ctx, func(ctx context.Context, tx db.Transaction) error {
+		doc, err := tx.Get(docRef)
+		if err != nil {
+			return err
+		}
+
+		var wallet entity.WalletResponse
+		if err := doc.DataTo(&wallet); err != nil {
+			return err
+		}
+
+		if err := wallet.ApplyBalance(*delta); err != nil {
+			return err
+		}
+		wallet.SetUpdate()
+
+		updated = &wallet
+		return tx.Update(docRef,
+			db.Update{Path: "balance", Value: wallet.Balance},
+			db.Update{Path: "updated_at", Value: wallet.UpdatedAt},
+		)
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return nil, entity.Error("wallet not found", "wallet", "UpdateBalance", entity.ApplicationLayerRepository, entity.ResponseCodeNotFound)
+		}
+		return nil, entity.Error(err.Error(), "wallet", "UpdateBalance", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+
+	return updated, nil
 }
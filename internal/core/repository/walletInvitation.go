@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/pkg/db"
+)
+
+const walletInvitationsCollection = "wallet_invitations"
+
+type IWalletInvitationRepo interface {
+	// SaveInvitation creates or replaces the invitation
+	SaveInvitation(ctx context.Context, data *entity.WalletInvitation) *entity.ModuleError
+	// GetInvitation returns nil when the invitation does not exist
+	GetInvitation(ctx context.Context, id string) (*entity.WalletInvitation, *entity.ModuleError)
+	// GetInvitationsByWallet returns the invitations of the wallet, the newest first
+	GetInvitationsByWallet(ctx context.Context, walletId string) ([]entity.WalletInvitation, *entity.ModuleError)
+	// GetInvitationsByEmail returns the invitations to the email, the newest first
+	GetInvitationsByEmail(ctx context.Context, email string) ([]entity.WalletInvitation, *entity.ModuleError)
+}
+
+type WalletInvitationRepo struct {
+	db db.DocumentStore
+}
+
+// NewWalletInvitationRepo creates the repository of the invitations to the wallets of the database kind
+func NewWalletInvitationRepo(database db.Database) (IWalletInvitationRepo, error) {
+	switch conn := database.(type) {
+	case *db.SQLDatabase:
+		return NewWalletInvitationSQLRepo(conn)
+	case db.DocumentStore:
+		return &WalletInvitationRepo{db: conn}, nil
+	}
+
+	return nil, errors.New("database is required")
+}
+
+func (w *WalletInvitationRepo) SaveInvitation(ctx context.Context, data *entity.WalletInvitation) *entity.ModuleError {
+	if err := w.db.Collection(walletInvitationsCollection).Doc(data.ID).Set(ctx, *data); err != nil {
+		return entity.Error(err.Error(), "wallet", "SaveInvitation", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+	return nil
+}
+
+func (w *WalletInvitationRepo) GetInvitation(ctx context.Context, id string) (*entity.WalletInvitation, *entity.ModuleError) {
+	doc, err := w.db.Collection(walletInvitationsCollection).Doc(id).Get(ctx)
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, entity.Error(err.Error(), "wallet", "GetInvitation", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+
+	var invitation entity.WalletInvitation
+	if err := doc.DataTo(&invitation); err != nil {
+		return nil, entity.Error(err.Error(), "wallet", "GetInvitation", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+	return &invitation, nil
+}
+
+func (w *WalletInvitationRepo) GetInvitationsByWallet(ctx context.Context, walletId string) ([]entity.WalletInvitation, *entity.ModuleError) {
+	return w.list(ctx, "GetInvitationsByWallet", "wallet_id", walletId)
+}
+
+func (w *WalletInvitationRepo) GetInvitationsByEmail(ctx context.Context, email string) ([]entity.WalletInvitation, *entity.ModuleError) {
+	return w.list(ctx, "GetInvitationsByEmail", "email", email)
+}
+
+func (w *WalletInvitationRepo) list(ctx context.Context, method, path, value string) ([]entity.WalletInvitation, *entity.ModuleError) {
+	docs, err := w.db.Collection(walletInvitationsCollection).Where(path, db.OpEqual, value).OrderBy("create_at", db.Desc).Documents(ctx)
+	if err != nil {
+		return nil, entity.Error(err.Error(), "wallet", method, entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+
+	invitations := make([]entity.WalletInvitation, 0, len(docs))
+	for _, doc := range docs {
+		var invitation entity.WalletInvitation
+		if err := doc.DataTo(&invitation); err != nil {
+			return nil, entity.Error(err.Error(), "wallet", method, entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+		}
+		invitations = append(invitations, invitation)
+	}
+	return invitations, nil
+}
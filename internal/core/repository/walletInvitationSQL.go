@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/pkg/db"
+)
+
+const walletInvitationColumns = `id, wallet_id, wallet_name, invited_by, email, level, status, expires_at, create_at, responded_at`
+
+type WalletInvitationSQLRepo struct {
+	db *db.SQLDatabase
+}
+
+// NewWalletInvitationSQLRepo creates the repository of the invitations to the wallets of a relational database
+func NewWalletInvitationSQLRepo(database *db.SQLDatabase) (IWalletInvitationRepo, error) {
+	if database == nil {
+		return nil, errors.New("database is required")
+	}
+
+	return &WalletInvitationSQLRepo{db: database}, nil
+}
+
+func (w *WalletInvitationSQLRepo) SaveInvitation(ctx context.Context, data *entity.WalletInvitation) *entity.ModuleError {
+	_, err := w.db.DB.ExecContext(ctx, w.db.Rebind(`INSERT INTO wallet_invitations (`+walletInvitationColumns+`)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (id) DO UPDATE SET
+    wallet_name = excluded.wallet_name,
+    level = excluded.level,
+    status = excluded.status,
+    expires_at = excluded.expires_at,
+    responded_at = excluded.responded_at`),
+		data.ID, data.WalletID, data.WalletName, data.InvitedBy, data.Email, data.Level, data.Status, data.ExpiresAt, data.CreatedAt, data.RespondedAt)
+	if err != nil {
+		return entity.Error(err.Error(), "wallet", "SaveInvitation", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+	return nil
+}
+
+func (w *WalletInvitationSQLRepo) GetInvitation(ctx context.Context, id string) (*entity.WalletInvitation, *entity.ModuleError) {
+	invitations, err := w.query(ctx, `WHERE id = ?`, id)
+	if err != nil {
+		return nil, entity.Error(err.Error(), "wallet", "GetInvitation", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+	if len(invitations) == 0 {
+		return nil, nil
+	}
+	return &invitations[0], nil
+}
+
+func (w *WalletInvitationSQLRepo) GetInvitationsByWallet(ctx context.Context, walletId string) ([]entity.WalletInvitation, *entity.ModuleError) {
+	invitations, err := w.query(ctx, `WHERE wallet_id = ? ORDER BY create_at DESC`, walletId)
+	if err != nil {
+		return nil, entity.Error(err.Error(), "wallet", "GetInvitationsByWallet", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+	return invitations, nil
+}
+
+func (w *WalletInvitationSQLRepo) GetInvitationsByEmail(ctx context.Context, email string) ([]entity.WalletInvitation, *entity.ModuleError) {
+	invitations, err := w.query(ctx, `WHERE email = ? ORDER BY create_at DESC`, email)
+	if err != nil {
+		return nil, entity.Error(err.Error(), "wallet", "GetInvitationsByEmail", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+	return invitations, nil
+}
+
+func (w *WalletInvitationSQLRepo) query(ctx context.Context, where string, args ...any) ([]entity.WalletInvitation, error) {
+	rows, err := w.db.DB.QueryContext(ctx, w.db.Rebind(`SELECT `+walletInvitationColumns+` FROM wallet_invitations `+where), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invitations := []entity.WalletInvitation{}
+	for rows.Next() {
+		var i entity.WalletInvitation
+		if err := rows.Scan(&i.ID, &i.WalletID, &i.WalletName, &i.InvitedBy, &i.Email, &i.Level, &i.Status,
+			&i.ExpiresAt, &i.CreatedAt, &i.RespondedAt); err != nil {
+			return nil, err
+		}
+		invitations = append(invitations, i)
+	}
+	return invitations, rows.Err()
+}
@@ -0,0 +1,316 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/pkg/db"
+)
+
+var walletTable = sqlTable{
+	name: "wallets",
+	columns: map[string]sqlKind{
+		"id":                  sqlText,
+		"name":                sqlText,
+		"description":         sqlText,
+		"owner_id":            sqlText,
+		"tenant_id":           sqlText,
+		"balance":             sqlDecimal,
+		"currency":            sqlText,
+		"shared_with_tenants": sqlArray,
+		"read_only":           sqlBoolean,
+		"created_at":          sqlTime,
+		"updated_at":          sqlTime,
+	},
+}
+
+const walletColumns = `id, name, description, owner_id, tenant_id, balance, currency, shared_with_tenants, permissions, read_only, created_at, updated_at`
+
+type WalletSQLRepo struct {
+	db *db.SQLDatabase
+}
+
+// NewWalletSQLRepo creates the wallet repository of a relational database
+func NewWalletSQLRepo(database *db.SQLDatabase) (IWalletRepo, error) {
+	if database == nil {
+		return nil, errors.New("database is required")
+	}
+
+	return &WalletSQLRepo{db: database}, nil
+}
+
+// Create inserts the wallet, a stored wallet of the id is a conflict and is not replaced
+func (w *WalletSQLRepo) Create(ctx context.Context, userId *string, data *entity.WalletResponse) (*entity.WalletResponse, *entity.ModuleError) {
+
+	err := w.insert(ctx, data)
+	if errors.Is(err, errWalletExists) {
+		return nil, entity.Error(err.Error(), "wallet", "Create", entity.ApplicationLayerRepository, entity.ResponseCodeConflict)
+	}
+	if err != nil {
+		return nil, entity.Error(err.Error(), "wallet", "Create", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+
+	return w.GetWalletByIdAndUserID(ctx, userId, &data.ID)
+}
+
+func (w *WalletSQLRepo) Get(ctx context.Context, userId *string, page *entity.PageRequest) (*entity.Page[entity.WalletResponse], *entity.ModuleError) {
+	wallets, err := sqlPage(ctx, w.db, walletTable, page, `owner_id = ?`, []any{*userId}, w.query)
+	if err != nil {
+		return nil, entity.Error(err.Error(), "wallet", "Get", entity.ApplicationLayerRepository, pageErrorCode(err))
+	}
+	return wallets, nil
+}
+
+func (w *WalletSQLRepo) GetAccessible(ctx context.Context, userId *string, tenantId *string, page *entity.PageRequest) (*entity.Page[entity.WalletResponse], *entity.ModuleError) {
+	where, args := w.accessible(*userId, *tenantId)
+	wallets, err := sqlPage(ctx, w.db, walletTable, page, where, args, w.query)
+	if err != nil {
+		return nil, entity.Error(err.Error(), "wallet", "GetAccessible", entity.ApplicationLayerRepository, pageErrorCode(err))
+	}
+	return wallets, nil
+}
+
+func (w *WalletSQLRepo) GetAccessibleByFilter(ctx context.Context, userId *string, tenantId *string, filter []entity.QueryDBClause) ([]entity.WalletResponse, *entity.ModuleError) {
+	where, args, err := walletTable.whereClauses(w.db, filter)
+	if err != nil {
+		return nil, entity.Error(err.Error(), "wallet", "GetAccessibleByFilter", entity.ApplicationLayerRepository, sqlErrorCode(err))
+	}
+
+	accessible, accessibleArgs := w.accessible(*userId, *tenantId)
+	wallets, err := w.query(ctx, joinAnd(accessible, where), "", 0, append(accessibleArgs, args...)...)
+	if err != nil {
+		return nil, entity.Error(err.Error(), "wallet", "GetAccessibleByFilter", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+	return wallets, nil
+}
+
+func (w *WalletSQLRepo) GetByTenant(ctx context.Context, tenantId *string) ([]entity.WalletResponse, *entity.ModuleError) {
+	wallets, err := w.query(ctx, `tenant_id = ?`, "", 0, *tenantId)
+	if err != nil {
+		return nil, entity.Error(err.Error(), "wallet", "GetByTenant", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+	return wallets, nil
+}
+
+// accessible is the condition of the wallets of the owner, of the tenant and shared with the tenant
+func (w *WalletSQLRepo) accessible(userId, tenantId string) (string, []any) {
+	return `owner_id = ? OR tenant_id = ? OR ` + w.db.ArrayContains("shared_with_tenants"), []any{userId, tenantId, tenantId}
+}
+
+func (w *WalletSQLRepo) GetWalletByIdAndUserID(ctx context.Context, userId *string, id *string) (*entity.WalletResponse, *entity.ModuleError) {
+	wallets, err := w.query(ctx, `id = ? AND owner_id = ?`, "", 0, *id, *userId)
+	if err != nil {
+		return nil, entity.Error(err.Error(), "wallet", "GetById", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+
+	if len(wallets) == 0 {
+		return &entity.WalletResponse{}, nil
+	}
+
+	return &wallets[0], nil
+}
+
+func (w *WalletSQLRepo) GetByID(ctx context.Context, walletId *string) (*entity.WalletResponse, *entity.ModuleError) {
+	wallets, err := w.query(ctx, `id = ?`, "", 0, *walletId)
+	if err != nil {
+		return nil, entity.Error(err.Error(), "wallet", "GetById", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+
+	if len(wallets) == 0 {
+		return &entity.WalletResponse{}, nil
+	}
+
+	return &wallets[0], nil
+}
+
+// Update writes the fields of the wallet that are changed by the users, the plans and the shares
+// The balance, the currency, the owner and the tenant are kept, the balance changes only through UpdateBalance
+func (w *WalletSQLRepo) Update(ctx context.Context, userId *string, data *entity.WalletResponse) (*entity.WalletResponse, *entity.ModuleError) {
+
+	shared, err := jsonValue(data.SharedWithTenants)
+	if err != nil {
+		return nil, entity.Error(err.Error(), "wallet", "Update", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+
+	permissions, err := jsonValue(data.Permissions)
+	if err != nil {
+		return nil, entity.Error(err.Error(), "wallet", "Update", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+
+	// the owner is part of the condition, so the check and the write are one statement
+	result, err := w.db.DB.ExecContext(ctx, w.db.Rebind(`UPDATE wallets SET name = ?, description = ?, shared_with_tenants = ?, permissions = ?, read_only = ?, updated_at = ?
+WHERE id = ? AND owner_id = ?`),
+		data.Name, data.Description, shared, permissions, data.ReadOnly, data.UpdatedAt, data.ID, *userId)
+	if err != nil {
+		return nil, entity.Error(err.Error(), "wallet", "Update", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		current, mErr := w.GetByID(ctx, &data.ID)
+		if mErr != nil {
+			return nil, mErr
+		}
+		if current.ID == "" {
+			return nil, entity.Error("wallet not found", "wallet", "Update", entity.ApplicationLayerRepository, entity.ResponseCodeNotFound)
+		}
+		return nil, entity.Error("unauthorized: wallet does not belong to the user", "wallet", "Update", entity.ApplicationLayerRepository, entity.ResponseCodeUnauthorized)
+	}
+
+	return w.GetByID(ctx, &data.ID)
+}
+
+func (w *WalletSQLRepo) Delete(ctx context.Context, userId *string, id *string) *entity.ModuleError {
+
+	_, err := w.db.DB.ExecContext(ctx, w.db.Rebind(`DELETE FROM wallets WHERE id = ?`), *id)
+	if err != nil {
+		return entity.Error(err.Error(), "wallet", "Delete", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+
+	return nil
+}
+
+func (w *WalletSQLRepo) GetByFilterMany(ctx context.Context, userId *string, filter []entity.QueryDBClause) ([]entity.WalletResponse, *entity.ModuleError) {
+	where, args, err := walletTable.whereClauses(w.db, filter)
+	if err != nil {
+		return nil, entity.Error(err.Error(), "wallet", "GetByFilterMany", entity.ApplicationLayerRepository, sqlErrorCode(err))
+	}
+
+	wallets, err := w.query(ctx, joinAnd(`owner_id = ?`, where), "", 0, append([]any{*userId}, args...)...)
+	if err != nil {
+		return nil, entity.Error(err.Error(), "wallet", "GetByFilterMany", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+	return wallets, nil
+}
+
+func (w *WalletSQLRepo) GetByFilterOne(ctx context.Context, userId *string, filter []entity.QueryDB) (*entity.WalletResponse, *entity.ModuleError) {
+	where, args, err := walletTable.where(w.db, filter)
+	if err != nil {
+		return nil, entity.Error(err.Error(), "wallet", "GetByFilterOne", entity.ApplicationLayerRepository, sqlErrorCode(err))
+	}
+
+	wallets, err := w.query(ctx, joinAnd(`owner_id = ?`, where), "", 1, append([]any{*userId}, args...)...)
+	if err != nil {
+		return nil, entity.Error(err.Error(), "wallet", "GetByFilterOne", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+
+	if len(wallets) == 0 {
+		return nil, nil
+	}
+
+	return &wallets[0], nil
+}
+
+// UpdateBalance applies a signed delta to the wallet balance
+// The balance is read and written in a database transaction, so concurrent writers do not lose updates
+// It returns the wallet after the update
+func (w *WalletSQLRepo) UpdateBalance(ctx context.Context, walletID *string, delta *entity.Money) (*entity.WalletResponse, *entity.ModuleError) {
+
+	tx, err := w.db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, entity.Error(err.Error(), "wallet", "UpdateBalance", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+	defer tx.Rollback()
+
+	wallet, err := lockWallet(ctx, w.db, tx, *walletID)
+	if err != nil {
+		if errors.Is(err, errWalletNotFound) {
+			return nil, entity.Error(err.Error(), "wallet", "UpdateBalance", entity.ApplicationLayerRepository, entity.ResponseCodeNotFound)
+		}
+		return nil, entity.Error(err.Error(), "wallet", "UpdateBalance", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+
+	if err := applyWalletBalance(ctx, w.db, tx, wallet, *delta); err != nil {
+		return nil, entity.Error(err.Error(), "wallet", "UpdateBalance", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, entity.Error(err.Error(), "wallet", "UpdateBalance", entity.ApplicationLayerRepository, entity.ResponseCodeInternalServer)
+	}
+
+	return wallet, nil
+}
+
+// lockWallet reads the wallet in the database transaction, after locking its row
+func lockWallet(ctx context.Context, database *db.SQLDatabase, tx *sql.Tx, walletID string) (*entity.WalletResponse, error) {
+	// the no-op update locks the row, in SQLite it takes the write lock before the read
+	result, err := tx.ExecContext(ctx, database.Rebind(`UPDATE wallets SET balance = balance WHERE id = ?`), walletID)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return nil, errWalletNotFound
+	}
+
+	return scanWallet(tx.QueryRowContext(ctx, database.Rebind(walletTable.selectQuery(walletColumns, `id = ?`, "", 0)), walletID))
+}
+
+// applyWalletBalance adds the delta to the balance of the wallet read by lockWallet and writes it in the database transaction
+func applyWalletBalance(ctx context.Context, database *db.SQLDatabase, tx *sql.Tx, wallet *entity.WalletResponse, delta entity.Money) error {
+	if err := wallet.ApplyBalance(delta); err != nil {
+		return err
+	}
+	wallet.SetUpdate()
+
+	_, err := tx.ExecContext(ctx, database.Rebind(`UPDATE wallets SET balance = ?, updated_at = ? WHERE id = ?`), wallet.Balance.Decimal(), wallet.UpdatedAt, wallet.ID)
+	return err
+}
+
+// insert adds the wallet, an id that is already stored is a conflict
+func (w *WalletSQLRepo) insert(ctx context.Context, data *entity.WalletResponse) error {
+	shared, err := jsonValue(data.SharedWithTenants)
+	if err != nil {
+		return err
+	}
+
+	permissions, err := jsonValue(data.Permissions)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.db.DB.ExecContext(ctx, w.db.Rebind(`INSERT INTO wallets (`+walletColumns+`)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`),
+		data.ID, data.Name, data.Description, data.OwnerID, data.TenantID, data.Balance.Decimal(), data.Currency, shared, permissions, data.ReadOnly, data.CreatedAt, data.UpdatedAt)
+	if db.IsUniqueViolation(err) {
+		return errWalletExists
+	}
+	return err
+}
+
+func (w *WalletSQLRepo) query(ctx context.Context, where, order string, limit int, args ...any) ([]entity.WalletResponse, error) {
+	rows, err := w.db.DB.QueryContext(ctx, w.db.Rebind(walletTable.selectQuery(walletColumns, where, order, limit)), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var wallets []entity.WalletResponse
+	for rows.Next() {
+		wallet, err := scanWallet(rows)
+		if err != nil {
+			return nil, err
+		}
+		wallets = append(wallets, *wallet)
+	}
+	return wallets, rows.Err()
+}
+
+// sqlScanner is a *sql.Row or *sql.Rows
+type sqlScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanWallet(row sqlScanner) (*entity.WalletResponse, error) {
+	var wallet entity.WalletResponse
+	err := row.Scan(&wallet.ID, &wallet.Name, &wallet.Description, &wallet.OwnerID, &wallet.TenantID,
+		sqlMoney{&wallet.Balance}, &wallet.Currency, sqlJSON{&wallet.SharedWithTenants}, sqlJSON{&wallet.Permissions}, &wallet.ReadOnly, &wallet.CreatedAt, &wallet.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := wallet.AfterDocumentLoad(); err != nil {
+		return nil, err
+	}
+	return &wallet, nil
+}
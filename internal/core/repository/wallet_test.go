@@ -0,0 +1,370 @@
+package repository_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/internal/core/repository"
+	"github.com/Tomelin/financial-management-backend/pkg/db"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+type WalletRepoTestSuite struct {
+	suite.Suite
+	database func() db.Database
+	repo     repository.IWalletRepo
+	userID   string
+	ctx      context.Context
+}
+
+func (s *WalletRepoTestSuite) SetupTest() {
+	repo, err := repository.NewWalletRepo(s.database())
+	s.Require().Nil(err)
+
+	s.repo = repo
+	s.userID = uuid.New().String()
+	s.ctx = context.Background()
+}
+
+func (s *WalletRepoTestSuite) newWallet(name string) *entity.WalletResponse {
+	wallet, mErr := entity.NewWallet(&entity.WalletResponse{
+		Name:     name,
+		OwnerID:  s.userID,
+		TenantID: uuid.New().String(),
+		Currency: "BRL",
+	})
+	s.Require().Nil(mErr)
+
+	created, mErr := s.repo.Create(s.ctx, &s.userID, wallet)
+	s.Require().Nil(mErr)
+	return created
+}
+
+func (s *WalletRepoTestSuite) TestNewWalletRepo_NilDatabase() {
+	_, err := repository.NewWalletRepo(nil)
+	s.NotNil(err)
+}
+
+func (s *WalletRepoTestSuite) TestCreateAndGet() {
+	created := s.newWallet("MyWallet")
+	s.Equal("MyWallet", created.Name)
+	s.Equal(entity.NewMoney(0, "BRL"), created.Balance)
+
+	s.newWallet("Other")
+
+	wallets, mErr := s.repo.Get(s.ctx, &s.userID, entity.DefaultPage())
+	s.Nil(mErr)
+	s.Len(wallets.Items, 2)
+	s.Equal(int64(2), *wallets.Total)
+	s.Empty(wallets.NextCursor)
+
+	otherUser := uuid.New().String()
+	wallets, mErr = s.repo.Get(s.ctx, &otherUser, entity.DefaultPage())
+	s.Nil(mErr)
+	s.Empty(wallets.Items)
+}
+
+func (s *WalletRepoTestSuite) TestCreate_Conflict() {
+	created := s.newWallet("MyWallet")
+
+	wallet := *created
+	wallet.Name = "Other"
+	wallet.OwnerID = uuid.New().String()
+	_, mErr := s.repo.Create(s.ctx, &wallet.OwnerID, &wallet)
+	s.Require().NotNil(mErr, "a create does not replace a stored wallet")
+	s.Equal(entity.ResponseCodeConflict, mErr.Code)
+
+	stored, mErr := s.repo.GetByID(s.ctx, &created.ID)
+	s.Require().Nil(mErr)
+	s.Equal("MyWallet", stored.Name)
+	s.Equal(s.userID, stored.OwnerID)
+}
+
+func (s *WalletRepoTestSuite) TestGet_Pages() {
+	for _, name := range []string{"c", "a", "e", "b", "d"} {
+		s.newWallet(name)
+	}
+
+	names := func(page *entity.PageRequest) []string {
+		var result []string
+		for pages := 0; ; pages++ {
+			s.Require().Less(pages, 3)
+
+			wallets, mErr := s.repo.Get(s.ctx, &s.userID, page)
+			s.Require().Nil(mErr)
+			s.Equal(int64(5), *wallets.Total)
+
+			for _, wallet := range wallets.Items {
+				result = append(result, wallet.Name)
+			}
+			if wallets.NextCursor == "" {
+				return result
+			}
+			page.Cursor = wallets.NextCursor
+		}
+	}
+
+	s.Equal([]string{"e", "d", "c", "b", "a"}, names(&entity.PageRequest{Limit: 2, OrderBy: "name", Direction: entity.PageDesc}))
+	s.Equal([]string{"c", "a", "e", "b", "d"}, names(&entity.PageRequest{Limit: 2, OrderBy: "created_at", Direction: entity.PageAsc}))
+	s.ElementsMatch([]string{"a", "b", "c", "d", "e"}, names(&entity.PageRequest{Limit: 2, Direction: entity.PageAsc}))
+
+	// the cursor only works with the order that created it
+	page := &entity.PageRequest{Limit: 2, OrderBy: "name", Direction: entity.PageAsc}
+	wallets, mErr := s.repo.Get(s.ctx, &s.userID, page)
+	s.Require().Nil(mErr)
+
+	page.Cursor = wallets.NextCursor
+	page.OrderBy = "created_at"
+	_, mErr = s.repo.Get(s.ctx, &s.userID, page)
+	s.Require().NotNil(mErr)
+	s.Equal(entity.ResponseCodeBadRequest, mErr.Code)
+
+	page.Cursor = "invalid"
+	_, mErr = s.repo.Get(s.ctx, &s.userID, page)
+	s.Require().NotNil(mErr)
+	s.Equal(entity.ResponseCodeBadRequest, mErr.Code)
+}
+
+func (s *WalletRepoTestSuite) TestGetByID_NotFound() {
+	id := uuid.New().String()
+	wallet, mErr := s.repo.GetByID(s.ctx, &id)
+	s.Nil(mErr)
+	s.Empty(wallet.ID)
+}
+
+func (s *WalletRepoTestSuite) TestUpdate_OtherUser() {
+	created := s.newWallet("MyWallet")
+
+	otherUser := uuid.New().String()
+	created.Name = "Changed"
+	_, mErr := s.repo.Update(s.ctx, &otherUser, created)
+	s.NotNil(mErr)
+	s.Equal(entity.ResponseCodeUnauthorized, mErr.Code)
+}
+
+func (s *WalletRepoTestSuite) TestUpdate_KeepsBalance() {
+	created := s.newWallet("MyWallet")
+	delta := entity.NewMoney(150, "BRL")
+	_, mErr := s.repo.UpdateBalance(s.ctx, &created.ID, &delta)
+	s.Require().Nil(mErr)
+
+	// the wallet read before the balance changed
+	created.Name = "Changed"
+	created.Currency = "USD"
+	created.SetUpdate()
+	updated, mErr := s.repo.Update(s.ctx, &s.userID, created)
+	s.Require().Nil(mErr)
+	s.Equal("Changed", updated.Name)
+
+	wallet, mErr := s.repo.GetByID(s.ctx, &created.ID)
+	s.Require().Nil(mErr)
+	s.Equal("Changed", wallet.Name)
+	s.Equal(entity.NewMoney(150, "BRL"), wallet.Balance, "the update does not write the balance")
+	s.Equal("BRL", wallet.Currency)
+
+	id := uuid.New().String()
+	_, mErr = s.repo.Update(s.ctx, &s.userID, &entity.WalletResponse{ID: id})
+	s.Require().NotNil(mErr)
+	s.Equal(entity.ResponseCodeNotFound, mErr.Code)
+}
+
+func (s *WalletRepoTestSuite) TestGetByFilter() {
+	s.newWallet("MyWallet")
+	s.newWallet("Other")
+
+	filter := []entity.QueryDB{{Key: "name", Value: "Other", Condition: "=="}}
+	wallets, mErr := s.repo.GetByFilterMany(s.ctx, &s.userID, entity.AndClause(filter))
+	s.Nil(mErr)
+	s.Len(wallets, 1)
+	s.Equal("Other", wallets[0].Name)
+
+	wallet, mErr := s.repo.GetByFilterOne(s.ctx, &s.userID, filter)
+	s.Nil(mErr)
+	s.Equal("Other", wallet.Name)
+
+	wallet, mErr = s.repo.GetByFilterOne(s.ctx, &s.userID, []entity.QueryDB{{Key: "name", Value: "none"}})
+	s.Nil(mErr)
+	s.Nil(wallet)
+}
+
+func (s *WalletRepoTestSuite) TestGetByFilter_Clauses() {
+	s.newWallet("MyWallet")
+	s.newWallet("Other")
+	s.newWallet("Third")
+
+	filter, err := entity.ParseQueryFilters([]string{"or(name:eq:MyWallet,name:eq:Third,and(name:eq:MyWallet,currency:eq:BRL))"})
+	s.Require().Nil(err)
+
+	wallets, mErr := s.repo.GetByFilterMany(s.ctx, &s.userID, filter)
+	s.Nil(mErr)
+	names := []string{}
+	for _, wallet := range wallets {
+		names = append(names, wallet.Name)
+	}
+	s.ElementsMatch([]string{"MyWallet", "Third"}, names)
+
+	filter, err = entity.ParseQueryFilters([]string{"name:in:MyWallet|Other", "name:ne:Other"})
+	s.Require().Nil(err)
+
+	wallets, mErr = s.repo.GetByFilterMany(s.ctx, &s.userID, filter)
+	s.Nil(mErr)
+	s.Require().Len(wallets, 1)
+	s.Equal("MyWallet", wallets[0].Name)
+}
+
+func (s *WalletRepoTestSuite) TestGetByFilter_Typed() {
+	for name, amount := range map[string]int64{"low": 500, "high": 15000} {
+		created := s.newWallet(name)
+		delta := entity.NewMoney(amount, "BRL")
+		_, mErr := s.repo.UpdateBalance(s.ctx, &created.ID, &delta)
+		s.Require().Nil(mErr)
+	}
+
+	names := func(filter string) []string {
+		clauses, err := entity.ParseQueryFilters([]string{filter})
+		s.Require().Nil(err)
+		s.Require().Nil(entity.WalletFields.Coerce(clauses))
+
+		wallets, mErr := s.repo.GetByFilterMany(s.ctx, &s.userID, clauses)
+		s.Require().Nil(mErr)
+		result := []string{}
+		for _, wallet := range wallets {
+			result = append(result, wallet.Name)
+		}
+		return result
+	}
+
+	s.ElementsMatch([]string{"high"}, names("balance:gt:99.5"))
+	s.ElementsMatch([]string{"low"}, names("balance:lte:5"))
+	s.ElementsMatch([]string{"low", "high"}, names("created_at:gte:2000-01-01"))
+	s.Empty(names("created_at:lt:2000-01-01"))
+}
+
+func (s *WalletRepoTestSuite) TestDelete() {
+	created := s.newWallet("MyWallet")
+
+	s.Nil(s.repo.Delete(s.ctx, &s.userID, &created.ID))
+
+	wallet, mErr := s.repo.GetByID(s.ctx, &created.ID)
+	s.Nil(mErr)
+	s.Empty(wallet.ID)
+}
+
+func (s *WalletRepoTestSuite) TestUpdateBalance_Concurrent() {
+	created := s.newWallet("MyWallet")
+
+	const writers = 20
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			delta := entity.NewMoney(150, "BRL")
+			_, mErr := s.repo.UpdateBalance(s.ctx, &created.ID, &delta)
+			s.Nil(mErr)
+		}()
+	}
+	wg.Wait()
+
+	wallet, mErr := s.repo.GetByID(s.ctx, &created.ID)
+	s.Nil(mErr)
+	s.Equal(entity.NewMoney(150*writers, "BRL"), wallet.Balance)
+}
+
+func (s *WalletRepoTestSuite) TestUpdateBalance_NotFound() {
+	id := uuid.New().String()
+	delta := entity.NewMoney(100, "BRL")
+
+	_, mErr := s.repo.UpdateBalance(s.ctx, &id, &delta)
+	s.NotNil(mErr)
+	s.Equal(entity.ResponseCodeNotFound, mErr.Code)
+}
+
+func (s *WalletRepoTestSuite) TestGetAccessible() {
+	owned := s.newWallet("Owned")
+	tenantID := uuid.New().String()
+
+	otherUser := uuid.New().String()
+	other := func(name string, share func(w *entity.WalletResponse)) {
+		wallet, mErr := entity.NewWallet(&entity.WalletResponse{Name: name, OwnerID: otherUser, TenantID: uuid.New().String(), Currency: "BRL"})
+		s.Require().Nil(mErr)
+		share(wallet)
+		_, mErr = s.repo.Create(s.ctx, &otherUser, wallet)
+		s.Require().Nil(mErr)
+	}
+	other("Shared", func(w *entity.WalletResponse) { s.Require().NoError(w.Grant(tenantID, entity.PermissionEdit)) })
+	other("Tenant", func(w *entity.WalletResponse) { w.TenantID = tenantID })
+	other("Hidden", func(w *entity.WalletResponse) {
+		s.Require().NoError(w.Grant(uuid.New().String(), entity.PermissionView))
+	})
+
+	wallets, mErr := s.repo.GetAccessible(s.ctx, &s.userID, &tenantID, entity.DefaultPage())
+	s.Require().Nil(mErr)
+	s.Equal(int64(3), *wallets.Total)
+	names := []string{}
+	for _, wallet := range wallets.Items {
+		names = append(names, wallet.Name)
+		if wallet.Name == "Shared" {
+			s.Equal(entity.PermissionEdit, wallet.PermissionOf(s.userID, tenantID), "the levels are stored")
+		}
+	}
+	s.ElementsMatch([]string{"Owned", "Shared", "Tenant"}, names)
+
+	filter, err := entity.ParseQueryFilters([]string{"name:ne:Tenant"})
+	s.Require().Nil(err)
+	found, mErr := s.repo.GetAccessibleByFilter(s.ctx, &s.userID, &tenantID, filter)
+	s.Require().Nil(mErr)
+	names = []string{}
+	for _, wallet := range found {
+		names = append(names, wallet.Name)
+	}
+	s.ElementsMatch([]string{owned.Name, "Shared"}, names)
+}
+
+func (s *WalletRepoTestSuite) TestInvitations() {
+	repo, err := repository.NewWalletInvitationRepo(s.database())
+	s.Require().NoError(err)
+
+	wallet := s.newWallet("MyWallet")
+	first, mErr := entity.NewWalletInvitation(wallet, s.userID, &entity.WalletInvitationRequest{Email: "first@domain.com", Level: entity.PermissionView})
+	s.Require().Nil(mErr)
+	s.Require().Nil(repo.SaveInvitation(s.ctx, first))
+	time.Sleep(2 * time.Millisecond)
+	second, mErr := entity.NewWalletInvitation(wallet, s.userID, &entity.WalletInvitationRequest{Email: "second@domain.com", Level: entity.PermissionAdmin})
+	s.Require().Nil(mErr)
+	s.Require().Nil(repo.SaveInvitation(s.ctx, second))
+
+	invitations, mErr := repo.GetInvitationsByWallet(s.ctx, wallet.ID)
+	s.Require().Nil(mErr)
+	s.Require().Len(invitations, 2)
+	s.Equal(second.ID, invitations[0].ID, "the newest first")
+
+	first.Respond(entity.InvitationAccepted, time.Now().UTC())
+	s.Require().Nil(repo.SaveInvitation(s.ctx, first))
+	got, mErr := repo.GetInvitation(s.ctx, first.ID)
+	s.Require().Nil(mErr)
+	s.Equal(entity.InvitationAccepted, got.Status)
+	s.Equal(entity.PermissionView, got.Level)
+	s.False(got.RespondedAt.IsZero())
+
+	invitations, mErr = repo.GetInvitationsByEmail(s.ctx, "second@domain.com")
+	s.Require().Nil(mErr)
+	s.Require().Len(invitations, 1)
+	s.Equal(second.ID, invitations[0].ID)
+
+	got, mErr = repo.GetInvitation(s.ctx, uuid.New().String())
+	s.Nil(mErr)
+	s.Nil(got)
+}
+
+func TestRunWalletRepoTestSuite(t *testing.T) {
+	suite.Run(t, &WalletRepoTestSuite{database: func() db.Database { return db.NewMemoryStore() }})
+}
+
+func TestRunWalletSQLRepoTestSuite(t *testing.T) {
+	suite.Run(t, &WalletRepoTestSuite{database: func() db.Database { return newSQLiteDatabase(t) }})
+}
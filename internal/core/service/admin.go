@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/internal/core/repository"
+)
+
+type AdminSvc struct {
+	tenant  ITenantService
+	user    entity.IUser
+	wallets repository.IWalletRepo
+}
+
+// NewAdminSvc creates the service of the system admins over the tenants
+func NewAdminSvc(tenant ITenantService, user entity.IUser, wallets repository.IWalletRepo) (entity.IAdmin, *entity.ModuleError) {
+	if tenant == nil || user == nil || wallets == nil {
+		return nil, entity.Error("tenant, user and wallets are required", "admin", "inicialization", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	}
+
+	return &AdminSvc{
+		tenant:  tenant,
+		user:    user,
+		wallets: wallets,
+	}, nil
+}
+
+func (a *AdminSvc) GetTenants(ctx context.Context, page *entity.PageRequest) (*entity.Page[entity.TenantUsage], *entity.ModuleError) {
+
+	tenants, err := a.tenant.Get(page)
+	if errors.Is(err, entity.ErrInvalidPage) {
+		return nil, entity.Error(err.Error(), "admin", "GetTenants", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	}
+	if err != nil {
+		return nil, entity.Error(err.Error(), "admin", "GetTenants", entity.ApplicationLayerService, entity.ResponseCodeInternalServer)
+	}
+
+	result := &entity.Page[entity.TenantUsage]{
+		Items:      make([]entity.TenantUsage, 0, len(tenants.Items)),
+		NextCursor: tenants.NextCursor,
+		Total:      tenants.Total,
+	}
+
+	for i := range tenants.Items {
+		usage, mErr := a.usage(ctx, &tenants.Items[i])
+		if mErr != nil {
+			return nil, mErr
+		}
+		result.Items = append(result.Items, *usage)
+	}
+
+	return result, nil
+}
+
+func (a *AdminSvc) SuspendTenant(ctx context.Context, tenantId *string, data *entity.SuspendRequest) (*entity.TenantResponse, *entity.ModuleError) {
+
+	if data == nil || data.Reason == "" {
+		return nil, entity.Error("reason is required", "admin", "SuspendTenant", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	}
+
+	return a.suspend(tenantId, true, data.Reason, "SuspendTenant")
+}
+
+func (a *AdminSvc) UnsuspendTenant(ctx context.Context, tenantId *string) (*entity.TenantResponse, *entity.ModuleError) {
+	return a.suspend(tenantId, false, "", "UnsuspendTenant")
+}
+
+func (a *AdminSvc) suspend(tenantId *string, suspended bool, reason, method string) (*entity.TenantResponse, *entity.ModuleError) {
+
+	if tenantId == nil || *tenantId == "" {
+		return nil, entity.Error("tenant id cannot be empty", "admin", method, entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	}
+
+	if _, err := a.tenant.GetById(tenantId); err != nil {
+		return nil, entity.Error(err.Error(), "admin", method, entity.ApplicationLayerService, entity.ResponseCodeNotFound)
+	}
+
+	tenant, err := a.tenant.SetSuspended(tenantId, suspended, reason)
+	if err != nil {
+		return nil, entity.Error(err.Error(), "admin", method, entity.ApplicationLayerService, entity.ResponseCodeInternalServer)
+	}
+	return tenant, nil
+}
+
+// usage counts the users and the wallets of the tenant
+func (a *AdminSvc) usage(ctx context.Context, tenant *entity.TenantResponse) (*entity.TenantUsage, *entity.ModuleError) {
+
+	users, err := a.user.GetByFilterMany(ctx, entity.AndClause([]entity.QueryDB{
+		{Key: "tenant_id", Value: tenant.ID, Condition: string(entity.QueryFirebaseEqual)},
+	}))
+	if err != nil {
+		return nil, entity.Error(err.Error(), "admin", "GetTenants", entity.ApplicationLayerService, entity.ResponseCodeInternalServer)
+	}
+
+	wallets, mErr := a.wallets.GetByTenant(ctx, &tenant.ID)
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	usage := &entity.TenantUsage{
+		Tenant:       *tenant,
+		Users:        len(users),
+		Wallets:      len(wallets),
+		Entitlements: make([]entity.Entitlement, 0, len(entity.UsageFeatures)),
+	}
+	for _, key := range entity.UsageFeatures {
+		usage.Entitlements = append(usage.Entitlements, tenant.Plan.Entitlement(key))
+	}
+	return usage, nil
+}
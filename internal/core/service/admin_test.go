@@ -0,0 +1,117 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/internal/core/repository"
+	"github.com/Tomelin/financial-management-backend/internal/core/service"
+	"github.com/Tomelin/financial-management-backend/pkg/db"
+	"github.com/Tomelin/financial-management-backend/tests/coremocks"
+)
+
+type AdminServiceTestSuite struct {
+	suite.Suite
+	svc     entity.IAdmin
+	tenants service.ITenantService
+	tenant  *entity.TenantResponse
+	ctx     context.Context
+}
+
+func (s *AdminServiceTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	database := db.NewMemoryStore()
+
+	repoPlan, err := repository.NewPlanRepository(database)
+	s.Require().NoError(err)
+	plans, err := service.NewPlanService(repoPlan)
+	s.Require().NoError(err)
+	plan, err := plans.Create(&entity.PlanResponse{
+		ID:       uuid.New().String(),
+		Name:     "silver",
+		Price:    entity.NewMoney(1990, "BRL"),
+		Features: []entity.PlanFeatures{{Name: entity.FeatureWalletsMax, Count: 3}},
+	})
+	s.Require().NoError(err)
+
+	repoTenant, err := repository.NewTenantRepository(database)
+	s.Require().NoError(err)
+	s.tenants, err = service.NewTenantService(repoTenant, plans)
+	s.Require().NoError(err)
+
+	ownerID := uuid.New().String()
+	s.tenant, err = repoTenant.Create(&entity.TenantResponse{ID: uuid.New().String(), Name: "owner@domain.com", OwnerID: ownerID, Plan: *plan})
+	s.Require().NoError(err)
+
+	// the usage counts the wallets of every member of the tenant
+	memberID := uuid.New().String()
+	wallets, err := repository.NewWalletRepo(database)
+	s.Require().NoError(err)
+	for name, owner := range map[string]string{"Home": ownerID, "Travel": memberID} {
+		wallet, mErr := entity.NewWallet(&entity.WalletResponse{Name: name, OwnerID: owner, TenantID: s.tenant.ID, Currency: "BRL"})
+		s.Require().Nil(mErr)
+		_, mErr = wallets.Create(s.ctx, &owner, wallet)
+		s.Require().Nil(mErr)
+	}
+
+	mockUser := new(coremocks.IUser)
+	mockUser.On("GetByFilterMany", mock.Anything, mock.Anything).Return([]entity.AccountUser{
+		{ID: ownerID, TenantID: s.tenant.ID},
+		{ID: memberID, TenantID: s.tenant.ID},
+	}, nil)
+
+	var mErr *entity.ModuleError
+	s.svc, mErr = service.NewAdminSvc(s.tenants, mockUser, wallets)
+	s.Require().Nil(mErr)
+}
+
+func (s *AdminServiceTestSuite) TestGetTenants() {
+	page, mErr := s.svc.GetTenants(s.ctx, entity.DefaultPage())
+	s.Require().Nil(mErr)
+	s.Require().Len(page.Items, 1)
+
+	usage := page.Items[0]
+	s.Equal(s.tenant.ID, usage.Tenant.ID)
+	s.Equal(2, usage.Users)
+	s.Equal(2, usage.Wallets)
+	s.Require().Len(usage.Entitlements, len(entity.UsageFeatures))
+	s.Equal(entity.Entitlement{Key: entity.FeatureWalletsMax, Limit: 3}, usage.Entitlements[0])
+	s.Equal(entity.DefaultEntitlements[entity.FeatureWalletSharesMax].Count, usage.Entitlements[1].Limit, "a feature the plan does not have is the default")
+}
+
+func (s *AdminServiceTestSuite) TestSuspendTenant() {
+	_, mErr := s.svc.SuspendTenant(s.ctx, &s.tenant.ID, &entity.SuspendRequest{})
+	s.Require().NotNil(mErr)
+	s.Equal(entity.ResponseCodeBadRequest, mErr.Code)
+
+	missing := uuid.New().String()
+	_, mErr = s.svc.SuspendTenant(s.ctx, &missing, &entity.SuspendRequest{Reason: "chargeback"})
+	s.Require().NotNil(mErr)
+	s.Equal(entity.ResponseCodeNotFound, mErr.Code)
+
+	tenant, mErr := s.svc.SuspendTenant(s.ctx, &s.tenant.ID, &entity.SuspendRequest{Reason: "chargeback"})
+	s.Require().Nil(mErr)
+	s.True(tenant.Suspended)
+	s.Equal("chargeback", tenant.SuspendedReason)
+	s.False(tenant.SuspendedAt.IsZero())
+
+	tenant.Suspended = false
+	tenant.Alias = "home"
+	updated, err := s.tenants.Update(tenant)
+	s.Require().NoError(err)
+	s.True(updated.Suspended, "an update does not change the suspension")
+
+	tenant, mErr = s.svc.UnsuspendTenant(s.ctx, &s.tenant.ID)
+	s.Require().Nil(mErr)
+	s.False(tenant.Suspended)
+	s.Empty(tenant.SuspendedReason)
+}
+
+func TestAdminServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(AdminServiceTestSuite))
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/internal/core/repository"
+)
+
+type AuditSvc struct {
+	repo repository.IAuditRepo
+}
+
+// NewAuditSvc creates the service of the audit log of the system admins
+func NewAuditSvc(repo repository.IAuditRepo) (entity.IAudit, *entity.ModuleError) {
+	if repo == nil {
+		return nil, entity.Error("repo is required", "audit", "inicialization", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	}
+
+	return &AuditSvc{repo: repo}, nil
+}
+
+func (a *AuditSvc) Record(ctx context.Context, data *entity.AuditLog) *entity.ModuleError {
+
+	if data == nil || data.Action == "" {
+		return entity.Error("action is required", "audit", "Record", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	}
+
+	if data.ID == "" {
+		id, _ := uuid.NewV7()
+		data.ID = id.String()
+	}
+
+	if data.CreatedAt.IsZero() {
+		data.CreatedAt = time.Now().UTC()
+	}
+
+	return a.repo.CreateAuditLog(ctx, data)
+}
+
+func (a *AuditSvc) Get(ctx context.Context, page *entity.PageRequest) (*entity.Page[entity.AuditLog], *entity.ModuleError) {
+	return a.repo.GetAuditLogs(ctx, page)
+}
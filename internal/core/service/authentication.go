@@ -7,50 +7,298 @@ import (
 
 	"github.com/Tomelin/financial-management-backend/internal/core/entity"
 	"github.com/Tomelin/financial-management-backend/internal/core/repository"
+	"github.com/Tomelin/financial-management-backend/pkg/cache"
+	"github.com/Tomelin/financial-management-backend/pkg/jwtkeys"
 	"github.com/Tomelin/financial-management-backend/pkg/logger"
 	"github.com/dgrijalva/jwt-go"
 	"github.com/google/uuid"
 )
 
+const (
+	// revocationCacheTTL is how long the auth path trusts a token that is not revoked without asking the repository
+	// A token revoked by another instance is rejected by this one after at most this time
+	revocationCacheTTL = 30 * time.Second
+	// generationCacheTTL is how long the auth path trusts the token generation of a user
+	generationCacheTTL = 30 * time.Second
+	// authorizationCacheSize is the number of entries of each cache
+	authorizationCacheSize = 10000
+)
+
 type AuthorizationSvc struct {
 	repo repository.IAuthorizationRepo
+	user entity.IUser
+	keys *jwtkeys.KeySet
 	log  logger.Logger
+	// revoked is the denylist cache, jti -> revoked
+	revoked *cache.TTL[string, bool]
+	// generations is the cache of the token generation of the users
+	generations *cache.TTL[string, int64]
 }
 
-func NewAuthorizationSvc(repo repository.IAuthorizationRepo, l logger.Logger) (entity.IAuthorization, error) {
+func NewAuthorizationSvc(repo repository.IAuthorizationRepo, user entity.IUser, keys *jwtkeys.KeySet, l logger.Logger) (entity.IAuthorization, error) {
 	if repo == nil {
 		return nil, l.Error(&logger.Message{
 			Body: "repository is required",
 			Code: logger.ResponseCodeInternalServer,
 		})
 	}
+
+	if user == nil {
+		return nil, l.Error(&logger.Message{
+			Body: "user service is required",
+			Code: logger.ResponseCodeInternalServer,
+		})
+	}
+
+	if keys == nil {
+		return nil, l.Error(&logger.Message{
+			Body: "signing keys are required",
+			Code: logger.ResponseCodeInternalServer,
+		})
+	}
+
 	return &AuthorizationSvc{
-		repo: repo,
-		log:  l,
+		repo:        repo,
+		user:        user,
+		keys:        keys,
+		log:         l,
+		revoked:     cache.NewTTL[string, bool](authorizationCacheSize),
+		generations: cache.NewTTL[string, int64](authorizationCacheSize),
 	}, nil
 }
 
-func (a *AuthorizationSvc) GenerateTokenJWT(ctx context.Context, token *entity.AuthorizationClaims, user *entity.AccountUser) (*string, error) {
+// GenerateTokenJWT issues the access token of the user and the first refresh token of a new family
+func (a *AuthorizationSvc) GenerateTokenJWT(ctx context.Context, token *entity.AuthorizationClaims, user *entity.AccountUser) (*entity.TokenPair, error) {
+	generation, err := a.generation(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return a.issueTokenPair(ctx, user, "", generation)
+}
+
+// ValidateTokenJWT verifies the signature, the expiry and the revocation of the token
+// It returns the user of the token, the error wraps entity.ErrUnauthorized when the token is not accepted
+func (a *AuthorizationSvc) ValidateTokenJWT(ctx context.Context, token string) (*entity.AccountUser, error) {
+	claims, err := a.ParseTokenJWT(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	revoked, err := a.isRevoked(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, fmt.Errorf("%w: token revoked", entity.ErrUnauthorized)
+	}
+
+	generation, err := a.generation(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Generation < generation {
+		return nil, fmt.Errorf("%w: token revoked", entity.ErrUnauthorized)
+	}
+
+	user, err := a.user.GetByEmail(ctx, &claims.Email)
+	if err != nil || user == nil || user.Email != claims.Email {
+		return nil, fmt.Errorf("%w: user of the token not found", entity.ErrUnauthorized)
+	}
+
+	return user, nil
+}
+
+// ParseTokenJWT verifies the signature and the expiry of the token and returns its claims
+// The key of the signature is the key of the kid of the token
+func (a *AuthorizationSvc) ParseTokenJWT(ctx context.Context, token string) (*entity.AuthorizationClaims, error) {
+	token = entity.BearerToken(token)
+	if token == "" {
+		return nil, fmt.Errorf("%w: token is required", entity.ErrUnauthorized)
+	}
+
+	claims := &entity.AuthorizationClaims{}
+	parsed, err := a.keys.Parse(token, claims)
+	if err != nil || !parsed.Valid {
+		return nil, fmt.Errorf("%w: invalid token", entity.ErrUnauthorized)
+	}
+
+	if !claims.VerifyExpiresAt(time.Now().Unix(), true) || claims.IsRevoked || claims.Id == "" || claims.Email == "" {
+		return nil, fmt.Errorf("%w: invalid token", entity.ErrUnauthorized)
+	}
+
+	return claims, nil
+}
+
+// RevokeTokenJWT adds the token of the id to the denylist
+func (a *AuthorizationSvc) RevokeTokenJWT(ctx context.Context, token *string) error {
+	if token == nil || *token == "" {
+		return a.log.Error(&logger.Message{
+			Body: "token id is required",
+			Code: logger.ResponseCodeBadRequest,
+		})
+	}
+
+	if err := a.repo.RevokeTokenJWT(ctx, token); err != nil {
+		return err
+	}
+
+	a.revoked.Set(*token, true, entity.AccessTokenTTL)
+	return nil
+}
+
+// RevokeAllTokenJWT bumps the token generation of the user, the tokens issued before are rejected
+func (a *AuthorizationSvc) RevokeAllTokenJWT(ctx context.Context, userID string) error {
+	if userID == "" {
+		return a.log.Error(&logger.Message{
+			Body: "user id is required",
+			Code: logger.ResponseCodeBadRequest,
+		})
+	}
+
+	generation, err := a.repo.IncrementTokenGeneration(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	a.generations.Set(userID, generation, generationCacheTTL)
+	return nil
+}
+
+// isRevoked consults the denylist, a revoked token stays cached until it expires
+func (a *AuthorizationSvc) isRevoked(ctx context.Context, claims *entity.AuthorizationClaims) (bool, error) {
+	if revoked, ok := a.revoked.Get(claims.Id); ok {
+		return revoked, nil
+	}
+
+	revoked, err := a.repo.IsRevokedTokenJWT(ctx, &claims.Id)
+	if err != nil {
+		return false, err
+	}
+
+	ttl := revocationCacheTTL
+	if revoked {
+		ttl = time.Until(time.Unix(claims.ExpiresAt, 0))
+	}
+	a.revoked.Set(claims.Id, revoked, ttl)
+
+	return revoked, nil
+}
+
+func (a *AuthorizationSvc) generation(ctx context.Context, userID string) (int64, error) {
+	if generation, ok := a.generations.Get(userID); ok {
+		return generation, nil
+	}
+
+	generation, err := a.repo.GetTokenGeneration(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
 
+	a.generations.Set(userID, generation, generationCacheTTL)
+	return generation, nil
+}
+
+// RefreshTokenJWT rotates the refresh token, the token is used once and the new one keeps its family
+// A token that was already used is a reuse, the whole family is revoked and the request is unauthorized
+func (a *AuthorizationSvc) RefreshTokenJWT(ctx context.Context, refreshToken string) (*entity.TokenPair, error) {
+	if refreshToken == "" {
+		return nil, fmt.Errorf("%w: refresh token is required", entity.ErrUnauthorized)
+	}
+
+	record, err := a.repo.GetRefreshToken(ctx, entity.RefreshTokenID(refreshToken))
+	if err != nil {
+		return nil, err
+	}
+	if record == nil || record.Revoked {
+		return nil, fmt.Errorf("%w: invalid refresh token", entity.ErrUnauthorized)
+	}
+	if record.Used {
+		return nil, a.revokeFamily(ctx, record)
+	}
+	if record.IsExpired(time.Now()) {
+		return nil, fmt.Errorf("%w: refresh token expired", entity.ErrUnauthorized)
+	}
+
+	used, err := a.repo.UseRefreshToken(ctx, record.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !used {
+		// a concurrent request used the token first
+		return nil, a.revokeFamily(ctx, record)
+	}
+
+	generation, err := a.generation(ctx, record.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if record.Generation < generation {
+		return nil, fmt.Errorf("%w: refresh token revoked", entity.ErrUnauthorized)
+	}
+
+	user, err := a.user.GetById(ctx, &record.UserID)
+	if err != nil || user == nil {
+		return nil, fmt.Errorf("%w: user of the refresh token not found", entity.ErrUnauthorized)
+	}
+
+	return a.issueTokenPair(ctx, user, record.Family, generation)
+}
+
+func (a *AuthorizationSvc) revokeFamily(ctx context.Context, record *entity.RefreshToken) error {
+	a.log.Warn(&logger.Message{
+		Body: fmt.Sprintf("refresh token reused, revoking the family %s of the user %s", record.Family, record.UserID),
+		Code: logger.ResponseCodeUnauthorized,
+	})
+
+	if err := a.repo.RevokeRefreshTokenFamily(ctx, record.Family); err != nil {
+		return err
+	}
+
+	// the access token of the session may be the one of the attacker, it is revoked with the session
+	session, err := a.repo.GetSession(ctx, record.Family)
+	if err != nil {
+		return err
+	}
+	if session != nil && session.AccessTokenID != "" {
+		if err := a.RevokeTokenJWT(ctx, &session.AccessTokenID); err != nil {
+			return err
+		}
+	}
+
+	if err := a.repo.DeleteSession(ctx, record.Family); err != nil {
+		return err
+	}
+	return fmt.Errorf("%w: refresh token reused", entity.ErrUnauthorized)
+}
+
+// issueTokenPair issues the tokens of the session of the family, an empty family starts a new session
+func (a *AuthorizationSvc) issueTokenPair(ctx context.Context, user *entity.AccountUser, family string, generation int64) (*entity.TokenPair, error) {
+
+	started := family == ""
+	if started {
+		family = uuid.NewString()
+	}
+
+	now := time.Now()
 	claimsID, _ := uuid.NewV7()
 	claims := &entity.AuthorizationClaims{
-		UserID:   user.ID,
-		Username: user.Name,
-		Email:    user.Email,
-		// Roles:     user.Roles,
-		IsRevoked: false,
+		UserID:     user.ID,
+		Username:   user.Name,
+		Email:      user.Email,
+		Roles:      user.Roles,
+		IsRevoked:  false,
+		Generation: generation,
+		SessionID:  family,
 		StandardClaims: jwt.StandardClaims{
-			// Audience  string `json:"aud,omitempty"`
 			Id:        claimsID.String(),
-			IssuedAt:  time.Now().Unix(),
+			IssuedAt:  now.Unix(),
 			Issuer:    "financial-app",
 			Subject:   user.Email,
-			ExpiresAt: time.Now().Add(time.Hour * 4).Unix(),
+			ExpiresAt: now.Add(entity.AccessTokenTTL).Unix(),
 		},
 	}
 
-	tokenJWT := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := tokenJWT.SignedString([]byte(entity.SecretTokenJWT))
+	accessToken, err := a.keys.Sign(claims)
 	if err != nil {
 		return nil, a.log.Error(&logger.Message{
 			Body: fmt.Sprintf("error signed token: %s", err.Error()),
@@ -58,27 +306,108 @@ func (a *AuthorizationSvc) GenerateTokenJWT(ctx context.Context, token *entity.A
 		})
 	}
 
-	a.repo.GenerateTokenJWT(ctx, token, user)
+	if _, err := a.repo.GenerateTokenJWT(ctx, claims, user); err != nil {
+		return nil, err
+	}
 
-	return &tokenString, nil
-}
+	refreshToken, record, err := entity.NewRefreshToken(user.ID, family, generation)
+	if err != nil {
+		return nil, a.log.Error(&logger.Message{
+			Body: fmt.Sprintf("error refresh token: %s", err.Error()),
+			Code: logger.ResponseCodeInternalServer,
+		})
+	}
 
-func (a *AuthorizationSvc) ValidateTokenJWT(ctx context.Context, token string) (*entity.AccountUser, error) {
-	return a.repo.ValidateTokenJWT(ctx, token)
-}
+	if err := a.repo.CreateRefreshToken(ctx, record); err != nil {
+		return nil, err
+	}
 
-func (a *AuthorizationSvc) RevokeTokenJWT(ctx context.Context, token *string) error {
-	return a.repo.RevokeTokenJWT(ctx, token)
+	if err := a.saveSession(ctx, user, family, generation, claims.Id, started); err != nil {
+		return nil, err
+	}
+
+	return &entity.TokenPair{
+		AccessToken:           accessToken,
+		TokenType:             "Bearer",
+		ExpiresAt:             time.Unix(claims.ExpiresAt, 0).UTC(),
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: record.ExpiresAt,
+	}, nil
 }
 
-func (a *AuthorizationSvc) RefreshTokenJWT(ctx context.Context, token *string) (*string, error) {
-	return a.repo.RefreshTokenJWT(ctx, token)
+// saveSession starts the session of a new family, or records the refresh of the session of the family
+// The device is the client of the context, the provider defaults to the provider of the user
+func (a *AuthorizationSvc) saveSession(ctx context.Context, user *entity.AccountUser, family string, generation int64, accessTokenID string, started bool) error {
+	client := entity.SessionClientFromContext(ctx)
+	if client.Provider == "" {
+		client.Provider = user.Provider
+	}
+
+	var session *entity.Session
+	if !started {
+		var err error
+		if session, err = a.repo.GetSession(ctx, family); err != nil {
+			return err
+		}
+	}
+
+	if session == nil {
+		// a family of before the sessions starts its session at the refresh
+		var err error
+		if session, err = entity.NewSession(family, user.ID, generation, client); err != nil {
+			return a.log.Error(&logger.Message{
+				Body: fmt.Sprintf("error session: %s", err.Error()),
+				Code: logger.ResponseCodeInternalServer,
+			})
+		}
+	} else {
+		session.Seen(client, time.Now().UTC())
+	}
+
+	session.AccessTokenID = accessTokenID
+	return a.repo.SaveSession(ctx, session)
 }
 
-func (a *AuthorizationSvc) ParseTokenJWT(ctx context.Context, token string) error {
-	return a.repo.ParseTokenJWT(ctx, token)
+// ListSessions returns the sessions of the user that can still be refreshed
+func (a *AuthorizationSvc) ListSessions(ctx context.Context, userID string) ([]entity.Session, error) {
+	generation, err := a.generation(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := a.repo.ListSessions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	active := make([]entity.Session, 0, len(sessions))
+	for _, session := range sessions {
+		if session.IsActive(generation, now) {
+			active = append(active, session)
+		}
+	}
+	return active, nil
 }
 
-func (a *AuthorizationSvc) StoreTokenJWT(ctx context.Context, token []byte, userId *string) error {
-	return a.repo.StoreTokenJWT(ctx, token, userId)
+// RevokeSession revokes the refresh tokens of the session and its last access token, then removes it
+func (a *AuthorizationSvc) RevokeSession(ctx context.Context, userID, id string) error {
+	session, err := a.repo.GetSession(ctx, id)
+	if err != nil {
+		return err
+	}
+	if session == nil || session.UserID != userID {
+		return entity.ErrSessionNotFound
+	}
+
+	if err := a.repo.RevokeRefreshTokenFamily(ctx, session.ID); err != nil {
+		return err
+	}
+	if session.AccessTokenID != "" {
+		if err := a.RevokeTokenJWT(ctx, &session.AccessTokenID); err != nil {
+			return err
+		}
+	}
+
+	return a.repo.DeleteSession(ctx, session.ID)
 }
@@ -0,0 +1,391 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/internal/core/repository"
+	"github.com/Tomelin/financial-management-backend/internal/core/service"
+	"github.com/Tomelin/financial-management-backend/pkg/db"
+	"github.com/Tomelin/financial-management-backend/pkg/jwtkeys"
+	"github.com/Tomelin/financial-management-backend/pkg/logger"
+	"github.com/Tomelin/financial-management-backend/tests/coremocks"
+)
+
+type AuthorizationServiceTestSuite struct {
+	suite.Suite
+	user     *entity.AccountUser
+	mockRepo *coremocks.IAuthorizationRepo
+	mockUser *coremocks.IUser
+	keys     *jwtkeys.KeySet
+	svc      entity.IAuthorization
+	ctx      context.Context
+}
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+func (s *AuthorizationServiceTestSuite) SetupTest() {
+	id := uuid.New().String()
+	s.user = &entity.AccountUser{
+		ID:       id,
+		TenantID: uuid.New().String(),
+		Roles:    []entity.AccountRoles{{Key: "wallet", Name: "wallet", Value: "admin"}},
+		User: entity.User{
+			Name:     "Teste",
+			Email:    "user@domain.com",
+			Provider: "google",
+		},
+	}
+
+	s.ctx = context.Background()
+	s.mockRepo = new(coremocks.IAuthorizationRepo)
+	s.mockRepo.On("GetTokenGeneration", mock.Anything, mock.Anything).Return(int64(0), nil)
+	s.mockRepo.On("GetSession", mock.Anything, mock.Anything).Return(nil, nil)
+	s.mockRepo.On("SaveSession", mock.Anything, mock.Anything).Return(nil)
+	s.mockRepo.On("DeleteSession", mock.Anything, mock.Anything).Return(nil)
+	s.mockUser = new(coremocks.IUser)
+	s.mockUser.On("GetByEmail", mock.Anything, mock.Anything).Return(s.user, nil)
+
+	keys, err := jwtkeys.NewKeySetFromConfig(jwtkeys.Config{
+		Keys: []jwtkeys.KeyConfig{{Kid: "test", Algorithm: jwtkeys.AlgorithmHS256, Secret: testSecret}},
+	})
+	s.Require().NoError(err)
+	s.keys = keys
+
+	svc, err := service.NewAuthorizationSvc(s.mockRepo, s.mockUser, s.keys, logger.NewLoggerConfig(map[string]any{"level": "error"}))
+	s.Require().NoError(err)
+	s.svc = svc
+}
+
+func (s *AuthorizationServiceTestSuite) signed(claims *entity.AuthorizationClaims, kid string, method jwt.SigningMethod, key any) string {
+	tokenJWT := jwt.NewWithClaims(method, claims)
+	tokenJWT.Header["kid"] = kid
+	token, err := tokenJWT.SignedString(key)
+	s.Require().NoError(err)
+	return token
+}
+
+func (s *AuthorizationServiceTestSuite) claims(expiresAt time.Time) *entity.AuthorizationClaims {
+	return &entity.AuthorizationClaims{
+		UserID: s.user.ID,
+		Email:  s.user.Email,
+		StandardClaims: jwt.StandardClaims{
+			Id:        uuid.New().String(),
+			ExpiresAt: expiresAt.Unix(),
+		},
+	}
+}
+
+func (s *AuthorizationServiceTestSuite) TestValidateTokenJWT() {
+	var stored *entity.AuthorizationClaims
+	s.mockRepo.On("GenerateTokenJWT", mock.Anything, mock.Anything, s.user).
+		Run(func(args mock.Arguments) { stored = args.Get(1).(*entity.AuthorizationClaims) }).
+		Return(nil, nil)
+	s.mockRepo.On("CreateRefreshToken", mock.Anything, mock.Anything).Return(nil)
+	s.mockRepo.On("IsRevokedTokenJWT", mock.Anything, mock.Anything).Return(false, nil)
+
+	token, err := s.svc.GenerateTokenJWT(s.ctx, &entity.AuthorizationClaims{}, s.user)
+	s.Require().NoError(err)
+	s.Require().NotNil(stored)
+	s.NotEmpty(stored.Id, "the signed claims are stored by the token id")
+	s.Equal("Bearer", token.TokenType)
+	s.NotEmpty(token.RefreshToken)
+	s.WithinDuration(time.Now().Add(entity.AccessTokenTTL), token.ExpiresAt, time.Minute)
+
+	user, err := s.svc.ValidateTokenJWT(s.ctx, "Bearer "+token.AccessToken)
+	s.Require().NoError(err)
+	s.Equal(s.user.ID, user.ID)
+
+	claims, err := s.svc.ParseTokenJWT(s.ctx, token.AccessToken)
+	s.Require().NoError(err)
+	s.Equal(stored.Id, claims.Id)
+	s.Equal(s.user.Roles, claims.Roles, "the roles of the user are embedded in the token")
+	s.mockRepo.AssertCalled(s.T(), "IsRevokedTokenJWT", mock.Anything, &stored.Id)
+}
+
+func (s *AuthorizationServiceTestSuite) TestValidateTokenJWT_Rejected() {
+	s.mockRepo.On("IsRevokedTokenJWT", mock.Anything, mock.Anything).Return(false, nil)
+
+	tests := map[string]string{
+		"empty":       "",
+		"malformed":   "not-a-token",
+		"expired":     s.signed(s.claims(time.Now().Add(-time.Minute)), "test", jwt.SigningMethodHS256, []byte(testSecret)),
+		"no expiry":   s.signed(s.claims(time.Unix(0, 0)), "test", jwt.SigningMethodHS256, []byte(testSecret)),
+		"no kid":      s.signed(s.claims(time.Now().Add(time.Hour)), "", jwt.SigningMethodHS256, []byte(testSecret)),
+		"unknown kid": s.signed(s.claims(time.Now().Add(time.Hour)), "other", jwt.SigningMethodHS256, []byte(testSecret)),
+		"other key":   s.signed(s.claims(time.Now().Add(time.Hour)), "test", jwt.SigningMethodHS256, []byte("other key")),
+		"other alg":   s.signed(s.claims(time.Now().Add(time.Hour)), "test", jwt.SigningMethodHS512, []byte(testSecret)),
+	}
+
+	for name, token := range tests {
+		_, err := s.svc.ValidateTokenJWT(s.ctx, token)
+		s.True(errors.Is(err, entity.ErrUnauthorized), name)
+	}
+}
+
+func (s *AuthorizationServiceTestSuite) TestValidateTokenJWT_Revoked() {
+	s.mockRepo.On("IsRevokedTokenJWT", mock.Anything, mock.Anything).Return(true, nil)
+
+	token := s.signed(s.claims(time.Now().Add(time.Hour)), "test", jwt.SigningMethodHS256, []byte(testSecret))
+	_, err := s.svc.ValidateTokenJWT(s.ctx, token)
+	s.True(errors.Is(err, entity.ErrUnauthorized))
+	s.mockUser.AssertNotCalled(s.T(), "GetByEmail", mock.Anything, mock.Anything)
+}
+
+func (s *AuthorizationServiceTestSuite) TestValidateTokenJWT_UnknownUser() {
+	s.mockRepo.On("IsRevokedTokenJWT", mock.Anything, mock.Anything).Return(false, nil)
+	s.mockUser = new(coremocks.IUser)
+	s.mockUser.On("GetByEmail", mock.Anything, mock.Anything).Return(nil, errors.New("not found"))
+
+	svc, err := service.NewAuthorizationSvc(s.mockRepo, s.mockUser, s.keys, logger.NewLoggerConfig(map[string]any{"level": "error"}))
+	s.Require().NoError(err)
+
+	token := s.signed(s.claims(time.Now().Add(time.Hour)), "test", jwt.SigningMethodHS256, []byte(testSecret))
+	_, err = svc.ValidateTokenJWT(s.ctx, token)
+	s.True(errors.Is(err, entity.ErrUnauthorized))
+}
+
+func (s *AuthorizationServiceTestSuite) TestRevokeTokenJWT() {
+	s.mockRepo.On("GenerateTokenJWT", mock.Anything, mock.Anything, s.user).Return(nil, nil)
+	s.mockRepo.On("CreateRefreshToken", mock.Anything, mock.Anything).Return(nil)
+	s.mockRepo.On("IsRevokedTokenJWT", mock.Anything, mock.Anything).Return(false, nil)
+	s.mockRepo.On("RevokeTokenJWT", mock.Anything, mock.Anything).Return(nil)
+
+	token, err := s.svc.GenerateTokenJWT(s.ctx, &entity.AuthorizationClaims{}, s.user)
+	s.Require().NoError(err)
+
+	for i := 0; i < 3; i++ {
+		_, err = s.svc.ValidateTokenJWT(s.ctx, token.AccessToken)
+		s.Require().NoError(err)
+	}
+	s.mockRepo.AssertNumberOfCalls(s.T(), "IsRevokedTokenJWT", 1)
+
+	claims, err := s.svc.ParseTokenJWT(s.ctx, token.AccessToken)
+	s.Require().NoError(err)
+	s.Require().NoError(s.svc.RevokeTokenJWT(s.ctx, &claims.Id))
+
+	// the revocation is seen at once, the cached result is replaced
+	_, err = s.svc.ValidateTokenJWT(s.ctx, token.AccessToken)
+	s.True(errors.Is(err, entity.ErrUnauthorized))
+	s.mockRepo.AssertNumberOfCalls(s.T(), "IsRevokedTokenJWT", 1)
+
+	s.Error(s.svc.RevokeTokenJWT(s.ctx, nil))
+}
+
+func (s *AuthorizationServiceTestSuite) TestValidateTokenJWT_RevokedCached() {
+	s.mockRepo.On("IsRevokedTokenJWT", mock.Anything, mock.Anything).Return(true, nil)
+
+	token := s.signed(s.claims(time.Now().Add(time.Hour)), "test", jwt.SigningMethodHS256, []byte(testSecret))
+	for i := 0; i < 3; i++ {
+		_, err := s.svc.ValidateTokenJWT(s.ctx, token)
+		s.True(errors.Is(err, entity.ErrUnauthorized))
+	}
+	s.mockRepo.AssertNumberOfCalls(s.T(), "IsRevokedTokenJWT", 1)
+}
+
+func (s *AuthorizationServiceTestSuite) TestRevokeAllTokenJWT() {
+	s.mockRepo.On("GenerateTokenJWT", mock.Anything, mock.Anything, s.user).Return(nil, nil)
+	s.mockRepo.On("CreateRefreshToken", mock.Anything, mock.Anything).Return(nil)
+	s.mockRepo.On("IsRevokedTokenJWT", mock.Anything, mock.Anything).Return(false, nil)
+	s.mockRepo.On("IncrementTokenGeneration", mock.Anything, s.user.ID).Return(int64(1), nil)
+
+	before, err := s.svc.GenerateTokenJWT(s.ctx, &entity.AuthorizationClaims{}, s.user)
+	s.Require().NoError(err)
+	_, err = s.svc.ValidateTokenJWT(s.ctx, before.AccessToken)
+	s.Require().NoError(err)
+
+	s.Require().NoError(s.svc.RevokeAllTokenJWT(s.ctx, s.user.ID))
+
+	_, err = s.svc.ValidateTokenJWT(s.ctx, before.AccessToken)
+	s.True(errors.Is(err, entity.ErrUnauthorized), "the tokens of the previous generation are revoked")
+
+	after, err := s.svc.GenerateTokenJWT(s.ctx, &entity.AuthorizationClaims{}, s.user)
+	s.Require().NoError(err)
+	claims, err := s.svc.ParseTokenJWT(s.ctx, after.AccessToken)
+	s.Require().NoError(err)
+	s.Equal(int64(1), claims.Generation)
+	_, err = s.svc.ValidateTokenJWT(s.ctx, after.AccessToken)
+	s.NoError(err)
+
+	// the refresh tokens of the previous generation are revoked as well
+	token, record := s.refreshToken(nil)
+	s.mockRepo.On("UseRefreshToken", mock.Anything, record.ID).Return(true, nil)
+	_, err = s.svc.RefreshTokenJWT(s.ctx, token)
+	s.True(errors.Is(err, entity.ErrUnauthorized))
+
+	s.Error(s.svc.RevokeAllTokenJWT(s.ctx, ""))
+}
+
+// refreshToken stores a refresh token of the user in the mock and returns the opaque token
+func (s *AuthorizationServiceTestSuite) refreshToken(change func(*entity.RefreshToken)) (string, *entity.RefreshToken) {
+	token, record, err := entity.NewRefreshToken(s.user.ID, "", 0)
+	s.Require().NoError(err)
+	if change != nil {
+		change(record)
+	}
+	s.mockRepo.On("GetRefreshToken", mock.Anything, record.ID).Return(record, nil)
+	return token, record
+}
+
+func (s *AuthorizationServiceTestSuite) TestRefreshTokenJWT_Rotation() {
+	token, record := s.refreshToken(nil)
+
+	var rotated *entity.RefreshToken
+	s.mockRepo.On("UseRefreshToken", mock.Anything, record.ID).Return(true, nil)
+	s.mockRepo.On("GenerateTokenJWT", mock.Anything, mock.Anything, s.user).Return(nil, nil)
+	s.mockRepo.On("CreateRefreshToken", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { rotated = args.Get(1).(*entity.RefreshToken) }).
+		Return(nil)
+	s.mockUser.On("GetById", mock.Anything, &s.user.ID).Return(s.user, nil)
+
+	pair, err := s.svc.RefreshTokenJWT(s.ctx, token)
+	s.Require().NoError(err)
+	s.NotEmpty(pair.AccessToken)
+	s.NotEqual(token, pair.RefreshToken)
+
+	s.Require().NotNil(rotated)
+	s.Equal(record.Family, rotated.Family, "the rotated token keeps the family")
+	s.Equal(entity.RefreshTokenID(pair.RefreshToken), rotated.ID)
+	s.mockRepo.AssertNotCalled(s.T(), "RevokeRefreshTokenFamily", mock.Anything, mock.Anything)
+
+	claims, err := s.svc.ParseTokenJWT(s.ctx, pair.AccessToken)
+	s.Require().NoError(err)
+	s.Equal(record.Family, claims.SessionID, "the session is the family")
+	s.mockRepo.AssertCalled(s.T(), "SaveSession", mock.Anything, mock.MatchedBy(func(session *entity.Session) bool {
+		return session.ID == record.Family && session.AccessTokenID == claims.Id
+	}))
+}
+
+func (s *AuthorizationServiceTestSuite) TestRefreshTokenJWT_Reuse() {
+	token, record := s.refreshToken(func(r *entity.RefreshToken) { r.Used = true })
+	s.mockRepo.On("RevokeRefreshTokenFamily", mock.Anything, record.Family).Return(nil)
+
+	_, err := s.svc.RefreshTokenJWT(s.ctx, token)
+	s.True(errors.Is(err, entity.ErrUnauthorized))
+	s.mockRepo.AssertCalled(s.T(), "RevokeRefreshTokenFamily", mock.Anything, record.Family)
+	s.mockRepo.AssertCalled(s.T(), "DeleteSession", mock.Anything, record.Family)
+	s.mockRepo.AssertNotCalled(s.T(), "CreateRefreshToken", mock.Anything, mock.Anything)
+}
+
+func (s *AuthorizationServiceTestSuite) TestRefreshTokenJWT_ConcurrentUse() {
+	token, record := s.refreshToken(nil)
+	s.mockRepo.On("UseRefreshToken", mock.Anything, record.ID).Return(false, nil)
+	s.mockRepo.On("RevokeRefreshTokenFamily", mock.Anything, record.Family).Return(nil)
+
+	_, err := s.svc.RefreshTokenJWT(s.ctx, token)
+	s.True(errors.Is(err, entity.ErrUnauthorized))
+	s.mockRepo.AssertCalled(s.T(), "RevokeRefreshTokenFamily", mock.Anything, record.Family)
+}
+
+func (s *AuthorizationServiceTestSuite) TestRefreshTokenJWT_Rejected() {
+	expired, _ := s.refreshToken(func(r *entity.RefreshToken) { r.ExpiresAt = time.Now().Add(-time.Minute) })
+	revoked, _ := s.refreshToken(func(r *entity.RefreshToken) { r.Revoked = true })
+	s.mockRepo.On("GetRefreshToken", mock.Anything, mock.Anything).Return(nil, nil)
+
+	tests := map[string]string{
+		"empty":   "",
+		"unknown": "unknown",
+		"expired": expired,
+		"revoked": revoked,
+	}
+
+	for name, token := range tests {
+		_, err := s.svc.RefreshTokenJWT(s.ctx, token)
+		s.True(errors.Is(err, entity.ErrUnauthorized), name)
+	}
+	s.mockRepo.AssertNotCalled(s.T(), "UseRefreshToken", mock.Anything, mock.Anything)
+	s.mockRepo.AssertNotCalled(s.T(), "RevokeRefreshTokenFamily", mock.Anything, mock.Anything)
+}
+
+func (s *AuthorizationServiceTestSuite) TestSessions() {
+	repo, err := repository.NewAuthorizationRepo(db.NewMemoryStore(), logger.NewLoggerConfig(map[string]any{"level": "error"}))
+	s.Require().NoError(err)
+	svc, err := service.NewAuthorizationSvc(repo, s.mockUser, s.keys, logger.NewLoggerConfig(map[string]any{"level": "error"}))
+	s.Require().NoError(err)
+	s.mockUser.On("GetById", mock.Anything, mock.Anything).Return(s.user, nil)
+
+	laptop, err := svc.GenerateTokenJWT(entity.WithSessionClient(s.ctx, entity.SessionClient{UserAgent: "Firefox", IP: "10.0.0.1", Provider: "github"}), &entity.AuthorizationClaims{}, s.user)
+	s.Require().NoError(err)
+	phone, err := svc.GenerateTokenJWT(s.ctx, &entity.AuthorizationClaims{}, s.user)
+	s.Require().NoError(err)
+
+	sessions, err := svc.ListSessions(s.ctx, s.user.ID)
+	s.Require().NoError(err)
+	s.Require().Len(sessions, 2, "each login is a session")
+	claims, err := svc.ParseTokenJWT(s.ctx, laptop.AccessToken)
+	s.Require().NoError(err)
+	var session entity.Session
+	for _, found := range sessions {
+		if found.ID == claims.SessionID {
+			session = found
+		}
+	}
+	s.Equal("Firefox", session.UserAgent)
+	s.Equal("10.0.0.1", session.IP)
+	s.Equal("github", session.Provider)
+
+	// the refresh keeps the session and records the device
+	laptop, err = svc.RefreshTokenJWT(entity.WithSessionClient(s.ctx, entity.SessionClient{IP: "10.0.0.2"}), laptop.RefreshToken)
+	s.Require().NoError(err)
+	sessions, err = svc.ListSessions(s.ctx, s.user.ID)
+	s.Require().NoError(err)
+	s.Require().Len(sessions, 2)
+	s.Equal(claims.SessionID, sessions[0].ID, "the last seen first")
+	s.Equal("10.0.0.2", sessions[0].IP)
+	s.Equal("Firefox", sessions[0].UserAgent)
+	s.Equal("google", sessions[1].Provider, "the provider defaults to the provider of the user")
+
+	s.True(errors.Is(svc.RevokeSession(s.ctx, uuid.New().String(), claims.SessionID), entity.ErrSessionNotFound))
+	s.Require().NoError(svc.RevokeSession(s.ctx, s.user.ID, claims.SessionID))
+	_, err = svc.RefreshTokenJWT(s.ctx, laptop.RefreshToken)
+	s.True(errors.Is(err, entity.ErrUnauthorized), "the refresh token of the session is revoked")
+	_, err = svc.ValidateTokenJWT(s.ctx, laptop.AccessToken)
+	s.True(errors.Is(err, entity.ErrUnauthorized), "the last access token of the session is revoked")
+	_, err = svc.ValidateTokenJWT(s.ctx, phone.AccessToken)
+	s.NoError(err)
+
+	sessions, err = svc.ListSessions(s.ctx, s.user.ID)
+	s.Require().NoError(err)
+	s.Len(sessions, 1)
+
+	s.Require().NoError(svc.RevokeAllTokenJWT(s.ctx, s.user.ID))
+	sessions, err = svc.ListSessions(s.ctx, s.user.ID)
+	s.Require().NoError(err)
+	s.Empty(sessions, "the sessions of the previous generation ended")
+}
+
+func (s *AuthorizationServiceTestSuite) TestRefreshTokenJWT_ReuseRevokesAccessToken() {
+	repo, err := repository.NewAuthorizationRepo(db.NewMemoryStore(), logger.NewLoggerConfig(map[string]any{"level": "error"}))
+	s.Require().NoError(err)
+	svc, err := service.NewAuthorizationSvc(repo, s.mockUser, s.keys, logger.NewLoggerConfig(map[string]any{"level": "error"}))
+	s.Require().NoError(err)
+	s.mockUser.On("GetById", mock.Anything, mock.Anything).Return(s.user, nil)
+
+	stolen, err := svc.GenerateTokenJWT(s.ctx, &entity.AuthorizationClaims{}, s.user)
+	s.Require().NoError(err)
+	rotated, err := svc.RefreshTokenJWT(s.ctx, stolen.RefreshToken)
+	s.Require().NoError(err)
+	_, err = svc.ValidateTokenJWT(s.ctx, rotated.AccessToken)
+	s.Require().NoError(err)
+
+	_, err = svc.RefreshTokenJWT(s.ctx, stolen.RefreshToken)
+	s.True(errors.Is(err, entity.ErrUnauthorized))
+	_, err = svc.ValidateTokenJWT(s.ctx, rotated.AccessToken)
+	s.True(errors.Is(err, entity.ErrUnauthorized), "the access token of the reused family is revoked")
+
+	sessions, err := svc.ListSessions(s.ctx, s.user.ID)
+	s.Require().NoError(err)
+	s.Empty(sessions)
+}
+
+func TestAuthorizationServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(AuthorizationServiceTestSuite))
+}
@@ -0,0 +1,299 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/internal/core/repository"
+	"github.com/Tomelin/financial-management-backend/pkg/payment"
+)
+
+type BillingSvc struct {
+	repo         repository.IBillingRepo
+	provider     payment.Provider
+	subscription entity.ISubscription
+	tenant       ITenantService
+	plan         entity.IPlan
+	user         entity.IUser
+	config       payment.Config
+}
+
+// NewBillingSvc creates the service that charges the paid plans through the payment provider
+func NewBillingSvc(repo repository.IBillingRepo, provider payment.Provider, subscription entity.ISubscription, tenant ITenantService, plan entity.IPlan, user entity.IUser, config payment.Config) (entity.IBilling, *entity.ModuleError) {
+	if repo == nil || provider == nil {
+		return nil, entity.Error("repo and provider are required", "billing", "inicialization", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	}
+
+	if subscription == nil || tenant == nil || plan == nil || user == nil {
+		return nil, entity.Error("subscription, tenant, plan and user are required", "billing", "inicialization", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	}
+
+	return &BillingSvc{
+		repo:         repo,
+		provider:     provider,
+		subscription: subscription,
+		tenant:       tenant,
+		plan:         plan,
+		user:         user,
+		config:       config,
+	}, nil
+}
+
+// Checkout creates the customer of the tenant on the first checkout, the plan changes when the provider confirms the payment
+func (b *BillingSvc) Checkout(ctx context.Context, userId *string, data *entity.SubscriptionRequest) (*entity.CheckoutResponse, *entity.ModuleError) {
+
+	if data == nil || data.PlanID == "" {
+		return nil, entity.Error("plan id is required", "billing", "Checkout", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	}
+
+	user, tenant, mErr := b.getOwner(ctx, userId, "Checkout")
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	plan, err := b.plan.GetById(&data.PlanID)
+	if err != nil || plan == nil || plan.ID == "" {
+		return nil, entity.Error("plan not found", "billing", "Checkout", entity.ApplicationLayerService, entity.ResponseCodeNotFound)
+	}
+
+	if !plan.Price.IsPositive() {
+		return nil, entity.Error("a free plan is changed on the subscription", "billing", "Checkout", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	}
+
+	account, mErr := b.repo.GetBillingAccount(ctx, tenant.ID)
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	now := time.Now().UTC()
+	if account == nil {
+		account = &entity.BillingAccount{TenantID: tenant.ID, CreatedAt: now}
+	}
+
+	if account.CustomerID == "" {
+		customerID, err := b.provider.CreateCustomer(ctx, &payment.Customer{TenantID: tenant.ID, Email: user.Email, Name: tenant.Name})
+		if err != nil {
+			return nil, entity.Error(err.Error(), "billing", "Checkout", entity.ApplicationLayerService, entity.ResponseCodeInternalServer)
+		}
+
+		account.CustomerID = customerID
+		account.UpdatedAt = now
+		if mErr := b.repo.SaveBillingAccount(ctx, account); mErr != nil {
+			return nil, mErr
+		}
+	}
+
+	session, err := b.provider.CreateCheckout(ctx, &payment.Checkout{
+		CustomerID: account.CustomerID,
+		TenantID:   tenant.ID,
+		PlanID:     plan.ID,
+		Amount:     plan.Price.Decimal(),
+		Currency:   plan.Price.Currency,
+		SuccessURL: b.config.SuccessURL,
+		CancelURL:  b.config.CancelURL,
+	})
+	if err != nil {
+		return nil, entity.Error(err.Error(), "billing", "Checkout", entity.ApplicationLayerService, entity.ResponseCodeInternalServer)
+	}
+
+	return &entity.CheckoutResponse{ID: session.ID, URL: session.URL}, nil
+}
+
+// Cancel cancels the subscription at the end of the period and stops the charges of the provider at once
+func (b *BillingSvc) Cancel(ctx context.Context, userId *string) (*entity.Subscription, *entity.ModuleError) {
+
+	subscription, mErr := b.subscription.Cancel(ctx, userId)
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	account, mErr := b.repo.GetBillingAccount(ctx, subscription.TenantID)
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	if account == nil || account.SubscriptionID == "" {
+		return subscription, nil
+	}
+
+	if err := b.provider.CancelSubscription(ctx, account.SubscriptionID); err != nil {
+		return nil, entity.Error(err.Error(), "billing", "Cancel", entity.ApplicationLayerService, entity.ResponseCodeInternalServer)
+	}
+
+	account.SubscriptionID = ""
+	account.UpdatedAt = time.Now().UTC()
+	if mErr := b.repo.SaveBillingAccount(ctx, account); mErr != nil {
+		return nil, mErr
+	}
+
+	return subscription, nil
+}
+
+// Resume undoes a cancel, a paid plan whose charges were stopped is subscribed again through the checkout
+func (b *BillingSvc) Resume(ctx context.Context, userId *string) (*entity.Subscription, *entity.ModuleError) {
+
+	subscription, mErr := b.subscription.Get(ctx, userId)
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	if subscription.PlanID != "" {
+		plan, err := b.plan.GetById(&subscription.PlanID)
+		if err != nil || plan == nil {
+			return nil, entity.Error("plan not found", "billing", "Resume", entity.ApplicationLayerService, entity.ResponseCodeNotFound)
+		}
+
+		account, mErr := b.repo.GetBillingAccount(ctx, subscription.TenantID)
+		if mErr != nil {
+			return nil, mErr
+		}
+
+		if plan.Price.IsPositive() && (account == nil || account.SubscriptionID == "") {
+			return nil, entity.Error("the charges were stopped, a paid plan is subscribed through the checkout", "billing", "Resume", entity.ApplicationLayerService, entity.ResponseCodeConflict)
+		}
+	}
+
+	return b.subscription.Resume(ctx, userId)
+}
+
+// HandleWebhook applies an event once, an event of a subscription that is not the one of the tenant is ignored
+func (b *BillingSvc) HandleWebhook(ctx context.Context, payload []byte, signature string) *entity.ModuleError {
+
+	event, err := b.provider.ParseWebhook(payload, signature)
+	if errors.Is(err, payment.ErrInvalidSignature) {
+		return entity.Error(err.Error(), "billing", "HandleWebhook", entity.ApplicationLayerService, entity.ResponseCodeUnauthorized)
+	}
+	if err != nil {
+		return entity.Error(err.Error(), "billing", "HandleWebhook", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	}
+
+	if event.ID == "" || event.TenantID == "" {
+		return entity.Error("event id and tenant id are required", "billing", "HandleWebhook", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	}
+
+	// the event is claimed before it is applied, a webhook sent again while it is applied is skipped
+	now := time.Now().UTC()
+	record := &entity.PaymentEvent{ID: event.ID, Type: event.Type, TenantID: event.TenantID, ClaimedAt: now}
+	claimed, mErr := b.repo.ClaimPaymentEvent(ctx, record)
+	if mErr != nil {
+		return mErr
+	}
+	if !claimed {
+		return b.appliedEvent(ctx, event.ID)
+	}
+
+	if mErr := b.apply(ctx, event, now); mErr != nil {
+		// the provider sends the event again, it is applied by that request
+		if rErr := b.repo.ReleasePaymentEvent(ctx, event.ID); rErr != nil {
+			return rErr
+		}
+		return mErr
+	}
+
+	record.ProcessedAt = time.Now().UTC()
+	return b.repo.SavePaymentEvent(ctx, record)
+}
+
+// appliedEvent answers a webhook claimed by another request, the provider sends it again while it is applied
+func (b *BillingSvc) appliedEvent(ctx context.Context, id string) *entity.ModuleError {
+	record, mErr := b.repo.GetPaymentEvent(ctx, id)
+	if mErr != nil {
+		return mErr
+	}
+	if record != nil && record.ProcessedAt.IsZero() {
+		return entity.Error("the event is being applied", "billing", "HandleWebhook", entity.ApplicationLayerService, entity.ResponseCodeConflict)
+	}
+	return nil
+}
+
+// apply changes the subscription of the tenant of the event
+func (b *BillingSvc) apply(ctx context.Context, event *payment.Event, now time.Time) *entity.ModuleError {
+
+	account, mErr := b.repo.GetBillingAccount(ctx, event.TenantID)
+	if mErr != nil {
+		return mErr
+	}
+
+	if event.Type == payment.EventCheckoutCompleted {
+		mErr = b.completed(ctx, account, event, now)
+	} else if account != nil && account.SubscriptionID != "" && account.SubscriptionID == event.SubscriptionID {
+		switch event.Type {
+		case payment.EventInvoicePaid:
+			_, mErr = b.subscription.MarkPaid(ctx, &event.TenantID)
+		case payment.EventInvoiceFailed:
+			_, mErr = b.subscription.MarkPastDue(ctx, &event.TenantID)
+		case payment.EventSubscriptionCanceled:
+			mErr = b.canceled(ctx, account, now)
+		}
+	}
+	return mErr
+}
+
+// completed links the subscription of the provider to the tenant and changes the plan
+// A subscription replaced by a new checkout is canceled in the provider
+func (b *BillingSvc) completed(ctx context.Context, account *entity.BillingAccount, event *payment.Event, now time.Time) *entity.ModuleError {
+
+	if event.PlanID == "" || event.SubscriptionID == "" {
+		return entity.Error("plan id and subscription id are required", "billing", "HandleWebhook", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	}
+
+	if account == nil {
+		account = &entity.BillingAccount{TenantID: event.TenantID, CustomerID: event.CustomerID, CreatedAt: now}
+	}
+
+	if _, mErr := b.subscription.ChangeTenantPlan(ctx, &event.TenantID, &entity.SubscriptionRequest{PlanID: event.PlanID}); mErr != nil {
+		return mErr
+	}
+
+	previous := account.SubscriptionID
+	account.SubscriptionID = event.SubscriptionID
+	account.UpdatedAt = now
+	if mErr := b.repo.SaveBillingAccount(ctx, account); mErr != nil {
+		return mErr
+	}
+
+	if previous != "" && previous != event.SubscriptionID {
+		if err := b.provider.CancelSubscription(ctx, previous); err != nil {
+			return entity.Error(err.Error(), "billing", "HandleWebhook", entity.ApplicationLayerService, entity.ResponseCodeInternalServer)
+		}
+	}
+	return nil
+}
+
+// canceled cancels the subscription of the tenant that the provider stopped charging
+func (b *BillingSvc) canceled(ctx context.Context, account *entity.BillingAccount, now time.Time) *entity.ModuleError {
+
+	if _, mErr := b.subscription.CancelTenant(ctx, &account.TenantID); mErr != nil && mErr.Code != entity.ResponseCodeConflict {
+		return mErr
+	}
+
+	account.SubscriptionID = ""
+	account.UpdatedAt = now
+	return b.repo.SaveBillingAccount(ctx, account)
+}
+
+// getOwner returns the user and its tenant, only the owner of the tenant pays the plan
+func (b *BillingSvc) getOwner(ctx context.Context, userId *string, method string) (*entity.AccountUser, *entity.TenantResponse, *entity.ModuleError) {
+
+	if userId == nil || *userId == "" {
+		return nil, nil, entity.Error("user id cannot be empty", "billing", method, entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	}
+
+	user, err := accountUserByID(ctx, b.user, userId)
+	if err != nil || user == nil {
+		return nil, nil, entity.Error("user not found", "billing", method, entity.ApplicationLayerService, entity.ResponseCodeUnauthorized)
+	}
+
+	tenant, err := b.tenant.GetById(&user.TenantID)
+	if err != nil || tenant == nil {
+		return nil, nil, entity.Error("tenant not found", "billing", method, entity.ApplicationLayerService, entity.ResponseCodeNotFound)
+	}
+
+	if tenant.OwnerID != user.ID {
+		return nil, nil, entity.Error("only the owner of the tenant pays the plan", "billing", method, entity.ApplicationLayerService, entity.ResponseCodeForbidden)
+	}
+
+	return user, tenant, nil
+}
@@ -0,0 +1,112 @@
+package service_test
+
+import (
+	"time"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/internal/core/repository"
+	"github.com/Tomelin/financial-management-backend/internal/core/service"
+	"github.com/Tomelin/financial-management-backend/pkg/db"
+	"github.com/Tomelin/financial-management-backend/pkg/payment"
+)
+
+// billing creates the billing of the tenant of the suite with the fake provider
+func (s *SubscriptionServiceTestSuite) billing() (entity.IBilling, *payment.FakeProvider) {
+	repo, err := repository.NewBillingRepo(db.NewMemoryStore())
+	s.Require().NoError(err)
+
+	fake, err := payment.NewFakeProvider("secret", "")
+	s.Require().NoError(err)
+
+	billing, mErr := service.NewBillingSvc(repo, fake, s.svc, s.tenants, s.plans, s.users, payment.Config{})
+	s.Require().Nil(mErr)
+	return billing, fake
+}
+
+func (s *SubscriptionServiceTestSuite) TestCheckout() {
+	billing, fake := s.billing()
+
+	_, mErr := billing.Checkout(s.ctx, &s.member.ID, &entity.SubscriptionRequest{PlanID: s.gold.ID})
+	s.Require().NotNil(mErr)
+	s.Equal(entity.ResponseCodeForbidden, mErr.Code, "only the owner pays the plan")
+
+	_, mErr = billing.Checkout(s.ctx, &s.owner.ID, &entity.SubscriptionRequest{PlanID: s.bronze.ID})
+	s.Require().NotNil(mErr)
+	s.Equal(entity.ResponseCodeBadRequest, mErr.Code, "a free plan has no checkout")
+
+	checkout, mErr := billing.Checkout(s.ctx, &s.owner.ID, &entity.SubscriptionRequest{PlanID: s.gold.ID})
+	s.Require().Nil(mErr)
+	s.Equal(s.silver.ID, s.plan().ID, "the plan changes when the payment is confirmed")
+
+	payload, signature, err := fake.Complete(checkout.ID)
+	s.Require().NoError(err)
+
+	mErr = billing.HandleWebhook(s.ctx, payload, "t=1,v1=abc")
+	s.Require().NotNil(mErr)
+	s.Equal(entity.ResponseCodeUnauthorized, mErr.Code)
+
+	s.Require().Nil(billing.HandleWebhook(s.ctx, payload, signature))
+	s.Equal(s.gold.ID, s.plan().ID)
+
+	completed, err := fake.ParseWebhook(payload, signature)
+	s.Require().NoError(err)
+
+	failed, failedSignature, err := fake.Renew(completed.SubscriptionID, false)
+	s.Require().NoError(err)
+	s.Require().Nil(billing.HandleWebhook(s.ctx, failed, failedSignature))
+	subscription, mErr := s.svc.Get(s.ctx, &s.owner.ID)
+	s.Require().Nil(mErr)
+	s.Equal(entity.SubscriptionPastDue, subscription.Status)
+
+	paid, paidSignature, err := fake.Renew(completed.SubscriptionID, true)
+	s.Require().NoError(err)
+	s.Require().Nil(billing.HandleWebhook(s.ctx, paid, paidSignature))
+	s.Require().Nil(billing.HandleWebhook(s.ctx, failed, failedSignature), "a webhook sent again is applied once")
+	subscription, mErr = s.svc.Get(s.ctx, &s.owner.ID)
+	s.Require().Nil(mErr)
+	s.Equal(entity.SubscriptionActive, subscription.Status)
+
+	subscription, mErr = billing.Cancel(s.ctx, &s.owner.ID)
+	s.Require().Nil(mErr)
+	s.True(subscription.CancelAtPeriodEnd)
+
+	_, mErr = billing.Resume(s.ctx, &s.owner.ID)
+	s.Require().NotNil(mErr)
+	s.Equal(entity.ResponseCodeConflict, mErr.Code, "the charges were stopped")
+}
+
+func (s *SubscriptionServiceTestSuite) TestHandleWebhook_Claimed() {
+	repo, err := repository.NewBillingRepo(db.NewMemoryStore())
+	s.Require().NoError(err)
+	fake, err := payment.NewFakeProvider("secret", "")
+	s.Require().NoError(err)
+	billing, mErr := service.NewBillingSvc(repo, fake, s.svc, s.tenants, s.plans, s.users, payment.Config{})
+	s.Require().Nil(mErr)
+
+	checkout, mErr := billing.Checkout(s.ctx, &s.owner.ID, &entity.SubscriptionRequest{PlanID: s.gold.ID})
+	s.Require().Nil(mErr)
+	payload, signature, err := fake.Complete(checkout.ID)
+	s.Require().NoError(err)
+	event, err := fake.ParseWebhook(payload, signature)
+	s.Require().NoError(err)
+
+	// another request claimed the event and applies it
+	claimed, mErr := repo.ClaimPaymentEvent(s.ctx, &entity.PaymentEvent{ID: event.ID, ClaimedAt: time.Now().UTC()})
+	s.Require().Nil(mErr)
+	s.Require().True(claimed)
+
+	mErr = billing.HandleWebhook(s.ctx, payload, signature)
+	s.Require().NotNil(mErr)
+	s.Equal(entity.ResponseCodeConflict, mErr.Code, "the provider sends it again")
+	s.Equal(s.silver.ID, s.plan().ID, "the event is applied once")
+
+	// the request failed and released the claim
+	s.Require().Nil(repo.ReleasePaymentEvent(s.ctx, event.ID))
+	s.Require().Nil(billing.HandleWebhook(s.ctx, payload, signature))
+	s.Equal(s.gold.ID, s.plan().ID)
+
+	processed, mErr := repo.GetPaymentEvent(s.ctx, event.ID)
+	s.Require().Nil(mErr)
+	s.False(processed.ProcessedAt.IsZero())
+	s.Require().Nil(billing.HandleWebhook(s.ctx, payload, signature), "an applied event is skipped")
+}
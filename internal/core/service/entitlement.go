@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+)
+
+type EntitlementSvc struct {
+	tenant entity.ITenant
+}
+
+// NewEntitlementSvc creates the service that resolves the features of the plan of a tenant
+func NewEntitlementSvc(tenant entity.ITenant) (entity.IEntitlement, *entity.ModuleError) {
+	if tenant == nil {
+		return nil, entity.Error("tenant is required", "entitlement", "inicialization", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	}
+
+	return &EntitlementSvc{tenant: tenant}, nil
+}
+
+func (e *EntitlementSvc) Get(ctx context.Context, tenantId *string, key string) (*entity.Entitlement, *entity.ModuleError) {
+	if tenantId == nil || *tenantId == "" {
+		return nil, entity.Error("tenant id cannot be empty", "entitlement", "Get", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	}
+
+	tenant, err := e.tenant.GetById(tenantId)
+	if err != nil {
+		return nil, entity.Error(err.Error(), "entitlement", "Get", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	}
+
+	if tenant == nil {
+		return nil, entity.Error("tenant not found", "entitlement", "Get", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	}
+
+	entitlement := tenant.Plan.Entitlement(key)
+	return &entitlement, nil
+}
+
+func (e *EntitlementSvc) Check(ctx context.Context, tenantId *string, key string, usage int) *entity.ModuleError {
+	entitlement, mErr := e.Get(ctx, tenantId, key)
+	if mErr != nil {
+		return mErr
+	}
+
+	return entitlement.Check(usage)
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/internal/core/repository"
+	"github.com/Tomelin/financial-management-backend/pkg/logger"
+)
+
+type IdentitySvc struct {
+	repo        repository.IIdentityRepo
+	credentials repository.ILocalAuthRepo
+	user        entity.IUser
+	log         logger.Logger
+}
+
+func NewIdentitySvc(repo repository.IIdentityRepo, credentials repository.ILocalAuthRepo, user entity.IUser, l logger.Logger) (entity.IIdentity, error) {
+	if repo == nil || credentials == nil || user == nil {
+		return nil, l.Error(&logger.Message{
+			Body: "identity repository, credential repository and user service are required",
+			Code: logger.ResponseCodeInternalServer,
+		})
+	}
+
+	return &IdentitySvc{
+		repo:        repo,
+		credentials: credentials,
+		user:        user,
+		log:         l,
+	}, nil
+}
+
+func (a *IdentitySvc) Resolve(ctx context.Context, identity *entity.ExternalIdentity) (*entity.AccountUser, bool, error) {
+	if err := identity.Validate(); err != nil {
+		return nil, false, fmt.Errorf("%w: %s", entity.ErrInvalidRequest, err.Error())
+	}
+
+	linked, err := a.repo.GetIdentity(ctx, identity.Provider, identity.ProviderUserID)
+	if err != nil {
+		return nil, false, err
+	}
+	if linked != nil {
+		user, err := a.user.GetById(ctx, &linked.UserID)
+		return user, false, err
+	}
+
+	existing, err := userByEmail(ctx, a.user, identity.User.Email)
+	if err != nil {
+		return nil, false, err
+	}
+	if existing != nil {
+		// only the provider proves the account is of the owner of the email
+		if !identity.EmailVerified {
+			return nil, false, entity.ErrIdentityConflict
+		}
+		if err := a.distrustPassword(ctx, existing.ID); err != nil {
+			return nil, false, err
+		}
+		if _, err := a.link(ctx, existing.ID, identity); err != nil {
+			if errors.Is(err, entity.ErrProviderLinked) {
+				return nil, false, entity.ErrIdentityConflict
+			}
+			return nil, false, err
+		}
+		return existing, false, nil
+	}
+
+	profile := identity.User
+	profile.Email = entity.NormalizeEmail(profile.Email)
+	profile.Provider = identity.Provider
+	profile.UserID = identity.ProviderUserID
+	user, err := entity.NewUser(&profile)
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: %s", entity.ErrInvalidRequest, err.Error())
+	}
+
+	created, err := a.user.Create(ctx, user)
+	if err != nil {
+		if err.Error() == "user already exists" {
+			return nil, false, entity.ErrIdentityConflict
+		}
+		return nil, false, err
+	}
+
+	if _, err := a.link(ctx, created.ID, identity); err != nil {
+		// a concurrent login linked the provider account first
+		if dErr := a.user.Delete(ctx, &created.ID); dErr != nil {
+			a.log.Warn(&logger.Message{Body: fmt.Sprintf("error deleting the user %s: %s", created.ID, dErr.Error()), Code: logger.ResponseCodeInternalServer})
+		}
+		return nil, false, err
+	}
+
+	return created, true, nil
+}
+
+func (a *IdentitySvc) Link(ctx context.Context, userID string, identity *entity.ExternalIdentity) (*entity.Identity, error) {
+	if err := identity.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %s", entity.ErrInvalidRequest, err.Error())
+	}
+	if userID == "" {
+		return nil, fmt.Errorf("%w: user ID is required", entity.ErrInvalidRequest)
+	}
+
+	return a.link(ctx, userID, identity)
+}
+
+// Unlink removes the provider of the user while the user has another way to sign in, another provider or a password
+func (a *IdentitySvc) Unlink(ctx context.Context, userID, provider string) error {
+	identities, err := a.repo.ListIdentities(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for _, identity := range identities {
+		if identity.Provider == provider {
+			found = true
+		}
+	}
+	if !found {
+		return entity.ErrIdentityNotFound
+	}
+
+	if len(identities) == 1 {
+		credential, err := a.credentials.GetCredential(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if credential == nil || credential.PasswordHash == "" {
+			return entity.ErrLastIdentity
+		}
+	}
+
+	return a.repo.DeleteIdentity(ctx, userID, provider)
+}
+
+func (a *IdentitySvc) List(ctx context.Context, userID string) ([]entity.Identity, error) {
+	return a.repo.ListIdentities(ctx, userID)
+}
+
+func (a *IdentitySvc) link(ctx context.Context, userID string, identity *entity.ExternalIdentity) (*entity.Identity, error) {
+	linked := &entity.Identity{
+		UserID:         userID,
+		Provider:       identity.Provider,
+		ProviderUserID: identity.ProviderUserID,
+		Email:          entity.NormalizeEmail(identity.User.Email),
+		LinkedAt:       time.Now().UTC(),
+	}
+
+	if err := a.repo.CreateIdentity(ctx, linked); err != nil {
+		return nil, err
+	}
+	return linked, nil
+}
+
+// distrustPassword clears the password of a credential whose email was never verified
+// Whoever registered it did not prove to own the email, the owner sets a password with the reset link
+func (a *IdentitySvc) distrustPassword(ctx context.Context, userID string) error {
+	credential, err := a.credentials.GetCredential(ctx, userID)
+	if err != nil || credential == nil || credential.EmailVerified {
+		return err
+	}
+
+	credential.PasswordHash = ""
+	credential.UpdatedAt = time.Now().UTC()
+	return a.credentials.UpdateCredential(ctx, credential)
+}
@@ -0,0 +1,180 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/internal/core/repository"
+	"github.com/Tomelin/financial-management-backend/internal/core/service"
+	"github.com/Tomelin/financial-management-backend/pkg/db"
+	"github.com/Tomelin/financial-management-backend/pkg/logger"
+	"github.com/Tomelin/financial-management-backend/tests/coremocks"
+)
+
+type IdentityServiceTestSuite struct {
+	suite.Suite
+	users       map[string]*entity.AccountUser
+	mockUser    *coremocks.IUser
+	credentials repository.ILocalAuthRepo
+	svc         entity.IIdentity
+	ctx         context.Context
+}
+
+func (s *IdentityServiceTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.users = map[string]*entity.AccountUser{}
+
+	byID := func(id string) *entity.AccountUser {
+		for _, user := range s.users {
+			if user.ID == id {
+				return user
+			}
+		}
+		return nil
+	}
+
+	s.mockUser = new(coremocks.IUser)
+	s.mockUser.On("GetByEmail", mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, email *string) *entity.AccountUser { return s.users[*email] },
+		func(ctx context.Context, email *string) error {
+			if _, ok := s.users[*email]; !ok {
+				return errors.New("not found")
+			}
+			return nil
+		})
+	s.mockUser.On("GetById", mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, id *string) *entity.AccountUser { return byID(*id) },
+		func(ctx context.Context, id *string) error {
+			if byID(*id) == nil {
+				return errors.New("not found")
+			}
+			return nil
+		})
+	s.mockUser.On("Create", mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, user *entity.AccountUser) *entity.AccountUser {
+			s.users[user.Email] = user
+			return user
+		}, nil)
+
+	l := logger.NewLoggerConfig(map[string]any{"level": "error"})
+	store := db.NewMemoryStore()
+	repo, err := repository.NewIdentityRepo(store, l)
+	s.Require().NoError(err)
+	s.credentials, err = repository.NewLocalAuthRepo(store, l)
+	s.Require().NoError(err)
+
+	svc, err := service.NewIdentitySvc(repo, s.credentials, s.mockUser, l)
+	s.Require().NoError(err)
+	s.svc = svc
+}
+
+func (s *IdentityServiceTestSuite) external(provider, providerUserID, email string, verified bool) *entity.ExternalIdentity {
+	return &entity.ExternalIdentity{
+		Provider:       provider,
+		ProviderUserID: providerUserID,
+		EmailVerified:  verified,
+		User:           entity.User{Name: "Teste", Email: email},
+	}
+}
+
+func (s *IdentityServiceTestSuite) TestResolve_CreatesOnceAndFindsByIdentity() {
+	user, created, err := s.svc.Resolve(s.ctx, s.external("github", "42", "User@Domain.com", false))
+	s.Require().NoError(err)
+	s.True(created)
+	s.Equal("github", user.Provider)
+	s.Equal("user@domain.com", user.Email)
+
+	// the email of the provider account changed, the identity still finds the user
+	again, created, err := s.svc.Resolve(s.ctx, s.external("github", "42", "other@domain.com", false))
+	s.Require().NoError(err)
+	s.False(created)
+	s.Equal(user.ID, again.ID)
+	s.Len(s.users, 1)
+}
+
+func (s *IdentityServiceTestSuite) TestResolve_VerifiedEmailLinksExistingUser() {
+	user, _, err := s.svc.Resolve(s.ctx, s.external("google", "1", "user@domain.com", true))
+	s.Require().NoError(err)
+
+	linked, created, err := s.svc.Resolve(s.ctx, s.external("github", "42", "user@domain.com", true))
+	s.Require().NoError(err)
+	s.False(created)
+	s.Equal(user.ID, linked.ID, "the second provider is linked to the same user")
+
+	identities, err := s.svc.List(s.ctx, user.ID)
+	s.Require().NoError(err)
+	s.Len(identities, 2)
+}
+
+func (s *IdentityServiceTestSuite) TestResolve_UnverifiedEmailConflicts() {
+	_, _, err := s.svc.Resolve(s.ctx, s.external("google", "1", "user@domain.com", true))
+	s.Require().NoError(err)
+
+	_, _, err = s.svc.Resolve(s.ctx, s.external("github", "42", "user@domain.com", false))
+	s.True(errors.Is(err, entity.ErrIdentityConflict))
+
+	// another account of a linked provider is not merged into the user
+	_, _, err = s.svc.Resolve(s.ctx, s.external("google", "2", "user@domain.com", true))
+	s.True(errors.Is(err, entity.ErrIdentityConflict))
+}
+
+func (s *IdentityServiceTestSuite) TestResolve_ClearsUnverifiedPassword() {
+	user, err := entity.NewUser(&entity.User{Name: "Teste", Email: "user@domain.com", Provider: entity.ProviderLocal})
+	s.Require().NoError(err)
+	s.users[user.Email] = user
+	s.Require().NoError(s.credentials.CreateCredential(s.ctx, &entity.Credential{UserID: user.ID, PasswordHash: "hash", CreatedAt: time.Now(), UpdatedAt: time.Now()}))
+
+	linked, _, err := s.svc.Resolve(s.ctx, s.external("google", "1", "user@domain.com", true))
+	s.Require().NoError(err)
+	s.Equal(user.ID, linked.ID)
+
+	credential, err := s.credentials.GetCredential(s.ctx, user.ID)
+	s.Require().NoError(err)
+	s.Empty(credential.PasswordHash, "whoever registered the email did not prove to own it")
+}
+
+func (s *IdentityServiceTestSuite) TestLinkAndUnlink() {
+	user, _, err := s.svc.Resolve(s.ctx, s.external("google", "1", "user@domain.com", true))
+	s.Require().NoError(err)
+	other, _, err := s.svc.Resolve(s.ctx, s.external("google", "2", "other@domain.com", true))
+	s.Require().NoError(err)
+
+	_, err = s.svc.Link(s.ctx, other.ID, s.external("google", "1", "user@domain.com", true))
+	s.True(errors.Is(err, entity.ErrIdentityLinked))
+	_, err = s.svc.Link(s.ctx, user.ID, s.external("google", "3", "user@domain.com", true))
+	s.True(errors.Is(err, entity.ErrProviderLinked))
+
+	identity, err := s.svc.Link(s.ctx, user.ID, s.external("github", "42", "user@users.github.com", false))
+	s.Require().NoError(err)
+	s.Equal("github", identity.Provider)
+
+	s.True(errors.Is(s.svc.Unlink(s.ctx, user.ID, "gitlab"), entity.ErrIdentityNotFound))
+	s.Require().NoError(s.svc.Unlink(s.ctx, user.ID, "google"))
+	s.True(errors.Is(s.svc.Unlink(s.ctx, user.ID, "github"), entity.ErrLastIdentity))
+
+	// with a password the last provider can be unlinked
+	s.Require().NoError(s.credentials.CreateCredential(s.ctx, &entity.Credential{UserID: user.ID, PasswordHash: "hash", EmailVerified: true, CreatedAt: time.Now(), UpdatedAt: time.Now()}))
+	s.NoError(s.svc.Unlink(s.ctx, user.ID, "github"))
+
+	identities, err := s.svc.List(s.ctx, user.ID)
+	s.Require().NoError(err)
+	s.Empty(identities)
+}
+
+func (s *IdentityServiceTestSuite) TestResolve_Invalid() {
+	_, _, err := s.svc.Resolve(s.ctx, s.external("github", "", "user@domain.com", true))
+	s.True(errors.Is(err, entity.ErrInvalidRequest))
+
+	_, _, err = s.svc.Resolve(s.ctx, s.external("github", "42", "user", true))
+	s.True(errors.Is(err, entity.ErrInvalidRequest))
+}
+
+func TestIdentityServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(IdentityServiceTestSuite))
+}
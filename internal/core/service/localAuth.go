@@ -0,0 +1,338 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/internal/core/repository"
+	"github.com/Tomelin/financial-management-backend/pkg/logger"
+	"github.com/Tomelin/financial-management-backend/pkg/mail"
+	"github.com/Tomelin/financial-management-backend/pkg/password"
+)
+
+// LocalAuthConfig has the links of the emails of the local authentication
+// The token is added to the link as the token query parameter
+type LocalAuthConfig struct {
+	VerifyEmailURL   string
+	ResetPasswordURL string
+}
+
+type LocalAuthSvc struct {
+	repo      repository.ILocalAuthRepo
+	user      entity.IUser
+	auth      entity.IAuthorization
+	twoFactor entity.ITwoFactor
+	sender    mail.Sender
+	config    LocalAuthConfig
+	log       logger.Logger
+
+	// dummyHash is verified when the email is unknown, so the login takes the same time
+	dummyOnce sync.Once
+	dummyHash string
+}
+
+func NewLocalAuthSvc(repo repository.ILocalAuthRepo, user entity.IUser, auth entity.IAuthorization, twoFactor entity.ITwoFactor, sender mail.Sender, config LocalAuthConfig, l logger.Logger) (entity.ILocalAuth, error) {
+	if repo == nil || user == nil || auth == nil || twoFactor == nil || sender == nil {
+		return nil, l.Error(&logger.Message{
+			Body: "repository, user service, authorization service, two-factor service and mail sender are required",
+			Code: logger.ResponseCodeInternalServer,
+		})
+	}
+
+	return &LocalAuthSvc{
+		repo:      repo,
+		user:      user,
+		auth:      auth,
+		twoFactor: twoFactor,
+		sender:    sender,
+		config:    config,
+		log:       l,
+	}, nil
+}
+
+func (a *LocalAuthSvc) Register(ctx context.Context, request *entity.RegisterRequest) (*entity.AccountUser, error) {
+	if err := request.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %s", entity.ErrInvalidRequest, err.Error())
+	}
+
+	existing, err := userByEmail(ctx, a.user, request.Email)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, entity.ErrEmailInUse
+	}
+
+	hash, err := password.Hash(request.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := entity.NewUser(&entity.User{
+		Name:     request.Name,
+		Email:    request.Email,
+		Provider: entity.ProviderLocal,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", entity.ErrInvalidRequest, err.Error())
+	}
+
+	created, err := a.user.Create(ctx, user)
+	if err != nil {
+		if err.Error() == "user already exists" {
+			return nil, entity.ErrEmailInUse
+		}
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	err = a.repo.CreateCredential(ctx, &entity.Credential{
+		UserID:       created.ID,
+		PasswordHash: hash,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	})
+	if err != nil {
+		// a user without credential could never sign in
+		if dErr := a.user.Delete(ctx, &created.ID); dErr != nil {
+			a.log.Warn(&logger.Message{Body: fmt.Sprintf("error deleting the user %s: %s", created.ID, dErr.Error()), Code: logger.ResponseCodeInternalServer})
+		}
+		return nil, err
+	}
+
+	if err := a.sendVerification(ctx, created); err != nil {
+		// the user asks a new link with ResendVerification
+		a.log.Warn(&logger.Message{Body: fmt.Sprintf("error sending the verification email: %s", err.Error()), Code: logger.ResponseCodeInternalServer})
+	}
+
+	return created, nil
+}
+
+// Login verifies the password, the error wraps entity.ErrUnauthorized when it is not accepted
+// The user of the second factor gets its challenge instead of the token pair
+func (a *LocalAuthSvc) Login(ctx context.Context, request *entity.LoginRequest) (*entity.LoginResult, error) {
+	if request == nil || request.Email == "" || request.Password == "" {
+		return nil, fmt.Errorf("%w: email and password are required", entity.ErrInvalidRequest)
+	}
+
+	user, credential, err := a.credential(ctx, request.Email)
+	if err != nil {
+		return nil, err
+	}
+	if credential == nil {
+		_, _ = password.Verify(request.Password, a.dummy())
+		return nil, fmt.Errorf("%w: %w", entity.ErrUnauthorized, entity.ErrInvalidCredentials)
+	}
+
+	ok, err := password.Verify(request.Password, credential.PasswordHash)
+	if err != nil || !ok {
+		return nil, fmt.Errorf("%w: %w", entity.ErrUnauthorized, entity.ErrInvalidCredentials)
+	}
+
+	if !credential.EmailVerified {
+		return nil, entity.ErrEmailNotVerified
+	}
+
+	if password.NeedsRehash(credential.PasswordHash, password.DefaultParams) {
+		a.rehash(ctx, credential, request.Password)
+	}
+
+	return a.twoFactor.Login(ctx, user)
+}
+
+func (a *LocalAuthSvc) VerifyEmail(ctx context.Context, token string) error {
+	credential, err := a.useActionToken(ctx, token, entity.ActionTokenVerifyEmail)
+	if err != nil {
+		return err
+	}
+
+	credential.EmailVerified = true
+	credential.UpdatedAt = time.Now().UTC()
+	return a.repo.UpdateCredential(ctx, credential)
+}
+
+func (a *LocalAuthSvc) ResendVerification(ctx context.Context, email string) error {
+	user, credential, err := a.credential(ctx, email)
+	if err != nil || credential == nil || credential.EmailVerified {
+		return err
+	}
+
+	return a.sendVerification(ctx, user)
+}
+
+func (a *LocalAuthSvc) ForgotPassword(ctx context.Context, email string) error {
+	user, credential, err := a.credential(ctx, email)
+	if err != nil || credential == nil {
+		return err
+	}
+
+	token, err := a.newActionToken(ctx, user.ID, entity.ActionTokenResetPassword, entity.PasswordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	return a.sender.Send(ctx, &mail.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hello %s,\n\nUse the link below to choose a new password, it expires in %s:\n\n%s\n\nIf you did not ask to reset your password, ignore this email.",
+			user.Name, entity.PasswordResetTTL, link(a.config.ResetPasswordURL, token)),
+	})
+}
+
+func (a *LocalAuthSvc) ResetPassword(ctx context.Context, request *entity.ResetPasswordRequest) error {
+	if err := request.Validate(); err != nil {
+		return fmt.Errorf("%w: %s", entity.ErrInvalidRequest, err.Error())
+	}
+
+	hash, err := password.Hash(request.Password)
+	if err != nil {
+		return err
+	}
+
+	credential, err := a.useActionToken(ctx, request.Token, entity.ActionTokenResetPassword)
+	if err != nil {
+		return err
+	}
+
+	// the link was received by email, the email is verified
+	credential.PasswordHash = hash
+	credential.EmailVerified = true
+	credential.UpdatedAt = time.Now().UTC()
+	if err := a.repo.UpdateCredential(ctx, credential); err != nil {
+		return err
+	}
+
+	return a.auth.RevokeAllTokenJWT(ctx, credential.UserID)
+}
+
+// credential returns the user of the email and its credential, both are nil when the email is unknown
+func (a *LocalAuthSvc) credential(ctx context.Context, email string) (*entity.AccountUser, *entity.Credential, error) {
+	user, err := userByEmail(ctx, a.user, email)
+	if err != nil || user == nil {
+		return nil, nil, err
+	}
+
+	credential, err := a.repo.GetCredential(ctx, user.ID)
+	if err != nil || credential == nil {
+		return nil, nil, err
+	}
+	return user, credential, nil
+}
+
+// userByEmail returns nil when the email is unknown or invalid
+func userByEmail(ctx context.Context, users entity.IUser, email string) (*entity.AccountUser, error) {
+	email = entity.NormalizeEmail(email)
+	if email == "" {
+		return nil, nil
+	}
+
+	user, err := users.GetByEmail(ctx, &email)
+	if err != nil {
+		if err.Error() == "not found" || err.Error() == "email is invalid" {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if user == nil || user.ID == "" {
+		return nil, nil
+	}
+	return user, nil
+}
+
+func (a *LocalAuthSvc) sendVerification(ctx context.Context, user *entity.AccountUser) error {
+	token, err := a.newActionToken(ctx, user.ID, entity.ActionTokenVerifyEmail, entity.EmailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	return a.sender.Send(ctx, &mail.Message{
+		To:      user.Email,
+		Subject: "Verify your email",
+		Body: fmt.Sprintf("Hello %s,\n\nUse the link below to verify your email, it expires in %s:\n\n%s",
+			user.Name, entity.EmailVerificationTTL, link(a.config.VerifyEmailURL, token)),
+	})
+}
+
+func (a *LocalAuthSvc) newActionToken(ctx context.Context, userID string, purpose entity.ActionTokenPurpose, ttl time.Duration) (string, error) {
+	token, record, err := entity.NewActionToken(userID, purpose, ttl)
+	if err != nil {
+		return "", err
+	}
+
+	if err := a.repo.CreateActionToken(ctx, record); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// useActionToken uses the token of the purpose and returns the credential of its user
+func (a *LocalAuthSvc) useActionToken(ctx context.Context, token string, purpose entity.ActionTokenPurpose) (*entity.Credential, error) {
+	if token == "" {
+		return nil, entity.ErrInvalidActionToken
+	}
+
+	record, err := a.repo.GetActionToken(ctx, entity.ActionTokenID(token))
+	if err != nil {
+		return nil, err
+	}
+	if !record.IsValid(purpose, time.Now()) {
+		return nil, entity.ErrInvalidActionToken
+	}
+
+	used, err := a.repo.UseActionToken(ctx, record.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !used {
+		return nil, entity.ErrInvalidActionToken
+	}
+
+	credential, err := a.repo.GetCredential(ctx, record.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if credential == nil {
+		return nil, entity.ErrInvalidActionToken
+	}
+	return credential, nil
+}
+
+// rehash updates the hash made with old parameters, a failure does not fail the login
+func (a *LocalAuthSvc) rehash(ctx context.Context, credential *entity.Credential, plain string) {
+	hash, err := password.Hash(plain)
+	if err == nil {
+		credential.PasswordHash = hash
+		credential.UpdatedAt = time.Now().UTC()
+		err = a.repo.UpdateCredential(ctx, credential)
+	}
+	if err != nil {
+		a.log.Warn(&logger.Message{Body: fmt.Sprintf("error updating the password hash: %s", err.Error()), Code: logger.ResponseCodeInternalServer})
+	}
+}
+
+func (a *LocalAuthSvc) dummy() string {
+	a.dummyOnce.Do(func() {
+		a.dummyHash, _ = password.Hash("dummy password of the unknown emails")
+	})
+	return a.dummyHash
+}
+
+// link adds the token to the link, without a link the email has only the token
+func link(base, token string) string {
+	if base == "" {
+		return token
+	}
+
+	u, err := url.Parse(base)
+	if err != nil {
+		return base + "?token=" + url.QueryEscape(token)
+	}
+	query := u.Query()
+	query.Set("token", token)
+	u.RawQuery = query.Encode()
+	return u.String()
+}
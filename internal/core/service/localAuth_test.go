@@ -0,0 +1,225 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/internal/core/repository"
+	"github.com/Tomelin/financial-management-backend/internal/core/service"
+	"github.com/Tomelin/financial-management-backend/pkg/db"
+	"github.com/Tomelin/financial-management-backend/pkg/logger"
+	"github.com/Tomelin/financial-management-backend/pkg/mail"
+	"github.com/Tomelin/financial-management-backend/tests/coremocks"
+)
+
+// outbox records the emails instead of sending them
+type outbox struct {
+	mu       sync.Mutex
+	messages []mail.Message
+}
+
+func (o *outbox) Send(ctx context.Context, message *mail.Message) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.messages = append(o.messages, *message)
+	return nil
+}
+
+// token returns the token of the link of the last email
+func (o *outbox) token() string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if len(o.messages) == 0 {
+		return ""
+	}
+
+	for _, line := range strings.Split(o.messages[len(o.messages)-1].Body, "\n") {
+		if u, err := url.Parse(line); err == nil && u.Query().Get("token") != "" {
+			return u.Query().Get("token")
+		}
+	}
+	return ""
+}
+
+type LocalAuthServiceTestSuite struct {
+	suite.Suite
+	users    map[string]*entity.AccountUser
+	mockUser *coremocks.IUser
+	mockAuth *coremocks.IAuthorization
+	outbox   *outbox
+	svc      entity.ILocalAuth
+	ctx      context.Context
+}
+
+func (s *LocalAuthServiceTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.users = map[string]*entity.AccountUser{}
+	s.outbox = &outbox{}
+
+	s.mockUser = new(coremocks.IUser)
+	s.mockUser.On("GetByEmail", mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, email *string) *entity.AccountUser { return s.users[*email] },
+		func(ctx context.Context, email *string) error {
+			if _, ok := s.users[*email]; !ok {
+				return errors.New("not found")
+			}
+			return nil
+		})
+	s.mockUser.On("Create", mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, user *entity.AccountUser) *entity.AccountUser {
+			s.users[user.Email] = user
+			return user
+		}, nil)
+
+	s.mockAuth = new(coremocks.IAuthorization)
+	s.mockAuth.On("GenerateTokenJWT", mock.Anything, mock.Anything, mock.Anything).Return(&entity.TokenPair{AccessToken: "access", TokenType: "Bearer"}, nil)
+	s.mockAuth.On("RevokeAllTokenJWT", mock.Anything, mock.Anything).Return(nil)
+
+	l := logger.NewLoggerConfig(map[string]any{"level": "error"})
+	store := db.NewMemoryStore()
+	repo, err := repository.NewLocalAuthRepo(store, l)
+	s.Require().NoError(err)
+	repoTwoFactor, err := repository.NewTwoFactorRepo(store, l)
+	s.Require().NoError(err)
+	twoFactor, err := service.NewTwoFactorSvc(repoTwoFactor, repo, s.mockUser, new(coremocks.ITenant), s.mockAuth, l)
+	s.Require().NoError(err)
+
+	svc, err := service.NewLocalAuthSvc(repo, s.mockUser, s.mockAuth, twoFactor, s.outbox, service.LocalAuthConfig{
+		VerifyEmailURL:   "https://app.domain.com/verify",
+		ResetPasswordURL: "https://app.domain.com/reset?lang=en",
+	}, l)
+	s.Require().NoError(err)
+	s.svc = svc
+}
+
+func (s *LocalAuthServiceTestSuite) register() *entity.AccountUser {
+	user, err := s.svc.Register(s.ctx, &entity.RegisterRequest{Name: "Teste", Email: " User@Domain.com ", Password: "password-1"})
+	s.Require().NoError(err)
+	return user
+}
+
+func (s *LocalAuthServiceTestSuite) TestRegister_VerifyAndLogin() {
+	user := s.register()
+	s.Equal("user@domain.com", user.Email)
+	s.Equal(entity.ProviderLocal, user.Provider)
+
+	s.Require().Len(s.outbox.messages, 1)
+	s.Equal("user@domain.com", s.outbox.messages[0].To)
+	s.Contains(s.outbox.messages[0].Body, "https://app.domain.com/verify?token=")
+
+	_, err := s.svc.Login(s.ctx, &entity.LoginRequest{Email: "user@domain.com", Password: "password-1"})
+	s.True(errors.Is(err, entity.ErrEmailNotVerified))
+
+	token := s.outbox.token()
+	s.Require().NotEmpty(token)
+	s.Require().NoError(s.svc.VerifyEmail(s.ctx, token))
+	s.True(errors.Is(s.svc.VerifyEmail(s.ctx, token), entity.ErrInvalidActionToken), "the link is used once")
+
+	pair, err := s.svc.Login(s.ctx, &entity.LoginRequest{Email: "USER@domain.com", Password: "password-1"})
+	s.Require().NoError(err)
+	s.Equal("access", pair.AccessToken)
+	s.mockAuth.AssertCalled(s.T(), "GenerateTokenJWT", mock.Anything, mock.Anything, user)
+}
+
+func (s *LocalAuthServiceTestSuite) TestRegister_Invalid() {
+	tests := map[string]*entity.RegisterRequest{
+		"no name":        {Email: "user@domain.com", Password: "password-1"},
+		"invalid email":  {Name: "Teste", Email: "user", Password: "password-1"},
+		"short password": {Name: "Teste", Email: "user@domain.com", Password: "short"},
+		"long password":  {Name: "Teste", Email: "user@domain.com", Password: strings.Repeat("a", entity.PasswordMaxLength+1)},
+	}
+
+	for name, request := range tests {
+		_, err := s.svc.Register(s.ctx, request)
+		s.True(errors.Is(err, entity.ErrInvalidRequest), name)
+	}
+
+	s.register()
+	_, err := s.svc.Register(s.ctx, &entity.RegisterRequest{Name: "Other", Email: "user@domain.com", Password: "password-2"})
+	s.True(errors.Is(err, entity.ErrEmailInUse))
+}
+
+func (s *LocalAuthServiceTestSuite) TestLogin_InvalidCredentials() {
+	s.register()
+	s.Require().NoError(s.svc.VerifyEmail(s.ctx, s.outbox.token()))
+
+	tests := map[string]*entity.LoginRequest{
+		"wrong password": {Email: "user@domain.com", Password: "password-2"},
+		"unknown email":  {Email: "other@domain.com", Password: "password-1"},
+	}
+
+	for name, request := range tests {
+		_, err := s.svc.Login(s.ctx, request)
+		s.True(errors.Is(err, entity.ErrUnauthorized), name)
+		s.True(errors.Is(err, entity.ErrInvalidCredentials), name)
+	}
+
+	// a user of another provider has no password
+	s.users["google@domain.com"] = &entity.AccountUser{ID: uuid.New().String(), User: entity.User{Email: "google@domain.com", Provider: "google"}}
+	_, err := s.svc.Login(s.ctx, &entity.LoginRequest{Email: "google@domain.com", Password: "password-1"})
+	s.True(errors.Is(err, entity.ErrInvalidCredentials))
+
+	s.mockAuth.AssertNotCalled(s.T(), "GenerateTokenJWT", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *LocalAuthServiceTestSuite) TestResendVerification() {
+	s.register()
+	first := s.outbox.token()
+
+	s.Require().NoError(s.svc.ResendVerification(s.ctx, "user@domain.com"))
+	s.Require().Len(s.outbox.messages, 2)
+	s.NotEqual(first, s.outbox.token())
+
+	s.NoError(s.svc.ResendVerification(s.ctx, "other@domain.com"), "an unknown email is not told apart")
+	s.Len(s.outbox.messages, 2)
+
+	s.Require().NoError(s.svc.VerifyEmail(s.ctx, s.outbox.token()))
+	s.NoError(s.svc.ResendVerification(s.ctx, "user@domain.com"))
+	s.Len(s.outbox.messages, 2, "a verified email is not sent again")
+}
+
+func (s *LocalAuthServiceTestSuite) TestForgotAndResetPassword() {
+	user := s.register()
+
+	s.NoError(s.svc.ForgotPassword(s.ctx, "other@domain.com"), "an unknown email is not told apart")
+	s.Len(s.outbox.messages, 1)
+
+	s.Require().NoError(s.svc.ForgotPassword(s.ctx, "user@domain.com"))
+	s.Require().Len(s.outbox.messages, 2)
+	s.Contains(s.outbox.messages[1].Body, "https://app.domain.com/reset?lang=en&token=")
+	token := s.outbox.token()
+
+	s.True(errors.Is(s.svc.ResetPassword(s.ctx, &entity.ResetPasswordRequest{Token: token, Password: "short"}), entity.ErrInvalidRequest))
+	s.True(errors.Is(s.svc.ResetPassword(s.ctx, &entity.ResetPasswordRequest{Token: "unknown", Password: "password-2"}), entity.ErrInvalidActionToken))
+
+	s.Require().NoError(s.svc.ResetPassword(s.ctx, &entity.ResetPasswordRequest{Token: token, Password: "password-2"}))
+	s.mockAuth.AssertCalled(s.T(), "RevokeAllTokenJWT", mock.Anything, user.ID)
+	s.True(errors.Is(s.svc.ResetPassword(s.ctx, &entity.ResetPasswordRequest{Token: token, Password: "password-3"}), entity.ErrInvalidActionToken))
+
+	// the reset verifies the email
+	_, err := s.svc.Login(s.ctx, &entity.LoginRequest{Email: "user@domain.com", Password: "password-1"})
+	s.True(errors.Is(err, entity.ErrInvalidCredentials))
+	_, err = s.svc.Login(s.ctx, &entity.LoginRequest{Email: "user@domain.com", Password: "password-2"})
+	s.NoError(err)
+}
+
+func (s *LocalAuthServiceTestSuite) TestVerifyEmail_OtherPurpose() {
+	s.register()
+	s.Require().NoError(s.svc.ForgotPassword(s.ctx, "user@domain.com"))
+
+	s.True(errors.Is(s.svc.VerifyEmail(s.ctx, s.outbox.token()), entity.ErrInvalidActionToken))
+	s.True(errors.Is(s.svc.VerifyEmail(s.ctx, ""), entity.ErrInvalidActionToken))
+}
+
+func TestLocalAuthServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(LocalAuthServiceTestSuite))
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/internal/core/repository"
+	"github.com/Tomelin/financial-management-backend/pkg/logger"
+)
+
+type PersonalAccessTokenSvc struct {
+	repo repository.IPersonalAccessTokenRepo
+	user entity.IUser
+	log  logger.Logger
+}
+
+func NewPersonalAccessTokenSvc(repo repository.IPersonalAccessTokenRepo, user entity.IUser, l logger.Logger) (entity.IPersonalAccessToken, error) {
+	if repo == nil || user == nil {
+		return nil, l.Error(&logger.Message{
+			Body: "personal access token repository and user service are required",
+			Code: logger.ResponseCodeInternalServer,
+		})
+	}
+
+	return &PersonalAccessTokenSvc{
+		repo: repo,
+		user: user,
+		log:  l,
+	}, nil
+}
+
+// Create does not grant a scope the user does not have, the principal of the request is used when it is the same user
+func (a *PersonalAccessTokenSvc) Create(ctx context.Context, userID string, request *entity.PersonalAccessTokenRequest) (*entity.PersonalAccessTokenCreated, error) {
+	token, record, err := entity.NewPersonalAccessToken(userID, request)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", entity.ErrInvalidRequest, err.Error())
+	}
+
+	user, err := a.user.GetById(ctx, &userID)
+	if err != nil {
+		return nil, err
+	}
+	principal, ok := entity.PrincipalFromContext(ctx)
+	if !ok || principal.User.ID != userID {
+		if principal, err = entity.NewPrincipal(user, nil); err != nil {
+			return nil, err
+		}
+	}
+	for _, scope := range record.Scopes {
+		if !principal.Can(scope.Module, scope.Level) {
+			return nil, fmt.Errorf("%w: the user does not have the scope %s %s", entity.ErrInvalidRequest, scope.Module, scope.Level)
+		}
+	}
+
+	if err := a.repo.CreateAccessToken(ctx, record); err != nil {
+		return nil, err
+	}
+
+	return &entity.PersonalAccessTokenCreated{
+		Token:               token,
+		PersonalAccessToken: *record,
+	}, nil
+}
+
+func (a *PersonalAccessTokenSvc) List(ctx context.Context, userID string) ([]entity.PersonalAccessToken, error) {
+	return a.repo.ListAccessTokens(ctx, userID)
+}
+
+func (a *PersonalAccessTokenSvc) Revoke(ctx context.Context, userID, id string) error {
+	return a.repo.DeleteAccessToken(ctx, userID, id)
+}
+
+func (a *PersonalAccessTokenSvc) Authenticate(ctx context.Context, token string) (*entity.Principal, error) {
+	if !entity.IsPersonalAccessToken(token) {
+		return nil, fmt.Errorf("%w: invalid token", entity.ErrUnauthorized)
+	}
+
+	hash := entity.PersonalAccessTokenHash(token)
+	record, err := a.repo.GetAccessTokenByHash(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	if record == nil || record.IsExpired(now) {
+		return nil, fmt.Errorf("%w: invalid or expired token", entity.ErrUnauthorized)
+	}
+
+	user, err := a.user.GetById(ctx, &record.UserID)
+	if err != nil || user == nil || user.ID == "" {
+		return nil, fmt.Errorf("%w: user not found", entity.ErrUnauthorized)
+	}
+
+	if now.Sub(record.LastUsedAt) >= entity.PersonalAccessTokenLastUsedInterval {
+		// a failure does not fail the request
+		if err := a.repo.TouchAccessToken(ctx, hash, now); err != nil {
+			a.log.Warn(&logger.Message{Body: fmt.Sprintf("error updating the last use of the token %s: %s", record.ID, err.Error()), Code: logger.ResponseCodeInternalServer})
+		}
+	}
+
+	principal, err := entity.NewPrincipal(user, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", entity.ErrUnauthorized, err.Error())
+	}
+
+	principal.TokenID = record.ID
+	principal.ExpiresAt = record.ExpiresAt
+	// nil scopes are not limited, a token without scopes grants nothing
+	principal.Scopes = append([]entity.TokenScope{}, record.Scopes...)
+	return principal, nil
+}
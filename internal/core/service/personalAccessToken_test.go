@@ -0,0 +1,116 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/internal/core/repository"
+	"github.com/Tomelin/financial-management-backend/internal/core/service"
+	"github.com/Tomelin/financial-management-backend/pkg/db"
+	"github.com/Tomelin/financial-management-backend/pkg/logger"
+	"github.com/Tomelin/financial-management-backend/tests/coremocks"
+)
+
+type PersonalAccessTokenServiceTestSuite struct {
+	suite.Suite
+	repo repository.IPersonalAccessTokenRepo
+	user *entity.AccountUser
+	svc  entity.IPersonalAccessToken
+	ctx  context.Context
+}
+
+func (s *PersonalAccessTokenServiceTestSuite) SetupTest() {
+	s.ctx = context.Background()
+
+	var err error
+	s.user, err = entity.NewUser(&entity.User{Name: "Teste", Email: "user@domain.com", Provider: entity.ProviderLocal})
+	s.Require().NoError(err)
+	s.user.TenantID = uuid.New().String()
+
+	mockUser := new(coremocks.IUser)
+	mockUser.On("GetById", mock.Anything, mock.Anything).Return(s.user, nil)
+
+	l := logger.NewLoggerConfig(map[string]any{"level": "error"})
+	s.repo, err = repository.NewPersonalAccessTokenRepo(db.NewMemoryStore(), l)
+	s.Require().NoError(err)
+
+	s.svc, err = service.NewPersonalAccessTokenSvc(s.repo, mockUser, l)
+	s.Require().NoError(err)
+}
+
+func (s *PersonalAccessTokenServiceTestSuite) create(scopes ...entity.TokenScope) *entity.PersonalAccessTokenCreated {
+	created, err := s.svc.Create(s.ctx, s.user.ID, &entity.PersonalAccessTokenRequest{Name: "ci", Scopes: scopes})
+	s.Require().NoError(err)
+	return created
+}
+
+func (s *PersonalAccessTokenServiceTestSuite) TestCreate() {
+	created := s.create(entity.TokenScope{Module: entity.ModuleTransaction, Level: entity.PermissionView})
+	s.True(strings.HasPrefix(created.Token, entity.PersonalAccessTokenPrefix))
+	s.Equal(s.user.ID, created.UserID)
+
+	tokens, err := s.svc.List(s.ctx, s.user.ID)
+	s.Require().NoError(err)
+	s.Require().Len(tokens, 1)
+	s.Equal(created.ID, tokens[0].ID)
+
+	_, err = s.svc.Create(s.ctx, s.user.ID, &entity.PersonalAccessTokenRequest{Name: "ci"})
+	s.True(errors.Is(err, entity.ErrInvalidRequest), "a token needs a scope")
+	past := time.Now().Add(-time.Hour)
+	_, err = s.svc.Create(s.ctx, s.user.ID, &entity.PersonalAccessTokenRequest{Name: "ci", ExpiresAt: &past,
+		Scopes: []entity.TokenScope{{Module: entity.ModuleTransaction, Level: entity.PermissionView}}})
+	s.True(errors.Is(err, entity.ErrInvalidRequest))
+	_, err = s.svc.Create(s.ctx, s.user.ID, &entity.PersonalAccessTokenRequest{Name: "ci",
+		Scopes: []entity.TokenScope{{Module: entity.ModuleSystem, Level: entity.PermissionSystemView}}})
+	s.True(errors.Is(err, entity.ErrInvalidRequest), "the user does not have the scope")
+}
+
+func (s *PersonalAccessTokenServiceTestSuite) TestAuthenticate() {
+	created := s.create(entity.TokenScope{Module: entity.ModuleTransaction, Level: entity.PermissionView})
+
+	principal, err := s.svc.Authenticate(s.ctx, created.Token)
+	s.Require().NoError(err)
+	s.Equal(s.user.ID, principal.User.ID)
+	s.Equal(created.ID, principal.TokenID)
+	s.True(principal.Can(entity.ModuleTransaction, entity.PermissionView))
+	s.False(principal.Can(entity.ModuleTransaction, entity.PermissionEdit))
+	s.False(principal.Can(entity.ModuleWallet, entity.PermissionView))
+
+	tokens, err := s.svc.List(s.ctx, s.user.ID)
+	s.Require().NoError(err)
+	s.False(tokens[0].LastUsedAt.IsZero(), "the use of the token is recorded")
+
+	_, err = s.svc.Authenticate(s.ctx, "pat_unknown")
+	s.True(errors.Is(err, entity.ErrUnauthorized))
+	_, err = s.svc.Authenticate(s.ctx, "eyJhbGciOi")
+	s.True(errors.Is(err, entity.ErrUnauthorized))
+}
+
+func (s *PersonalAccessTokenServiceTestSuite) TestAuthenticate_ExpiredOrRevoked() {
+	created := s.create(entity.TokenScope{Module: entity.ModuleTransaction, Level: entity.PermissionView})
+
+	s.True(errors.Is(s.svc.Revoke(s.ctx, uuid.New().String(), created.ID), entity.ErrPersonalAccessTokenNotFound))
+	s.Require().NoError(s.svc.Revoke(s.ctx, s.user.ID, created.ID))
+	_, err := s.svc.Authenticate(s.ctx, created.Token)
+	s.True(errors.Is(err, entity.ErrUnauthorized), "a revoked token is not accepted")
+
+	token, record, err := entity.NewPersonalAccessToken(s.user.ID, &entity.PersonalAccessTokenRequest{Name: "old",
+		Scopes: []entity.TokenScope{{Module: entity.ModuleTransaction, Level: entity.PermissionView}}})
+	s.Require().NoError(err)
+	record.ExpiresAt = time.Now().UTC().Add(-time.Minute)
+	s.Require().NoError(s.repo.CreateAccessToken(s.ctx, record))
+	_, err = s.svc.Authenticate(s.ctx, token)
+	s.True(errors.Is(err, entity.ErrUnauthorized), "an expired token is not accepted")
+}
+
+func TestPersonalAccessTokenServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(PersonalAccessTokenServiceTestSuite))
+}
@@ -48,9 +48,9 @@ func (p *PlanSvc) Create(plan *entity.PlanResponse) (*entity.PlanResponse, error
 	return response, nil
 }
 
-func (p *PlanSvc) Get() ([]entity.PlanResponse, error) {
+func (p *PlanSvc) Get(page *entity.PageRequest) (*entity.Page[entity.PlanResponse], error) {
 
-	return p.repo.Get()
+	return p.repo.Get(page)
 }
 
 func (p *PlanSvc) GetById(id *string) (*entity.PlanResponse, error) {
@@ -125,17 +125,13 @@ func (p *PlanSvc) Delete(id *string) error {
 	return p.repo.Delete(id)
 }
 
-func (p *PlanSvc) GetByFilterMany(key string, value *string) ([]entity.PlanResponse, error) {
+func (p *PlanSvc) GetByFilterMany(filter []entity.QueryDBClause) ([]entity.PlanResponse, error) {
 
-	if key == "" {
-		return nil, fmt.Errorf("key %s", entity.ErrCannotEmpty)
-	}
-
-	if value == nil || *value == "" {
-		return nil, fmt.Errorf("value %s", entity.ErrCannotEmpty)
+	if isEmptyFilter(filter) {
+		return nil, fmt.Errorf("filter %s", entity.ErrCannotEmpty)
 	}
 
-	return p.repo.GetByFilterMany(key, value)
+	return p.repo.GetByFilterMany(filter)
 }
 
 func (p *PlanSvc) GetByFilterOne(key string, value *string) (*entity.PlanResponse, error) {
@@ -0,0 +1,384 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/internal/core/repository"
+)
+
+type SubscriptionSvc struct {
+	repo    repository.ISubscriptionRepo
+	tenant  ITenantService
+	plan    entity.IPlan
+	wallets repository.IWalletRepo
+	user    entity.IUser
+}
+
+// NewSubscriptionSvc creates the service of the subscriptions of the tenants to the plans
+func NewSubscriptionSvc(repo repository.ISubscriptionRepo, tenant ITenantService, plan entity.IPlan, wallets repository.IWalletRepo, user entity.IUser) (entity.ISubscription, *entity.ModuleError) {
+	if repo == nil || wallets == nil {
+		return nil, entity.Error("repo is required", "subscription", "inicialization", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	}
+
+	if tenant == nil || plan == nil || user == nil {
+		return nil, entity.Error("tenant, plan and user are required", "subscription", "inicialization", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	}
+
+	return &SubscriptionSvc{
+		repo:    repo,
+		tenant:  tenant,
+		plan:    plan,
+		wallets: wallets,
+		user:    user,
+	}, nil
+}
+
+func (s *SubscriptionSvc) Get(ctx context.Context, userId *string) (*entity.Subscription, *entity.ModuleError) {
+
+	tenant, mErr := s.getTenant(ctx, userId, false, "Get")
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	return s.load(ctx, tenant, time.Now().UTC(), "Get")
+}
+
+func (s *SubscriptionSvc) ChangePlan(ctx context.Context, userId *string, data *entity.SubscriptionRequest) (*entity.Subscription, *entity.ModuleError) {
+
+	tenant, mErr := s.getTenant(ctx, userId, true, "ChangePlan")
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	return s.changePlan(ctx, tenant, data, false, "ChangePlan")
+}
+
+func (s *SubscriptionSvc) ChangeTenantPlan(ctx context.Context, tenantId *string, data *entity.SubscriptionRequest) (*entity.Subscription, *entity.ModuleError) {
+
+	tenant, mErr := s.getTenantById(tenantId, "ChangeTenantPlan")
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	return s.changePlan(ctx, tenant, data, true, "ChangeTenantPlan")
+}
+
+func (s *SubscriptionSvc) Cancel(ctx context.Context, userId *string) (*entity.Subscription, *entity.ModuleError) {
+
+	tenant, mErr := s.getTenant(ctx, userId, true, "Cancel")
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	return s.cancel(ctx, tenant, "Cancel")
+}
+
+func (s *SubscriptionSvc) CancelTenant(ctx context.Context, tenantId *string) (*entity.Subscription, *entity.ModuleError) {
+
+	tenant, mErr := s.getTenantById(tenantId, "CancelTenant")
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	return s.cancel(ctx, tenant, "CancelTenant")
+}
+
+func (s *SubscriptionSvc) Resume(ctx context.Context, userId *string) (*entity.Subscription, *entity.ModuleError) {
+
+	tenant, mErr := s.getTenant(ctx, userId, true, "Resume")
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	now := time.Now().UTC()
+	subscription, mErr := s.load(ctx, tenant, now, "Resume")
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	if err := subscription.Resume(now); err != nil {
+		return nil, entity.Error(err.Error(), "subscription", "Resume", entity.ApplicationLayerService, entity.ResponseCodeConflict)
+	}
+
+	return s.save(ctx, subscription)
+}
+
+func (s *SubscriptionSvc) MarkPaid(ctx context.Context, tenantId *string) (*entity.Subscription, *entity.ModuleError) {
+
+	tenant, mErr := s.getTenantById(tenantId, "MarkPaid")
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	now := time.Now().UTC()
+	subscription, mErr := s.load(ctx, tenant, now, "MarkPaid")
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	if err := subscription.Paid(now); err != nil {
+		return nil, entity.Error(err.Error(), "subscription", "MarkPaid", entity.ApplicationLayerService, entity.ResponseCodeConflict)
+	}
+
+	return s.save(ctx, subscription)
+}
+
+func (s *SubscriptionSvc) MarkPastDue(ctx context.Context, tenantId *string) (*entity.Subscription, *entity.ModuleError) {
+
+	tenant, mErr := s.getTenantById(tenantId, "MarkPastDue")
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	now := time.Now().UTC()
+	subscription, mErr := s.load(ctx, tenant, now, "MarkPastDue")
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	if err := subscription.MarkPastDue(now); err != nil {
+		return nil, entity.Error(err.Error(), "subscription", "MarkPastDue", entity.ApplicationLayerService, entity.ResponseCodeConflict)
+	}
+
+	return s.save(ctx, subscription)
+}
+
+// Renew advances the subscriptions due, a tenant that fails does not stop the others
+func (s *SubscriptionSvc) Renew(ctx context.Context, now time.Time) *entity.ModuleError {
+
+	subscriptions, mErr := s.repo.GetSubscriptionsDue(ctx, now)
+	if mErr != nil {
+		return mErr
+	}
+
+	var failed []string
+	for i := range subscriptions {
+		tenant, err := s.tenant.GetById(&subscriptions[i].TenantID)
+		if err != nil || tenant == nil {
+			failed = append(failed, subscriptions[i].TenantID)
+			continue
+		}
+
+		if mErr := s.advance(ctx, tenant, &subscriptions[i], now, "Renew"); mErr != nil {
+			failed = append(failed, subscriptions[i].TenantID)
+		}
+	}
+
+	if len(failed) > 0 {
+		return entity.Error("subscriptions not renewed: "+strings.Join(failed, ", "), "subscription", "Renew", entity.ApplicationLayerService, entity.ResponseCodeInternalServer)
+	}
+	return nil
+}
+
+// changePlan changes the plan of the tenant, paid reports that the plan is paid through the provider
+// Without it a paid plan cannot be started or upgraded to, a downgrade is always allowed
+func (s *SubscriptionSvc) changePlan(ctx context.Context, tenant *entity.TenantResponse, data *entity.SubscriptionRequest, paid bool, method string) (*entity.Subscription, *entity.ModuleError) {
+
+	if data == nil || data.PlanID == "" {
+		return nil, entity.Error("plan id is required", "subscription", method, entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	}
+
+	now := time.Now().UTC()
+	subscription, mErr := s.load(ctx, tenant, now, method)
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	next, err := s.plan.GetById(&data.PlanID)
+	if err != nil || next == nil || next.ID == "" {
+		return nil, entity.Error("plan not found", "subscription", method, entity.ApplicationLayerService, entity.ResponseCodeNotFound)
+	}
+
+	current, mErr := s.getPlan(subscription.PlanID, method)
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	checkout := !paid && next.Price.IsPositive()
+	switch {
+	case subscription.Status == entity.SubscriptionCanceled:
+		if checkout {
+			return nil, entity.Error("a paid plan is subscribed through the checkout", "subscription", method, entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+		}
+		subscription.Restart(next.ID, now)
+	case next.ID == subscription.PlanID:
+		// choosing the plan again drops a scheduled downgrade
+		subscription.Downgrade("", now)
+	default:
+		upgrade, err := entity.IsUpgrade(current, next)
+		if err != nil {
+			return nil, entity.Error(err.Error(), "subscription", method, entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+		}
+
+		if !upgrade {
+			subscription.Downgrade(next.ID, now)
+			return s.save(ctx, subscription)
+		}
+
+		if checkout {
+			return nil, entity.Error("a paid plan is subscribed through the checkout", "subscription", method, entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+		}
+		subscription.Upgrade(next.ID, now)
+	}
+
+	if mErr := s.apply(ctx, tenant, next, method); mErr != nil {
+		return nil, mErr
+	}
+
+	return s.save(ctx, subscription)
+}
+
+func (s *SubscriptionSvc) cancel(ctx context.Context, tenant *entity.TenantResponse, method string) (*entity.Subscription, *entity.ModuleError) {
+
+	now := time.Now().UTC()
+	subscription, mErr := s.load(ctx, tenant, now, method)
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	if err := subscription.Cancel(now); err != nil {
+		return nil, entity.Error(err.Error(), "subscription", method, entity.ApplicationLayerService, entity.ResponseCodeConflict)
+	}
+
+	return s.save(ctx, subscription)
+}
+
+// load returns the subscription of the tenant with the changes due at now applied
+// A tenant without a subscription is subscribed to its plan
+func (s *SubscriptionSvc) load(ctx context.Context, tenant *entity.TenantResponse, now time.Time, method string) (*entity.Subscription, *entity.ModuleError) {
+
+	subscription, mErr := s.repo.GetSubscription(ctx, tenant.ID)
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	if subscription == nil {
+		subscription = entity.NewSubscription(tenant.ID, &tenant.Plan, now)
+		if mErr := s.repo.SaveSubscription(ctx, subscription); mErr != nil {
+			return nil, mErr
+		}
+	}
+
+	if mErr := s.advance(ctx, tenant, subscription, now, method); mErr != nil {
+		return nil, mErr
+	}
+	return subscription, nil
+}
+
+// advance applies the changes due and, when the plan changed, the plan to the tenant
+func (s *SubscriptionSvc) advance(ctx context.Context, tenant *entity.TenantResponse, subscription *entity.Subscription, now time.Time, method string) *entity.ModuleError {
+
+	planID := subscription.PlanID
+	if !subscription.Advance(now) {
+		return nil
+	}
+
+	if subscription.PlanID != planID {
+		plan, mErr := s.getPlan(subscription.PlanID, method)
+		if mErr != nil {
+			return mErr
+		}
+
+		if mErr := s.apply(ctx, tenant, plan, method); mErr != nil {
+			return mErr
+		}
+	}
+
+	return s.repo.SaveSubscription(ctx, subscription)
+}
+
+// apply sets the plan of the tenant and makes read-only the wallets over the wallets of the plan
+// The oldest wallets are kept, a wallet back within the plan can be changed again
+func (s *SubscriptionSvc) apply(ctx context.Context, tenant *entity.TenantResponse, plan *entity.PlanResponse, method string) *entity.ModuleError {
+
+	if err := s.tenant.SetPlan(&tenant.ID, plan); err != nil {
+		return entity.Error(err.Error(), "subscription", method, entity.ApplicationLayerService, entity.ResponseCodeInternalServer)
+	}
+	tenant.Plan = *plan
+
+	wallets, mErr := s.wallets.GetByTenant(ctx, &tenant.ID)
+	if mErr != nil {
+		return mErr
+	}
+
+	sort.SliceStable(wallets, func(i, j int) bool {
+		return wallets[i].CreatedAt.Before(wallets[j].CreatedAt)
+	})
+
+	entitlement := plan.Entitlement(entity.FeatureWalletsMax)
+	for i := range wallets {
+		readOnly := !entitlement.Allows(i)
+		if wallets[i].ReadOnly == readOnly {
+			continue
+		}
+
+		wallets[i].ReadOnly = readOnly
+		wallets[i].SetUpdate()
+		if _, mErr := s.wallets.Update(ctx, &wallets[i].OwnerID, &wallets[i]); mErr != nil {
+			return mErr
+		}
+	}
+
+	return nil
+}
+
+func (s *SubscriptionSvc) save(ctx context.Context, subscription *entity.Subscription) (*entity.Subscription, *entity.ModuleError) {
+	if mErr := s.repo.SaveSubscription(ctx, subscription); mErr != nil {
+		return nil, mErr
+	}
+	return subscription, nil
+}
+
+// getPlan returns the plan of the id, an empty id is the empty plan of the default entitlements
+func (s *SubscriptionSvc) getPlan(id string, method string) (*entity.PlanResponse, *entity.ModuleError) {
+	if id == "" {
+		return &entity.PlanResponse{}, nil
+	}
+
+	plan, err := s.plan.GetById(&id)
+	if err != nil || plan == nil {
+		return nil, entity.Error("plan not found", "subscription", method, entity.ApplicationLayerService, entity.ResponseCodeNotFound)
+	}
+	return plan, nil
+}
+
+// getTenant returns the tenant of the user, owner requires the user to be the owner of the tenant
+func (s *SubscriptionSvc) getTenant(ctx context.Context, userId *string, owner bool, method string) (*entity.TenantResponse, *entity.ModuleError) {
+
+	if userId == nil || *userId == "" {
+		return nil, entity.Error("user id cannot be empty", "subscription", method, entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	}
+
+	user, err := accountUserByID(ctx, s.user, userId)
+	if err != nil || user == nil {
+		return nil, entity.Error("user not found", "subscription", method, entity.ApplicationLayerService, entity.ResponseCodeUnauthorized)
+	}
+
+	tenant, err := s.tenant.GetById(&user.TenantID)
+	if err != nil || tenant == nil {
+		return nil, entity.Error("tenant not found", "subscription", method, entity.ApplicationLayerService, entity.ResponseCodeNotFound)
+	}
+
+	if owner && tenant.OwnerID != user.ID {
+		return nil, entity.Error("only the owner of the tenant changes the subscription", "subscription", method, entity.ApplicationLayerService, entity.ResponseCodeForbidden)
+	}
+
+	return tenant, nil
+}
+
+func (s *SubscriptionSvc) getTenantById(tenantId *string, method string) (*entity.TenantResponse, *entity.ModuleError) {
+
+	if tenantId == nil || *tenantId == "" {
+		return nil, entity.Error("tenant id cannot be empty", "subscription", method, entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	}
+
+	tenant, err := s.tenant.GetById(tenantId)
+	if err != nil || tenant == nil {
+		return nil, entity.Error("tenant not found", "subscription", method, entity.ApplicationLayerService, entity.ResponseCodeNotFound)
+	}
+	return tenant, nil
+}
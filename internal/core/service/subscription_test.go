@@ -0,0 +1,188 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/internal/core/repository"
+	"github.com/Tomelin/financial-management-backend/internal/core/service"
+	"github.com/Tomelin/financial-management-backend/pkg/db"
+	"github.com/Tomelin/financial-management-backend/tests/coremocks"
+)
+
+type SubscriptionServiceTestSuite struct {
+	suite.Suite
+	svc     entity.ISubscription
+	tenants service.ITenantService
+	plans   entity.IPlan
+	users   entity.IUser
+	wallets repository.IWalletRepo
+	owner   *entity.AccountUser
+	member  *entity.AccountUser
+	bronze  *entity.PlanResponse
+	silver  *entity.PlanResponse
+	gold    *entity.PlanResponse
+	ctx     context.Context
+}
+
+func (s *SubscriptionServiceTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	database := db.NewMemoryStore()
+
+	repoPlan, err := repository.NewPlanRepository(database)
+	s.Require().NoError(err)
+	plans, err := service.NewPlanService(repoPlan)
+	s.Require().NoError(err)
+	s.plans = plans
+
+	newPlan := func(name string, price int64, wallets int) *entity.PlanResponse {
+		plan, err := plans.Create(&entity.PlanResponse{
+			ID:       uuid.New().String(),
+			Name:     name,
+			Price:    entity.NewMoney(price, "BRL"),
+			Features: []entity.PlanFeatures{{Name: entity.FeatureWalletsMax, Count: wallets}},
+		})
+		s.Require().NoError(err)
+		return plan
+	}
+	s.bronze = newPlan("bronze", 0, 1)
+	s.silver = newPlan("silver", 1990, 3)
+	s.gold = newPlan("gold", 4990, 10)
+
+	repoTenant, err := repository.NewTenantRepository(database)
+	s.Require().NoError(err)
+	s.tenants, err = service.NewTenantService(repoTenant, plans)
+	s.Require().NoError(err)
+
+	tenantID := uuid.New().String()
+	s.owner = &entity.AccountUser{ID: uuid.New().String(), TenantID: tenantID, User: entity.User{Email: "owner@domain.com"}}
+	s.member = &entity.AccountUser{ID: uuid.New().String(), TenantID: tenantID, User: entity.User{Email: "member@domain.com"}}
+	_, err = repoTenant.Create(&entity.TenantResponse{ID: tenantID, Name: s.owner.Email, OwnerID: s.owner.ID, Plan: *s.silver})
+	s.Require().NoError(err)
+
+	users := map[string]*entity.AccountUser{s.owner.ID: s.owner, s.member.ID: s.member}
+	mockUser := new(coremocks.IUser)
+	mockUser.On("GetById", mock.Anything, mock.Anything).Return(func(ctx context.Context, id *string) (*entity.AccountUser, error) {
+		return users[*id], nil
+	})
+	s.users = mockUser
+
+	s.wallets, err = repository.NewWalletRepo(database)
+	s.Require().NoError(err)
+	// the member owns a wallet of the tenant too, the plan limits the wallets of the tenant
+	owners := map[string]*entity.AccountUser{"Home": s.owner, "Travel": s.owner, "Work": s.member}
+	for i, name := range []string{"Home", "Travel", "Work"} {
+		wallet, mErr := entity.NewWallet(&entity.WalletResponse{Name: name, OwnerID: owners[name].ID, TenantID: tenantID, Currency: "BRL"})
+		s.Require().Nil(mErr)
+		wallet.CreatedAt = time.Now().UTC().Add(time.Duration(i) * time.Minute)
+		_, mErr = s.wallets.Create(s.ctx, &owners[name].ID, wallet)
+		s.Require().Nil(mErr)
+	}
+
+	repo, err := repository.NewSubscriptionRepo(database)
+	s.Require().NoError(err)
+	var mErr *entity.ModuleError
+	s.svc, mErr = service.NewSubscriptionSvc(repo, s.tenants, plans, s.wallets, mockUser)
+	s.Require().Nil(mErr)
+}
+
+func (s *SubscriptionServiceTestSuite) plan() *entity.PlanResponse {
+	plan, err := s.tenants.GetPlan(&s.owner.TenantID)
+	s.Require().NoError(err)
+	return plan
+}
+
+// readOnly returns the read-only flags of the wallets, the oldest first
+func (s *SubscriptionServiceTestSuite) readOnly() []bool {
+	wallets, mErr := s.wallets.GetByTenant(s.ctx, &s.owner.TenantID)
+	s.Require().Nil(mErr)
+
+	flags := map[string]bool{}
+	for _, wallet := range wallets {
+		flags[wallet.Name] = wallet.ReadOnly
+	}
+	return []bool{flags["Home"], flags["Travel"], flags["Work"]}
+}
+
+func (s *SubscriptionServiceTestSuite) TestUpgradeAndDowngrade() {
+	subscription, mErr := s.svc.Get(s.ctx, &s.member.ID)
+	s.Require().Nil(mErr)
+	s.Equal(entity.SubscriptionTrialing, subscription.Status, "the tenant is subscribed to its plan")
+	s.Equal(s.silver.ID, subscription.PlanID)
+
+	_, mErr = s.svc.ChangePlan(s.ctx, &s.member.ID, &entity.SubscriptionRequest{PlanID: s.gold.ID})
+	s.Require().NotNil(mErr)
+	s.Equal(entity.ResponseCodeForbidden, mErr.Code, "only the owner changes the plan")
+
+	_, mErr = s.svc.ChangePlan(s.ctx, &s.owner.ID, &entity.SubscriptionRequest{PlanID: s.gold.ID})
+	s.Require().NotNil(mErr)
+	s.Equal(entity.ResponseCodeBadRequest, mErr.Code, "a paid upgrade is made through the checkout")
+
+	subscription, mErr = s.svc.ChangeTenantPlan(s.ctx, &s.owner.TenantID, &entity.SubscriptionRequest{PlanID: s.gold.ID})
+	s.Require().Nil(mErr)
+	s.Equal(s.gold.ID, subscription.PlanID)
+	s.Equal(s.gold.ID, s.plan().ID, "an upgrade applies at once")
+
+	subscription, mErr = s.svc.ChangePlan(s.ctx, &s.owner.ID, &entity.SubscriptionRequest{PlanID: s.bronze.ID})
+	s.Require().Nil(mErr)
+	s.Equal(s.bronze.ID, subscription.ScheduledPlanID)
+	s.Equal(s.gold.ID, s.plan().ID, "a downgrade waits for the end of the period")
+	s.Equal([]bool{false, false, false}, s.readOnly())
+
+	s.Require().Nil(s.svc.Renew(s.ctx, subscription.PeriodEnd))
+	s.Equal(s.bronze.ID, s.plan().ID)
+	s.Equal([]bool{false, true, true}, s.readOnly(), "the wallets over the plan are read-only")
+
+	subscription, mErr = s.svc.ChangeTenantPlan(s.ctx, &s.owner.TenantID, &entity.SubscriptionRequest{PlanID: s.silver.ID})
+	s.Require().Nil(mErr)
+	s.Equal(entity.SubscriptionActive, subscription.Status)
+	s.Equal([]bool{false, false, false}, s.readOnly(), "the wallets back within the plan are changed again")
+}
+
+func (s *SubscriptionServiceTestSuite) TestCancelAndPastDue() {
+	subscription, mErr := s.svc.Cancel(s.ctx, &s.owner.ID)
+	s.Require().Nil(mErr)
+	s.True(subscription.CancelAtPeriodEnd)
+
+	subscription, mErr = s.svc.Resume(s.ctx, &s.owner.ID)
+	s.Require().Nil(mErr)
+	s.False(subscription.CancelAtPeriodEnd)
+
+	subscription, mErr = s.svc.MarkPastDue(s.ctx, &s.owner.TenantID)
+	s.Require().Nil(mErr)
+	s.Equal(entity.SubscriptionPastDue, subscription.Status)
+
+	s.Require().Nil(s.svc.Renew(s.ctx, time.Now().UTC()))
+	s.Equal(s.silver.ID, s.plan().ID, "the plan is kept in the grace period")
+
+	s.Require().Nil(s.svc.Renew(s.ctx, subscription.DueAt))
+	subscription, mErr = s.svc.Get(s.ctx, &s.owner.ID)
+	s.Require().Nil(mErr)
+	s.Equal(entity.SubscriptionCanceled, subscription.Status)
+	s.Empty(s.plan().ID, "a canceled subscription has the default entitlements")
+	s.Equal([]bool{false, true, true}, s.readOnly())
+
+	_, mErr = s.svc.Cancel(s.ctx, &s.owner.ID)
+	s.Require().NotNil(mErr)
+	s.Equal(entity.ResponseCodeConflict, mErr.Code)
+
+	subscription, mErr = s.svc.ChangePlan(s.ctx, &s.owner.ID, &entity.SubscriptionRequest{PlanID: s.bronze.ID})
+	s.Require().Nil(mErr)
+	s.Equal(entity.SubscriptionActive, subscription.Status, "a canceled tenant subscribes again")
+	s.Equal(s.bronze.ID, s.plan().ID)
+
+	subscription, mErr = s.svc.ChangeTenantPlan(s.ctx, &s.owner.TenantID, &entity.SubscriptionRequest{PlanID: s.gold.ID})
+	s.Require().Nil(mErr)
+	s.Equal(entity.SubscriptionActive, subscription.Status)
+	s.Equal(s.gold.ID, s.plan().ID)
+}
+
+func TestSubscriptionServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(SubscriptionServiceTestSuite))
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"log"
 	"strings"
+	"time"
 
 	"github.com/Tomelin/financial-management-backend/internal/core/entity"
 	"github.com/Tomelin/financial-management-backend/internal/core/repository"
@@ -15,6 +16,8 @@ type ITenantService interface {
 	entity.ITenant
 	GetPlan(id *string) (*entity.PlanResponse, error)
 	SetPlan(id *string, plan *entity.PlanResponse) error
+	// SetSuspended suspends the tenant with the reason or unsuspends it
+	SetSuspended(id *string, suspended bool, reason string) (*entity.TenantResponse, error)
 }
 
 type TenantSvc struct {
@@ -86,9 +89,9 @@ func (u *TenantSvc) Create(tenant *entity.TenantResponse) (*entity.TenantRespons
 	return nil, errors.New("tenant already exists")
 }
 
-func (u *TenantSvc) Get() ([]entity.TenantResponse, error) {
+func (u *TenantSvc) Get(page *entity.PageRequest) (*entity.Page[entity.TenantResponse], error) {
 
-	return u.repo.Get()
+	return u.repo.Get(page)
 }
 
 func (u *TenantSvc) GetById(id *string) (*entity.TenantResponse, error) {
@@ -124,6 +127,15 @@ func (u *TenantSvc) Update(data *entity.TenantResponse) (*entity.TenantResponse,
 		return nil, err
 	}
 
+	// the suspension is changed only by SetSuspended
+	current, err := u.GetById(&data.ID)
+	if err != nil {
+		return nil, err
+	}
+	data.Suspended = current.Suspended
+	data.SuspendedReason = current.SuspendedReason
+	data.SuspendedAt = current.SuspendedAt
+
 	return u.repo.Update(data)
 }
 
@@ -149,16 +161,12 @@ func (u *TenantSvc) Delete(id *string) error {
 	return errors.New("not found")
 }
 
-func (u *TenantSvc) GetByFilterMany(ctx context.Context, filter []entity.QueryDB) ([]entity.TenantResponse, error) {
+func (u *TenantSvc) GetByFilterMany(ctx context.Context, filter []entity.QueryDBClause) ([]entity.TenantResponse, error) {
 
-	if len(filter) == 0 {
+	if isEmptyFilter(filter) {
 		return nil, errors.New("filter cannot be empty")
 	}
 
-	if filter[0].Key == "" || filter[0].Value == "" {
-		return nil, errors.New("filter key and value cannot be empty")
-	}
-
 	return u.repo.GetByFilterMany(ctx, filter)
 }
 
@@ -179,31 +187,49 @@ func (u *TenantSvc) SetPlan(id *string, plan *entity.PlanResponse) error {
 		return errors.New("id is required")
 	}
 
-	if plan.IsEmpty(plan) {
+	if plan == nil {
 		return errors.New("plan is required")
 	}
 
-	if err := plan.Validate(); err != nil {
-		return err
+	// an empty plan removes the plan, the tenant has the default entitlements
+	if !plan.IsEmpty(plan) {
+		if err := plan.Validate(); err != nil {
+			return err
+		}
 	}
 
-	if err := plan.Validate(); err != nil {
+	if _, err := u.GetById(id); err != nil {
 		return err
 	}
 
-	data, err := u.GetById(id)
-	if err != nil {
-		return err
+	return u.repo.SetPlan(id, plan)
+}
+
+func (u *TenantSvc) SetSuspended(id *string, suspended bool, reason string) (*entity.TenantResponse, error) {
+
+	if id == nil || *id == "" {
+		return nil, errors.New("id is required")
 	}
 
-	data.Plan = *plan
+	if suspended && strings.TrimSpace(reason) == "" {
+		return nil, errors.New("reason is required")
+	}
 
-	_, err = u.Update(data)
+	tenant, err := u.GetById(id)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	tenant.Suspended = suspended
+	tenant.SuspendedReason = ""
+	tenant.SuspendedAt = time.Time{}
+	if suspended {
+		tenant.SuspendedReason = strings.TrimSpace(reason)
+		tenant.SuspendedAt = time.Now().UTC()
 	}
+	tenant.UpdatedAt = time.Now()
 
-	return nil
+	return u.repo.Update(tenant)
 }
 
 func (u *TenantSvc) GetPlan(id *string) (*entity.PlanResponse, error) {
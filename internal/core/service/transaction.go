@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/pkg/utils"
+)
+
+type TransactionSvc struct {
+	repo     entity.ITransaction
+	wallet   entity.IWallet
+	category entity.ITransactionCategory
+	user     entity.IUser
+}
+
+// NewTransactionSvc creates a new TransactionSvc
+// It requires a repository, a wallet, a transaction category and a user
+// It returns a TransactionSvc and an error
+func NewTransactionSvc(repo entity.ITransaction, wallet entity.IWallet, category entity.ITransactionCategory, user entity.IUser) (entity.ITransaction, *entity.ModuleError) {
+	if repo == nil {
+		return nil, entity.Error("repo is required", "transaction", "inicialization", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	}
+
+	if wallet == nil {
+		return nil, entity.Error("wallet is required", "transaction", "inicialization", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	}
+
+	if category == nil {
+		return nil, entity.Error("category is required", "transaction", "inicialization", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	}
+
+	if user == nil {
+		return nil, entity.Error("user is required", "transaction", "inicialization", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	}
+
+	return &TransactionSvc{
+		repo:     repo,
+		wallet:   wallet,
+		category: category,
+		user:     user,
+	}, nil
+}
+
+func (t *TransactionSvc) Create(ctx context.Context, userId *string, walletId *string, transaction *entity.WalletTransaction) (*entity.WalletTransaction, *entity.ModuleError) {
+
+	if transaction == nil || transaction.IsEmpty(transaction) {
+		return nil, entity.Error("transaction required", "transaction", "Create", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	}
+
+	wallet, user, mErr := t.getWallet(ctx, userId, walletId, entity.PermissionEdit)
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	transaction.WalletID = wallet.ID
+	transaction.TenantID = wallet.TenantID
+	transaction.Currency = wallet.Currency
+	transaction.CreatedBy = user.ID
+
+	if mErr := transaction.Validate(); mErr != nil {
+		return nil, mErr
+	}
+
+	if mErr := t.validateCategory(ctx, user, wallet, &transaction.CategoryID); mErr != nil {
+		return nil, mErr
+	}
+
+	// the repository applies the signed amount to the balance of the wallet with the write
+	result, mErr := t.repo.Create(ctx, &user.ID, &wallet.ID, transaction)
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	return result, nil
+}
+
+func (t *TransactionSvc) Get(ctx context.Context, userId *string, walletId *string, page *entity.PageRequest) (*entity.Page[entity.WalletTransaction], *entity.ModuleError) {
+
+	wallet, user, mErr := t.getWallet(ctx, userId, walletId, entity.PermissionView)
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	return t.repo.Get(ctx, &user.ID, &wallet.ID, page)
+}
+
+func (t *TransactionSvc) GetByID(ctx context.Context, userId *string, walletId *string, id *string) (*entity.WalletTransaction, *entity.ModuleError) {
+
+	if id == nil || *id == "" {
+		return nil, entity.Error("id cannot be empty", "transaction", "GetByID", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	}
+
+	if err := utils.ValidateUUID(id); err != nil {
+		return nil, entity.Error(err.Error(), "transaction", "GetByID", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	}
+
+	wallet, user, mErr := t.getWallet(ctx, userId, walletId, entity.PermissionView)
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	result, mErr := t.repo.GetByID(ctx, &user.ID, &wallet.ID, id)
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	if result == nil || result.ID == "" {
+		return nil, entity.Error("transaction not found", "transaction", "GetByID", entity.ApplicationLayerService, entity.ResponseCodeNotFound)
+	}
+
+	return result, nil
+}
+
+func (t *TransactionSvc) Update(ctx context.Context, userId *string, walletId *string, data *entity.WalletTransaction) (*entity.WalletTransaction, *entity.ModuleError) {
+
+	if data == nil || data.IsEmpty(data) {
+		return nil, entity.Error("transaction cannot be empty", "transaction", "Update", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	}
+
+	current, mErr := t.GetByID(ctx, userId, walletId, &data.ID)
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	wallet, user, mErr := t.getWallet(ctx, userId, walletId, entity.PermissionEdit)
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	data.WalletID = current.WalletID
+	data.TenantID = current.TenantID
+	data.Currency = current.Currency
+	data.CreatedBy = current.CreatedBy
+	data.CreatedAt = current.CreatedAt
+	if data.Date.IsZero() {
+		data.Date = current.Date
+	}
+
+	if mErr := data.Validate(); mErr != nil {
+		return nil, mErr
+	}
+
+	if mErr := t.validateCategory(ctx, user, wallet, &data.CategoryID); mErr != nil {
+		return nil, mErr
+	}
+
+	// the repository applies the difference to the balance of the wallet, from the transaction stored at the write
+	data.SetUpdate()
+	result, mErr := t.repo.Update(ctx, &user.ID, &wallet.ID, data)
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	return result, nil
+}
+
+func (t *TransactionSvc) Delete(ctx context.Context, userId *string, walletId *string, id *string) *entity.ModuleError {
+
+	if _, _, mErr := t.getWallet(ctx, userId, walletId, entity.PermissionEdit); mErr != nil {
+		return mErr
+	}
+
+	current, mErr := t.GetByID(ctx, userId, walletId, id)
+	if mErr != nil {
+		return mErr
+	}
+
+	// the repository reverts the signed amount from the balance of the wallet with the delete
+	return t.repo.Delete(ctx, userId, &current.WalletID, &current.ID)
+}
+
+func (t *TransactionSvc) GetByFilterMany(ctx context.Context, userId *string, walletId *string, filter []entity.QueryDB) ([]entity.WalletTransaction, *entity.ModuleError) {
+
+	if len(filter) == 0 {
+		return nil, entity.Error("filter cannot be empty", "transaction", "GetByFilterMany", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	}
+
+	if filter[0].Key == "" || filter[0].Value == "" {
+		return nil, entity.Error("key and value cannot be empty", "transaction", "GetByFilterMany", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	}
+
+	wallet, user, mErr := t.getWallet(ctx, userId, walletId, entity.PermissionView)
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	return t.repo.GetByFilterMany(ctx, &user.ID, &wallet.ID, filter)
+}
+
+// getWallet returns the wallet and the user when the level of the user on the wallet grants the level.
+// The wallet is visible to its owner, to the users of the owner tenant and to the tenants that the wallet was shared with.
+func (t *TransactionSvc) getWallet(ctx context.Context, userId *string, walletId *string, level entity.PermissionLevel) (*entity.WalletResponse, *entity.AccountUser, *entity.ModuleError) {
+
+	if userId == nil || *userId == "" {
+		return nil, nil, entity.Error("user id cannot be empty", "transaction", "Generic", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	}
+
+	if walletId == nil || *walletId == "" {
+		return nil, nil, entity.Error("wallet id cannot be empty", "transaction", "Generic", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	}
+
+	if err := utils.ValidateUUID(walletId); err != nil {
+		return nil, nil, entity.Error(err.Error(), "transaction", "Generic", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	}
+
+	user, err := accountUserByID(ctx, t.user, userId)
+	if err != nil {
+		return nil, nil, entity.Error(err.Error(), "transaction", "Generic", entity.ApplicationLayerService, entity.ResponseCodeUnauthorized)
+	}
+
+	if user == nil || user.ID == "" {
+		return nil, nil, entity.Error("user not found", "transaction", "Generic", entity.ApplicationLayerService, entity.ResponseCodeUnauthorized)
+	}
+
+	wallet, mErr := t.wallet.GetByID(ctx, walletId)
+	if mErr != nil {
+		return nil, nil, mErr
+	}
+
+	if wallet == nil || wallet.ID == "" {
+		return nil, nil, entity.Error("wallet not found", "transaction", "Generic", entity.ApplicationLayerService, entity.ResponseCodeNotFound)
+	}
+
+	if wallet.PermissionOf(user.ID, user.TenantID) == "" {
+		return nil, nil, entity.Error("wallet not found", "transaction", "Generic", entity.ApplicationLayerService, entity.ResponseCodeNotFound)
+	}
+
+	if !walletAllows(ctx, wallet, user, entity.ModuleTransaction, level) {
+		return nil, nil, entity.Error(string(level)+" permission on the wallet is required", "transaction", "Generic", entity.ApplicationLayerService, entity.ResponseCodeForbidden)
+	}
+
+	return wallet, user, nil
+}
+
+// validateCategory checks if the category is a default category or belongs to the wallet
+func (t *TransactionSvc) validateCategory(ctx context.Context, user *entity.AccountUser, wallet *entity.WalletResponse, categoryId *string) *entity.ModuleError {
+
+	category, mErr := t.category.GetById(ctx, &user.Email, categoryId)
+	if mErr != nil {
+		return mErr
+	}
+
+	isDefault, _ := strconv.ParseBool(category.Default)
+	if !isDefault && category.WalletID != wallet.ID {
+		return entity.Error("category does not belong to the wallet", "transaction", "validateCategory", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	}
+
+	return nil
+}
@@ -10,11 +10,12 @@ import (
 )
 
 type TransactionCategorySvc struct {
-	repo   entity.ITransactionCategoryRepository
-	tenant ITenantService
-	wallet entity.IWallet
-	Trace  *observability.Tracer
-	user   entity.IUser
+	repo        entity.ITransactionCategoryRepository
+	tenant      ITenantService
+	wallet      entity.IWallet
+	Trace       *observability.Tracer
+	user        entity.IUser
+	entitlement entity.IEntitlement
 }
 
 func NewTransactionCategorySvc(
@@ -23,6 +24,7 @@ func NewTransactionCategorySvc(
 	tenant *ITenantService,
 	wallet *entity.IWallet,
 	user *entity.IUser,
+	entitlement *entity.IEntitlement,
 ) (entity.ITransactionCategory, *entity.ModuleError) {
 
 	if repo == nil {
@@ -37,12 +39,17 @@ func NewTransactionCategorySvc(
 		return nil, entity.Error("wallet is required", "transactionCategory", "inicialization", entity.ApplicationLayerService, entity.ResponseCodeInternalServer)
 	}
 
+	if entitlement == nil {
+		return nil, entity.Error("entitlement is required", "transactionCategory", "inicialization", entity.ApplicationLayerService, entity.ResponseCodeInternalServer)
+	}
+
 	return &TransactionCategorySvc{
-		repo:   *repo,
-		tenant: *tenant,
-		wallet: *wallet,
-		Trace:  trace,
-		user:   *user,
+		repo:        *repo,
+		tenant:      *tenant,
+		wallet:      *wallet,
+		Trace:       trace,
+		user:        *user,
+		entitlement: *entitlement,
 	}, nil
 }
 
@@ -69,6 +76,20 @@ func (c *TransactionCategorySvc) Create(ctx context.Context, email *string, cate
 		return nil, entity.Error("wallet id and tenant id are required", "transactionCategory", "Create", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
 	}
 
+	user, err := accountUser(ctx, c.user, email)
+	if err != nil {
+		return nil, entity.Error(err.Error(), "transactionCategory", "Create", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	}
+
+	if user == nil || user.Email == "" {
+		return nil, entity.Error("user not found", "transactionCategory", "Create", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	}
+
+	// the default categories are of every tenant, they need edit on the module
+	if principal, ok := entity.PrincipalFromContext(ctx); walletDefault && ok && !principal.Can(entity.ModuleCategory, entity.PermissionEdit) {
+		return nil, entity.Error(entity.ErrForbidden.Error(), "transactionCategory", "Create", entity.ApplicationLayerService, entity.ResponseCodeForbidden)
+	}
+
 	if !walletDefault && category.WalletID != "" && category.TenantID != "" {
 		// Validate if the tenant ID is valid
 		tenantId := category.TenantID
@@ -93,25 +114,37 @@ func (c *TransactionCategorySvc) Create(ctx context.Context, email *string, cate
 		if mErr != nil || wallet == nil || wallet.Name == "" {
 			return nil, mErr
 		}
-	}
 
-	user, err := c.user.GetByEmail(ctx, email)
-	if err != nil {
-		return nil, entity.Error(err.Error(), "transactionCategory", "Create", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
-	}
+		// the categories of a wallet need edit on the wallet
+		if !walletAllows(ctx, wallet, user, entity.ModuleCategory, entity.PermissionEdit) {
+			return nil, entity.Error("edit permission on the wallet is required", "transactionCategory", "Create", entity.ApplicationLayerService, entity.ResponseCodeForbidden)
+		}
 
-	if user == nil || user.Email == "" {
-		return nil, entity.Error("user not found", "transactionCategory", "Create", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+		// Validate if the plan of the tenant allows one more custom category
+		custom, mErr := c.repo.GetByFilterMany(ctx, entity.AndClause([]entity.QueryDB{
+			{
+				Key:       "tenant_id",
+				Condition: string(entity.QueryFirebaseEqual),
+				Value:     tenantId,
+			},
+		}))
+		if mErr != nil {
+			return nil, mErr
+		}
+
+		if mErr := c.entitlement.Check(ctx, &tenantId, entity.FeatureCategoriesCustomMax, len(custom)); mErr != nil {
+			return nil, mErr
+		}
 	}
 
 	// Validate if the category already exists
-	data, _ := c.GetByFilterMany(ctx, email, []entity.QueryDB{
+	data, _ := c.GetByFilterMany(ctx, email, entity.AndClause([]entity.QueryDB{
 		{
 			Key:       "name",
 			Condition: string(entity.QueryFirebaseEqual),
 			Value:     category.Name,
 		},
-	})
+	}))
 
 	if len(data) > 0 {
 		return nil, entity.Error("category already exists", "transactionCategory", "Create", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
@@ -120,22 +153,28 @@ func (c *TransactionCategorySvc) Create(ctx context.Context, email *string, cate
 	return c.repo.Create(ctx, category)
 }
 
-func (c *TransactionCategorySvc) Get(ctx context.Context, email *string, walletID *string) ([]entity.TransactionCategory, *entity.ModuleError) {
+func (c *TransactionCategorySvc) Get(ctx context.Context, email *string, walletID *string, page *entity.PageRequest) (*entity.Page[entity.TransactionCategory], *entity.ModuleError) {
 	ctx, span := c.Trace.Trace.Start(ctx, "TransactionCategorySvc.Get")
 	span.SetName("TransactionCategorySvc")
 	defer span.End()
 
-	data, mErr := c.GetByFilterMany(ctx, email, []entity.QueryDB{
-		{
-			Key:       "default",
-			Condition: string(entity.QueryFirebaseEqual),
-			Value:     "true",
-		},
-	})
+	visible, mErr := c.visibleFilter(ctx, email, "Get")
 	if mErr != nil {
 		return nil, mErr
 	}
 
+	// the default categories and, when the wallet is informed, the categories of the wallet
+	categories := entity.QueryDBClause{
+		Clause: entity.QueryClauseOr,
+		Queries: []entity.QueryDB{
+			{
+				Key:       "default",
+				Condition: string(entity.QueryFirebaseEqual),
+				Value:     "true",
+			},
+		},
+	}
+
 	if walletID != nil && *walletID != "" {
 		if err := utils.ValidateUUID(walletID); err != nil {
 			return nil, entity.Error(err.Error(), "transactionCategory", "Get", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
@@ -147,27 +186,24 @@ func (c *TransactionCategorySvc) Get(ctx context.Context, email *string, walletI
 			return nil, mErr
 		}
 
-		dataTemp, mErr := c.GetByFilterMany(ctx, email, []entity.QueryDB{
-			{
-				Key:       "default",
-				Condition: string(entity.QueryFirebaseEqual),
-				Value:     "false",
-			},
-			{
-				Key:       "wallet_id",
-				Condition: string(entity.QueryFirebaseEqual),
-				Value:     *walletID,
+		categories.Clauses = append(categories.Clauses, entity.QueryDBClause{
+			Clause: entity.QueryClauseAnd,
+			Queries: []entity.QueryDB{
+				{
+					Key:       "default",
+					Condition: string(entity.QueryFirebaseEqual),
+					Value:     "false",
+				},
+				{
+					Key:       "wallet_id",
+					Condition: string(entity.QueryFirebaseEqual),
+					Value:     *walletID,
+				},
 			},
 		})
-		if mErr != nil {
-			return nil, mErr
-		}
-
-		data = append(data, dataTemp...)
 	}
 
-	return data, nil
-
+	return c.repo.Get(ctx, []entity.QueryDBClause{categories, *visible}, page)
 }
 
 func (c *TransactionCategorySvc) GetById(ctx context.Context, email *string, id *string) (*entity.TransactionCategory, *entity.ModuleError) {
@@ -183,7 +219,7 @@ func (c *TransactionCategorySvc) GetById(ctx context.Context, email *string, id
 		return nil, entity.Error(err.Error(), "transactionCategory", "GetById", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
 	}
 
-	result, err := c.user.GetByEmail(ctx, email)
+	result, err := accountUser(ctx, c.user, email)
 	if err != nil {
 		return nil, entity.Error(err.Error(), "transactionCategory", "GetById", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
 	}
@@ -243,7 +279,7 @@ func (c *TransactionCategorySvc) Delete(ctx context.Context, email *string, id *
 		return entity.Error("category default cannot be deleted", "transactionCategory", "Delete", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
 	}
 
-	user, err := c.user.GetByEmail(ctx, email)
+	user, err := accountUser(ctx, c.user, email)
 	if err != nil || user == nil || user.Email == "" {
 		message := "user not found"
 		if err != nil {
@@ -271,29 +307,66 @@ func (c *TransactionCategorySvc) Delete(ctx context.Context, email *string, id *
 	return nil
 }
 
-func (c *TransactionCategorySvc) GetByFilterMany(ctx context.Context, email *string, filter []entity.QueryDB) ([]entity.TransactionCategory, *entity.ModuleError) {
+func (c *TransactionCategorySvc) GetByFilterMany(ctx context.Context, email *string, filter []entity.QueryDBClause) ([]entity.TransactionCategory, *entity.ModuleError) {
 	ctx, span := c.Trace.Trace.Start(ctx, "TransactionCategorySvc.GetByFilterMany")
 	defer span.End()
 
-	if len(filter) == 0 {
+	if isEmptyFilter(filter) {
 		return nil, entity.Error("filter cannot be empty", "transactionCategory", "GetByFilterMany", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
 	}
 
+	visible, mErr := c.visibleFilter(ctx, email, "GetByFilterMany")
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	filters := append(append([]entity.QueryDBClause{}, filter...), *visible)
+
+	data, mErr := c.repo.GetByFilterMany(ctx, filters)
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	return data, nil
+}
+
+func (c *TransactionCategorySvc) GetByFilterOne(ctx context.Context, email *string, filter []entity.QueryDB) (*entity.TransactionCategory, *entity.ModuleError) {
+	ctx, span := c.Trace.Trace.Start(ctx, "TransactionCategorySvc.GetByFilterOne")
+	defer span.End()
+
+	if len(filter) == 0 {
+		return nil, entity.Error("filter cannot be empty", "transactionCategory", "GetByFilterOne", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	}
+
 	if filter[0].Key == "" || filter[0].Value == "" {
-		return nil, entity.Error("key and value cannot be empty", "transactionCategory", "GetByFilterMany", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+		return nil, entity.Error("key and value cannot be empty", "transactionCategory", "GetByFilterOne", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	}
+
+	if email == nil {
+		return nil, entity.Error("user cannot be empty", "transactionCategory", "GetByFilterOne", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	}
+
+	data, mErr := c.repo.GetByFilterOne(ctx, filter)
+	if mErr != nil {
+		return nil, mErr
 	}
 
+	return data, nil
+}
+
+// visibleFilter is the filter of the categories the user sees, the default ones and the ones of the tenant
+func (c *TransactionCategorySvc) visibleFilter(ctx context.Context, email *string, method string) (*entity.QueryDBClause, *entity.ModuleError) {
 	if email == nil {
-		return nil, entity.Error("user cannot be empty", "transactionCategory", "GetByFilterMany", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+		return nil, entity.Error("user cannot be empty", "transactionCategory", method, entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
 	}
 
-	user, err := c.user.GetByEmail(ctx, email)
+	user, err := accountUser(ctx, c.user, email)
 	if err != nil {
-		return nil, entity.Error(err.Error(), "transactionCategory", "GetByFilterMany", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+		return nil, entity.Error(err.Error(), "transactionCategory", method, entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
 	}
 
 	if user == nil || user.Email == "" {
-		return nil, entity.Error("user not found", "transactionCategory", "GetByFilterMany", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+		return nil, entity.Error("user not found", "transactionCategory", method, entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
 	}
 
 	tenant, err := c.tenant.GetByFilterOne(ctx, []entity.QueryDB{{
@@ -303,75 +376,39 @@ func (c *TransactionCategorySvc) GetByFilterMany(ctx context.Context, email *str
 	}})
 
 	if err != nil {
-		return nil, entity.Error(err.Error(), "transactionCategory", "GetByFilterMany", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+		return nil, entity.Error(err.Error(), "transactionCategory", method, entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
 	}
 
 	if tenant == nil || tenant.Name == "" {
-		return nil, entity.Error("tenant not found", "transactionCategory", "GetByFilterMany", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+		return nil, entity.Error("tenant not found", "transactionCategory", method, entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
 	}
 
-	filters2 := []entity.QueryDBClause{
-		{
-			Clause: entity.QueryClauseAnd,
-			Queries: append(filter,
-				[]entity.QueryDB{
+	// the user sees the default categories and the categories of the tenant
+	return &entity.QueryDBClause{
+		Clause: entity.QueryClauseOr,
+		Queries: []entity.QueryDB{
+			{
+				Key:       "default",
+				Condition: string(entity.QueryFirebaseEqual),
+				Value:     "true",
+			},
+		},
+		Clauses: []entity.QueryDBClause{
+			{
+				Clause: entity.QueryClauseAnd,
+				Queries: []entity.QueryDB{
 					{
 						Key:       "default",
 						Condition: string(entity.QueryFirebaseEqual),
-						Value:     "true",
+						Value:     "false",
 					},
 					{
-						Key:       filter[0].Key,
+						Key:       "tenant_id",
 						Condition: string(entity.QueryFirebaseEqual),
-						Value:     filter[0].Value,
+						Value:     tenant.ID,
 					},
-				}...),
-		},
-		{
-			Clause: entity.QueryClauseOr,
-			Queries: append(filter, []entity.QueryDB{
-				{
-					Key:       "default",
-					Condition: string(entity.QueryFirebaseEqual),
-					Value:     "false",
 				},
-				{
-					Key:       "tenant_id",
-					Condition: string(entity.QueryFirebaseEqual),
-					Value:     tenant.ID,
-				},
-			}...),
+			},
 		},
-	}
-
-	data, mErr := c.repo.GetByFilterMany(ctx, filters2)
-	if mErr != nil {
-		return nil, mErr
-	}
-
-	return data, nil
-}
-
-func (c *TransactionCategorySvc) GetByFilterOne(ctx context.Context, email *string, filter []entity.QueryDB) (*entity.TransactionCategory, *entity.ModuleError) {
-	ctx, span := c.Trace.Trace.Start(ctx, "TransactionCategorySvc.GetByFilterOne")
-	defer span.End()
-
-	if len(filter) == 0 {
-		return nil, entity.Error("filter cannot be empty", "transactionCategory", "GetByFilterOne", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
-	}
-
-	if filter[0].Key == "" || filter[0].Value == "" {
-		return nil, entity.Error("key and value cannot be empty", "transactionCategory", "GetByFilterOne", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
-	}
-
-	if email == nil {
-		return nil, entity.Error("user cannot be empty", "transactionCategory", "GetByFilterOne", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
-	}
-
-	data, mErr := c.repo.GetByFilterOne(ctx, filter)
-	if mErr != nil {
-		return nil, mErr
-	}
-
-	return data, nil
+	}, nil
 }
@@ -0,0 +1,165 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/internal/core/service"
+	"github.com/Tomelin/financial-management-backend/tests/coremocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type TransactionServiceTestSuite struct {
+	suite.Suite
+	mockRepo        *coremocks.ITransaction
+	mockWallet      *coremocks.IWallet
+	mockCategory    *coremocks.ITransactionCategory
+	mockUser        *coremocks.IUser
+	mockAccountUser *entity.AccountUser
+	mockWalletEnt   *entity.WalletResponse
+	mockCategoryEnt *entity.TransactionCategory
+	mockTransaction *entity.WalletTransaction
+	svc             entity.ITransaction
+	ctx             context.Context
+}
+
+func (s *TransactionServiceTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.mockRepo = new(coremocks.ITransaction)
+	s.mockWallet = new(coremocks.IWallet)
+	s.mockCategory = new(coremocks.ITransactionCategory)
+	s.mockUser = new(coremocks.IUser)
+
+	userID := uuid.New().String()
+	tenantID := uuid.New().String()
+	s.mockAccountUser = &entity.AccountUser{
+		ID:       userID,
+		TenantID: tenantID,
+		User:     entity.User{Email: "user@domain.com"},
+	}
+
+	s.mockWalletEnt = &entity.WalletResponse{
+		ID:       uuid.New().String(),
+		OwnerID:  userID,
+		TenantID: tenantID,
+		Currency: "BRL",
+	}
+
+	s.mockCategoryEnt = &entity.TransactionCategory{
+		ID:       uuid.New().String(),
+		Name:     "market",
+		Default:  "false",
+		TenantID: tenantID,
+		WalletID: s.mockWalletEnt.ID,
+	}
+
+	s.mockTransaction = &entity.WalletTransaction{
+		ID:         uuid.New().String(),
+		CategoryID: s.mockCategoryEnt.ID,
+		Type:       entity.TransactionTypeExpense,
+		Amount:     entity.NewMoney(2550, "BRL"),
+	}
+
+	s.mockUser.On("GetById", mock.Anything, &s.mockAccountUser.ID).Return(s.mockAccountUser, nil)
+	s.mockWallet.On("GetByID", mock.Anything, &s.mockWalletEnt.ID).Return(s.mockWalletEnt, nil)
+	s.mockCategory.On("GetById", mock.Anything, &s.mockAccountUser.Email, &s.mockCategoryEnt.ID).Return(s.mockCategoryEnt, nil)
+
+	svc, err := service.NewTransactionSvc(s.mockRepo, s.mockWallet, s.mockCategory, s.mockUser)
+	s.Require().Nil(err)
+	s.svc = svc
+}
+
+func (s *TransactionServiceTestSuite) TestNewTransactionSvc_Error() {
+	svc, err := service.NewTransactionSvc(nil, s.mockWallet, s.mockCategory, s.mockUser)
+	s.Nil(svc)
+	s.Equal("repo is required", err.Err)
+
+	svc, err = service.NewTransactionSvc(s.mockRepo, nil, s.mockCategory, s.mockUser)
+	s.Nil(svc)
+	s.Equal("wallet is required", err.Err)
+}
+
+func (s *TransactionServiceTestSuite) TestCreate() {
+	s.mockRepo.On("Create", mock.Anything, &s.mockAccountUser.ID, &s.mockWalletEnt.ID, s.mockTransaction).Return(s.mockTransaction, nil)
+
+	result, err := s.svc.Create(s.ctx, &s.mockAccountUser.ID, &s.mockWalletEnt.ID, s.mockTransaction)
+	s.Nil(err)
+	s.Equal(s.mockWalletEnt.ID, result.WalletID)
+	s.Equal(s.mockAccountUser.ID, result.CreatedBy)
+	s.mockWallet.AssertNotCalled(s.T(), "UpdateBalance", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *TransactionServiceTestSuite) TestCreate_Error_CategoryFromOtherWallet() {
+	s.mockCategoryEnt.WalletID = uuid.New().String()
+
+	result, err := s.svc.Create(s.ctx, &s.mockAccountUser.ID, &s.mockWalletEnt.ID, s.mockTransaction)
+	s.Nil(result)
+	s.Equal("category does not belong to the wallet", err.Err)
+	s.mockRepo.AssertNotCalled(s.T(), "Create", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	s.mockWallet.AssertNotCalled(s.T(), "UpdateBalance", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *TransactionServiceTestSuite) TestCreate_Error_WalletNotVisible() {
+	s.mockWalletEnt.OwnerID = uuid.New().String()
+	s.mockWalletEnt.TenantID = uuid.New().String()
+
+	result, err := s.svc.Create(s.ctx, &s.mockAccountUser.ID, &s.mockWalletEnt.ID, s.mockTransaction)
+	s.Nil(result)
+	s.Equal(entity.ResponseCodeNotFound, err.Code)
+}
+
+func (s *TransactionServiceTestSuite) TestCreate_SharedWallet() {
+	s.mockWalletEnt.OwnerID = uuid.New().String()
+	s.mockWalletEnt.TenantID = uuid.New().String()
+	s.mockWalletEnt.SharedWithTenants = []string{s.mockAccountUser.TenantID}
+	s.mockWalletEnt.Permissions = []entity.WalletPermission{{TennantID: s.mockAccountUser.TenantID, Level: entity.PermissionEdit}}
+	s.mockRepo.On("Create", mock.Anything, &s.mockAccountUser.ID, &s.mockWalletEnt.ID, s.mockTransaction).Return(s.mockTransaction, nil)
+
+	principal, perr := entity.NewPrincipal(s.mockAccountUser, nil)
+	s.Require().NoError(perr)
+	ctx := entity.WithPrincipal(s.ctx, principal)
+
+	_, err := s.svc.Create(ctx, &s.mockAccountUser.ID, &s.mockWalletEnt.ID, s.mockTransaction)
+	s.Nil(err, "a member edits the wallet shared with edit")
+
+	principal.Roles = []entity.AccountRoles{{Key: string(entity.ModuleTransaction), Value: string(entity.PermissionView)}}
+	_, err = s.svc.Create(ctx, &s.mockAccountUser.ID, &s.mockWalletEnt.ID, s.mockTransaction)
+	s.Require().NotNil(err)
+	s.Equal(entity.ResponseCodeForbidden, err.Code, "the assigned role limits the wallet")
+}
+
+func (s *TransactionServiceTestSuite) TestUpdate() {
+	current := *s.mockTransaction
+	current.WalletID = s.mockWalletEnt.ID
+
+	updated := current
+	updated.Type = entity.TransactionTypeIncome
+	updated.Amount = entity.NewMoney(1000, "BRL")
+
+	s.mockRepo.On("GetByID", mock.Anything, &s.mockAccountUser.ID, &s.mockWalletEnt.ID, &updated.ID).Return(&current, nil)
+	s.mockRepo.On("Update", mock.Anything, &s.mockAccountUser.ID, &s.mockWalletEnt.ID, &updated).Return(&updated, nil)
+
+	result, err := s.svc.Update(s.ctx, &s.mockAccountUser.ID, &s.mockWalletEnt.ID, &updated)
+	s.Nil(err)
+	s.Equal(entity.TransactionTypeIncome, result.Type)
+	s.mockWallet.AssertNotCalled(s.T(), "UpdateBalance", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *TransactionServiceTestSuite) TestDelete() {
+	current := *s.mockTransaction
+	current.WalletID = s.mockWalletEnt.ID
+
+	s.mockRepo.On("GetByID", mock.Anything, &s.mockAccountUser.ID, &s.mockWalletEnt.ID, &current.ID).Return(&current, nil)
+	s.mockRepo.On("Delete", mock.Anything, &s.mockAccountUser.ID, &current.WalletID, &current.ID).Return(nil)
+
+	err := s.svc.Delete(s.ctx, &s.mockAccountUser.ID, &s.mockWalletEnt.ID, &current.ID)
+	s.Nil(err)
+	s.mockRepo.AssertExpectations(s.T())
+}
+
+func TestTransactionTestSuite(t *testing.T) {
+	suite.Run(t, new(TransactionServiceTestSuite))
+}
@@ -0,0 +1,336 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/internal/core/repository"
+	"github.com/Tomelin/financial-management-backend/pkg/logger"
+	"github.com/Tomelin/financial-management-backend/pkg/totp"
+)
+
+type TwoFactorSvc struct {
+	repo repository.ITwoFactorRepo
+	// challenges keeps the challenge tokens of the login with the action tokens
+	challenges repository.ILocalAuthRepo
+	user       entity.IUser
+	tenant     entity.ITenant
+	auth       entity.IAuthorization
+	log        logger.Logger
+}
+
+func NewTwoFactorSvc(repo repository.ITwoFactorRepo, challenges repository.ILocalAuthRepo, user entity.IUser, tenant entity.ITenant, auth entity.IAuthorization, l logger.Logger) (entity.ITwoFactor, error) {
+	if repo == nil || challenges == nil || user == nil || tenant == nil || auth == nil {
+		return nil, l.Error(&logger.Message{
+			Body: "two-factor repository, action token repository, user service, tenant service and authorization service are required",
+			Code: logger.ResponseCodeInternalServer,
+		})
+	}
+
+	return &TwoFactorSvc{
+		repo:       repo,
+		challenges: challenges,
+		user:       user,
+		tenant:     tenant,
+		auth:       auth,
+		log:        l,
+	}, nil
+}
+
+func (a *TwoFactorSvc) Login(ctx context.Context, user *entity.AccountUser) (*entity.LoginResult, error) {
+	twoFactor, err := a.repo.GetTwoFactor(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	enabled := twoFactor != nil && twoFactor.Enabled
+	if !enabled {
+		required, err := a.repo.TwoFactorRequired(ctx, user.ID, user.TenantID)
+		if err != nil {
+			return nil, err
+		}
+		if !required {
+			token, err := a.issue(ctx, user)
+			if err != nil {
+				return nil, err
+			}
+			return &entity.LoginResult{TokenPair: token}, nil
+		}
+	}
+
+	challenge, record, err := entity.NewActionToken(user.ID, entity.ActionTokenTwoFactor, entity.TwoFactorChallengeTTL)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.challenges.CreateActionToken(ctx, record); err != nil {
+		return nil, err
+	}
+
+	return &entity.LoginResult{
+		TwoFactorRequired:   true,
+		TwoFactorEnrollment: !enabled,
+		ChallengeToken:      challenge,
+	}, nil
+}
+
+// Verify ends the challenge whether the code is right or not, a code is not guessed in a single login
+func (a *TwoFactorSvc) Verify(ctx context.Context, request *entity.TwoFactorVerifyRequest) (*entity.TwoFactorVerifyResult, error) {
+	if err := request.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %s", entity.ErrInvalidRequest, err.Error())
+	}
+
+	record, err := a.challenge(ctx, request.ChallengeToken)
+	if err != nil {
+		return nil, err
+	}
+	used, err := a.challenges.UseActionToken(ctx, record.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !used {
+		return nil, fmt.Errorf("%w: %w", entity.ErrUnauthorized, entity.ErrInvalidActionToken)
+	}
+
+	twoFactor, err := a.repo.GetTwoFactor(ctx, record.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor == nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrUnauthorized, entity.ErrTwoFactorNotEnabled)
+	}
+
+	result := &entity.TwoFactorVerifyResult{}
+	if twoFactor.Enabled {
+		err = a.check(ctx, twoFactor, request.Code+request.RecoveryCode)
+	} else if request.Code != "" {
+		// the challenge to enroll, the first code confirms the secret
+		result.RecoveryCodes, err = a.enable(ctx, twoFactor, request.Code)
+	} else {
+		err = entity.ErrInvalidTwoFactorCode
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrUnauthorized, err)
+	}
+
+	user, err := a.user.GetById(ctx, &record.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	result.TokenPair, err = a.issue(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (a *TwoFactorSvc) ChallengeUser(ctx context.Context, challengeToken string) (string, error) {
+	record, err := a.challenge(ctx, challengeToken)
+	if err != nil {
+		return "", err
+	}
+	return record.UserID, nil
+}
+
+func (a *TwoFactorSvc) Enroll(ctx context.Context, userID string) (*entity.TwoFactorEnrollment, error) {
+	twoFactor, err := a.repo.GetTwoFactor(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor != nil && twoFactor.Enabled {
+		return nil, entity.ErrTwoFactorEnabled
+	}
+
+	user, err := a.user.GetById(ctx, &userID)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	err = a.repo.SaveTwoFactor(ctx, &entity.TwoFactor{
+		UserID:    userID,
+		Secret:    secret,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.TwoFactorEnrollment{
+		Secret: secret,
+		URI:    totp.URI(entity.TwoFactorIssuer, user.Email, secret),
+	}, nil
+}
+
+func (a *TwoFactorSvc) Confirm(ctx context.Context, userID, code string) ([]string, error) {
+	twoFactor, err := a.repo.GetTwoFactor(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor == nil {
+		return nil, entity.ErrTwoFactorNotEnabled
+	}
+	if twoFactor.Enabled {
+		return nil, entity.ErrTwoFactorEnabled
+	}
+
+	return a.enable(ctx, twoFactor, code)
+}
+
+func (a *TwoFactorSvc) Disable(ctx context.Context, userID, code string) error {
+	twoFactor, err := a.enabled(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	user, err := a.user.GetById(ctx, &userID)
+	if err != nil {
+		return err
+	}
+	required, err := a.repo.TwoFactorRequired(ctx, user.ID, user.TenantID)
+	if err != nil {
+		return err
+	}
+	if required {
+		return entity.ErrTwoFactorRequired
+	}
+
+	if err := a.check(ctx, twoFactor, code); err != nil {
+		return err
+	}
+	return a.repo.DeleteTwoFactor(ctx, userID)
+}
+
+func (a *TwoFactorSvc) RegenerateRecoveryCodes(ctx context.Context, userID, code string) ([]string, error) {
+	twoFactor, err := a.enabled(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.check(ctx, twoFactor, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := entity.NewRecoveryCodes(entity.RecoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+
+	twoFactor.RecoveryCodes = hashes
+	twoFactor.UpdatedAt = time.Now().UTC()
+	if err := a.repo.SaveTwoFactor(ctx, twoFactor); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (a *TwoFactorSvc) Status(ctx context.Context, user *entity.AccountUser) (*entity.TwoFactorStatus, error) {
+	twoFactor, err := a.repo.GetTwoFactor(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	required, err := a.repo.TwoFactorRequired(ctx, user.ID, user.TenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &entity.TwoFactorStatus{Required: required}
+	if twoFactor != nil && twoFactor.Enabled {
+		status.Enabled = true
+		status.RecoveryCodesLeft = len(twoFactor.RecoveryCodes)
+	}
+	return status, nil
+}
+
+func (a *TwoFactorSvc) RequireForTenant(ctx context.Context, userID, tenantID string, required bool) error {
+	tenant, err := a.tenant.GetById(&tenantID)
+	if err != nil {
+		return err
+	}
+	if tenant.OwnerID != userID {
+		return entity.ErrForbidden
+	}
+
+	tenant.RequireTwoFactor = required
+	tenant.UpdatedAt = time.Now()
+	_, err = a.tenant.Update(tenant)
+	return err
+}
+
+// challenge returns the record of a pending challenge, the error wraps entity.ErrUnauthorized otherwise
+func (a *TwoFactorSvc) challenge(ctx context.Context, challengeToken string) (*entity.ActionToken, error) {
+	if challengeToken == "" {
+		return nil, fmt.Errorf("%w: %w", entity.ErrUnauthorized, entity.ErrInvalidActionToken)
+	}
+
+	record, err := a.challenges.GetActionToken(ctx, entity.ActionTokenID(challengeToken))
+	if err != nil {
+		return nil, err
+	}
+	if !record.IsValid(entity.ActionTokenTwoFactor, time.Now()) {
+		return nil, fmt.Errorf("%w: %w", entity.ErrUnauthorized, entity.ErrInvalidActionToken)
+	}
+	return record, nil
+}
+
+// enabled returns the second factor of the user, entity.ErrTwoFactorNotEnabled when it is not enabled
+func (a *TwoFactorSvc) enabled(ctx context.Context, userID string) (*entity.TwoFactor, error) {
+	twoFactor, err := a.repo.GetTwoFactor(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor == nil || !twoFactor.Enabled {
+		return nil, entity.ErrTwoFactorNotEnabled
+	}
+	return twoFactor, nil
+}
+
+// enable checks the first code of the secret, enables it and returns the recovery codes
+func (a *TwoFactorSvc) enable(ctx context.Context, twoFactor *entity.TwoFactor, code string) ([]string, error) {
+	step, ok := totp.Validate(twoFactor.Secret, code, time.Now(), entity.TwoFactorSkew)
+	if !ok {
+		return nil, entity.ErrInvalidTwoFactorCode
+	}
+
+	codes, hashes, err := entity.NewRecoveryCodes(entity.RecoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+
+	twoFactor.Enabled = true
+	twoFactor.LastStep = step
+	twoFactor.RecoveryCodes = hashes
+	twoFactor.UpdatedAt = time.Now().UTC()
+	if err := a.repo.SaveTwoFactor(ctx, twoFactor); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// check accepts a code of the secret newer than the last one, or a recovery code, and records its use
+func (a *TwoFactorSvc) check(ctx context.Context, twoFactor *entity.TwoFactor, code string) error {
+	if step, ok := totp.Validate(twoFactor.Secret, code, time.Now(), entity.TwoFactorSkew); ok && step > twoFactor.LastStep {
+		twoFactor.LastStep = step
+	} else if ok || !twoFactor.UseRecoveryCode(code) {
+		return entity.ErrInvalidTwoFactorCode
+	}
+
+	twoFactor.UpdatedAt = time.Now().UTC()
+	return a.repo.SaveTwoFactor(ctx, twoFactor)
+}
+
+func (a *TwoFactorSvc) issue(ctx context.Context, user *entity.AccountUser) (*entity.TokenPair, error) {
+	return a.auth.GenerateTokenJWT(ctx, &entity.AuthorizationClaims{
+		Email:    user.Email,
+		UserID:   user.ID,
+		Username: user.Name,
+	}, user)
+}
@@ -0,0 +1,197 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/internal/core/repository"
+	"github.com/Tomelin/financial-management-backend/internal/core/service"
+	"github.com/Tomelin/financial-management-backend/pkg/db"
+	"github.com/Tomelin/financial-management-backend/pkg/logger"
+	"github.com/Tomelin/financial-management-backend/pkg/totp"
+	"github.com/Tomelin/financial-management-backend/tests/coremocks"
+)
+
+type TwoFactorServiceTestSuite struct {
+	suite.Suite
+	store      db.DocumentStore
+	user       *entity.AccountUser
+	mockTenant *coremocks.ITenant
+	mockAuth   *coremocks.IAuthorization
+	confirmed  string
+	svc        entity.ITwoFactor
+	ctx        context.Context
+}
+
+func (s *TwoFactorServiceTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.store = db.NewMemoryStore()
+
+	var err error
+	s.user, err = entity.NewUser(&entity.User{Name: "Teste", Email: "user@domain.com", Provider: entity.ProviderLocal})
+	s.Require().NoError(err)
+	s.user.TenantID = uuid.New().String()
+
+	mockUser := new(coremocks.IUser)
+	mockUser.On("GetById", mock.Anything, mock.Anything).Return(s.user, nil)
+
+	s.mockTenant = new(coremocks.ITenant)
+	s.mockAuth = new(coremocks.IAuthorization)
+	s.mockAuth.On("GenerateTokenJWT", mock.Anything, mock.Anything, mock.Anything).Return(&entity.TokenPair{AccessToken: "access", TokenType: "Bearer"}, nil)
+
+	l := logger.NewLoggerConfig(map[string]any{"level": "error"})
+	repo, err := repository.NewTwoFactorRepo(s.store, l)
+	s.Require().NoError(err)
+	challenges, err := repository.NewLocalAuthRepo(s.store, l)
+	s.Require().NoError(err)
+
+	s.svc, err = service.NewTwoFactorSvc(repo, challenges, mockUser, s.mockTenant, s.mockAuth, l)
+	s.Require().NoError(err)
+}
+
+// enable enrolls the user and returns the secret and the recovery codes, the code of the confirmation is kept
+func (s *TwoFactorServiceTestSuite) enable() (string, []string) {
+	enrollment, err := s.svc.Enroll(s.ctx, s.user.ID)
+	s.Require().NoError(err)
+	s.Contains(enrollment.URI, "otpauth://totp/")
+
+	s.confirmed = s.code(enrollment.Secret, 0)
+	codes, err := s.svc.Confirm(s.ctx, s.user.ID, s.confirmed)
+	s.Require().NoError(err)
+	s.Len(codes, entity.RecoveryCodeCount)
+	return enrollment.Secret, codes
+}
+
+// code returns the code of the secret steps after now
+func (s *TwoFactorServiceTestSuite) code(secret string, steps int) string {
+	code, err := totp.Code(secret, time.Now().Add(time.Duration(steps)*totp.Period))
+	s.Require().NoError(err)
+	return code
+}
+
+func (s *TwoFactorServiceTestSuite) challenge() *entity.LoginResult {
+	result, err := s.svc.Login(s.ctx, s.user)
+	s.Require().NoError(err)
+	s.Require().True(result.TwoFactorRequired)
+	s.Nil(result.TokenPair, "the tokens are issued after the second factor")
+	return result
+}
+
+func (s *TwoFactorServiceTestSuite) TestLogin_WithoutTwoFactor() {
+	result, err := s.svc.Login(s.ctx, s.user)
+	s.Require().NoError(err)
+	s.False(result.TwoFactorRequired)
+	s.Equal("access", result.AccessToken)
+}
+
+func (s *TwoFactorServiceTestSuite) TestLogin_VerifyCode() {
+	secret, _ := s.enable()
+
+	result := s.challenge()
+	s.False(result.TwoFactorEnrollment)
+
+	// the code of the confirmation is not accepted again
+	_, err := s.svc.Verify(s.ctx, &entity.TwoFactorVerifyRequest{ChallengeToken: result.ChallengeToken, Code: s.confirmed})
+	s.True(errors.Is(err, entity.ErrUnauthorized))
+	_, err = s.svc.Verify(s.ctx, &entity.TwoFactorVerifyRequest{ChallengeToken: result.ChallengeToken, Code: s.code(secret, 1)})
+	s.True(errors.Is(err, entity.ErrUnauthorized), "a wrong code ends the challenge")
+
+	result = s.challenge()
+	verified, err := s.svc.Verify(s.ctx, &entity.TwoFactorVerifyRequest{ChallengeToken: result.ChallengeToken, Code: s.code(secret, 1)})
+	s.Require().NoError(err)
+	s.Equal("access", verified.AccessToken)
+	s.Empty(verified.RecoveryCodes)
+	s.mockAuth.AssertNumberOfCalls(s.T(), "GenerateTokenJWT", 1)
+}
+
+func (s *TwoFactorServiceTestSuite) TestLogin_VerifyRecoveryCode() {
+	_, codes := s.enable()
+
+	result := s.challenge()
+	_, err := s.svc.Verify(s.ctx, &entity.TwoFactorVerifyRequest{ChallengeToken: result.ChallengeToken, RecoveryCode: " " + codes[3] + " "})
+	s.Require().NoError(err)
+
+	result = s.challenge()
+	_, err = s.svc.Verify(s.ctx, &entity.TwoFactorVerifyRequest{ChallengeToken: result.ChallengeToken, RecoveryCode: codes[3]})
+	s.True(errors.Is(err, entity.ErrUnauthorized), "a recovery code is used once")
+
+	status, err := s.svc.Status(s.ctx, s.user)
+	s.Require().NoError(err)
+	s.True(status.Enabled)
+	s.Equal(entity.RecoveryCodeCount-1, status.RecoveryCodesLeft)
+
+	_, err = s.svc.Verify(s.ctx, &entity.TwoFactorVerifyRequest{ChallengeToken: "unknown", Code: "123456"})
+	s.True(errors.Is(err, entity.ErrUnauthorized))
+	_, err = s.svc.Verify(s.ctx, &entity.TwoFactorVerifyRequest{ChallengeToken: "unknown"})
+	s.True(errors.Is(err, entity.ErrInvalidRequest))
+}
+
+func (s *TwoFactorServiceTestSuite) TestRequiredByTenant_EnrollsAtLogin() {
+	tenants, err := repository.NewTenantRepository(s.store)
+	s.Require().NoError(err)
+	_, err = tenants.Create(&entity.TenantResponse{ID: s.user.TenantID, Name: "owner@domain.com", OwnerID: s.user.ID, RequireTwoFactor: true})
+	s.Require().NoError(err)
+
+	result := s.challenge()
+	s.True(result.TwoFactorEnrollment)
+
+	userID, err := s.svc.ChallengeUser(s.ctx, result.ChallengeToken)
+	s.Require().NoError(err)
+	s.Equal(s.user.ID, userID)
+
+	enrollment, err := s.svc.Enroll(s.ctx, userID)
+	s.Require().NoError(err)
+
+	verified, err := s.svc.Verify(s.ctx, &entity.TwoFactorVerifyRequest{ChallengeToken: result.ChallengeToken, Code: s.code(enrollment.Secret, 0)})
+	s.Require().NoError(err)
+	s.Equal("access", verified.AccessToken)
+	s.Len(verified.RecoveryCodes, entity.RecoveryCodeCount)
+
+	err = s.svc.Disable(s.ctx, s.user.ID, s.code(enrollment.Secret, 1))
+	s.True(errors.Is(err, entity.ErrTwoFactorRequired))
+}
+
+func (s *TwoFactorServiceTestSuite) TestDisableAndRegenerate() {
+	secret, codes := s.enable()
+
+	_, err := s.svc.Enroll(s.ctx, s.user.ID)
+	s.True(errors.Is(err, entity.ErrTwoFactorEnabled))
+
+	_, err = s.svc.RegenerateRecoveryCodes(s.ctx, s.user.ID, "000000")
+	s.True(errors.Is(err, entity.ErrInvalidTwoFactorCode))
+	newCodes, err := s.svc.RegenerateRecoveryCodes(s.ctx, s.user.ID, s.code(secret, 1))
+	s.Require().NoError(err)
+	s.Len(newCodes, entity.RecoveryCodeCount)
+
+	s.True(errors.Is(s.svc.Disable(s.ctx, s.user.ID, codes[0]), entity.ErrInvalidTwoFactorCode), "the old recovery codes were replaced")
+	s.Require().NoError(s.svc.Disable(s.ctx, s.user.ID, newCodes[0]))
+	s.True(errors.Is(s.svc.Disable(s.ctx, s.user.ID, newCodes[1]), entity.ErrTwoFactorNotEnabled))
+
+	result, err := s.svc.Login(s.ctx, s.user)
+	s.Require().NoError(err)
+	s.False(result.TwoFactorRequired)
+}
+
+func (s *TwoFactorServiceTestSuite) TestRequireForTenant() {
+	tenant := &entity.TenantResponse{ID: s.user.TenantID, Name: "owner@domain.com", OwnerID: s.user.ID}
+	s.mockTenant.On("GetById", mock.Anything).Return(tenant, nil)
+	s.mockTenant.On("Update", mock.Anything).Return(tenant, nil)
+
+	err := s.svc.RequireForTenant(s.ctx, uuid.New().String(), tenant.ID, true)
+	s.True(errors.Is(err, entity.ErrForbidden))
+	s.mockTenant.AssertNotCalled(s.T(), "Update", mock.Anything)
+
+	s.Require().NoError(s.svc.RequireForTenant(s.ctx, s.user.ID, tenant.ID, true))
+	s.mockTenant.AssertCalled(s.T(), "Update", mock.MatchedBy(func(t *entity.TenantResponse) bool { return t.RequireTwoFactor }))
+}
+
+func TestTwoFactorServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(TwoFactorServiceTestSuite))
+}
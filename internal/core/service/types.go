@@ -12,7 +12,7 @@ func ValidateUser(ctx context.Context, user entity.IUser, email *string) *entity
 		return entity.Error("email is required", "user", "ValidateUser", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
 	}
 
-	response, err := user.GetByEmail(ctx, email)
+	response, err := accountUser(ctx, user, email)
 	if response != nil {
 		return entity.Error(err.Error(), "user", "ValidateUser", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
 	}
@@ -27,3 +27,44 @@ func ValidateUser(ctx context.Context, user entity.IUser, email *string) *entity
 
 	return nil
 }
+
+// isEmptyFilter reports if no clause of the filter has a query with key and value
+func isEmptyFilter(filter []entity.QueryDBClause) bool {
+	for _, clause := range filter {
+		if !clause.IsEmpty() {
+			return false
+		}
+	}
+	return true
+}
+
+// accountUser returns the user of the email
+// The principal of the request is used when it is the same user, so the user is loaded once per request
+func accountUser(ctx context.Context, users entity.IUser, email *string) (*entity.AccountUser, error) {
+	if principal, ok := entity.PrincipalFromContext(ctx); ok && email != nil && principal.Email() == *email {
+		user := principal.User
+		return &user, nil
+	}
+
+	return users.GetByEmail(ctx, email)
+}
+
+// accountUserByID returns the user of the id, the principal of the request is used when it is the same user
+func accountUserByID(ctx context.Context, users entity.IUser, id *string) (*entity.AccountUser, error) {
+	if principal, ok := entity.PrincipalFromContext(ctx); ok && id != nil && principal.User.ID == *id {
+		user := principal.User
+		return &user, nil
+	}
+
+	return users.GetById(ctx, id)
+}
+
+// walletAllows reports if the user has the level on the module of the wallet
+// The principal of the request is used when it is the same user, so its roles and scopes limit the level of the wallet
+func walletAllows(ctx context.Context, wallet *entity.WalletResponse, user *entity.AccountUser, module entity.Module, level entity.PermissionLevel) bool {
+	if principal, ok := entity.PrincipalFromContext(ctx); ok && principal.User.ID == user.ID {
+		return principal.CanOnWallet(wallet, module, level)
+	}
+
+	return wallet.Allows(user.ID, user.TenantID, level)
+}
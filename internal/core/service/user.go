@@ -72,7 +72,7 @@ func (u *UserSvc) Create(ctx context.Context, user *entity.AccountUser) (*entity
 		},
 	}
 
-	tenant, err := u.tenant.GetByFilterMany(ctx, filterTenant[0].Queries)
+	tenant, err := u.tenant.GetByFilterMany(ctx, filterTenant)
 	if err != nil && err.Error() != "not found" {
 		return nil, err
 	}
@@ -100,9 +100,9 @@ func (u *UserSvc) Create(ctx context.Context, user *entity.AccountUser) (*entity
 	return nil, errors.New("user already exists")
 }
 
-func (u *UserSvc) Get(ctx context.Context) ([]entity.AccountUser, error) {
+func (u *UserSvc) Get(ctx context.Context, page *entity.PageRequest) (*entity.Page[entity.AccountUser], error) {
 
-	return u.repo.Get(ctx)
+	return u.repo.Get(ctx, page)
 }
 
 func (u *UserSvc) GetById(ctx context.Context, id *string) (*entity.AccountUser, error) {
@@ -146,12 +146,37 @@ func (u *UserSvc) Update(ctx context.Context, data *entity.AccountUser) (*entity
 		return nil, err
 	}
 
-	if err := data.Validate(); err != nil {
+	current, err := u.repo.GetById(ctx, &data.ID)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, errors.New("not found")
+	}
+
+	user := *current
+	user.Name = data.Name
+	user.AvatarURL = data.AvatarURL
+	user.FirstName = data.FirstName
+	user.LastName = data.LastName
+	user.NickName = data.NickName
+	user.Description = data.Description
+	user.Location = data.Location
+
+	// Only a system admin changes the roles, the tenant and the identity of a user
+	if principal, ok := entity.PrincipalFromContext(ctx); ok && principal.Can(entity.ModuleSystem, entity.PermissionSystemAdmin) {
+		user.Roles = data.Roles
+		user.TenantID = data.TenantID
+		user.Email = data.Email
+		user.Provider = data.Provider
+	}
+
+	if err := user.Validate(); err != nil {
 		return nil, err
 	}
 
-	data.UpdatedAt = time.Now()
-	return u.repo.Update(ctx, data)
+	user.UpdatedAt = time.Now()
+	return u.repo.Update(ctx, &user)
 }
 
 func (u *UserSvc) Delete(ctx context.Context, id *string) error {
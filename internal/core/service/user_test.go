@@ -8,6 +8,7 @@ import (
 	"github.com/Tomelin/financial-management-backend/internal/core/service"
 	"github.com/Tomelin/financial-management-backend/tests/coremocks"
 	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -140,7 +141,7 @@ var (
 func (s *UserServiceTestSuite) TestUserService_Error_CreateUser() {
 
 	s.mockCoreUser.On("GetByFilterOne", s.ctx, s.mockFilterUser).Return(s.mockUserEntity, nil).Once()
-	s.mockCoreTenant.On("GetByFilterMany", s.ctx, s.mockFilterTenant[0].Queries).Return(s.mockTenantsEntity, nil).Once()
+	s.mockCoreTenant.On("GetByFilterMany", s.ctx, s.mockFilterTenant).Return(s.mockTenantsEntity, nil).Once()
 
 	userSvc, err := service.NewUserService(s.mockCoreUser, s.mockCoreTenant, nil)
 	s.Require().NoError(err)
@@ -170,6 +171,40 @@ func (s *UserServiceTestSuite) TestUserService_Error_CreateUser() {
 	// s.Require().Nil(result)
 }
 
+func (s *UserServiceTestSuite) TestUserService_Update_KeepsPrivilegedFields() {
+
+	stored := *s.mockAccountUserEntity
+	s.mockCoreUser.On("GetById", mock.Anything, &stored.ID).Return(&stored, nil)
+	s.mockCoreUser.On("Update", mock.Anything, mock.Anything).Return(
+		func(_ context.Context, data *entity.AccountUser) *entity.AccountUser { return data }, nil)
+
+	userSvc, err := service.NewUserService(s.mockCoreUser, s.mockCoreTenant, nil)
+	s.Require().NoError(err)
+
+	data := stored
+	data.Name = "Changed"
+	data.TenantID = uuid.New().String()
+	data.Email = "other@domain.com"
+	data.Roles = []entity.AccountRoles{{Key: string(entity.ModuleSystem), Value: string(entity.PermissionSystemAdmin)}}
+
+	principal, _ := entity.NewPrincipal(&stored, nil)
+	result, err := userSvc.Update(entity.WithPrincipal(s.ctx, principal), &data)
+	s.Require().NoError(err)
+	s.Require().Equal("Changed", result.Name)
+	s.Require().Equal(stored.TenantID, result.TenantID)
+	s.Require().Equal(stored.Email, result.Email)
+	s.Require().Empty(result.Roles)
+
+	admin := stored
+	admin.Roles = data.Roles
+	principal, _ = entity.NewPrincipal(&admin, nil)
+	result, err = userSvc.Update(entity.WithPrincipal(s.ctx, principal), &data)
+	s.Require().NoError(err)
+	s.Require().Equal(data.TenantID, result.TenantID)
+	s.Require().Equal(data.Email, result.Email)
+	s.Require().Equal(data.Roles, result.Roles)
+}
+
 var (
 	ctx      = context.Background()
 	mockUser = &entity.User{
@@ -7,25 +7,27 @@ import (
 	"time"
 
 	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/internal/core/repository"
 	"github.com/Tomelin/financial-management-backend/pkg/utils"
 )
 
 type IWalletSvc interface {
 	entity.IWallet
-	SetBalance(id *string, balance *float64) error
+	SetBalance(id *string, balance *entity.Money) error
 	PlanValidate(id *string) error
 }
 
 type WalletSvc struct {
-	repo   entity.IWallet
-	tenant entity.ITenant
-	owner  entity.IUser
+	repo        repository.IWalletRepo
+	tenant      entity.ITenant
+	owner       entity.IUser
+	entitlement entity.IEntitlement
 }
 
 // NewWalletSvc creates a new WalletSvc
-// It requires a repository, a tenant, and a user
+// It requires a repository, a tenant, a user and the entitlements of the plans
 // It returns a WalletSvc and an error
-func NewWalletSvc(repo entity.IWallet, tenant entity.ITenant, user entity.IUser) (entity.IWallet, *entity.ModuleError) {
+func NewWalletSvc(repo repository.IWalletRepo, tenant entity.ITenant, user entity.IUser, entitlement entity.IEntitlement) (entity.IWallet, *entity.ModuleError) {
 	if repo == nil {
 		return nil, entity.Error("repo is required", "wallet", "inicialization", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
 	}
@@ -34,10 +36,15 @@ func NewWalletSvc(repo entity.IWallet, tenant entity.ITenant, user entity.IUser)
 		return nil, entity.Error("tenant is required", "wallet", "inicialization", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
 	}
 
+	if entitlement == nil {
+		return nil, entity.Error("entitlement is required", "wallet", "inicialization", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	}
+
 	return &WalletSvc{
-		repo:   repo,
-		tenant: tenant,
-		owner:  user,
+		repo:        repo,
+		tenant:      tenant,
+		owner:       user,
+		entitlement: entitlement,
 	}, nil
 }
 
@@ -56,58 +63,39 @@ func (w *WalletSvc) Create(ctx context.Context, userId *string, wallet *entity.W
 		return nil, entity.Error("required email of user", "wallet", "Create", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
 	}
 
-	// Validate if the owner ID is valid
-	ownerId := wallet.OwnerID
-	if err := utils.ValidateUUID(&ownerId); err != nil {
-		return nil, entity.Error(err.Error(), "wallet", "Create", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
-	}
-
-	// Validate if the tenant ID is valid
-	tenantId := wallet.TenantID
-	if err := utils.ValidateUUID(&tenantId); err != nil {
-		return nil, entity.Error(err.Error(), "wallet", "Create", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
-	}
-
-	// Validate if the tenant exists
-	tenant, err := w.tenant.GetById(&tenantId)
-	if err != nil || tenant == nil {
+	// The wallet belongs to the user and to the tenant of the user
+	owner, err := w.owner.GetById(ctx, userId)
+	if err != nil {
 		return nil, entity.Error(err.Error(), "wallet", "GetById", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
 	}
-
-	// Validate if the tenant exists
-	owner, err := w.owner.GetById(ctx, &ownerId)
-	if err != nil || owner == nil {
-		return nil, entity.Error(err.Error(), "wallet", "GetById", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	if owner == nil {
+		return nil, entity.Error("owner not found", "wallet", "GetById", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
 	}
+	wallet.OwnerID = owner.ID
+	wallet.TenantID = owner.TenantID
 
-	// Validate if the user is authorized to create a wallet
-	if tenant.OwnerID != ownerId && tenant.ID != tenantId && *userId != tenant.OwnerID && owner.ID != *userId {
-		return nil, entity.Error("unathorized user", "wallet", "Create", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	tenant, err := w.tenant.GetById(&owner.TenantID)
+	if err != nil {
+		return nil, entity.Error(err.Error(), "wallet", "GetById", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
 	}
-
-	// Create a query to filter the wallets by owner ID and tenant ID
-	queries := []entity.QueryDB{
-		{Key: "owner_id", Value: wallet.OwnerID, Condition: "=="},
-		{Key: "tenant_id", Value: wallet.TenantID, Condition: "=="},
+	if tenant == nil {
+		return nil, entity.Error("tenant not found", "wallet", "GetById", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
 	}
 
-	// Get the wallets by filter
-	data, mErr := w.repo.GetByFilterMany(ctx, userId, queries)
+	// Get the wallets of the tenant, the limit of the plan counts the wallets of every member
+	data, mErr := w.repo.GetByTenant(ctx, &tenant.ID)
 	if mErr != nil {
 		return nil, mErr
 	}
 
-	dataCount := len(data)
-	if dataCount > 0 {
-		if (dataCount == 1 && tenant.Plan.Name == "bronze") || (dataCount == 2 && tenant.Plan.Name == "silver") {
-			return nil, entity.Error("limit of wallets reached", "wallet", "Create", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	for _, getWallet := range data {
+		if getWallet.OwnerID == wallet.OwnerID && wallet.Name == getWallet.Name {
+			return nil, entity.Error("wallet already exists", "wallet", "Create", entity.ApplicationLayerService, entity.ResponseCodeConflict)
 		}
+	}
 
-		for _, getWallet := range data {
-			if wallet.Name == getWallet.Name {
-				return nil, entity.Error("limit of wallets reached", "wallet", "Create", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
-			}
-		}
+	if mErr := w.entitlement.Check(ctx, &tenant.ID, entity.FeatureWalletsMax, len(data)); mErr != nil {
+		return nil, mErr
 	}
 
 	// Create the wallet
@@ -121,8 +109,25 @@ func (w *WalletSvc) Create(ctx context.Context, userId *string, wallet *entity.W
 	return result, nil
 }
 
-func (w *WalletSvc) Get(ctx context.Context, userId *string) ([]entity.WalletResponse, *entity.ModuleError) {
-	return w.repo.Get(ctx, userId)
+// Get pages the wallets the user owns, of the tenant of the user and shared with it, with the level of the user
+func (w *WalletSvc) Get(ctx context.Context, userId *string, page *entity.PageRequest) (*entity.Page[entity.WalletResponse], *entity.ModuleError) {
+
+	if userId == nil || *userId == "" {
+		return nil, entity.Error("user id cannot be empty", "wallet", "Get", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	}
+
+	user, err := accountUserByID(ctx, w.owner, userId)
+	if err != nil || user == nil {
+		return nil, entity.Error("user not found", "wallet", "Get", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	}
+
+	result, mErr := w.repo.GetAccessible(ctx, &user.ID, &user.TenantID, page)
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	withPermission(result.Items, user)
+	return result, nil
 }
 
 func (w *WalletSvc) GetWalletByIdAndUserID(ctx context.Context, email *string, walletId *string) (*entity.WalletResponse, *entity.ModuleError) {
@@ -139,7 +144,7 @@ func (w *WalletSvc) GetWalletByIdAndUserID(ctx context.Context, email *string, w
 		return nil, entity.Error("email is invalid", "wallet", "GetById", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
 	}
 
-	user, err := w.owner.GetByEmail(ctx, email)
+	user, err := accountUser(ctx, w.owner, email)
 	if err != nil {
 		return nil, entity.Error(err.Error(), "wallet", "GetById", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
 	}
@@ -148,7 +153,18 @@ func (w *WalletSvc) GetWalletByIdAndUserID(ctx context.Context, email *string, w
 		return nil, entity.Error("user not found", "wallet", "GetById", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
 	}
 
-	return w.repo.GetWalletByIdAndUserID(ctx, &user.ID, walletId)
+	wallet, mErr := w.repo.GetByID(ctx, walletId)
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	// a wallet the user cannot see is the same as a missing wallet
+	if wallet == nil || wallet.PermissionOf(user.ID, user.TenantID) == "" {
+		return &entity.WalletResponse{}, nil
+	}
+
+	wallet.Permission = wallet.PermissionOf(user.ID, user.TenantID)
+	return wallet, nil
 }
 
 func (w *WalletSvc) Update(ctx context.Context, email *string, data *entity.WalletResponse) (*entity.WalletResponse, *entity.ModuleError) {
@@ -166,10 +182,29 @@ func (w *WalletSvc) Update(ctx context.Context, email *string, data *entity.Wall
 		return nil, mErr
 	}
 
-	if user.ID != data.OwnerID {
-		return nil, entity.Error("unauthorized user", "wallet", "Update", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	current, mErr := w.repo.GetByID(ctx, &data.ID)
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	if current == nil || current.ID == "" || current.PermissionOf(user.ID, user.TenantID) == "" {
+		return nil, entity.Error("wallet not found", "wallet", "Update", entity.ApplicationLayerService, entity.ResponseCodeNotFound)
+	}
+
+	if !walletAllows(ctx, current, user, entity.ModuleWallet, entity.PermissionEdit) {
+		return nil, entity.Error("unauthorized user", "wallet", "Update", entity.ApplicationLayerService, entity.ResponseCodeForbidden)
 	}
 
+	// the owner, the tenant and the shares are not changed by an update, they have their own routes
+	// read-only follows the plan of the tenant, the balance follows the transactions
+	data.OwnerID = current.OwnerID
+	data.TenantID = current.TenantID
+	data.Balance = current.Balance
+	data.Currency = current.Currency
+	data.SharedWithTenants = current.SharedWithTenants
+	data.Permissions = current.Permissions
+	data.ReadOnly = current.ReadOnly
+
 	tenantID := data.TenantID
 	tenant, err := w.tenant.GetById(&tenantID)
 	if err != nil {
@@ -189,7 +224,14 @@ func (w *WalletSvc) Update(ctx context.Context, email *string, data *entity.Wall
 		return nil, entity.Error("owner not found", "wallet", "Update", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
 	}
 
-	return w.repo.Update(ctx, &user.ID, data)
+	data.SetUpdate()
+	updated, mErr := w.repo.Update(ctx, &current.OwnerID, data)
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	updated.Permission = updated.PermissionOf(user.ID, user.TenantID)
+	return updated, nil
 }
 
 func (w *WalletSvc) Delete(ctx context.Context, email *string, id *string) *entity.ModuleError {
@@ -209,16 +251,25 @@ func (w *WalletSvc) Delete(ctx context.Context, email *string, id *string) *enti
 	}
 
 	// if wallet == nil || *wallet == (entity.WalletResponse{}) {
-	if wallet == nil {
+	if wallet == nil || wallet.ID == "" {
 		return entity.Error("wallet not found", "wallet", "Delete", entity.ApplicationLayerService, entity.ResponseCodeNotFound)
 	}
 
+	// only the owner deletes the wallet, the tenants it is shared with leave it with unshare
+	if wallet.OwnerID != user.ID {
+		return entity.Error("unauthorized user", "wallet", "Delete", entity.ApplicationLayerService, entity.ResponseCodeForbidden)
+	}
+
+	if principal, ok := entity.PrincipalFromContext(ctx); ok && principal.User.ID == user.ID && !principal.RoleAllows(entity.ModuleWallet, entity.PermissionOwner) {
+		return entity.Error(entity.ErrForbidden.Error(), "wallet", "Delete", entity.ApplicationLayerService, entity.ResponseCodeForbidden)
+	}
+
 	return w.repo.Delete(ctx, &user.ID, id)
 }
 
-func (w *WalletSvc) GetByFilterMany(ctx context.Context, email *string, filter []entity.QueryDB) ([]entity.WalletResponse, *entity.ModuleError) {
+func (w *WalletSvc) GetByFilterMany(ctx context.Context, email *string, filter []entity.QueryDBClause) ([]entity.WalletResponse, *entity.ModuleError) {
 
-	if len(filter) == 0 {
+	if isEmptyFilter(filter) {
 		return nil, entity.Error("filter cannot be empty", "wallet", "GetByFilterMany", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
 	}
 
@@ -231,7 +282,13 @@ func (w *WalletSvc) GetByFilterMany(ctx context.Context, email *string, filter [
 		return nil, entity.Error("user not found", "wallet", "GetByFilterMany", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
 	}
 
-	return w.repo.GetByFilterMany(ctx, &user.ID, filter)
+	wallets, mErr := w.repo.GetAccessibleByFilter(ctx, &user.ID, &user.TenantID, filter)
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	withPermission(wallets, user)
+	return wallets, nil
 }
 
 func (w *WalletSvc) GetByFilterOne(ctx context.Context, email *string, filter []entity.QueryDB) (*entity.WalletResponse, *entity.ModuleError) {
@@ -275,8 +332,30 @@ func (w *WalletSvc) GetByID(ctx context.Context, walletId *string) (*entity.Wall
 	return result, nil
 }
 
-func (w *WalletSvc) UpdateBalance(ctx context.Context, walletID *string, balance *float64) (*entity.WalletResponse, *entity.ModuleError) {
-	return nil, nil
+// UpdateBalance applies a signed delta to the wallet balance
+// The caller must already have checked that the user can change the wallet
+func (w *WalletSvc) UpdateBalance(ctx context.Context, walletID *string, delta *entity.Money) (*entity.WalletResponse, *entity.ModuleError) {
+
+	if walletID == nil || *walletID == "" {
+		return nil, entity.Error("wallet id cannot be empty", "wallet", "UpdateBalance", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	}
+
+	if err := utils.ValidateUUID(walletID); err != nil {
+		return nil, entity.Error(err.Error(), "wallet", "UpdateBalance", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	}
+
+	if delta == nil {
+		return nil, entity.Error("delta cannot be empty", "wallet", "UpdateBalance", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	}
+
+	return w.repo.UpdateBalance(ctx, walletID, delta)
+}
+
+// withPermission sets the level of the user on each wallet
+func withPermission(wallets []entity.WalletResponse, user *entity.AccountUser) {
+	for i := range wallets {
+		wallets[i].Permission = wallets[i].PermissionOf(user.ID, user.TenantID)
+	}
 }
 
 func (w *WalletSvc) userIsValid(ctx context.Context, userId, requesterId *string) error {
@@ -317,7 +396,7 @@ func (w *WalletSvc) getUser(ctx context.Context, email *string) (*entity.Account
 		return nil, entity.Error("email is invalid", "wallet", "Generic", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
 	}
 
-	user, err := w.owner.GetByEmail(ctx, email)
+	user, err := accountUser(ctx, w.owner, email)
 	if err != nil {
 		return nil, entity.Error(err.Error(), "wallet", "Generic", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
 	}
@@ -0,0 +1,372 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/internal/core/repository"
+	"github.com/Tomelin/financial-management-backend/pkg/utils"
+)
+
+type WalletShareSvc struct {
+	wallets     repository.IWalletRepo
+	invitations repository.IWalletInvitationRepo
+	entitlement entity.IEntitlement
+	user        entity.IUser
+}
+
+// NewWalletShareSvc creates the service of the invitations and the levels of the shared wallets
+func NewWalletShareSvc(wallets repository.IWalletRepo, invitations repository.IWalletInvitationRepo, entitlement entity.IEntitlement, user entity.IUser) (entity.IWalletShare, *entity.ModuleError) {
+	if wallets == nil || invitations == nil {
+		return nil, entity.Error("repo is required", "wallet", "inicialization", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	}
+
+	if entitlement == nil || user == nil {
+		return nil, entity.Error("entitlement and user are required", "wallet", "inicialization", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	}
+
+	return &WalletShareSvc{
+		wallets:     wallets,
+		invitations: invitations,
+		entitlement: entitlement,
+		user:        user,
+	}, nil
+}
+
+func (w *WalletShareSvc) Invite(ctx context.Context, userId *string, walletId *string, data *entity.WalletInvitationRequest) (*entity.WalletInvitation, *entity.ModuleError) {
+
+	wallet, user, mErr := w.getWallet(ctx, userId, walletId, entity.PermissionAdmin, "Invite")
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	invitation, mErr := entity.NewWalletInvitation(wallet, user.ID, data)
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	if invitation.Email == entity.NormalizeEmail(user.Email) {
+		return nil, entity.Error("cannot invite yourself", "wallet", "Invite", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	}
+
+	// the invited email may not have an account yet, the access is checked again on accept
+	if invited, err := w.user.GetByEmail(ctx, &invitation.Email); err == nil && invited != nil && invited.ID != "" {
+		if wallet.PermissionOf(invited.ID, invited.TenantID) != "" {
+			return nil, entity.Error("wallet is already shared with the user", "wallet", "Invite", entity.ApplicationLayerService, entity.ResponseCodeConflict)
+		}
+	}
+
+	invitations, mErr := w.invitations.GetInvitationsByWallet(ctx, wallet.ID)
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	now := time.Now().UTC()
+	for _, pending := range invitations {
+		if pending.Email == invitation.Email && pending.IsPending(now) {
+			return nil, entity.Error("the email already has a pending invitation", "wallet", "Invite", entity.ApplicationLayerService, entity.ResponseCodeConflict)
+		}
+	}
+
+	if mErr := w.checkLimit(ctx, wallet, invitations, ""); mErr != nil {
+		return nil, mErr
+	}
+
+	if mErr := w.invitations.SaveInvitation(ctx, invitation); mErr != nil {
+		return nil, mErr
+	}
+
+	return invitation, nil
+}
+
+func (w *WalletShareSvc) GetInvitations(ctx context.Context, userId *string, walletId *string) ([]entity.WalletInvitation, *entity.ModuleError) {
+
+	wallet, _, mErr := w.getWallet(ctx, userId, walletId, entity.PermissionAdmin, "GetInvitations")
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	invitations, mErr := w.invitations.GetInvitationsByWallet(ctx, wallet.ID)
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	now := time.Now().UTC()
+	for i := range invitations {
+		invitations[i].Refresh(now)
+	}
+	return invitations, nil
+}
+
+func (w *WalletShareSvc) CancelInvitation(ctx context.Context, userId *string, walletId *string, invitationId *string) *entity.ModuleError {
+
+	wallet, _, mErr := w.getWallet(ctx, userId, walletId, entity.PermissionAdmin, "CancelInvitation")
+	if mErr != nil {
+		return mErr
+	}
+
+	invitation, mErr := w.getInvitation(ctx, invitationId, "CancelInvitation")
+	if mErr != nil {
+		return mErr
+	}
+
+	if invitation.WalletID != wallet.ID {
+		return entity.Error("invitation not found", "wallet", "CancelInvitation", entity.ApplicationLayerService, entity.ResponseCodeNotFound)
+	}
+
+	now := time.Now().UTC()
+	if !invitation.IsPending(now) {
+		return entity.Error("invitation is not pending", "wallet", "CancelInvitation", entity.ApplicationLayerService, entity.ResponseCodeConflict)
+	}
+
+	invitation.Respond(entity.InvitationCanceled, now)
+	return w.invitations.SaveInvitation(ctx, invitation)
+}
+
+func (w *WalletShareSvc) GetReceivedInvitations(ctx context.Context, userId *string) ([]entity.WalletInvitation, *entity.ModuleError) {
+
+	user, mErr := w.getUser(ctx, userId, "GetReceivedInvitations")
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	invitations, mErr := w.invitations.GetInvitationsByEmail(ctx, entity.NormalizeEmail(user.Email))
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	now := time.Now().UTC()
+	pending := make([]entity.WalletInvitation, 0, len(invitations))
+	for _, invitation := range invitations {
+		if invitation.IsPending(now) {
+			pending = append(pending, invitation)
+		}
+	}
+	return pending, nil
+}
+
+func (w *WalletShareSvc) Accept(ctx context.Context, userId *string, invitationId *string) (*entity.WalletResponse, *entity.ModuleError) {
+
+	user, invitation, mErr := w.receivedInvitation(ctx, userId, invitationId, "Accept")
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	if user.TenantID == "" {
+		return nil, entity.Error("user has no tenant", "wallet", "Accept", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	}
+
+	wallet, mErr := w.wallets.GetByID(ctx, &invitation.WalletID)
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	if wallet == nil || wallet.ID == "" {
+		return nil, entity.Error("wallet not found", "wallet", "Accept", entity.ApplicationLayerService, entity.ResponseCodeNotFound)
+	}
+
+	if wallet.OwnerID == user.ID || wallet.TenantID == user.TenantID {
+		return nil, entity.Error("wallet already belongs to the tenant of the user", "wallet", "Accept", entity.ApplicationLayerService, entity.ResponseCodeConflict)
+	}
+
+	// a tenant the wallet is already shared with only changes its level
+	if !wallet.IsSharedWith(user.TenantID) {
+		invitations, mErr := w.invitations.GetInvitationsByWallet(ctx, wallet.ID)
+		if mErr != nil {
+			return nil, mErr
+		}
+
+		if mErr := w.checkLimit(ctx, wallet, invitations, invitation.ID); mErr != nil {
+			return nil, mErr
+		}
+	}
+
+	if err := wallet.Grant(user.TenantID, invitation.Level); err != nil {
+		return nil, entity.Error(err.Error(), "wallet", "Accept", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	}
+	wallet.SetUpdate()
+
+	updated, mErr := w.wallets.Update(ctx, &wallet.OwnerID, wallet)
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	invitation.Respond(entity.InvitationAccepted, time.Now().UTC())
+	if mErr := w.invitations.SaveInvitation(ctx, invitation); mErr != nil {
+		return nil, mErr
+	}
+
+	updated.Permission = updated.PermissionOf(user.ID, user.TenantID)
+	return updated, nil
+}
+
+func (w *WalletShareSvc) Decline(ctx context.Context, userId *string, invitationId *string) *entity.ModuleError {
+
+	_, invitation, mErr := w.receivedInvitation(ctx, userId, invitationId, "Decline")
+	if mErr != nil {
+		return mErr
+	}
+
+	invitation.Respond(entity.InvitationDeclined, time.Now().UTC())
+	return w.invitations.SaveInvitation(ctx, invitation)
+}
+
+func (w *WalletShareSvc) SetPermission(ctx context.Context, userId *string, walletId *string, tenantId *string, level entity.PermissionLevel) (*entity.WalletResponse, *entity.ModuleError) {
+
+	wallet, user, mErr := w.getWallet(ctx, userId, walletId, entity.PermissionAdmin, "SetPermission")
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	if tenantId == nil || !wallet.IsSharedWith(*tenantId) {
+		return nil, entity.Error("wallet is not shared with the tenant", "wallet", "SetPermission", entity.ApplicationLayerService, entity.ResponseCodeNotFound)
+	}
+
+	if err := wallet.Grant(*tenantId, level); err != nil {
+		return nil, entity.Error(err.Error(), "wallet", "SetPermission", entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	}
+	wallet.SetUpdate()
+
+	updated, mErr := w.wallets.Update(ctx, &wallet.OwnerID, wallet)
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	updated.Permission = updated.PermissionOf(user.ID, user.TenantID)
+	return updated, nil
+}
+
+func (w *WalletShareSvc) Unshare(ctx context.Context, userId *string, walletId *string, tenantId *string) *entity.ModuleError {
+
+	wallet, user, mErr := w.getWallet(ctx, userId, walletId, entity.PermissionView, "Unshare")
+	if mErr != nil {
+		return mErr
+	}
+
+	if tenantId == nil || !wallet.IsSharedWith(*tenantId) {
+		return entity.Error("wallet is not shared with the tenant", "wallet", "Unshare", entity.ApplicationLayerService, entity.ResponseCodeNotFound)
+	}
+
+	leaving := *tenantId == user.TenantID && wallet.TenantID != user.TenantID
+	if !leaving && !walletAllows(ctx, wallet, user, entity.ModuleWallet, entity.PermissionAdmin) {
+		return entity.Error("admin permission on the wallet is required", "wallet", "Unshare", entity.ApplicationLayerService, entity.ResponseCodeForbidden)
+	}
+
+	wallet.Unshare(*tenantId)
+	wallet.SetUpdate()
+
+	_, mErr = w.wallets.Update(ctx, &wallet.OwnerID, wallet)
+	return mErr
+}
+
+// getWallet returns the wallet and the user when the level of the user on the wallet grants the level
+// A wallet the user cannot see is not found
+func (w *WalletShareSvc) getWallet(ctx context.Context, userId *string, walletId *string, level entity.PermissionLevel, method string) (*entity.WalletResponse, *entity.AccountUser, *entity.ModuleError) {
+
+	if walletId == nil || utils.ValidateUUID(walletId) != nil {
+		return nil, nil, entity.Error("wallet id is invalid", "wallet", method, entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	}
+
+	user, mErr := w.getUser(ctx, userId, method)
+	if mErr != nil {
+		return nil, nil, mErr
+	}
+
+	wallet, mErr := w.wallets.GetByID(ctx, walletId)
+	if mErr != nil {
+		return nil, nil, mErr
+	}
+
+	if wallet == nil || wallet.ID == "" || wallet.PermissionOf(user.ID, user.TenantID) == "" {
+		return nil, nil, entity.Error("wallet not found", "wallet", method, entity.ApplicationLayerService, entity.ResponseCodeNotFound)
+	}
+
+	if !walletAllows(ctx, wallet, user, entity.ModuleWallet, level) {
+		return nil, nil, entity.Error(string(level)+" permission on the wallet is required", "wallet", method, entity.ApplicationLayerService, entity.ResponseCodeForbidden)
+	}
+
+	return wallet, user, nil
+}
+
+func (w *WalletShareSvc) getUser(ctx context.Context, userId *string, method string) (*entity.AccountUser, *entity.ModuleError) {
+
+	if userId == nil || *userId == "" {
+		return nil, entity.Error("user id cannot be empty", "wallet", method, entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	}
+
+	user, err := accountUserByID(ctx, w.user, userId)
+	if err != nil {
+		return nil, entity.Error(err.Error(), "wallet", method, entity.ApplicationLayerService, entity.ResponseCodeUnauthorized)
+	}
+
+	if user == nil || user.ID == "" {
+		return nil, entity.Error("user not found", "wallet", method, entity.ApplicationLayerService, entity.ResponseCodeUnauthorized)
+	}
+
+	return user, nil
+}
+
+func (w *WalletShareSvc) getInvitation(ctx context.Context, invitationId *string, method string) (*entity.WalletInvitation, *entity.ModuleError) {
+
+	if invitationId == nil || *invitationId == "" {
+		return nil, entity.Error("invitation id cannot be empty", "wallet", method, entity.ApplicationLayerService, entity.ResponseCodeBadRequest)
+	}
+
+	invitation, mErr := w.invitations.GetInvitation(ctx, *invitationId)
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	if invitation == nil {
+		return nil, entity.Error("invitation not found", "wallet", method, entity.ApplicationLayerService, entity.ResponseCodeNotFound)
+	}
+
+	return invitation, nil
+}
+
+// receivedInvitation returns the pending invitation to the email of the user
+// An invitation past its expiration is stored as expired
+func (w *WalletShareSvc) receivedInvitation(ctx context.Context, userId *string, invitationId *string, method string) (*entity.AccountUser, *entity.WalletInvitation, *entity.ModuleError) {
+
+	user, mErr := w.getUser(ctx, userId, method)
+	if mErr != nil {
+		return nil, nil, mErr
+	}
+
+	invitation, mErr := w.getInvitation(ctx, invitationId, method)
+	if mErr != nil {
+		return nil, nil, mErr
+	}
+
+	if invitation.Email != entity.NormalizeEmail(user.Email) {
+		return nil, nil, entity.Error("invitation not found", "wallet", method, entity.ApplicationLayerService, entity.ResponseCodeNotFound)
+	}
+
+	if invitation.Refresh(time.Now().UTC()) {
+		if mErr := w.invitations.SaveInvitation(ctx, invitation); mErr != nil {
+			return nil, nil, mErr
+		}
+	}
+
+	if invitation.Status != entity.InvitationPending {
+		return nil, nil, entity.Error("invitation is "+string(invitation.Status), "wallet", method, entity.ApplicationLayerService, entity.ResponseCodeConflict)
+	}
+
+	return user, invitation, nil
+}
+
+// checkLimit checks the number of tenants the plan of the wallet tenant shares a wallet with
+// The pending invitations count, except the one being accepted
+func (w *WalletShareSvc) checkLimit(ctx context.Context, wallet *entity.WalletResponse, invitations []entity.WalletInvitation, accepting string) *entity.ModuleError {
+
+	now := time.Now().UTC()
+	shared := len(wallet.SharedWithTenants)
+	for _, invitation := range invitations {
+		if invitation.ID != accepting && invitation.IsPending(now) {
+			shared++
+		}
+	}
+
+	return w.entitlement.Check(ctx, &wallet.TenantID, entity.FeatureWalletSharesMax, shared)
+}
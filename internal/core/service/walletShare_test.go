@@ -0,0 +1,260 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/internal/core/repository"
+	"github.com/Tomelin/financial-management-backend/internal/core/service"
+	"github.com/Tomelin/financial-management-backend/pkg/db"
+	"github.com/Tomelin/financial-management-backend/tests/coremocks"
+)
+
+type WalletShareServiceTestSuite struct {
+	suite.Suite
+	wallets     repository.IWalletRepo
+	invitations repository.IWalletInvitationRepo
+	svc         entity.IWalletShare
+	wallet      entity.IWallet
+	users       map[string]*entity.AccountUser
+	owner       *entity.AccountUser
+	guest       *entity.AccountUser
+	other       *entity.AccountUser
+	plan        entity.PlanResponse
+	walletID    string
+	ctx         context.Context
+}
+
+func (s *WalletShareServiceTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.plan = entity.PlanResponse{Name: "silver", Features: []entity.PlanFeatures{{Name: entity.FeatureWalletSharesMax, Count: 2}}}
+	s.users = map[string]*entity.AccountUser{}
+
+	newUser := func(email string) *entity.AccountUser {
+		user := &entity.AccountUser{ID: uuid.New().String(), TenantID: uuid.New().String(), User: entity.User{Email: email}}
+		s.users[user.ID] = user
+		return user
+	}
+	s.owner = newUser("owner@domain.com")
+	s.guest = newUser("guest@domain.com")
+	s.other = newUser("other@domain.com")
+
+	mockUser := new(coremocks.IUser)
+	mockUser.On("GetById", mock.Anything, mock.Anything).Return(func(ctx context.Context, id *string) (*entity.AccountUser, error) {
+		return s.users[*id], nil
+	})
+	mockUser.On("GetByEmail", mock.Anything, mock.Anything).Return(func(ctx context.Context, email *string) (*entity.AccountUser, error) {
+		for _, user := range s.users {
+			if user.Email == *email {
+				return user, nil
+			}
+		}
+		return nil, nil
+	})
+
+	mockTenant := new(coremocks.ITenant)
+	mockTenant.On("GetById", mock.Anything).Return(func(id *string) (*entity.TenantResponse, error) {
+		return &entity.TenantResponse{ID: *id, Plan: s.plan}, nil
+	})
+	entitlement, mErr := service.NewEntitlementSvc(mockTenant)
+	s.Require().Nil(mErr)
+
+	database := db.NewMemoryStore()
+	var err error
+	s.wallets, err = repository.NewWalletRepo(database)
+	s.Require().NoError(err)
+	s.invitations, err = repository.NewWalletInvitationRepo(database)
+	s.Require().NoError(err)
+
+	s.svc, mErr = service.NewWalletShareSvc(s.wallets, s.invitations, entitlement, mockUser)
+	s.Require().Nil(mErr)
+	s.wallet, mErr = service.NewWalletSvc(s.wallets, mockTenant, mockUser, entitlement)
+	s.Require().Nil(mErr)
+
+	wallet, mErr := entity.NewWallet(&entity.WalletResponse{Name: "Family", OwnerID: s.owner.ID, TenantID: s.owner.TenantID, Currency: "BRL"})
+	s.Require().Nil(mErr)
+	_, mErr = s.wallets.Create(s.ctx, &s.owner.ID, wallet)
+	s.Require().Nil(mErr)
+	s.walletID = wallet.ID
+}
+
+func (s *WalletShareServiceTestSuite) invite(by *entity.AccountUser, email string, level entity.PermissionLevel) (*entity.WalletInvitation, *entity.ModuleError) {
+	return s.svc.Invite(s.ctx, &by.ID, &s.walletID, &entity.WalletInvitationRequest{Email: email, Level: level})
+}
+
+func (s *WalletShareServiceTestSuite) TestInviteAndAccept() {
+	invitation, mErr := s.invite(s.owner, "Guest@Domain.com", entity.PermissionEdit)
+	s.Require().Nil(mErr)
+	s.Equal("guest@domain.com", invitation.Email)
+
+	_, mErr = s.invite(s.owner, "guest@domain.com", entity.PermissionView)
+	s.Require().NotNil(mErr)
+	s.Equal(entity.ResponseCodeConflict, mErr.Code, "the email already has a pending invitation")
+
+	received, mErr := s.svc.GetReceivedInvitations(s.ctx, &s.guest.ID)
+	s.Require().Nil(mErr)
+	s.Require().Len(received, 1)
+	s.Equal(invitation.ID, received[0].ID)
+
+	_, mErr = s.svc.Accept(s.ctx, &s.other.ID, &invitation.ID)
+	s.Require().NotNil(mErr)
+	s.Equal(entity.ResponseCodeNotFound, mErr.Code, "the invitation is of another email")
+
+	wallet, mErr := s.svc.Accept(s.ctx, &s.guest.ID, &invitation.ID)
+	s.Require().Nil(mErr)
+	s.Equal(entity.PermissionEdit, wallet.Permission)
+	s.True(wallet.IsSharedWith(s.guest.TenantID))
+
+	_, mErr = s.svc.Accept(s.ctx, &s.guest.ID, &invitation.ID)
+	s.Require().NotNil(mErr)
+	s.Equal(entity.ResponseCodeConflict, mErr.Code, "an invitation is accepted once")
+
+	page, mErr := s.wallet.Get(s.ctx, &s.guest.ID, entity.DefaultPage())
+	s.Require().Nil(mErr)
+	s.Require().Len(page.Items, 1, "the shared wallet is listed")
+	s.Equal(entity.PermissionEdit, page.Items[0].Permission)
+
+	page, mErr = s.wallet.Get(s.ctx, &s.owner.ID, entity.DefaultPage())
+	s.Require().Nil(mErr)
+	s.Require().Len(page.Items, 1)
+	s.Equal(entity.PermissionOwner, page.Items[0].Permission)
+
+	_, mErr = s.invite(s.guest, "other@domain.com", entity.PermissionView)
+	s.Require().NotNil(mErr)
+	s.Equal(entity.ResponseCodeForbidden, mErr.Code, "edit does not invite")
+
+	_, mErr = s.invite(s.owner, "guest@domain.com", entity.PermissionView)
+	s.Require().NotNil(mErr)
+	s.Equal(entity.ResponseCodeConflict, mErr.Code, "the wallet is already shared with the guest")
+}
+
+func (s *WalletShareServiceTestSuite) TestDeclineCancelAndExpire() {
+	invitation, mErr := s.invite(s.owner, "guest@domain.com", entity.PermissionView)
+	s.Require().Nil(mErr)
+	s.Require().Nil(s.svc.Decline(s.ctx, &s.guest.ID, &invitation.ID))
+
+	_, mErr = s.svc.Accept(s.ctx, &s.guest.ID, &invitation.ID)
+	s.Require().NotNil(mErr)
+	s.Equal(entity.ResponseCodeConflict, mErr.Code)
+
+	invitation, mErr = s.invite(s.owner, "guest@domain.com", entity.PermissionView)
+	s.Require().Nil(mErr, "a declined invitation can be sent again")
+	s.Require().Nil(s.svc.CancelInvitation(s.ctx, &s.owner.ID, &s.walletID, &invitation.ID))
+	received, mErr := s.svc.GetReceivedInvitations(s.ctx, &s.guest.ID)
+	s.Require().Nil(mErr)
+	s.Empty(received)
+
+	invitation, mErr = s.invite(s.owner, "guest@domain.com", entity.PermissionView)
+	s.Require().Nil(mErr)
+	invitation.ExpiresAt = time.Now().UTC().Add(-time.Minute)
+	s.Require().Nil(s.invitations.SaveInvitation(s.ctx, invitation))
+
+	_, mErr = s.svc.Accept(s.ctx, &s.guest.ID, &invitation.ID)
+	s.Require().NotNil(mErr)
+	s.Equal(entity.ResponseCodeConflict, mErr.Code)
+	stored, mErr := s.invitations.GetInvitation(s.ctx, invitation.ID)
+	s.Require().Nil(mErr)
+	s.Equal(entity.InvitationExpired, stored.Status)
+}
+
+func (s *WalletShareServiceTestSuite) TestPlanLimit() {
+	s.plan = entity.PlanResponse{Name: "bronze", Features: []entity.PlanFeatures{{Name: entity.FeatureWalletsMax, Count: 1}}}
+
+	_, mErr := s.invite(s.owner, "guest@domain.com", entity.PermissionView)
+	s.Require().Nil(mErr, "a plan without the feature has the default limit")
+	_, mErr = s.invite(s.owner, "other@domain.com", entity.PermissionView)
+	s.Require().NotNil(mErr, "the pending invitation counts in the limit")
+	s.Equal(entity.ResponseCodeBadRequest, mErr.Code)
+	s.Equal("limit reached: wallet.shares.max usage 1 of 1", mErr.Err)
+
+	s.plan.Features = append(s.plan.Features, entity.PlanFeatures{Name: entity.FeatureWalletSharesMax, Unlimited: true})
+	_, mErr = s.invite(s.owner, "other@domain.com", entity.PermissionView)
+	s.Nil(mErr, "the feature is unlimited")
+}
+
+func (s *WalletShareServiceTestSuite) TestWalletLimit() {
+	create := func(name string) *entity.ModuleError {
+		wallet, mErr := entity.NewWallet(&entity.WalletResponse{Name: name, OwnerID: s.owner.ID, TenantID: s.owner.TenantID, Currency: "BRL"})
+		s.Require().Nil(mErr)
+		_, mErr = s.wallet.Create(s.ctx, &s.owner.ID, wallet)
+		return mErr
+	}
+
+	mErr := create("Travel")
+	s.Require().NotNil(mErr, "the default allows one wallet")
+	s.Equal("limit reached: wallets.max usage 1 of 1", mErr.Err)
+
+	s.plan.Features = append(s.plan.Features, entity.PlanFeatures{Name: entity.FeatureWalletsMax, Count: 2})
+	s.Require().Nil(create("Travel"))
+	mErr = create("Family")
+	s.Require().NotNil(mErr)
+	s.Equal(entity.ResponseCodeConflict, mErr.Code, "the name is checked before the limit")
+	mErr = create("House")
+	s.Require().NotNil(mErr)
+	s.Equal("limit reached: wallets.max usage 2 of 2", mErr.Err)
+}
+
+func (s *WalletShareServiceTestSuite) TestWalletLimit_Tenant() {
+	member := &entity.AccountUser{ID: uuid.New().String(), TenantID: s.owner.TenantID, User: entity.User{Email: "member@domain.com"}}
+	s.users[member.ID] = member
+
+	wallet, mErr := entity.NewWallet(&entity.WalletResponse{Name: "Travel", OwnerID: member.ID, TenantID: member.TenantID, Currency: "BRL"})
+	s.Require().Nil(mErr)
+	_, mErr = s.wallet.Create(s.ctx, &member.ID, wallet)
+	s.Require().NotNil(mErr, "the wallet of the owner counts for the members of the tenant")
+	s.Equal("limit reached: wallets.max usage 1 of 1", mErr.Err)
+}
+
+func (s *WalletShareServiceTestSuite) TestCreate_OwnerOfTheUser() {
+	wallet, mErr := entity.NewWallet(&entity.WalletResponse{Name: "Travel", OwnerID: s.owner.ID, TenantID: s.owner.TenantID, Currency: "BRL"})
+	s.Require().Nil(mErr)
+
+	created, mErr := s.wallet.Create(s.ctx, &s.guest.ID, wallet)
+	s.Require().Nil(mErr)
+	s.Equal(s.guest.ID, created.OwnerID, "the owner of the request body is ignored")
+	s.Equal(s.guest.TenantID, created.TenantID, "the wallet is counted in the tenant of the user")
+}
+
+func (s *WalletShareServiceTestSuite) TestSetPermissionAndUnshare() {
+	invitation, mErr := s.invite(s.owner, "guest@domain.com", entity.PermissionAdmin)
+	s.Require().Nil(mErr)
+	_, mErr = s.svc.Accept(s.ctx, &s.guest.ID, &invitation.ID)
+	s.Require().Nil(mErr)
+
+	invitation, mErr = s.invite(s.guest, "other@domain.com", entity.PermissionView)
+	s.Require().Nil(mErr, "an admin invites")
+	_, mErr = s.svc.Accept(s.ctx, &s.other.ID, &invitation.ID)
+	s.Require().Nil(mErr)
+
+	_, mErr = s.svc.SetPermission(s.ctx, &s.other.ID, &s.walletID, &s.other.TenantID, entity.PermissionAdmin)
+	s.Require().NotNil(mErr)
+	s.Equal(entity.ResponseCodeForbidden, mErr.Code, "view does not change the levels")
+
+	wallet, mErr := s.svc.SetPermission(s.ctx, &s.owner.ID, &s.walletID, &s.other.TenantID, entity.PermissionEdit)
+	s.Require().Nil(mErr)
+	s.Equal(entity.PermissionEdit, wallet.PermissionOf(s.other.ID, s.other.TenantID))
+
+	_, mErr = s.svc.SetPermission(s.ctx, &s.owner.ID, &s.walletID, &s.other.TenantID, entity.PermissionOwner)
+	s.Require().NotNil(mErr)
+	s.Equal(entity.ResponseCodeBadRequest, mErr.Code)
+
+	s.Require().Nil(s.svc.Unshare(s.ctx, &s.other.ID, &s.walletID, &s.other.TenantID), "a tenant leaves the wallet")
+	mErr = s.svc.Unshare(s.ctx, &s.other.ID, &s.walletID, &s.guest.TenantID)
+	s.Require().NotNil(mErr)
+	s.Equal(entity.ResponseCodeNotFound, mErr.Code, "the wallet is not visible after leaving")
+
+	s.Require().Nil(s.svc.Unshare(s.ctx, &s.owner.ID, &s.walletID, &s.guest.TenantID))
+	page, mErr := s.wallet.Get(s.ctx, &s.guest.ID, entity.DefaultPage())
+	s.Require().Nil(mErr)
+	s.Empty(page.Items)
+}
+
+func TestWalletShareServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(WalletShareServiceTestSuite))
+}
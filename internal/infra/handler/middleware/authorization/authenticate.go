@@ -0,0 +1,145 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+)
+
+// Authenticate returns the middleware that authenticates the request by the JWT of the Authorization header, or of the Authorization cookie
+//
+// The token is verified by the authorization service (signature, expiry and revocation) and the user is loaded once.
+// A personal access token, with the pat_ prefix, is verified by its service instead and its principal is limited to its scopes.
+// The principal is stored in the Gin context and in the context.Context of the request.
+// The owner of the tenant gets the owner levels of the policy, the other members the default ones.
+// Requests without a valid token are aborted with 401, the users of a suspended tenant with 403 and of a tenant that cannot be loaded with 503.
+func Authenticate(auth entity.IAuthorization, accessTokens entity.IPersonalAccessToken, tenants entity.ITenant) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := RequestToken(c)
+		if token == "" {
+			abortUnauthorized(c, "token authorization is required")
+			return
+		}
+
+		if entity.IsPersonalAccessToken(token) {
+			authenticateAccessToken(c, accessTokens, tenants, token)
+			return
+		}
+
+		claims, err := auth.ParseTokenJWT(c.Request.Context(), token)
+		if err != nil {
+			abortUnauthorized(c, err.Error())
+			return
+		}
+
+		user, err := auth.ValidateTokenJWT(c.Request.Context(), token)
+		if errors.Is(err, entity.ErrUnauthorized) {
+			abortUnauthorized(c, err.Error())
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": entity.Error(err.Error(), "token", "Authenticate", entity.ApplicationLayerMiddleware, entity.ResponseCodeInternalServer)})
+			c.Abort()
+			return
+		}
+
+		principal, err := entity.NewPrincipal(user, claims)
+		if err != nil {
+			abortUnauthorized(c, err.Error())
+			return
+		}
+
+		if suspended(c, tenants, principal) {
+			return
+		}
+
+		SetPrincipal(c, principal)
+		c.Next()
+	}
+}
+
+func authenticateAccessToken(c *gin.Context, accessTokens entity.IPersonalAccessToken, tenants entity.ITenant, token string) {
+	if accessTokens == nil {
+		abortUnauthorized(c, "personal access tokens are not accepted")
+		return
+	}
+
+	principal, err := accessTokens.Authenticate(c.Request.Context(), token)
+	if errors.Is(err, entity.ErrUnauthorized) {
+		abortUnauthorized(c, err.Error())
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": entity.Error(err.Error(), "token", "Authenticate", entity.ApplicationLayerMiddleware, entity.ResponseCodeInternalServer)})
+		c.Abort()
+		return
+	}
+
+	if suspended(c, tenants, principal) {
+		return
+	}
+
+	SetPrincipal(c, principal)
+	c.Next()
+}
+
+// suspended loads the tenant of the principal, marks the owner of the tenant and aborts the request when the tenant is suspended
+// The request is aborted as well when the tenant cannot be loaded, with 503, the system users are not blocked
+func suspended(c *gin.Context, tenants entity.ITenant, principal *entity.Principal) bool {
+	if tenants == nil || principal.TenantID == "" {
+		return false
+	}
+
+	system := principal.Can(entity.ModuleSystem, entity.PermissionSystemView)
+	tenant, err := tenants.GetById(&principal.TenantID)
+	if err != nil || tenant == nil {
+		if system {
+			return false
+		}
+
+		message := "tenant not found"
+		if err != nil {
+			message = err.Error()
+		}
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": entity.Error(message, "token", "Authenticate", entity.ApplicationLayerMiddleware, entity.ResponseCodeServiceUnavailable)})
+		c.Abort()
+		return true
+	}
+	principal.TenantOwner = tenant.OwnerID == principal.User.ID
+
+	if !tenant.Suspended || system {
+		return false
+	}
+
+	c.JSON(http.StatusForbidden, gin.H{"error": entity.Error(entity.ErrTenantSuspended.Error(), "token", "Authenticate", entity.ApplicationLayerMiddleware, entity.ResponseCodeForbidden)})
+	c.Abort()
+	return true
+}
+
+// SetPrincipal stores the principal in the Gin context and in the context.Context of the request
+func SetPrincipal(c *gin.Context, principal *entity.Principal) {
+	c.Set(entity.PrincipalKey, principal)
+	c.Request = c.Request.WithContext(entity.WithPrincipal(c.Request.Context(), principal))
+}
+
+// GetPrincipal returns the principal stored by the Authenticate middleware
+func GetPrincipal(c *gin.Context) (*entity.Principal, bool) {
+	if value, ok := c.Get(entity.PrincipalKey); ok {
+		if principal, ok := value.(*entity.Principal); ok && principal != nil {
+			return principal, true
+		}
+	}
+
+	if c.Request == nil {
+		return nil, false
+	}
+	return entity.PrincipalFromContext(c.Request.Context())
+}
+
+func abortUnauthorized(c *gin.Context, message string) {
+	c.JSON(http.StatusUnauthorized, gin.H{"error": entity.Error(message, "token", "Authenticate", entity.ApplicationLayerMiddleware, entity.ResponseCodeUnauthorized)})
+	c.Abort()
+}
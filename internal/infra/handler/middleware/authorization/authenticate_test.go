@@ -0,0 +1,129 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	middleware "github.com/Tomelin/financial-management-backend/internal/infra/handler/middleware/authorization"
+	"github.com/Tomelin/financial-management-backend/tests/coremocks"
+)
+
+// accessTokens authenticates every personal access token as the user, with the wallet scope
+type accessTokens struct {
+	entity.IPersonalAccessToken
+	user *entity.AccountUser
+}
+
+func (a *accessTokens) Authenticate(ctx context.Context, token string) (*entity.Principal, error) {
+	principal, err := entity.NewPrincipal(a.user, nil)
+	if err != nil {
+		return nil, err
+	}
+	principal.Scopes = []entity.TokenScope{{Module: entity.ModuleWallet, Level: entity.PermissionView}}
+	return principal, nil
+}
+
+type AuthenticateTestSuite struct {
+	suite.Suite
+	user    *entity.AccountUser
+	tenant  *entity.TenantResponse
+	auth    *coremocks.IAuthorization
+	tenants *coremocks.ITenant
+	router  *gin.Engine
+}
+
+func (s *AuthenticateTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+
+	s.user = &entity.AccountUser{ID: uuid.New().String(), TenantID: uuid.New().String(), User: entity.User{Email: "user@domain.com"}}
+	s.tenant = &entity.TenantResponse{ID: s.user.TenantID, OwnerID: s.user.ID}
+
+	s.auth = new(coremocks.IAuthorization)
+	s.auth.On("ParseTokenJWT", mock.Anything, "token").Return(&entity.AuthorizationClaims{}, nil)
+	s.auth.On("ValidateTokenJWT", mock.Anything, "token").Return(s.user, nil)
+	s.tenants = new(coremocks.ITenant)
+	s.tenants.On("GetById", &s.user.TenantID).Return(s.tenant, nil)
+
+	s.router = gin.New()
+	s.router.Use(middleware.Authenticate(s.auth, &accessTokens{user: s.user}, s.tenants))
+	s.router.GET("/wallet", middleware.Require(entity.ModuleWallet, entity.PermissionView), func(c *gin.Context) { c.Status(http.StatusOK) })
+	s.router.PUT("/wallet", middleware.Require(entity.ModuleWallet, entity.PermissionEdit), func(c *gin.Context) { c.Status(http.StatusOK) })
+	s.router.GET("/auth/sessions", middleware.RequireLogin(), func(c *gin.Context) { c.Status(http.StatusOK) })
+}
+
+func (s *AuthenticateTestSuite) request(method string) int {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(method, "/wallet", nil)
+	r.Header.Set("Authorization", "Bearer token")
+	s.router.ServeHTTP(w, r)
+	return w.Code
+}
+
+func (s *AuthenticateTestSuite) TestOwner() {
+	s.Equal(http.StatusOK, s.request(http.MethodGet))
+	s.Equal(http.StatusOK, s.request(http.MethodPut), "the owner edits the resources of the tenant")
+}
+
+func (s *AuthenticateTestSuite) TestMember() {
+	s.tenant.OwnerID = uuid.New().String()
+
+	s.Equal(http.StatusOK, s.request(http.MethodGet))
+	s.Equal(http.StatusForbidden, s.request(http.MethodPut), "a member views the resources of the tenant")
+}
+
+func (s *AuthenticateTestSuite) TestViewRole() {
+	s.user.Roles = []entity.AccountRoles{{Key: string(entity.ModuleWallet), Value: string(entity.PermissionView)}}
+
+	s.Equal(http.StatusOK, s.request(http.MethodGet))
+	s.Equal(http.StatusForbidden, s.request(http.MethodPut), "the view role restricts the owner")
+}
+
+func (s *AuthenticateTestSuite) TestSuspended() {
+	s.tenant.Suspended = true
+	s.Equal(http.StatusForbidden, s.request(http.MethodGet))
+
+	s.user.Roles = []entity.AccountRoles{{Key: string(entity.ModuleSystem), Value: string(entity.PermissionSystemView)}}
+	s.Equal(http.StatusOK, s.request(http.MethodGet), "the system users are not blocked")
+}
+
+func (s *AuthenticateTestSuite) TestTenantLookupFails() {
+	s.tenants.ExpectedCalls = nil
+	s.tenants.On("GetById", &s.user.TenantID).Return(nil, errors.New("unavailable"))
+
+	s.Equal(http.StatusServiceUnavailable, s.request(http.MethodGet), "the suspension is not skipped")
+}
+
+func (s *AuthenticateTestSuite) TestCookie() {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/wallet", nil)
+	r.AddCookie(&http.Cookie{Name: "Authorization", Value: "token"})
+	s.router.ServeHTTP(w, r)
+
+	s.Equal(http.StatusOK, w.Code, "the browser sends the token in the cookie")
+}
+
+func (s *AuthenticateTestSuite) TestRequireLogin() {
+	request := func(token string) int {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/auth/sessions", nil)
+		r.Header.Set("Authorization", "Bearer "+token)
+		s.router.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	s.Equal(http.StatusOK, request("token"))
+	s.Equal(http.StatusForbidden, request(entity.PersonalAccessTokenPrefix+"token"), "a personal access token does not manage the sessions")
+}
+
+func TestAuthenticateTestSuite(t *testing.T) {
+	suite.Run(t, new(AuthenticateTestSuite))
+}
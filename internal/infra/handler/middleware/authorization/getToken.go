@@ -3,40 +3,16 @@ package middleware
 import (
 	"errors"
 
-	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
 
 	"github.com/Tomelin/financial-management-backend/internal/core/entity"
 )
 
-// OpenTokenJWT
-// Parse token and return claims.
-func OpenTokenJWT(token *string) (*entity.AuthorizationClaims, error) {
-
-	if token == nil {
-		return nil, errors.New("invalid token")
-	}
-
-	secretKey := []byte(entity.SecretTokenJWT)
-	extractToken, err := jwt.ParseWithClaims(*token, &entity.AuthorizationClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return secretKey, nil
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if claims, ok := extractToken.Claims.(*entity.AuthorizationClaims); ok && extractToken.Valid {
-		return claims, nil
-	}
-
-	return nil, errors.New("invalid token")
-}
-
 // GetTokenJWT
-// Receive a token from header and return the token as string.
+// Receive a token from header and return the token as string, without the Bearer scheme.
 func GetTokenJWT(c *gin.Context) (*string, error) {
 
-	token := c.GetHeader("Authorization")
+	token := entity.BearerToken(c.GetHeader("Authorization"))
 	if token == "" {
 		return nil, errors.New("token authorization is required")
 	}
@@ -44,38 +20,28 @@ func GetTokenJWT(c *gin.Context) (*string, error) {
 	return &token, nil
 }
 
-// GetClaimsFromToken
-// Receive a token and return the claims from the token.
-func GetClaimsFromToken(c *gin.Context) (*entity.AuthorizationClaims, error) {
-
-	token := c.GetHeader("Authorization")
-	if token == "" {
-		return nil, errors.New("token authorization is required")
-	}
-
-	claims, err := OpenTokenJWT(&token)
-	if err != nil {
-		return nil, err
+// RequestToken
+// Return the token of the Authorization header, or of the Authorization cookie of the browser.
+func RequestToken(c *gin.Context) string {
+	if token := entity.BearerToken(c.GetHeader("Authorization")); token != "" {
+		return token
 	}
-	return claims, nil
+	token, _ := c.Cookie("Authorization")
+	return token
 }
 
 // GetEmailFromToken
 //
-// Receive a token and return the email from the token.
+// Return the email of the user authenticated by the Authenticate middleware.
 //
 // Return a email of user with success or error.
 func GetEmailFromToken(c *gin.Context) (*string, error) {
 
-	token, err := GetTokenJWT(c)
-	if err != nil {
-		return nil, err
-	}
-
-	claims, err := OpenTokenJWT(token)
-	if err != nil {
-		return nil, err
+	principal, ok := GetPrincipal(c)
+	if !ok {
+		return nil, errors.New("token authorization is required")
 	}
 
-	return &claims.Email, nil
+	email := principal.Email()
+	return &email, nil
 }
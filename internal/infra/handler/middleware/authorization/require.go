@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+)
+
+// Require returns the middleware that allows the request when the principal has the level on the module
+//
+// It runs after Authenticate, requests without principal are aborted with 401 and the denied ones with 403.
+func Require(module entity.Module, level entity.PermissionLevel) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := GetPrincipal(c)
+		if !ok {
+			abortUnauthorized(c, "token authorization is required")
+			return
+		}
+
+		if !principal.Can(module, level) {
+			c.JSON(http.StatusForbidden, gin.H{"error": entity.Error(entity.ErrForbidden.Error(), string(module), "Require", entity.ApplicationLayerMiddleware, entity.ResponseCodeForbidden)})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireLogin returns the middleware that allows only the principal of a login, the JWT
+//
+// A personal access token does not manage the tokens, the sessions or the identities of its user, it is aborted with 403.
+func RequireLogin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := GetPrincipal(c)
+		if !ok {
+			abortUnauthorized(c, "token authorization is required")
+			return
+		}
+
+		if principal.Scopes != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": entity.Error(entity.ErrForbidden.Error(), "auth", "RequireLogin", entity.ApplicationLayerMiddleware, entity.ResponseCodeForbidden)})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
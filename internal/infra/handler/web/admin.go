@@ -0,0 +1,222 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/internal/core/service"
+)
+
+type AdminHandlerHttpInterface interface {
+	GetTenants(c *gin.Context)
+	SuspendTenant(c *gin.Context)
+	UnsuspendTenant(c *gin.Context)
+	GetAudit(c *gin.Context)
+}
+
+// AdminHandlerHttp is the API of the system admins, every request to it is written to the audit log
+type AdminHandlerHttp struct {
+	Service entity.IAdmin
+	Audit   entity.IAudit
+	plans   *PlanHandlerHttp
+	tenants *TenantHandlerHttp
+	users   *UserHandlerHttp
+}
+
+// NewAdminHandlerHttp registers the /admin routes, system-view reads them and system-admin changes the plans and the tenants
+func NewAdminHandlerHttp(svc entity.IAdmin, audit entity.IAudit, plan entity.IPlan, tenant service.ITenantService, user entity.IUser, routerGroup *gin.RouterGroup, middleware ...func(c *gin.Context)) AdminHandlerHttpInterface {
+
+	lab := &AdminHandlerHttp{
+		Service: svc,
+		Audit:   audit,
+		plans:   &PlanHandlerHttp{Service: plan},
+		tenants: &TenantHandlerHttp{Service: tenant},
+		users:   &UserHandlerHttp{Service: user},
+	}
+
+	lab.handlers(routerGroup, middleware...)
+
+	return lab
+}
+
+func (c *AdminHandlerHttp) handlers(routerGroup *gin.RouterGroup, middleware ...func(c *gin.Context)) {
+	middlewareList := make([]gin.HandlerFunc, len(middleware))
+	for i, mw := range middleware {
+		middlewareList[i] = mw
+	}
+
+	admin := routerGroup.Group("/admin", middlewareList...)
+
+	// the audit runs before the permission, the denied requests are written as well
+	view := func(action string, handler gin.HandlerFunc) []gin.HandlerFunc {
+		return []gin.HandlerFunc{c.audit(action), require(entity.ModuleSystem, entity.PermissionSystemView), handler}
+	}
+	change := func(action string, handler gin.HandlerFunc) []gin.HandlerFunc {
+		return []gin.HandlerFunc{c.audit(action), require(entity.ModuleSystem, entity.PermissionSystemAdmin), handler}
+	}
+
+	admin.POST("/plan", change(entity.AuditPlanCreate, c.plans.Create)...)
+	admin.PUT("/plan/:id", change(entity.AuditPlanUpdate, c.plans.Update)...)
+	admin.DELETE("/plan/:id", change(entity.AuditPlanDelete, c.plans.Delete)...)
+
+	admin.GET("/tenant", view(entity.AuditTenantList, c.GetTenants)...)
+	admin.GET("/tenant/search", view(entity.AuditTenantSearch, c.tenants.GetByFilterMany)...)
+	admin.GET("/tenant/filter", view(entity.AuditTenantSearch, c.tenants.GetByFilterOne)...)
+	admin.GET("/tenant/:id", view(entity.AuditTenantView, c.tenants.GetById)...)
+	admin.POST("/tenant", change(entity.AuditTenantCreate, c.tenants.Create)...)
+	admin.PUT("/tenant/:id", change(entity.AuditTenantUpdate, c.tenants.Update)...)
+	admin.DELETE("/tenant/:id", change(entity.AuditTenantDelete, c.tenants.Delete)...)
+	admin.POST("/tenant/:id/suspend", change(entity.AuditTenantSuspend, c.SuspendTenant)...)
+	admin.POST("/tenant/:id/unsuspend", change(entity.AuditTenantUnsuspend, c.UnsuspendTenant)...)
+
+	admin.GET("/user", view(entity.AuditUserList, c.users.Get)...)
+	admin.GET("/user/search", view(entity.AuditUserSearch, c.users.GetByFilterMany)...)
+	admin.GET("/user/filter", view(entity.AuditUserSearch, c.users.GetByFilterOne)...)
+	admin.GET("/user/:id", view(entity.AuditUserView, c.users.GetById)...)
+
+	admin.GET("/audit", view(entity.AuditAuditList, c.GetAudit)...)
+}
+
+// audit writes the request to the audit log once it is answered, with its status
+func (c *AdminHandlerHttp) audit(action string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Next()
+
+		log := &entity.AuditLog{
+			Action:     action,
+			ResourceID: ctx.Param("id"),
+			Method:     ctx.Request.Method,
+			Path:       ctx.Request.URL.Path,
+			Status:     ctx.Writer.Status(),
+			IP:         ctx.ClientIP(),
+		}
+		if principal, mErr := requestPrincipal(ctx, "admin", "audit"); mErr == nil {
+			log.ActorID = principal.User.ID
+			log.ActorEmail = principal.Email()
+		}
+
+		// the response is sent, a failed record is only logged by the request logger
+		if mErr := c.Audit.Record(ctx.Request.Context(), log); mErr != nil {
+			_ = ctx.Error(errors.New(mErr.Err))
+		}
+	}
+}
+
+// GetTenants    godoc
+// @Summary     page the tenants with their users, wallets and the limits of their plan
+// @Tags        Admin
+// @Produce     json
+// @Param       limit query int false "page size" default(50)
+// @Param       order_by query string false "field of the order"
+// @Param       direction query string false "asc or desc" default(asc)
+// @Param       cursor query string false "next_cursor of the previous page"
+// @Success     200 {object} entity.Page[entity.TenantUsage]
+// @Failure     400 {object} entity.ModuleError
+// @Failure     403 {object} entity.ModuleError
+// @Router      /admin/tenant [get]
+func (obj *AdminHandlerHttp) GetTenants(c *gin.Context) {
+
+	page, err := pageRequest(c, entity.TenantFields)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": entity.Error(err.Error(), "admin", "GetTenants", entity.ApplicationLayerHandler, entity.ResponseCodeBadRequest)})
+		c.Abort()
+		return
+	}
+
+	response, mErr := obj.Service.GetTenants(c.Request.Context(), page)
+	if mErr != nil {
+		c.JSON(int(mErr.Code), gin.H{"error": mErr})
+		c.Abort()
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// SuspendTenant    godoc
+// @Summary     suspend the tenant, its users cannot use the API until it is unsuspended
+// @Tags        Admin
+// @Accept      json
+// @Produce     json
+// @Param       id path string true "tenant id"
+// @Param       reason body entity.SuspendRequest true "reason"
+// @Success     200 {object} entity.TenantResponse
+// @Failure     400 {object} entity.ModuleError
+// @Failure     403 {object} entity.ModuleError
+// @Failure     404 {object} entity.ModuleError
+// @Router      /admin/tenant/{id}/suspend [post]
+func (obj *AdminHandlerHttp) SuspendTenant(c *gin.Context) {
+
+	var request entity.SuspendRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": entity.Error(err.Error(), "admin", "SuspendTenant", entity.ApplicationLayerHandler, entity.ResponseCodeBadRequest)})
+		c.Abort()
+		return
+	}
+
+	tenantId := c.Param("id")
+	tenant, mErr := obj.Service.SuspendTenant(c.Request.Context(), &tenantId, &request)
+	if mErr != nil {
+		c.JSON(int(mErr.Code), gin.H{"error": mErr})
+		c.Abort()
+		return
+	}
+
+	c.JSON(http.StatusOK, tenant)
+}
+
+// UnsuspendTenant    godoc
+// @Summary     unsuspend the tenant
+// @Tags        Admin
+// @Produce     json
+// @Param       id path string true "tenant id"
+// @Success     200 {object} entity.TenantResponse
+// @Failure     403 {object} entity.ModuleError
+// @Failure     404 {object} entity.ModuleError
+// @Router      /admin/tenant/{id}/unsuspend [post]
+func (obj *AdminHandlerHttp) UnsuspendTenant(c *gin.Context) {
+
+	tenantId := c.Param("id")
+	tenant, mErr := obj.Service.UnsuspendTenant(c.Request.Context(), &tenantId)
+	if mErr != nil {
+		c.JSON(int(mErr.Code), gin.H{"error": mErr})
+		c.Abort()
+		return
+	}
+
+	c.JSON(http.StatusOK, tenant)
+}
+
+// GetAudit    godoc
+// @Summary     page the audit log of the system admins
+// @Tags        Admin
+// @Produce     json
+// @Param       limit query int false "page size" default(50)
+// @Param       order_by query string false "field of the order"
+// @Param       direction query string false "asc or desc" default(asc)
+// @Param       cursor query string false "next_cursor of the previous page"
+// @Success     200 {object} entity.Page[entity.AuditLog]
+// @Failure     400 {object} entity.ModuleError
+// @Failure     403 {object} entity.ModuleError
+// @Router      /admin/audit [get]
+func (obj *AdminHandlerHttp) GetAudit(c *gin.Context) {
+
+	page, err := pageRequest(c, entity.AuditFields)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": entity.Error(err.Error(), "admin", "GetAudit", entity.ApplicationLayerHandler, entity.ResponseCodeBadRequest)})
+		c.Abort()
+		return
+	}
+
+	response, mErr := obj.Audit.Get(c.Request.Context(), page)
+	if mErr != nil {
+		c.JSON(int(mErr.Code), gin.H{"error": mErr})
+		c.Abort()
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
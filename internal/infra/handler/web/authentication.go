@@ -2,16 +2,16 @@ package web
 
 import (
 	"context"
+	"errors"
 	"net/http"
-
-	"encoding/json"
+	"time"
 
 	"github.com/gin-gonic/contrib/sessions"
 	"github.com/gin-gonic/gin"
+	"github.com/markbates/goth"
 	"github.com/markbates/goth/gothic"
 
 	"github.com/Tomelin/financial-management-backend/internal/core/entity"
-	middleware "github.com/Tomelin/financial-management-backend/internal/infra/handler/middleware/authorization"
 	"github.com/Tomelin/financial-management-backend/pkg/authProvider"
 	"github.com/Tomelin/financial-management-backend/pkg/logger"
 )
@@ -22,19 +22,47 @@ type IAuthHandlerHttp interface {
 	Login(c *gin.Context)
 	IsLoggedIn(c *gin.Context)
 	ValidateToken(c *gin.Context)
+	Refresh(c *gin.Context)
+	LogoutAll(c *gin.Context)
+	Providers(c *gin.Context)
+	Link(c *gin.Context)
+	Unlink(c *gin.Context)
+	Identities(c *gin.Context)
+}
+
+const (
+	// refreshTokenCookie is the cookie of the refresh token of the browser login
+	refreshTokenCookie = "refresh_token"
+
+	// redirectToSession keeps the allowed redirect_to of the login in the session until the callback
+	redirectToSession = "redirect_to"
+
+	// linkUserSession and linkExpiresSession keep the user of a Link in the session until the callback
+	linkUserSession    = "link_user_id"
+	linkExpiresSession = "link_expires_at"
+	// linkTTL is how long the user has to finish the login of the provider to link
+	linkTTL = 10 * time.Minute
+)
+
+type refreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
 }
 
 type AuthHandlerHttp struct {
 	User         entity.IUser
+	Identity     entity.IIdentity
+	TwoFactor    entity.ITwoFactor
 	AuthProvider authProvider.IAuthProvider
 	Log          logger.Logger
 	tokenJWT     entity.IAuthorization
 }
 
-func NewAuthenticationHandlerHttp(ap authProvider.IAuthProvider, l logger.Logger, tokenJWT entity.IAuthorization, user entity.IUser, routerGroup *gin.RouterGroup, middleware ...func(c *gin.Context)) IAuthHandlerHttp {
+func NewAuthenticationHandlerHttp(ap authProvider.IAuthProvider, l logger.Logger, tokenJWT entity.IAuthorization, user entity.IUser, identity entity.IIdentity, twoFactor entity.ITwoFactor, routerGroup *gin.RouterGroup, middleware ...func(c *gin.Context)) IAuthHandlerHttp {
 
 	lab := &AuthHandlerHttp{
 		User:         user,
+		Identity:     identity,
+		TwoFactor:    twoFactor,
 		AuthProvider: ap,
 		tokenJWT:     tokenJWT,
 		Log:          l,
@@ -51,10 +79,19 @@ func (c *AuthHandlerHttp) handlers(routerGroup *gin.RouterGroup, middleware ...f
 		middlewareList[i] = mw
 	}
 
-	routerGroup.GET("/v1/auth/:provider/callback", append(middlewareList, c.Callback)...)
-	routerGroup.GET("/v1/auth/:provider/logout", append(middlewareList, c.Logout)...)
-	routerGroup.GET("/v1/auth/:provider", append(middlewareList, c.Login)...)
-	routerGroup.GET("/v1/auth/:provider/is_logged_in", append(middlewareList, c.IsLoggedIn)...)
+	// the login routes are public, the middleware authenticates the routes of the user of the request
+	loginList := append(middlewareList, requireLogin())
+
+	routerGroup.GET("/v1/auth/providers", c.Providers)
+	routerGroup.GET("/v1/auth/identities", append(loginList, c.Identities)...)
+	routerGroup.DELETE("/v1/auth/identities/:provider", append(loginList, c.Unlink)...)
+	routerGroup.GET("/v1/auth/:provider/link", append(loginList, c.provider, c.Link)...)
+	routerGroup.GET("/v1/auth/:provider/callback", c.provider, c.Callback)
+	routerGroup.GET("/v1/auth/:provider/logout", c.provider, c.Logout)
+	routerGroup.GET("/v1/auth/:provider", c.provider, c.Login)
+	routerGroup.GET("/v1/auth/:provider/is_logged_in", c.provider, c.IsLoggedIn)
+	routerGroup.POST("/v1/auth/refresh", c.Refresh)
+	routerGroup.POST("/v1/auth/logout/all", append(loginList, c.LogoutAll)...)
 }
 
 func (obj *AuthHandlerHttp) Callback(c *gin.Context) {
@@ -63,6 +100,9 @@ func (obj *AuthHandlerHttp) Callback(c *gin.Context) {
 	userFromProvider, err := obj.AuthProvider.Callback(c.Writer, c.Request)
 	if err != nil {
 		obj.AuthProvider.Logout(c, c.Writer, c.Request)
+		if obj.redirectError(c, err.Error()) {
+			return
+		}
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"error": obj.Log.Error(&logger.Message{Body: err.Error(), Code: logger.ResponseCodeUnauthorized}).Error(),
 		})
@@ -70,108 +110,95 @@ func (obj *AuthHandlerHttp) Callback(c *gin.Context) {
 	}
 	if userFromProvider == nil {
 		obj.AuthProvider.Logout(c, c.Writer, c.Request)
+		if obj.redirectError(c, "user not found") {
+			return
+		}
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"error": obj.Log.Error(&logger.Message{Body: "user not found", Code: logger.ResponseCodeUnauthorized}).Error(),
 		})
 		return
 	}
 
-	b, err := json.Marshal(userFromProvider)
-	if err != nil {
-		obj.AuthProvider.Logout(c, c.Writer, c.Request)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": obj.Log.Error(&logger.Message{Body: err.Error(), Code: logger.ResponseCodeInternalServer}).Error(),
-		})
-		return
-	}
-
-	var user entity.User
-	err = json.Unmarshal(b, &user)
-	if err != nil {
-		obj.AuthProvider.Logout(c, c.Writer, c.Request)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": obj.Log.Error(&logger.Message{Body: err.Error(), Code: logger.ResponseCodeInternalServer}).Error(),
-		})
+	ctx := c.Request.Context()
+	identity := externalIdentity(c.Param("provider"), userFromProvider)
+	redirects := obj.AuthProvider.Redirects()
+	redirectTo := redirects.Redirect(redirectingTo(c), redirects.SuccessURL)
+
+	if userID := linkingUser(c); userID != "" {
+		if _, err := obj.Identity.Link(ctx, userID, identity); err != nil {
+			if obj.redirectError(c, err.Error()) {
+				return
+			}
+			identityError(c, "Callback", err)
+			return
+		}
+		http.Redirect(c.Writer, c.Request, redirectTo, http.StatusTemporaryRedirect)
 		return
 	}
 
-	response, err := entity.NewUser(&user)
+	user, created, err := obj.Identity.Resolve(ctx, identity)
 	if err != nil {
 		obj.AuthProvider.Logout(c, c.Writer, c.Request)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": obj.Log.Error(&logger.Message{Body: err.Error(), Code: logger.ResponseCodeInternalServer}).Error(),
-		})
+		if obj.redirectError(c, err.Error()) {
+			return
+		}
+		identityError(c, "Callback", err)
 		return
 	}
 
-	ctx := c.Request.Context()
-	getuser, err := obj.User.GetByEmail(ctx, &user.Email)
+	withSessionClient(c, c.Param("provider"))
+	result, err := obj.TwoFactor.Login(c.Request.Context(), user)
 	if err != nil {
-		if err.Error() != "not found" {
-			obj.AuthProvider.Logout(c, c.Writer, c.Request)
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": err.Error(),
-			})
+		if obj.redirectError(c, err.Error()) {
 			return
 		}
-	}
-
-	if getuser != nil {
-		getuser.ID = response.ID
-	}
-
-	token, err := obj.tokenJWT.GenerateTokenJWT(context.Background(), &entity.AuthorizationClaims{
-		Email:     response.Email,
-		UserID:    response.ID,
-		Roles:     []entity.AccountRoles{},
-		IsRevoked: false,
-		// StandardClaims: jwt.StandardClaims{
-		// 	ExpiresAt: userFromProvider.ExpiresAt.Unix() - 300, // diminuir 5 minutos
-		// },
-		Username: response.Name,
-	}, response)
-
-	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": obj.Log.Error(&logger.Message{Body: err.Error(), Code: logger.ResponseCodeInternalServer}).Error(),
 		})
-		http.Redirect(c.Writer, c.Request, "http://localhost:5173/error", http.StatusTemporaryRedirect)
 		return
 	}
 
-	store := sessions.NewCookieStore([]byte(*token))
-	c.SetCookie("Authorization", *token, 3600, "/", "", true, true) // Ajuste os parâmetros do cookie conforme necessário
-
-	sessions.Default(c).Set("Authorization", store)
-	sessions.Default(c).Save()
-
-	_, err = obj.User.Create(ctx, response)
-	if err != nil {
-		if err.Error() == "user already exists" {
-			http.Redirect(c.Writer, c.Request, "http://localhost:5173", http.StatusTemporaryRedirect)
+	// the frontend asks the code and sends it to /v1/auth/2fa/verify, with the challenge of the cookie
+	// a user that must enroll gets the secret from /v1/auth/2fa/verify/enroll first
+	if result.TokenPair == nil {
+		obj.AuthProvider.Cookies().Set(c, twoFactorChallengeCookie, result.ChallengeToken, int(entity.TwoFactorChallengeTTL.Seconds()))
+		if result.TwoFactorEnrollment {
+			http.Redirect(c.Writer, c.Request, authProvider.WithQuery(redirects.TwoFactorURL, "enroll", "true"), http.StatusTemporaryRedirect)
 			return
 		}
-
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": obj.Log.Error(&logger.Message{Body: err.Error(), Code: logger.ResponseCodeInternalServer}).Error(),
-		})
-		http.Redirect(c.Writer, c.Request, "http://localhost:5173/error", http.StatusTemporaryRedirect)
+		http.Redirect(c.Writer, c.Request, redirects.TwoFactorURL, http.StatusTemporaryRedirect)
 		return
 	}
 
-	err = obj.tokenJWT.StoreTokenJWT(context.Background(), []byte(*token), &response.ID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": obj.Log.Error(&logger.Message{Body: err.Error(), Code: logger.ResponseCodeInternalServer}).Error(),
-		})
-		http.Redirect(c.Writer, c.Request, "http://localhost:5173/error", http.StatusTemporaryRedirect)
+	setTokenCookies(c, obj.AuthProvider.Cookies(), result.TokenPair)
+
+	if created {
+		http.Redirect(c.Writer, c.Request, redirects.SignupURL, http.StatusTemporaryRedirect)
 		return
 	}
 
-	http.Redirect(c.Writer, c.Request, "http://localhost:5173/form", http.StatusTemporaryRedirect)
+	http.Redirect(c.Writer, c.Request, redirectTo, http.StatusTemporaryRedirect)
 }
 
+// Logout revokes the token and the session of the request and ends the session of the provider
 func (obj *AuthHandlerHttp) Logout(c *gin.Context) {
+	if token := requestToken(c); token != "" {
+		// an invalid or expired token has nothing to revoke
+		if claims, err := obj.tokenJWT.ParseTokenJWT(c.Request.Context(), token); err == nil {
+			if err := obj.tokenJWT.RevokeTokenJWT(c.Request.Context(), &claims.Id); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": entity.Error(err.Error(), "auth", "Logout", entity.ApplicationLayerHandler, entity.ResponseCodeInternalServer)})
+				return
+			}
+			if claims.SessionID != "" {
+				err := obj.tokenJWT.RevokeSession(c.Request.Context(), claims.UserID, claims.SessionID)
+				if err != nil && !errors.Is(err, entity.ErrSessionNotFound) {
+					c.JSON(http.StatusInternalServerError, gin.H{"error": entity.Error(err.Error(), "auth", "Logout", entity.ApplicationLayerHandler, entity.ResponseCodeInternalServer)})
+					return
+				}
+			}
+		}
+	}
+
 	err := obj.AuthProvider.Logout(c, c.Writer, c.Request)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{
@@ -179,21 +206,21 @@ func (obj *AuthHandlerHttp) Logout(c *gin.Context) {
 		})
 		return
 	}
-	cookie, _ := c.Cookie("Authorization")
 
-	middleware.OpenTokenJWT(&cookie)
-
-	session := sessions.Default(c)
-	c.SetCookie("Authorization", "", -1, "/", "", true, true)
+	clearTokenCookies(c, obj.AuthProvider.Cookies())
 
-	session.Delete("Authorization")
-	session.Save()
-
-	c.Writer.Header().Set("Location", "http://localhost:5173")
+	redirects := obj.AuthProvider.Redirects()
+	c.Writer.Header().Set("Location", redirects.Redirect(c.Query(redirectToSession), redirects.LogoutURL))
 	c.Writer.WriteHeader(http.StatusTemporaryRedirect)
 }
 
+// Login starts the login of the provider, an allowed redirect_to is the page after the callback
 func (obj *AuthHandlerHttp) Login(c *gin.Context) {
+	if redirectTo := c.Query(redirectToSession); redirectTo != "" && obj.AuthProvider.Redirects().Allowed(redirectTo) {
+		session := sessions.Default(c)
+		session.Set(redirectToSession, redirectTo)
+		session.Save()
+	}
 
 	c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), "provider", c.Param("provider")))
 	c.Set("provider", c.Param("provider"))
@@ -253,3 +280,218 @@ func (obj *AuthHandlerHttp) ValidateToken(c *gin.Context) {
 
 	c.Next()
 }
+
+// Refresh rotates the refresh token of the body, or of the cookie, and returns a new token pair
+func (obj *AuthHandlerHttp) Refresh(c *gin.Context) {
+
+	var request refreshRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": entity.Error(err.Error(), "auth", "Refresh", entity.ApplicationLayerHandler, entity.ResponseCodeBadRequest)})
+			return
+		}
+	}
+	if request.RefreshToken == "" {
+		request.RefreshToken, _ = c.Cookie(refreshTokenCookie)
+	}
+	if request.RefreshToken == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": entity.Error("refresh token is required", "auth", "Refresh", entity.ApplicationLayerHandler, entity.ResponseCodeBadRequest)})
+		return
+	}
+
+	withSessionClient(c, "")
+	token, err := obj.tokenJWT.RefreshTokenJWT(c.Request.Context(), request.RefreshToken)
+	if errors.Is(err, entity.ErrUnauthorized) {
+		obj.AuthProvider.Cookies().Set(c, refreshTokenCookie, "", -1)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": entity.Error(err.Error(), "auth", "Refresh", entity.ApplicationLayerHandler, entity.ResponseCodeUnauthorized)})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": entity.Error(err.Error(), "auth", "Refresh", entity.ApplicationLayerHandler, entity.ResponseCodeInternalServer)})
+		return
+	}
+
+	setTokenCookies(c, obj.AuthProvider.Cookies(), token)
+	c.JSON(http.StatusOK, token)
+}
+
+// LogoutAll revokes every token of the user of the request, on all devices
+func (obj *AuthHandlerHttp) LogoutAll(c *gin.Context) {
+	principal, mErr := requestPrincipal(c, "auth", "LogoutAll")
+	if mErr != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": mErr})
+		return
+	}
+
+	if err := obj.tokenJWT.RevokeAllTokenJWT(c.Request.Context(), principal.User.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": entity.Error(err.Error(), "auth", "LogoutAll", entity.ApplicationLayerHandler, entity.ResponseCodeInternalServer)})
+		return
+	}
+
+	clearTokenCookies(c, obj.AuthProvider.Cookies())
+	c.Status(http.StatusNoContent)
+}
+
+// Link starts the login of the provider to link its account to the user of the request
+// The user is kept in the session until the callback of the provider
+func (obj *AuthHandlerHttp) Link(c *gin.Context) {
+	principal, mErr := requestPrincipal(c, "auth", "Link")
+	if mErr != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": mErr})
+		return
+	}
+
+	session := sessions.Default(c)
+	session.Set(linkUserSession, principal.User.ID)
+	session.Set(linkExpiresSession, time.Now().Add(linkTTL).Unix())
+	if err := session.Save(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": entity.Error(err.Error(), "auth", "Link", entity.ApplicationLayerHandler, entity.ResponseCodeInternalServer)})
+		return
+	}
+
+	c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), "provider", c.Param("provider")))
+	gothic.BeginAuthHandler(c.Writer, c.Request)
+}
+
+// Unlink removes the provider from the user of the request
+func (obj *AuthHandlerHttp) Unlink(c *gin.Context) {
+	principal, mErr := requestPrincipal(c, "auth", "Unlink")
+	if mErr != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": mErr})
+		return
+	}
+
+	if err := obj.Identity.Unlink(c.Request.Context(), principal.User.ID, c.Param("provider")); err != nil {
+		identityError(c, "Unlink", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Identities returns the providers linked to the user of the request
+func (obj *AuthHandlerHttp) Identities(c *gin.Context) {
+	principal, mErr := requestPrincipal(c, "auth", "Identities")
+	if mErr != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": mErr})
+		return
+	}
+
+	identities, err := obj.Identity.List(c.Request.Context(), principal.User.ID)
+	if err != nil {
+		identityError(c, "Identities", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"identities": identities})
+}
+
+// Providers returns the names of the enabled OAuth providers
+func (obj *AuthHandlerHttp) Providers(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"providers": obj.AuthProvider.Providers()})
+}
+
+// provider aborts with 404 the routes of an OAuth provider that is not enabled
+func (obj *AuthHandlerHttp) provider(c *gin.Context) {
+	if !obj.AuthProvider.HasProvider(c.Param("provider")) {
+		c.JSON(http.StatusNotFound, gin.H{"error": entity.Error("provider not found", "auth", "Provider", entity.ApplicationLayerHandler, entity.ResponseCodeNotFound)})
+		c.Abort()
+		return
+	}
+	c.Next()
+}
+
+// redirectError redirects the browser to the error page of the configuration, it reports false when there is none
+func (obj *AuthHandlerHttp) redirectError(c *gin.Context, message string) bool {
+	errorURL := obj.AuthProvider.Redirects().ErrorURL
+	if errorURL == "" {
+		return false
+	}
+	http.Redirect(c.Writer, c.Request, authProvider.WithQuery(errorURL, "error", message), http.StatusTemporaryRedirect)
+	return true
+}
+
+// redirectingTo returns the redirect_to kept by the Login, and removes it from the session
+func redirectingTo(c *gin.Context) string {
+	session := sessions.Default(c)
+	redirectTo, _ := session.Get(redirectToSession).(string)
+	if redirectTo != "" {
+		session.Delete(redirectToSession)
+		session.Save()
+	}
+	return redirectTo
+}
+
+// linkingUser returns the user of a Link started in the session, and ends it
+func linkingUser(c *gin.Context) string {
+	session := sessions.Default(c)
+	userID, _ := session.Get(linkUserSession).(string)
+	expiresAt, _ := session.Get(linkExpiresSession).(int64)
+	if userID == "" {
+		return ""
+	}
+
+	session.Delete(linkUserSession)
+	session.Delete(linkExpiresSession)
+	session.Save()
+
+	if time.Now().Unix() > expiresAt {
+		return ""
+	}
+	return userID
+}
+
+// externalIdentity is the account of the provider, the email is verified when the provider says so
+func externalIdentity(provider string, user *goth.User) *entity.ExternalIdentity {
+	verified := false
+	for _, key := range []string{"email_verified", "verified_email"} {
+		switch value := user.RawData[key].(type) {
+		case bool:
+			verified = verified || value
+		case string:
+			verified = verified || value == "true"
+		}
+	}
+
+	return &entity.ExternalIdentity{
+		Provider:       provider,
+		ProviderUserID: user.UserID,
+		EmailVerified:  verified,
+		User: entity.User{
+			Name:        user.Name,
+			Email:       user.Email,
+			AvatarURL:   user.AvatarURL,
+			FirstName:   user.FirstName,
+			LastName:    user.LastName,
+			NickName:    user.NickName,
+			Description: user.Description,
+			Location:    user.Location,
+		},
+	}
+}
+
+func identityError(c *gin.Context, method string, err error) {
+	code := entity.ResponseCodeInternalServer
+	switch {
+	case errors.Is(err, entity.ErrUnauthorized):
+		code = entity.ResponseCodeUnauthorized
+	case errors.Is(err, entity.ErrIdentityNotFound):
+		code = entity.ResponseCodeNotFound
+	case errors.Is(err, entity.ErrIdentityLinked), errors.Is(err, entity.ErrProviderLinked), errors.Is(err, entity.ErrIdentityConflict), errors.Is(err, entity.ErrLastIdentity):
+		code = entity.ResponseCodeConflict
+	case errors.Is(err, entity.ErrInvalidRequest):
+		code = entity.ResponseCodeBadRequest
+	}
+
+	c.JSON(int(code), gin.H{"error": entity.Error(err.Error(), "auth", method, entity.ApplicationLayerHandler, code)})
+}
+
+func setTokenCookies(c *gin.Context, cookies *authProvider.CookieConfig, token *entity.TokenPair) {
+	cookies.Set(c, "Authorization", token.AccessToken, int(entity.AccessTokenTTL.Seconds()))
+	cookies.Set(c, refreshTokenCookie, token.RefreshToken, int(entity.RefreshTokenTTL.Seconds()))
+}
+
+func clearTokenCookies(c *gin.Context, cookies *authProvider.CookieConfig) {
+	cookies.Set(c, "Authorization", "", -1)
+	cookies.Set(c, refreshTokenCookie, "", -1)
+}
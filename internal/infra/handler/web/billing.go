@@ -0,0 +1,203 @@
+package web
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/pkg/payment"
+)
+
+// maxWebhookSize limits the body of a webhook of the payment provider
+const maxWebhookSize = 64 << 10
+
+type BillingHandlerHttpInterface interface {
+	Checkout(c *gin.Context)
+	Cancel(c *gin.Context)
+	Resume(c *gin.Context)
+	Webhook(c *gin.Context)
+}
+
+// BillingHandlerHttp charges the paid plans and receives the webhooks of the payment provider
+type BillingHandlerHttp struct {
+	Service  entity.IBilling
+	Provider payment.Provider
+	DevMode  bool
+}
+
+// NewBillingHandlerHttp registers the billing routes, the webhook is not authenticated, its signature is verified
+// The checkout of the fake provider is registered only in the dev mode
+func NewBillingHandlerHttp(svc entity.IBilling, provider payment.Provider, devMode bool, routerGroup *gin.RouterGroup, middleware ...func(c *gin.Context)) BillingHandlerHttpInterface {
+
+	lab := &BillingHandlerHttp{
+		Service:  svc,
+		Provider: provider,
+		DevMode:  devMode,
+	}
+
+	lab.handlers(routerGroup, middleware...)
+
+	return lab
+}
+
+func (c *BillingHandlerHttp) handlers(routerGroup *gin.RouterGroup, middleware ...func(c *gin.Context)) {
+	middlewareList := make([]gin.HandlerFunc, len(middleware))
+	for i, mw := range middleware {
+		middlewareList[i] = mw
+	}
+
+	routerGroup.POST("/billing/checkout", append(middlewareList, require(entity.ModuleTenant, entity.PermissionOwner), c.Checkout)...)
+	routerGroup.POST("/subscription/cancel", append(middlewareList, require(entity.ModuleTenant, entity.PermissionOwner), c.Cancel)...)
+	routerGroup.POST("/subscription/resume", append(middlewareList, require(entity.ModuleTenant, entity.PermissionOwner), c.Resume)...)
+	routerGroup.POST("/billing/webhook", c.Webhook)
+
+	// the fake provider has no payment page, visiting the checkout pays it
+	if _, ok := c.Provider.(*payment.FakeProvider); ok && c.DevMode {
+		routerGroup.GET("/billing/fake/checkout/:id", c.FakeCheckout)
+	}
+}
+
+// Checkout    godoc
+// @Summary     create the checkout of a paid plan, the plan changes when the payment is confirmed
+// @Tags        Billing
+// @Accept      json
+// @Produce     json
+// @Param       plan body entity.SubscriptionRequest true "plan id"
+// @Success     200 {object} entity.CheckoutResponse
+// @Failure     400 {object} entity.ModuleError
+// @Failure     403 {object} entity.ModuleError
+// @Failure     404 {object} entity.ModuleError
+// @Router      /billing/checkout [post]
+func (obj *BillingHandlerHttp) Checkout(c *gin.Context) {
+
+	var request entity.SubscriptionRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": entity.Error(err.Error(), "billing", "Checkout", entity.ApplicationLayerHandler, entity.ResponseCodeBadRequest)})
+		c.Abort()
+		return
+	}
+
+	principal, mErr := requestPrincipal(c, "billing", "Checkout")
+	if mErr != nil {
+		c.JSON(int(mErr.Code), gin.H{"error": mErr})
+		c.Abort()
+		return
+	}
+
+	checkout, mErr := obj.Service.Checkout(c.Request.Context(), &principal.User.ID, &request)
+	if mErr != nil {
+		c.JSON(int(mErr.Code), gin.H{"error": mErr})
+		c.Abort()
+		return
+	}
+
+	c.JSON(http.StatusOK, checkout)
+}
+
+// Cancel    godoc
+// @Summary     cancel the subscription at the end of the period and stop the charges
+// @Tags        Subscription
+// @Produce     json
+// @Success     200 {object} entity.Subscription
+// @Failure     403 {object} entity.ModuleError
+// @Failure     409 {object} entity.ModuleError
+// @Router      /subscription/cancel [post]
+func (obj *BillingHandlerHttp) Cancel(c *gin.Context) {
+
+	principal, mErr := requestPrincipal(c, "billing", "Cancel")
+	if mErr != nil {
+		c.JSON(int(mErr.Code), gin.H{"error": mErr})
+		c.Abort()
+		return
+	}
+
+	subscription, mErr := obj.Service.Cancel(c.Request.Context(), &principal.User.ID)
+	if mErr != nil {
+		c.JSON(int(mErr.Code), gin.H{"error": mErr})
+		c.Abort()
+		return
+	}
+
+	c.JSON(http.StatusOK, subscription)
+}
+
+// Resume    godoc
+// @Summary     undo a cancel or a downgrade that is not applied yet
+// @Tags        Subscription
+// @Produce     json
+// @Success     200 {object} entity.Subscription
+// @Failure     403 {object} entity.ModuleError
+// @Failure     409 {object} entity.ModuleError
+// @Router      /subscription/resume [post]
+func (obj *BillingHandlerHttp) Resume(c *gin.Context) {
+
+	principal, mErr := requestPrincipal(c, "billing", "Resume")
+	if mErr != nil {
+		c.JSON(int(mErr.Code), gin.H{"error": mErr})
+		c.Abort()
+		return
+	}
+
+	subscription, mErr := obj.Service.Resume(c.Request.Context(), &principal.User.ID)
+	if mErr != nil {
+		c.JSON(int(mErr.Code), gin.H{"error": mErr})
+		c.Abort()
+		return
+	}
+
+	c.JSON(http.StatusOK, subscription)
+}
+
+// Webhook    godoc
+// @Summary     receive an event of the payment provider, the body is signed on the X-Signature header
+// @Tags        Billing
+// @Accept      json
+// @Success     204
+// @Failure     400 {object} entity.ModuleError
+// @Failure     401 {object} entity.ModuleError
+// @Router      /billing/webhook [post]
+func (obj *BillingHandlerHttp) Webhook(c *gin.Context) {
+
+	payload, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookSize))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": entity.Error(err.Error(), "billing", "Webhook", entity.ApplicationLayerHandler, entity.ResponseCodeBadRequest)})
+		c.Abort()
+		return
+	}
+
+	if mErr := obj.Service.HandleWebhook(c.Request.Context(), payload, c.GetHeader(payment.SignatureHeader)); mErr != nil {
+		c.JSON(int(mErr.Code), gin.H{"error": mErr})
+		c.Abort()
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// FakeCheckout    godoc
+// @Summary     pay a checkout of the fake provider, it exists only when the fake provider is configured in the dev mode
+// @Tags        Billing
+// @Param       id path string true "checkout id"
+// @Success     204
+// @Failure     404 {object} entity.ModuleError
+// @Router      /billing/fake/checkout/{id} [get]
+func (obj *BillingHandlerHttp) FakeCheckout(c *gin.Context) {
+
+	fake := obj.Provider.(*payment.FakeProvider)
+	payload, signature, err := fake.Complete(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": entity.Error(err.Error(), "billing", "FakeCheckout", entity.ApplicationLayerHandler, entity.ResponseCodeNotFound)})
+		c.Abort()
+		return
+	}
+
+	if mErr := obj.Service.HandleWebhook(c.Request.Context(), payload, signature); mErr != nil {
+		c.JSON(int(mErr.Code), gin.H{"error": mErr})
+		c.Abort()
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -40,13 +40,13 @@ func (cat *CategoryHandlerHttp) handlers(routerGroup *gin.RouterGroup, middlewar
 		middlewareList[i] = mw
 	}
 
-	routerGroup.POST("/category", append(middlewareList, cat.Create)...)
-	routerGroup.GET("/category/", append(middlewareList, cat.Get)...)
-	routerGroup.GET("/category/:id", append(middlewareList, cat.GetById)...)
-	routerGroup.PUT("/category/:id", append(middlewareList, cat.Update)...)
-	routerGroup.DELETE("/category/:id", append(middlewareList, cat.Delete)...)
-	routerGroup.GET("/category/search", append(middlewareList, cat.GetByFilterMany)...)
-	routerGroup.GET("/category/filter", append(middlewareList, cat.GetByFilterOne)...)
+	routerGroup.POST("/category", append(middlewareList, require(entity.ModuleCategory, entity.PermissionEdit), cat.Create)...)
+	routerGroup.GET("/category/", append(middlewareList, require(entity.ModuleCategory, entity.PermissionView), cat.Get)...)
+	routerGroup.GET("/category/:id", append(middlewareList, require(entity.ModuleCategory, entity.PermissionView), cat.GetById)...)
+	routerGroup.PUT("/category/:id", append(middlewareList, require(entity.ModuleCategory, entity.PermissionEdit), cat.Update)...)
+	routerGroup.DELETE("/category/:id", append(middlewareList, require(entity.ModuleCategory, entity.PermissionEdit), cat.Delete)...)
+	routerGroup.GET("/category/search", append(middlewareList, require(entity.ModuleCategory, entity.PermissionView), cat.GetByFilterMany)...)
+	routerGroup.GET("/category/filter", append(middlewareList, require(entity.ModuleCategory, entity.PermissionView), cat.GetByFilterOne)...)
 }
 
 func (cat *CategoryHandlerHttp) Create(c *gin.Context) {
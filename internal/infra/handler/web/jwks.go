@@ -0,0 +1,42 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Tomelin/financial-management-backend/pkg/jwtkeys"
+)
+
+type JWKSHandlerHttpInterface interface {
+	Get(c *gin.Context)
+}
+
+type JWKSHandlerHttp struct {
+	Keys *jwtkeys.KeySet
+}
+
+// NewJWKSHandlerHttp publishes the public keys that verify the tokens
+// The route is registered in the root group, /.well-known/jwks.json
+func NewJWKSHandlerHttp(keys *jwtkeys.KeySet, routerGroup *gin.RouterGroup) JWKSHandlerHttpInterface {
+
+	lab := &JWKSHandlerHttp{
+		Keys: keys,
+	}
+
+	routerGroup.GET("/.well-known/jwks.json", lab.Get)
+
+	return lab
+}
+
+// GetJWKS    godoc
+// @Summary     public keys of the tokens
+// @Tags        Authentication
+// @Produce     json
+// @Description public keys that verify the signature of the tokens, the HS256 secrets are not published
+// @Success     200 {object} jwtkeys.JSONWebKeySet
+// @Router      /.well-known/jwks.json [get]
+func (obj *JWKSHandlerHttp) Get(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, obj.Keys.JWKS())
+}
@@ -0,0 +1,185 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/pkg/authProvider"
+)
+
+type ILocalAuthHandlerHttp interface {
+	Register(c *gin.Context)
+	Login(c *gin.Context)
+	VerifyEmail(c *gin.Context)
+	ResendVerification(c *gin.Context)
+	ForgotPassword(c *gin.Context)
+	ResetPassword(c *gin.Context)
+}
+
+type LocalAuthHandlerHttp struct {
+	Service entity.ILocalAuth
+	cookies *authProvider.CookieConfig
+}
+
+func NewLocalAuthHandlerHttp(svc entity.ILocalAuth, cookies *authProvider.CookieConfig, routerGroup *gin.RouterGroup, middleware ...func(c *gin.Context)) ILocalAuthHandlerHttp {
+
+	lab := &LocalAuthHandlerHttp{
+		Service: svc,
+		cookies: cookies,
+	}
+
+	lab.handlers(routerGroup, middleware...)
+
+	return lab
+}
+
+func (c *LocalAuthHandlerHttp) handlers(routerGroup *gin.RouterGroup, middleware ...func(c *gin.Context)) {
+	middlewareList := make([]gin.HandlerFunc, len(middleware))
+	for i, mw := range middleware {
+		middlewareList[i] = mw
+	}
+
+	routerGroup.POST("/v1/auth/local/register", append(middlewareList, c.Register)...)
+	routerGroup.POST("/v1/auth/local/login", append(middlewareList, c.Login)...)
+	routerGroup.POST("/v1/auth/local/verify", append(middlewareList, c.VerifyEmail)...)
+	routerGroup.POST("/v1/auth/local/verify/resend", append(middlewareList, c.ResendVerification)...)
+	routerGroup.POST("/v1/auth/local/password/forgot", append(middlewareList, c.ForgotPassword)...)
+	routerGroup.POST("/v1/auth/local/password/reset", append(middlewareList, c.ResetPassword)...)
+}
+
+// Register    godoc
+// @Summary     register a user with email and password
+// @Tags        Auth
+// @Accept      json
+// @Produce     json
+// @Success     201 {object} entity.AccountUser
+// @Failure     400 {object} entity.ModuleError
+// @Failure     409 {object} entity.ModuleError
+// @Router      /v1/auth/local/register [post]
+func (obj *LocalAuthHandlerHttp) Register(c *gin.Context) {
+	var request entity.RegisterRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": entity.Error(err.Error(), "auth", "Register", entity.ApplicationLayerHandler, entity.ResponseCodeBadRequest)})
+		return
+	}
+
+	user, err := obj.Service.Register(c.Request.Context(), &request)
+	if err != nil {
+		localAuthError(c, "Register", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, user)
+}
+
+// Login    godoc
+// @Summary     sign in with email and password
+// @Tags        Auth
+// @Accept      json
+// @Produce     json
+// @Success     200 {object} entity.LoginResult
+// @Failure     401 {object} entity.ModuleError
+// @Failure     403 {object} entity.ModuleError
+// @Router      /v1/auth/local/login [post]
+func (obj *LocalAuthHandlerHttp) Login(c *gin.Context) {
+	var request entity.LoginRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": entity.Error(err.Error(), "auth", "Login", entity.ApplicationLayerHandler, entity.ResponseCodeBadRequest)})
+		return
+	}
+
+	withSessionClient(c, entity.ProviderLocal)
+	result, err := obj.Service.Login(c.Request.Context(), &request)
+	if err != nil {
+		localAuthError(c, "Login", err)
+		return
+	}
+
+	// with the second factor the tokens come from /v1/auth/2fa/verify
+	if result.TokenPair != nil {
+		setTokenCookies(c, obj.cookies, result.TokenPair)
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func (obj *LocalAuthHandlerHttp) VerifyEmail(c *gin.Context) {
+	var request entity.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": entity.Error(err.Error(), "auth", "VerifyEmail", entity.ApplicationLayerHandler, entity.ResponseCodeBadRequest)})
+		return
+	}
+
+	if err := obj.Service.VerifyEmail(c.Request.Context(), request.Token); err != nil {
+		localAuthError(c, "VerifyEmail", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ResendVerification answers 202 whether the email exists or not
+func (obj *LocalAuthHandlerHttp) ResendVerification(c *gin.Context) {
+	var request entity.EmailRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": entity.Error(err.Error(), "auth", "ResendVerification", entity.ApplicationLayerHandler, entity.ResponseCodeBadRequest)})
+		return
+	}
+
+	if err := obj.Service.ResendVerification(c.Request.Context(), request.Email); err != nil {
+		localAuthError(c, "ResendVerification", err)
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+// ForgotPassword answers 202 whether the email exists or not
+func (obj *LocalAuthHandlerHttp) ForgotPassword(c *gin.Context) {
+	var request entity.EmailRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": entity.Error(err.Error(), "auth", "ForgotPassword", entity.ApplicationLayerHandler, entity.ResponseCodeBadRequest)})
+		return
+	}
+
+	if err := obj.Service.ForgotPassword(c.Request.Context(), request.Email); err != nil {
+		localAuthError(c, "ForgotPassword", err)
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+func (obj *LocalAuthHandlerHttp) ResetPassword(c *gin.Context) {
+	var request entity.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": entity.Error(err.Error(), "auth", "ResetPassword", entity.ApplicationLayerHandler, entity.ResponseCodeBadRequest)})
+		return
+	}
+
+	if err := obj.Service.ResetPassword(c.Request.Context(), &request); err != nil {
+		localAuthError(c, "ResetPassword", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// localAuthError writes the error of the local authentication service with its status
+func localAuthError(c *gin.Context, method string, err error) {
+	code := entity.ResponseCodeInternalServer
+	switch {
+	case errors.Is(err, entity.ErrUnauthorized):
+		code = entity.ResponseCodeUnauthorized
+	case errors.Is(err, entity.ErrEmailNotVerified):
+		code = entity.ResponseCodeForbidden
+	case errors.Is(err, entity.ErrEmailInUse):
+		code = entity.ResponseCodeConflict
+	case errors.Is(err, entity.ErrInvalidActionToken), errors.Is(err, entity.ErrInvalidRequest):
+		code = entity.ResponseCodeBadRequest
+	}
+
+	c.JSON(int(code), gin.H{"error": entity.Error(err.Error(), "auth", method, entity.ApplicationLayerHandler, code)})
+}
@@ -0,0 +1,14 @@
+package web
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+)
+
+// pageRequest reads the page of the list routes, the order_by parameter accepts the sortable fields
+//
+//	/wallet?limit=20&order_by=name&direction=desc&cursor=<next_cursor of the previous page>
+func pageRequest(c *gin.Context, fields entity.Fields) (*entity.PageRequest, error) {
+	return entity.NewPageRequest(c.Query("limit"), c.Query("order_by"), c.Query("direction"), c.Query("cursor"), fields)
+}
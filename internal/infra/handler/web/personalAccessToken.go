@@ -0,0 +1,138 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+)
+
+type IPersonalAccessTokenHandlerHttp interface {
+	Create(c *gin.Context)
+	List(c *gin.Context)
+	Revoke(c *gin.Context)
+}
+
+// PersonalAccessTokenHandlerHttp manages the personal access tokens of the user of the JWT
+// A personal access token does not manage the tokens, the routes run behind the authentication middleware and accept only the JWT of the login
+type PersonalAccessTokenHandlerHttp struct {
+	Service entity.IPersonalAccessToken
+}
+
+func NewPersonalAccessTokenHandlerHttp(svc entity.IPersonalAccessToken, routerGroup *gin.RouterGroup, middleware ...func(c *gin.Context)) IPersonalAccessTokenHandlerHttp {
+
+	lab := &PersonalAccessTokenHandlerHttp{
+		Service: svc,
+	}
+
+	lab.handlers(routerGroup, middleware...)
+
+	return lab
+}
+
+func (c *PersonalAccessTokenHandlerHttp) handlers(routerGroup *gin.RouterGroup, middleware ...func(c *gin.Context)) {
+	middlewareList := make([]gin.HandlerFunc, len(middleware))
+	for i, mw := range middleware {
+		middlewareList[i] = mw
+	}
+
+	middlewareList = append(middlewareList, requireLogin())
+
+	routerGroup.POST("/v1/auth/tokens", append(middlewareList, c.Create)...)
+	routerGroup.GET("/v1/auth/tokens", append(middlewareList, c.List)...)
+	routerGroup.DELETE("/v1/auth/tokens/:id", append(middlewareList, c.Revoke)...)
+}
+
+// Create    godoc
+// @Summary     create a personal access token, the token is shown only in this response
+// @Tags        Auth
+// @Accept      json
+// @Produce     json
+// @Param       token body entity.PersonalAccessTokenRequest true "name, scopes and optional expiry"
+// @Success     201 {object} entity.PersonalAccessTokenCreated
+// @Failure     400 {object} entity.ModuleError
+// @Failure     401 {object} entity.ModuleError
+// @Router      /v1/auth/tokens [post]
+func (obj *PersonalAccessTokenHandlerHttp) Create(c *gin.Context) {
+	principal, mErr := requestPrincipal(c, "auth", "Create")
+	if mErr != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": mErr})
+		return
+	}
+
+	var request entity.PersonalAccessTokenRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": entity.Error(err.Error(), "auth", "Create", entity.ApplicationLayerHandler, entity.ResponseCodeBadRequest)})
+		return
+	}
+
+	created, err := obj.Service.Create(c.Request.Context(), principal.User.ID, &request)
+	if err != nil {
+		accessTokenError(c, "Create", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, created)
+}
+
+// List    godoc
+// @Summary     list the personal access tokens of the user, without the tokens
+// @Tags        Auth
+// @Produce     json
+// @Success     200 {object} map[string][]entity.PersonalAccessToken
+// @Failure     401 {object} entity.ModuleError
+// @Router      /v1/auth/tokens [get]
+func (obj *PersonalAccessTokenHandlerHttp) List(c *gin.Context) {
+	principal, mErr := requestPrincipal(c, "auth", "List")
+	if mErr != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": mErr})
+		return
+	}
+
+	tokens, err := obj.Service.List(c.Request.Context(), principal.User.ID)
+	if err != nil {
+		accessTokenError(c, "List", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tokens": tokens})
+}
+
+// Revoke    godoc
+// @Summary     revoke a personal access token
+// @Tags        Auth
+// @Param       id path string true "token id"
+// @Success     204
+// @Failure     401 {object} entity.ModuleError
+// @Failure     404 {object} entity.ModuleError
+// @Router      /v1/auth/tokens/{id} [delete]
+func (obj *PersonalAccessTokenHandlerHttp) Revoke(c *gin.Context) {
+	principal, mErr := requestPrincipal(c, "auth", "Revoke")
+	if mErr != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": mErr})
+		return
+	}
+
+	if err := obj.Service.Revoke(c.Request.Context(), principal.User.ID, c.Param("id")); err != nil {
+		accessTokenError(c, "Revoke", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func accessTokenError(c *gin.Context, method string, err error) {
+	code := entity.ResponseCodeInternalServer
+	switch {
+	case errors.Is(err, entity.ErrUnauthorized):
+		code = entity.ResponseCodeUnauthorized
+	case errors.Is(err, entity.ErrPersonalAccessTokenNotFound):
+		code = entity.ResponseCodeNotFound
+	case errors.Is(err, entity.ErrInvalidRequest):
+		code = entity.ResponseCodeBadRequest
+	}
+
+	c.JSON(int(code), gin.H{"error": entity.Error(err.Error(), "auth", method, entity.ApplicationLayerHandler, code)})
+}
@@ -1,6 +1,7 @@
 package web
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -41,13 +42,10 @@ func (c *PlanHandlerHttp) handlers(routerGroup *gin.RouterGroup, middleware ...f
 		middlewareList[i] = mw
 	}
 
-	routerGroup.POST("/plan", append(middlewareList, c.Create)...)
-	routerGroup.GET("/plan/:id", append(middlewareList, c.GetById)...)
-	routerGroup.GET("/plan/search", append(middlewareList, c.GetByFilterMany)...)
-	routerGroup.GET("/plan/filter", append(middlewareList, c.GetByFilterOne)...)
-	routerGroup.GET("/plan", append(middlewareList, c.Get)...)
-	routerGroup.PUT("/plan/:id", append(middlewareList, c.Update)...)
-	routerGroup.DELETE("/plan/:id", append(middlewareList, c.Delete)...)
+	routerGroup.GET("/plan/:id", append(middlewareList, require(entity.ModulePlan, entity.PermissionView), c.GetById)...)
+	routerGroup.GET("/plan/search", append(middlewareList, require(entity.ModulePlan, entity.PermissionView), c.GetByFilterMany)...)
+	routerGroup.GET("/plan/filter", append(middlewareList, require(entity.ModulePlan, entity.PermissionView), c.GetByFilterOne)...)
+	routerGroup.GET("/plan", append(middlewareList, require(entity.ModulePlan, entity.PermissionView), c.Get)...)
 }
 
 // CreatePlanResponse    godoc
@@ -57,9 +55,10 @@ func (c *PlanHandlerHttp) handlers(routerGroup *gin.RouterGroup, middleware ...f
 // @Produce     json
 // @Description create a new lab destroy
 // @Success     200 {object} entity.PlanResponse
+// @Failure     403 {object} entity.ModuleError
 // @Failure     404 {object} string
 // @Failure     500 {object} string
-// @Router      /plan [post]
+// @Router      /admin/plan [post]
 func (obj *PlanHandlerHttp) Create(c *gin.Context) {
 
 	var plan entity.PlanResponse
@@ -94,21 +93,39 @@ func (obj *PlanHandlerHttp) Create(c *gin.Context) {
 // @Tags        Plan
 // @Accept       json
 // @Produce     json
+// @Param       limit query int false "page size" default(50)
+// @Param       order_by query string false "field of the order"
+// @Param       direction query string false "asc or desc" default(asc)
+// @Param       cursor query string false "next_cursor of the previous page"
 // @Description get all lab destroy
-// @Success     200 {object} []entity.PlanResponse
+// @Success     200 {object} entity.Page[entity.PlanResponse]
+// @Failure     400 {object} string
+// @Failure     403 {object} entity.ModuleError
 // @Failure     404 {object} string
 // @Failure     500 {object} string
 // @Router      /plan [get]
 func (obj *PlanHandlerHttp) Get(c *gin.Context) {
 
-	response, err := obj.Service.Get()
+	page, err := pageRequest(c, entity.PlanFields)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Abort()
+		return
+	}
+
+	response, err := obj.Service.Get(page)
+	if errors.Is(err, entity.ErrInvalidPage) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Abort()
+		return
+	}
 	if err != nil {
 		c.JSON(500, gin.H{"error": err.Error()})
 		c.Abort()
 		return
 	}
 
-	if len(response) == 0 {
+	if len(response.Items) == 0 {
 		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
 		c.Abort()
 		return
@@ -125,6 +142,7 @@ func (obj *PlanHandlerHttp) Get(c *gin.Context) {
 // @Param       id path string true "found"
 // @Description get a lab destroy by ID
 // @Success     200 {object} entity.PlanResponse
+// @Failure     403 {object} entity.ModuleError
 // @Failure     404 {object} string
 // @Failure     500 {object} string
 // @Router      /plan/{id} [get]
@@ -165,23 +183,23 @@ func (obj *PlanHandlerHttp) GetById(c *gin.Context) {
 // @Param       email query string false "email@domain.com"
 // @Param       project query string false "project_name"
 // @Param       available query boolean false "string default" default(false)
+// @Param       filter query string false "or(name:eq:gold,price:lte:10.5)"
 // @Description get a lab destroy by email or project
 // @Success     200 {object} []entity.PlanResponse
+// @Failure     403 {object} entity.ModuleError
 // @Failure     404 {object} string
 // @Failure     500 {object} string
 // @Router      /plan/search [get]
 func (obj *PlanHandlerHttp) GetByFilterMany(c *gin.Context) {
 
-	key := c.Query("key")
-	value := c.Query("value")
-
-	if key == "" || value == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "key and value are required"})
+	filter, err := searchFilter(c, entity.PlanFields)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": entity.Error(err.Error(), "plan", "GetByFilterMany", entity.ApplicationLayerHandler, entity.ResponseCodeBadRequest)})
 		c.Abort()
 		return
 	}
 
-	response, err := obj.Service.GetByFilterMany(key, &value)
+	response, err := obj.Service.GetByFilterMany(filter)
 	if err != nil {
 		if err.Error() == "not found" {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
@@ -211,6 +229,7 @@ func (obj *PlanHandlerHttp) GetByFilterMany(c *gin.Context) {
 // @Param       available query boolean false "string default" default(false)
 // @Description get a lab destroy by email or project
 // @Success     200 {object} []entity.PlanResponse
+// @Failure     403 {object} entity.ModuleError
 // @Failure     404 {object} string
 // @Failure     500 {object} string
 // @Router      /plan/search [get]
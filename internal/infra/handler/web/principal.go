@@ -0,0 +1,99 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	middleware "github.com/Tomelin/financial-management-backend/internal/infra/handler/middleware/authorization"
+)
+
+// requestPrincipal returns the principal stored by the authentication middleware
+func requestPrincipal(c *gin.Context, module, method string) (*entity.Principal, *entity.ModuleError) {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return nil, entity.Error("token authorization is required", module, method, entity.ApplicationLayerHandler, entity.ResponseCodeUnauthorized)
+	}
+	return principal, nil
+}
+
+// requestToken returns the token of the Authorization header, or of the Authorization cookie
+func requestToken(c *gin.Context) string {
+	return middleware.RequestToken(c)
+}
+
+// requestEmail returns the email of the principal of the request
+func requestEmail(c *gin.Context, module, method string) (*string, *entity.ModuleError) {
+	principal, mErr := requestPrincipal(c, module, method)
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	email := principal.Email()
+	return &email, nil
+}
+
+// require returns the middleware that allows the request when the principal has the level on the module
+// The handlers name their middleware parameter as the package, so the routes use it through here
+func require(module entity.Module, level entity.PermissionLevel) gin.HandlerFunc {
+	return middleware.Require(module, level)
+}
+
+// requireLogin returns the middleware that allows only the principal of a JWT, not of a personal access token
+func requireLogin() gin.HandlerFunc {
+	return middleware.RequireLogin()
+}
+
+// requireSelf allows the request on the user of the id when it is the principal, or when the principal has the system level
+// An empty level allows only the principal, the request is aborted otherwise
+func requireSelf(c *gin.Context, method, id string, system entity.PermissionLevel) bool {
+	principal, mErr := requestPrincipal(c, "user", method)
+	if mErr != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": mErr})
+		c.Abort()
+		return false
+	}
+
+	if principal.User.ID == id || (system != "" && principal.Can(entity.ModuleUser, system)) {
+		return true
+	}
+
+	c.JSON(http.StatusForbidden, gin.H{"error": entity.Error(entity.ErrForbidden.Error(), "user", method, entity.ApplicationLayerHandler, entity.ResponseCodeForbidden)})
+	c.Abort()
+	return false
+}
+
+// requireTenant allows the request on the tenant of the id when the principal is a member of it, or when it has system-view
+func requireTenant(c *gin.Context, method, id string) bool {
+	principal, mErr := requestPrincipal(c, "tenant", method)
+	if mErr != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": mErr})
+		c.Abort()
+		return false
+	}
+
+	if principal.TenantID == id || principal.Can(entity.ModuleTenant, entity.PermissionSystemView) {
+		return true
+	}
+
+	c.JSON(http.StatusForbidden, gin.H{"error": entity.Error(entity.ErrForbidden.Error(), "tenant", method, entity.ApplicationLayerHandler, entity.ResponseCodeForbidden)})
+	c.Abort()
+	return false
+}
+
+// tenantFilter limits the filter to the tenant of the principal, the system users search every tenant
+func tenantFilter(c *gin.Context, method string, filter []entity.QueryDBClause) ([]entity.QueryDBClause, *entity.ModuleError) {
+	principal, mErr := requestPrincipal(c, "tenant", method)
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	if principal.Can(entity.ModuleTenant, entity.PermissionSystemView) {
+		return filter, nil
+	}
+
+	return append(filter, entity.AndClause([]entity.QueryDB{
+		{Key: "id", Value: principal.TenantID, Condition: string(entity.QueryFirebaseEqual)},
+	})...), nil
+}
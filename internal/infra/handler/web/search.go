@@ -0,0 +1,46 @@
+package web
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+)
+
+// searchFilter reads the filter of the search routes
+// The filter parameter accepts the syntax of entity.ParseQueryFilter, it may be repeated and the filters are joined by AND:
+//
+//	/wallet/search?filter=or(name:eq:Nubank,and(balance:gte:100,currency:in:BRL|USD))
+//
+// Without filter it reads the single key, value and condition parameters
+// Only the fields of the entity are accepted, their values are converted to the types of the fields
+func searchFilter(c *gin.Context, fields entity.Fields) ([]entity.QueryDBClause, error) {
+	var clauses []entity.QueryDBClause
+	if filters := c.QueryArray("filter"); len(filters) > 0 {
+		parsed, err := entity.ParseQueryFilters(filters)
+		if err != nil {
+			return nil, err
+		}
+		clauses = parsed
+	} else {
+		key := c.Query("key")
+		value := c.Query("value")
+		if key == "" || value == "" {
+			return nil, errors.New("filter or key and value are required")
+		}
+
+		clauses = entity.AndClause([]entity.QueryDB{
+			{
+				Key:       key,
+				Value:     value,
+				Condition: entity.QueryFirebaseString(c.Query("condition")),
+			},
+		})
+	}
+
+	if err := fields.Coerce(clauses); err != nil {
+		return nil, err
+	}
+	return clauses, nil
+}
@@ -0,0 +1,116 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+)
+
+type ISessionHandlerHttp interface {
+	List(c *gin.Context)
+	Revoke(c *gin.Context)
+}
+
+// SessionHandlerHttp manages the sessions, the logged in devices, of the user of the JWT
+// The routes run behind the authentication middleware and accept only the JWT of the login
+type SessionHandlerHttp struct {
+	tokenJWT entity.IAuthorization
+}
+
+func NewSessionHandlerHttp(tokenJWT entity.IAuthorization, routerGroup *gin.RouterGroup, middleware ...func(c *gin.Context)) ISessionHandlerHttp {
+
+	lab := &SessionHandlerHttp{
+		tokenJWT: tokenJWT,
+	}
+
+	lab.handlers(routerGroup, middleware...)
+
+	return lab
+}
+
+func (c *SessionHandlerHttp) handlers(routerGroup *gin.RouterGroup, middleware ...func(c *gin.Context)) {
+	middlewareList := make([]gin.HandlerFunc, len(middleware))
+	for i, mw := range middleware {
+		middlewareList[i] = mw
+	}
+
+	middlewareList = append(middlewareList, requireLogin())
+
+	routerGroup.GET("/v1/auth/sessions", append(middlewareList, c.List)...)
+	routerGroup.DELETE("/v1/auth/sessions/:id", append(middlewareList, c.Revoke)...)
+}
+
+// List    godoc
+// @Summary     list the active sessions of the user, the current one is marked
+// @Tags        Auth
+// @Produce     json
+// @Success     200 {object} map[string][]entity.Session
+// @Failure     401 {object} entity.ModuleError
+// @Router      /v1/auth/sessions [get]
+func (obj *SessionHandlerHttp) List(c *gin.Context) {
+	principal, mErr := requestPrincipal(c, "auth", "List")
+	if mErr != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": mErr})
+		return
+	}
+
+	sessions, err := obj.tokenJWT.ListSessions(c.Request.Context(), principal.User.ID)
+	if err != nil {
+		sessionError(c, "List", err)
+		return
+	}
+
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == principal.SessionID
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sessions": sessions})
+}
+
+// Revoke    godoc
+// @Summary     log a device out, its refresh token stops working
+// @Tags        Auth
+// @Param       id path string true "session id"
+// @Success     204
+// @Failure     401 {object} entity.ModuleError
+// @Failure     404 {object} entity.ModuleError
+// @Router      /v1/auth/sessions/{id} [delete]
+func (obj *SessionHandlerHttp) Revoke(c *gin.Context) {
+	principal, mErr := requestPrincipal(c, "auth", "Revoke")
+	if mErr != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": mErr})
+		return
+	}
+
+	if err := obj.tokenJWT.RevokeSession(c.Request.Context(), principal.User.ID, c.Param("id")); err != nil {
+		sessionError(c, "Revoke", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// withSessionClient puts the device of the request in its context, for the session of the tokens it issues
+// An empty provider keeps the provider of the user
+func withSessionClient(c *gin.Context, provider string) {
+	c.Request = c.Request.WithContext(entity.WithSessionClient(c.Request.Context(), entity.SessionClient{
+		UserAgent: c.Request.UserAgent(),
+		IP:        c.ClientIP(),
+		Provider:  provider,
+	}))
+}
+
+func sessionError(c *gin.Context, method string, err error) {
+	code := entity.ResponseCodeInternalServer
+	switch {
+	case errors.Is(err, entity.ErrUnauthorized):
+		code = entity.ResponseCodeUnauthorized
+	case errors.Is(err, entity.ErrSessionNotFound):
+		code = entity.ResponseCodeNotFound
+	}
+
+	c.JSON(int(code), gin.H{"error": entity.Error(err.Error(), "auth", method, entity.ApplicationLayerHandler, code)})
+}
@@ -0,0 +1,105 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+)
+
+type SubscriptionHandlerHttpInterface interface {
+	Get(c *gin.Context)
+	ChangePlan(c *gin.Context)
+}
+
+// SubscriptionHandlerHttp changes the plan of the tenant of the user over time
+// The cancel and the resume stop and check the charges, they are routes of BillingHandlerHttp
+type SubscriptionHandlerHttp struct {
+	Service entity.ISubscription
+}
+
+func NewSubscriptionHandlerHttp(svc entity.ISubscription, routerGroup *gin.RouterGroup, middleware ...func(c *gin.Context)) SubscriptionHandlerHttpInterface {
+
+	lab := &SubscriptionHandlerHttp{
+		Service: svc,
+	}
+
+	lab.handlers(routerGroup, middleware...)
+
+	return lab
+}
+
+func (c *SubscriptionHandlerHttp) handlers(routerGroup *gin.RouterGroup, middleware ...func(c *gin.Context)) {
+	middlewareList := make([]gin.HandlerFunc, len(middleware))
+	for i, mw := range middleware {
+		middlewareList[i] = mw
+	}
+
+	routerGroup.GET("/subscription", append(middlewareList, require(entity.ModuleTenant, entity.PermissionView), c.Get)...)
+	routerGroup.PUT("/subscription/plan", append(middlewareList, require(entity.ModuleTenant, entity.PermissionOwner), c.ChangePlan)...)
+}
+
+// Get    godoc
+// @Summary     the subscription of the tenant of the user
+// @Tags        Subscription
+// @Produce     json
+// @Success     200 {object} entity.Subscription
+// @Failure     401 {object} entity.ModuleError
+// @Failure     404 {object} entity.ModuleError
+// @Router      /subscription [get]
+func (obj *SubscriptionHandlerHttp) Get(c *gin.Context) {
+
+	principal, mErr := requestPrincipal(c, "subscription", "Get")
+	if mErr != nil {
+		c.JSON(int(mErr.Code), gin.H{"error": mErr})
+		c.Abort()
+		return
+	}
+
+	subscription, mErr := obj.Service.Get(c.Request.Context(), &principal.User.ID)
+	if mErr != nil {
+		c.JSON(int(mErr.Code), gin.H{"error": mErr})
+		c.Abort()
+		return
+	}
+
+	c.JSON(http.StatusOK, subscription)
+}
+
+// ChangePlan    godoc
+// @Summary     change the plan, an upgrade applies at once and a downgrade at the end of the period
+// @Tags        Subscription
+// @Accept      json
+// @Produce     json
+// @Param       plan body entity.SubscriptionRequest true "plan id"
+// @Success     200 {object} entity.Subscription
+// @Failure     400 {object} entity.ModuleError
+// @Failure     403 {object} entity.ModuleError
+// @Failure     404 {object} entity.ModuleError
+// @Router      /subscription/plan [put]
+func (obj *SubscriptionHandlerHttp) ChangePlan(c *gin.Context) {
+
+	var request entity.SubscriptionRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": entity.Error(err.Error(), "subscription", "ChangePlan", entity.ApplicationLayerHandler, entity.ResponseCodeBadRequest)})
+		c.Abort()
+		return
+	}
+
+	principal, mErr := requestPrincipal(c, "subscription", "ChangePlan")
+	if mErr != nil {
+		c.JSON(int(mErr.Code), gin.H{"error": mErr})
+		c.Abort()
+		return
+	}
+
+	subscription, mErr := obj.Service.ChangePlan(c.Request.Context(), &principal.User.ID, &request)
+	if mErr != nil {
+		c.JSON(int(mErr.Code), gin.H{"error": mErr})
+		c.Abort()
+		return
+	}
+
+	c.JSON(http.StatusOK, subscription)
+}
@@ -13,7 +13,6 @@ import (
 
 type TenantHandlerHttpInterface interface {
 	Create(c *gin.Context)
-	Get(c *gin.Context)
 	GetById(c *gin.Context)
 	Update(c *gin.Context)
 	Delete(c *gin.Context)
@@ -21,20 +20,21 @@ type TenantHandlerHttpInterface interface {
 	GetByFilterOne(c *gin.Context)
 }
 
+// TenantHandlerHttp answers the tenant routes, the members read their own tenant and AdminHandlerHttp registers the routes of the system admins
 type TenantHandlerHttp struct {
 	Service service.ITenantService
 }
 
-func NewTenantHandlerHttp(svc *service.ITenantService, routerGroup *gin.RouterGroup, middleware ...func(c *gin.Context)) TenantHandlerHttpInterface {
+// NewTenantHandlerHttp registers the read routes of the members, they find only the tenant they belong to
+func NewTenantHandlerHttp(svc service.ITenantService, routerGroup *gin.RouterGroup, middleware ...func(c *gin.Context)) TenantHandlerHttpInterface {
 
 	lab := &TenantHandlerHttp{
-		Service: *svc,
+		Service: svc,
 	}
 
 	lab.handlers(routerGroup, middleware...)
 
 	return lab
-
 }
 
 func (c *TenantHandlerHttp) handlers(routerGroup *gin.RouterGroup, middleware ...func(c *gin.Context)) {
@@ -43,13 +43,8 @@ func (c *TenantHandlerHttp) handlers(routerGroup *gin.RouterGroup, middleware ..
 		middlewareList[i] = mw
 	}
 
-	routerGroup.POST("/tenant", append(middlewareList, c.Create)...)
-	routerGroup.GET("/tenant/:id", append(middlewareList, c.GetById)...)
-	routerGroup.GET("/tenant/search", append(middlewareList, c.GetByFilterMany)...)
-	routerGroup.GET("/tenant/filter", append(middlewareList, c.GetByFilterOne)...)
-	routerGroup.GET("/tenant", append(middlewareList, c.Get)...)
-	routerGroup.PUT("/tenant/:id", append(middlewareList, c.Update)...)
-	routerGroup.DELETE("/tenant/:id", append(middlewareList, c.Delete)...)
+	routerGroup.GET("/tenant/search", append(middlewareList, require(entity.ModuleTenant, entity.PermissionView), c.GetByFilterMany)...)
+	routerGroup.GET("/tenant/:id", append(middlewareList, require(entity.ModuleTenant, entity.PermissionView), c.GetById)...)
 }
 
 // CreateTenantResponse    godoc
@@ -59,9 +54,10 @@ func (c *TenantHandlerHttp) handlers(routerGroup *gin.RouterGroup, middleware ..
 // @Produce     json
 // @Description create a new lab destroy
 // @Success     200 {object} entity.TenantResponse
+// @Failure     403 {object} entity.ModuleError
 // @Failure     404 {object} string
 // @Failure     500 {object} string
-// @Router      /tenant [post]
+// @Router      /admin/tenant [post]
 func (obj *TenantHandlerHttp) Create(c *gin.Context) {
 
 	var tenant entity.TenantResponse
@@ -91,34 +87,6 @@ func (obj *TenantHandlerHttp) Create(c *gin.Context) {
 	c.JSON(http.StatusAccepted, result)
 }
 
-// GetAllTenantResponse    godoc
-// @Summary     get all lab destroy
-// @Tags        Tenant
-// @Accept       json
-// @Produce     json
-// @Description get all lab destroy
-// @Success     200 {object} []entity.TenantResponse
-// @Failure     404 {object} string
-// @Failure     500 {object} string
-// @Router      /tenant [get]
-func (obj *TenantHandlerHttp) Get(c *gin.Context) {
-
-	response, err := obj.Service.Get()
-	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
-		c.Abort()
-		return
-	}
-
-	if len(response) == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
-		c.Abort()
-		return
-	}
-
-	c.JSON(http.StatusOK, response)
-}
-
 // GetById      godoc
 // @Summary     get a lab destroy by ID
 // @Tags        Tenant
@@ -127,9 +95,11 @@ func (obj *TenantHandlerHttp) Get(c *gin.Context) {
 // @Param       id path string true "found"
 // @Description get a lab destroy by ID
 // @Success     200 {object} entity.TenantResponse
+// @Failure     403 {object} entity.ModuleError
 // @Failure     404 {object} string
 // @Failure     500 {object} string
 // @Router      /tenant/{id} [get]
+// @Router      /admin/tenant/{id} [get]
 func (obj *TenantHandlerHttp) GetById(c *gin.Context) {
 
 	tenantId := c.Param("id")
@@ -138,6 +108,9 @@ func (obj *TenantHandlerHttp) GetById(c *gin.Context) {
 		c.Abort()
 		return
 	}
+	if !requireTenant(c, "GetById", tenantId) {
+		return
+	}
 
 	response, err := obj.Service.GetById(&tenantId)
 	if err != nil {
@@ -167,29 +140,31 @@ func (obj *TenantHandlerHttp) GetById(c *gin.Context) {
 // @Param       email query string false "email@domain.com"
 // @Param       project query string false "project_name"
 // @Param       available query boolean false "string default" default(false)
+// @Param       filter query string false "or(name:eq:Nubank,balance:gte:100)"
 // @Description get a lab destroy by email or project
 // @Success     200 {object} []entity.TenantResponse
+// @Failure     403 {object} entity.ModuleError
 // @Failure     404 {object} string
 // @Failure     500 {object} string
 // @Router      /tenant/search [get]
+// @Router      /admin/tenant/search [get]
 func (obj *TenantHandlerHttp) GetByFilterMany(c *gin.Context) {
 
-	key := c.Query("key")
-	value := c.Query("value")
-
-	if key == "" || value == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "key and value are required"})
+	filter, err := searchFilter(c, entity.TenantFields)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": entity.Error(err.Error(), "tenant", "GetByFilterMany", entity.ApplicationLayerHandler, entity.ResponseCodeBadRequest)})
 		c.Abort()
 		return
 	}
 
-	filter := entity.QueryDB{
-		Key:       key,
-		Value:     value,
-		Condition: string(entity.QueryFirebaseEqual),
+	filter, mErr := tenantFilter(c, "GetByFilterMany", filter)
+	if mErr != nil {
+		c.JSON(int(mErr.Code), gin.H{"error": mErr})
+		c.Abort()
+		return
 	}
 
-	response, err := obj.Service.GetByFilterMany(context.Background(), []entity.QueryDB{filter})
+	response, err := obj.Service.GetByFilterMany(context.Background(), filter)
 	if err != nil {
 		if err.Error() == "not found" {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
@@ -219,9 +194,10 @@ func (obj *TenantHandlerHttp) GetByFilterMany(c *gin.Context) {
 // @Param       available query boolean false "string default" default(false)
 // @Description get a lab destroy by email or project
 // @Success     200 {object} []entity.TenantResponse
+// @Failure     403 {object} entity.ModuleError
 // @Failure     404 {object} string
 // @Failure     500 {object} string
-// @Router      /tenant/search [get]
+// @Router      /admin/tenant/filter [get]
 func (obj *TenantHandlerHttp) GetByFilterOne(c *gin.Context) {
 
 	key := c.Query("key")
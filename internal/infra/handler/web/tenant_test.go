@@ -0,0 +1,76 @@
+package web_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	middleware "github.com/Tomelin/financial-management-backend/internal/infra/handler/middleware/authorization"
+	"github.com/Tomelin/financial-management-backend/internal/infra/handler/web"
+	"github.com/Tomelin/financial-management-backend/tests/coremocks"
+)
+
+type TenantHandlerTestSuite struct {
+	suite.Suite
+	member  *entity.AccountUser
+	tenant  *entity.TenantResponse
+	service *coremocks.ITenantService
+	router  *gin.Engine
+}
+
+func (s *TenantHandlerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+
+	s.tenant = &entity.TenantResponse{ID: uuid.New().String(), OwnerID: uuid.New().String(), Name: "Family"}
+	s.member = &entity.AccountUser{ID: uuid.New().String(), TenantID: s.tenant.ID, User: entity.User{Email: "member@domain.com"}}
+
+	s.service = new(coremocks.ITenantService)
+	s.service.On("GetById", mock.Anything).Return(s.tenant, nil)
+	s.service.On("GetByFilterMany", mock.Anything, mock.Anything).Return([]entity.TenantResponse{*s.tenant}, nil)
+
+	s.router = gin.New()
+	web.NewTenantHandlerHttp(s.service, s.router.Group(""), func(c *gin.Context) {
+		principal, _ := entity.NewPrincipal(s.member, nil)
+		middleware.SetPrincipal(c, principal)
+	})
+}
+
+func (s *TenantHandlerTestSuite) get(path string) int {
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w.Code
+}
+
+func (s *TenantHandlerTestSuite) TestGetById_Member() {
+	s.Equal(http.StatusOK, s.get("/tenant/"+s.tenant.ID), "a member reads its own tenant")
+	s.Equal(http.StatusForbidden, s.get("/tenant/"+uuid.New().String()), "a member does not read another tenant")
+	s.service.AssertNumberOfCalls(s.T(), "GetById", 1)
+}
+
+func (s *TenantHandlerTestSuite) TestGetByFilterMany_Member() {
+	s.Equal(http.StatusOK, s.get("/tenant/search?filter=or(name:eq:Family,alias:eq:family)"))
+	s.service.AssertCalled(s.T(), "GetByFilterMany", mock.Anything, mock.MatchedBy(func(filter []entity.QueryDBClause) bool {
+		last := filter[len(filter)-1]
+		return len(filter) == 2 && last.Queries[0].Key == "id" && last.Queries[0].Value == s.tenant.ID
+	}))
+}
+
+func (s *TenantHandlerTestSuite) TestGetByFilterMany_SystemView() {
+	s.member.Roles = []entity.AccountRoles{{Key: string(entity.ModuleSystem), Value: string(entity.PermissionSystemView)}}
+
+	// the system users search every tenant
+	s.Equal(http.StatusOK, s.get("/tenant/search?filter=name:eq:Family"))
+	s.service.AssertCalled(s.T(), "GetByFilterMany", mock.Anything, mock.MatchedBy(func(filter []entity.QueryDBClause) bool {
+		return len(filter) == 1
+	}))
+}
+
+func TestTenantHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(TenantHandlerTestSuite))
+}
@@ -0,0 +1,339 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/pkg/utils"
+)
+
+type TransactionHandlerHttpInterface interface {
+	Create(c *gin.Context)
+	Get(c *gin.Context)
+	GetByID(c *gin.Context)
+	Update(c *gin.Context)
+	Delete(c *gin.Context)
+	GetByFilterMany(c *gin.Context)
+}
+
+type TransactionHandlerHttp struct {
+	Service entity.ITransaction
+	User    entity.IUser
+}
+
+func NewTransactionHandlerHttp(svc *entity.ITransaction, user *entity.IUser, routerGroup *gin.RouterGroup, middleware ...func(c *gin.Context)) TransactionHandlerHttpInterface {
+
+	lab := &TransactionHandlerHttp{
+		Service: *svc,
+		User:    *user,
+	}
+
+	lab.handlers(routerGroup, middleware...)
+
+	return lab
+}
+
+func (c *TransactionHandlerHttp) handlers(routerGroup *gin.RouterGroup, middleware ...func(c *gin.Context)) {
+	middlewareList := make([]gin.HandlerFunc, len(middleware))
+	for i, mw := range middleware {
+		middlewareList[i] = mw
+	}
+
+	// the routes of a wallet need view on the module, the level of the user on the wallet allows the writes
+	routerGroup.POST("/wallet/:id/transactions", append(middlewareList, require(entity.ModuleTransaction, entity.PermissionView), c.Create)...)
+	routerGroup.GET("/wallet/:id/transactions", append(middlewareList, require(entity.ModuleTransaction, entity.PermissionView), c.Get)...)
+	routerGroup.GET("/wallet/:id/transactions/search", append(middlewareList, require(entity.ModuleTransaction, entity.PermissionView), c.GetByFilterMany)...)
+	routerGroup.GET("/wallet/:id/transactions/:transactionId", append(middlewareList, require(entity.ModuleTransaction, entity.PermissionView), c.GetByID)...)
+	routerGroup.PUT("/wallet/:id/transactions/:transactionId", append(middlewareList, require(entity.ModuleTransaction, entity.PermissionView), c.Update)...)
+	routerGroup.DELETE("/wallet/:id/transactions/:transactionId", append(middlewareList, require(entity.ModuleTransaction, entity.PermissionView), c.Delete)...)
+}
+
+// CreateWalletTransaction    godoc
+// @Summary     create a new transaction in the wallet
+// @Tags        Transaction
+// @Accept       json
+// @Produce     json
+// @Param       id path string true "wallet id"
+// @Description create a new income or expense in the wallet
+// @Success     202 {object} entity.WalletTransaction
+// @Failure     400 {object} entity.ModuleError
+// @Failure     403 {object} entity.ModuleError
+// @Failure     404 {object} entity.ModuleError
+// @Failure     500 {object} entity.ModuleError
+// @Router      /wallet/{id}/transactions [post]
+func (obj *TransactionHandlerHttp) Create(c *gin.Context) {
+
+	walletId := c.Param("id")
+
+	var transaction entity.WalletTransaction
+	if err := c.BindJSON(&transaction); err != nil {
+		if err.Error() == "EOF" {
+			c.JSON(http.StatusBadRequest, entity.Error("body is required", "transaction", "Create", entity.ApplicationLayerHandler, entity.ResponseCodeBadRequest))
+			c.Abort()
+			return
+		}
+		c.JSON(http.StatusBadRequest, entity.Error(err.Error(), "transaction", "Create", entity.ApplicationLayerHandler, entity.ResponseCodeBadRequest))
+		c.Abort()
+		return
+	}
+
+	transaction.WalletID = walletId
+	data, mErr := entity.NewWalletTransaction(&transaction)
+	if mErr != nil {
+		c.JSON(int(mErr.Code), mErr)
+		c.Abort()
+		return
+	}
+
+	userId, mErr := obj.getUserID(c)
+	if mErr != nil {
+		c.JSON(int(mErr.Code), mErr)
+		c.Abort()
+		return
+	}
+
+	result, mErr := obj.Service.Create(c.Request.Context(), userId, &walletId, data)
+	if mErr != nil {
+		c.JSON(int(mErr.Code), mErr)
+		c.Abort()
+		return
+	}
+
+	c.JSON(http.StatusAccepted, result)
+}
+
+// GetWalletTransactions    godoc
+// @Summary     get all transactions of the wallet
+// @Tags        Transaction
+// @Accept       json
+// @Produce     json
+// @Param       id path string true "wallet id"
+// @Param       limit query int false "page size" default(50)
+// @Param       order_by query string false "field of the order"
+// @Param       direction query string false "asc or desc" default(asc)
+// @Param       cursor query string false "next_cursor of the previous page"
+// @Description get all transactions of the wallet
+// @Success     200 {object} entity.Page[entity.WalletTransaction]
+// @Failure     400 {object} entity.ModuleError
+// @Failure     403 {object} entity.ModuleError
+// @Failure     404 {object} entity.ModuleError
+// @Failure     500 {object} entity.ModuleError
+// @Router      /wallet/{id}/transactions [get]
+func (obj *TransactionHandlerHttp) Get(c *gin.Context) {
+
+	walletId := c.Param("id")
+
+	page, err := pageRequest(c, entity.TransactionFields)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error(err.Error(), "transaction", "Get", entity.ApplicationLayerHandler, entity.ResponseCodeBadRequest))
+		c.Abort()
+		return
+	}
+
+	userId, mErr := obj.getUserID(c)
+	if mErr != nil {
+		c.JSON(int(mErr.Code), mErr)
+		c.Abort()
+		return
+	}
+
+	response, mErr := obj.Service.Get(c.Request.Context(), userId, &walletId, page)
+	if mErr != nil {
+		c.JSON(int(mErr.Code), mErr)
+		c.Abort()
+		return
+	}
+
+	if len(response.Items) == 0 {
+		c.JSON(http.StatusNotFound, entity.Error("not found", "transaction", "Get", entity.ApplicationLayerHandler, entity.ResponseCodeNotFound))
+		c.Abort()
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetWalletTransactionByID      godoc
+// @Summary     get a transaction of the wallet by ID
+// @Tags        Transaction
+// @Accept      json
+// @Produce     json
+// @Param       id path string true "wallet id"
+// @Param       transactionId path string true "transaction id"
+// @Description get a transaction of the wallet by ID
+// @Success     200 {object} entity.WalletTransaction
+// @Failure     403 {object} entity.ModuleError
+// @Failure     404 {object} entity.ModuleError
+// @Failure     500 {object} entity.ModuleError
+// @Router      /wallet/{id}/transactions/{transactionId} [get]
+func (obj *TransactionHandlerHttp) GetByID(c *gin.Context) {
+
+	walletId := c.Param("id")
+	transactionId := c.Param("transactionId")
+
+	userId, mErr := obj.getUserID(c)
+	if mErr != nil {
+		c.JSON(int(mErr.Code), mErr)
+		c.Abort()
+		return
+	}
+
+	response, mErr := obj.Service.GetByID(c.Request.Context(), userId, &walletId, &transactionId)
+	if mErr != nil {
+		c.JSON(int(mErr.Code), mErr)
+		c.Abort()
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetWalletTransactionsByFilter  godoc
+// @Summary     search the transactions of the wallet
+// @Tags        Transaction
+// @Accept      json
+// @Produce     json
+// @Param       id path string true "wallet id"
+// @Param       key query string true "type"
+// @Param       value query string true "expense"
+// @Param       condition query string false "=="
+// @Description search the transactions of the wallet by key and value
+// @Success     200 {object} []entity.WalletTransaction
+// @Failure     403 {object} entity.ModuleError
+// @Failure     404 {object} entity.ModuleError
+// @Failure     500 {object} entity.ModuleError
+// @Router      /wallet/{id}/transactions/search [get]
+func (obj *TransactionHandlerHttp) GetByFilterMany(c *gin.Context) {
+
+	walletId := c.Param("id")
+	key := c.Query("key")
+	value := c.Query("value")
+	condition := entity.QueryFirebaseString(c.Query("condition"))
+
+	if key == "" || value == "" {
+		c.JSON(http.StatusBadRequest, entity.Error("key and value are required", "transaction", "GetByFilterMany", entity.ApplicationLayerHandler, entity.ResponseCodeBadRequest))
+		c.Abort()
+		return
+	}
+
+	query := []entity.QueryDB{
+		{
+			Key:       key,
+			Value:     value,
+			Condition: condition,
+		},
+	}
+
+	if err := entity.TransactionFields.CoerceQueries(query); err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error(err.Error(), "transaction", "GetByFilterMany", entity.ApplicationLayerHandler, entity.ResponseCodeBadRequest))
+		c.Abort()
+		return
+	}
+
+	userId, mErr := obj.getUserID(c)
+	if mErr != nil {
+		c.JSON(int(mErr.Code), mErr)
+		c.Abort()
+		return
+	}
+
+	response, mErr := obj.Service.GetByFilterMany(c.Request.Context(), userId, &walletId, query)
+	if mErr != nil {
+		c.JSON(int(mErr.Code), mErr)
+		c.Abort()
+		return
+	}
+
+	if len(response) == 0 {
+		c.JSON(http.StatusNotFound, entity.Error("not found", "transaction", "GetByFilterMany", entity.ApplicationLayerHandler, entity.ResponseCodeNotFound))
+		c.Abort()
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (obj *TransactionHandlerHttp) Update(c *gin.Context) {
+
+	walletId := c.Param("id")
+	transactionId := c.Param("transactionId")
+
+	if err := utils.ValidateUUID(&transactionId); err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error(err.Error(), "transaction", "Update", entity.ApplicationLayerHandler, entity.ResponseCodeBadRequest))
+		c.Abort()
+		return
+	}
+
+	var transaction entity.WalletTransaction
+	if err := c.ShouldBindJSON(&transaction); err != nil {
+		if err.Error() == "EOF" {
+			c.JSON(http.StatusBadRequest, entity.Error("body is required", "transaction", "Update", entity.ApplicationLayerHandler, entity.ResponseCodeBadRequest))
+			c.Abort()
+			return
+		}
+		c.JSON(http.StatusBadRequest, entity.Error(err.Error(), "transaction", "Update", entity.ApplicationLayerHandler, entity.ResponseCodeBadRequest))
+		c.Abort()
+		return
+	}
+
+	if transaction.ID == "" {
+		transaction.ID = transactionId
+	}
+
+	if transaction.ID != transactionId {
+		c.JSON(http.StatusBadRequest, entity.Error("id in body must be equal to id in path", "transaction", "Update", entity.ApplicationLayerHandler, entity.ResponseCodeBadRequest))
+		c.Abort()
+		return
+	}
+
+	userId, mErr := obj.getUserID(c)
+	if mErr != nil {
+		c.JSON(int(mErr.Code), mErr)
+		c.Abort()
+		return
+	}
+
+	data, mErr := obj.Service.Update(c.Request.Context(), userId, &walletId, &transaction)
+	if mErr != nil {
+		c.JSON(int(mErr.Code), mErr)
+		c.Abort()
+		return
+	}
+
+	c.JSON(http.StatusOK, data)
+}
+
+func (obj *TransactionHandlerHttp) Delete(c *gin.Context) {
+
+	walletId := c.Param("id")
+	transactionId := c.Param("transactionId")
+
+	userId, mErr := obj.getUserID(c)
+	if mErr != nil {
+		c.JSON(int(mErr.Code), mErr)
+		c.Abort()
+		return
+	}
+
+	mErr = obj.Service.Delete(c.Request.Context(), userId, &walletId, &transactionId)
+	if mErr != nil {
+		c.JSON(int(mErr.Code), mErr)
+		c.Abort()
+		return
+	}
+
+	c.JSON(http.StatusNoContent, gin.H{"message": "deleted"})
+}
+
+// getUserID returns the ID of the user authenticated by the token
+func (obj *TransactionHandlerHttp) getUserID(c *gin.Context) (*string, *entity.ModuleError) {
+
+	principal, mErr := requestPrincipal(c, "transaction", "getUserID")
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	return &principal.User.ID, nil
+}
@@ -1,14 +1,12 @@
 package web
 
 import (
-	"context"
 	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 
 	"github.com/Tomelin/financial-management-backend/internal/core/entity"
-	middleware "github.com/Tomelin/financial-management-backend/internal/infra/handler/middleware/authorization"
 	"github.com/Tomelin/financial-management-backend/pkg/observability"
 )
 
@@ -51,11 +49,12 @@ func (c *TransactionCategoryHandlerHttp) handlers(routerGroup *gin.RouterGroup,
 		middlewareList[i] = mw
 	}
 
-	routerGroup.POST("/category", append(middlewareList, c.Create)...)
-	routerGroup.GET("/category", append(middlewareList, c.Get)...)
-	routerGroup.GET("/category/:id", append(middlewareList, c.GetById)...)
-	routerGroup.GET("/category/search", append(middlewareList, c.GetByFilterMany)...)
-	routerGroup.GET("/category/filter", append(middlewareList, c.GetByFilterOne)...)
+	// a category of a wallet needs edit on the wallet and a default category edit on the module, the service checks them
+	routerGroup.POST("/category", append(middlewareList, require(entity.ModuleCategory, entity.PermissionView), c.Create)...)
+	routerGroup.GET("/category", append(middlewareList, require(entity.ModuleCategory, entity.PermissionView), c.Get)...)
+	routerGroup.GET("/category/:id", append(middlewareList, require(entity.ModuleCategory, entity.PermissionView), c.GetById)...)
+	routerGroup.GET("/category/search", append(middlewareList, require(entity.ModuleCategory, entity.PermissionView), c.GetByFilterMany)...)
+	routerGroup.GET("/category/filter", append(middlewareList, require(entity.ModuleCategory, entity.PermissionView), c.GetByFilterOne)...)
 	// routerGroup.GET("/category/:id", append(middlewareList, c.GetWalletByIdAndUserID)...)
 	// routerGroup.PUT("/category/:id", append(middlewareList, c.Update)...)
 	// routerGroup.DELETE("/category/:id", append(middlewareList, c.Delete)...)
@@ -68,11 +67,12 @@ func (c *TransactionCategoryHandlerHttp) handlers(routerGroup *gin.RouterGroup,
 // @Produce     json
 // @Description create a new lab destroy
 // @Success     200 {object} entity.WalletResponse
+// @Failure     403 {object} entity.ModuleError
 // @Failure     404 {object} string
 // @Failure     500 {object} string
 // @Router      /category [post]
 func (obj *TransactionCategoryHandlerHttp) Create(c *gin.Context) {
-	ctx, span := obj.Trace.Trace.Start(context.Background(), fmt.Sprintf("%s.Create", string(entity.ApplicationLayerHandler)))
+	ctx, span := obj.Trace.Trace.Start(c.Request.Context(), fmt.Sprintf("%s.Create", string(entity.ApplicationLayerHandler)))
 	defer span.End()
 
 	var category entity.TransactionCategory
@@ -95,9 +95,9 @@ func (obj *TransactionCategoryHandlerHttp) Create(c *gin.Context) {
 		return
 	}
 
-	email, err := middleware.GetEmailFromToken(c)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, entity.Error(err.Error(), "create", "transaction_category", entity.ApplicationLayerHandler, entity.ResponseCodeUnauthorized))
+	email, mErr := requestEmail(c, "transactionCategory", "Create")
+	if mErr != nil {
+		c.JSON(int(mErr.Code), gin.H{"error": mErr})
 		c.Abort()
 		return
 	}
@@ -118,31 +118,44 @@ func (obj *TransactionCategoryHandlerHttp) Create(c *gin.Context) {
 // @Accept       json
 // @Produce     json
 // @Description get all lab destroy
-// @Success     200 {object} []entity.WalletResponse
+// @Param       limit query int false "page size" default(50)
+// @Param       order_by query string false "field of the order"
+// @Param       direction query string false "asc or desc" default(asc)
+// @Param       cursor query string false "next_cursor of the previous page"
+// @Success     200 {object} entity.Page[entity.TransactionCategory]
+// @Failure     400 {object} string
+// @Failure     403 {object} entity.ModuleError
 // @Failure     404 {object} string
 // @Failure     500 {object} string
 // @Router      /category [get]
 func (obj *TransactionCategoryHandlerHttp) Get(c *gin.Context) {
-	ctx, span := obj.Trace.Trace.Start(context.Background(), fmt.Sprintf("%s.Get", string(entity.ApplicationLayerHandler)))
+	ctx, span := obj.Trace.Trace.Start(c.Request.Context(), fmt.Sprintf("%s.Get", string(entity.ApplicationLayerHandler)))
 	defer span.End()
 
-	email, err := middleware.GetEmailFromToken(c)
+	email, mErr := requestEmail(c, "transactionCategory", "Get")
+	if mErr != nil {
+		c.JSON(int(mErr.Code), gin.H{"error": mErr})
+		c.Abort()
+		return
+	}
+
+	page, err := pageRequest(c, entity.TransactionCategoryFields)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, entity.Error(err.Error(), "get", "transaction_category", entity.ApplicationLayerHandler, entity.ResponseCodeUnauthorized))
+		c.JSON(http.StatusBadRequest, entity.Error(err.Error(), "get", "transaction_category", entity.ApplicationLayerHandler, entity.ResponseCodeBadRequest))
 		c.Abort()
 		return
 	}
 
 	walletID := c.Query("wallet_id")
 
-	response, mErr := obj.Service.Get(ctx, email, &walletID)
+	response, mErr := obj.Service.Get(ctx, email, &walletID, page)
 	if mErr != nil {
 		c.JSON(int(mErr.Code), mErr)
 		c.Abort()
 		return
 	}
 
-	if len(response) == 0 {
+	if len(response.Items) == 0 {
 		c.JSON(http.StatusNotFound, entity.Error("category not found", "get", "transaction_category", entity.ApplicationLayerHandler, entity.ResponseCodeNotFound))
 		c.Abort()
 		return
@@ -157,11 +170,12 @@ func (obj *TransactionCategoryHandlerHttp) Get(c *gin.Context) {
 // @Produce     json
 // @Description get all lab destroy
 // @Success     200 {object} entity.WalletResponse
+// @Failure     403 {object} entity.ModuleError
 // @Failure     404 {object} string
 // @Failure     500 {object} string
 // @Router      /category/{id} [get]
 func (obj *TransactionCategoryHandlerHttp) GetById(c *gin.Context) {
-	ctx, span := obj.Trace.Trace.Start(context.Background(), fmt.Sprintf("%s.GetById", string(entity.ApplicationLayerHandler)))
+	ctx, span := obj.Trace.Trace.Start(c.Request.Context(), fmt.Sprintf("%s.GetById", string(entity.ApplicationLayerHandler)))
 	defer span.End()
 
 	id := c.Param("id")
@@ -171,9 +185,9 @@ func (obj *TransactionCategoryHandlerHttp) GetById(c *gin.Context) {
 		return
 	}
 
-	email, err := middleware.GetEmailFromToken(c)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, entity.Error(err.Error(), "get", "transaction_category", entity.ApplicationLayerHandler, entity.ResponseCodeUnauthorized))
+	email, mErr := requestEmail(c, "transactionCategory", "GetById")
+	if mErr != nil {
+		c.JSON(int(mErr.Code), gin.H{"error": mErr})
 		c.Abort()
 		return
 	}
@@ -240,36 +254,27 @@ func (obj *TransactionCategoryHandlerHttp) GetById(c *gin.Context) {
 // @Param       email query string false "email@domain.com"
 // @Param       project query string false "project_name"
 // @Param       available query boolean false "string default" default(false)
+// @Param       filter query string false "or(name:eq:Nubank,balance:gte:100)"
 // @Description get a lab destroy by email or project
 // @Success     200 {object} []entity.WalletResponse
+// @Failure     403 {object} entity.ModuleError
 // @Failure     404 {object} string
 // @Failure     500 {object} string
 // @Router      /category/search [get]
 func (obj *TransactionCategoryHandlerHttp) GetByFilterMany(c *gin.Context) {
-	ctx, span := obj.Trace.Trace.Start(context.Background(), fmt.Sprintf("%s.GetByFilterMany", string(entity.ApplicationLayerHandler)))
+	ctx, span := obj.Trace.Trace.Start(c.Request.Context(), fmt.Sprintf("%s.GetByFilterMany", string(entity.ApplicationLayerHandler)))
 	defer span.End()
 
-	key := c.Query("key")
-	value := c.Query("value")
-	condition := entity.QueryFirebaseString(c.Query("condition"))
-
-	if key == "" || value == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": entity.Error("key and value are required", "transactionCategory", "GetByFilterMany", entity.ApplicationLayerHandler, entity.ResponseCodeBadRequest)})
+	query, err := searchFilter(c, entity.TransactionCategoryFields)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": entity.Error(err.Error(), "transactionCategory", "GetByFilterMany", entity.ApplicationLayerHandler, entity.ResponseCodeBadRequest)})
 		c.Abort()
 		return
 	}
 
-	query := []entity.QueryDB{
-		{
-			Key:       key,
-			Value:     value,
-			Condition: condition,
-		},
-	}
-
-	email, err := middleware.GetEmailFromToken(c)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": entity.Error(err.Error(), "transactionCategory", "GetByFilterMany", entity.ApplicationLayerHandler, entity.ResponseCodeBadRequest)})
+	email, mErr := requestEmail(c, "transactionCategory", "GetByFilterMany")
+	if mErr != nil {
+		c.JSON(int(mErr.Code), gin.H{"error": mErr})
 		c.Abort()
 		return
 	}
@@ -300,11 +305,12 @@ func (obj *TransactionCategoryHandlerHttp) GetByFilterMany(c *gin.Context) {
 // @Param       available query boolean false "string default" default(false)
 // @Description get a lab destroy by email or project
 // @Success     200 {object} []entity.WalletResponse
+// @Failure     403 {object} entity.ModuleError
 // @Failure     404 {object} string
 // @Failure     500 {object} string
 // @Router      /category/search [get]
 func (obj *TransactionCategoryHandlerHttp) GetByFilterOne(c *gin.Context) {
-	ctx, span := obj.Trace.Trace.Start(context.Background(), fmt.Sprintf("%s.GetByFilterOne", string(entity.ApplicationLayerHandler)))
+	ctx, span := obj.Trace.Trace.Start(c.Request.Context(), fmt.Sprintf("%s.GetByFilterOne", string(entity.ApplicationLayerHandler)))
 	defer span.End()
 
 	key := c.Query("key")
@@ -325,9 +331,9 @@ func (obj *TransactionCategoryHandlerHttp) GetByFilterOne(c *gin.Context) {
 		},
 	}
 
-	email, err := middleware.GetEmailFromToken(c)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": entity.Error(err.Error(), "transactionCategory", "GetByFilterOne", entity.ApplicationLayerHandler, entity.ResponseCodeBadRequest)})
+	email, mErr := requestEmail(c, "transactionCategory", "GetByFilterOne")
+	if mErr != nil {
+		c.JSON(int(mErr.Code), gin.H{"error": mErr})
 		c.Abort()
 		return
 	}
@@ -0,0 +1,312 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/pkg/authProvider"
+)
+
+// twoFactorChallengeCookie keeps the challenge of the OAuth login, the callback redirects to the frontend
+const twoFactorChallengeCookie = "two_factor_challenge"
+
+type ITwoFactorHandlerHttp interface {
+	Status(c *gin.Context)
+	Enroll(c *gin.Context)
+	EnrollChallenge(c *gin.Context)
+	Confirm(c *gin.Context)
+	Verify(c *gin.Context)
+	Disable(c *gin.Context)
+	RecoveryCodes(c *gin.Context)
+	RequireForTenant(c *gin.Context)
+}
+
+type TwoFactorHandlerHttp struct {
+	Service entity.ITwoFactor
+	cookies *authProvider.CookieConfig
+}
+
+type challengeRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+}
+
+func NewTwoFactorHandlerHttp(svc entity.ITwoFactor, cookies *authProvider.CookieConfig, routerGroup *gin.RouterGroup, middleware ...func(c *gin.Context)) ITwoFactorHandlerHttp {
+
+	lab := &TwoFactorHandlerHttp{
+		Service: svc,
+		cookies: cookies,
+	}
+
+	lab.handlers(routerGroup, middleware...)
+
+	return lab
+}
+
+func (c *TwoFactorHandlerHttp) handlers(routerGroup *gin.RouterGroup, middleware ...func(c *gin.Context)) {
+	middlewareList := make([]gin.HandlerFunc, len(middleware))
+	for i, mw := range middleware {
+		middlewareList[i] = mw
+	}
+
+	// the routes of the challenge of the login are public, the middleware authenticates the routes of the user of the request
+	loginList := append(middlewareList, requireLogin())
+
+	routerGroup.POST("/v1/auth/2fa/verify", c.Verify)
+	routerGroup.POST("/v1/auth/2fa/verify/enroll", c.EnrollChallenge)
+	routerGroup.GET("/v1/auth/2fa", append(loginList, c.Status)...)
+	routerGroup.POST("/v1/auth/2fa/enroll", append(loginList, c.Enroll)...)
+	routerGroup.POST("/v1/auth/2fa/confirm", append(loginList, c.Confirm)...)
+	routerGroup.POST("/v1/auth/2fa/disable", append(loginList, c.Disable)...)
+	routerGroup.POST("/v1/auth/2fa/recovery-codes", append(loginList, c.RecoveryCodes)...)
+	routerGroup.PUT("/v1/auth/2fa/tenant/:id", append(loginList, c.RequireForTenant)...)
+}
+
+// Status    godoc
+// @Summary     second factor of the user of the request
+// @Tags        Auth
+// @Produce     json
+// @Success     200 {object} entity.TwoFactorStatus
+// @Failure     401 {object} entity.ModuleError
+// @Router      /v1/auth/2fa [get]
+func (obj *TwoFactorHandlerHttp) Status(c *gin.Context) {
+	principal, mErr := requestPrincipal(c, "auth", "Status")
+	if mErr != nil {
+		c.JSON(int(mErr.Code), gin.H{"error": mErr})
+		return
+	}
+
+	status, err := obj.Service.Status(c.Request.Context(), &principal.User)
+	if err != nil {
+		twoFactorError(c, "Status", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// Enroll    godoc
+// @Summary     generate the TOTP secret of the user of the request
+// @Tags        Auth
+// @Produce     json
+// @Success     200 {object} entity.TwoFactorEnrollment
+// @Failure     401 {object} entity.ModuleError
+// @Failure     409 {object} entity.ModuleError
+// @Router      /v1/auth/2fa/enroll [post]
+func (obj *TwoFactorHandlerHttp) Enroll(c *gin.Context) {
+	principal, mErr := requestPrincipal(c, "auth", "Enroll")
+	if mErr != nil {
+		c.JSON(int(mErr.Code), gin.H{"error": mErr})
+		return
+	}
+
+	obj.enroll(c, "Enroll", principal.User.ID)
+}
+
+// EnrollChallenge    godoc
+// @Summary     generate the TOTP secret of the challenge of a login that requires to enroll, the code is sent to /v1/auth/2fa/verify
+// @Tags        Auth
+// @Accept      json
+// @Produce     json
+// @Success     200 {object} entity.TwoFactorEnrollment
+// @Failure     401 {object} entity.ModuleError
+// @Failure     409 {object} entity.ModuleError
+// @Router      /v1/auth/2fa/verify/enroll [post]
+func (obj *TwoFactorHandlerHttp) EnrollChallenge(c *gin.Context) {
+	var request challengeRequest
+	_ = c.ShouldBindJSON(&request)
+
+	userID, err := obj.Service.ChallengeUser(c.Request.Context(), challengeToken(c, request.ChallengeToken))
+	if err != nil {
+		twoFactorError(c, "EnrollChallenge", err)
+		return
+	}
+
+	obj.enroll(c, "EnrollChallenge", userID)
+}
+
+func (obj *TwoFactorHandlerHttp) enroll(c *gin.Context, method, userID string) {
+	enrollment, err := obj.Service.Enroll(c.Request.Context(), userID)
+	if err != nil {
+		twoFactorError(c, method, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, enrollment)
+}
+
+// Confirm    godoc
+// @Summary     enable the second factor with a code of the secret, the recovery codes are shown once
+// @Tags        Auth
+// @Accept      json
+// @Produce     json
+// @Success     200 {object} map[string][]string
+// @Failure     400 {object} entity.ModuleError
+// @Failure     401 {object} entity.ModuleError
+// @Router      /v1/auth/2fa/confirm [post]
+func (obj *TwoFactorHandlerHttp) Confirm(c *gin.Context) {
+	user, request, ok := obj.codeRequest(c, "Confirm")
+	if !ok {
+		return
+	}
+
+	codes, err := obj.Service.Confirm(c.Request.Context(), user.ID, request.Code)
+	if err != nil {
+		twoFactorError(c, "Confirm", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+// Verify    godoc
+// @Summary     send the code, or a recovery code, of the challenge of the login
+// @Tags        Auth
+// @Accept      json
+// @Produce     json
+// @Success     200 {object} entity.TwoFactorVerifyResult
+// @Failure     400 {object} entity.ModuleError
+// @Failure     401 {object} entity.ModuleError
+// @Router      /v1/auth/2fa/verify [post]
+func (obj *TwoFactorHandlerHttp) Verify(c *gin.Context) {
+	var request entity.TwoFactorVerifyRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": entity.Error(err.Error(), "auth", "Verify", entity.ApplicationLayerHandler, entity.ResponseCodeBadRequest)})
+		return
+	}
+	request.ChallengeToken = challengeToken(c, request.ChallengeToken)
+
+	withSessionClient(c, "")
+	result, err := obj.Service.Verify(c.Request.Context(), &request)
+	obj.cookies.Set(c, twoFactorChallengeCookie, "", -1)
+	if err != nil {
+		twoFactorError(c, "Verify", err)
+		return
+	}
+
+	setTokenCookies(c, obj.cookies, result.TokenPair)
+	c.JSON(http.StatusOK, result)
+}
+
+// Disable    godoc
+// @Summary     remove the second factor, with a code or a recovery code
+// @Tags        Auth
+// @Accept      json
+// @Success     204
+// @Failure     400 {object} entity.ModuleError
+// @Failure     401 {object} entity.ModuleError
+// @Failure     409 {object} entity.ModuleError
+// @Router      /v1/auth/2fa/disable [post]
+func (obj *TwoFactorHandlerHttp) Disable(c *gin.Context) {
+	user, request, ok := obj.codeRequest(c, "Disable")
+	if !ok {
+		return
+	}
+
+	if err := obj.Service.Disable(c.Request.Context(), user.ID, request.Code); err != nil {
+		twoFactorError(c, "Disable", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RecoveryCodes    godoc
+// @Summary     replace the recovery codes, with a code
+// @Tags        Auth
+// @Accept      json
+// @Produce     json
+// @Success     200 {object} map[string][]string
+// @Failure     400 {object} entity.ModuleError
+// @Failure     401 {object} entity.ModuleError
+// @Router      /v1/auth/2fa/recovery-codes [post]
+func (obj *TwoFactorHandlerHttp) RecoveryCodes(c *gin.Context) {
+	user, request, ok := obj.codeRequest(c, "RecoveryCodes")
+	if !ok {
+		return
+	}
+
+	codes, err := obj.Service.RegenerateRecoveryCodes(c.Request.Context(), user.ID, request.Code)
+	if err != nil {
+		twoFactorError(c, "RecoveryCodes", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+// RequireForTenant    godoc
+// @Summary     require the second factor of everyone that accesses the wallets of the tenant, only its owner
+// @Tags        Auth
+// @Accept      json
+// @Param       id path string true "tenant id"
+// @Success     204
+// @Failure     401 {object} entity.ModuleError
+// @Failure     403 {object} entity.ModuleError
+// @Failure     404 {object} entity.ModuleError
+// @Router      /v1/auth/2fa/tenant/{id} [put]
+func (obj *TwoFactorHandlerHttp) RequireForTenant(c *gin.Context) {
+	principal, mErr := requestPrincipal(c, "auth", "RequireForTenant")
+	if mErr != nil {
+		c.JSON(int(mErr.Code), gin.H{"error": mErr})
+		return
+	}
+
+	var request entity.TwoFactorTenantRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": entity.Error(err.Error(), "auth", "RequireForTenant", entity.ApplicationLayerHandler, entity.ResponseCodeBadRequest)})
+		return
+	}
+
+	if err := obj.Service.RequireForTenant(c.Request.Context(), principal.User.ID, c.Param("id"), request.Required); err != nil {
+		twoFactorError(c, "RequireForTenant", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// codeRequest returns the user of the request and the code of the body, the request is answered otherwise
+func (obj *TwoFactorHandlerHttp) codeRequest(c *gin.Context, method string) (*entity.AccountUser, *entity.TwoFactorCodeRequest, bool) {
+	principal, mErr := requestPrincipal(c, "auth", method)
+	if mErr != nil {
+		c.JSON(int(mErr.Code), gin.H{"error": mErr})
+		return nil, nil, false
+	}
+
+	var request entity.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": entity.Error(err.Error(), "auth", method, entity.ApplicationLayerHandler, entity.ResponseCodeBadRequest)})
+		return nil, nil, false
+	}
+	return &principal.User, &request, true
+}
+
+// challengeToken returns the challenge of the body, or of the cookie of the OAuth login
+func challengeToken(c *gin.Context, body string) string {
+	if body != "" {
+		return body
+	}
+	token, _ := c.Cookie(twoFactorChallengeCookie)
+	return token
+}
+
+func twoFactorError(c *gin.Context, method string, err error) {
+	code := entity.ResponseCodeInternalServer
+	switch {
+	case errors.Is(err, entity.ErrUnauthorized):
+		code = entity.ResponseCodeUnauthorized
+	case errors.Is(err, entity.ErrForbidden):
+		code = entity.ResponseCodeForbidden
+	case err.Error() == "tenant not found":
+		code = entity.ResponseCodeNotFound
+	case errors.Is(err, entity.ErrTwoFactorEnabled), errors.Is(err, entity.ErrTwoFactorRequired):
+		code = entity.ResponseCodeConflict
+	case errors.Is(err, entity.ErrInvalidTwoFactorCode), errors.Is(err, entity.ErrTwoFactorNotEnabled), errors.Is(err, entity.ErrInvalidRequest):
+		code = entity.ResponseCodeBadRequest
+	}
+
+	c.JSON(int(code), gin.H{"error": entity.Error(err.Error(), "auth", method, entity.ApplicationLayerHandler, code)})
+}
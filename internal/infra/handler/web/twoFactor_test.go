@@ -0,0 +1,103 @@
+package web_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	middleware "github.com/Tomelin/financial-management-backend/internal/infra/handler/middleware/authorization"
+	"github.com/Tomelin/financial-management-backend/internal/infra/handler/web"
+)
+
+// twoFactor enrolls the user of the request, or the user of the challenge "challenge"
+type twoFactor struct {
+	entity.ITwoFactor
+	user     string
+	enrolled []string
+}
+
+func (t *twoFactor) ChallengeUser(ctx context.Context, challengeToken string) (string, error) {
+	if challengeToken != "challenge" {
+		return "", entity.ErrUnauthorized
+	}
+	return t.user, nil
+}
+
+func (t *twoFactor) Enroll(ctx context.Context, userID string) (*entity.TwoFactorEnrollment, error) {
+	t.enrolled = append(t.enrolled, userID)
+	return &entity.TwoFactorEnrollment{}, nil
+}
+
+func (t *twoFactor) Status(ctx context.Context, user *entity.AccountUser) (*entity.TwoFactorStatus, error) {
+	return &entity.TwoFactorStatus{}, nil
+}
+
+type TwoFactorHandlerTestSuite struct {
+	suite.Suite
+	user      *entity.AccountUser
+	scopes    []entity.TokenScope
+	twoFactor *twoFactor
+	router    *gin.Engine
+}
+
+func (s *TwoFactorHandlerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+
+	s.user = &entity.AccountUser{ID: uuid.New().String(), TenantID: uuid.New().String(), User: entity.User{Email: "user@domain.com"}}
+	s.scopes = nil
+	s.twoFactor = &twoFactor{user: uuid.New().String()}
+
+	// the middleware authenticates the user only with the header, as Authenticate does
+	s.router = gin.New()
+	web.NewTwoFactorHandlerHttp(s.twoFactor, nil, s.router.Group(""), func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		principal, _ := entity.NewPrincipal(s.user, nil)
+		principal.Scopes = s.scopes
+		middleware.SetPrincipal(c, principal)
+	})
+}
+
+func (s *TwoFactorHandlerTestSuite) request(method, path, token, body string) int {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	s.router.ServeHTTP(w, r)
+	return w.Code
+}
+
+func (s *TwoFactorHandlerTestSuite) TestEnroll() {
+	s.Equal(http.StatusUnauthorized, s.request(http.MethodPost, "/v1/auth/2fa/enroll", "", ""))
+	s.Equal(http.StatusOK, s.request(http.MethodPost, "/v1/auth/2fa/enroll", "token", ""))
+	s.Equal([]string{s.user.ID}, s.twoFactor.enrolled, "the user of the request enrolls")
+}
+
+func (s *TwoFactorHandlerTestSuite) TestEnrollChallenge() {
+	s.Equal(http.StatusOK, s.request(http.MethodPost, "/v1/auth/2fa/verify/enroll", "", `{"challenge_token":"challenge"}`))
+	s.Equal(http.StatusUnauthorized, s.request(http.MethodPost, "/v1/auth/2fa/verify/enroll", "token", ""), "the challenge route does not take the token")
+	s.Equal([]string{s.twoFactor.user}, s.twoFactor.enrolled, "the user of the challenge enrolls")
+}
+
+func (s *TwoFactorHandlerTestSuite) TestPersonalAccessToken() {
+	s.scopes = []entity.TokenScope{{Module: entity.ModuleWallet, Level: entity.PermissionView}}
+
+	s.Equal(http.StatusForbidden, s.request(http.MethodGet, "/v1/auth/2fa", "token", ""), "a personal access token does not manage the second factor")
+	s.Equal(http.StatusForbidden, s.request(http.MethodPost, "/v1/auth/2fa/enroll", "token", ""))
+	s.Empty(s.twoFactor.enrolled)
+}
+
+func TestTwoFactorHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(TwoFactorHandlerTestSuite))
+}
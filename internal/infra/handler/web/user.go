@@ -1,6 +1,7 @@
 package web
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 
@@ -45,12 +46,9 @@ func (c *UserHandlerHttp) handlers(routerGroup *gin.RouterGroup, middleware ...f
 	}
 
 	routerGroup.POST("/user", c.Create)
-	routerGroup.GET("/user/:id", append(middlewareList, c.GetById)...)
-	routerGroup.GET("/user/search", append(middlewareList, c.GetByFilterMany)...)
-	routerGroup.GET("/user/filter", append(middlewareList, c.GetByFilterOne)...)
-	routerGroup.GET("/user", append(middlewareList, c.Get)...)
-	routerGroup.PUT("/user/:id", append(middlewareList, c.Update)...)
-	routerGroup.DELETE("/user/:id", append(middlewareList, c.Delete)...)
+	routerGroup.GET("/user/:id", append(middlewareList, require(entity.ModuleUser, entity.PermissionView), c.GetById)...)
+	routerGroup.PUT("/user/:id", append(middlewareList, require(entity.ModuleUser, entity.PermissionEdit), c.Update)...)
+	routerGroup.DELETE("/user/:id", append(middlewareList, require(entity.ModuleUser, entity.PermissionOwner), c.Delete)...)
 }
 
 // CreateUserResponse    godoc
@@ -101,21 +99,39 @@ func (obj *UserHandlerHttp) Create(c *gin.Context) {
 // @Tags        User
 // @Accept       json
 // @Produce     json
+// @Param       limit query int false "page size" default(50)
+// @Param       order_by query string false "field of the order"
+// @Param       direction query string false "asc or desc" default(asc)
+// @Param       cursor query string false "next_cursor of the previous page"
 // @Description get all lab destroy
-// @Success     200 {object} []entity.EntityResponse
+// @Success     200 {object} entity.Page[entity.AccountUser]
+// @Failure     400 {object} string
+// @Failure     403 {object} entity.ModuleError
 // @Failure     404 {object} string
 // @Failure     500 {object} string
-// @Router      /User [get]
+// @Router      /admin/user [get]
 func (obj *UserHandlerHttp) Get(c *gin.Context) {
+	page, err := pageRequest(c, entity.UserFields)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Abort()
+		return
+	}
+
 	ctx := c.Request.Context()
-	response, err := obj.Service.Get(ctx)
+	response, err := obj.Service.Get(ctx, page)
+	if errors.Is(err, entity.ErrInvalidPage) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Abort()
+		return
+	}
 	if err != nil {
 		c.JSON(500, gin.H{"error": err.Error()})
 		c.Abort()
 		return
 	}
 
-	if len(response) == 0 {
+	if len(response.Items) == 0 {
 		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
 		c.Abort()
 		return
@@ -132,6 +148,7 @@ func (obj *UserHandlerHttp) Get(c *gin.Context) {
 // @Param       id path string true "found"
 // @Description get a lab destroy by ID
 // @Success     200 {object} entity.EntityResponse
+// @Failure     403 {object} entity.ModuleError
 // @Failure     404 {object} string
 // @Failure     500 {object} string
 // @Router      /User/{id} [get]
@@ -143,6 +160,9 @@ func (obj *UserHandlerHttp) GetById(c *gin.Context) {
 		c.Abort()
 		return
 	}
+	if !requireSelf(c, "GetById", userId, entity.PermissionSystemView) {
+		return
+	}
 	ctx := c.Request.Context()
 	response, err := obj.Service.GetById(ctx, &userId)
 	if err != nil {
@@ -173,34 +193,22 @@ func (obj *UserHandlerHttp) GetById(c *gin.Context) {
 // @Param       project query string false "project_name"
 // @Param       username query string false "username"
 // @Param       available query boolean false "string default" default(false)
+// @Param       filter query string false "or(email:eq:a@example.com,provider:eq:google)"
 // @Description get a lab destroy by email or project
 // @Success     200 {object} []entity.EntityResponse
+// @Failure     403 {object} entity.ModuleError
 // @Failure     404 {object} string
 // @Failure     500 {object} string
-// @Router      /User/search [get]
+// @Router      /admin/user/search [get]
 func (obj *UserHandlerHttp) GetByFilterMany(c *gin.Context) {
 
-	key := c.Query("key")
-	value := c.Query("value")
-
-	if key == "" || value == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "key and value are required"})
+	filter, err := searchFilter(c, entity.UserFields)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": entity.Error(err.Error(), "user", "GetByFilterMany", entity.ApplicationLayerHandler, entity.ResponseCodeBadRequest)})
 		c.Abort()
 		return
 	}
 
-	filter := []entity.QueryDBClause{
-		{
-			Clause: entity.QueryClauseAnd,
-			Queries: []entity.QueryDB{
-				{
-					Key:       key,
-					Value:     value,
-					Condition: string(entity.QueryFirebaseEqual),
-				},
-			},
-		},
-	}
 	ctx := c.Request.Context()
 	response, err := obj.Service.GetByFilterMany(ctx, filter)
 	if err != nil {
@@ -227,9 +235,10 @@ func (obj *UserHandlerHttp) GetByFilterMany(c *gin.Context) {
 // @Param       available query boolean false "string default" default(false)
 // @Description get a lab destroy by email or project
 // @Success     200 {object} []entity.EntityResponse
+// @Failure     403 {object} entity.ModuleError
 // @Failure     404 {object} string
 // @Failure     500 {object} string
-// @Router      /User/search [get]
+// @Router      /admin/user/filter [get]
 func (obj *UserHandlerHttp) GetByFilterOne(c *gin.Context) {
 
 	key := c.Query("key")
@@ -284,6 +293,9 @@ func (obj *UserHandlerHttp) Update(c *gin.Context) {
 		c.Abort()
 		return
 	}
+	if !requireSelf(c, "Update", userId, entity.PermissionSystemAdmin) {
+		return
+	}
 
 	var user entity.AccountUser
 	if err := c.ShouldBindJSON(&user); err != nil {
@@ -325,6 +337,9 @@ func (obj *UserHandlerHttp) Delete(c *gin.Context) {
 		c.Abort()
 		return
 	}
+	if !requireSelf(c, "Delete", userId, "") {
+		return
+	}
 	ctx := c.Request.Context()
 	err := obj.Service.Delete(ctx, &userId)
 
@@ -0,0 +1,78 @@
+package web_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	middleware "github.com/Tomelin/financial-management-backend/internal/infra/handler/middleware/authorization"
+	"github.com/Tomelin/financial-management-backend/internal/infra/handler/web"
+	"github.com/Tomelin/financial-management-backend/tests/coremocks"
+)
+
+type UserHandlerTestSuite struct {
+	suite.Suite
+	caller  *entity.AccountUser
+	user    *entity.AccountUser
+	service *coremocks.IUser
+	router  *gin.Engine
+}
+
+func (s *UserHandlerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+
+	tenantID := uuid.New().String()
+	s.caller = &entity.AccountUser{ID: uuid.New().String(), TenantID: tenantID, User: entity.User{Email: "caller@domain.com"}}
+	s.user = &entity.AccountUser{ID: uuid.New().String(), TenantID: tenantID, User: entity.User{Name: "User", Email: "user@domain.com", Provider: "google"}}
+
+	s.service = new(coremocks.IUser)
+	s.service.On("Update", mock.Anything, mock.Anything).Return(
+		func(_ context.Context, data *entity.AccountUser) *entity.AccountUser { return data }, nil)
+
+	var svc entity.IUser = s.service
+	s.router = gin.New()
+	web.NewUserHandlerHttp(&svc, nil, s.router.Group(""), func(c *gin.Context) {
+		principal, _ := entity.NewPrincipal(s.caller, nil)
+		principal.TenantOwner = true
+		middleware.SetPrincipal(c, principal)
+	})
+}
+
+func (s *UserHandlerTestSuite) update() int {
+	data := *s.user
+	data.Roles = []entity.AccountRoles{{Key: string(entity.ModuleWallet), Value: string(entity.PermissionView)}}
+	body, _ := json.Marshal(data)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPut, "/user/"+s.user.ID, bytes.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	s.router.ServeHTTP(w, r)
+	return w.Code
+}
+
+func (s *UserHandlerTestSuite) TestUpdate_OtherUser() {
+	s.Equal(http.StatusForbidden, s.update(), "a user updates only itself")
+	s.service.AssertNotCalled(s.T(), "Update", mock.Anything, mock.Anything)
+}
+
+func (s *UserHandlerTestSuite) TestUpdate_SystemAdmin() {
+	s.caller.Roles = []entity.AccountRoles{{Key: string(entity.ModuleSystem), Value: string(entity.PermissionSystemAdmin)}}
+
+	s.Equal(http.StatusOK, s.update(), "a system admin assigns the roles of another user")
+	s.service.AssertCalled(s.T(), "Update", mock.Anything, mock.MatchedBy(func(data *entity.AccountUser) bool {
+		return data.ID == s.user.ID && len(data.Roles) == 1
+	}))
+}
+
+func TestUserHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(UserHandlerTestSuite))
+}
@@ -1,13 +1,11 @@
 package web
 
 import (
-	"context"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 
 	"github.com/Tomelin/financial-management-backend/internal/core/entity"
-	middleware "github.com/Tomelin/financial-management-backend/internal/infra/handler/middleware/authorization"
 	"github.com/Tomelin/financial-management-backend/pkg/utils"
 )
 
@@ -45,13 +43,14 @@ func (c *WalletHandlerHttp) handlers(routerGroup *gin.RouterGroup, middleware ..
 		middlewareList[i] = mw
 	}
 
-	routerGroup.POST("/wallet", append(middlewareList, c.Create)...)
-	routerGroup.GET("/wallet/:id", append(middlewareList, c.GetWalletByIdAndUserID)...)
-	routerGroup.GET("/wallet/search", append(middlewareList, c.GetByFilterMany)...)
-	routerGroup.GET("/wallet/filter", append(middlewareList, c.GetByFilterOne)...)
-	routerGroup.GET("/wallet", append(middlewareList, c.Get)...)
-	routerGroup.PUT("/wallet/:id", append(middlewareList, c.Update)...)
-	routerGroup.DELETE("/wallet/:id", append(middlewareList, c.Delete)...)
+	routerGroup.POST("/wallet", append(middlewareList, require(entity.ModuleWallet, entity.PermissionOwner), c.Create)...)
+	routerGroup.GET("/wallet/:id", append(middlewareList, require(entity.ModuleWallet, entity.PermissionView), c.GetWalletByIdAndUserID)...)
+	routerGroup.GET("/wallet/search", append(middlewareList, require(entity.ModuleWallet, entity.PermissionView), c.GetByFilterMany)...)
+	routerGroup.GET("/wallet/filter", append(middlewareList, require(entity.ModuleWallet, entity.PermissionView), c.GetByFilterOne)...)
+	routerGroup.GET("/wallet", append(middlewareList, require(entity.ModuleWallet, entity.PermissionView), c.Get)...)
+	// the routes of a wallet need view on the module, the level of the user on the wallet allows the writes
+	routerGroup.PUT("/wallet/:id", append(middlewareList, require(entity.ModuleWallet, entity.PermissionView), c.Update)...)
+	routerGroup.DELETE("/wallet/:id", append(middlewareList, require(entity.ModuleWallet, entity.PermissionView), c.Delete)...)
 }
 
 // CreateWalletResponse    godoc
@@ -61,13 +60,14 @@ func (c *WalletHandlerHttp) handlers(routerGroup *gin.RouterGroup, middleware ..
 // @Produce     json
 // @Description create a new lab destroy
 // @Success     200 {object} entity.WalletResponse
+// @Failure     403 {object} entity.ModuleError
 // @Failure     404 {object} string
 // @Failure     500 {object} string
 // @Router      /wallet [post]
 func (obj *WalletHandlerHttp) Create(c *gin.Context) {
 
 	var wallet entity.WalletResponse
-	ctx := context.Background()
+	ctx := c.Request.Context()
 	if err := c.BindJSON(&wallet); err != nil {
 		if err.Error() == "EOF" {
 			c.JSON(http.StatusBadRequest, entity.Error("body is required", "wallet", "Create", entity.ApplicationLayerHandler, entity.ResponseCodeBadRequest))
@@ -79,42 +79,27 @@ func (obj *WalletHandlerHttp) Create(c *gin.Context) {
 		return
 	}
 
-	data, mErr := entity.NewWallet(&wallet)
+	principal, mErr := requestPrincipal(c, "wallet", "Create")
 	if mErr != nil {
-		c.JSON(http.StatusBadGateway, mErr)
+		c.JSON(int(mErr.Code), gin.H{"error": mErr})
 		c.Abort()
 		return
 	}
 
-	authId, err := middleware.GetEmailFromToken(c)
-	if mErr != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
-		c.Abort()
-		return
-	}
+	// the wallet belongs to the principal and to its tenant, the ones of the request body are ignored
+	wallet.OwnerID = principal.User.ID
+	wallet.TenantID = principal.TenantID
 
-	user, err := obj.User.GetByEmail(ctx, authId)
+	data, mErr := entity.NewWallet(&wallet)
 	if mErr != nil {
-		c.JSON(500, gin.H{"error2": err.Error()})
-		c.Abort()
-		return
-	}
-
-	if authId == nil || *authId == "" || user == nil {
-		c.JSON(401, gin.H{"error": "token authorization is required"})
-		c.Abort()
-		return
-	}
-
-	if user.Email != *authId {
-		c.JSON(401, gin.H{"error": user.Email + " is not equal to " + *authId})
+		c.JSON(http.StatusBadGateway, mErr)
 		c.Abort()
 		return
 	}
 
-	result, mErr := obj.Service.Create(context.Background(), &user.ID, data)
+	result, mErr := obj.Service.Create(ctx, &principal.User.ID, data)
 	if mErr != nil {
-		c.JSON(http.StatusInternalServerError, mErr)
+		c.JSON(int(mErr.Code), mErr)
 		c.Abort()
 		return
 	}
@@ -126,48 +111,41 @@ func (obj *WalletHandlerHttp) Create(c *gin.Context) {
 // @Tags        Wallet
 // @Accept       json
 // @Produce     json
+// @Param       limit query int false "page size" default(50)
+// @Param       order_by query string false "field of the order"
+// @Param       direction query string false "asc or desc" default(asc)
+// @Param       cursor query string false "next_cursor of the previous page"
 // @Description get all lab destroy
-// @Success     200 {object} []entity.WalletResponse
+// @Success     200 {object} entity.Page[entity.WalletResponse]
+// @Failure     400 {object} string
+// @Failure     403 {object} entity.ModuleError
 // @Failure     404 {object} string
 // @Failure     500 {object} string
 // @Router      /wallet [get]
 func (obj *WalletHandlerHttp) Get(c *gin.Context) {
 
-	authId, err := middleware.GetEmailFromToken(c)
-	if err != nil {
-		c.JSON(500, gin.H{"error": entity.Error(err.Error(), "wallet", "Get", entity.ApplicationLayerHandler, entity.ResponseCodeUnauthorized)})
-		c.Abort()
-		return
-	}
-
-	ctx := context.Background()
-	user, err := obj.User.GetByEmail(ctx, authId)
+	page, err := pageRequest(c, entity.WalletFields)
 	if err != nil {
-		c.JSON(500, gin.H{"error": entity.Error(err.Error(), "wallet", "Get", entity.ApplicationLayerHandler, entity.ResponseCodeUnauthorized)})
-		c.Abort()
-		return
-	}
-
-	if authId == nil || *authId == "" || user == nil {
-		c.JSON(401, gin.H{"error": "token authorization is required"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": entity.Error(err.Error(), "wallet", "Get", entity.ApplicationLayerHandler, entity.ResponseCodeBadRequest)})
 		c.Abort()
 		return
 	}
 
-	if user.Email != *authId {
-		c.JSON(401, gin.H{"error": user.Email + " is not equal to " + *authId})
+	principal, mErr := requestPrincipal(c, "wallet", "Get")
+	if mErr != nil {
+		c.JSON(int(mErr.Code), gin.H{"error": mErr})
 		c.Abort()
 		return
 	}
 
-	response, mErr := obj.Service.Get(context.Background(), &user.ID)
+	response, mErr := obj.Service.Get(c.Request.Context(), &principal.User.ID, page)
 	if mErr != nil {
-		c.JSON(500, gin.H{"error": mErr})
+		c.JSON(int(mErr.Code), gin.H{"error": mErr})
 		c.Abort()
 		return
 	}
 
-	if len(response) == 0 {
+	if len(response.Items) == 0 {
 		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
 		c.Abort()
 		return
@@ -184,6 +162,7 @@ func (obj *WalletHandlerHttp) Get(c *gin.Context) {
 // @Param       id path string true "found"
 // @Description get a lab destroy by ID
 // @Success     200 {object} entity.WalletResponse
+// @Failure     403 {object} entity.ModuleError
 // @Failure     404 {object} string
 // @Failure     500 {object} string
 // @Router      /wallet/{id} [get]
@@ -196,14 +175,14 @@ func (obj *WalletHandlerHttp) GetWalletByIdAndUserID(c *gin.Context) {
 		return
 	}
 
-	email, err := middleware.GetEmailFromToken(c)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	email, mErr := requestEmail(c, "wallet", "GetWalletByIdAndUserID")
+	if mErr != nil {
+		c.JSON(int(mErr.Code), gin.H{"error": mErr})
 		c.Abort()
 		return
 	}
 
-	response, mErr := obj.Service.GetWalletByIdAndUserID(context.Background(), email, &walletId)
+	response, mErr := obj.Service.GetWalletByIdAndUserID(c.Request.Context(), email, &walletId)
 	if mErr != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": mErr})
 		c.Abort()
@@ -221,37 +200,30 @@ func (obj *WalletHandlerHttp) GetWalletByIdAndUserID(c *gin.Context) {
 // @Param       email query string false "email@domain.com"
 // @Param       project query string false "project_name"
 // @Param       available query boolean false "string default" default(false)
+// @Param       filter query string false "or(name:eq:Nubank,balance:gte:100)"
 // @Description get a lab destroy by email or project
 // @Success     200 {object} []entity.WalletResponse
+// @Failure     403 {object} entity.ModuleError
 // @Failure     404 {object} string
 // @Failure     500 {object} string
 // @Router      /wallet/search [get]
 func (obj *WalletHandlerHttp) GetByFilterMany(c *gin.Context) {
 
-	key := c.Query("key")
-	value := c.Query("value")
-	if key == "" || value == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": entity.Error("key and value are required", "wallet", "GetByFilterMany", entity.ApplicationLayerHandler, entity.ResponseCodeBadRequest)})
+	query, err := searchFilter(c, entity.WalletFields)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": entity.Error(err.Error(), "wallet", "GetByFilterMany", entity.ApplicationLayerHandler, entity.ResponseCodeBadRequest)})
 		c.Abort()
 		return
 	}
 
-	query := []entity.QueryDB{
-		{
-			Key:       key,
-			Value:     value,
-			Condition: string(entity.QueryFirebaseEqual),
-		},
-	}
-
-	email, err := middleware.GetEmailFromToken(c)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": entity.Error(err.Error(), "wallet", "GetByFilterMany", entity.ApplicationLayerHandler, entity.ResponseCodeBadRequest)})
+	email, mErr := requestEmail(c, "wallet", "GetByFilterMany")
+	if mErr != nil {
+		c.JSON(int(mErr.Code), gin.H{"error": mErr})
 		c.Abort()
 		return
 	}
 
-	response, mErr := obj.Service.GetByFilterMany(context.Background(), email, query)
+	response, mErr := obj.Service.GetByFilterMany(c.Request.Context(), email, query)
 	if mErr != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": mErr})
 		c.Abort()
@@ -277,6 +249,7 @@ func (obj *WalletHandlerHttp) GetByFilterMany(c *gin.Context) {
 // @Param       available query boolean false "string default" default(false)
 // @Description get a lab destroy by email or project
 // @Success     200 {object} []entity.WalletResponse
+// @Failure     403 {object} entity.ModuleError
 // @Failure     404 {object} string
 // @Failure     500 {object} string
 // @Router      /wallet/search [get]
@@ -299,14 +272,14 @@ func (obj *WalletHandlerHttp) GetByFilterOne(c *gin.Context) {
 		},
 	}
 
-	email, err := middleware.GetEmailFromToken(c)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": entity.Error(err.Error(), "wallet", "Update", entity.ApplicationLayerHandler, entity.ResponseCodeBadRequest)})
+	email, mErr := requestEmail(c, "wallet", "GetByFilterOne")
+	if mErr != nil {
+		c.JSON(int(mErr.Code), gin.H{"error": mErr})
 		c.Abort()
 		return
 	}
 
-	response, mErr := obj.Service.GetByFilterOne(context.Background(), email, query)
+	response, mErr := obj.Service.GetByFilterOne(c.Request.Context(), email, query)
 	if mErr != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": mErr})
 		c.Abort()
@@ -355,14 +328,14 @@ func (obj *WalletHandlerHttp) Update(c *gin.Context) {
 		return
 	}
 
-	email, err := middleware.GetEmailFromToken(c)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": entity.Error(err.Error(), "wallet", "Update", entity.ApplicationLayerHandler, entity.ResponseCodeBadRequest)})
+	email, mErr := requestEmail(c, "wallet", "Update")
+	if mErr != nil {
+		c.JSON(int(mErr.Code), gin.H{"error": mErr})
 		c.Abort()
 		return
 	}
 
-	data, mErr := obj.Service.Update(context.Background(), email, &wallet)
+	data, mErr := obj.Service.Update(c.Request.Context(), email, &wallet)
 	if mErr != nil {
 		if mErr.Code == entity.ResponseCodeNotFound {
 			c.JSON(int(mErr.Code), gin.H{"error": mErr})
@@ -385,27 +358,19 @@ func (obj *WalletHandlerHttp) Delete(c *gin.Context) {
 		return
 	}
 
-	authId := obj.getAccessToken(c)
-	err := obj.Service.Delete(context.Background(), authId, &walletId)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+	email, mErr := requestEmail(c, "wallet", "Delete")
+	if mErr != nil {
+		c.JSON(int(mErr.Code), gin.H{"error": mErr})
 		c.Abort()
 		return
 	}
 
-	c.JSON(http.StatusNoContent, gin.H{"message": "deleted"})
-}
-
-func (obj *WalletHandlerHttp) getAccessToken(c *gin.Context) *string {
-
-	authId := c.GetHeader("Authorization")
-	if authId == "" {
-		return nil
-	}
-	claims, err := middleware.OpenTokenJWT(&authId)
+	err := obj.Service.Delete(c.Request.Context(), email, &walletId)
 	if err != nil {
-		return nil
+		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		c.Abort()
+		return
 	}
 
-	return &claims.Email
+	c.JSON(http.StatusNoContent, gin.H{"message": "deleted"})
 }
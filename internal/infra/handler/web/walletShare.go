@@ -0,0 +1,302 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+)
+
+type WalletShareHandlerHttpInterface interface {
+	Invite(c *gin.Context)
+	GetInvitations(c *gin.Context)
+	CancelInvitation(c *gin.Context)
+	GetReceivedInvitations(c *gin.Context)
+	Accept(c *gin.Context)
+	Decline(c *gin.Context)
+	SetPermission(c *gin.Context)
+	Unshare(c *gin.Context)
+}
+
+// WalletShareHandlerHttp shares the wallets with other tenants through invitations by email
+type WalletShareHandlerHttp struct {
+	Service entity.IWalletShare
+}
+
+func NewWalletShareHandlerHttp(svc entity.IWalletShare, routerGroup *gin.RouterGroup, middleware ...func(c *gin.Context)) WalletShareHandlerHttpInterface {
+
+	lab := &WalletShareHandlerHttp{
+		Service: svc,
+	}
+
+	lab.handlers(routerGroup, middleware...)
+
+	return lab
+}
+
+func (c *WalletShareHandlerHttp) handlers(routerGroup *gin.RouterGroup, middleware ...func(c *gin.Context)) {
+	middlewareList := make([]gin.HandlerFunc, len(middleware))
+	for i, mw := range middleware {
+		middlewareList[i] = mw
+	}
+
+	routerGroup.GET("/wallet/invitations", append(middlewareList, require(entity.ModuleWallet, entity.PermissionView), c.GetReceivedInvitations)...)
+	routerGroup.POST("/wallet/invitations/:invitationId/accept", append(middlewareList, require(entity.ModuleWallet, entity.PermissionEdit), c.Accept)...)
+	routerGroup.POST("/wallet/invitations/:invitationId/decline", append(middlewareList, require(entity.ModuleWallet, entity.PermissionView), c.Decline)...)
+	// the routes of a wallet need view on the module, the level of the user on the wallet allows the writes
+	routerGroup.POST("/wallet/:id/invitations", append(middlewareList, require(entity.ModuleWallet, entity.PermissionView), c.Invite)...)
+	routerGroup.GET("/wallet/:id/invitations", append(middlewareList, require(entity.ModuleWallet, entity.PermissionView), c.GetInvitations)...)
+	routerGroup.DELETE("/wallet/:id/invitations/:invitationId", append(middlewareList, require(entity.ModuleWallet, entity.PermissionView), c.CancelInvitation)...)
+	routerGroup.PUT("/wallet/:id/permissions/:tenantId", append(middlewareList, require(entity.ModuleWallet, entity.PermissionView), c.SetPermission)...)
+	routerGroup.DELETE("/wallet/:id/permissions/:tenantId", append(middlewareList, require(entity.ModuleWallet, entity.PermissionView), c.Unshare)...)
+}
+
+// Invite    godoc
+// @Summary     invite an email to the wallet with a level, view, edit or admin
+// @Tags        Wallet
+// @Accept      json
+// @Produce     json
+// @Param       id path string true "wallet id"
+// @Param       invitation body entity.WalletInvitationRequest true "email and level"
+// @Success     201 {object} entity.WalletInvitation
+// @Failure     400 {object} entity.ModuleError
+// @Failure     403 {object} entity.ModuleError
+// @Failure     404 {object} entity.ModuleError
+// @Failure     409 {object} entity.ModuleError
+// @Router      /wallet/{id}/invitations [post]
+func (obj *WalletShareHandlerHttp) Invite(c *gin.Context) {
+
+	var request entity.WalletInvitationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": entity.Error(err.Error(), "wallet", "Invite", entity.ApplicationLayerHandler, entity.ResponseCodeBadRequest)})
+		c.Abort()
+		return
+	}
+
+	principal, mErr := requestPrincipal(c, "wallet", "Invite")
+	if mErr != nil {
+		c.JSON(int(mErr.Code), gin.H{"error": mErr})
+		c.Abort()
+		return
+	}
+
+	walletId := c.Param("id")
+	invitation, mErr := obj.Service.Invite(c.Request.Context(), &principal.User.ID, &walletId, &request)
+	if mErr != nil {
+		c.JSON(int(mErr.Code), gin.H{"error": mErr})
+		c.Abort()
+		return
+	}
+
+	c.JSON(http.StatusCreated, invitation)
+}
+
+// GetInvitations    godoc
+// @Summary     list the invitations of the wallet
+// @Tags        Wallet
+// @Produce     json
+// @Param       id path string true "wallet id"
+// @Success     200 {object} map[string][]entity.WalletInvitation
+// @Failure     403 {object} entity.ModuleError
+// @Failure     404 {object} entity.ModuleError
+// @Router      /wallet/{id}/invitations [get]
+func (obj *WalletShareHandlerHttp) GetInvitations(c *gin.Context) {
+
+	principal, mErr := requestPrincipal(c, "wallet", "GetInvitations")
+	if mErr != nil {
+		c.JSON(int(mErr.Code), gin.H{"error": mErr})
+		c.Abort()
+		return
+	}
+
+	walletId := c.Param("id")
+	invitations, mErr := obj.Service.GetInvitations(c.Request.Context(), &principal.User.ID, &walletId)
+	if mErr != nil {
+		c.JSON(int(mErr.Code), gin.H{"error": mErr})
+		c.Abort()
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"invitations": invitations})
+}
+
+// CancelInvitation    godoc
+// @Summary     cancel a pending invitation of the wallet
+// @Tags        Wallet
+// @Param       id path string true "wallet id"
+// @Param       invitationId path string true "invitation id"
+// @Success     204
+// @Failure     403 {object} entity.ModuleError
+// @Failure     404 {object} entity.ModuleError
+// @Failure     409 {object} entity.ModuleError
+// @Router      /wallet/{id}/invitations/{invitationId} [delete]
+func (obj *WalletShareHandlerHttp) CancelInvitation(c *gin.Context) {
+
+	principal, mErr := requestPrincipal(c, "wallet", "CancelInvitation")
+	if mErr != nil {
+		c.JSON(int(mErr.Code), gin.H{"error": mErr})
+		c.Abort()
+		return
+	}
+
+	walletId, invitationId := c.Param("id"), c.Param("invitationId")
+	if mErr := obj.Service.CancelInvitation(c.Request.Context(), &principal.User.ID, &walletId, &invitationId); mErr != nil {
+		c.JSON(int(mErr.Code), gin.H{"error": mErr})
+		c.Abort()
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetReceivedInvitations    godoc
+// @Summary     list the pending invitations to the email of the user
+// @Tags        Wallet
+// @Produce     json
+// @Success     200 {object} map[string][]entity.WalletInvitation
+// @Failure     401 {object} entity.ModuleError
+// @Router      /wallet/invitations [get]
+func (obj *WalletShareHandlerHttp) GetReceivedInvitations(c *gin.Context) {
+
+	principal, mErr := requestPrincipal(c, "wallet", "GetReceivedInvitations")
+	if mErr != nil {
+		c.JSON(int(mErr.Code), gin.H{"error": mErr})
+		c.Abort()
+		return
+	}
+
+	invitations, mErr := obj.Service.GetReceivedInvitations(c.Request.Context(), &principal.User.ID)
+	if mErr != nil {
+		c.JSON(int(mErr.Code), gin.H{"error": mErr})
+		c.Abort()
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"invitations": invitations})
+}
+
+// Accept    godoc
+// @Summary     accept an invitation, the wallet is shared with the tenant of the user
+// @Tags        Wallet
+// @Produce     json
+// @Param       invitationId path string true "invitation id"
+// @Success     200 {object} entity.WalletResponse
+// @Failure     400 {object} entity.ModuleError
+// @Failure     404 {object} entity.ModuleError
+// @Failure     409 {object} entity.ModuleError
+// @Router      /wallet/invitations/{invitationId}/accept [post]
+func (obj *WalletShareHandlerHttp) Accept(c *gin.Context) {
+
+	principal, mErr := requestPrincipal(c, "wallet", "Accept")
+	if mErr != nil {
+		c.JSON(int(mErr.Code), gin.H{"error": mErr})
+		c.Abort()
+		return
+	}
+
+	invitationId := c.Param("invitationId")
+	wallet, mErr := obj.Service.Accept(c.Request.Context(), &principal.User.ID, &invitationId)
+	if mErr != nil {
+		c.JSON(int(mErr.Code), gin.H{"error": mErr})
+		c.Abort()
+		return
+	}
+
+	c.JSON(http.StatusOK, wallet)
+}
+
+// Decline    godoc
+// @Summary     decline an invitation
+// @Tags        Wallet
+// @Param       invitationId path string true "invitation id"
+// @Success     204
+// @Failure     404 {object} entity.ModuleError
+// @Failure     409 {object} entity.ModuleError
+// @Router      /wallet/invitations/{invitationId}/decline [post]
+func (obj *WalletShareHandlerHttp) Decline(c *gin.Context) {
+
+	principal, mErr := requestPrincipal(c, "wallet", "Decline")
+	if mErr != nil {
+		c.JSON(int(mErr.Code), gin.H{"error": mErr})
+		c.Abort()
+		return
+	}
+
+	invitationId := c.Param("invitationId")
+	if mErr := obj.Service.Decline(c.Request.Context(), &principal.User.ID, &invitationId); mErr != nil {
+		c.JSON(int(mErr.Code), gin.H{"error": mErr})
+		c.Abort()
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// SetPermission    godoc
+// @Summary     change the level of a tenant the wallet is shared with
+// @Tags        Wallet
+// @Accept      json
+// @Produce     json
+// @Param       id path string true "wallet id"
+// @Param       tenantId path string true "tenant id"
+// @Param       permission body entity.WalletPermissionRequest true "view, edit or admin"
+// @Success     200 {object} entity.WalletResponse
+// @Failure     400 {object} entity.ModuleError
+// @Failure     403 {object} entity.ModuleError
+// @Failure     404 {object} entity.ModuleError
+// @Router      /wallet/{id}/permissions/{tenantId} [put]
+func (obj *WalletShareHandlerHttp) SetPermission(c *gin.Context) {
+
+	var request entity.WalletPermissionRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": entity.Error(err.Error(), "wallet", "SetPermission", entity.ApplicationLayerHandler, entity.ResponseCodeBadRequest)})
+		c.Abort()
+		return
+	}
+
+	principal, mErr := requestPrincipal(c, "wallet", "SetPermission")
+	if mErr != nil {
+		c.JSON(int(mErr.Code), gin.H{"error": mErr})
+		c.Abort()
+		return
+	}
+
+	walletId, tenantId := c.Param("id"), c.Param("tenantId")
+	wallet, mErr := obj.Service.SetPermission(c.Request.Context(), &principal.User.ID, &walletId, &tenantId, request.Level)
+	if mErr != nil {
+		c.JSON(int(mErr.Code), gin.H{"error": mErr})
+		c.Abort()
+		return
+	}
+
+	c.JSON(http.StatusOK, wallet)
+}
+
+// Unshare    godoc
+// @Summary     remove a tenant of the wallet, the tenant of the user can leave the wallet
+// @Tags        Wallet
+// @Param       id path string true "wallet id"
+// @Param       tenantId path string true "tenant id"
+// @Success     204
+// @Failure     403 {object} entity.ModuleError
+// @Failure     404 {object} entity.ModuleError
+// @Router      /wallet/{id}/permissions/{tenantId} [delete]
+func (obj *WalletShareHandlerHttp) Unshare(c *gin.Context) {
+
+	principal, mErr := requestPrincipal(c, "wallet", "Unshare")
+	if mErr != nil {
+		c.JSON(int(mErr.Code), gin.H{"error": mErr})
+		c.Abort()
+		return
+	}
+
+	walletId, tenantId := c.Param("id"), c.Param("tenantId")
+	if mErr := obj.Service.Unshare(c.Request.Context(), &principal.User.ID, &walletId, &tenantId); mErr != nil {
+		c.JSON(int(mErr.Code), gin.H{"error": mErr})
+		c.Abort()
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
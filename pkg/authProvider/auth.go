@@ -11,22 +11,26 @@ import (
 
 	"github.com/markbates/goth"
 	"github.com/markbates/goth/gothic"
-	"github.com/markbates/goth/providers/google"
 )
 
-const (
-	// AuthProviderGoogle is the google provider
-	key    = "randomString"
-	MaxAge = 86400 * 30
-	IsProd = false
-)
+// MaxAge is the default lifetime of the sessions, in seconds
+const MaxAge = 86400 * 30
 
 type IAuthProvider interface {
 	Login(w http.ResponseWriter, r *http.Request) (*SessionStore, error)
 	Callback(w http.ResponseWriter, r *http.Request) (*goth.User, error)
 	Logout(c *gin.Context, w http.ResponseWriter, r *http.Request) error
 	IsLoggedIn(w http.ResponseWriter, r *http.Request) (*goth.User, error)
-	Store() (*sessions.CookieStore, error)
+	// Store is the store of the sessions of the configuration, gothic uses the same one
+	Store() (sessions.Store, error)
+	// Cookies are the attributes of the cookies of the auth routes
+	Cookies() *CookieConfig
+	// Redirects are the pages of the frontend, with the defaults of the URLs that are not set
+	Redirects() *RedirectConfig
+	// HasProvider reports if the OAuth provider of the name is enabled
+	HasProvider(name string) bool
+	// Providers returns the names of the enabled OAuth providers, sorted
+	Providers() []string
 }
 
 type SessionRequest struct {
@@ -35,24 +39,32 @@ type SessionRequest struct {
 }
 
 type SessionStore struct {
-	CookieStore *sessions.CookieStore
-	Request     *SessionRequest
-	Session     *sessions.Session
-	User        *goth.User
+	Sessions  sessions.Store
+	Request   *SessionRequest
+	Session   *sessions.Session
+	User      *goth.User
+	providers []string
+	cookies   *CookieConfig
+	redirects *RedirectConfig
 }
 
 type AuthConfig struct {
-	Providers ProvidersConfig `json:"providers"`
+	Providers ProvidersConfig     `json:"providers"`
+	Local     LocalProviderConfig `json:"local"`
+	Cookie    CookieConfig        `json:"cookie"`
+	Session   SessionConfig       `json:"session"`
+	Redirects RedirectConfig      `json:"redirects"`
 }
 
-type ProviderConfig struct {
-	ClientID     string `json:"client_id"`
-	ClientSecret string `json:"client_secret"`
-	RedirectURL  string `json:"redirect_url"`
+// LocalProviderConfig is the email and password provider, the URLs are the links of its emails
+type LocalProviderConfig struct {
+	VerifyEmailURL   string `json:"verify_email_url"`
+	ResetPasswordURL string `json:"reset_password_url"`
 }
 
-type ProvidersConfig struct {
-	Google ProviderConfig `json:"google"`
+// ParseConfig parses the auth section of the configuration
+func ParseConfig(fields any) (*AuthConfig, error) {
+	return (&SessionStore{}).parseConfig(fields)
 }
 
 func NewAuthProvider(fields any) (IAuthProvider, error) {
@@ -63,21 +75,28 @@ func NewAuthProvider(fields any) (IAuthProvider, error) {
 		return nil, err
 	}
 
-	store := sessions.NewCookieStore([]byte(key))
-	sessions := &sessions.Options{
-		Path:     "/",
-		MaxAge:   MaxAge,
-		HttpOnly: true,
-		Secure:   IsProd,
+	providers, err := NewProviders(rest.Providers)
+	if err != nil {
+		return nil, err
 	}
-	store.Options(*sessions)
 
+	store, err := NewSessionStore(rest.Session, &rest.Cookie)
+	if err != nil {
+		return nil, err
+	}
+
+	// gothic reads the state of the OAuth login only from its global store
 	gothic.Store = store
-	goth.UseProviders(
-		google.New(rest.Providers.Google.ClientID, rest.Providers.Google.ClientSecret, rest.Providers.Google.RedirectURL),
-	)
+	goth.ClearProviders()
+	goth.UseProviders(providers...)
+	for _, provider := range providers {
+		session.providers = append(session.providers, provider.Name())
+	}
 
-	session.CookieStore = &store
+	redirects := rest.Redirects.WithDefaults()
+	session.Sessions = store
+	session.cookies = &rest.Cookie
+	session.redirects = &redirects
 	return &session, nil
 }
 
@@ -93,7 +112,7 @@ func (a *SessionStore) Callback(w http.ResponseWriter, r *http.Request) (*goth.U
 		return nil, err
 	}
 
-	session, err := gothic.Store.Get(r, "access_token")
+	session, err := a.Sessions.Get(r, "access_token")
 	if err != nil {
 		return nil, err
 	}
@@ -113,9 +132,10 @@ func (a *SessionStore) Logout(c *gin.Context, w http.ResponseWriter, r *http.Req
 	session.Clear()
 	session.Options(sessions.Options{
 		Path:     "/",
+		Domain:   a.cookies.domain(),
 		MaxAge:   -1,
 		HttpOnly: true,
-		Secure:   IsProd,
+		Secure:   a.cookies.IsSecure(),
 	})
 	err := session.Save()
 	if err != nil {
@@ -132,7 +152,7 @@ func (a *SessionStore) Logout(c *gin.Context, w http.ResponseWriter, r *http.Req
 
 func (a *SessionStore) IsLoggedIn(w http.ResponseWriter, r *http.Request) (*goth.User, error) {
 
-	session, err := gothic.Store.Get(r, "access_token")
+	session, err := a.Sessions.Get(r, "access_token")
 	if err != nil {
 		return nil, err
 	}
@@ -144,8 +164,32 @@ func (a *SessionStore) IsLoggedIn(w http.ResponseWriter, r *http.Request) (*goth
 	return &user, nil
 }
 
-func (a *SessionStore) Store() (*sessions.CookieStore, error) {
-	return a.CookieStore, nil
+func (a *SessionStore) Store() (sessions.Store, error) {
+	if a.Sessions == nil {
+		return nil, errors.New("session store is not configured")
+	}
+	return a.Sessions, nil
+}
+
+func (a *SessionStore) Cookies() *CookieConfig {
+	return a.cookies
+}
+
+func (a *SessionStore) Redirects() *RedirectConfig {
+	return a.redirects
+}
+
+func (a *SessionStore) HasProvider(name string) bool {
+	for _, provider := range a.providers {
+		if provider == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *SessionStore) Providers() []string {
+	return append([]string{}, a.providers...)
 }
 
 func (a *SessionStore) parseConfig(fields any) (*AuthConfig, error) {
@@ -0,0 +1,140 @@
+package authProvider
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/azureadv2"
+	"github.com/markbates/goth/providers/discord"
+	"github.com/markbates/goth/providers/facebook"
+	"github.com/markbates/goth/providers/github"
+	"github.com/markbates/goth/providers/gitlab"
+	"github.com/markbates/goth/providers/google"
+	"github.com/markbates/goth/providers/openidConnect"
+)
+
+// ProviderTypeOIDC is the generic OpenID Connect provider, its endpoints come from the discovery document
+const ProviderTypeOIDC = "oidc"
+
+// ProviderConfig is an OAuth provider of the configuration, the key of the entry is its name in the /v1/auth/:provider routes
+type ProviderConfig struct {
+	// Type is the goth provider, the default is the key of the entry
+	Type         string   `json:"type"`
+	ClientID     string   `json:"client_id"`
+	ClientSecret string   `json:"client_secret"`
+	RedirectURL  string   `json:"redirect_url"`
+	Scopes       []string `json:"scopes"`
+	// DiscoveryURL is the discovery document of the oidc type,
+	// e.g. https://sso.domain.com/realms/app/.well-known/openid-configuration
+	DiscoveryURL string `json:"discovery_url"`
+	// Tenant is the Azure AD tenant of the microsoft type, the default is common
+	Tenant string `json:"tenant"`
+}
+
+// ProvidersConfig are the OAuth providers by name
+type ProvidersConfig map[string]ProviderConfig
+
+type providerFactory func(cfg ProviderConfig) (goth.Provider, error)
+
+// providerTypes are the goth providers that can be enabled by the configuration
+var providerTypes = map[string]providerFactory{
+	"google": func(cfg ProviderConfig) (goth.Provider, error) {
+		return google.New(cfg.ClientID, cfg.ClientSecret, cfg.RedirectURL, cfg.Scopes...), nil
+	},
+	"github": func(cfg ProviderConfig) (goth.Provider, error) {
+		return github.New(cfg.ClientID, cfg.ClientSecret, cfg.RedirectURL, cfg.Scopes...), nil
+	},
+	"gitlab": func(cfg ProviderConfig) (goth.Provider, error) {
+		return gitlab.New(cfg.ClientID, cfg.ClientSecret, cfg.RedirectURL, cfg.Scopes...), nil
+	},
+	"microsoft": func(cfg ProviderConfig) (goth.Provider, error) {
+		opts := azureadv2.ProviderOptions{Tenant: azureadv2.TenantType(cfg.Tenant)}
+		for _, scope := range cfg.Scopes {
+			opts.Scopes = append(opts.Scopes, azureadv2.ScopeType(scope))
+		}
+		return azureadv2.New(cfg.ClientID, cfg.ClientSecret, cfg.RedirectURL, opts), nil
+	},
+	"facebook": func(cfg ProviderConfig) (goth.Provider, error) {
+		return facebook.New(cfg.ClientID, cfg.ClientSecret, cfg.RedirectURL, cfg.Scopes...), nil
+	},
+	"discord": func(cfg ProviderConfig) (goth.Provider, error) {
+		return discord.New(cfg.ClientID, cfg.ClientSecret, cfg.RedirectURL, cfg.Scopes...), nil
+	},
+	ProviderTypeOIDC: func(cfg ProviderConfig) (goth.Provider, error) {
+		if cfg.DiscoveryURL == "" {
+			return nil, errors.New("discovery_url is required")
+		}
+		scopes := cfg.Scopes
+		if len(scopes) == 0 {
+			scopes = []string{"email", "profile"}
+		}
+		return openidConnect.New(cfg.ClientID, cfg.ClientSecret, cfg.RedirectURL, cfg.DiscoveryURL, scopes...)
+	},
+}
+
+// providerName is the format of the keys, they are path values of the routes
+var providerName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// reservedProviders are names of other routes of /v1/auth
+var reservedProviders = map[string]bool{"local": true, "refresh": true, "logout": true, "providers": true, "identities": true, "2fa": true, "tokens": true, "sessions": true}
+
+// ProviderTypes returns the types that can be enabled, sorted
+func ProviderTypes() []string {
+	types := make([]string, 0, len(providerTypes))
+	for name := range providerTypes {
+		types = append(types, name)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// NewProviders creates the goth providers of the configuration, named by their keys
+// The oidc providers fetch their discovery document
+func NewProviders(cfg ProvidersConfig) ([]goth.Provider, error) {
+	names := make([]string, 0, len(cfg))
+	for name := range cfg {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	providers := make([]goth.Provider, 0, len(names))
+	for _, name := range names {
+		provider, err := newProvider(name, cfg[name])
+		if err != nil {
+			return nil, fmt.Errorf("auth provider %s: %w", name, err)
+		}
+		providers = append(providers, provider)
+	}
+
+	return providers, nil
+}
+
+func newProvider(name string, cfg ProviderConfig) (goth.Provider, error) {
+	if !providerName.MatchString(name) || reservedProviders[name] {
+		return nil, errors.New("invalid provider name")
+	}
+
+	kind := cfg.Type
+	if kind == "" {
+		kind = name
+	}
+	factory, ok := providerTypes[kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown provider type %q", kind)
+	}
+
+	if cfg.ClientID == "" || cfg.RedirectURL == "" {
+		return nil, errors.New("client_id and redirect_url are required")
+	}
+
+	provider, err := factory(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	provider.SetName(name)
+	return provider, nil
+}
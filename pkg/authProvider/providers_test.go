@@ -0,0 +1,107 @@
+package authProvider_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/Tomelin/financial-management-backend/pkg/authProvider"
+)
+
+type ProvidersTestSuite struct {
+	suite.Suite
+	discovery *httptest.Server
+}
+
+func (s *ProvidersTestSuite) SetupSuite() {
+	s.discovery = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		base := "http://" + r.Host
+		json.NewEncoder(w).Encode(map[string]any{
+			"issuer":                 base,
+			"authorization_endpoint": base + "/auth",
+			"token_endpoint":         base + "/token",
+			"userinfo_endpoint":      base + "/userinfo",
+			"end_session_endpoint":   base + "/logout",
+		})
+	}))
+}
+
+func (s *ProvidersTestSuite) TearDownSuite() {
+	s.discovery.Close()
+}
+
+func (s *ProvidersTestSuite) provider(kind string) authProvider.ProviderConfig {
+	return authProvider.ProviderConfig{Type: kind, ClientID: "id", ClientSecret: "secret", RedirectURL: "http://localhost/callback"}
+}
+
+func (s *ProvidersTestSuite) TestNewProviders() {
+	keycloak := s.provider(authProvider.ProviderTypeOIDC)
+	keycloak.DiscoveryURL = s.discovery.URL + "/.well-known/openid-configuration"
+
+	providers, err := authProvider.NewProviders(authProvider.ProvidersConfig{
+		"google":    s.provider(""),
+		"github":    s.provider(""),
+		"microsoft": s.provider(""),
+		"work":      s.provider("microsoft"),
+		"keycloak":  keycloak,
+	})
+	s.Require().NoError(err)
+
+	var names []string
+	for _, provider := range providers {
+		names = append(names, provider.Name())
+	}
+	s.Equal([]string{"github", "google", "keycloak", "microsoft", "work"}, names, "the providers are named by their keys")
+
+	session, err := providers[2].BeginAuth("state")
+	s.Require().NoError(err)
+	url, err := session.GetAuthURL()
+	s.Require().NoError(err)
+	s.Contains(url, s.discovery.URL+"/auth")
+}
+
+func (s *ProvidersTestSuite) TestNewProviders_Invalid() {
+	tests := map[string]authProvider.ProvidersConfig{
+		"unknown type":      {"other": s.provider("")},
+		"no client id":      {"google": {RedirectURL: "http://localhost/callback"}},
+		"no redirect url":   {"google": {ClientID: "id"}},
+		"oidc no discovery": {"sso": s.provider(authProvider.ProviderTypeOIDC)},
+		"oidc discovery": {"sso": func() authProvider.ProviderConfig {
+			p := s.provider(authProvider.ProviderTypeOIDC)
+			p.DiscoveryURL = "http://127.0.0.1:1/none"
+			return p
+		}()},
+		"reserved name": {"local": s.provider("google")},
+		"invalid name":  {"Google Login": s.provider("google")},
+	}
+
+	for name, cfg := range tests {
+		_, err := authProvider.NewProviders(cfg)
+		s.Error(err, name)
+	}
+}
+
+func (s *ProvidersTestSuite) TestNewAuthProvider() {
+	provider, err := authProvider.NewAuthProvider(map[string]any{
+		"providers": map[string]any{
+			"google": map[string]any{"client_id": "id", "client_secret": "secret", "redirect_url": "http://localhost/callback", "scopes": []string{"email"}},
+		},
+		"local": map[string]any{"verify_email_url": "http://localhost/verify"},
+	})
+	s.Require().NoError(err)
+	s.True(provider.HasProvider("google"))
+	s.False(provider.HasProvider("github"))
+	s.False(provider.HasProvider("local"))
+	s.Equal([]string{"google"}, provider.Providers())
+
+	cfg, err := authProvider.ParseConfig(map[string]any{"local": map[string]any{"verify_email_url": "http://localhost/verify"}})
+	s.Require().NoError(err)
+	s.Equal("http://localhost/verify", cfg.Local.VerifyEmailURL)
+}
+
+func TestProvidersTestSuite(t *testing.T) {
+	suite.Run(t, new(ProvidersTestSuite))
+}
@@ -0,0 +1,85 @@
+package authProvider
+
+import (
+	"net/url"
+	"strings"
+)
+
+// defaultFrontendURL is the frontend of the development, the default of the redirects
+const defaultFrontendURL = "http://localhost:5173"
+
+// RedirectConfig are the pages of the frontend the auth routes redirect the browser to
+type RedirectConfig struct {
+	// SuccessURL is the page after the login, and after the link of a provider
+	SuccessURL string `json:"success_url"`
+	// SignupURL is the page after the first login of a new user
+	SignupURL string `json:"signup_url"`
+	// TwoFactorURL is the page that asks the code of the second factor, it gets enroll=true when the user has to enroll
+	TwoFactorURL string `json:"two_factor_url"`
+	// ErrorURL is the page of a failed login, it gets the error in the error query parameter
+	// Without it the failures are answered with JSON
+	ErrorURL  string `json:"error_url"`
+	LogoutURL string `json:"logout_url"`
+	// AllowedRedirects are the URLs the redirect_to query parameter may point to
+	// An entry is an origin, e.g. https://app.domain.com, or an origin and a path prefix
+	AllowedRedirects []string `json:"allowed_redirects"`
+}
+
+// WithDefaults returns the configuration with the development frontend in the URLs that are not set
+func (c RedirectConfig) WithDefaults() RedirectConfig {
+	if c.SuccessURL == "" {
+		c.SuccessURL = defaultFrontendURL
+	}
+	if c.SignupURL == "" {
+		c.SignupURL = strings.TrimRight(c.SuccessURL, "/") + "/form"
+	}
+	if c.TwoFactorURL == "" {
+		c.TwoFactorURL = strings.TrimRight(c.SuccessURL, "/") + "/2fa"
+	}
+	if c.LogoutURL == "" {
+		c.LogoutURL = c.SuccessURL
+	}
+	return c
+}
+
+// Allowed reports if the redirect_to target matches an entry of the allowlist
+// Only absolute http and https URLs are accepted, the scheme and the host are compared exactly
+func (c *RedirectConfig) Allowed(target string) bool {
+	to, err := url.Parse(target)
+	if err != nil || !to.IsAbs() || (to.Scheme != "http" && to.Scheme != "https") || to.Host == "" || to.User != nil {
+		return false
+	}
+
+	for _, entry := range c.AllowedRedirects {
+		allowed, err := url.Parse(entry)
+		if err != nil || !strings.EqualFold(allowed.Scheme, to.Scheme) || !strings.EqualFold(allowed.Host, to.Host) {
+			continue
+		}
+
+		prefix := strings.TrimRight(allowed.Path, "/")
+		if prefix == "" || to.Path == prefix || strings.HasPrefix(to.Path, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Redirect returns the target when it is allowed, the fallback otherwise
+func (c *RedirectConfig) Redirect(target, fallback string) string {
+	if target != "" && c.Allowed(target) {
+		return target
+	}
+	return fallback
+}
+
+// WithQuery returns the URL with the query parameter added
+func WithQuery(rawURL, key, value string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	query := u.Query()
+	query.Set(key, value)
+	u.RawQuery = query.Encode()
+	return u.String()
+}
@@ -0,0 +1,288 @@
+package authProvider
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/boj/redistore"
+	"github.com/gin-gonic/contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/securecookie"
+	gorilla "github.com/gorilla/sessions"
+
+	"github.com/Tomelin/financial-management-backend/pkg/cache"
+)
+
+const (
+	// SessionBackendCookie keeps the values of the session in the cookie, signed and optionally encrypted
+	SessionBackendCookie = "cookie"
+	// SessionBackendMemory keeps the values in the process, the cookie has only the id of the session
+	// The sessions are lost at a restart and are not shared by the instances
+	SessionBackendMemory = "memory"
+	// SessionBackendRedis keeps the values in a Redis compatible server, the cookie has only the id of the session
+	SessionBackendRedis = "redis"
+
+	// defaultSessionMemorySize is the number of sessions of the memory backend
+	defaultSessionMemorySize = 10000
+	// defaultRedisPoolSize is the number of idle connections to the Redis server
+	defaultRedisPoolSize = 10
+)
+
+// CookieConfig are the attributes of the cookies of the auth routes, of the session and of the tokens
+type CookieConfig struct {
+	Domain string `json:"domain"`
+	// SameSite is lax (default), strict or none, none requires secure cookies
+	SameSite string `json:"same_site"`
+	// Secure defaults to true, false only for the development over http
+	Secure *bool `json:"secure"`
+}
+
+// SessionConfig is the store of the sessions of the OAuth logins
+type SessionConfig struct {
+	// Backend is cookie (default), memory or redis
+	Backend string `json:"backend"`
+	// AuthenticationKey signs the cookies, it should have 32 or 64 bytes
+	// Without it a random key is generated at the start, the sessions do not survive a restart
+	AuthenticationKey string `json:"authentication_key"`
+	// EncryptionKey encrypts the cookies when set, it has 16, 24 or 32 bytes
+	EncryptionKey string `json:"encryption_key"`
+	// MaxAge is the lifetime of the sessions in seconds, the default is 30 days
+	MaxAge     int         `json:"max_age"`
+	MemorySize int         `json:"memory_size"`
+	Redis      RedisConfig `json:"redis"`
+}
+
+// RedisConfig is the server of the redis backend
+type RedisConfig struct {
+	Address  string `json:"address"`
+	Password string `json:"password"`
+	// Network is tcp (default) or unix
+	Network  string `json:"network"`
+	PoolSize int    `json:"pool_size"`
+}
+
+// IsSecure reports if the cookies have the Secure attribute
+func (c *CookieConfig) IsSecure() bool {
+	return c == nil || c.Secure == nil || *c.Secure
+}
+
+// SameSiteMode returns the http mode of SameSite
+func (c *CookieConfig) SameSiteMode() http.SameSite {
+	if c == nil {
+		return http.SameSiteLaxMode
+	}
+	switch strings.ToLower(c.SameSite) {
+	case "strict":
+		return http.SameSiteStrictMode
+	case "none":
+		return http.SameSiteNoneMode
+	}
+	return http.SameSiteLaxMode
+}
+
+// Validate checks the SameSite mode
+func (c *CookieConfig) Validate() error {
+	switch strings.ToLower(c.SameSite) {
+	case "", "lax", "strict":
+	case "none":
+		if !c.IsSecure() {
+			return errors.New("cookie same_site none requires secure cookies")
+		}
+	default:
+		return fmt.Errorf("invalid cookie same_site %q", c.SameSite)
+	}
+	return nil
+}
+
+// Set writes a cookie of the path / with the attributes of the configuration, a negative maxAge removes it
+func (c *CookieConfig) Set(ctx *gin.Context, name, value string, maxAge int) {
+	ctx.SetSameSite(c.SameSiteMode())
+	ctx.SetCookie(name, value, maxAge, "/", c.domain(), c.IsSecure(), true)
+}
+
+func (c *CookieConfig) domain() string {
+	if c == nil {
+		return ""
+	}
+	return c.Domain
+}
+
+// options are the gorilla options of the session cookies
+func (c *CookieConfig) options(maxAge int) *gorilla.Options {
+	return &gorilla.Options{
+		Path:     "/",
+		Domain:   c.domain(),
+		MaxAge:   maxAge,
+		Secure:   c.IsSecure(),
+		HttpOnly: true,
+		SameSite: c.SameSiteMode(),
+	}
+}
+
+// NewSessionStore creates the store of the backend of the configuration
+func NewSessionStore(cfg SessionConfig, cookie *CookieConfig) (sessions.Store, error) {
+	if cookie != nil {
+		if err := cookie.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	maxAge := cfg.MaxAge
+	if maxAge <= 0 {
+		maxAge = MaxAge
+	}
+
+	keys, err := cfg.keyPair()
+	if err != nil {
+		return nil, err
+	}
+
+	options := cookie.options(maxAge)
+	switch strings.ToLower(cfg.Backend) {
+	case "", SessionBackendCookie:
+		store := gorilla.NewCookieStore(keys...)
+		store.Options = options
+		store.MaxAge(maxAge)
+		return &sessionStore{Store: store, options: store.Options}, nil
+
+	case SessionBackendMemory:
+		size := cfg.MemorySize
+		if size <= 0 {
+			size = defaultSessionMemorySize
+		}
+		codecs := securecookie.CodecsFromPairs(keys...)
+		for _, codec := range codecs {
+			if c, ok := codec.(*securecookie.SecureCookie); ok {
+				c.MaxAge(maxAge)
+			}
+		}
+		store := &memoryStore{codecs: codecs, options: options, sessions: cache.NewTTL[string, map[any]any](size)}
+		return &sessionStore{Store: store, options: options}, nil
+
+	case SessionBackendRedis:
+		if cfg.Redis.Address == "" {
+			return nil, errors.New("session redis address is required")
+		}
+		network, size := cfg.Redis.Network, cfg.Redis.PoolSize
+		if network == "" {
+			network = "tcp"
+		}
+		if size <= 0 {
+			size = defaultRedisPoolSize
+		}
+		store, err := redistore.NewRediStore(size, network, cfg.Redis.Address, cfg.Redis.Password, keys...)
+		if err != nil {
+			return nil, fmt.Errorf("session redis: %w", err)
+		}
+		store.Options = options
+		store.SetMaxAge(maxAge)
+		return &sessionStore{Store: store, options: store.Options}, nil
+	}
+
+	return nil, fmt.Errorf("unknown session backend %q", cfg.Backend)
+}
+
+// keyPair returns the authentication and the encryption keys of the cookies
+func (cfg SessionConfig) keyPair() ([][]byte, error) {
+	authentication := []byte(cfg.AuthenticationKey)
+	if len(authentication) == 0 {
+		authentication = securecookie.GenerateRandomKey(64)
+	}
+
+	if cfg.EncryptionKey == "" {
+		return [][]byte{authentication}, nil
+	}
+	switch len(cfg.EncryptionKey) {
+	case 16, 24, 32:
+		return [][]byte{authentication, []byte(cfg.EncryptionKey)}, nil
+	}
+	return nil, errors.New("session encryption_key has 16, 24 or 32 bytes")
+}
+
+// sessionStore adapts a gorilla store to the gin sessions
+// Options replaces the options of the store and keeps the SameSite of the configuration
+type sessionStore struct {
+	gorilla.Store
+	options *gorilla.Options
+}
+
+func (s *sessionStore) Options(options sessions.Options) {
+	*s.options = gorilla.Options{
+		Path:     options.Path,
+		Domain:   options.Domain,
+		MaxAge:   options.MaxAge,
+		Secure:   options.Secure,
+		HttpOnly: options.HttpOnly,
+		SameSite: s.options.SameSite,
+	}
+}
+
+// memoryStore keeps the values of the sessions in the process, the cookie has the signed id of the session
+type memoryStore struct {
+	codecs   []securecookie.Codec
+	options  *gorilla.Options
+	sessions *cache.TTL[string, map[any]any]
+}
+
+func (s *memoryStore) Get(r *http.Request, name string) (*gorilla.Session, error) {
+	return gorilla.GetRegistry(r).Get(s, name)
+}
+
+// New returns the session of the cookie, a new session when the cookie is missing, invalid or expired
+func (s *memoryStore) New(r *http.Request, name string) (*gorilla.Session, error) {
+	session := gorilla.NewSession(s, name)
+	options := *s.options
+	session.Options = &options
+	session.IsNew = true
+
+	cookie, err := r.Cookie(name)
+	if err != nil {
+		return session, nil
+	}
+	if err := securecookie.DecodeMulti(name, cookie.Value, &session.ID, s.codecs...); err != nil {
+		return session, err
+	}
+
+	if values, ok := s.sessions.Get(session.ID); ok {
+		for key, value := range values {
+			session.Values[key] = value
+		}
+		session.IsNew = false
+	}
+	return session, nil
+}
+
+// Save keeps a copy of the values, a MaxAge lower than 1 removes the session
+func (s *memoryStore) Save(r *http.Request, w http.ResponseWriter, session *gorilla.Session) error {
+	if session.Options.MaxAge <= 0 {
+		s.sessions.Delete(session.ID)
+		http.SetCookie(w, gorilla.NewCookie(session.Name(), "", session.Options))
+		return nil
+	}
+
+	if session.ID == "" {
+		b := make([]byte, 32)
+		if _, err := rand.Read(b); err != nil {
+			return err
+		}
+		session.ID = strings.TrimRight(base32.StdEncoding.EncodeToString(b), "=")
+	}
+
+	values := make(map[any]any, len(session.Values))
+	for key, value := range session.Values {
+		values[key] = value
+	}
+	s.sessions.Set(session.ID, values, time.Duration(session.Options.MaxAge)*time.Second)
+
+	encoded, err := securecookie.EncodeMulti(session.Name(), session.ID, s.codecs...)
+	if err != nil {
+		return err
+	}
+	http.SetCookie(w, gorilla.NewCookie(session.Name(), encoded, session.Options))
+	return nil
+}
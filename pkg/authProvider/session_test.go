@@ -0,0 +1,125 @@
+package authProvider_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/Tomelin/financial-management-backend/pkg/authProvider"
+)
+
+type SessionTestSuite struct {
+	suite.Suite
+}
+
+func (s *SessionTestSuite) TestRedirects() {
+	redirects := authProvider.RedirectConfig{
+		SuccessURL:       "https://app.domain.com/home",
+		AllowedRedirects: []string{"https://app.domain.com", "https://admin.domain.com/panel/"},
+	}.WithDefaults()
+	s.Equal("https://app.domain.com/home/form", redirects.SignupURL)
+	s.Equal("https://app.domain.com/home/2fa", redirects.TwoFactorURL)
+	s.Equal("https://app.domain.com/home", redirects.LogoutURL)
+	s.Empty(redirects.ErrorURL, "without an error page the failures are answered with JSON")
+
+	tests := map[string]bool{
+		"https://app.domain.com":                   true,
+		"https://app.domain.com/wallets?id=1":      true,
+		"https://admin.domain.com/panel":           true,
+		"https://admin.domain.com/panel/users":     true,
+		"https://admin.domain.com/panelx":          false,
+		"https://admin.domain.com/":                false,
+		"http://app.domain.com":                    false,
+		"https://app.domain.com.evil.com":          false,
+		"https://user@app.domain.com":              false,
+		"//app.domain.com/home":                    false,
+		"/home":                                    false,
+		"javascript:alert(1)":                      false,
+		"https://evil.com/?https://app.domain.com": false,
+	}
+	for target, allowed := range tests {
+		s.Equal(allowed, redirects.Allowed(target), target)
+	}
+
+	s.Equal("https://app.domain.com/wallets", redirects.Redirect("https://app.domain.com/wallets", redirects.SuccessURL))
+	s.Equal(redirects.SuccessURL, redirects.Redirect("https://evil.com", redirects.SuccessURL))
+	s.Equal(redirects.SuccessURL, redirects.Redirect("", redirects.SuccessURL))
+	s.Equal("https://app.domain.com/error?error=denied&lang=pt", authProvider.WithQuery("https://app.domain.com/error?lang=pt", "error", "denied"))
+	s.Equal("http://localhost:5173", authProvider.RedirectConfig{}.WithDefaults().SuccessURL)
+}
+
+func (s *SessionTestSuite) TestCookieConfig() {
+	var cookies *authProvider.CookieConfig
+	s.True(cookies.IsSecure(), "the cookies are secure by default")
+	s.Equal(http.SameSiteLaxMode, cookies.SameSiteMode())
+
+	insecure := false
+	s.NoError((&authProvider.CookieConfig{SameSite: "Strict"}).Validate())
+	s.NoError((&authProvider.CookieConfig{SameSite: "none"}).Validate())
+	s.Error((&authProvider.CookieConfig{SameSite: "none", Secure: &insecure}).Validate(), "none requires secure cookies")
+	s.Error((&authProvider.CookieConfig{SameSite: "other"}).Validate())
+}
+
+// roundTrip saves a value in a session and reads it back with the cookie of the response
+func (s *SessionTestSuite) roundTrip(cfg authProvider.SessionConfig, cookies *authProvider.CookieConfig) *http.Cookie {
+	store, err := authProvider.NewSessionStore(cfg, cookies)
+	s.Require().NoError(err)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	session, err := store.New(r, "Authorization")
+	s.Require().NoError(err)
+	session.Values["redirect_to"] = "https://app.domain.com"
+	s.Require().NoError(session.Save(r, w))
+
+	response := w.Result()
+	s.Require().Len(response.Cookies(), 1)
+	cookie := response.Cookies()[0]
+
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(cookie)
+	session, err = store.New(r, "Authorization")
+	s.Require().NoError(err)
+	s.False(session.IsNew)
+	s.Equal("https://app.domain.com", session.Values["redirect_to"])
+
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(&http.Cookie{Name: "Authorization", Value: cookie.Value + "x"})
+	session, _ = store.New(r, "Authorization")
+	s.True(session.IsNew, "a tampered cookie starts a new session")
+	return cookie
+}
+
+func (s *SessionTestSuite) TestNewSessionStore() {
+	cookies := &authProvider.CookieConfig{Domain: "domain.com", SameSite: "strict"}
+	cookie := s.roundTrip(authProvider.SessionConfig{AuthenticationKey: "0123456789abcdef0123456789abcdef"}, cookies)
+	s.Equal("domain.com", cookie.Domain)
+	s.Equal(http.SameSiteStrictMode, cookie.SameSite)
+	s.True(cookie.Secure)
+	s.True(cookie.HttpOnly)
+
+	s.roundTrip(authProvider.SessionConfig{Backend: authProvider.SessionBackendCookie, EncryptionKey: "0123456789abcdef"}, nil)
+	cookie = s.roundTrip(authProvider.SessionConfig{Backend: authProvider.SessionBackendMemory, MaxAge: 60}, nil)
+	s.Equal(60, cookie.MaxAge)
+}
+
+func (s *SessionTestSuite) TestNewSessionStore_Invalid() {
+	tests := map[string]authProvider.SessionConfig{
+		"unknown backend":  {Backend: "file"},
+		"encryption key":   {EncryptionKey: "short"},
+		"no redis address": {Backend: authProvider.SessionBackendRedis},
+	}
+	for name, cfg := range tests {
+		_, err := authProvider.NewSessionStore(cfg, nil)
+		s.Error(err, name)
+	}
+
+	_, err := authProvider.NewSessionStore(authProvider.SessionConfig{}, &authProvider.CookieConfig{SameSite: "other"})
+	s.Error(err)
+}
+
+func TestSessionTestSuite(t *testing.T) {
+	suite.Run(t, new(SessionTestSuite))
+}
@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// TTL is an in-process cache whose entries expire after their own TTL
+// It is safe for concurrent use, when it is full the expired entries are dropped first
+type TTL[K comparable, V any] struct {
+	mu      sync.Mutex
+	entries map[K]entry[V]
+	size    int
+	now     func() time.Time
+}
+
+type entry[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+// NewTTL creates a cache of at most size entries, a size lower than 1 is unbounded
+func NewTTL[K comparable, V any](size int) *TTL[K, V] {
+	return &TTL[K, V]{
+		entries: make(map[K]entry[V]),
+		size:    size,
+		now:     time.Now,
+	}
+}
+
+// Get returns the value of the key when it is cached and not expired
+func (c *TTL[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	if !c.now().Before(e.expiresAt) {
+		delete(c.entries, key)
+		var zero V
+		return zero, false
+	}
+	return e.value, true
+}
+
+// Set caches the value of the key for the ttl, a ttl lower than 1 removes the key
+func (c *TTL[K, V]) Set(key K, value V, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if ttl <= 0 {
+		delete(c.entries, key)
+		return
+	}
+
+	if _, ok := c.entries[key]; !ok && c.size > 0 && len(c.entries) >= c.size {
+		c.evict()
+	}
+	c.entries[key] = entry[V]{value: value, expiresAt: c.now().Add(ttl)}
+}
+
+// Delete removes the key of the cache
+func (c *TTL[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+}
+
+// Len returns the number of entries, the expired ones not dropped yet included
+func (c *TTL[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// evict drops the expired entries, or one entry when none expired
+func (c *TTL[K, V]) evict() {
+	now := c.now()
+	for key, e := range c.entries {
+		if !now.Before(e.expiresAt) {
+			delete(c.entries, key)
+		}
+	}
+	if len(c.entries) < c.size {
+		return
+	}
+	for key := range c.entries {
+		delete(c.entries, key)
+		return
+	}
+}
@@ -0,0 +1,71 @@
+package cache_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/Tomelin/financial-management-backend/pkg/cache"
+)
+
+type TTLTestSuite struct {
+	suite.Suite
+}
+
+func (s *TTLTestSuite) TestSetAndGet() {
+	c := cache.NewTTL[string, bool](0)
+	c.Set("a", true, time.Minute)
+
+	value, ok := c.Get("a")
+	s.True(ok)
+	s.True(value)
+
+	_, ok = c.Get("b")
+	s.False(ok)
+
+	c.Delete("a")
+	_, ok = c.Get("a")
+	s.False(ok)
+}
+
+func (s *TTLTestSuite) TestExpiry() {
+	c := cache.NewTTL[string, int](0)
+	c.Set("a", 1, 10*time.Millisecond)
+	c.Set("b", 2, 0)
+
+	_, ok := c.Get("b")
+	s.False(ok, "a ttl lower than 1 is not cached")
+
+	s.Eventually(func() bool {
+		_, ok := c.Get("a")
+		return !ok
+	}, time.Second, 5*time.Millisecond)
+	s.Equal(0, c.Len())
+}
+
+func (s *TTLTestSuite) TestSize() {
+	c := cache.NewTTL[int, int](2)
+	c.Set(1, 1, time.Millisecond)
+	c.Set(2, 2, time.Minute)
+	time.Sleep(5 * time.Millisecond)
+
+	// the expired entry is dropped first
+	c.Set(3, 3, time.Minute)
+	s.Equal(2, c.Len())
+	_, ok := c.Get(2)
+	s.True(ok)
+
+	c.Set(4, 4, time.Minute)
+	s.Equal(2, c.Len())
+	_, ok = c.Get(4)
+	s.True(ok)
+
+	// updating a key does not evict
+	c.Set(4, 5, time.Minute)
+	s.Equal(2, c.Len())
+}
+
+func TestTTLTestSuite(t *testing.T) {
+	suite.Run(t, new(TTLTestSuite))
+}
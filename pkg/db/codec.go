@@ -0,0 +1,376 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// DocumentValuer is implemented by types that are stored with a different value in the document
+// e.g. a money amount that is stored as a decimal number
+type DocumentValuer interface {
+	DocumentValue() (any, error)
+}
+
+// DocumentScanner is implemented by types that are read from the value stored in the document
+type DocumentScanner interface {
+	ScanDocumentValue(value any) error
+}
+
+// DocumentLoader is implemented by structs that must be completed after all fields were read
+type DocumentLoader interface {
+	AfterDocumentLoad() error
+}
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	valuerType  = reflect.TypeOf((*DocumentValuer)(nil)).Elem()
+	scannerType = reflect.TypeOf((*DocumentScanner)(nil)).Elem()
+	loaderType  = reflect.TypeOf((*DocumentLoader)(nil)).Elem()
+)
+
+// Encode converts a struct into the map stored in the document
+// The field names come from the firestore tag, like the Firestore client does
+func Encode(v any) (map[string]any, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil, errors.New("cannot encode a nil value")
+		}
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() == reflect.Map {
+		value, err := encodeValue(rv)
+		if err != nil {
+			return nil, err
+		}
+		data, _ := value.(map[string]any)
+		return data, nil
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("cannot encode %s as a document", rv.Type())
+	}
+
+	return encodeStruct(rv)
+}
+
+// Decode converts the map stored in the document into dst, honoring the DocumentScanner and DocumentLoader hooks
+// dst must be a pointer to a struct or to a map
+func Decode(data map[string]any, dst any) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return errors.New("destination must be a non nil pointer")
+	}
+
+	return decodeValue(data, rv.Elem())
+}
+
+type fieldInfo struct {
+	name      string
+	index     []int
+	omitEmpty bool
+}
+
+// fields returns the document fields of the struct type, with the embedded structs flattened
+func fields(t reflect.Type) []fieldInfo {
+	var result []fieldInfo
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("firestore")
+		if tag == "-" {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				for _, inner := range fields(ft) {
+					inner.index = append([]int{i}, inner.index...)
+					result = append(result, inner)
+				}
+				continue
+			}
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		result = append(result, fieldInfo{
+			name:      name,
+			index:     []int{i},
+			omitEmpty: strings.Contains(opts, "omitempty"),
+		})
+	}
+	return result
+}
+
+func encodeStruct(rv reflect.Value) (map[string]any, error) {
+	data := make(map[string]any)
+	for _, f := range fields(rv.Type()) {
+		fv, ok := fieldByIndex(rv, f.index, false)
+		if !ok {
+			continue
+		}
+
+		if f.omitEmpty && fv.IsZero() {
+			continue
+		}
+
+		value, err := encodeValue(fv)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.name, err)
+		}
+		data[f.name] = value
+	}
+	return data, nil
+}
+
+func encodeValue(rv reflect.Value) (any, error) {
+	if !rv.IsValid() {
+		return nil, nil
+	}
+
+	if rv.Type().Implements(valuerType) {
+		if rv.Kind() == reflect.Pointer && rv.IsNil() {
+			return nil, nil
+		}
+		return rv.Interface().(DocumentValuer).DocumentValue()
+	}
+	if rv.CanAddr() && rv.Addr().Type().Implements(valuerType) {
+		return rv.Addr().Interface().(DocumentValuer).DocumentValue()
+	}
+
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		return encodeValue(rv.Elem())
+	case reflect.Struct:
+		if rv.Type() == timeType {
+			return rv.Interface(), nil
+		}
+		return encodeStruct(rv)
+	case reflect.Slice:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return rv.Bytes(), nil
+		}
+		fallthrough
+	case reflect.Array:
+		list := make([]any, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			value, err := encodeValue(rv.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			list[i] = value
+		}
+		return list, nil
+	case reflect.Map:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		if rv.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("map key must be a string, got %s", rv.Type().Key())
+		}
+		data := make(map[string]any, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			value, err := encodeValue(iter.Value())
+			if err != nil {
+				return nil, err
+			}
+			data[iter.Key().String()] = value
+		}
+		return data, nil
+	case reflect.String:
+		return rv.String(), nil
+	case reflect.Bool:
+		return rv.Bool(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), nil
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return int64(rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), nil
+	}
+
+	return nil, fmt.Errorf("cannot encode value of type %s", rv.Type())
+}
+
+func decodeStruct(data map[string]any, rv reflect.Value) error {
+	for _, f := range fields(rv.Type()) {
+		value, ok := data[f.name]
+		if !ok {
+			continue
+		}
+
+		fv, _ := fieldByIndex(rv, f.index, true)
+		if err := decodeValue(value, fv); err != nil {
+			return fmt.Errorf("%s: %w", f.name, err)
+		}
+	}
+
+	if rv.CanAddr() && rv.Addr().Type().Implements(loaderType) {
+		return rv.Addr().Interface().(DocumentLoader).AfterDocumentLoad()
+	}
+
+	return nil
+}
+
+func decodeValue(value any, rv reflect.Value) error {
+
+	if rv.CanAddr() && rv.Addr().Type().Implements(scannerType) {
+		return rv.Addr().Interface().(DocumentScanner).ScanDocumentValue(value)
+	}
+
+	if value == nil {
+		rv.Set(reflect.Zero(rv.Type()))
+		return nil
+	}
+
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return decodeValue(value, rv.Elem())
+	}
+
+	if rv.Kind() == reflect.Interface && rv.NumMethod() == 0 {
+		rv.Set(reflect.ValueOf(value))
+		return nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Struct:
+		if rv.Type() == timeType {
+			t, ok := value.(time.Time)
+			if !ok {
+				return fmt.Errorf("cannot decode %T into time", value)
+			}
+			rv.Set(reflect.ValueOf(t))
+			return nil
+		}
+		data, ok := value.(map[string]any)
+		if !ok {
+			return fmt.Errorf("cannot decode %T into %s", value, rv.Type())
+		}
+		return decodeStruct(data, rv)
+	case reflect.Slice:
+		if b, ok := value.([]byte); ok && rv.Type().Elem().Kind() == reflect.Uint8 {
+			rv.SetBytes(append([]byte(nil), b...))
+			return nil
+		}
+		list, ok := value.([]any)
+		if !ok {
+			return fmt.Errorf("cannot decode %T into %s", value, rv.Type())
+		}
+		slice := reflect.MakeSlice(rv.Type(), len(list), len(list))
+		for i, item := range list {
+			if err := decodeValue(item, slice.Index(i)); err != nil {
+				return err
+			}
+		}
+		rv.Set(slice)
+		return nil
+	case reflect.Map:
+		data, ok := value.(map[string]any)
+		if !ok {
+			return fmt.Errorf("cannot decode %T into %s", value, rv.Type())
+		}
+		m := reflect.MakeMapWithSize(rv.Type(), len(data))
+		for k, item := range data {
+			elem := reflect.New(rv.Type().Elem()).Elem()
+			if err := decodeValue(item, elem); err != nil {
+				return err
+			}
+			m.SetMapIndex(reflect.ValueOf(k).Convert(rv.Type().Key()), elem)
+		}
+		rv.Set(m)
+		return nil
+	case reflect.String:
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("cannot decode %T into string", value)
+		}
+		rv.SetString(s)
+		return nil
+	case reflect.Bool:
+		b, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("cannot decode %T into bool", value)
+		}
+		rv.SetBool(b)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		switch n := value.(type) {
+		case int64:
+			rv.SetInt(n)
+		case int:
+			rv.SetInt(int64(n))
+		case float64:
+			if n != float64(int64(n)) {
+				return fmt.Errorf("cannot decode %v into %s", n, rv.Type())
+			}
+			rv.SetInt(int64(n))
+		default:
+			return fmt.Errorf("cannot decode %T into %s", value, rv.Type())
+		}
+		return nil
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint:
+		n, ok := value.(int64)
+		if !ok || n < 0 {
+			return fmt.Errorf("cannot decode %v into %s", value, rv.Type())
+		}
+		rv.SetUint(uint64(n))
+		return nil
+	case reflect.Float32, reflect.Float64:
+		switch n := value.(type) {
+		case float64:
+			rv.SetFloat(n)
+		case int64:
+			rv.SetFloat(float64(n))
+		case int:
+			rv.SetFloat(float64(n))
+		default:
+			return fmt.Errorf("cannot decode %T into %s", value, rv.Type())
+		}
+		return nil
+	}
+
+	return fmt.Errorf("cannot decode into %s", rv.Type())
+}
+
+// fieldByIndex walks the index of a promoted field
+// When alloc is true the nil embedded pointers are allocated, otherwise the field is skipped
+func fieldByIndex(rv reflect.Value, index []int, alloc bool) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && rv.Kind() == reflect.Pointer {
+			if rv.IsNil() {
+				if !alloc {
+					return reflect.Value{}, false
+				}
+				rv.Set(reflect.New(rv.Type().Elem()))
+			}
+			rv = rv.Elem()
+		}
+		rv = rv.Field(x)
+	}
+	return rv, true
+}
@@ -0,0 +1,80 @@
+package db_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Tomelin/financial-management-backend/internal/core/entity"
+	"github.com/Tomelin/financial-management-backend/pkg/db"
+	"github.com/stretchr/testify/suite"
+)
+
+type CodecTestSuite struct {
+	suite.Suite
+}
+
+func (s *CodecTestSuite) TestEncodeDecode_Wallet() {
+	now := time.Now().UTC()
+	wallet := &entity.WalletResponse{
+		ID:                "0193f1f4-5d3a-7d3c-8a5e-3f1b2c4d5e6f",
+		Name:              "MyWallet",
+		Balance:           entity.NewMoney(1234, "BRL"),
+		Currency:          "BRL",
+		SharedWithTenants: []string{"tenant"},
+		CreatedAt:         now,
+	}
+
+	data, err := db.Encode(wallet)
+	s.Nil(err)
+	s.Equal(12.34, data["balance"])
+	s.Equal("BRL", data["currency"])
+	s.Equal(now, data["created_at"])
+	s.Equal([]any{"tenant"}, data["shared_with_tenants"])
+	s.NotContains(data, "mu")
+
+	var decoded entity.WalletResponse
+	s.Nil(db.Decode(data, &decoded))
+	s.Equal(entity.NewMoney(1234, "BRL"), decoded.Balance)
+	s.Equal(wallet.SharedWithTenants, decoded.SharedWithTenants)
+	s.Equal(now, decoded.CreatedAt)
+}
+
+func (s *CodecTestSuite) TestEncodeDecode_EmbeddedStruct() {
+	user := &entity.AccountUser{
+		ID:    "id",
+		Roles: []entity.AccountRoles{{Key: "wallet", Value: "read"}},
+		User:  entity.User{Name: "name", Email: "user@domain.com"},
+	}
+
+	data, err := db.Encode(user)
+	s.Nil(err)
+	s.Equal("user@domain.com", data["email"])
+	s.Equal([]any{map[string]any{"key": "wallet", "name": "", "vale": "read"}}, data["roles"])
+
+	var decoded entity.AccountUser
+	s.Nil(db.Decode(data, &decoded))
+	s.Equal(user.User, decoded.User)
+	s.Equal(user.Roles, decoded.Roles)
+}
+
+func (s *CodecTestSuite) TestDecode_NestedLoader() {
+	data := map[string]any{
+		"id":   "tenant",
+		"plan": map[string]any{"name": "gold", "price": 49.9},
+	}
+
+	var tenant entity.TenantResponse
+	s.Nil(db.Decode(data, &tenant))
+	s.Equal(entity.NewMoney(4990, "BRL"), tenant.Plan.Price)
+	s.Equal("BRL", tenant.Plan.Currency)
+}
+
+func (s *CodecTestSuite) TestDecode_Error() {
+	var wallet entity.WalletResponse
+	s.NotNil(db.Decode(map[string]any{"name": 10}, &wallet))
+	s.NotNil(db.Decode(map[string]any{}, map[string]any{}))
+}
+
+func TestRunCodecTestSuite(t *testing.T) {
+	suite.Run(t, new(CodecTestSuite))
+}
@@ -3,11 +3,16 @@ package db
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"log"
+	"reflect"
 
 	"cloud.google.com/go/firestore"
+	"cloud.google.com/go/firestore/apiv1/firestorepb"
 	firebase "firebase.google.com/go/v4"
 	"google.golang.org/api/option"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type FirebaseDatabaseConfig struct {
@@ -29,13 +34,21 @@ type FirebaseDatabaseClient struct {
 	Client *firestore.Client
 }
 
-type FirebaseDatabaseInterface interface {
-	Close()
-	Collection(name string) *firestore.CollectionRef
-	Documents(ctx context.Context, name string) *firestore.DocumentIterator
-}
+// NewFirebaseDatabaseConnection connects to the database of the kind
+// "firebase" connects to Firestore and "memory" creates an in-memory store for local development and tests,
+// both are a DocumentStore. "sqlite" and "postgres" open a *SQLDatabase with the schema migrated.
+func NewFirebaseDatabaseConnection(ctx context.Context, fields any, kind string) (Database, error) {
 
-func NewFirebaseDatabaseConnection(ctx context.Context, fields any, kind string) (FirebaseDatabaseInterface, error) {
+	switch kind {
+	case KindMemory:
+		return NewMemoryStore(), nil
+	case KindSQLite, KindPostgres:
+		database, err := NewSQLDatabase(ctx, fields, kind)
+		if err != nil {
+			return nil, err
+		}
+		return database, nil
+	}
 
 	fbConfig, err := parseConfig(fields)
 	if err != nil {
@@ -66,16 +79,324 @@ func NewFirebaseDatabaseConnection(ctx context.Context, fields any, kind string)
 	return &query, nil
 }
 
-func (fbd *FirebaseDatabaseClient) Close() {
-	fbd.Client.Close()
+func (fbd *FirebaseDatabaseClient) Close() error {
+	return fbd.Client.Close()
+}
+
+func (fbd *FirebaseDatabaseClient) Collection(name string) CollectionRef {
+	return newFirestoreCollection(fbd.Client.Collection(name))
+}
+
+// RunTransaction runs f in a Firestore transaction
+// f may be called more than once when the transaction is retried by a concurrent write
+func (fbd *FirebaseDatabaseClient) RunTransaction(ctx context.Context, f func(context.Context, Transaction) error) error {
+	err := fbd.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		return f(ctx, &firestoreTransaction{tx: tx})
+	})
+	return firestoreError(err)
+}
+
+type firestoreQuery struct {
+	coll  *firestore.CollectionRef
+	query firestore.Query
+	err   error
+}
+
+func (q *firestoreQuery) Where(path, op string, value any) Query {
+	return q.WhereFilter(PropertyFilter{Path: path, Operator: op, Value: value})
+}
+
+// WhereFilter adds the filter as a Firestore entity filter, OR filters are composite OrFilter
+// Firestore returns each document once, even when it matches more than one branch
+func (q *firestoreQuery) WhereFilter(filter Filter) Query {
+	if q.err != nil {
+		return q
+	}
+
+	ef, err := q.entityFilter(filter)
+	if err != nil {
+		return &firestoreQuery{coll: q.coll, query: q.query, err: err}
+	}
+
+	return &firestoreQuery{coll: q.coll, query: q.query.WhereEntity(ef)}
+}
+
+func (q *firestoreQuery) entityFilter(filter Filter) (firestore.EntityFilter, error) {
+	switch f := filter.(type) {
+	case PropertyFilter:
+		if !validOperator(f.Operator) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidOperator, f.Operator)
+		}
+		value := f.Value
+		if f.Path == DocumentID {
+			value = q.docRefs(value)
+		}
+		return firestore.PropertyFilter{Path: f.Path, Operator: f.Operator, Value: value}, nil
+	case AndFilter:
+		filters, err := q.entityFilters(f.Filters)
+		if err != nil {
+			return nil, err
+		}
+		return firestore.AndFilter{Filters: filters}, nil
+	case OrFilter:
+		filters, err := q.entityFilters(f.Filters)
+		if err != nil {
+			return nil, err
+		}
+		return firestore.OrFilter{Filters: filters}, nil
+	}
+	return nil, fmt.Errorf("%w: %T", ErrInvalidFilter, filter)
+}
+
+func (q *firestoreQuery) entityFilters(filters []Filter) ([]firestore.EntityFilter, error) {
+	if len(filters) == 0 {
+		return nil, fmt.Errorf("%w: empty composite filter", ErrInvalidFilter)
+	}
+
+	result := make([]firestore.EntityFilter, len(filters))
+	for i, f := range filters {
+		ef, err := q.entityFilter(f)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = ef
+	}
+	return result, nil
+}
+
+func (q *firestoreQuery) OrderBy(path string, dir Direction) Query {
+	if q.err != nil {
+		return q
+	}
+
+	direction := firestore.Asc
+	if dir == Desc {
+		direction = firestore.Desc
+	}
+
+	return &firestoreQuery{coll: q.coll, query: q.query.OrderBy(path, direction)}
+}
+
+func (q *firestoreQuery) Limit(n int) Query {
+	if q.err != nil {
+		return q
+	}
+	return &firestoreQuery{coll: q.coll, query: q.query.Limit(n)}
+}
+
+func (q *firestoreQuery) StartAfter(values ...any) Query {
+	if q.err != nil {
+		return q
+	}
+	return &firestoreQuery{coll: q.coll, query: q.query.StartAfter(values...)}
+}
+
+// Count runs a count aggregation, Firestore bills one read for each batch of up to 1000 documents
+func (q *firestoreQuery) Count(ctx context.Context) (int64, error) {
+	if q.err != nil {
+		return 0, q.err
+	}
+
+	result, err := q.query.NewAggregationQuery().WithCount("count").Get(ctx)
+	if err != nil {
+		return 0, firestoreError(err)
+	}
+
+	count, ok := result["count"].(*firestorepb.Value)
+	if !ok {
+		return 0, fmt.Errorf("unexpected count result %T", result["count"])
+	}
+	return count.GetIntegerValue(), nil
+}
+
+func (q *firestoreQuery) Documents(ctx context.Context) ([]*Document, error) {
+	if q.err != nil {
+		return nil, q.err
+	}
+
+	snaps, err := q.query.Documents(ctx).GetAll()
+	if err != nil {
+		return nil, firestoreError(err)
+	}
+
+	return firestoreDocuments(snaps), nil
+}
+
+// docRefs converts document IDs into references, Firestore filters the document ID by reference
+func (q *firestoreQuery) docRefs(value any) any {
+	switch v := value.(type) {
+	case string:
+		return q.coll.Doc(v)
+	case []string:
+		refs := make([]*firestore.DocumentRef, len(v))
+		for i, id := range v {
+			refs[i] = q.coll.Doc(id)
+		}
+		return refs
+	}
+	return value
+}
+
+type firestoreCollection struct {
+	*firestoreQuery
+}
+
+func newFirestoreCollection(coll *firestore.CollectionRef) *firestoreCollection {
+	return &firestoreCollection{firestoreQuery: &firestoreQuery{coll: coll, query: coll.Query}}
+}
+
+func (c *firestoreCollection) Doc(id string) DocumentRef {
+	return &firestoreDocRef{ref: c.coll.Doc(id)}
 }
 
-func (fbd *FirebaseDatabaseClient) Collection(name string) *firestore.CollectionRef {
-	return fbd.Client.Collection(name)
+type firestoreDocRef struct {
+	ref *firestore.DocumentRef
 }
 
-func (fbd *FirebaseDatabaseClient) Documents(ctx context.Context, name string) *firestore.DocumentIterator {
-	return fbd.Client.Collection(name).Documents(ctx)
+func (d *firestoreDocRef) ID() string {
+	return d.ref.ID
+}
+
+func (d *firestoreDocRef) Collection(name string) CollectionRef {
+	return newFirestoreCollection(d.ref.Collection(name))
+}
+
+func (d *firestoreDocRef) Get(ctx context.Context) (*Document, error) {
+	snap, err := d.ref.Get(ctx)
+	if err != nil {
+		return nil, firestoreError(err)
+	}
+	return firestoreDocument(snap), nil
+}
+
+func (d *firestoreDocRef) Set(ctx context.Context, data any) error {
+	document, err := Encode(data)
+	if err != nil {
+		return err
+	}
+
+	_, err = d.ref.Set(ctx, document)
+	return firestoreError(err)
+}
+
+func (d *firestoreDocRef) Update(ctx context.Context, updates ...Update) error {
+	fsUpdates, err := firestoreUpdates(updates)
+	if err != nil {
+		return err
+	}
+
+	_, err = d.ref.Update(ctx, fsUpdates)
+	return firestoreError(err)
+}
+
+func (d *firestoreDocRef) Delete(ctx context.Context) error {
+	_, err := d.ref.Delete(ctx)
+	return firestoreError(err)
+}
+
+type firestoreTransaction struct {
+	tx *firestore.Transaction
+}
+
+func (t *firestoreTransaction) Get(ref DocumentRef) (*Document, error) {
+	r, ok := ref.(*firestoreDocRef)
+	if !ok {
+		return nil, ErrForeignReference
+	}
+
+	snap, err := t.tx.Get(r.ref)
+	if err != nil {
+		return nil, firestoreError(err)
+	}
+	return firestoreDocument(snap), nil
+}
+
+func (t *firestoreTransaction) Documents(q Query) ([]*Document, error) {
+	var fq *firestoreQuery
+	switch v := q.(type) {
+	case *firestoreQuery:
+		fq = v
+	case *firestoreCollection:
+		fq = v.firestoreQuery
+	default:
+		return nil, ErrForeignReference
+	}
+
+	if fq.err != nil {
+		return nil, fq.err
+	}
+
+	snaps, err := t.tx.Documents(fq.query).GetAll()
+	if err != nil {
+		return nil, firestoreError(err)
+	}
+	return firestoreDocuments(snaps), nil
+}
+
+func (t *firestoreTransaction) Set(ref DocumentRef, data any) error {
+	r, ok := ref.(*firestoreDocRef)
+	if !ok {
+		return ErrForeignReference
+	}
+
+	document, err := Encode(data)
+	if err != nil {
+		return err
+	}
+	return t.tx.Set(r.ref, document)
+}
+
+func (t *firestoreTransaction) Update(ref DocumentRef, updates ...Update) error {
+	r, ok := ref.(*firestoreDocRef)
+	if !ok {
+		return ErrForeignReference
+	}
+
+	fsUpdates, err := firestoreUpdates(updates)
+	if err != nil {
+		return err
+	}
+	return t.tx.Update(r.ref, fsUpdates)
+}
+
+func (t *firestoreTransaction) Delete(ref DocumentRef) error {
+	r, ok := ref.(*firestoreDocRef)
+	if !ok {
+		return ErrForeignReference
+	}
+	return t.tx.Delete(r.ref)
+}
+
+func firestoreUpdates(updates []Update) ([]firestore.Update, error) {
+	result := make([]firestore.Update, len(updates))
+	for i, u := range updates {
+		value, err := encodeValue(reflect.ValueOf(u.Value))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", u.Path, err)
+		}
+		result[i] = firestore.Update{Path: u.Path, Value: value}
+	}
+	return result, nil
+}
+
+func firestoreDocument(snap *firestore.DocumentSnapshot) *Document {
+	return &Document{ID: snap.Ref.ID, Data: snap.Data()}
+}
+
+func firestoreDocuments(snaps []*firestore.DocumentSnapshot) []*Document {
+	docs := make([]*Document, 0, len(snaps))
+	for _, snap := range snaps {
+		docs = append(docs, firestoreDocument(snap))
+	}
+	return docs
+}
+
+// firestoreError converts the not found status into ErrNotFound
+func firestoreError(err error) error {
+	if err != nil && status.Code(err) == codes.NotFound {
+		return fmt.Errorf("%w: %s", ErrNotFound, err.Error())
+	}
+	return err
 }
 
 func parseConfig(fields any) (*FirebaseDatabaseConfig, error) {
@@ -0,0 +1,850 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrTransactionConflict is returned when a memory transaction keeps conflicting with concurrent writes
+var ErrTransactionConflict = errors.New("transaction aborted after too many conflicts")
+
+const memoryTransactionAttempts = 10
+
+type memoryRecord struct {
+	data    map[string]any
+	version uint64
+}
+
+// MemoryStore is a thread-safe in-memory DocumentStore
+// It follows the Firestore semantics used by the repositories, so they can be tested without GCP
+type MemoryStore struct {
+	mu          sync.RWMutex
+	clock       uint64
+	collections map[string]map[string]*memoryRecord
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{collections: make(map[string]map[string]*memoryRecord)}
+}
+
+func (m *MemoryStore) Close() error {
+	return nil
+}
+
+func (m *MemoryStore) Collection(name string) CollectionRef {
+	return &memoryCollection{memoryQuery: &memoryQuery{store: m, path: name}}
+}
+
+// RunTransaction runs f with optimistic concurrency
+// The writes are applied only if no document read by f changed in the meantime, otherwise f is retried after a backoff
+func (m *MemoryStore) RunTransaction(ctx context.Context, f func(context.Context, Transaction) error) error {
+	for attempt := 0; attempt < memoryTransactionAttempts; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		tx := &memoryTransaction{store: m, reads: make(map[memoryKey]uint64)}
+		if err := f(ctx, tx); err != nil {
+			return err
+		}
+
+		committed, err := m.commit(tx)
+		if err != nil {
+			return err
+		}
+		if committed {
+			return nil
+		}
+
+		// random backoff, so the writers that conflicted do not collide again
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(rand.IntN(1<<attempt)+1) * time.Millisecond):
+		}
+	}
+
+	return ErrTransactionConflict
+}
+
+type memoryKey struct {
+	path string
+	id   string
+}
+
+func (m *MemoryStore) version(key memoryKey) uint64 {
+	if r, ok := m.collections[key.path][key.id]; ok {
+		return r.version
+	}
+	return 0
+}
+
+func (m *MemoryStore) get(key memoryKey) (*Document, uint64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	r, ok := m.collections[key.path][key.id]
+	if !ok {
+		return nil, 0, fmt.Errorf("%w: %s/%s", ErrNotFound, key.path, key.id)
+	}
+	return &Document{ID: key.id, Data: copyMap(r.data)}, r.version, nil
+}
+
+// apply runs the writes over a copy of the records, so a failed write does not change the store
+// The caller must hold the write lock
+func (m *MemoryStore) apply(writes []memoryWrite) error {
+	staged := make(map[memoryKey]*memoryRecord)
+	for _, w := range writes {
+		current, seen := staged[w.key]
+		if !seen {
+			if r, ok := m.collections[w.key.path][w.key.id]; ok {
+				current = &memoryRecord{data: r.data, version: r.version}
+			}
+		}
+
+		switch {
+		case w.delete:
+			current = nil
+		case w.updates != nil:
+			if current == nil {
+				return fmt.Errorf("%w: %s/%s", ErrNotFound, w.key.path, w.key.id)
+			}
+			data := copyMap(current.data)
+			for _, u := range w.updates {
+				setPath(data, u.Path, u.Value)
+			}
+			current = &memoryRecord{data: data}
+		default:
+			current = &memoryRecord{data: w.data}
+		}
+		staged[w.key] = current
+	}
+
+	for key, r := range staged {
+		if r == nil {
+			delete(m.collections[key.path], key.id)
+			continue
+		}
+		if m.collections[key.path] == nil {
+			m.collections[key.path] = make(map[string]*memoryRecord)
+		}
+		m.clock++
+		r.version = m.clock
+		m.collections[key.path][key.id] = r
+	}
+	return nil
+}
+
+func (m *MemoryStore) write(w memoryWrite) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.apply([]memoryWrite{w})
+}
+
+func (m *MemoryStore) commit(tx *memoryTransaction) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key, version := range tx.reads {
+		if m.version(key) != version {
+			return false, nil
+		}
+	}
+
+	return true, m.apply(tx.writes)
+}
+
+type memoryWrite struct {
+	key     memoryKey
+	data    map[string]any
+	updates []Update
+	delete  bool
+}
+
+func newSetWrite(key memoryKey, data any) (memoryWrite, error) {
+	document, err := Encode(data)
+	if err != nil {
+		return memoryWrite{}, err
+	}
+	if document == nil {
+		document = map[string]any{}
+	}
+	return memoryWrite{key: key, data: copyMap(document)}, nil
+}
+
+func newUpdateWrite(key memoryKey, updates []Update) (memoryWrite, error) {
+	encoded := make([]Update, len(updates))
+	for i, u := range updates {
+		if u.Path == "" {
+			return memoryWrite{}, errors.New("update path cannot be empty")
+		}
+		value, err := encodeValue(reflect.ValueOf(u.Value))
+		if err != nil {
+			return memoryWrite{}, fmt.Errorf("%s: %w", u.Path, err)
+		}
+		encoded[i] = Update{Path: u.Path, Value: copyValue(value)}
+	}
+	return memoryWrite{key: key, updates: encoded}, nil
+}
+
+type memoryOrder struct {
+	path string
+	dir  Direction
+}
+
+type memoryQuery struct {
+	store   *MemoryStore
+	path    string
+	filters []Filter
+	orders  []memoryOrder
+	limit   int
+	after   []any
+	err     error
+}
+
+func (q *memoryQuery) clone() *memoryQuery {
+	c := *q
+	c.filters = append([]Filter(nil), q.filters...)
+	c.orders = append([]memoryOrder(nil), q.orders...)
+	c.after = append([]any(nil), q.after...)
+	return &c
+}
+
+func (q *memoryQuery) Where(path, op string, value any) Query {
+	return q.WhereFilter(PropertyFilter{Path: path, Operator: op, Value: value})
+}
+
+func (q *memoryQuery) WhereFilter(filter Filter) Query {
+	c := q.clone()
+	if c.err != nil {
+		return c
+	}
+
+	encoded, err := encodeFilter(filter)
+	if err != nil {
+		c.err = err
+		return c
+	}
+
+	c.filters = append(c.filters, encoded)
+	return c
+}
+
+func (q *memoryQuery) OrderBy(path string, dir Direction) Query {
+	c := q.clone()
+	c.orders = append(c.orders, memoryOrder{path: path, dir: dir})
+	return c
+}
+
+func (q *memoryQuery) Limit(n int) Query {
+	c := q.clone()
+	c.limit = n
+	return c
+}
+
+func (q *memoryQuery) StartAfter(values ...any) Query {
+	c := q.clone()
+	if c.err != nil {
+		return c
+	}
+
+	c.after = make([]any, len(values))
+	for i, v := range values {
+		encoded, err := encodeValue(reflect.ValueOf(v))
+		if err != nil {
+			c.err = err
+			return c
+		}
+		c.after[i] = encoded
+	}
+	return c
+}
+
+func (q *memoryQuery) Documents(ctx context.Context) ([]*Document, error) {
+	docs, _, err := q.run(ctx)
+	return docs, err
+}
+
+func (q *memoryQuery) Count(ctx context.Context) (int64, error) {
+	docs, _, err := q.run(ctx)
+	return int64(len(docs)), err
+}
+
+// run returns the documents of the query and their versions
+func (q *memoryQuery) run(ctx context.Context) ([]*Document, []uint64, error) {
+	if q.err != nil {
+		return nil, nil, q.err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	if len(q.after) > len(q.orders) {
+		return nil, nil, fmt.Errorf("%w: %d values for %d orders", ErrInvalidCursor, len(q.after), len(q.orders))
+	}
+
+	q.store.mu.RLock()
+	defer q.store.mu.RUnlock()
+
+	type row struct {
+		doc     *Document
+		version uint64
+	}
+
+	var rows []row
+	for id, r := range q.store.collections[q.path] {
+		doc := &Document{ID: id, Data: r.data}
+		if q.match(doc) && q.isAfter(doc) {
+			rows = append(rows, row{doc: doc, version: r.version})
+		}
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		return q.less(rows[i].doc, rows[j].doc)
+	})
+
+	if q.limit > 0 && len(rows) > q.limit {
+		rows = rows[:q.limit]
+	}
+
+	docs := make([]*Document, len(rows))
+	versions := make([]uint64, len(rows))
+	for i, r := range rows {
+		docs[i] = &Document{ID: r.doc.ID, Data: copyMap(r.doc.Data)}
+		versions[i] = r.version
+	}
+	return docs, versions, nil
+}
+
+func (q *memoryQuery) match(doc *Document) bool {
+	for _, f := range q.filters {
+		if !matchDocument(doc, f) {
+			return false
+		}
+	}
+
+	// Firestore only returns the documents that have the ordered fields
+	for _, o := range q.orders {
+		if _, ok := fieldValue(doc, o.path); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (q *memoryQuery) less(a, b *Document) bool {
+	lastDir := Asc
+	for _, o := range q.orders {
+		va, _ := fieldValue(a, o.path)
+		vb, _ := fieldValue(b, o.path)
+		c := compareValues(va, vb)
+		if c == 0 {
+			lastDir = o.dir
+			continue
+		}
+		if o.dir == Desc {
+			return c > 0
+		}
+		return c < 0
+	}
+
+	// ties are ordered by document ID, in the direction of the last order like Firestore
+	if lastDir == Desc {
+		return a.ID > b.ID
+	}
+	return a.ID < b.ID
+}
+
+// isAfter reports if the document comes after the values of StartAfter
+func (q *memoryQuery) isAfter(doc *Document) bool {
+	for i, v := range q.after {
+		value, _ := fieldValue(doc, q.orders[i].path)
+		c := compareValues(value, v)
+		if c == 0 {
+			continue
+		}
+		if q.orders[i].dir == Desc {
+			return c < 0
+		}
+		return c > 0
+	}
+	return len(q.after) == 0
+}
+
+type memoryCollection struct {
+	*memoryQuery
+}
+
+func (c *memoryCollection) Doc(id string) DocumentRef {
+	return &memoryDocRef{store: c.store, key: memoryKey{path: c.path, id: id}}
+}
+
+type memoryDocRef struct {
+	store *MemoryStore
+	key   memoryKey
+}
+
+func (d *memoryDocRef) ID() string {
+	return d.key.id
+}
+
+func (d *memoryDocRef) Collection(name string) CollectionRef {
+	return &memoryCollection{memoryQuery: &memoryQuery{store: d.store, path: d.key.path + "/" + d.key.id + "/" + name}}
+}
+
+func (d *memoryDocRef) Get(ctx context.Context) (*Document, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	doc, _, err := d.store.get(d.key)
+	return doc, err
+}
+
+func (d *memoryDocRef) Set(ctx context.Context, data any) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	w, err := newSetWrite(d.key, data)
+	if err != nil {
+		return err
+	}
+	return d.store.write(w)
+}
+
+func (d *memoryDocRef) Update(ctx context.Context, updates ...Update) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	w, err := newUpdateWrite(d.key, updates)
+	if err != nil {
+		return err
+	}
+	return d.store.write(w)
+}
+
+func (d *memoryDocRef) Delete(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return d.store.write(memoryWrite{key: d.key, delete: true})
+}
+
+type memoryTransaction struct {
+	store  *MemoryStore
+	reads  map[memoryKey]uint64
+	writes []memoryWrite
+}
+
+func (t *memoryTransaction) ref(ref DocumentRef) (*memoryDocRef, error) {
+	r, ok := ref.(*memoryDocRef)
+	if !ok || r.store != t.store {
+		return nil, ErrForeignReference
+	}
+	return r, nil
+}
+
+func (t *memoryTransaction) Get(ref DocumentRef) (*Document, error) {
+	r, err := t.ref(ref)
+	if err != nil {
+		return nil, err
+	}
+	if len(t.writes) > 0 {
+		return nil, ErrReadAfterWrite
+	}
+
+	doc, version, err := t.store.get(r.key)
+	t.reads[r.key] = version
+	return doc, err
+}
+
+func (t *memoryTransaction) Documents(q Query) ([]*Document, error) {
+	var mq *memoryQuery
+	switch v := q.(type) {
+	case *memoryQuery:
+		mq = v
+	case *memoryCollection:
+		mq = v.memoryQuery
+	default:
+		return nil, ErrForeignReference
+	}
+	if mq.store != t.store {
+		return nil, ErrForeignReference
+	}
+	if len(t.writes) > 0 {
+		return nil, ErrReadAfterWrite
+	}
+
+	docs, versions, err := mq.run(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	for i, doc := range docs {
+		t.reads[memoryKey{path: mq.path, id: doc.ID}] = versions[i]
+	}
+	return docs, nil
+}
+
+func (t *memoryTransaction) Set(ref DocumentRef, data any) error {
+	r, err := t.ref(ref)
+	if err != nil {
+		return err
+	}
+	w, err := newSetWrite(r.key, data)
+	if err != nil {
+		return err
+	}
+	t.writes = append(t.writes, w)
+	return nil
+}
+
+func (t *memoryTransaction) Update(ref DocumentRef, updates ...Update) error {
+	r, err := t.ref(ref)
+	if err != nil {
+		return err
+	}
+	w, err := newUpdateWrite(r.key, updates)
+	if err != nil {
+		return err
+	}
+	t.writes = append(t.writes, w)
+	return nil
+}
+
+func (t *memoryTransaction) Delete(ref DocumentRef) error {
+	r, err := t.ref(ref)
+	if err != nil {
+		return err
+	}
+	t.writes = append(t.writes, memoryWrite{key: r.key, delete: true})
+	return nil
+}
+
+// encodeFilter validates the operators and encodes the values of the filter, like Set encodes the documents
+func encodeFilter(filter Filter) (Filter, error) {
+	switch f := filter.(type) {
+	case PropertyFilter:
+		if !validOperator(f.Operator) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidOperator, f.Operator)
+		}
+		encoded, err := encodeValue(reflect.ValueOf(f.Value))
+		if err != nil {
+			return nil, err
+		}
+		return PropertyFilter{Path: f.Path, Operator: f.Operator, Value: encoded}, nil
+	case AndFilter:
+		filters, err := encodeFilters(f.Filters)
+		if err != nil {
+			return nil, err
+		}
+		return AndFilter{Filters: filters}, nil
+	case OrFilter:
+		filters, err := encodeFilters(f.Filters)
+		if err != nil {
+			return nil, err
+		}
+		return OrFilter{Filters: filters}, nil
+	}
+	return nil, fmt.Errorf("%w: %T", ErrInvalidFilter, filter)
+}
+
+func encodeFilters(filters []Filter) ([]Filter, error) {
+	if len(filters) == 0 {
+		return nil, fmt.Errorf("%w: empty composite filter", ErrInvalidFilter)
+	}
+
+	encoded := make([]Filter, len(filters))
+	for i, f := range filters {
+		e, err := encodeFilter(f)
+		if err != nil {
+			return nil, err
+		}
+		encoded[i] = e
+	}
+	return encoded, nil
+}
+
+func matchDocument(doc *Document, filter Filter) bool {
+	switch f := filter.(type) {
+	case PropertyFilter:
+		value, ok := fieldValue(doc, f.Path)
+		return ok && matchFilter(value, f.Operator, f.Value)
+	case AndFilter:
+		for _, child := range f.Filters {
+			if !matchDocument(doc, child) {
+				return false
+			}
+		}
+		return true
+	case OrFilter:
+		for _, child := range f.Filters {
+			if matchDocument(doc, child) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func validOperator(op string) bool {
+	switch op {
+	case OpEqual, OpNotEqual, OpLess, OpLessEqual, OpGreater, OpGreaterEqual,
+		OpIn, OpNotIn, OpArrayContains, OpArrayContainsAny:
+		return true
+	}
+	return false
+}
+
+// fieldValue returns the value of a dotted path, DocumentID returns the document ID
+func fieldValue(doc *Document, path string) (any, bool) {
+	if path == DocumentID {
+		return doc.ID, true
+	}
+
+	var current any = doc.Data
+	for _, part := range strings.Split(path, ".") {
+		m, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		current, ok = m[part]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+func setPath(data map[string]any, path string, value any) {
+	parts := strings.Split(path, ".")
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := data[part].(map[string]any)
+		if !ok {
+			next = make(map[string]any)
+			data[part] = next
+		}
+		data = next
+	}
+	data[parts[len(parts)-1]] = value
+}
+
+func matchFilter(value any, op string, target any) bool {
+	switch op {
+	case OpEqual:
+		return equalValues(value, target)
+	case OpNotEqual:
+		return value != nil && !equalValues(value, target)
+	case OpLess, OpLessEqual, OpGreater, OpGreaterEqual:
+		// Firestore only compares values of the same type
+		if typeRank(value) != typeRank(target) {
+			return false
+		}
+		c := compareValues(value, target)
+		switch op {
+		case OpLess:
+			return c < 0
+		case OpLessEqual:
+			return c <= 0
+		case OpGreater:
+			return c > 0
+		default:
+			return c >= 0
+		}
+	case OpIn:
+		list, _ := target.([]any)
+		for _, item := range list {
+			if equalValues(value, item) {
+				return true
+			}
+		}
+		return false
+	case OpNotIn:
+		if value == nil {
+			return false
+		}
+		list, _ := target.([]any)
+		for _, item := range list {
+			if equalValues(value, item) {
+				return false
+			}
+		}
+		return true
+	case OpArrayContains:
+		list, _ := value.([]any)
+		for _, item := range list {
+			if equalValues(item, target) {
+				return true
+			}
+		}
+		return false
+	case OpArrayContainsAny:
+		list, _ := value.([]any)
+		targets, _ := target.([]any)
+		for _, item := range list {
+			for _, t := range targets {
+				if equalValues(item, t) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	return false
+}
+
+func equalValues(a, b any) bool {
+	if typeRank(a) != typeRank(b) {
+		return false
+	}
+	return compareValues(a, b) == 0
+}
+
+// typeRank is the Firestore ordering of value types
+func typeRank(v any) int {
+	switch v.(type) {
+	case nil:
+		return 0
+	case bool:
+		return 1
+	case int64, float64:
+		return 2
+	case time.Time:
+		return 3
+	case string:
+		return 4
+	case []byte:
+		return 5
+	case []any:
+		return 8
+	case map[string]any:
+		return 9
+	}
+	return 10
+}
+
+// compareValues orders two values like Firestore, first by type and then by value
+func compareValues(a, b any) int {
+	ra, rb := typeRank(a), typeRank(b)
+	if ra != rb {
+		return compareInt(ra, rb)
+	}
+
+	switch va := a.(type) {
+	case nil:
+		return 0
+	case bool:
+		vb := b.(bool)
+		switch {
+		case va == vb:
+			return 0
+		case !va:
+			return -1
+		}
+		return 1
+	case int64, float64:
+		fa, fb := toFloat(a), toFloat(b)
+		switch {
+		case fa < fb:
+			return -1
+		case fa > fb:
+			return 1
+		}
+		return 0
+	case time.Time:
+		return va.Compare(b.(time.Time))
+	case string:
+		return strings.Compare(va, b.(string))
+	case []byte:
+		return bytes.Compare(va, b.([]byte))
+	case []any:
+		vb := b.([]any)
+		for i := 0; i < len(va) && i < len(vb); i++ {
+			if c := compareValues(va[i], vb[i]); c != 0 {
+				return c
+			}
+		}
+		return compareInt(len(va), len(vb))
+	case map[string]any:
+		vb := b.(map[string]any)
+		keys := make([]string, 0, len(va)+len(vb))
+		for k := range va {
+			keys = append(keys, k)
+		}
+		for k := range vb {
+			if _, ok := va[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			xa, oka := va[k]
+			xb, okb := vb[k]
+			if oka != okb {
+				if !oka {
+					return 1
+				}
+				return -1
+			}
+			if c := compareValues(xa, xb); c != 0 {
+				return c
+			}
+		}
+		return 0
+	}
+
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func toFloat(v any) float64 {
+	switch n := v.(type) {
+	case int64:
+		return float64(n)
+	case float64:
+		return n
+	}
+	return 0
+}
+
+func copyMap(data map[string]any) map[string]any {
+	if data == nil {
+		return nil
+	}
+	c := make(map[string]any, len(data))
+	for k, v := range data {
+		c[k] = copyValue(v)
+	}
+	return c
+}
+
+func copyValue(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		return copyMap(val)
+	case []any:
+		c := make([]any, len(val))
+		for i, item := range val {
+			c[i] = copyValue(item)
+		}
+		return c
+	case []byte:
+		return append([]byte(nil), val...)
+	}
+	return v
+}
@@ -0,0 +1,330 @@
+package db_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Tomelin/financial-management-backend/pkg/db"
+	"github.com/stretchr/testify/suite"
+)
+
+type MemoryStoreTestSuite struct {
+	suite.Suite
+	store *db.MemoryStore
+	ctx   context.Context
+}
+
+type memoryItem struct {
+	ID    string   `firestore:"id"`
+	Name  string   `firestore:"name"`
+	Price float64  `firestore:"price"`
+	Tags  []string `firestore:"tags,omitempty"`
+}
+
+func (s *MemoryStoreTestSuite) SetupTest() {
+	s.store = db.NewMemoryStore()
+	s.ctx = context.Background()
+
+	items := []memoryItem{
+		{ID: "a", Name: "apple", Price: 3, Tags: []string{"fruit", "red"}},
+		{ID: "b", Name: "banana", Price: 1, Tags: []string{"fruit"}},
+		{ID: "c", Name: "carrot", Price: 2, Tags: []string{"vegetable"}},
+		{ID: "d", Name: "donut", Price: 2},
+	}
+	for _, item := range items {
+		s.Require().Nil(s.store.Collection("items").Doc(item.ID).Set(s.ctx, item))
+	}
+}
+
+func (s *MemoryStoreTestSuite) ids(q db.Query) []string {
+	docs, err := q.Documents(s.ctx)
+	s.Require().Nil(err)
+
+	ids := make([]string, 0, len(docs))
+	for _, doc := range docs {
+		ids = append(ids, doc.ID)
+	}
+	return ids
+}
+
+func (s *MemoryStoreTestSuite) TestGet() {
+	doc, err := s.store.Collection("items").Doc("a").Get(s.ctx)
+	s.Nil(err)
+
+	var item memoryItem
+	s.Nil(doc.DataTo(&item))
+	s.Equal(memoryItem{ID: "a", Name: "apple", Price: 3, Tags: []string{"fruit", "red"}}, item)
+
+	_, err = s.store.Collection("items").Doc("z").Get(s.ctx)
+	s.True(errors.Is(err, db.ErrNotFound))
+}
+
+func (s *MemoryStoreTestSuite) TestGet_ReturnsCopy() {
+	doc, err := s.store.Collection("items").Doc("a").Get(s.ctx)
+	s.Nil(err)
+	doc.Data["name"] = "changed"
+
+	doc, err = s.store.Collection("items").Doc("a").Get(s.ctx)
+	s.Nil(err)
+	s.Equal("apple", doc.Data["name"])
+}
+
+func (s *MemoryStoreTestSuite) TestUpdate() {
+	ref := s.store.Collection("items").Doc("a")
+	s.Nil(ref.Update(s.ctx, db.Update{Path: "price", Value: 5.5}, db.Update{Path: "meta.origin", Value: "BR"}))
+
+	doc, err := ref.Get(s.ctx)
+	s.Nil(err)
+	s.Equal(5.5, doc.Data["price"])
+	s.Equal("apple", doc.Data["name"])
+	s.Equal(map[string]any{"origin": "BR"}, doc.Data["meta"])
+
+	err = s.store.Collection("items").Doc("z").Update(s.ctx, db.Update{Path: "price", Value: 1})
+	s.True(errors.Is(err, db.ErrNotFound))
+}
+
+func (s *MemoryStoreTestSuite) TestDelete() {
+	s.Nil(s.store.Collection("items").Doc("a").Delete(s.ctx))
+	s.ElementsMatch([]string{"b", "c", "d"}, s.ids(s.store.Collection("items")))
+
+	// deleting a missing document is not an error, like Firestore
+	s.Nil(s.store.Collection("items").Doc("a").Delete(s.ctx))
+}
+
+func (s *MemoryStoreTestSuite) TestWhere() {
+	items := s.store.Collection("items")
+
+	s.ElementsMatch([]string{"c", "d"}, s.ids(items.Where("price", db.OpEqual, 2)))
+	s.ElementsMatch([]string{"a", "b"}, s.ids(items.Where("price", db.OpNotEqual, 2)))
+	s.ElementsMatch([]string{"a", "c", "d"}, s.ids(items.Where("price", db.OpGreaterEqual, 2)))
+	s.ElementsMatch([]string{"b"}, s.ids(items.Where("price", db.OpLess, 2)))
+	s.ElementsMatch([]string{"a", "b"}, s.ids(items.Where("tags", db.OpArrayContains, "fruit")))
+	s.ElementsMatch([]string{"a", "c"}, s.ids(items.Where("tags", db.OpArrayContainsAny, []string{"red", "vegetable"})))
+	s.ElementsMatch([]string{"a", "d"}, s.ids(items.Where("name", db.OpIn, []string{"apple", "donut"})))
+	s.ElementsMatch([]string{"b", "c"}, s.ids(items.Where("name", db.OpNotIn, []string{"apple", "donut"})))
+	s.ElementsMatch([]string{"c"}, s.ids(items.Where("price", db.OpEqual, 2).Where("tags", db.OpArrayContains, "vegetable")))
+	s.ElementsMatch([]string{"b"}, s.ids(items.Where(db.DocumentID, db.OpEqual, "b")))
+
+	// values of another type never match a range filter
+	s.Empty(s.ids(items.Where("price", db.OpGreater, "1")))
+}
+
+func (s *MemoryStoreTestSuite) TestWhere_InvalidOperator() {
+	_, err := s.store.Collection("items").Where("price", "like", 2).Documents(s.ctx)
+	s.True(errors.Is(err, db.ErrInvalidOperator))
+}
+
+func (s *MemoryStoreTestSuite) TestWhereFilter() {
+	items := s.store.Collection("items")
+
+	// apple matches both sides of the OR and is returned once
+	or := db.OrFilter{Filters: []db.Filter{
+		db.PropertyFilter{Path: "tags", Operator: db.OpArrayContains, Value: "red"},
+		db.PropertyFilter{Path: "price", Operator: db.OpGreater, Value: 2},
+		db.PropertyFilter{Path: "name", Operator: db.OpEqual, Value: "donut"},
+	}}
+	s.Equal([]string{"a", "d"}, s.ids(items.WhereFilter(or).OrderBy("name", db.Asc)))
+
+	nested := db.OrFilter{Filters: []db.Filter{
+		db.AndFilter{Filters: []db.Filter{
+			db.PropertyFilter{Path: "tags", Operator: db.OpArrayContains, Value: "fruit"},
+			db.PropertyFilter{Path: "price", Operator: db.OpLess, Value: 2},
+		}},
+		db.PropertyFilter{Path: db.DocumentID, Operator: db.OpEqual, Value: "c"},
+	}}
+	s.ElementsMatch([]string{"b", "c"}, s.ids(items.WhereFilter(nested)))
+	s.ElementsMatch([]string{"c"}, s.ids(items.WhereFilter(nested).Where("price", db.OpEqual, 2)))
+}
+
+func (s *MemoryStoreTestSuite) TestWhereFilter_Invalid() {
+	items := s.store.Collection("items")
+
+	_, err := items.WhereFilter(db.OrFilter{}).Documents(s.ctx)
+	s.ErrorIs(err, db.ErrInvalidFilter)
+
+	_, err = items.WhereFilter(db.AndFilter{Filters: []db.Filter{
+		db.PropertyFilter{Path: "price", Operator: "like", Value: 2},
+	}}).Documents(s.ctx)
+	s.NotNil(err)
+}
+
+func (s *MemoryStoreTestSuite) TestStartAfterAndCount() {
+	items := s.store.Collection("items")
+
+	q := items.OrderBy("price", db.Asc).OrderBy(db.DocumentID, db.Asc)
+	s.Equal([]string{"d", "a"}, s.ids(q.StartAfter(2, "c")))
+
+	count, err := items.Where("price", db.OpEqual, 2).Count(s.ctx)
+	s.Nil(err)
+	s.Equal(int64(2), count)
+
+	_, err = items.OrderBy("price", db.Asc).StartAfter(2, "c").Documents(s.ctx)
+	s.ErrorIs(err, db.ErrInvalidCursor)
+}
+
+func (s *MemoryStoreTestSuite) TestPaginate() {
+	items := s.store.Collection("items")
+
+	var ids []string
+	cursor := ""
+	for pages := 0; ; pages++ {
+		s.Require().Less(pages, 2)
+
+		docs, next, err := db.Paginate(s.ctx, items, "price", db.Desc, 3, cursor)
+		s.Require().Nil(err)
+		for _, doc := range docs {
+			ids = append(ids, doc.ID)
+		}
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	s.Equal([]string{"a", "d", "c", "b"}, ids)
+
+	_, _, err := db.Paginate(s.ctx, items, "name", db.Desc, 3, cursor)
+	s.ErrorIs(err, db.ErrInvalidCursor)
+}
+
+func (s *MemoryStoreTestSuite) TestCursor_KeepsTypes() {
+	now := time.Now().UTC()
+	cursor, err := db.EncodeCursor("created_at", db.Asc, now, int64(2), "a")
+	s.Require().Nil(err)
+
+	values, err := db.DecodeCursor(cursor, "created_at", db.Asc)
+	s.Nil(err)
+	s.True(now.Equal(values[0].(time.Time)))
+	s.Equal([]any{int64(2), "a"}, values[1:])
+
+	_, err = db.DecodeCursor(cursor, "created_at", db.Desc)
+	s.ErrorIs(err, db.ErrInvalidCursor)
+
+	_, err = db.DecodeCursor("not a cursor", "created_at", db.Asc)
+	s.ErrorIs(err, db.ErrInvalidCursor)
+}
+
+func (s *MemoryStoreTestSuite) TestOrderByAndLimit() {
+	items := s.store.Collection("items")
+
+	s.Equal([]string{"b", "c", "d", "a"}, s.ids(items.OrderBy("price", db.Asc)))
+	s.Equal([]string{"a", "d", "c", "b"}, s.ids(items.OrderBy("price", db.Desc)))
+	s.Equal([]string{"a", "c", "d"}, s.ids(items.OrderBy("price", db.Desc).OrderBy("name", db.Asc).Limit(3)))
+	s.Equal([]string{"b", "a"}, s.ids(items.Where("tags", db.OpArrayContains, "fruit").OrderBy("name", db.Desc)))
+
+	// documents without the ordered field are left out, arrays are compared item by item
+	s.Equal([]string{"b", "a", "c"}, s.ids(items.OrderBy("tags", db.Asc)))
+}
+
+func (s *MemoryStoreTestSuite) TestQueryIsImmutable() {
+	base := s.store.Collection("items").Where("price", db.OpEqual, 2)
+	_ = base.Where("name", db.OpEqual, "carrot")
+
+	s.ElementsMatch([]string{"c", "d"}, s.ids(base))
+}
+
+func (s *MemoryStoreTestSuite) TestSubCollection() {
+	ref := s.store.Collection("items").Doc("a").Collection("reviews").Doc("r1")
+	s.Nil(ref.Set(s.ctx, map[string]any{"stars": 5}))
+
+	s.Equal([]string{"r1"}, s.ids(s.store.Collection("items").Doc("a").Collection("reviews")))
+	s.Empty(s.ids(s.store.Collection("items").Doc("b").Collection("reviews")))
+	s.Len(s.ids(s.store.Collection("items")), 4)
+}
+
+func (s *MemoryStoreTestSuite) TestTransaction_Commit() {
+	ref := s.store.Collection("items").Doc("a")
+	err := s.store.RunTransaction(s.ctx, func(ctx context.Context, tx db.Transaction) error {
+		doc, err := tx.Get(ref)
+		if err != nil {
+			return err
+		}
+		return tx.Update(ref, db.Update{Path: "price", Value: doc.Data["price"].(float64) + 1})
+	})
+	s.Nil(err)
+
+	doc, err := ref.Get(s.ctx)
+	s.Nil(err)
+	s.Equal(4.0, doc.Data["price"])
+}
+
+func (s *MemoryStoreTestSuite) TestTransaction_RollbackOnError() {
+	errAbort := errors.New("abort")
+	err := s.store.RunTransaction(s.ctx, func(ctx context.Context, tx db.Transaction) error {
+		if err := tx.Delete(s.store.Collection("items").Doc("a")); err != nil {
+			return err
+		}
+		return errAbort
+	})
+	s.Equal(errAbort, err)
+
+	_, err = s.store.Collection("items").Doc("a").Get(s.ctx)
+	s.Nil(err)
+}
+
+func (s *MemoryStoreTestSuite) TestTransaction_FailedWriteIsNotApplied() {
+	err := s.store.RunTransaction(s.ctx, func(ctx context.Context, tx db.Transaction) error {
+		if err := tx.Set(s.store.Collection("items").Doc("e"), memoryItem{ID: "e"}); err != nil {
+			return err
+		}
+		return tx.Update(s.store.Collection("items").Doc("z"), db.Update{Path: "price", Value: 1})
+	})
+	s.True(errors.Is(err, db.ErrNotFound))
+
+	_, err = s.store.Collection("items").Doc("e").Get(s.ctx)
+	s.True(errors.Is(err, db.ErrNotFound))
+}
+
+func (s *MemoryStoreTestSuite) TestTransaction_ReadAfterWrite() {
+	ref := s.store.Collection("items").Doc("a")
+	err := s.store.RunTransaction(s.ctx, func(ctx context.Context, tx db.Transaction) error {
+		if err := tx.Delete(ref); err != nil {
+			return err
+		}
+		_, err := tx.Get(ref)
+		return err
+	})
+	s.True(errors.Is(err, db.ErrReadAfterWrite))
+}
+
+func (s *MemoryStoreTestSuite) TestTransaction_ForeignReference() {
+	other := db.NewMemoryStore()
+	err := s.store.RunTransaction(s.ctx, func(ctx context.Context, tx db.Transaction) error {
+		_, err := tx.Get(other.Collection("items").Doc("a"))
+		return err
+	})
+	s.True(errors.Is(err, db.ErrForeignReference))
+}
+
+func (s *MemoryStoreTestSuite) TestTransaction_Concurrent() {
+	ref := s.store.Collection("counters").Doc("c")
+	s.Require().Nil(ref.Set(s.ctx, map[string]any{"value": 0}))
+
+	const writers = 20
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := s.store.RunTransaction(s.ctx, func(ctx context.Context, tx db.Transaction) error {
+				doc, err := tx.Get(ref)
+				if err != nil {
+					return err
+				}
+				return tx.Update(ref, db.Update{Path: "value", Value: doc.Data["value"].(int64) + 1})
+			})
+			s.Nil(err)
+		}()
+	}
+	wg.Wait()
+
+	doc, err := ref.Get(s.ctx)
+	s.Nil(err)
+	s.Equal(int64(writers), doc.Data["value"])
+}
+
+func TestRunMemoryStoreTestSuite(t *testing.T) {
+	suite.Run(t, new(MemoryStoreTestSuite))
+}
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS credentials (
+    user_id        TEXT PRIMARY KEY,
+    password_hash  TEXT      NOT NULL DEFAULT '',
+    email_verified BOOLEAN   NOT NULL DEFAULT FALSE,
+    create_at      TIMESTAMP NOT NULL,
+    update_at      TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS action_tokens (
+    id         TEXT PRIMARY KEY,
+    user_id    TEXT      NOT NULL DEFAULT '',
+    purpose    TEXT      NOT NULL DEFAULT '',
+    expires_at TIMESTAMP NOT NULL,
+    used       BOOLEAN   NOT NULL DEFAULT FALSE,
+    create_at  TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS action_tokens_user_id_idx ON action_tokens (user_id);
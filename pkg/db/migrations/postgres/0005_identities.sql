@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS identities (
+    id               TEXT PRIMARY KEY,
+    user_id          TEXT      NOT NULL DEFAULT '',
+    provider         TEXT      NOT NULL DEFAULT '',
+    provider_user_id TEXT      NOT NULL DEFAULT '',
+    email            TEXT      NOT NULL DEFAULT '',
+    linked_at        TIMESTAMP NOT NULL,
+    UNIQUE (user_id, provider)
+);
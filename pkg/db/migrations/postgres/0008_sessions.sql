@@ -0,0 +1,14 @@
+-- a session is a login on a device, its id is the family of the refresh tokens of the login
+CREATE TABLE IF NOT EXISTS sessions (
+    id              TEXT PRIMARY KEY,
+    user_id         TEXT      NOT NULL DEFAULT '',
+    provider        TEXT      NOT NULL DEFAULT '',
+    user_agent      TEXT      NOT NULL DEFAULT '',
+    ip              TEXT      NOT NULL DEFAULT '',
+    access_token_id TEXT      NOT NULL DEFAULT '',
+    generation      BIGINT    NOT NULL DEFAULT 0,
+    create_at       TIMESTAMP NOT NULL,
+    last_seen_at    TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS sessions_user_id_idx ON sessions (user_id);
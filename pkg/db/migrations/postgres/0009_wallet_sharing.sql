@@ -0,0 +1,19 @@
+-- the levels of the tenants a wallet is shared with
+ALTER TABLE wallets ADD COLUMN permissions JSONB NOT NULL DEFAULT '[]';
+
+-- an invitation to a wallet, on accept the wallet is shared with the tenant of the invited user
+CREATE TABLE IF NOT EXISTS wallet_invitations (
+    id           TEXT PRIMARY KEY,
+    wallet_id    TEXT      NOT NULL DEFAULT '',
+    wallet_name  TEXT      NOT NULL DEFAULT '',
+    invited_by   TEXT      NOT NULL DEFAULT '',
+    email        TEXT      NOT NULL DEFAULT '',
+    level        TEXT      NOT NULL DEFAULT '',
+    status       TEXT      NOT NULL DEFAULT '',
+    expires_at   TIMESTAMP NOT NULL,
+    create_at    TIMESTAMP NOT NULL,
+    responded_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS wallet_invitations_wallet_id_idx ON wallet_invitations (wallet_id);
+CREATE INDEX IF NOT EXISTS wallet_invitations_email_idx ON wallet_invitations (email);
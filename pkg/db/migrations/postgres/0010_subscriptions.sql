@@ -0,0 +1,20 @@
+-- a wallet over the wallets of the plan of the tenant
+ALTER TABLE wallets ADD COLUMN read_only BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- the subscription of a tenant to a plan, one for each tenant
+CREATE TABLE IF NOT EXISTS subscriptions (
+    tenant_id            TEXT PRIMARY KEY,
+    plan_id              TEXT      NOT NULL DEFAULT '',
+    status               TEXT      NOT NULL DEFAULT '',
+    trial_end            TIMESTAMP NOT NULL,
+    period_start         TIMESTAMP NOT NULL,
+    period_end           TIMESTAMP NOT NULL,
+    scheduled_plan_id    TEXT      NOT NULL DEFAULT '',
+    cancel_at_period_end BOOLEAN   NOT NULL DEFAULT FALSE,
+    past_due_at          TIMESTAMP NOT NULL,
+    due_at               TIMESTAMP NOT NULL,
+    create_at            TIMESTAMP NOT NULL,
+    update_at            TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS subscriptions_due_at_idx ON subscriptions (due_at);
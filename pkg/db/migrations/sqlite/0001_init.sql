@@ -0,0 +1,102 @@
+CREATE TABLE IF NOT EXISTS plans (
+    id          TEXT PRIMARY KEY,
+    name        TEXT      NOT NULL DEFAULT '',
+    description TEXT      NOT NULL DEFAULT '',
+    features    TEXT      NOT NULL DEFAULT '[]',
+    price       NUMERIC   NOT NULL DEFAULT 0,
+    currency    TEXT      NOT NULL DEFAULT '',
+    create_at   TIMESTAMP NOT NULL,
+    update_at   TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS plans_name_idx ON plans (name);
+
+CREATE TABLE IF NOT EXISTS tenants (
+    id        TEXT PRIMARY KEY,
+    name      TEXT      NOT NULL DEFAULT '',
+    alias     TEXT      NOT NULL DEFAULT '',
+    owner_id  TEXT      NOT NULL DEFAULT '',
+    users     TEXT      NOT NULL DEFAULT '[]',
+    plan      TEXT      NOT NULL DEFAULT '{}',
+    wallets   TEXT      NOT NULL DEFAULT '[]',
+    create_at TIMESTAMP NOT NULL,
+    update_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS tenants_owner_id_idx ON tenants (owner_id);
+CREATE INDEX IF NOT EXISTS tenants_name_idx ON tenants (name);
+
+CREATE TABLE IF NOT EXISTS users (
+    id          TEXT PRIMARY KEY,
+    tenant_id   TEXT      NOT NULL DEFAULT '',
+    roles       TEXT      NOT NULL DEFAULT '[]',
+    name        TEXT      NOT NULL DEFAULT '',
+    email       TEXT      NOT NULL DEFAULT '',
+    avatar_url  TEXT      NOT NULL DEFAULT '',
+    provider    TEXT      NOT NULL DEFAULT '',
+    first_name  TEXT      NOT NULL DEFAULT '',
+    last_name   TEXT      NOT NULL DEFAULT '',
+    nick_name   TEXT      NOT NULL DEFAULT '',
+    description TEXT      NOT NULL DEFAULT '',
+    user_id     TEXT      NOT NULL DEFAULT '',
+    location    TEXT      NOT NULL DEFAULT '',
+    create_at   TIMESTAMP NOT NULL,
+    update_at   TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS users_email_idx ON users (email);
+
+CREATE TABLE IF NOT EXISTS wallets (
+    id                  TEXT PRIMARY KEY,
+    name                TEXT      NOT NULL DEFAULT '',
+    description         TEXT      NOT NULL DEFAULT '',
+    owner_id            TEXT      NOT NULL DEFAULT '',
+    tenant_id           TEXT      NOT NULL DEFAULT '',
+    balance             NUMERIC   NOT NULL DEFAULT 0,
+    currency            TEXT      NOT NULL DEFAULT '',
+    shared_with_tenants TEXT      NOT NULL DEFAULT '[]',
+    created_at          TIMESTAMP NOT NULL,
+    updated_at          TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS wallets_owner_id_idx ON wallets (owner_id);
+
+CREATE TABLE IF NOT EXISTS transaction_categories (
+    id        TEXT PRIMARY KEY,
+    name      TEXT NOT NULL DEFAULT '',
+    "default" TEXT NOT NULL DEFAULT '',
+    tenant_id TEXT NOT NULL DEFAULT '',
+    wallet_id TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS transaction_categories_tenant_id_idx ON transaction_categories (tenant_id);
+CREATE INDEX IF NOT EXISTS transaction_categories_wallet_id_idx ON transaction_categories (wallet_id);
+
+CREATE TABLE IF NOT EXISTS wallet_transactions (
+    id          TEXT PRIMARY KEY,
+    wallet_id   TEXT      NOT NULL DEFAULT '',
+    tenant_id   TEXT      NOT NULL DEFAULT '',
+    category_id TEXT      NOT NULL DEFAULT '',
+    type        TEXT      NOT NULL DEFAULT '',
+    amount      NUMERIC   NOT NULL DEFAULT 0,
+    currency    TEXT      NOT NULL DEFAULT '',
+    description TEXT      NOT NULL DEFAULT '',
+    date        TIMESTAMP NOT NULL,
+    created_by  TEXT      NOT NULL DEFAULT '',
+    created_at  TIMESTAMP NOT NULL,
+    updated_at  TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS wallet_transactions_wallet_id_idx ON wallet_transactions (wallet_id);
+
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id         TEXT PRIMARY KEY,
+    user_id    TEXT    NOT NULL DEFAULT '',
+    email      TEXT    NOT NULL DEFAULT '',
+    token      TEXT    NOT NULL DEFAULT '',
+    claims     TEXT    NOT NULL DEFAULT '{}',
+    is_revoked BOOLEAN NOT NULL DEFAULT FALSE,
+    expires_at BIGINT  NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS refresh_tokens_user_id_idx ON refresh_tokens (user_id);
@@ -0,0 +1,26 @@
+-- the access token records move to access_tokens, refresh_tokens keeps the rotating refresh tokens
+DROP TABLE IF EXISTS refresh_tokens;
+
+CREATE TABLE IF NOT EXISTS access_tokens (
+    id         TEXT PRIMARY KEY,
+    user_id    TEXT    NOT NULL DEFAULT '',
+    email      TEXT    NOT NULL DEFAULT '',
+    claims     TEXT    NOT NULL DEFAULT '{}',
+    is_revoked BOOLEAN NOT NULL DEFAULT FALSE,
+    expires_at BIGINT  NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS access_tokens_user_id_idx ON access_tokens (user_id);
+
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id         TEXT PRIMARY KEY,
+    user_id    TEXT      NOT NULL DEFAULT '',
+    family     TEXT      NOT NULL DEFAULT '',
+    expires_at TIMESTAMP NOT NULL,
+    revoked    BOOLEAN   NOT NULL DEFAULT FALSE,
+    used       BOOLEAN   NOT NULL DEFAULT FALSE,
+    create_at  TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS refresh_tokens_user_id_idx ON refresh_tokens (user_id);
+CREATE INDEX IF NOT EXISTS refresh_tokens_family_idx ON refresh_tokens (family);
@@ -0,0 +1,7 @@
+ALTER TABLE refresh_tokens ADD COLUMN generation BIGINT NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS token_generations (
+    user_id    TEXT PRIMARY KEY,
+    generation BIGINT    NOT NULL DEFAULT 0,
+    update_at  TIMESTAMP NOT NULL
+);
@@ -0,0 +1,11 @@
+ALTER TABLE tenants ADD COLUMN require_two_factor BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS two_factors (
+    user_id        TEXT PRIMARY KEY,
+    secret         TEXT      NOT NULL DEFAULT '',
+    enabled        BOOLEAN   NOT NULL DEFAULT FALSE,
+    last_step      BIGINT    NOT NULL DEFAULT 0,
+    recovery_codes TEXT      NOT NULL DEFAULT '[]',
+    create_at      TIMESTAMP NOT NULL,
+    update_at      TIMESTAMP NOT NULL
+);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS personal_access_tokens (
+    id           TEXT PRIMARY KEY,
+    token_hash   TEXT      NOT NULL UNIQUE,
+    user_id      TEXT      NOT NULL DEFAULT '',
+    name         TEXT      NOT NULL DEFAULT '',
+    scopes       TEXT      NOT NULL DEFAULT '[]',
+    expires_at   TIMESTAMP NOT NULL,
+    last_used_at TIMESTAMP NOT NULL,
+    create_at    TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS personal_access_tokens_user_id_idx ON personal_access_tokens (user_id);
@@ -0,0 +1,17 @@
+-- the customer and the subscription of a tenant in the payment provider
+CREATE TABLE IF NOT EXISTS billing_accounts (
+    tenant_id       TEXT PRIMARY KEY,
+    customer_id     TEXT      NOT NULL DEFAULT '',
+    subscription_id TEXT      NOT NULL DEFAULT '',
+    create_at       TIMESTAMP NOT NULL,
+    update_at       TIMESTAMP NOT NULL
+);
+
+-- the webhooks of the payment provider, claimed by the request that applies them
+CREATE TABLE IF NOT EXISTS payment_events (
+    id           TEXT PRIMARY KEY,
+    type         TEXT      NOT NULL DEFAULT '',
+    tenant_id    TEXT      NOT NULL DEFAULT '',
+    claimed_at   TIMESTAMP NOT NULL,
+    processed_at TIMESTAMP NOT NULL
+);
@@ -0,0 +1,21 @@
+-- a tenant suspended by a system admin
+ALTER TABLE tenants ADD COLUMN suspended BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE tenants ADD COLUMN suspended_reason TEXT NOT NULL DEFAULT '';
+ALTER TABLE tenants ADD COLUMN suspended_at TIMESTAMP NOT NULL DEFAULT '0001-01-01 00:00:00';
+
+-- the actions of the system admins
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id          TEXT PRIMARY KEY,
+    actor_id    TEXT      NOT NULL DEFAULT '',
+    actor_email TEXT      NOT NULL DEFAULT '',
+    action      TEXT      NOT NULL DEFAULT '',
+    resource_id TEXT      NOT NULL DEFAULT '',
+    method      TEXT      NOT NULL DEFAULT '',
+    path        TEXT      NOT NULL DEFAULT '',
+    status      INTEGER   NOT NULL DEFAULT 0,
+    ip          TEXT      NOT NULL DEFAULT '',
+    create_at   TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS audit_logs_create_at_idx ON audit_logs (create_at);
+CREATE INDEX IF NOT EXISTS audit_logs_actor_id_idx ON audit_logs (actor_id);
@@ -0,0 +1,158 @@
+package db
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// cursor is the position of the last document of a page
+// The values keep their type, so a time is compared as a time and not as a string
+type cursor struct {
+	Path      string        `json:"p"`
+	Direction Direction     `json:"d"`
+	Values    []cursorValue `json:"v"`
+}
+
+type cursorValue struct {
+	String *string    `json:"s,omitempty"`
+	Int    *int64     `json:"i,omitempty"`
+	Float  *float64   `json:"f,omitempty"`
+	Bool   *bool      `json:"b,omitempty"`
+	Time   *time.Time `json:"t,omitempty"`
+}
+
+func newCursorValue(value any) (cursorValue, error) {
+	switch v := value.(type) {
+	case string:
+		return cursorValue{String: &v}, nil
+	case int:
+		i := int64(v)
+		return cursorValue{Int: &i}, nil
+	case int64:
+		return cursorValue{Int: &v}, nil
+	case float64:
+		return cursorValue{Float: &v}, nil
+	case bool:
+		return cursorValue{Bool: &v}, nil
+	case time.Time:
+		return cursorValue{Time: &v}, nil
+	}
+	return cursorValue{}, fmt.Errorf("%w: cannot order by a %T value", ErrInvalidCursor, value)
+}
+
+func (v cursorValue) value() (any, error) {
+	switch {
+	case v.String != nil:
+		return *v.String, nil
+	case v.Int != nil:
+		return *v.Int, nil
+	case v.Float != nil:
+		return *v.Float, nil
+	case v.Bool != nil:
+		return *v.Bool, nil
+	case v.Time != nil:
+		return *v.Time, nil
+	}
+	return nil, ErrInvalidCursor
+}
+
+// EncodeCursor returns the opaque cursor of the values of a query ordered by path and direction
+func EncodeCursor(path string, dir Direction, values ...any) (string, error) {
+	c := cursor{Path: path, Direction: dir, Values: make([]cursorValue, len(values))}
+	for i, value := range values {
+		v, err := newCursorValue(value)
+		if err != nil {
+			return "", err
+		}
+		c.Values[i] = v
+	}
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// DecodeCursor returns the values of a cursor of EncodeCursor
+// It returns ErrInvalidCursor when the cursor was created for another order
+func DecodeCursor(encoded string, path string, dir Direction) ([]any, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidCursor, err)
+	}
+
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidCursor, err)
+	}
+
+	if c.Path != path || c.Direction != dir {
+		return nil, fmt.Errorf("%w: the cursor belongs to another order", ErrInvalidCursor)
+	}
+
+	values := make([]any, len(c.Values))
+	for i, v := range c.Values {
+		value, err := v.value()
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// Paginate runs the query ordered by path and then by document ID, starting after the cursor
+// It returns at most limit documents and the cursor of the next page, the cursor is empty on the last page
+// An empty path orders only by document ID
+func Paginate(ctx context.Context, q Query, path string, dir Direction, limit int, after string) ([]*Document, string, error) {
+	if path == DocumentID {
+		path = ""
+	}
+
+	orders := 1
+	if path != "" {
+		q = q.OrderBy(path, dir)
+		orders++
+	}
+	q = q.OrderBy(DocumentID, dir)
+
+	if after != "" {
+		values, err := DecodeCursor(after, path, dir)
+		if err != nil {
+			return nil, "", err
+		}
+		if len(values) != orders {
+			return nil, "", fmt.Errorf("%w: %d values for %d orders", ErrInvalidCursor, len(values), orders)
+		}
+		q = q.StartAfter(values...)
+	}
+
+	// one more document tells if there is a next page
+	docs, err := q.Limit(limit + 1).Documents(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if len(docs) <= limit {
+		return docs, "", nil
+	}
+
+	docs = docs[:limit]
+	last := docs[len(docs)-1]
+
+	values := []any{last.ID}
+	if path != "" {
+		value, _ := fieldValue(last, path)
+		values = []any{value, last.ID}
+	}
+
+	next, err := EncodeCursor(path, dir, values...)
+	if err != nil {
+		return nil, "", err
+	}
+	return docs, next, nil
+}
@@ -0,0 +1,224 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/mattn/go-sqlite3"
+)
+
+// Database kinds accepted by NewFirebaseDatabaseConnection
+const (
+	KindFirebase = "firebase"
+	KindMemory   = "memory"
+	KindSQLite   = "sqlite"
+	KindPostgres = "postgres"
+)
+
+// DefaultSQLiteDSN is used when the sqlite kind is configured without dsn, so it works out of the box
+const DefaultSQLiteDSN = "file:financial-management.db?_busy_timeout=5000&_txlock=immediate"
+
+//go:embed migrations
+var migrations embed.FS
+
+type SQLDatabaseConfig struct {
+	DSN string `json:"dsn" mapstructure:"dsn"`
+}
+
+// SQLDatabase is a relational database with the schema migrated
+type SQLDatabase struct {
+	DB   *sql.DB
+	Kind string
+}
+
+// NewSQLDatabase opens the database of the kind and applies the pending migrations
+func NewSQLDatabase(ctx context.Context, fields any, kind string) (*SQLDatabase, error) {
+
+	config, err := parseSQLConfig(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	var driver string
+	switch kind {
+	case KindSQLite:
+		driver = "sqlite3"
+		if config.DSN == "" {
+			config.DSN = DefaultSQLiteDSN
+		}
+	case KindPostgres:
+		driver = "pgx"
+		if config.DSN == "" {
+			return nil, fmt.Errorf("dsn is required for %s", kind)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported sql database kind %q", kind)
+	}
+
+	conn, err := sql.Open(driver, config.DSN)
+	if err != nil {
+		return nil, err
+	}
+
+	// an in-memory sqlite database only lives in its own connection
+	if kind == KindSQLite && strings.Contains(config.DSN, ":memory:") {
+		conn.SetMaxOpenConns(1)
+	}
+
+	database := &SQLDatabase{DB: conn, Kind: kind}
+
+	if err := conn.PingContext(ctx); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if err := database.Migrate(ctx); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return database, nil
+}
+
+func (s *SQLDatabase) Close() error {
+	return s.DB.Close()
+}
+
+// IsUniqueViolation reports if the error of a write is the violation of a primary key or of a unique index
+func IsUniqueViolation(err error) bool {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey || sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique
+	}
+
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// Rebind converts the ? placeholders to the placeholders of the database
+func (s *SQLDatabase) Rebind(query string) string {
+	if s.Kind != KindPostgres {
+		return query
+	}
+
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// ArrayContains returns the condition that checks if the JSON array column contains the value of the placeholder
+func (s *SQLDatabase) ArrayContains(column string) string {
+	if s.Kind == KindPostgres {
+		return fmt.Sprintf("%s @> jsonb_build_array(?::text)", column)
+	}
+	return fmt.Sprintf("EXISTS (SELECT 1 FROM json_each(%s) WHERE json_each.value = ?)", column)
+}
+
+// Migrate applies the embedded migrations of the database kind that were not applied yet
+// Each migration runs in its own transaction and is recorded in schema_migrations
+func (s *SQLDatabase) Migrate(ctx context.Context) error {
+
+	_, err := s.DB.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version    TEXT PRIMARY KEY,
+    applied_at TIMESTAMP NOT NULL
+)`)
+	if err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+
+	dir := path.Join("migrations", s.Kind)
+	entries, err := fs.ReadDir(migrations, dir)
+	if err != nil {
+		return err
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".sql") {
+			files = append(files, entry.Name())
+		}
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		version := strings.TrimSuffix(file, ".sql")
+
+		var applied int
+		err := s.DB.QueryRowContext(ctx, s.Rebind("SELECT COUNT(*) FROM schema_migrations WHERE version = ?"), version).Scan(&applied)
+		if err != nil {
+			return err
+		}
+		if applied > 0 {
+			continue
+		}
+
+		script, err := migrations.ReadFile(path.Join(dir, file))
+		if err != nil {
+			return err
+		}
+
+		if err := s.migrate(ctx, version, string(script)); err != nil {
+			return fmt.Errorf("migration %s: %w", version, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *SQLDatabase) migrate(ctx context.Context, version, script string) error {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, s.Rebind("INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)"), version, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func parseSQLConfig(fields any) (*SQLDatabaseConfig, error) {
+	var config SQLDatabaseConfig
+	if fields == nil {
+		return &config, nil
+	}
+
+	b, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(b, &config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
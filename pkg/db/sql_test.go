@@ -0,0 +1,76 @@
+package db_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/Tomelin/financial-management-backend/pkg/db"
+	"github.com/stretchr/testify/suite"
+)
+
+type SQLDatabaseTestSuite struct {
+	suite.Suite
+	ctx context.Context
+}
+
+func (s *SQLDatabaseTestSuite) SetupTest() {
+	s.ctx = context.Background()
+}
+
+func (s *SQLDatabaseTestSuite) TestMigrate_Idempotent() {
+	fields := map[string]any{"dsn": "file:" + filepath.Join(s.T().TempDir(), "test.db")}
+
+	database, err := db.NewSQLDatabase(s.ctx, fields, db.KindSQLite)
+	s.Require().Nil(err)
+	s.Nil(database.Migrate(s.ctx))
+
+	var migrations int
+	s.Nil(database.DB.QueryRowContext(s.ctx, "SELECT COUNT(*) FROM schema_migrations").Scan(&migrations))
+	s.Greater(migrations, 0)
+	s.Nil(database.Close())
+
+	// opening again does not apply the migrations twice
+	database, err = db.NewSQLDatabase(s.ctx, fields, db.KindSQLite)
+	s.Require().Nil(err)
+	defer database.Close()
+
+	var applied int
+	s.Nil(database.DB.QueryRowContext(s.ctx, "SELECT COUNT(*) FROM schema_migrations").Scan(&applied))
+	s.Equal(migrations, applied)
+
+	var wallets int
+	s.Nil(database.DB.QueryRowContext(s.ctx, "SELECT COUNT(*) FROM wallets").Scan(&wallets))
+	s.Equal(0, wallets)
+}
+
+func (s *SQLDatabaseTestSuite) TestNewFirebaseDatabaseConnection_Kind() {
+	database, err := db.NewFirebaseDatabaseConnection(s.ctx, map[string]any{"dsn": ":memory:"}, db.KindSQLite)
+	s.Require().Nil(err)
+	s.IsType(&db.SQLDatabase{}, database)
+	s.Nil(database.Close())
+
+	database, err = db.NewFirebaseDatabaseConnection(s.ctx, nil, db.KindMemory)
+	s.Require().Nil(err)
+	s.Implements((*db.DocumentStore)(nil), database)
+
+	_, err = db.NewFirebaseDatabaseConnection(s.ctx, nil, db.KindPostgres)
+	s.NotNil(err)
+}
+
+func (s *SQLDatabaseTestSuite) TestNewSQLDatabase_UnsupportedKind() {
+	_, err := db.NewSQLDatabase(s.ctx, nil, "oracle")
+	s.NotNil(err)
+}
+
+func (s *SQLDatabaseTestSuite) TestRebind() {
+	sqlite := &db.SQLDatabase{Kind: db.KindSQLite}
+	s.Equal("SELECT * FROM t WHERE a = ? AND b = ?", sqlite.Rebind("SELECT * FROM t WHERE a = ? AND b = ?"))
+
+	postgres := &db.SQLDatabase{Kind: db.KindPostgres}
+	s.Equal("SELECT * FROM t WHERE a = $1 AND b = $2", postgres.Rebind("SELECT * FROM t WHERE a = ? AND b = ?"))
+}
+
+func TestRunSQLDatabaseTestSuite(t *testing.T) {
+	suite.Run(t, new(SQLDatabaseTestSuite))
+}
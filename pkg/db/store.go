@@ -0,0 +1,146 @@
+package db
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	ErrNotFound         = errors.New("document not found")
+	ErrInvalidOperator  = errors.New("invalid query operator")
+	ErrInvalidFilter    = errors.New("invalid query filter")
+	ErrReadAfterWrite   = errors.New("transaction reads must happen before writes")
+	ErrForeignReference = errors.New("document reference belongs to another store")
+	ErrInvalidCursor    = errors.New("invalid cursor")
+)
+
+// DocumentID is the path used to filter or order by the document ID
+const DocumentID = "__name__"
+
+// Direction is the sort direction of OrderBy
+type Direction int
+
+const (
+	Asc Direction = iota
+	Desc
+)
+
+// Operators accepted by Where, they are the same of Firestore
+const (
+	OpEqual            = "=="
+	OpNotEqual         = "!="
+	OpLess             = "<"
+	OpLessEqual        = "<="
+	OpGreater          = ">"
+	OpGreaterEqual     = ">="
+	OpIn               = "in"
+	OpNotIn            = "not-in"
+	OpArrayContains    = "array-contains"
+	OpArrayContainsAny = "array-contains-any"
+)
+
+// Update changes a single field of a document, nested fields are separated by dots
+type Update struct {
+	Path  string
+	Value any
+}
+
+// Document is a document read from the store
+type Document struct {
+	ID   string
+	Data map[string]any
+}
+
+// DataTo reads the document into dst, see Decode
+func (d *Document) DataTo(dst any) error {
+	if d == nil {
+		return ErrNotFound
+	}
+	return Decode(d.Data, dst)
+}
+
+// Filter is a condition of WhereFilter, a PropertyFilter or a composite AndFilter/OrFilter
+type Filter interface {
+	isFilter()
+}
+
+// PropertyFilter compares a field with a value, it is the filter of Where
+type PropertyFilter struct {
+	Path     string
+	Operator string
+	Value    any
+}
+
+// AndFilter matches the documents that match all the filters
+type AndFilter struct {
+	Filters []Filter
+}
+
+// OrFilter matches the documents that match any of the filters
+// A document is returned once even when it matches more than one filter
+type OrFilter struct {
+	Filters []Filter
+}
+
+func (PropertyFilter) isFilter() {}
+func (AndFilter) isFilter()      {}
+func (OrFilter) isFilter()       {}
+
+// Query filters, sorts and limits the documents of a collection
+// Queries are immutable, every method returns a new query
+type Query interface {
+	Where(path, op string, value any) Query
+	// WhereFilter adds a filter that may combine conditions with AND and OR
+	WhereFilter(filter Filter) Query
+	OrderBy(path string, dir Direction) Query
+	Limit(n int) Query
+	// StartAfter starts the results after the position of the values, one value for each OrderBy
+	// The value of DocumentID is the document ID
+	StartAfter(values ...any) Query
+	Documents(ctx context.Context) ([]*Document, error)
+	// Count returns the number of documents of the query without reading them
+	Count(ctx context.Context) (int64, error)
+}
+
+// CollectionRef is a collection of documents, it is also the query of all its documents
+type CollectionRef interface {
+	Query
+	Doc(id string) DocumentRef
+}
+
+// DocumentRef points to a document that may not exist yet
+type DocumentRef interface {
+	ID() string
+	Collection(name string) CollectionRef
+	// Get returns ErrNotFound when the document does not exist
+	Get(ctx context.Context) (*Document, error)
+	// Set creates or replaces the document, data is a struct or a map
+	Set(ctx context.Context, data any) error
+	// Update changes some fields, it returns ErrNotFound when the document does not exist
+	Update(ctx context.Context, updates ...Update) error
+	Delete(ctx context.Context) error
+}
+
+// Transaction reads and writes documents atomically
+// All reads must happen before the writes, like in Firestore
+type Transaction interface {
+	Get(ref DocumentRef) (*Document, error)
+	Documents(q Query) ([]*Document, error)
+	Set(ref DocumentRef, data any) error
+	Update(ref DocumentRef, updates ...Update) error
+	Delete(ref DocumentRef) error
+}
+
+// DocumentStore is a backend neutral document database
+type DocumentStore interface {
+	Collection(name string) CollectionRef
+	// RunTransaction runs f in a transaction, f may be called more than once when a concurrent write conflicts
+	RunTransaction(ctx context.Context, f func(ctx context.Context, tx Transaction) error) error
+	Close() error
+}
+
+// Database is a connection opened by NewFirebaseDatabaseConnection
+// It is a DocumentStore for the document backends or a *SQLDatabase for the relational ones
+type Database interface {
+	Close() error
+}
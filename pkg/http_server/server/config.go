@@ -36,9 +36,10 @@ type RestAPI struct {
 	Config *RestAPIConfig
 	Route  *gin.Engine
 	*gin.RouterGroup
+	authenticate gin.HandlerFunc
 }
 
-func NewRestApi(fields any, s *sessions.CookieStore) (*RestAPI, error) {
+func NewRestApi(fields any, s sessions.Store) (*RestAPI, error) {
 
 	b, err := json.Marshal(fields)
 	if err != nil {
@@ -73,7 +74,7 @@ var SwaggerInfo = &swag.Spec{
 	// RightDelim: "}}",
 }
 
-func newRestAPI(config *RestAPIConfig, s *sessions.CookieStore) (*gin.Engine, *gin.RouterGroup) {
+func newRestAPI(config *RestAPIConfig, s sessions.Store) (*gin.Engine, *gin.RouterGroup) {
 
 	if config == nil {
 		config = &RestAPIConfig{}
@@ -91,7 +92,7 @@ func newRestAPI(config *RestAPIConfig, s *sessions.CookieStore) (*gin.Engine, *g
 	router.Use(gin.Logger())
 	router.Use(gin.Recovery())
 
-	router.Use(sessions.Sessions("Authorization", *s))
+	router.Use(sessions.Sessions("Authorization", s))
 
 	router.UseH2C = true
 
@@ -38,8 +38,6 @@ func (s *RestAPI) Run(handle http.Handler) error {
 	}
 
 	http2.ConfigureServer(&srv, &http2.Server{})
-	s.Route.Use(s.ValidateToken)
-	s.Route.Use(s.MiddlewareHeader)
 
 	return srv.ListenAndServe()
 }
@@ -57,24 +55,26 @@ func setHeader(c *gin.Context) {
 	c.Next()
 }
 
-func (s *RestAPI) MiddlewareHeader(c *gin.Context) {
-	const SkipMiddlewareKey = "skipMiddleware"
+// DevMode reports if the server runs in the debug or test mode of the configuration
+// The routes meant for development are registered only in these modes, the default is neither
+func (s *RestAPI) DevMode() bool {
+	return s.Config != nil && (s.Config.Mode == gin.DebugMode || s.Config.Mode == gin.TestMode)
+}
 
-	if c.GetBool(SkipMiddlewareKey) {
-		c.Next()
-		return
-	}
+// SetAuthenticator sets the middleware that authenticates the requests of ValidateToken
+func (s *RestAPI) SetAuthenticator(authenticate gin.HandlerFunc) {
+	s.authenticate = authenticate
+}
+
+// ValidateToken authenticates the request with the middleware of SetAuthenticator
+// The requests are rejected when no authenticator was set
+func (s *RestAPI) ValidateToken(c *gin.Context) {
 
-	if c.GetHeader("Authorization") == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": entity.Error("invalid token", "token", "MiddlewareHeader", entity.ApplicationLayerHandler, entity.ResponseCodeUnauthorized)})
-		c.Writer.Flush()
+	if s.authenticate == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": entity.Error("invalid token", "token", "ValidateToken", entity.ApplicationLayerMiddleware, entity.ResponseCodeUnauthorized)})
 		c.Abort()
 		return
 	}
-	c.Next()
-}
 
-func (s *RestAPI) ValidateToken(c *gin.Context) {
-
-	c.Next()
+	s.authenticate(c)
 }
@@ -0,0 +1,322 @@
+package jwtkeys
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// minSecretSize is the minimum size of the HS256 secrets, the size of the hash
+const minSecretSize = 32
+
+var (
+	// ErrUnknownKey is returned when the kid of a token is not a verification key
+	ErrUnknownKey = errors.New("unknown signing key")
+	// ErrNoSigningKey is returned when the configuration has no key to sign the tokens
+	ErrNoSigningKey = errors.New("signing key is required")
+)
+
+// Config is the jwt section of the configuration
+//
+//	jwt:
+//	  signing_kid: "2025-01"
+//	  keys:
+//	    - kid: "2025-01"
+//	      algorithm: EdDSA
+//	      private_key_file: /etc/financial/jwt-2025-01.pem
+//	    - kid: "2024-12"
+//	      algorithm: HS256
+//	      secret_file: /etc/financial/jwt-2024-12.secret
+//
+// The signing key must have the secret or the private key, the other keys only verify the tokens.
+// signing_kid can be omitted when there is only one key.
+type Config struct {
+	SigningKid string      `json:"signing_kid"`
+	Keys       []KeyConfig `json:"keys"`
+}
+
+// KeyConfig is a key of the configuration, the PEM values or the files with them
+type KeyConfig struct {
+	Kid            string `json:"kid"`
+	Algorithm      string `json:"algorithm"`
+	Secret         string `json:"secret"`
+	SecretFile     string `json:"secret_file"`
+	PrivateKey     string `json:"private_key"`
+	PrivateKeyFile string `json:"private_key_file"`
+	PublicKey      string `json:"public_key"`
+	PublicKeyFile  string `json:"public_key_file"`
+}
+
+// Key is a key of the KeySet
+type Key struct {
+	Kid       string
+	Algorithm string
+	method    jwt.SigningMethod
+	signKey   any
+	verifyKey any
+}
+
+// KeySet signs the tokens with the signing key and verifies them with every key of the set
+type KeySet struct {
+	signing *Key
+	keys    map[string]*Key
+}
+
+// NewKeySet creates the KeySet of the jwt section of the configuration
+func NewKeySet(fields any) (*KeySet, error) {
+	b, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		return nil, err
+	}
+
+	return NewKeySetFromConfig(cfg)
+}
+
+// NewKeySetFromConfig creates the KeySet of the configuration
+func NewKeySetFromConfig(cfg Config) (*KeySet, error) {
+	if len(cfg.Keys) == 0 {
+		return nil, errors.New("jwt keys are required")
+	}
+
+	ks := &KeySet{keys: make(map[string]*Key, len(cfg.Keys))}
+	for _, kc := range cfg.Keys {
+		key, err := newKey(kc)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := ks.keys[key.Kid]; ok {
+			return nil, fmt.Errorf("duplicated kid %q", key.Kid)
+		}
+		ks.keys[key.Kid] = key
+	}
+
+	signingKid := cfg.SigningKid
+	if signingKid == "" && len(cfg.Keys) == 1 {
+		signingKid = cfg.Keys[0].Kid
+	}
+
+	signing, ok := ks.keys[signingKid]
+	if !ok || signing.signKey == nil {
+		return nil, fmt.Errorf("%w: kid %q", ErrNoSigningKey, signingKid)
+	}
+	ks.signing = signing
+
+	return ks, nil
+}
+
+// SigningKid returns the kid of the key that signs the tokens
+func (ks *KeySet) SigningKid() string {
+	return ks.signing.Kid
+}
+
+// Sign signs the claims with the signing key, the kid is set in the header of the token
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.signing.method, claims)
+	token.Header["kid"] = ks.signing.Kid
+	return token.SignedString(ks.signing.signKey)
+}
+
+// Keyfunc returns the verification key of the kid of the token
+// The algorithm of the token must be the algorithm of the key
+func (ks *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := ks.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: kid %q", ErrUnknownKey, kid)
+	}
+
+	if token.Method == nil || token.Method.Alg() != key.Algorithm {
+		return nil, fmt.Errorf("unexpected signing method %v of kid %q", token.Header["alg"], kid)
+	}
+
+	return key.verifyKey, nil
+}
+
+// Parse verifies the token and reads its claims
+func (ks *KeySet) Parse(token string, claims jwt.Claims) (*jwt.Token, error) {
+	return jwt.ParseWithClaims(token, claims, ks.Keyfunc)
+}
+
+// Keys returns the keys of the set sorted by kid
+func (ks *KeySet) Keys() []*Key {
+	keys := make([]*Key, 0, len(ks.keys))
+	for _, key := range ks.keys {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Kid < keys[j].Kid })
+	return keys
+}
+
+func newKey(kc KeyConfig) (*Key, error) {
+	if kc.Kid == "" {
+		return nil, errors.New("kid of the jwt key is required")
+	}
+
+	key := &Key{Kid: kc.Kid, Algorithm: kc.Algorithm}
+
+	var err error
+	switch kc.Algorithm {
+	case AlgorithmHS256:
+		key.method = jwt.SigningMethodHS256
+		err = key.loadSecret(kc)
+	case AlgorithmRS256:
+		key.method = jwt.SigningMethodRS256
+		err = key.loadRSA(kc)
+	case AlgorithmEdDSA:
+		key.method = SigningMethodEdDSA
+		err = key.loadEd25519(kc)
+	default:
+		err = fmt.Errorf("algorithm must be %s, %s or %s", AlgorithmHS256, AlgorithmRS256, AlgorithmEdDSA)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("jwt key %q: %w", kc.Kid, err)
+	}
+
+	return key, nil
+}
+
+func (k *Key) loadSecret(kc KeyConfig) error {
+	secret, err := value(kc.Secret, kc.SecretFile)
+	if err != nil {
+		return err
+	}
+
+	secret = strings.TrimSpace(secret)
+	if len(secret) < minSecretSize {
+		return fmt.Errorf("secret must have at least %d bytes", minSecretSize)
+	}
+
+	k.signKey = []byte(secret)
+	k.verifyKey = []byte(secret)
+	return nil
+}
+
+func (k *Key) loadRSA(kc KeyConfig) error {
+	private, err := value(kc.PrivateKey, kc.PrivateKeyFile)
+	if err != nil {
+		return err
+	}
+
+	if private != "" {
+		privateKey, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(private))
+		if err != nil {
+			return err
+		}
+		k.signKey = privateKey
+		k.verifyKey = &privateKey.PublicKey
+		return nil
+	}
+
+	public, err := value(kc.PublicKey, kc.PublicKeyFile)
+	if err != nil {
+		return err
+	}
+	if public == "" {
+		return errors.New("private or public key is required")
+	}
+
+	publicKey, err := jwt.ParseRSAPublicKeyFromPEM([]byte(public))
+	if err != nil {
+		return err
+	}
+	k.verifyKey = publicKey
+	return nil
+}
+
+func (k *Key) loadEd25519(kc KeyConfig) error {
+	private, err := value(kc.PrivateKey, kc.PrivateKeyFile)
+	if err != nil {
+		return err
+	}
+
+	if private != "" {
+		der, err := pemBytes(private)
+		if err != nil {
+			return err
+		}
+		parsed, err := x509.ParsePKCS8PrivateKey(der)
+		if err != nil {
+			return err
+		}
+		privateKey, ok := parsed.(ed25519.PrivateKey)
+		if !ok {
+			return errEdDSAKey
+		}
+		k.signKey = privateKey
+		k.verifyKey = privateKey.Public().(ed25519.PublicKey)
+		return nil
+	}
+
+	public, err := value(kc.PublicKey, kc.PublicKeyFile)
+	if err != nil {
+		return err
+	}
+	if public == "" {
+		return errors.New("private or public key is required")
+	}
+
+	der, err := pemBytes(public)
+	if err != nil {
+		return err
+	}
+	parsed, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return err
+	}
+	publicKey, ok := parsed.(ed25519.PublicKey)
+	if !ok {
+		return errEdDSAKey
+	}
+	k.verifyKey = publicKey
+	return nil
+}
+
+// Public returns the public key of the asymmetric keys, nil for HS256
+func (k *Key) Public() any {
+	switch key := k.verifyKey.(type) {
+	case *rsa.PublicKey, ed25519.PublicKey:
+		return key
+	}
+	return nil
+}
+
+// value returns the inline value or the content of the file
+func value(inline, file string) (string, error) {
+	if inline != "" || file == "" {
+		return inline, nil
+	}
+
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func pemBytes(value string) ([]byte, error) {
+	block, _ := pem.Decode([]byte(value))
+	if block == nil {
+		return nil, errors.New("key must be PEM encoded")
+	}
+	return block.Bytes, nil
+}
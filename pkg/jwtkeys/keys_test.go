@@ -0,0 +1,190 @@
+package jwtkeys_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/Tomelin/financial-management-backend/pkg/jwtkeys"
+)
+
+const secret = "0123456789abcdef0123456789abcdef"
+
+type KeySetTestSuite struct {
+	suite.Suite
+	rsaPrivate string
+	rsaPublic  string
+	edPrivate  string
+	edPublic   string
+}
+
+func (s *KeySetTestSuite) SetupSuite() {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	s.Require().NoError(err)
+	s.rsaPrivate = string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}))
+	der, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	s.Require().NoError(err)
+	s.rsaPublic = string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+
+	edPublic, edPrivate, err := ed25519.GenerateKey(rand.Reader)
+	s.Require().NoError(err)
+	der, err = x509.MarshalPKCS8PrivateKey(edPrivate)
+	s.Require().NoError(err)
+	s.edPrivate = string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	der, err = x509.MarshalPKIXPublicKey(edPublic)
+	s.Require().NoError(err)
+	s.edPublic = string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func (s *KeySetTestSuite) claims() *jwt.StandardClaims {
+	return &jwt.StandardClaims{Subject: "user@domain.com", ExpiresAt: time.Now().Add(time.Hour).Unix()}
+}
+
+func (s *KeySetTestSuite) keys() []jwtkeys.KeyConfig {
+	return []jwtkeys.KeyConfig{
+		{Kid: "hs", Algorithm: jwtkeys.AlgorithmHS256, Secret: secret},
+		{Kid: "rs", Algorithm: jwtkeys.AlgorithmRS256, PrivateKey: s.rsaPrivate},
+		{Kid: "ed", Algorithm: jwtkeys.AlgorithmEdDSA, PrivateKey: s.edPrivate},
+	}
+}
+
+func (s *KeySetTestSuite) TestSignAndParse() {
+	for _, kid := range []string{"hs", "rs", "ed"} {
+		ks, err := jwtkeys.NewKeySetFromConfig(jwtkeys.Config{SigningKid: kid, Keys: s.keys()})
+		s.Require().NoError(err, kid)
+
+		token, err := ks.Sign(s.claims())
+		s.Require().NoError(err, kid)
+
+		var claims jwt.StandardClaims
+		parsed, err := ks.Parse(token, &claims)
+		s.Require().NoError(err, kid)
+		s.Equal(kid, parsed.Header["kid"])
+		s.Equal("user@domain.com", claims.Subject)
+	}
+}
+
+func (s *KeySetTestSuite) TestRotation() {
+	old, err := jwtkeys.NewKeySetFromConfig(jwtkeys.Config{Keys: s.keys()[:1]})
+	s.Require().NoError(err)
+	token, err := old.Sign(s.claims())
+	s.Require().NoError(err)
+
+	// the new key signs, the previous key still verifies the tokens it signed
+	current, err := jwtkeys.NewKeySetFromConfig(jwtkeys.Config{SigningKid: "ed", Keys: s.keys()})
+	s.Require().NoError(err)
+	s.Equal("ed", current.SigningKid())
+
+	_, err = current.Parse(token, &jwt.StandardClaims{})
+	s.NoError(err)
+
+	// removing the key invalidates its tokens
+	removed, err := jwtkeys.NewKeySetFromConfig(jwtkeys.Config{SigningKid: "ed", Keys: s.keys()[1:]})
+	s.Require().NoError(err)
+	_, err = removed.Parse(token, &jwt.StandardClaims{})
+	s.Error(err)
+}
+
+func (s *KeySetTestSuite) TestParse_Rejected() {
+	ks, err := jwtkeys.NewKeySetFromConfig(jwtkeys.Config{SigningKid: "ed", Keys: s.keys()})
+	s.Require().NoError(err)
+
+	tokens := map[string]*jwt.Token{
+		"unknown kid": jwt.NewWithClaims(jwt.SigningMethodHS256, s.claims()),
+		"alg of other key": func() *jwt.Token {
+			token := jwt.NewWithClaims(jwt.SigningMethodHS256, s.claims())
+			token.Header["kid"] = "ed"
+			return token
+		}(),
+	}
+	tokens["unknown kid"].Header["kid"] = "other"
+
+	for name, token := range tokens {
+		signed, err := token.SignedString([]byte(secret))
+		s.Require().NoError(err)
+		_, err = ks.Parse(signed, &jwt.StandardClaims{})
+		s.Error(err, name)
+	}
+
+	_, err = ks.Parse("not-a-token", &jwt.StandardClaims{})
+	s.Error(err)
+}
+
+func (s *KeySetTestSuite) TestVerificationOnly() {
+	signer, err := jwtkeys.NewKeySetFromConfig(jwtkeys.Config{SigningKid: "rs", Keys: s.keys()})
+	s.Require().NoError(err)
+	token, err := signer.Sign(s.claims())
+	s.Require().NoError(err)
+
+	dir := s.T().TempDir()
+	file := filepath.Join(dir, "rs.pem")
+	s.Require().NoError(os.WriteFile(file, []byte(s.rsaPublic), 0o600))
+
+	verifier, err := jwtkeys.NewKeySetFromConfig(jwtkeys.Config{SigningKid: "hs", Keys: []jwtkeys.KeyConfig{
+		{Kid: "hs", Algorithm: jwtkeys.AlgorithmHS256, Secret: secret},
+		{Kid: "rs", Algorithm: jwtkeys.AlgorithmRS256, PublicKeyFile: file},
+		{Kid: "ed", Algorithm: jwtkeys.AlgorithmEdDSA, PublicKey: s.edPublic},
+	}})
+	s.Require().NoError(err)
+
+	_, err = verifier.Parse(token, &jwt.StandardClaims{})
+	s.NoError(err)
+
+	_, err = jwtkeys.NewKeySetFromConfig(jwtkeys.Config{SigningKid: "rs", Keys: []jwtkeys.KeyConfig{
+		{Kid: "rs", Algorithm: jwtkeys.AlgorithmRS256, PublicKey: s.rsaPublic},
+	}})
+	s.True(errors.Is(err, jwtkeys.ErrNoSigningKey))
+}
+
+func (s *KeySetTestSuite) TestJWKS() {
+	ks, err := jwtkeys.NewKeySetFromConfig(jwtkeys.Config{SigningKid: "hs", Keys: s.keys()})
+	s.Require().NoError(err)
+
+	jwks := ks.JWKS()
+	s.Require().Len(jwks.Keys, 2, "the HS256 secret is not published")
+
+	s.Equal("ed", jwks.Keys[0].Kid)
+	s.Equal("OKP", jwks.Keys[0].Kty)
+	s.Equal("Ed25519", jwks.Keys[0].Crv)
+	s.NotEmpty(jwks.Keys[0].X)
+
+	s.Equal("rs", jwks.Keys[1].Kid)
+	s.Equal("RSA", jwks.Keys[1].Kty)
+	s.Equal("AQAB", jwks.Keys[1].E)
+	s.NotEmpty(jwks.Keys[1].N)
+}
+
+func (s *KeySetTestSuite) TestNewKeySet_Invalid() {
+	tests := map[string]any{
+		"no keys":       map[string]any{},
+		"short secret":  map[string]any{"keys": []any{map[string]any{"kid": "hs", "algorithm": "HS256", "secret": "short"}}},
+		"no kid":        map[string]any{"keys": []any{map[string]any{"algorithm": "HS256", "secret": secret}}},
+		"algorithm":     map[string]any{"keys": []any{map[string]any{"kid": "hs", "algorithm": "none", "secret": secret}}},
+		"signing kid":   map[string]any{"signing_kid": "other", "keys": []any{map[string]any{"kid": "hs", "algorithm": "HS256", "secret": secret}}},
+		"duplicate kid": map[string]any{"signing_kid": "hs", "keys": []any{map[string]any{"kid": "hs", "algorithm": "HS256", "secret": secret}, map[string]any{"kid": "hs", "algorithm": "HS256", "secret": secret}}},
+		"not pem":       map[string]any{"keys": []any{map[string]any{"kid": "ed", "algorithm": "EdDSA", "private_key": "abc"}}},
+		"missing file":  map[string]any{"keys": []any{map[string]any{"kid": "hs", "algorithm": "HS256", "secret_file": "/nonexistent/secret"}}},
+	}
+
+	for name, fields := range tests {
+		_, err := jwtkeys.NewKeySet(fields)
+		s.Error(err, name)
+	}
+
+	_, err := jwtkeys.NewKeySet(map[string]any{"keys": []any{map[string]any{"kid": "hs", "algorithm": "HS256", "secret": secret}}})
+	s.NoError(err)
+}
+
+func TestKeySetTestSuite(t *testing.T) {
+	suite.Run(t, new(KeySetTestSuite))
+}
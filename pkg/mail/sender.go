@@ -0,0 +1,129 @@
+package mail
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/Tomelin/financial-management-backend/pkg/logger"
+)
+
+const (
+	// KindLog writes the emails to the logger
+	KindLog = "log"
+	// KindFile writes each email to a file of the directory
+	KindFile = "file"
+)
+
+// Sender sends the emails of the application
+type Sender interface {
+	Send(ctx context.Context, message *Message) error
+}
+
+// Message is a plain text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Config is the mail section of the configuration
+type Config struct {
+	Kind string `json:"kind"`
+	From string `json:"from"`
+	Dir  string `json:"dir"`
+}
+
+// NewSender creates the sender of the kind of the mail section, the default is the log sender
+func NewSender(fields any, l logger.Logger) (Sender, error) {
+	var cfg Config
+	if fields != nil {
+		b, err := json.Marshal(fields)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(b, &cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	switch cfg.Kind {
+	case "", KindLog:
+		return NewLogSender(l), nil
+	case KindFile:
+		return NewFileSender(cfg.Dir, cfg.From)
+	}
+
+	return nil, fmt.Errorf("unknown mail sender %q", cfg.Kind)
+}
+
+func (m *Message) validate() error {
+	if m == nil || m.To == "" {
+		return errors.New("recipient is required")
+	}
+	if strings.ContainsAny(m.To+m.Subject, "\r\n") {
+		return errors.New("invalid header")
+	}
+	return nil
+}
+
+// LogSender writes the emails to the logger, it is meant for development
+type LogSender struct {
+	log logger.Logger
+}
+
+func NewLogSender(l logger.Logger) *LogSender {
+	return &LogSender{log: l}
+}
+
+func (s *LogSender) Send(ctx context.Context, message *Message) error {
+	if err := message.validate(); err != nil {
+		return err
+	}
+
+	s.log.Info("email",
+		logger.Attr{Key: "to", Value: logger.Value{Str: message.To}},
+		logger.Attr{Key: "subject", Value: logger.Value{Str: message.Subject}},
+		logger.Attr{Key: "body", Value: logger.Value{Str: message.Body}},
+	)
+	return nil
+}
+
+// FileSender writes each email to a .eml file of the directory, it is meant for development
+type FileSender struct {
+	dir  string
+	from string
+	seq  atomic.Uint64
+}
+
+func NewFileSender(dir, from string) (*FileSender, error) {
+	if dir == "" {
+		return nil, errors.New("mail dir is required")
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	if from == "" {
+		from = "no-reply@localhost"
+	}
+	return &FileSender{dir: dir, from: from}, nil
+}
+
+func (s *FileSender) Send(ctx context.Context, message *Message) error {
+	if err := message.validate(); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	name := fmt.Sprintf("%s-%06d.eml", now.Format("20060102T150405.000000000"), s.seq.Add(1))
+	content := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nDate: %s\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n%s\r\n",
+		s.from, message.To, message.Subject, now.Format(time.RFC1123Z), message.Body)
+
+	return os.WriteFile(filepath.Join(s.dir, name), []byte(content), 0o600)
+}
@@ -0,0 +1,70 @@
+package mail_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/Tomelin/financial-management-backend/pkg/logger"
+	"github.com/Tomelin/financial-management-backend/pkg/mail"
+)
+
+type SenderTestSuite struct {
+	suite.Suite
+	log logger.Logger
+}
+
+func (s *SenderTestSuite) SetupTest() {
+	s.log = logger.NewLoggerConfig(map[string]any{"level": "error"})
+}
+
+func (s *SenderTestSuite) TestNewSender() {
+	sender, err := mail.NewSender(nil, s.log)
+	s.Require().NoError(err)
+	s.IsType(&mail.LogSender{}, sender)
+
+	sender, err = mail.NewSender(map[string]any{"kind": "file", "dir": s.T().TempDir()}, s.log)
+	s.Require().NoError(err)
+	s.IsType(&mail.FileSender{}, sender)
+
+	_, err = mail.NewSender(map[string]any{"kind": "file"}, s.log)
+	s.Error(err)
+
+	_, err = mail.NewSender(map[string]any{"kind": "smtp"}, s.log)
+	s.Error(err)
+}
+
+func (s *SenderTestSuite) TestFileSender() {
+	dir := s.T().TempDir()
+	sender, err := mail.NewFileSender(dir, "app@domain.com")
+	s.Require().NoError(err)
+
+	for i := 0; i < 2; i++ {
+		s.Require().NoError(sender.Send(context.Background(), &mail.Message{To: "user@domain.com", Subject: "Verify", Body: "token"}))
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	s.Require().NoError(err)
+	s.Require().Len(files, 2)
+
+	content, err := os.ReadFile(files[0])
+	s.Require().NoError(err)
+	s.True(strings.HasPrefix(string(content), "From: app@domain.com\r\nTo: user@domain.com\r\nSubject: Verify\r\n"))
+	s.Contains(string(content), "\r\n\r\ntoken")
+}
+
+func (s *SenderTestSuite) TestSend_Invalid() {
+	sender := mail.NewLogSender(s.log)
+	s.Error(sender.Send(context.Background(), nil))
+	s.Error(sender.Send(context.Background(), &mail.Message{}))
+	s.Error(sender.Send(context.Background(), &mail.Message{To: "user@domain.com\r\nBcc: other@domain.com"}))
+	s.NoError(sender.Send(context.Background(), &mail.Message{To: "user@domain.com", Subject: "Hi"}))
+}
+
+func TestSenderTestSuite(t *testing.T) {
+	suite.Run(t, new(SenderTestSuite))
+}
@@ -0,0 +1,70 @@
+package password_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/Tomelin/financial-management-backend/pkg/password"
+)
+
+// params are cheap parameters, the tests do not measure the cost
+var params = password.Params{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+type PasswordTestSuite struct {
+	suite.Suite
+}
+
+func (s *PasswordTestSuite) TestHashAndVerify() {
+	hash, err := password.HashWith("correct horse battery staple", params)
+	s.Require().NoError(err)
+	s.True(strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$"))
+
+	ok, err := password.Verify("correct horse battery staple", hash)
+	s.NoError(err)
+	s.True(ok)
+
+	ok, err = password.Verify("wrong", hash)
+	s.NoError(err)
+	s.False(ok)
+
+	other, err := password.HashWith("correct horse battery staple", params)
+	s.Require().NoError(err)
+	s.NotEqual(hash, other, "each hash has its own salt")
+}
+
+func (s *PasswordTestSuite) TestDefaultParams() {
+	hash, err := password.Hash("secret-password")
+	s.Require().NoError(err)
+
+	ok, err := password.Verify("secret-password", hash)
+	s.NoError(err)
+	s.True(ok)
+	s.False(password.NeedsRehash(hash, password.DefaultParams))
+	s.True(password.NeedsRehash(hash, params))
+}
+
+func (s *PasswordTestSuite) TestVerify_InvalidHash() {
+	tests := []string{
+		"",
+		"plain",
+		"$2a$10$abcdefghijklmnopqrstuv",
+		"$argon2i$v=19$m=1024,t=1,p=1$c2FsdA$a2V5",
+		"$argon2id$v=19$m=0,t=1,p=1$c2FsdA$a2V5",
+		"$argon2id$v=19$m=1024,t=1,p=1$!!$a2V5",
+	}
+
+	for _, hash := range tests {
+		_, err := password.Verify("secret", hash)
+		s.True(errors.Is(err, password.ErrInvalidHash), hash)
+	}
+
+	_, err := password.Verify("secret", "$argon2id$v=16$m=1024,t=1,p=1$c2FsdA$a2V5")
+	s.True(errors.Is(err, password.ErrIncompatibleVersion))
+}
+
+func TestPasswordTestSuite(t *testing.T) {
+	suite.Run(t, new(PasswordTestSuite))
+}
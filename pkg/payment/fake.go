@@ -0,0 +1,163 @@
+package payment
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FakeProvider keeps the customers and the subscriptions in memory and charges nothing
+// The application completes a checkout and renews a subscription with the signed webhooks it returns.
+type FakeProvider struct {
+	secret      string
+	checkoutURL string
+
+	mu            sync.Mutex
+	customers     map[string]Customer
+	sessions      map[string]Checkout
+	subscriptions map[string]fakeSubscription
+}
+
+type fakeSubscription struct {
+	checkout Checkout
+	canceled bool
+}
+
+// NewFakeProvider creates the fake provider, without a secret the webhooks are signed with a random one
+func NewFakeProvider(secret, checkoutURL string) (*FakeProvider, error) {
+	if secret == "" {
+		b := make([]byte, 32)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		secret = hex.EncodeToString(b)
+	}
+
+	if checkoutURL == "" {
+		checkoutURL = "http://localhost:8080/billing/fake/checkout"
+	}
+
+	return &FakeProvider{
+		secret:        secret,
+		checkoutURL:   strings.TrimSuffix(checkoutURL, "/"),
+		customers:     map[string]Customer{},
+		sessions:      map[string]Checkout{},
+		subscriptions: map[string]fakeSubscription{},
+	}, nil
+}
+
+func (f *FakeProvider) CreateCustomer(ctx context.Context, customer *Customer) (string, error) {
+	if customer == nil || customer.TenantID == "" {
+		return "", errors.New("tenant is required")
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	id := "cus_" + uuid.NewString()
+	f.customers[id] = *customer
+	return id, nil
+}
+
+func (f *FakeProvider) CreateCheckout(ctx context.Context, checkout *Checkout) (*Session, error) {
+	if checkout == nil || checkout.TenantID == "" || checkout.PlanID == "" {
+		return nil, errors.New("tenant and plan are required")
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.customers[checkout.CustomerID]; !ok {
+		return nil, errors.New("customer not found")
+	}
+
+	id := "cs_" + uuid.NewString()
+	f.sessions[id] = *checkout
+	return &Session{ID: id, URL: f.checkoutURL + "/" + id}, nil
+}
+
+func (f *FakeProvider) CancelSubscription(ctx context.Context, subscriptionID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	subscription, ok := f.subscriptions[subscriptionID]
+	if !ok {
+		return errors.New("subscription not found")
+	}
+
+	subscription.canceled = true
+	f.subscriptions[subscriptionID] = subscription
+	return nil
+}
+
+func (f *FakeProvider) ParseWebhook(payload []byte, signature string) (*Event, error) {
+	if err := Verify(f.secret, payload, signature, time.Now()); err != nil {
+		return nil, err
+	}
+
+	var event Event
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// Complete pays the checkout, it returns the signed webhook of the completed checkout
+// A checkout is completed once.
+func (f *FakeProvider) Complete(sessionID string) ([]byte, string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	checkout, ok := f.sessions[sessionID]
+	if !ok {
+		return nil, "", errors.New("checkout not found")
+	}
+	delete(f.sessions, sessionID)
+
+	subscriptionID := "sub_" + uuid.NewString()
+	f.subscriptions[subscriptionID] = fakeSubscription{checkout: checkout}
+
+	return f.event(EventCheckoutCompleted, subscriptionID, checkout)
+}
+
+// Renew charges the renewal of the subscription, it returns the signed webhook of the paid or failed invoice
+func (f *FakeProvider) Renew(subscriptionID string, paid bool) ([]byte, string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	subscription, ok := f.subscriptions[subscriptionID]
+	if !ok || subscription.canceled {
+		return nil, "", errors.New("subscription not found")
+	}
+
+	kind := EventInvoicePaid
+	if !paid {
+		kind = EventInvoiceFailed
+	}
+	return f.event(kind, subscriptionID, subscription.checkout)
+}
+
+func (f *FakeProvider) event(kind, subscriptionID string, checkout Checkout) ([]byte, string, error) {
+	now := time.Now().UTC()
+	payload, err := json.Marshal(Event{
+		ID:             "evt_" + uuid.NewString(),
+		Type:           kind,
+		TenantID:       checkout.TenantID,
+		CustomerID:     checkout.CustomerID,
+		SubscriptionID: subscriptionID,
+		PlanID:         checkout.PlanID,
+		CreatedAt:      now,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return payload, Sign(f.secret, payload, now), nil
+}
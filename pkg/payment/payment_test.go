@@ -0,0 +1,93 @@
+package payment_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/Tomelin/financial-management-backend/pkg/payment"
+)
+
+type PaymentTestSuite struct {
+	suite.Suite
+	ctx context.Context
+}
+
+func (s *PaymentTestSuite) SetupTest() {
+	s.ctx = context.Background()
+}
+
+func (s *PaymentTestSuite) TestNewProvider() {
+	cfg, err := payment.LoadConfig(map[string]any{"kind": "fake", "webhook_secret": "secret"})
+	s.Require().NoError(err)
+	s.Equal("secret", cfg.WebhookSecret)
+
+	provider, err := payment.NewProvider(cfg)
+	s.Require().NoError(err)
+	s.IsType(&payment.FakeProvider{}, provider)
+
+	_, err = payment.NewProvider(payment.Config{Kind: "stripe"})
+	s.Error(err)
+	_, err = payment.NewProvider(payment.Config{})
+	s.Error(err, "the fake provider is not selected without a kind")
+}
+
+func (s *PaymentTestSuite) TestVerify() {
+	payload := []byte(`{"id":"evt_1"}`)
+	now := time.Now()
+	signature := payment.Sign("secret", payload, now)
+
+	s.NoError(payment.Verify("secret", payload, signature, now))
+	s.ErrorIs(payment.Verify("other", payload, signature, now), payment.ErrInvalidSignature)
+	s.ErrorIs(payment.Verify("secret", []byte(`{"id":"evt_2"}`), signature, now), payment.ErrInvalidSignature)
+	s.ErrorIs(payment.Verify("secret", payload, signature, now.Add(payment.SignatureTolerance+time.Second)), payment.ErrInvalidSignature, "an old signature is a replay")
+	s.ErrorIs(payment.Verify("secret", payload, "v1=abc", now), payment.ErrInvalidSignature)
+	s.ErrorIs(payment.Verify("", payload, signature, now), payment.ErrInvalidSignature)
+}
+
+func (s *PaymentTestSuite) TestFakeProvider() {
+	fake, err := payment.NewFakeProvider("secret", "")
+	s.Require().NoError(err)
+
+	_, err = fake.CreateCheckout(s.ctx, &payment.Checkout{CustomerID: "cus_missing", TenantID: "t1", PlanID: "p1"})
+	s.Error(err, "the customer is created first")
+
+	customerID, err := fake.CreateCustomer(s.ctx, &payment.Customer{TenantID: "t1", Email: "owner@domain.com"})
+	s.Require().NoError(err)
+
+	session, err := fake.CreateCheckout(s.ctx, &payment.Checkout{CustomerID: customerID, TenantID: "t1", PlanID: "p1", Amount: "19.90", Currency: "BRL"})
+	s.Require().NoError(err)
+	s.Contains(session.URL, session.ID)
+
+	payload, signature, err := fake.Complete(session.ID)
+	s.Require().NoError(err)
+
+	event, err := fake.ParseWebhook(payload, signature)
+	s.Require().NoError(err)
+	s.Equal(payment.EventCheckoutCompleted, event.Type)
+	s.Equal("t1", event.TenantID)
+	s.Equal("p1", event.PlanID)
+	s.NotEmpty(event.SubscriptionID)
+
+	_, _, err = fake.Complete(session.ID)
+	s.Error(err, "a checkout is completed once")
+
+	payload, signature, err = fake.Renew(event.SubscriptionID, false)
+	s.Require().NoError(err)
+	renewal, err := fake.ParseWebhook(payload, signature)
+	s.Require().NoError(err)
+	s.Equal(payment.EventInvoiceFailed, renewal.Type)
+
+	s.Require().NoError(fake.CancelSubscription(s.ctx, event.SubscriptionID))
+	_, _, err = fake.Renew(event.SubscriptionID, true)
+	s.Error(err, "a canceled subscription is not charged")
+
+	_, err = fake.ParseWebhook(payload, "t=1,v1=abc")
+	s.ErrorIs(err, payment.ErrInvalidSignature)
+}
+
+func TestPaymentTestSuite(t *testing.T) {
+	suite.Run(t, new(PaymentTestSuite))
+}
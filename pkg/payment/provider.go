@@ -0,0 +1,118 @@
+package payment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// KindFake charges nothing, the checkouts are completed by the application, it is meant for development
+	KindFake = "fake"
+
+	// SignatureHeader is the header of the signature of the webhooks
+	SignatureHeader = "X-Signature"
+)
+
+// The types of the events of the webhooks
+const (
+	// EventCheckoutCompleted is sent when the customer pays the first charge of the checkout
+	EventCheckoutCompleted = "checkout.completed"
+	// EventInvoicePaid is sent when a renewal is paid
+	EventInvoicePaid = "invoice.paid"
+	// EventInvoiceFailed is sent when a renewal is not paid
+	EventInvoiceFailed = "invoice.payment_failed"
+	// EventSubscriptionCanceled is sent when the subscription of the provider is canceled
+	EventSubscriptionCanceled = "subscription.canceled"
+)
+
+// ErrInvalidSignature is returned when the signature of a webhook does not match the payload
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// Provider charges the plans of the tenants
+// An adapter of a payment gateway implements it, the events of the gateway are translated to Event
+type Provider interface {
+	// CreateCustomer creates the customer of the tenant and returns its id
+	CreateCustomer(ctx context.Context, customer *Customer) (string, error)
+	// CreateCheckout creates the page the customer subscribes to the plan on
+	CreateCheckout(ctx context.Context, checkout *Checkout) (*Session, error)
+	// CancelSubscription stops the charges of the subscription
+	CancelSubscription(ctx context.Context, subscriptionID string) error
+	// ParseWebhook verifies the signature of the payload and returns its event
+	ParseWebhook(payload []byte, signature string) (*Event, error)
+}
+
+type Customer struct {
+	TenantID string
+	Email    string
+	Name     string
+}
+
+// Checkout subscribes the customer to a plan, the amount is a decimal in the currency
+type Checkout struct {
+	CustomerID string
+	TenantID   string
+	PlanID     string
+	Amount     string
+	Currency   string
+	SuccessURL string
+	CancelURL  string
+}
+
+// Session is a checkout created by the provider, the customer pays on the URL
+type Session struct {
+	ID             string `json:"id"`
+	URL            string `json:"url"`
+	SubscriptionID string `json:"subscription_id"`
+}
+
+// Event is a webhook of the provider, the ID is unique, a provider may send it more than once
+type Event struct {
+	ID             string    `json:"id"`
+	Type           string    `json:"type"`
+	TenantID       string    `json:"tenant_id"`
+	CustomerID     string    `json:"customer_id"`
+	SubscriptionID string    `json:"subscription_id"`
+	PlanID         string    `json:"plan_id"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// Config is the payment section of the configuration
+type Config struct {
+	Kind          string `json:"kind"`
+	WebhookSecret string `json:"webhook_secret"`
+	CheckoutURL   string `json:"checkout_url"`
+	SuccessURL    string `json:"success_url"`
+	CancelURL     string `json:"cancel_url"`
+}
+
+// LoadConfig reads the payment section of the configuration
+func LoadConfig(fields any) (Config, error) {
+	var cfg Config
+	if fields == nil {
+		return cfg, nil
+	}
+
+	b, err := json.Marshal(fields)
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+// NewProvider creates the provider of the kind of the payment section, the kind is required
+func NewProvider(cfg Config) (Provider, error) {
+	switch cfg.Kind {
+	case "":
+		return nil, errors.New("the kind of the payment provider is required")
+	case KindFake:
+		return NewFakeProvider(cfg.WebhookSecret, cfg.CheckoutURL)
+	}
+
+	return nil, fmt.Errorf("unknown payment provider %q", cfg.Kind)
+}
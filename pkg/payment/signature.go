@@ -0,0 +1,68 @@
+package payment
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignatureTolerance is how old the timestamp of a signature can be, it limits the replay of a webhook
+const SignatureTolerance = 5 * time.Minute
+
+// Sign returns the signature of the payload at the time, "t=<unix>,v1=<hex HMAC-SHA256 of t.payload>"
+func Sign(secret string, payload []byte, at time.Time) string {
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	return "t=" + timestamp + ",v1=" + mac(secret, timestamp, payload)
+}
+
+// Verify checks that the signature is of the payload and that it is not older than the tolerance
+// The comparison takes constant time
+func Verify(secret string, payload []byte, signature string, now time.Time) error {
+	if secret == "" {
+		return fmt.Errorf("%w: webhook secret is not configured", ErrInvalidSignature)
+	}
+
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(signature, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return ErrInvalidSignature
+	}
+
+	if age := now.Sub(time.Unix(unix, 0)); age > SignatureTolerance || age < -SignatureTolerance {
+		return fmt.Errorf("%w: timestamp out of tolerance", ErrInvalidSignature)
+	}
+
+	expected := []byte(mac(secret, timestamp, payload))
+	for _, candidate := range signatures {
+		if hmac.Equal(expected, []byte(candidate)) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+func mac(secret, timestamp string, payload []byte) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(timestamp))
+	h.Write([]byte("."))
+	h.Write(payload)
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,79 @@
+package totp_test
+
+import (
+	"encoding/base32"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/Tomelin/financial-management-backend/pkg/totp"
+)
+
+// rfcSecret is the SHA-1 secret of the test vectors of RFC 6238
+var rfcSecret = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+type TOTPTestSuite struct {
+	suite.Suite
+}
+
+func (s *TOTPTestSuite) TestCode_RFC6238() {
+	// the codes are the last 6 digits of the 8 digits of the RFC
+	vectors := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1111111111: "050471",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+
+	for unix, want := range vectors {
+		code, err := totp.Code(rfcSecret, time.Unix(unix, 0))
+		s.Require().NoError(err)
+		s.Equal(want, code, "time %d", unix)
+	}
+}
+
+func (s *TOTPTestSuite) TestValidate() {
+	secret, err := totp.GenerateSecret()
+	s.Require().NoError(err)
+	s.Len(secret, 32)
+
+	now := time.Unix(1700000000, 0)
+	code, err := totp.Code(secret, now)
+	s.Require().NoError(err)
+
+	step, ok := totp.Validate(secret, code, now, 1)
+	s.True(ok)
+	s.Equal(totp.Step(now), step)
+
+	// the code of the previous step is accepted inside the skew
+	step, ok = totp.Validate(secret, code, now.Add(totp.Period), 1)
+	s.True(ok)
+	s.Equal(totp.Step(now), step)
+
+	_, ok = totp.Validate(secret, code, now.Add(2*totp.Period), 1)
+	s.False(ok)
+	_, ok = totp.Validate(secret, "12345", now, 1)
+	s.False(ok)
+	_, ok = totp.Validate("not base32!", code, now, 1)
+	s.False(ok)
+}
+
+func (s *TOTPTestSuite) TestURI() {
+	uri := totp.URI("Financial Management", "user@domain.com", "SECRET")
+
+	u, err := url.Parse(uri)
+	s.Require().NoError(err)
+	s.Equal("otpauth", u.Scheme)
+	s.Equal("totp", u.Host)
+	s.Equal("/Financial Management:user@domain.com", u.Path)
+	s.Equal("SECRET", u.Query().Get("secret"))
+	s.Equal("Financial Management", u.Query().Get("issuer"))
+	s.Equal("6", u.Query().Get("digits"))
+}
+
+func TestTOTPTestSuite(t *testing.T) {
+	suite.Run(t, new(TOTPTestSuite))
+}
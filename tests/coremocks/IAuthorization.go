@@ -15,23 +15,23 @@ type IAuthorization struct {
 }
 
 // GenerateTokenJWT provides a mock function with given fields: ctx, token, user
-func (_m *IAuthorization) GenerateTokenJWT(ctx context.Context, token *entity.AuthorizationClaims, user *entity.AccountUser) (*string, error) {
+func (_m *IAuthorization) GenerateTokenJWT(ctx context.Context, token *entity.AuthorizationClaims, user *entity.AccountUser) (*entity.TokenPair, error) {
 	ret := _m.Called(ctx, token, user)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GenerateTokenJWT")
 	}
 
-	var r0 *string
+	var r0 *entity.TokenPair
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, *entity.AuthorizationClaims, *entity.AccountUser) (*string, error)); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.AuthorizationClaims, *entity.AccountUser) (*entity.TokenPair, error)); ok {
 		return rf(ctx, token, user)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, *entity.AuthorizationClaims, *entity.AccountUser) *string); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.AuthorizationClaims, *entity.AccountUser) *entity.TokenPair); ok {
 		r0 = rf(ctx, token, user)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*string)
+			r0 = ret.Get(0).(*entity.TokenPair)
 		}
 	}
 
@@ -44,47 +44,89 @@ func (_m *IAuthorization) GenerateTokenJWT(ctx context.Context, token *entity.Au
 	return r0, r1
 }
 
+// ListSessions provides a mock function with given fields: ctx, userID
+func (_m *IAuthorization) ListSessions(ctx context.Context, userID string) ([]entity.Session, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListSessions")
+	}
+
+	var r0 []entity.Session
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]entity.Session, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []entity.Session); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Session)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ParseTokenJWT provides a mock function with given fields: ctx, token
-func (_m *IAuthorization) ParseTokenJWT(ctx context.Context, token string) error {
+func (_m *IAuthorization) ParseTokenJWT(ctx context.Context, token string) (*entity.AuthorizationClaims, error) {
 	ret := _m.Called(ctx, token)
 
 	if len(ret) == 0 {
 		panic("no return value specified for ParseTokenJWT")
 	}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+	var r0 *entity.AuthorizationClaims
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*entity.AuthorizationClaims, error)); ok {
+		return rf(ctx, token)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *entity.AuthorizationClaims); ok {
 		r0 = rf(ctx, token)
 	} else {
-		r0 = ret.Error(0)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.AuthorizationClaims)
+		}
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
-// RefreshTokenJWT provides a mock function with given fields: ctx, token
-func (_m *IAuthorization) RefreshTokenJWT(ctx context.Context, token *string) (*string, error) {
-	ret := _m.Called(ctx, token)
+// RefreshTokenJWT provides a mock function with given fields: ctx, refreshToken
+func (_m *IAuthorization) RefreshTokenJWT(ctx context.Context, refreshToken string) (*entity.TokenPair, error) {
+	ret := _m.Called(ctx, refreshToken)
 
 	if len(ret) == 0 {
 		panic("no return value specified for RefreshTokenJWT")
 	}
 
-	var r0 *string
+	var r0 *entity.TokenPair
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, *string) (*string, error)); ok {
-		return rf(ctx, token)
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*entity.TokenPair, error)); ok {
+		return rf(ctx, refreshToken)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, *string) *string); ok {
-		r0 = rf(ctx, token)
+	if rf, ok := ret.Get(0).(func(context.Context, string) *entity.TokenPair); ok {
+		r0 = rf(ctx, refreshToken)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*string)
+			r0 = ret.Get(0).(*entity.TokenPair)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, *string) error); ok {
-		r1 = rf(ctx, token)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, refreshToken)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -92,17 +134,35 @@ func (_m *IAuthorization) RefreshTokenJWT(ctx context.Context, token *string) (*
 	return r0, r1
 }
 
-// RevokeTokenJWT provides a mock function with given fields: ctx, token
-func (_m *IAuthorization) RevokeTokenJWT(ctx context.Context, token *string) error {
-	ret := _m.Called(ctx, token)
+// RevokeAllTokenJWT provides a mock function with given fields: ctx, userID
+func (_m *IAuthorization) RevokeAllTokenJWT(ctx context.Context, userID string) error {
+	ret := _m.Called(ctx, userID)
 
 	if len(ret) == 0 {
-		panic("no return value specified for RevokeTokenJWT")
+		panic("no return value specified for RevokeAllTokenJWT")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, *string) error); ok {
-		r0 = rf(ctx, token)
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RevokeSession provides a mock function with given fields: ctx, userID, id
+func (_m *IAuthorization) RevokeSession(ctx context.Context, userID string, id string) error {
+	ret := _m.Called(ctx, userID, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeSession")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, userID, id)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -110,17 +170,17 @@ func (_m *IAuthorization) RevokeTokenJWT(ctx context.Context, token *string) err
 	return r0
 }
 
-// StoreTokenJWT provides a mock function with given fields: ctx, token, userId
-func (_m *IAuthorization) StoreTokenJWT(ctx context.Context, token []byte, userId *string) error {
-	ret := _m.Called(ctx, token, userId)
+// RevokeTokenJWT provides a mock function with given fields: ctx, token
+func (_m *IAuthorization) RevokeTokenJWT(ctx context.Context, token *string) error {
+	ret := _m.Called(ctx, token)
 
 	if len(ret) == 0 {
-		panic("no return value specified for StoreTokenJWT")
+		panic("no return value specified for RevokeTokenJWT")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, []byte, *string) error); ok {
-		r0 = rf(ctx, token, userId)
+	if rf, ok := ret.Get(0).(func(context.Context, *string) error); ok {
+		r0 = rf(ctx, token)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -14,6 +14,42 @@ type IAuthorizationRepo struct {
 	mock.Mock
 }
 
+// CreateRefreshToken provides a mock function with given fields: ctx, token
+func (_m *IAuthorizationRepo) CreateRefreshToken(ctx context.Context, token *entity.RefreshToken) error {
+	ret := _m.Called(ctx, token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateRefreshToken")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.RefreshToken) error); ok {
+		r0 = rf(ctx, token)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteSession provides a mock function with given fields: ctx, id
+func (_m *IAuthorizationRepo) DeleteSession(ctx context.Context, id string) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteSession")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // GenerateTokenJWT provides a mock function with given fields: ctx, token, user
 func (_m *IAuthorizationRepo) GenerateTokenJWT(ctx context.Context, token *entity.AuthorizationClaims, user *entity.AccountUser) (*string, error) {
 	ret := _m.Called(ctx, token, user)
@@ -44,47 +80,143 @@ func (_m *IAuthorizationRepo) GenerateTokenJWT(ctx context.Context, token *entit
 	return r0, r1
 }
 
-// ParseTokenJWT provides a mock function with given fields: ctx, token
-func (_m *IAuthorizationRepo) ParseTokenJWT(ctx context.Context, token string) error {
-	ret := _m.Called(ctx, token)
+// GetRefreshToken provides a mock function with given fields: ctx, id
+func (_m *IAuthorizationRepo) GetRefreshToken(ctx context.Context, id string) (*entity.RefreshToken, error) {
+	ret := _m.Called(ctx, id)
 
 	if len(ret) == 0 {
-		panic("no return value specified for ParseTokenJWT")
+		panic("no return value specified for GetRefreshToken")
 	}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
-		r0 = rf(ctx, token)
+	var r0 *entity.RefreshToken
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*entity.RefreshToken, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *entity.RefreshToken); ok {
+		r0 = rf(ctx, id)
 	} else {
-		r0 = ret.Error(0)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.RefreshToken)
+		}
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
-// RefreshTokenJWT provides a mock function with given fields: ctx, token
-func (_m *IAuthorizationRepo) RefreshTokenJWT(ctx context.Context, token *string) (*string, error) {
-	ret := _m.Called(ctx, token)
+// GetSession provides a mock function with given fields: ctx, id
+func (_m *IAuthorizationRepo) GetSession(ctx context.Context, id string) (*entity.Session, error) {
+	ret := _m.Called(ctx, id)
 
 	if len(ret) == 0 {
-		panic("no return value specified for RefreshTokenJWT")
+		panic("no return value specified for GetSession")
 	}
 
-	var r0 *string
+	var r0 *entity.Session
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, *string) (*string, error)); ok {
-		return rf(ctx, token)
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*entity.Session, error)); ok {
+		return rf(ctx, id)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, *string) *string); ok {
-		r0 = rf(ctx, token)
+	if rf, ok := ret.Get(0).(func(context.Context, string) *entity.Session); ok {
+		r0 = rf(ctx, id)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*string)
+			r0 = ret.Get(0).(*entity.Session)
 		}
 	}
 
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTokenGeneration provides a mock function with given fields: ctx, userID
+func (_m *IAuthorizationRepo) GetTokenGeneration(ctx context.Context, userID string) (int64, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTokenGeneration")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (int64, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) int64); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IncrementTokenGeneration provides a mock function with given fields: ctx, userID
+func (_m *IAuthorizationRepo) IncrementTokenGeneration(ctx context.Context, userID string) (int64, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IncrementTokenGeneration")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (int64, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) int64); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IsRevokedTokenJWT provides a mock function with given fields: ctx, tokenId
+func (_m *IAuthorizationRepo) IsRevokedTokenJWT(ctx context.Context, tokenId *string) (bool, error) {
+	ret := _m.Called(ctx, tokenId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IsRevokedTokenJWT")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *string) (bool, error)); ok {
+		return rf(ctx, tokenId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *string) bool); ok {
+		r0 = rf(ctx, tokenId)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
 	if rf, ok := ret.Get(1).(func(context.Context, *string) error); ok {
-		r1 = rf(ctx, token)
+		r1 = rf(ctx, tokenId)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -92,9 +224,57 @@ func (_m *IAuthorizationRepo) RefreshTokenJWT(ctx context.Context, token *string
 	return r0, r1
 }
 
-// RevokeTokenJWT provides a mock function with given fields: ctx, token
-func (_m *IAuthorizationRepo) RevokeTokenJWT(ctx context.Context, token *string) error {
-	ret := _m.Called(ctx, token)
+// ListSessions provides a mock function with given fields: ctx, userID
+func (_m *IAuthorizationRepo) ListSessions(ctx context.Context, userID string) ([]entity.Session, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListSessions")
+	}
+
+	var r0 []entity.Session
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]entity.Session, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []entity.Session); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Session)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RevokeRefreshTokenFamily provides a mock function with given fields: ctx, family
+func (_m *IAuthorizationRepo) RevokeRefreshTokenFamily(ctx context.Context, family string) error {
+	ret := _m.Called(ctx, family)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeRefreshTokenFamily")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, family)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RevokeTokenJWT provides a mock function with given fields: ctx, tokenId
+func (_m *IAuthorizationRepo) RevokeTokenJWT(ctx context.Context, tokenId *string) error {
+	ret := _m.Called(ctx, tokenId)
 
 	if len(ret) == 0 {
 		panic("no return value specified for RevokeTokenJWT")
@@ -102,7 +282,7 @@ func (_m *IAuthorizationRepo) RevokeTokenJWT(ctx context.Context, token *string)
 
 	var r0 error
 	if rf, ok := ret.Get(0).(func(context.Context, *string) error); ok {
-		r0 = rf(ctx, token)
+		r0 = rf(ctx, tokenId)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -110,17 +290,17 @@ func (_m *IAuthorizationRepo) RevokeTokenJWT(ctx context.Context, token *string)
 	return r0
 }
 
-// StoreTokenJWT provides a mock function with given fields: ctx, token, userId
-func (_m *IAuthorizationRepo) StoreTokenJWT(ctx context.Context, token []byte, userId *string) error {
-	ret := _m.Called(ctx, token, userId)
+// SaveSession provides a mock function with given fields: ctx, session
+func (_m *IAuthorizationRepo) SaveSession(ctx context.Context, session *entity.Session) error {
+	ret := _m.Called(ctx, session)
 
 	if len(ret) == 0 {
-		panic("no return value specified for StoreTokenJWT")
+		panic("no return value specified for SaveSession")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, []byte, *string) error); ok {
-		r0 = rf(ctx, token, userId)
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.Session) error); ok {
+		r0 = rf(ctx, session)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -128,29 +308,27 @@ func (_m *IAuthorizationRepo) StoreTokenJWT(ctx context.Context, token []byte, u
 	return r0
 }
 
-// ValidateTokenJWT provides a mock function with given fields: ctx, token
-func (_m *IAuthorizationRepo) ValidateTokenJWT(ctx context.Context, token string) (*entity.AccountUser, error) {
-	ret := _m.Called(ctx, token)
+// UseRefreshToken provides a mock function with given fields: ctx, id
+func (_m *IAuthorizationRepo) UseRefreshToken(ctx context.Context, id string) (bool, error) {
+	ret := _m.Called(ctx, id)
 
 	if len(ret) == 0 {
-		panic("no return value specified for ValidateTokenJWT")
+		panic("no return value specified for UseRefreshToken")
 	}
 
-	var r0 *entity.AccountUser
+	var r0 bool
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) (*entity.AccountUser, error)); ok {
-		return rf(ctx, token)
+	if rf, ok := ret.Get(0).(func(context.Context, string) (bool, error)); ok {
+		return rf(ctx, id)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) *entity.AccountUser); ok {
-		r0 = rf(ctx, token)
+	if rf, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = rf(ctx, id)
 	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*entity.AccountUser)
-		}
+		r0 = ret.Get(0).(bool)
 	}
 
 	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, token)
+		r1 = rf(ctx, id)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -60,29 +60,29 @@ func (_m *IPlanRepo) Delete(id *string) error {
 	return r0
 }
 
-// Get provides a mock function with no fields
-func (_m *IPlanRepo) Get() ([]entity.PlanResponse, error) {
-	ret := _m.Called()
+// Get provides a mock function with given fields: page
+func (_m *IPlanRepo) Get(page *entity.PageRequest) (*entity.Page[entity.PlanResponse], error) {
+	ret := _m.Called(page)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Get")
 	}
 
-	var r0 []entity.PlanResponse
+	var r0 *entity.Page[entity.PlanResponse]
 	var r1 error
-	if rf, ok := ret.Get(0).(func() ([]entity.PlanResponse, error)); ok {
-		return rf()
+	if rf, ok := ret.Get(0).(func(*entity.PageRequest) (*entity.Page[entity.PlanResponse], error)); ok {
+		return rf(page)
 	}
-	if rf, ok := ret.Get(0).(func() []entity.PlanResponse); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(*entity.PageRequest) *entity.Page[entity.PlanResponse]); ok {
+		r0 = rf(page)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]entity.PlanResponse)
+			r0 = ret.Get(0).(*entity.Page[entity.PlanResponse])
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func() error); ok {
-		r1 = rf()
+	if rf, ok := ret.Get(1).(func(*entity.PageRequest) error); ok {
+		r1 = rf(page)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -90,9 +90,9 @@ func (_m *IPlanRepo) Get() ([]entity.PlanResponse, error) {
 	return r0, r1
 }
 
-// GetByFilterMany provides a mock function with given fields: key, value
-func (_m *IPlanRepo) GetByFilterMany(key string, value *string) ([]entity.PlanResponse, error) {
-	ret := _m.Called(key, value)
+// GetByFilterMany provides a mock function with given fields: filter
+func (_m *IPlanRepo) GetByFilterMany(filter []entity.QueryDBClause) ([]entity.PlanResponse, error) {
+	ret := _m.Called(filter)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetByFilterMany")
@@ -100,19 +100,19 @@ func (_m *IPlanRepo) GetByFilterMany(key string, value *string) ([]entity.PlanRe
 
 	var r0 []entity.PlanResponse
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string, *string) ([]entity.PlanResponse, error)); ok {
-		return rf(key, value)
+	if rf, ok := ret.Get(0).(func([]entity.QueryDBClause) ([]entity.PlanResponse, error)); ok {
+		return rf(filter)
 	}
-	if rf, ok := ret.Get(0).(func(string, *string) []entity.PlanResponse); ok {
-		r0 = rf(key, value)
+	if rf, ok := ret.Get(0).(func([]entity.QueryDBClause) []entity.PlanResponse); ok {
+		r0 = rf(filter)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]entity.PlanResponse)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string, *string) error); ok {
-		r1 = rf(key, value)
+	if rf, ok := ret.Get(1).(func([]entity.QueryDBClause) error); ok {
+		r1 = rf(filter)
 	} else {
 		r1 = ret.Error(1)
 	}
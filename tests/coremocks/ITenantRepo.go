@@ -62,29 +62,29 @@ func (_m *ITenantRepo) Delete(id *string) error {
 	return r0
 }
 
-// Get provides a mock function with no fields
-func (_m *ITenantRepo) Get() ([]entity.TenantResponse, error) {
-	ret := _m.Called()
+// Get provides a mock function with given fields: page
+func (_m *ITenantRepo) Get(page *entity.PageRequest) (*entity.Page[entity.TenantResponse], error) {
+	ret := _m.Called(page)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Get")
 	}
 
-	var r0 []entity.TenantResponse
+	var r0 *entity.Page[entity.TenantResponse]
 	var r1 error
-	if rf, ok := ret.Get(0).(func() ([]entity.TenantResponse, error)); ok {
-		return rf()
+	if rf, ok := ret.Get(0).(func(*entity.PageRequest) (*entity.Page[entity.TenantResponse], error)); ok {
+		return rf(page)
 	}
-	if rf, ok := ret.Get(0).(func() []entity.TenantResponse); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(*entity.PageRequest) *entity.Page[entity.TenantResponse]); ok {
+		r0 = rf(page)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]entity.TenantResponse)
+			r0 = ret.Get(0).(*entity.Page[entity.TenantResponse])
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func() error); ok {
-		r1 = rf()
+	if rf, ok := ret.Get(1).(func(*entity.PageRequest) error); ok {
+		r1 = rf(page)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -93,7 +93,7 @@ func (_m *ITenantRepo) Get() ([]entity.TenantResponse, error) {
 }
 
 // GetByFilterMany provides a mock function with given fields: ctx, filter
-func (_m *ITenantRepo) GetByFilterMany(ctx context.Context, filter []entity.QueryDB) ([]entity.TenantResponse, error) {
+func (_m *ITenantRepo) GetByFilterMany(ctx context.Context, filter []entity.QueryDBClause) ([]entity.TenantResponse, error) {
 	ret := _m.Called(ctx, filter)
 
 	if len(ret) == 0 {
@@ -102,10 +102,10 @@ func (_m *ITenantRepo) GetByFilterMany(ctx context.Context, filter []entity.Quer
 
 	var r0 []entity.TenantResponse
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, []entity.QueryDB) ([]entity.TenantResponse, error)); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, []entity.QueryDBClause) ([]entity.TenantResponse, error)); ok {
 		return rf(ctx, filter)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, []entity.QueryDB) []entity.TenantResponse); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, []entity.QueryDBClause) []entity.TenantResponse); ok {
 		r0 = rf(ctx, filter)
 	} else {
 		if ret.Get(0) != nil {
@@ -113,7 +113,7 @@ func (_m *ITenantRepo) GetByFilterMany(ctx context.Context, filter []entity.Quer
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, []entity.QueryDB) error); ok {
+	if rf, ok := ret.Get(1).(func(context.Context, []entity.QueryDBClause) error); ok {
 		r1 = rf(ctx, filter)
 	} else {
 		r1 = ret.Error(1)
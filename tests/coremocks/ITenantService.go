@@ -62,29 +62,29 @@ func (_m *ITenantService) Delete(id *string) error {
 	return r0
 }
 
-// Get provides a mock function with no fields
-func (_m *ITenantService) Get() ([]entity.TenantResponse, error) {
-	ret := _m.Called()
+// Get provides a mock function with given fields: page
+func (_m *ITenantService) Get(page *entity.PageRequest) (*entity.Page[entity.TenantResponse], error) {
+	ret := _m.Called(page)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Get")
 	}
 
-	var r0 []entity.TenantResponse
+	var r0 *entity.Page[entity.TenantResponse]
 	var r1 error
-	if rf, ok := ret.Get(0).(func() ([]entity.TenantResponse, error)); ok {
-		return rf()
+	if rf, ok := ret.Get(0).(func(*entity.PageRequest) (*entity.Page[entity.TenantResponse], error)); ok {
+		return rf(page)
 	}
-	if rf, ok := ret.Get(0).(func() []entity.TenantResponse); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(*entity.PageRequest) *entity.Page[entity.TenantResponse]); ok {
+		r0 = rf(page)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]entity.TenantResponse)
+			r0 = ret.Get(0).(*entity.Page[entity.TenantResponse])
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func() error); ok {
-		r1 = rf()
+	if rf, ok := ret.Get(1).(func(*entity.PageRequest) error); ok {
+		r1 = rf(page)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -93,7 +93,7 @@ func (_m *ITenantService) Get() ([]entity.TenantResponse, error) {
 }
 
 // GetByFilterMany provides a mock function with given fields: ctx, filter
-func (_m *ITenantService) GetByFilterMany(ctx context.Context, filter []entity.QueryDB) ([]entity.TenantResponse, error) {
+func (_m *ITenantService) GetByFilterMany(ctx context.Context, filter []entity.QueryDBClause) ([]entity.TenantResponse, error) {
 	ret := _m.Called(ctx, filter)
 
 	if len(ret) == 0 {
@@ -102,10 +102,10 @@ func (_m *ITenantService) GetByFilterMany(ctx context.Context, filter []entity.Q
 
 	var r0 []entity.TenantResponse
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, []entity.QueryDB) ([]entity.TenantResponse, error)); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, []entity.QueryDBClause) ([]entity.TenantResponse, error)); ok {
 		return rf(ctx, filter)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, []entity.QueryDB) []entity.TenantResponse); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, []entity.QueryDBClause) []entity.TenantResponse); ok {
 		r0 = rf(ctx, filter)
 	} else {
 		if ret.Get(0) != nil {
@@ -113,7 +113,7 @@ func (_m *ITenantService) GetByFilterMany(ctx context.Context, filter []entity.Q
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, []entity.QueryDB) error); ok {
+	if rf, ok := ret.Get(1).(func(context.Context, []entity.QueryDBClause) error); ok {
 		r1 = rf(ctx, filter)
 	} else {
 		r1 = ret.Error(1)
@@ -230,6 +230,36 @@ func (_m *ITenantService) SetPlan(id *string, plan *entity.PlanResponse) error {
 	return r0
 }
 
+// SetSuspended provides a mock function with given fields: id, suspended, reason
+func (_m *ITenantService) SetSuspended(id *string, suspended bool, reason string) (*entity.TenantResponse, error) {
+	ret := _m.Called(id, suspended, reason)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetSuspended")
+	}
+
+	var r0 *entity.TenantResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*string, bool, string) (*entity.TenantResponse, error)); ok {
+		return rf(id, suspended, reason)
+	}
+	if rf, ok := ret.Get(0).(func(*string, bool, string) *entity.TenantResponse); ok {
+		r0 = rf(id, suspended, reason)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.TenantResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*string, bool, string) error); ok {
+		r1 = rf(id, suspended, reason)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Update provides a mock function with given fields: data
 func (_m *ITenantService) Update(data *entity.TenantResponse) (*entity.TenantResponse, error) {
 	ret := _m.Called(data)
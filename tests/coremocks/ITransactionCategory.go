@@ -66,29 +66,29 @@ func (_m *ITransactionCategory) Delete(ctx context.Context, email *string, id *s
 	return r0
 }
 
-// Get provides a mock function with given fields: ctx, email, walletID
-func (_m *ITransactionCategory) Get(ctx context.Context, email *string, walletID *string) ([]entity.TransactionCategory, *entity.ModuleError) {
-	ret := _m.Called(ctx, email, walletID)
+// Get provides a mock function with given fields: ctx, email, walletID, page
+func (_m *ITransactionCategory) Get(ctx context.Context, email *string, walletID *string, page *entity.PageRequest) (*entity.Page[entity.TransactionCategory], *entity.ModuleError) {
+	ret := _m.Called(ctx, email, walletID, page)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Get")
 	}
 
-	var r0 []entity.TransactionCategory
+	var r0 *entity.Page[entity.TransactionCategory]
 	var r1 *entity.ModuleError
-	if rf, ok := ret.Get(0).(func(context.Context, *string, *string) ([]entity.TransactionCategory, *entity.ModuleError)); ok {
-		return rf(ctx, email, walletID)
+	if rf, ok := ret.Get(0).(func(context.Context, *string, *string, *entity.PageRequest) (*entity.Page[entity.TransactionCategory], *entity.ModuleError)); ok {
+		return rf(ctx, email, walletID, page)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, *string, *string) []entity.TransactionCategory); ok {
-		r0 = rf(ctx, email, walletID)
+	if rf, ok := ret.Get(0).(func(context.Context, *string, *string, *entity.PageRequest) *entity.Page[entity.TransactionCategory]); ok {
+		r0 = rf(ctx, email, walletID, page)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]entity.TransactionCategory)
+			r0 = ret.Get(0).(*entity.Page[entity.TransactionCategory])
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, *string, *string) *entity.ModuleError); ok {
-		r1 = rf(ctx, email, walletID)
+	if rf, ok := ret.Get(1).(func(context.Context, *string, *string, *entity.PageRequest) *entity.ModuleError); ok {
+		r1 = rf(ctx, email, walletID, page)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(*entity.ModuleError)
@@ -99,7 +99,7 @@ func (_m *ITransactionCategory) Get(ctx context.Context, email *string, walletID
 }
 
 // GetByFilterMany provides a mock function with given fields: ctx, email, filter
-func (_m *ITransactionCategory) GetByFilterMany(ctx context.Context, email *string, filter []entity.QueryDB) ([]entity.TransactionCategory, *entity.ModuleError) {
+func (_m *ITransactionCategory) GetByFilterMany(ctx context.Context, email *string, filter []entity.QueryDBClause) ([]entity.TransactionCategory, *entity.ModuleError) {
 	ret := _m.Called(ctx, email, filter)
 
 	if len(ret) == 0 {
@@ -108,10 +108,10 @@ func (_m *ITransactionCategory) GetByFilterMany(ctx context.Context, email *stri
 
 	var r0 []entity.TransactionCategory
 	var r1 *entity.ModuleError
-	if rf, ok := ret.Get(0).(func(context.Context, *string, []entity.QueryDB) ([]entity.TransactionCategory, *entity.ModuleError)); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, *string, []entity.QueryDBClause) ([]entity.TransactionCategory, *entity.ModuleError)); ok {
 		return rf(ctx, email, filter)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, *string, []entity.QueryDB) []entity.TransactionCategory); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, *string, []entity.QueryDBClause) []entity.TransactionCategory); ok {
 		r0 = rf(ctx, email, filter)
 	} else {
 		if ret.Get(0) != nil {
@@ -119,7 +119,7 @@ func (_m *ITransactionCategory) GetByFilterMany(ctx context.Context, email *stri
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, *string, []entity.QueryDB) *entity.ModuleError); ok {
+	if rf, ok := ret.Get(1).(func(context.Context, *string, []entity.QueryDBClause) *entity.ModuleError); ok {
 		r1 = rf(ctx, email, filter)
 	} else {
 		if ret.Get(1) != nil {
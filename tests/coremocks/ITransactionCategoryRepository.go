@@ -66,29 +66,29 @@ func (_m *ITransactionCategoryRepository) Delete(ctx context.Context, id *string
 	return r0
 }
 
-// Get provides a mock function with given fields: ctx, walletID
-func (_m *ITransactionCategoryRepository) Get(ctx context.Context, walletID *string) ([]entity.TransactionCategory, *entity.ModuleError) {
-	ret := _m.Called(ctx, walletID)
+// Get provides a mock function with given fields: ctx, filter, page
+func (_m *ITransactionCategoryRepository) Get(ctx context.Context, filter []entity.QueryDBClause, page *entity.PageRequest) (*entity.Page[entity.TransactionCategory], *entity.ModuleError) {
+	ret := _m.Called(ctx, filter, page)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Get")
 	}
 
-	var r0 []entity.TransactionCategory
+	var r0 *entity.Page[entity.TransactionCategory]
 	var r1 *entity.ModuleError
-	if rf, ok := ret.Get(0).(func(context.Context, *string) ([]entity.TransactionCategory, *entity.ModuleError)); ok {
-		return rf(ctx, walletID)
+	if rf, ok := ret.Get(0).(func(context.Context, []entity.QueryDBClause, *entity.PageRequest) (*entity.Page[entity.TransactionCategory], *entity.ModuleError)); ok {
+		return rf(ctx, filter, page)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, *string) []entity.TransactionCategory); ok {
-		r0 = rf(ctx, walletID)
+	if rf, ok := ret.Get(0).(func(context.Context, []entity.QueryDBClause, *entity.PageRequest) *entity.Page[entity.TransactionCategory]); ok {
+		r0 = rf(ctx, filter, page)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]entity.TransactionCategory)
+			r0 = ret.Get(0).(*entity.Page[entity.TransactionCategory])
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, *string) *entity.ModuleError); ok {
-		r1 = rf(ctx, walletID)
+	if rf, ok := ret.Get(1).(func(context.Context, []entity.QueryDBClause, *entity.PageRequest) *entity.ModuleError); ok {
+		r1 = rf(ctx, filter, page)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(*entity.ModuleError)
@@ -62,29 +62,29 @@ func (_m *IUser) Delete(ctx context.Context, id *string) error {
 	return r0
 }
 
-// Get provides a mock function with given fields: ctx
-func (_m *IUser) Get(ctx context.Context) ([]entity.AccountUser, error) {
-	ret := _m.Called(ctx)
+// Get provides a mock function with given fields: ctx, page
+func (_m *IUser) Get(ctx context.Context, page *entity.PageRequest) (*entity.Page[entity.AccountUser], error) {
+	ret := _m.Called(ctx, page)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Get")
 	}
 
-	var r0 []entity.AccountUser
+	var r0 *entity.Page[entity.AccountUser]
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context) ([]entity.AccountUser, error)); ok {
-		return rf(ctx)
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.PageRequest) (*entity.Page[entity.AccountUser], error)); ok {
+		return rf(ctx, page)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context) []entity.AccountUser); ok {
-		r0 = rf(ctx)
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.PageRequest) *entity.Page[entity.AccountUser]); ok {
+		r0 = rf(ctx, page)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]entity.AccountUser)
+			r0 = ret.Get(0).(*entity.Page[entity.AccountUser])
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
-		r1 = rf(ctx)
+	if rf, ok := ret.Get(1).(func(context.Context, *entity.PageRequest) error); ok {
+		r1 = rf(ctx, page)
 	} else {
 		r1 = ret.Error(1)
 	}
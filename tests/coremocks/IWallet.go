@@ -66,29 +66,29 @@ func (_m *IWallet) Delete(ctx context.Context, userId *string, walletId *string)
 	return r0
 }
 
-// Get provides a mock function with given fields: ctx, userId
-func (_m *IWallet) Get(ctx context.Context, userId *string) ([]entity.WalletResponse, *entity.ModuleError) {
-	ret := _m.Called(ctx, userId)
+// Get provides a mock function with given fields: ctx, userId, page
+func (_m *IWallet) Get(ctx context.Context, userId *string, page *entity.PageRequest) (*entity.Page[entity.WalletResponse], *entity.ModuleError) {
+	ret := _m.Called(ctx, userId, page)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Get")
 	}
 
-	var r0 []entity.WalletResponse
+	var r0 *entity.Page[entity.WalletResponse]
 	var r1 *entity.ModuleError
-	if rf, ok := ret.Get(0).(func(context.Context, *string) ([]entity.WalletResponse, *entity.ModuleError)); ok {
-		return rf(ctx, userId)
+	if rf, ok := ret.Get(0).(func(context.Context, *string, *entity.PageRequest) (*entity.Page[entity.WalletResponse], *entity.ModuleError)); ok {
+		return rf(ctx, userId, page)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, *string) []entity.WalletResponse); ok {
-		r0 = rf(ctx, userId)
+	if rf, ok := ret.Get(0).(func(context.Context, *string, *entity.PageRequest) *entity.Page[entity.WalletResponse]); ok {
+		r0 = rf(ctx, userId, page)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]entity.WalletResponse)
+			r0 = ret.Get(0).(*entity.Page[entity.WalletResponse])
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, *string) *entity.ModuleError); ok {
-		r1 = rf(ctx, userId)
+	if rf, ok := ret.Get(1).(func(context.Context, *string, *entity.PageRequest) *entity.ModuleError); ok {
+		r1 = rf(ctx, userId, page)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(*entity.ModuleError)
@@ -99,7 +99,7 @@ func (_m *IWallet) Get(ctx context.Context, userId *string) ([]entity.WalletResp
 }
 
 // GetByFilterMany provides a mock function with given fields: ctx, userId, filter
-func (_m *IWallet) GetByFilterMany(ctx context.Context, userId *string, filter []entity.QueryDB) ([]entity.WalletResponse, *entity.ModuleError) {
+func (_m *IWallet) GetByFilterMany(ctx context.Context, userId *string, filter []entity.QueryDBClause) ([]entity.WalletResponse, *entity.ModuleError) {
 	ret := _m.Called(ctx, userId, filter)
 
 	if len(ret) == 0 {
@@ -108,10 +108,10 @@ func (_m *IWallet) GetByFilterMany(ctx context.Context, userId *string, filter [
 
 	var r0 []entity.WalletResponse
 	var r1 *entity.ModuleError
-	if rf, ok := ret.Get(0).(func(context.Context, *string, []entity.QueryDB) ([]entity.WalletResponse, *entity.ModuleError)); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, *string, []entity.QueryDBClause) ([]entity.WalletResponse, *entity.ModuleError)); ok {
 		return rf(ctx, userId, filter)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, *string, []entity.QueryDB) []entity.WalletResponse); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, *string, []entity.QueryDBClause) []entity.WalletResponse); ok {
 		r0 = rf(ctx, userId, filter)
 	} else {
 		if ret.Get(0) != nil {
@@ -119,7 +119,7 @@ func (_m *IWallet) GetByFilterMany(ctx context.Context, userId *string, filter [
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, *string, []entity.QueryDB) *entity.ModuleError); ok {
+	if rf, ok := ret.Get(1).(func(context.Context, *string, []entity.QueryDBClause) *entity.ModuleError); ok {
 		r1 = rf(ctx, userId, filter)
 	} else {
 		if ret.Get(1) != nil {
@@ -258,9 +258,9 @@ func (_m *IWallet) Update(ctx context.Context, userId *string, data *entity.Wall
 	return r0, r1
 }
 
-// UpdateBalance provides a mock function with given fields: ctx, walletID, balance
-func (_m *IWallet) UpdateBalance(ctx context.Context, walletID *string, balance *float64) (*entity.WalletResponse, *entity.ModuleError) {
-	ret := _m.Called(ctx, walletID, balance)
+// UpdateBalance provides a mock function with given fields: ctx, walletID, delta
+func (_m *IWallet) UpdateBalance(ctx context.Context, walletID *string, delta *entity.Money) (*entity.WalletResponse, *entity.ModuleError) {
+	ret := _m.Called(ctx, walletID, delta)
 
 	if len(ret) == 0 {
 		panic("no return value specified for UpdateBalance")
@@ -268,19 +268,19 @@ func (_m *IWallet) UpdateBalance(ctx context.Context, walletID *string, balance
 
 	var r0 *entity.WalletResponse
 	var r1 *entity.ModuleError
-	if rf, ok := ret.Get(0).(func(context.Context, *string, *float64) (*entity.WalletResponse, *entity.ModuleError)); ok {
-		return rf(ctx, walletID, balance)
+	if rf, ok := ret.Get(0).(func(context.Context, *string, *entity.Money) (*entity.WalletResponse, *entity.ModuleError)); ok {
+		return rf(ctx, walletID, delta)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, *string, *float64) *entity.WalletResponse); ok {
-		r0 = rf(ctx, walletID, balance)
+	if rf, ok := ret.Get(0).(func(context.Context, *string, *entity.Money) *entity.WalletResponse); ok {
+		r0 = rf(ctx, walletID, delta)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*entity.WalletResponse)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, *string, *float64) *entity.ModuleError); ok {
-		r1 = rf(ctx, walletID, balance)
+	if rf, ok := ret.Get(1).(func(context.Context, *string, *entity.Money) *entity.ModuleError); ok {
+		r1 = rf(ctx, walletID, delta)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(*entity.ModuleError)
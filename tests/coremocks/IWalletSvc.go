@@ -66,29 +66,29 @@ func (_m *IWalletSvc) Delete(ctx context.Context, userId *string, walletId *stri
 	return r0
 }
 
-// Get provides a mock function with given fields: ctx, userId
-func (_m *IWalletSvc) Get(ctx context.Context, userId *string) ([]entity.WalletResponse, *entity.ModuleError) {
-	ret := _m.Called(ctx, userId)
+// Get provides a mock function with given fields: ctx, userId, page
+func (_m *IWalletSvc) Get(ctx context.Context, userId *string, page *entity.PageRequest) (*entity.Page[entity.WalletResponse], *entity.ModuleError) {
+	ret := _m.Called(ctx, userId, page)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Get")
 	}
 
-	var r0 []entity.WalletResponse
+	var r0 *entity.Page[entity.WalletResponse]
 	var r1 *entity.ModuleError
-	if rf, ok := ret.Get(0).(func(context.Context, *string) ([]entity.WalletResponse, *entity.ModuleError)); ok {
-		return rf(ctx, userId)
+	if rf, ok := ret.Get(0).(func(context.Context, *string, *entity.PageRequest) (*entity.Page[entity.WalletResponse], *entity.ModuleError)); ok {
+		return rf(ctx, userId, page)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, *string) []entity.WalletResponse); ok {
-		r0 = rf(ctx, userId)
+	if rf, ok := ret.Get(0).(func(context.Context, *string, *entity.PageRequest) *entity.Page[entity.WalletResponse]); ok {
+		r0 = rf(ctx, userId, page)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]entity.WalletResponse)
+			r0 = ret.Get(0).(*entity.Page[entity.WalletResponse])
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, *string) *entity.ModuleError); ok {
-		r1 = rf(ctx, userId)
+	if rf, ok := ret.Get(1).(func(context.Context, *string, *entity.PageRequest) *entity.ModuleError); ok {
+		r1 = rf(ctx, userId, page)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(*entity.ModuleError)
@@ -99,7 +99,7 @@ func (_m *IWalletSvc) Get(ctx context.Context, userId *string) ([]entity.WalletR
 }
 
 // GetByFilterMany provides a mock function with given fields: ctx, userId, filter
-func (_m *IWalletSvc) GetByFilterMany(ctx context.Context, userId *string, filter []entity.QueryDB) ([]entity.WalletResponse, *entity.ModuleError) {
+func (_m *IWalletSvc) GetByFilterMany(ctx context.Context, userId *string, filter []entity.QueryDBClause) ([]entity.WalletResponse, *entity.ModuleError) {
 	ret := _m.Called(ctx, userId, filter)
 
 	if len(ret) == 0 {
@@ -108,10 +108,10 @@ func (_m *IWalletSvc) GetByFilterMany(ctx context.Context, userId *string, filte
 
 	var r0 []entity.WalletResponse
 	var r1 *entity.ModuleError
-	if rf, ok := ret.Get(0).(func(context.Context, *string, []entity.QueryDB) ([]entity.WalletResponse, *entity.ModuleError)); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, *string, []entity.QueryDBClause) ([]entity.WalletResponse, *entity.ModuleError)); ok {
 		return rf(ctx, userId, filter)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, *string, []entity.QueryDB) []entity.WalletResponse); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, *string, []entity.QueryDBClause) []entity.WalletResponse); ok {
 		r0 = rf(ctx, userId, filter)
 	} else {
 		if ret.Get(0) != nil {
@@ -119,7 +119,7 @@ func (_m *IWalletSvc) GetByFilterMany(ctx context.Context, userId *string, filte
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, *string, []entity.QueryDB) *entity.ModuleError); ok {
+	if rf, ok := ret.Get(1).(func(context.Context, *string, []entity.QueryDBClause) *entity.ModuleError); ok {
 		r1 = rf(ctx, userId, filter)
 	} else {
 		if ret.Get(1) != nil {
@@ -245,7 +245,7 @@ func (_m *IWalletSvc) PlanValidate(id *string) error {
 }
 
 // SetBalance provides a mock function with given fields: id, balance
-func (_m *IWalletSvc) SetBalance(id *string, balance *float64) error {
+func (_m *IWalletSvc) SetBalance(id *string, balance *entity.Money) error {
 	ret := _m.Called(id, balance)
 
 	if len(ret) == 0 {
@@ -253,7 +253,7 @@ func (_m *IWalletSvc) SetBalance(id *string, balance *float64) error {
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(*string, *float64) error); ok {
+	if rf, ok := ret.Get(0).(func(*string, *entity.Money) error); ok {
 		r0 = rf(id, balance)
 	} else {
 		r0 = ret.Error(0)
@@ -294,9 +294,9 @@ func (_m *IWalletSvc) Update(ctx context.Context, userId *string, data *entity.W
 	return r0, r1
 }
 
-// UpdateBalance provides a mock function with given fields: ctx, walletID, balance
-func (_m *IWalletSvc) UpdateBalance(ctx context.Context, walletID *string, balance *float64) (*entity.WalletResponse, *entity.ModuleError) {
-	ret := _m.Called(ctx, walletID, balance)
+// UpdateBalance provides a mock function with given fields: ctx, walletID, delta
+func (_m *IWalletSvc) UpdateBalance(ctx context.Context, walletID *string, delta *entity.Money) (*entity.WalletResponse, *entity.ModuleError) {
+	ret := _m.Called(ctx, walletID, delta)
 
 	if len(ret) == 0 {
 		panic("no return value specified for UpdateBalance")
@@ -304,19 +304,19 @@ func (_m *IWalletSvc) UpdateBalance(ctx context.Context, walletID *string, balan
 
 	var r0 *entity.WalletResponse
 	var r1 *entity.ModuleError
-	if rf, ok := ret.Get(0).(func(context.Context, *string, *float64) (*entity.WalletResponse, *entity.ModuleError)); ok {
-		return rf(ctx, walletID, balance)
+	if rf, ok := ret.Get(0).(func(context.Context, *string, *entity.Money) (*entity.WalletResponse, *entity.ModuleError)); ok {
+		return rf(ctx, walletID, delta)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, *string, *float64) *entity.WalletResponse); ok {
-		r0 = rf(ctx, walletID, balance)
+	if rf, ok := ret.Get(0).(func(context.Context, *string, *entity.Money) *entity.WalletResponse); ok {
+		r0 = rf(ctx, walletID, delta)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*entity.WalletResponse)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, *string, *float64) *entity.ModuleError); ok {
-		r1 = rf(ctx, walletID, balance)
+	if rf, ok := ret.Get(1).(func(context.Context, *string, *entity.Money) *entity.ModuleError); ok {
+		r1 = rf(ctx, walletID, delta)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(*entity.ModuleError)
@@ -50,6 +50,44 @@ func (_m *IAuthProvider) Callback(w http.ResponseWriter, r *http.Request) (*goth
 	return r0, r1
 }
 
+// Cookies provides a mock function with no fields
+func (_m *IAuthProvider) Cookies() *authProvider.CookieConfig {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Cookies")
+	}
+
+	var r0 *authProvider.CookieConfig
+	if rf, ok := ret.Get(0).(func() *authProvider.CookieConfig); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*authProvider.CookieConfig)
+		}
+	}
+
+	return r0
+}
+
+// HasProvider provides a mock function with given fields: name
+func (_m *IAuthProvider) HasProvider(name string) bool {
+	ret := _m.Called(name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for HasProvider")
+	}
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(string) bool); ok {
+		r0 = rf(name)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
 // IsLoggedIn provides a mock function with given fields: w, r
 func (_m *IAuthProvider) IsLoggedIn(w http.ResponseWriter, r *http.Request) (*goth.User, error) {
 	ret := _m.Called(w, r)
@@ -128,25 +166,63 @@ func (_m *IAuthProvider) Logout(c *gin.Context, w http.ResponseWriter, r *http.R
 	return r0
 }
 
+// Providers provides a mock function with no fields
+func (_m *IAuthProvider) Providers() []string {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Providers")
+	}
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func() []string); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	return r0
+}
+
+// Redirects provides a mock function with no fields
+func (_m *IAuthProvider) Redirects() *authProvider.RedirectConfig {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Redirects")
+	}
+
+	var r0 *authProvider.RedirectConfig
+	if rf, ok := ret.Get(0).(func() *authProvider.RedirectConfig); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*authProvider.RedirectConfig)
+		}
+	}
+
+	return r0
+}
+
 // Store provides a mock function with no fields
-func (_m *IAuthProvider) Store() (*sessions.CookieStore, error) {
+func (_m *IAuthProvider) Store() (sessions.Store, error) {
 	ret := _m.Called()
 
 	if len(ret) == 0 {
 		panic("no return value specified for Store")
 	}
 
-	var r0 *sessions.CookieStore
+	var r0 sessions.Store
 	var r1 error
-	if rf, ok := ret.Get(0).(func() (*sessions.CookieStore, error)); ok {
+	if rf, ok := ret.Get(0).(func() (sessions.Store, error)); ok {
 		return rf()
 	}
-	if rf, ok := ret.Get(0).(func() *sessions.CookieStore); ok {
+	if rf, ok := ret.Get(0).(func() sessions.Store); ok {
 		r0 = rf()
 	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*sessions.CookieStore)
-		}
+		r0 = ret.Get(0).(sessions.Store)
 	}
 
 	if rf, ok := ret.Get(1).(func() error); ok {